@@ -13,27 +13,55 @@ import (
 	"syscall"
 	"time"
 
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+	appattachments "rdmm404/voltr-finance/internal/app/attachments"
 	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
 	appcategories "rdmm404/voltr-finance/internal/app/categories"
+	appevents "rdmm404/voltr-finance/internal/app/events"
+	appgoals "rdmm404/voltr-finance/internal/app/goals"
 	apphouseholds "rdmm404/voltr-finance/internal/app/households"
+	appidempotency "rdmm404/voltr-finance/internal/app/idempotency"
 	apptransactions "rdmm404/voltr-finance/internal/app/transactions"
 	appusers "rdmm404/voltr-finance/internal/app/users"
+	appwebhooks "rdmm404/voltr-finance/internal/app/webhooks"
+	"rdmm404/voltr-finance/internal/blobstore"
 	"rdmm404/voltr-finance/internal/database"
 	"rdmm404/voltr-finance/internal/database/sqlc"
 	"rdmm404/voltr-finance/internal/httpapi"
+	"rdmm404/voltr-finance/internal/notify"
+	alertpostgres "rdmm404/voltr-finance/internal/postgres/alerts"
+	attachmentpostgres "rdmm404/voltr-finance/internal/postgres/attachments"
 	budgetpostgres "rdmm404/voltr-finance/internal/postgres/budgets"
 	categorypostgres "rdmm404/voltr-finance/internal/postgres/categories"
+	goalpostgres "rdmm404/voltr-finance/internal/postgres/goals"
 	householdpostgres "rdmm404/voltr-finance/internal/postgres/households"
+	idempotencypostgres "rdmm404/voltr-finance/internal/postgres/idempotency"
 	transactionpostgres "rdmm404/voltr-finance/internal/postgres/transactions"
 	userpostgres "rdmm404/voltr-finance/internal/postgres/users"
+	webhookpostgres "rdmm404/voltr-finance/internal/postgres/webhooks"
 	"rdmm404/voltr-finance/internal/server"
 	"rdmm404/voltr-finance/internal/webui"
 )
 
+// eventHistorySize is how many recent events /v1/events keeps for clients
+// resuming with Last-Event-ID.
+const eventHistorySize = 1024
+
+// webhookPollInterval is how often the webhook worker looks for new outbox
+// events and due retries.
+const webhookPollInterval = 5 * time.Second
+
+// idempotencyPurgeInterval is how often expired idempotency keys are deleted.
+const idempotencyPurgeInterval = time.Hour
+
 type config struct {
-	API      httpapi.Config
-	UI       webui.Config
-	Database database.Config
+	API         httpapi.Config
+	UI          webui.Config
+	Database    database.Config
+	Alerts      notify.Config
+	Attachments blobstore.Config
+	// IdempotencyTTL is how long Idempotency-Key responses are replayed.
+	IdempotencyTTL time.Duration
 }
 
 func main() {
@@ -54,11 +82,32 @@ func loadConfig() config {
 			Port: uint16(envInt("DB_PORT", 5432)), Name: os.Getenv("DB_NAME"),
 			MaxPoolSize: int32(envInt("DB_POOL_SIZE", 5)), MinPoolSize: int32(envInt("DB_MIN_POOL_SIZE", 0)),
 		},
+		Alerts: notify.Config{
+			WebhookURL: os.Getenv("VOLTR_ALERT_WEBHOOK_URL"), WebhookTimeout: time.Duration(envInt("VOLTR_ALERT_WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second,
+			SMTP: notify.SMTPConfig{
+				Address: os.Getenv("VOLTR_ALERT_SMTP_ADDRESS"), From: os.Getenv("VOLTR_ALERT_SMTP_FROM"), To: envList("VOLTR_ALERT_SMTP_TO"),
+				Username: os.Getenv("VOLTR_ALERT_SMTP_USERNAME"), Password: os.Getenv("VOLTR_ALERT_SMTP_PASSWORD"),
+				Timeout: time.Duration(envInt("VOLTR_ALERT_SMTP_TIMEOUT_SECONDS", 10)) * time.Second,
+			},
+		},
+		Attachments: blobstore.Config{
+			Dir: env("VOLTR_ATTACHMENTS_DIR", "/var/lib/voltr/attachments"),
+			S3: blobstore.S3Config{
+				Endpoint: os.Getenv("VOLTR_ATTACHMENTS_S3_ENDPOINT"), Region: env("VOLTR_ATTACHMENTS_S3_REGION", "us-east-1"), Bucket: os.Getenv("VOLTR_ATTACHMENTS_S3_BUCKET"),
+				AccessKeyID: os.Getenv("VOLTR_ATTACHMENTS_S3_ACCESS_KEY_ID"), SecretAccessKey: os.Getenv("VOLTR_ATTACHMENTS_S3_SECRET_ACCESS_KEY"),
+				PathStyle: envBool("VOLTR_ATTACHMENTS_S3_PATH_STYLE"),
+			},
+		},
+		IdempotencyTTL: time.Duration(envInt("VOLTR_IDEMPOTENCY_TTL_HOURS", 24)) * time.Hour,
 	}
 }
 
 func (c config) Validate() error {
-	return errors.Join(c.API.Validate(), c.UI.Validate(), c.Database.Validate())
+	var idempotencyErr error
+	if c.IdempotencyTTL <= 0 {
+		idempotencyErr = errors.New("VOLTR_IDEMPOTENCY_TTL_HOURS must be a positive number of hours")
+	}
+	return errors.Join(c.API.Validate(), c.UI.Validate(), c.Database.Validate(), c.Alerts.Validate(), c.Attachments.Validate(), idempotencyErr)
 }
 
 func run(ctx context.Context, cfg config) error {
@@ -72,20 +121,34 @@ func run(ctx context.Context, cfg config) error {
 	defer pool.Close()
 
 	queries := sqlc.New(pool)
+	eventBus := appevents.NewBus(eventHistorySize)
 	userService := appusers.NewService(userpostgres.NewRepository(queries))
-	categoryService := appcategories.NewService(categorypostgres.NewRepository(queries))
+	categoryService := appcategories.NewService(categorypostgres.NewRepository(queries)).WithEvents(eventBus)
 	householdService := apphouseholds.NewService(householdpostgres.NewRepository(queries))
+	alertService := appalerts.NewService(alertpostgres.NewRepository(pool), notify.New(cfg.Alerts, slog.Default())...)
 	transactionService := apptransactions.NewService(
 		transactionpostgres.NewRepository(pool),
 		identityResolver{users: userService},
 		categoryResolver{categories: categoryService},
-	)
-	budgetService := appbudgets.NewService(budgetpostgres.NewRepository(pool))
+		alertEvaluator{alerts: alertService},
+	).WithEvents(eventBus)
+	goalService := appgoals.NewService(goalpostgres.NewRepository(pool))
+	budgetService := appbudgets.NewService(budgetpostgres.NewRepository(pool), goalReader{goals: goalService}).WithEvents(eventBus)
+	webhookService := appwebhooks.NewService(webhookpostgres.NewRepository(pool), notify.NewDeliverySender(0))
+	blobs, err := blobstore.New(cfg.Attachments)
+	if err != nil {
+		return fmt.Errorf("configure attachment storage: %w", err)
+	}
+	attachmentService := appattachments.NewService(attachmentpostgres.NewRepository(pool), blobs)
+	idempotencyService := appidempotency.NewService(idempotencypostgres.NewRepository(pool), cfg.IdempotencyTTL)
 
-	httpServer, err := server.New(cfg.API, cfg.UI, transactionService, userService, householdService, categoryService, budgetService)
+	httpServer, err := server.New(cfg.API, cfg.UI, transactionService, userService, householdService, categoryService, budgetService, goalService, alertService, eventBus, webhookService, attachmentService, idempotencyService)
 	if err != nil {
 		return fmt.Errorf("configure HTTP server: %w", err)
 	}
+	go dispatchAlerts(ctx, alertService)
+	go deliverWebhooks(ctx, webhookService, webhookPollInterval)
+	go purgeIdempotencyKeys(ctx, idempotencyService, idempotencyPurgeInterval)
 
 	result := make(chan error, 1)
 	go func() {
@@ -100,6 +163,9 @@ func run(ctx context.Context, cfg config) error {
 		}
 		return err
 	case <-ctx.Done():
+		// Shutdown waits for open requests; ending the event streams first
+		// lets it finish.
+		eventBus.Close()
 		shutdownContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		if err := httpServer.Shutdown(shutdownContext); err != nil {
@@ -136,6 +202,89 @@ func (r categoryResolver) ResolveActiveCategoryID(ctx context.Context, id *int64
 	return &category.ID, nil
 }
 
+type goalReader struct{ goals *appgoals.Service }
+
+func (r goalReader) GoalProgress(ctx context.Context, owner appbudgets.Owner, period appbudgets.Period) ([]appbudgets.GoalProgress, error) {
+	items, err := r.goals.ForPeriod(ctx, appgoals.Owner{HouseholdID: owner.HouseholdID, UserID: owner.UserID}, period.Start, period.End)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]appbudgets.GoalProgress, 0, len(items))
+	for _, item := range items {
+		result = append(result, appbudgets.GoalProgress{
+			ID: item.ID, Name: item.Name, TargetAmount: item.TargetAmount, TargetDate: item.TargetDate,
+			BalanceAmount: item.BalanceAmount, RemainingAmount: item.RemainingAmount,
+			RequiredMonthlyAmount: item.RequiredMonthlyAmount, PeriodNetAmount: item.PeriodNetAmount, State: string(item.State),
+		})
+	}
+	return result, nil
+}
+
+// alertEvaluator runs after the transaction write has committed, so failures
+// are logged rather than turned into a failed request.
+type alertEvaluator struct{ alerts *appalerts.Service }
+
+func (e alertEvaluator) EvaluateTransaction(ctx context.Context, item apptransactions.Transaction) {
+	created, err := e.alerts.EvaluateTransaction(ctx, item.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "evaluate budget alerts", "transactionId", item.ID, "error", err)
+	}
+	for _, alert := range created {
+		if alert.NotificationError != nil {
+			slog.WarnContext(ctx, "budget alert delivery failed", "alertId", alert.ID, "error", *alert.NotificationError)
+		}
+	}
+}
+
+// dispatchAlerts sends queued budget alerts to the notifiers until ctx ends,
+// keeping the webhook and mail round trips off the transaction requests.
+func dispatchAlerts(ctx context.Context, alerts *appalerts.Service) {
+	for {
+		alert, err := alerts.Dispatch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "dispatch budget alert", "alertId", alert.ID, "error", err)
+		} else if alert.NotificationError != nil {
+			slog.WarnContext(ctx, "budget alert delivery failed", "alertId", alert.ID, "error", *alert.NotificationError)
+		}
+	}
+}
+
+// deliverWebhooks runs webhook worker passes until ctx ends. A failed pass is
+// logged and tried again on the next tick.
+func deliverWebhooks(ctx context.Context, webhooks *appwebhooks.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := webhooks.Deliver(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "deliver webhooks", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeIdempotencyKeys deletes expired idempotency keys until ctx ends.
+func purgeIdempotencyKeys(ctx context.Context, keys *appidempotency.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := keys.PurgeExpired(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "purge idempotency keys", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func env(name, fallback string) string {
 	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
 		return value
@@ -143,6 +292,21 @@ func env(name, fallback string) string {
 	return fallback
 }
 
+func envList(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func envBool(name string) bool {
+	value, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(name)))
+	return value
+}
+
 func envInt(name string, fallback int) int {
 	value := strings.TrimSpace(os.Getenv(name))
 	if value == "" {
@@ -17,6 +17,9 @@ func TestLoadConfigAndValidate(t *testing.T) {
 	t.Setenv("DB_NAME", "finance")
 	t.Setenv("DB_POOL_SIZE", "8")
 	t.Setenv("DB_MIN_POOL_SIZE", "2")
+	t.Setenv("VOLTR_ALERT_SMTP_ADDRESS", "mail:25")
+	t.Setenv("VOLTR_ALERT_SMTP_FROM", "voltr@example.com")
+	t.Setenv("VOLTR_ALERT_SMTP_TO", "a@example.com, b@example.com")
 	config := loadConfig()
 	if err := config.Validate(); err != nil {
 		t.Fatal(err)
@@ -24,6 +27,9 @@ func TestLoadConfigAndValidate(t *testing.T) {
 	if config.API.Address != ":9090" || config.UI.DefaultUserID != 1 || config.UI.DefaultHouseholdID != 2 || config.Database.Port != 5433 || config.Database.MaxPoolSize != 8 || config.Database.MinPoolSize != 2 {
 		t.Fatalf("config=%+v", config)
 	}
+	if config.Alerts.SMTP.Address != "mail:25" || len(config.Alerts.SMTP.To) != 2 || config.Alerts.SMTP.To[1] != "b@example.com" || config.Alerts.WebhookURL != "" {
+		t.Fatalf("alerts config=%+v", config.Alerts)
+	}
 }
 
 func TestConfigurationRejectsEmptyAPIKeyBeforeStartup(t *testing.T) {
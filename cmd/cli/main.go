@@ -34,7 +34,7 @@ func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer,
 		fmt.Fprintln(stderr, err)
 		return 1
 	}
-	client, err := restclient.New(restclient.Config{BaseURL: cfg.API.BaseURL, APIKey: cfg.API.APIKey})
+	client, err := restclient.New(restclient.Config{BaseURL: cfg.API.BaseURL, APIKey: cfg.API.APIKey, Retries: 2})
 	if err != nil {
 		fmt.Fprintln(stderr, err)
 		return 1
@@ -0,0 +1,64 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+CREATE EXTENSION IF NOT EXISTS btree_gist WITH SCHEMA public;
+
+ALTER TABLE budget ADD COLUMN period_kind VARCHAR(16) NOT NULL DEFAULT 'monthly';
+
+ALTER TABLE budget ADD CONSTRAINT chk_budget_period_kind
+CHECK (period_kind IN ('weekly', 'biweekly', 'monthly', 'quarterly', 'annual', 'custom'));
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM budget a
+        JOIN budget b
+          ON a.id < b.id
+         AND a.household_id IS NOT DISTINCT FROM b.household_id
+         AND a.user_id IS NOT DISTINCT FROM b.user_id
+         AND daterange(a.period_start, a.period_end, '[]') && daterange(b.period_start, b.period_end, '[]')
+    ) THEN
+        RAISE EXCEPTION 'Existing monthly budgets overlap for the same owner; clean up budget rows before running this migration.';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_budget_household_period;
+DROP INDEX IF EXISTS idx_budget_user_period;
+
+-- An owner holds at most one budget of each period kind for any given day.
+-- Different kinds may coexist, e.g. weekly grocery budgets inside a monthly budget.
+ALTER TABLE budget ADD CONSTRAINT excl_budget_household_period_overlap
+EXCLUDE USING gist (
+    household_id WITH =,
+    period_kind WITH =,
+    daterange(period_start, period_end, '[]') WITH &&
+)
+WHERE (household_id IS NOT NULL);
+
+ALTER TABLE budget ADD CONSTRAINT excl_budget_user_period_overlap
+EXCLUDE USING gist (
+    user_id WITH =,
+    period_kind WITH =,
+    daterange(period_start, period_end, '[]') WITH &&
+)
+WHERE (user_id IS NOT NULL);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+ALTER TABLE budget DROP CONSTRAINT IF EXISTS excl_budget_user_period_overlap;
+ALTER TABLE budget DROP CONSTRAINT IF EXISTS excl_budget_household_period_overlap;
+
+DELETE FROM budget WHERE period_kind <> 'monthly';
+
+CREATE UNIQUE INDEX idx_budget_household_period
+ON budget(household_id, period_start, period_end)
+WHERE household_id IS NOT NULL;
+
+CREATE UNIQUE INDEX idx_budget_user_period
+ON budget(user_id, period_start, period_end)
+WHERE user_id IS NOT NULL;
+
+ALTER TABLE budget DROP CONSTRAINT IF EXISTS chk_budget_period_kind;
+ALTER TABLE budget DROP COLUMN IF EXISTS period_kind;
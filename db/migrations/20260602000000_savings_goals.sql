@@ -0,0 +1,48 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+CREATE TABLE savings_goal (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    household_id BIGINT REFERENCES household(id),
+    user_id BIGINT REFERENCES users(id),
+    name VARCHAR NOT NULL,
+    target_amount NUMERIC(12, 2) NOT NULL,
+    start_date DATE NOT NULL,
+    target_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_savings_goal_exactly_one_owner CHECK (
+        (household_id IS NOT NULL AND user_id IS NULL)
+        OR
+        (household_id IS NULL AND user_id IS NOT NULL)
+    ),
+    CONSTRAINT chk_savings_goal_target_amount CHECK (target_amount > 0),
+    CONSTRAINT chk_savings_goal_valid_dates CHECK (target_date >= start_date)
+);
+
+CREATE INDEX idx_savings_goal_household_id
+ON savings_goal(household_id)
+WHERE household_id IS NOT NULL;
+
+CREATE INDEX idx_savings_goal_user_id
+ON savings_goal(user_id)
+WHERE user_id IS NOT NULL;
+
+CREATE TABLE savings_goal_category (
+    goal_id BIGINT NOT NULL REFERENCES savings_goal(id) ON DELETE CASCADE,
+    category_id BIGINT NOT NULL REFERENCES category(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (goal_id, category_id)
+);
+
+CREATE INDEX idx_savings_goal_category_category_id
+ON savings_goal_category(category_id);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP INDEX IF EXISTS idx_savings_goal_category_category_id;
+DROP TABLE IF EXISTS savings_goal_category;
+DROP INDEX IF EXISTS idx_savings_goal_user_id;
+DROP INDEX IF EXISTS idx_savings_goal_household_id;
+DROP TABLE IF EXISTS savings_goal;
@@ -0,0 +1,37 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+CREATE TABLE budget_line_alert_rule (
+    budget_line_id BIGINT NOT NULL REFERENCES budget_line(id) ON DELETE CASCADE,
+    threshold_percent INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (budget_line_id, threshold_percent),
+    CONSTRAINT chk_budget_line_alert_rule_threshold CHECK (threshold_percent BETWEEN 1 AND 1000)
+);
+
+-- A budget line belongs to exactly one budget period, so the unique key
+-- de-duplicates alerts per line, per period and per threshold.
+CREATE TABLE budget_alert (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    budget_id BIGINT NOT NULL REFERENCES budget(id) ON DELETE CASCADE,
+    budget_line_id BIGINT NOT NULL REFERENCES budget_line(id) ON DELETE CASCADE,
+    threshold_percent INTEGER NOT NULL,
+    allocation_amount NUMERIC(12, 2) NOT NULL,
+    actual_amount NUMERIC(12, 2) NOT NULL,
+    transaction_id BIGINT REFERENCES transaction(id) ON DELETE SET NULL,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    notification_error VARCHAR,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT budget_alert_budget_line_id_threshold_percent_key UNIQUE (budget_line_id, threshold_percent)
+);
+
+CREATE INDEX idx_budget_alert_budget_id ON budget_alert(budget_id);
+CREATE INDEX idx_budget_alert_created_at ON budget_alert(created_at);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP INDEX IF EXISTS idx_budget_alert_created_at;
+DROP INDEX IF EXISTS idx_budget_alert_budget_id;
+DROP TABLE IF EXISTS budget_alert;
+DROP TABLE IF EXISTS budget_line_alert_rule;
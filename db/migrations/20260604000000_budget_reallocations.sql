@@ -0,0 +1,32 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- Each row moves allocation from one line of a budget to another. The lines'
+-- allocation_amount already includes every move; these rows are the audit
+-- trail that lets reports show the allocation originally planned.
+CREATE TABLE budget_reallocation (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    budget_id BIGINT NOT NULL REFERENCES budget(id) ON DELETE CASCADE,
+    from_line_id BIGINT NOT NULL,
+    to_line_id BIGINT NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL,
+    reason VARCHAR,
+    created_by_user_id BIGINT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (budget_id, from_line_id)
+        REFERENCES budget_line(budget_id, id)
+        ON DELETE CASCADE,
+    FOREIGN KEY (budget_id, to_line_id)
+        REFERENCES budget_line(budget_id, id)
+        ON DELETE CASCADE,
+    CHECK (amount > 0),
+    CONSTRAINT chk_budget_reallocation_distinct_lines CHECK (from_line_id <> to_line_id)
+);
+
+CREATE INDEX idx_budget_reallocation_budget_id ON budget_reallocation(budget_id);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP INDEX IF EXISTS idx_budget_reallocation_budget_id;
+DROP TABLE IF EXISTS budget_reallocation;
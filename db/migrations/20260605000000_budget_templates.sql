@@ -0,0 +1,61 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- A named budget layout an owner can create budgets from or merge into an
+-- existing budget, so a one-off month does not carry forward forever.
+CREATE TABLE budget_template (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    household_id BIGINT REFERENCES household(id),
+    user_id BIGINT REFERENCES users(id),
+    name VARCHAR NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_budget_template_exactly_one_owner CHECK (
+        (household_id IS NOT NULL AND user_id IS NULL)
+        OR
+        (household_id IS NULL AND user_id IS NOT NULL)
+    )
+);
+
+CREATE UNIQUE INDEX idx_budget_template_household_name
+ON budget_template(household_id, lower(name))
+WHERE household_id IS NOT NULL;
+
+CREATE UNIQUE INDEX idx_budget_template_user_name
+ON budget_template(user_id, lower(name))
+WHERE user_id IS NOT NULL;
+
+CREATE TABLE budget_template_line (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    template_id BIGINT NOT NULL REFERENCES budget_template(id) ON DELETE CASCADE,
+    name VARCHAR NOT NULL,
+    allocation_amount NUMERIC(12, 2) NOT NULL,
+    sort_order INTEGER NOT NULL,
+    CHECK (allocation_amount >= 0),
+    UNIQUE (template_id, id),
+    UNIQUE (template_id, sort_order)
+);
+
+CREATE TABLE budget_template_line_category (
+    template_id BIGINT NOT NULL REFERENCES budget_template(id) ON DELETE CASCADE,
+    template_line_id BIGINT NOT NULL,
+    category_id BIGINT NOT NULL REFERENCES category(id),
+    PRIMARY KEY (template_line_id, category_id),
+    FOREIGN KEY (template_id, template_line_id)
+        REFERENCES budget_template_line(template_id, id)
+        ON DELETE CASCADE,
+    UNIQUE (template_id, category_id)
+);
+
+CREATE INDEX idx_budget_template_line_category_category_id
+ON budget_template_line_category(category_id);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP INDEX IF EXISTS idx_budget_template_line_category_category_id;
+DROP TABLE IF EXISTS budget_template_line_category;
+DROP TABLE IF EXISTS budget_template_line;
+DROP INDEX IF EXISTS idx_budget_template_user_name;
+DROP INDEX IF EXISTS idx_budget_template_household_name;
+DROP TABLE IF EXISTS budget_template;
@@ -0,0 +1,51 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- A closing freezes a reviewed budget. The row keeps the report totals and
+-- each line's actuals at close time so later reports can serve them and flag
+-- drift. Reopening stamps the row instead of deleting it, so the history of
+-- closes and reopens stays. A budget has at most one open closing.
+CREATE TABLE budget_closing (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    budget_id BIGINT NOT NULL REFERENCES budget(id) ON DELETE CASCADE,
+    allocation_amount NUMERIC(12, 2) NOT NULL,
+    actual_amount NUMERIC(12, 2) NOT NULL,
+    unmapped_actual_amount NUMERIC(12, 2) NOT NULL,
+    uncategorized_actual_amount NUMERIC(12, 2) NOT NULL,
+    closed_by_user_id BIGINT NOT NULL REFERENCES users(id),
+    closed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reopened_by_user_id BIGINT REFERENCES users(id),
+    reopened_at TIMESTAMP WITH TIME ZONE,
+    reopen_reason VARCHAR,
+    CONSTRAINT chk_budget_closing_reopen CHECK (
+        (reopened_at IS NULL AND reopened_by_user_id IS NULL AND reopen_reason IS NULL)
+        OR
+        (reopened_at IS NOT NULL AND reopened_by_user_id IS NOT NULL AND reopen_reason IS NOT NULL)
+    )
+);
+
+CREATE UNIQUE INDEX idx_budget_closing_open_budget_id
+ON budget_closing(budget_id)
+WHERE reopened_at IS NULL;
+
+-- Lines are copied by value; budget_line_id is cleared if the line is deleted
+-- after the budget is reopened.
+CREATE TABLE budget_closing_line (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    closing_id BIGINT NOT NULL REFERENCES budget_closing(id) ON DELETE CASCADE,
+    budget_line_id BIGINT REFERENCES budget_line(id) ON DELETE SET NULL,
+    name VARCHAR NOT NULL,
+    allocation_amount NUMERIC(12, 2) NOT NULL,
+    actual_amount NUMERIC(12, 2) NOT NULL,
+    sort_order INTEGER NOT NULL
+);
+
+CREATE INDEX idx_budget_closing_line_closing_id ON budget_closing_line(closing_id);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP INDEX IF EXISTS idx_budget_closing_line_closing_id;
+DROP TABLE IF EXISTS budget_closing_line;
+DROP INDEX IF EXISTS idx_budget_closing_open_budget_id;
+DROP TABLE IF EXISTS budget_closing;
@@ -0,0 +1,21 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- Household budget lines can split their allocation between members, each
+-- member getting either a fixed amount or a percentage of the line.
+CREATE TABLE budget_line_member_allocation (
+    budget_line_id BIGINT NOT NULL REFERENCES budget_line(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    allocation_amount NUMERIC(12, 2),
+    allocation_percent NUMERIC(5, 2),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (budget_line_id, user_id),
+    CONSTRAINT chk_budget_line_member_allocation_kind CHECK ((allocation_amount IS NULL) <> (allocation_percent IS NULL)),
+    CONSTRAINT chk_budget_line_member_allocation_amount CHECK (allocation_amount >= 0),
+    CONSTRAINT chk_budget_line_member_allocation_percent CHECK (allocation_percent > 0 AND allocation_percent <= 100)
+);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP TABLE IF EXISTS budget_line_member_allocation;
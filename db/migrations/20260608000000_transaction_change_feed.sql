@@ -0,0 +1,44 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- Every insert and update of a transaction, including soft deletes and
+-- restores, takes the next change_seq. Sync clients read changes in that order
+-- from a token. The advisory lock is held until commit, so sequence numbers
+-- become visible in order and a reader never skips a change that commits late.
+CREATE SEQUENCE transaction_change_seq;
+
+ALTER TABLE transaction ADD COLUMN change_seq BIGINT;
+
+WITH ordered AS (
+    SELECT id, row_number() OVER (ORDER BY COALESCE(updated_at, created_at, transaction_date), id) AS change_seq
+    FROM transaction
+)
+UPDATE transaction t SET change_seq = ordered.change_seq
+FROM ordered
+WHERE ordered.id = t.id;
+
+SELECT setval('transaction_change_seq', COALESCE((SELECT max(change_seq) FROM transaction), 0) + 1, false);
+
+ALTER TABLE transaction ALTER COLUMN change_seq SET NOT NULL;
+CREATE UNIQUE INDEX idx_transaction_change_seq ON transaction(change_seq);
+
+CREATE FUNCTION transaction_record_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('transactions.transaction_change_seq'));
+    NEW.change_seq := nextval('transactions.transaction_change_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transaction_record_change
+BEFORE INSERT OR UPDATE ON transaction
+FOR EACH ROW EXECUTE FUNCTION transaction_record_change();
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP TRIGGER IF EXISTS transaction_record_change ON transaction;
+DROP FUNCTION IF EXISTS transaction_record_change();
+DROP INDEX IF EXISTS idx_transaction_change_seq;
+ALTER TABLE transaction DROP COLUMN IF EXISTS change_seq;
+DROP SEQUENCE IF EXISTS transaction_change_seq;
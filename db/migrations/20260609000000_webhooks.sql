@@ -0,0 +1,68 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- An empty event_types array subscribes to every event type; a null
+-- household_id subscribes to every household and to personal records.
+CREATE TABLE webhook_subscription (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    url VARCHAR NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    household_id BIGINT REFERENCES household(id) ON DELETE CASCADE,
+    secret VARCHAR NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Repositories write outbox rows in the same database transaction as the
+-- change they describe. The delivery worker fans each row out to the matching
+-- subscriptions and then marks it dispatched.
+CREATE TABLE webhook_outbox (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    event_type VARCHAR NOT NULL,
+    household_id BIGINT,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dispatched_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_webhook_outbox_undispatched ON webhook_outbox(id) WHERE dispatched_at IS NULL;
+
+CREATE TABLE webhook_delivery (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscription(id) ON DELETE CASCADE,
+    outbox_id BIGINT NOT NULL REFERENCES webhook_outbox(id) ON DELETE CASCADE,
+    status VARCHAR NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    last_error VARCHAR,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT webhook_delivery_subscription_id_outbox_id_key UNIQUE (subscription_id, outbox_id),
+    CONSTRAINT chk_webhook_delivery_status CHECK (status IN ('pending', 'succeeded', 'failed'))
+);
+
+CREATE INDEX idx_webhook_delivery_due ON webhook_delivery(next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE webhook_delivery_attempt (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    delivery_id BIGINT NOT NULL REFERENCES webhook_delivery(id) ON DELETE CASCADE,
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status_code INTEGER,
+    error VARCHAR,
+    duration_ms INTEGER NOT NULL
+);
+
+CREATE INDEX idx_webhook_delivery_attempt_delivery_id ON webhook_delivery_attempt(delivery_id);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP INDEX IF EXISTS idx_webhook_delivery_attempt_delivery_id;
+DROP TABLE IF EXISTS webhook_delivery_attempt;
+DROP INDEX IF EXISTS idx_webhook_delivery_due;
+DROP TABLE IF EXISTS webhook_delivery;
+DROP INDEX IF EXISTS idx_webhook_outbox_undispatched;
+DROP TABLE IF EXISTS webhook_outbox;
+DROP TABLE IF EXISTS webhook_subscription;
@@ -0,0 +1,21 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- One row per create, update, delete or restore of a transaction, written in
+-- the same database transaction as the change. changes holds the fields that
+-- changed as [{"field":...,"before":...,"after":...}].
+CREATE TABLE transaction_history (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    transaction_id BIGINT NOT NULL REFERENCES transaction(id) ON DELETE CASCADE,
+    action VARCHAR NOT NULL CHECK (action IN ('created', 'updated', 'deleted', 'restored')),
+    actor_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transaction_history_transaction_id ON transaction_history(transaction_id, id);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP TABLE IF EXISTS transaction_history;
@@ -0,0 +1,40 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- version counts the writes to a row. The API returns it as the ETag and
+-- compares it with If-Match, so a client's write only applies to the version
+-- it last read. The trigger bumps it on every update, including the ones that
+-- do not name the column.
+ALTER TABLE transaction ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE budget_line ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE category ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+CREATE FUNCTION bump_row_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transaction_bump_version
+BEFORE UPDATE ON transaction
+FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+CREATE TRIGGER budget_line_bump_version
+BEFORE UPDATE ON budget_line
+FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+CREATE TRIGGER category_bump_version
+BEFORE UPDATE ON category
+FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP TRIGGER IF EXISTS category_bump_version ON category;
+DROP TRIGGER IF EXISTS budget_line_bump_version ON budget_line;
+DROP TRIGGER IF EXISTS transaction_bump_version ON transaction;
+DROP FUNCTION IF EXISTS bump_row_version();
+ALTER TABLE category DROP COLUMN IF EXISTS version;
+ALTER TABLE budget_line DROP COLUMN IF EXISTS version;
+ALTER TABLE transaction DROP COLUMN IF EXISTS version;
@@ -0,0 +1,22 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- A row is claimed before the request runs, with a null status_code while it
+-- is in flight, and completed with the response that retries replay. Rows
+-- are ignored once expired and purged by the API process.
+CREATE TABLE idempotency_key (
+    key VARCHAR(255) PRIMARY KEY,
+    fingerprint VARCHAR NOT NULL,
+    status_code INTEGER,
+    response_headers JSONB NOT NULL DEFAULT '{}',
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_idempotency_key_expires_at ON idempotency_key(expires_at);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP TABLE IF EXISTS idempotency_key;
@@ -0,0 +1,22 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- Attachment contents live in the configured blob store under storage_key;
+-- this table holds what the API serves about them.
+CREATE TABLE transaction_attachment (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    transaction_id BIGINT NOT NULL REFERENCES transaction(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    sha256 CHAR(64) NOT NULL,
+    storage_key VARCHAR NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transaction_attachment_transaction_id ON transaction_attachment(transaction_id);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP TABLE IF EXISTS transaction_attachment;
@@ -0,0 +1,24 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- Tags are free-form labels shared by every household. Unlike categories they
+-- play no part in budgets, and a transaction can carry any number of them.
+CREATE TABLE tag (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE transaction_tag (
+    transaction_id BIGINT NOT NULL REFERENCES transaction(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tag(id) ON DELETE CASCADE,
+    PRIMARY KEY (transaction_id, tag_id)
+);
+
+CREATE INDEX idx_transaction_tag_tag_id ON transaction_tag(tag_id);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP TABLE IF EXISTS transaction_tag;
+DROP TABLE IF EXISTS tag;
@@ -0,0 +1,17 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+-- Saved filters are transaction filter expressions stored under a name. The
+-- API validates the query before saving it.
+CREATE TABLE saved_filter (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE,
+    query TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP TABLE IF EXISTS saved_filter;
@@ -0,0 +1,20 @@
+-- migrate:up
+SET search_path TO transactions, public;
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public;
+
+-- Searches match the description and notes as one document. The 'simple'
+-- configuration neither stems nor drops stop words, which suits short merchant
+-- names in any language. Queries must repeat these expressions exactly for the
+-- planner to use the indexes.
+CREATE INDEX idx_transaction_search_vector ON transaction
+    USING GIN (to_tsvector('simple', COALESCE(description, '') || ' ' || COALESCE(notes, '')));
+
+CREATE INDEX idx_transaction_search_trigram ON transaction
+    USING GIN ((COALESCE(description, '') || ' ' || COALESCE(notes, '')) public.gin_trgm_ops);
+
+-- migrate:down
+SET search_path TO transactions, public;
+
+DROP INDEX IF EXISTS idx_transaction_search_trigram;
+DROP INDEX IF EXISTS idx_transaction_search_vector;
@@ -22,6 +22,63 @@ SET row_security = off;
 CREATE SCHEMA transactions;
 
 
+--
+-- Name: btree_gist; Type: EXTENSION; Schema: -; Owner: -
+--
+
+CREATE EXTENSION IF NOT EXISTS btree_gist WITH SCHEMA public;
+
+
+--
+-- Name: EXTENSION btree_gist; Type: COMMENT; Schema: -; Owner: -
+--
+
+COMMENT ON EXTENSION btree_gist IS 'support for indexing common datatypes in GiST';
+
+
+--
+-- Name: pg_trgm; Type: EXTENSION; Schema: -; Owner: -
+--
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public;
+
+
+--
+-- Name: EXTENSION pg_trgm; Type: COMMENT; Schema: -; Owner: -
+--
+
+COMMENT ON EXTENSION pg_trgm IS 'text similarity measurement and index searching based on trigrams';
+
+
+--
+-- Name: bump_row_version(); Type: FUNCTION; Schema: transactions; Owner: -
+--
+
+CREATE FUNCTION transactions.bump_row_version() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$;
+
+
+--
+-- Name: transaction_record_change(); Type: FUNCTION; Schema: transactions; Owner: -
+--
+
+CREATE FUNCTION transactions.transaction_record_change() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('transactions.transaction_change_seq'));
+    NEW.change_seq := nextval('transactions.transaction_change_seq');
+    RETURN NEW;
+END;
+$$;
+
+
 SET default_tablespace = '';
 
 SET default_table_access_method = heap;
@@ -39,7 +96,9 @@ CREATE TABLE transactions.budget (
     period_start date NOT NULL,
     period_end date NOT NULL,
     source_budget_id bigint,
+    period_kind character varying(16) DEFAULT 'monthly'::character varying NOT NULL,
     CONSTRAINT chk_budget_exactly_one_owner CHECK ((((household_id IS NOT NULL) AND (user_id IS NULL)) OR ((household_id IS NULL) AND (user_id IS NOT NULL)))),
+    CONSTRAINT chk_budget_period_kind CHECK (((period_kind)::text = ANY ((ARRAY['weekly'::character varying, 'biweekly'::character varying, 'monthly'::character varying, 'quarterly'::character varying, 'annual'::character varying, 'custom'::character varying])::text[]))),
     CONSTRAINT chk_budget_valid_period CHECK ((period_end >= period_start))
 );
 
@@ -72,6 +131,101 @@ COMMENT ON COLUMN transactions.budget.user_id IS 'Optional reference to a specif
 COMMENT ON COLUMN transactions.budget.household_id IS 'Optional reference to a household for shared group budgets.';
 
 
+--
+-- Name: budget_alert; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.budget_alert (
+    id bigint NOT NULL,
+    budget_id bigint NOT NULL,
+    budget_line_id bigint NOT NULL,
+    threshold_percent integer NOT NULL,
+    allocation_amount numeric(12,2) NOT NULL,
+    actual_amount numeric(12,2) NOT NULL,
+    transaction_id bigint,
+    notified_at timestamp with time zone,
+    notification_error character varying,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+
+--
+-- Name: budget_alert_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.budget_alert ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.budget_alert_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: budget_closing; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.budget_closing (
+    id bigint NOT NULL,
+    budget_id bigint NOT NULL,
+    allocation_amount numeric(12,2) NOT NULL,
+    actual_amount numeric(12,2) NOT NULL,
+    unmapped_actual_amount numeric(12,2) NOT NULL,
+    uncategorized_actual_amount numeric(12,2) NOT NULL,
+    closed_by_user_id bigint NOT NULL,
+    closed_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    reopened_by_user_id bigint,
+    reopened_at timestamp with time zone,
+    reopen_reason character varying,
+    CONSTRAINT chk_budget_closing_reopen CHECK ((((reopened_at IS NULL) AND (reopened_by_user_id IS NULL) AND (reopen_reason IS NULL)) OR ((reopened_at IS NOT NULL) AND (reopened_by_user_id IS NOT NULL) AND (reopen_reason IS NOT NULL))))
+);
+
+
+--
+-- Name: budget_closing_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.budget_closing ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.budget_closing_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: budget_closing_line; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.budget_closing_line (
+    id bigint NOT NULL,
+    closing_id bigint NOT NULL,
+    budget_line_id bigint,
+    name character varying NOT NULL,
+    allocation_amount numeric(12,2) NOT NULL,
+    actual_amount numeric(12,2) NOT NULL,
+    sort_order integer NOT NULL
+);
+
+
+--
+-- Name: budget_closing_line_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.budget_closing_line ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.budget_closing_line_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
 --
 -- Name: budget_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
 --
@@ -98,10 +252,23 @@ CREATE TABLE transactions.budget_line (
     sort_order integer NOT NULL,
     created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
     updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    version bigint DEFAULT 1 NOT NULL,
     CONSTRAINT budget_line_allocation_amount_check CHECK ((allocation_amount >= (0)::numeric))
 );
 
 
+--
+-- Name: budget_line_alert_rule; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.budget_line_alert_rule (
+    budget_line_id bigint NOT NULL,
+    threshold_percent integer NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_budget_line_alert_rule_threshold CHECK (((threshold_percent >= 1) AND (threshold_percent <= 1000)))
+);
+
+
 --
 -- Name: budget_line_category; Type: TABLE; Schema: transactions; Owner: -
 --
@@ -128,6 +295,122 @@ ALTER TABLE transactions.budget_line ALTER COLUMN id ADD GENERATED ALWAYS AS IDE
 );
 
 
+--
+-- Name: budget_line_member_allocation; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.budget_line_member_allocation (
+    budget_line_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    allocation_amount numeric(12,2),
+    allocation_percent numeric(5,2),
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_budget_line_member_allocation_amount CHECK ((allocation_amount >= (0)::numeric)),
+    CONSTRAINT chk_budget_line_member_allocation_kind CHECK (((allocation_amount IS NULL) <> (allocation_percent IS NULL))),
+    CONSTRAINT chk_budget_line_member_allocation_percent CHECK (((allocation_percent > (0)::numeric) AND (allocation_percent <= (100)::numeric)))
+);
+
+
+--
+-- Name: budget_reallocation; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.budget_reallocation (
+    id bigint NOT NULL,
+    budget_id bigint NOT NULL,
+    from_line_id bigint NOT NULL,
+    to_line_id bigint NOT NULL,
+    amount numeric(12,2) NOT NULL,
+    reason character varying,
+    created_by_user_id bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT budget_reallocation_amount_check CHECK ((amount > (0)::numeric)),
+    CONSTRAINT chk_budget_reallocation_distinct_lines CHECK ((from_line_id <> to_line_id))
+);
+
+
+--
+-- Name: budget_reallocation_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.budget_reallocation ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.budget_reallocation_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: budget_template; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.budget_template (
+    id bigint NOT NULL,
+    household_id bigint,
+    user_id bigint,
+    name character varying NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_budget_template_exactly_one_owner CHECK ((((household_id IS NOT NULL) AND (user_id IS NULL)) OR ((household_id IS NULL) AND (user_id IS NOT NULL))))
+);
+
+
+--
+-- Name: budget_template_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.budget_template ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.budget_template_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: budget_template_line; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.budget_template_line (
+    id bigint NOT NULL,
+    template_id bigint NOT NULL,
+    name character varying NOT NULL,
+    allocation_amount numeric(12,2) NOT NULL,
+    sort_order integer NOT NULL,
+    CONSTRAINT budget_template_line_allocation_amount_check CHECK ((allocation_amount >= (0)::numeric))
+);
+
+
+--
+-- Name: budget_template_line_category; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.budget_template_line_category (
+    template_id bigint NOT NULL,
+    template_line_id bigint NOT NULL,
+    category_id bigint NOT NULL
+);
+
+
+--
+-- Name: budget_template_line_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.budget_template_line ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.budget_template_line_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
 --
 -- Name: category; Type: TABLE; Schema: transactions; Owner: -
 --
@@ -139,7 +422,8 @@ CREATE TABLE transactions.category (
     description text,
     is_active boolean DEFAULT true NOT NULL,
     created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
-    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    version bigint DEFAULT 1 NOT NULL
 );
 
 
@@ -273,6 +557,21 @@ COMMENT ON COLUMN transactions.household_user.created_at IS 'Timestamp when the
 COMMENT ON COLUMN transactions.household_user.updated_at IS 'Timestamp of the last change to this membership record.';
 
 
+--
+-- Name: idempotency_key; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.idempotency_key (
+    key character varying(255) NOT NULL,
+    fingerprint character varying NOT NULL,
+    status_code integer,
+    response_headers jsonb DEFAULT '{}'::jsonb NOT NULL,
+    response_body bytea,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+
+
 --
 -- Name: llm_message; Type: TABLE; Schema: transactions; Owner: -
 --
@@ -330,6 +629,78 @@ ALTER TABLE transactions.llm_session ALTER COLUMN id ADD GENERATED ALWAYS AS IDE
 );
 
 
+--
+-- Name: saved_filter; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.saved_filter (
+    id bigint NOT NULL,
+    name character varying(64) NOT NULL,
+    query text NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+--
+-- Name: saved_filter_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.saved_filter ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.saved_filter_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: savings_goal; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.savings_goal (
+    id bigint NOT NULL,
+    household_id bigint,
+    user_id bigint,
+    name character varying NOT NULL,
+    target_amount numeric(12,2) NOT NULL,
+    start_date date NOT NULL,
+    target_date date NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_savings_goal_exactly_one_owner CHECK ((((household_id IS NOT NULL) AND (user_id IS NULL)) OR ((household_id IS NULL) AND (user_id IS NOT NULL)))),
+    CONSTRAINT chk_savings_goal_target_amount CHECK ((target_amount > (0)::numeric)),
+    CONSTRAINT chk_savings_goal_valid_dates CHECK ((target_date >= start_date))
+);
+
+
+--
+-- Name: savings_goal_category; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.savings_goal_category (
+    goal_id bigint NOT NULL,
+    category_id bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+
+--
+-- Name: savings_goal_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.savings_goal ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.savings_goal_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
 --
 -- Name: schema_migrations; Type: TABLE; Schema: transactions; Owner: -
 --
@@ -339,6 +710,31 @@ CREATE TABLE transactions.schema_migrations (
 );
 
 
+--
+-- Name: tag; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.tag (
+    id bigint NOT NULL,
+    name character varying(64) NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+--
+-- Name: tag_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.tag ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.tag_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
 --
 -- Name: transaction; Type: TABLE; Schema: transactions; Owner: -
 --
@@ -357,7 +753,9 @@ CREATE TABLE transactions.transaction (
     deleted_at timestamp with time zone,
     deleted_by_user_id bigint,
     delete_reason text,
-    category_id bigint
+    category_id bigint,
+    change_seq bigint NOT NULL,
+    version bigint DEFAULT 1 NOT NULL
 );
 
 
@@ -425,10 +823,82 @@ COMMENT ON COLUMN transactions.transaction.notes IS 'Extended details or comment
 
 
 --
--- Name: transaction_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+-- Name: transaction_attachment; Type: TABLE; Schema: transactions; Owner: -
 --
 
-ALTER TABLE transactions.transaction ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+CREATE TABLE transactions.transaction_attachment (
+    id bigint NOT NULL,
+    transaction_id bigint NOT NULL,
+    file_name character varying(255) NOT NULL,
+    content_type character varying NOT NULL,
+    size_bytes bigint NOT NULL,
+    sha256 character(64) NOT NULL,
+    storage_key character varying NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT transaction_attachment_size_bytes_check CHECK ((size_bytes > 0))
+);
+
+
+--
+-- Name: transaction_attachment_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.transaction_attachment ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.transaction_attachment_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: transaction_change_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+CREATE SEQUENCE transactions.transaction_change_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: transaction_history; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.transaction_history (
+    id bigint NOT NULL,
+    transaction_id bigint NOT NULL,
+    action character varying NOT NULL,
+    actor_user_id bigint,
+    changes jsonb DEFAULT '[]'::jsonb NOT NULL,
+    changed_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT transaction_history_action_check CHECK (((action)::text = ANY ((ARRAY['created'::character varying, 'updated'::character varying, 'deleted'::character varying, 'restored'::character varying])::text[])))
+);
+
+
+--
+-- Name: transaction_history_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.transaction_history ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.transaction_history_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: transaction_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.transaction ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
     SEQUENCE NAME transactions.transaction_id_seq
     START WITH 1
     INCREMENT BY 1
@@ -438,6 +908,16 @@ ALTER TABLE transactions.transaction ALTER COLUMN id ADD GENERATED ALWAYS AS IDE
 );
 
 
+--
+-- Name: transaction_tag; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.transaction_tag (
+    transaction_id bigint NOT NULL,
+    tag_id bigint NOT NULL
+);
+
+
 --
 -- Name: users; Type: TABLE; Schema: transactions; Owner: -
 --
@@ -455,434 +935,1130 @@ CREATE TABLE transactions.users (
 
 
 --
--- Name: TABLE users; Type: COMMENT; Schema: transactions; Owner: -
+-- Name: TABLE users; Type: COMMENT; Schema: transactions; Owner: -
+--
+
+COMMENT ON TABLE transactions.users IS 'Stores identity information for individuals linked to Discord accounts.';
+
+
+--
+-- Name: COLUMN users.id; Type: COMMENT; Schema: transactions; Owner: -
+--
+
+COMMENT ON COLUMN transactions.users.id IS 'Internal unique identifier for the user.';
+
+
+--
+-- Name: COLUMN users.discord_id; Type: COMMENT; Schema: transactions; Owner: -
+--
+
+COMMENT ON COLUMN transactions.users.discord_id IS 'The unique ID provided by the Discord API.';
+
+
+--
+-- Name: COLUMN users.name; Type: COMMENT; Schema: transactions; Owner: -
+--
+
+COMMENT ON COLUMN transactions.users.name IS 'The display name or username of the Discord user.';
+
+
+--
+-- Name: COLUMN users.created_at; Type: COMMENT; Schema: transactions; Owner: -
+--
+
+COMMENT ON COLUMN transactions.users.created_at IS 'Timestamp when the user record was first created.';
+
+
+--
+-- Name: COLUMN users.updated_at; Type: COMMENT; Schema: transactions; Owner: -
+--
+
+COMMENT ON COLUMN transactions.users.updated_at IS 'Timestamp of the most recent modification to the user record.';
+
+
+--
+-- Name: users_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.users ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.users_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: webhook_delivery; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.webhook_delivery (
+    id bigint NOT NULL,
+    subscription_id bigint NOT NULL,
+    outbox_id bigint NOT NULL,
+    status character varying DEFAULT 'pending'::character varying NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    next_attempt_at timestamp with time zone,
+    last_error character varying,
+    delivered_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT chk_webhook_delivery_status CHECK (((status)::text = ANY ((ARRAY['pending'::character varying, 'succeeded'::character varying, 'failed'::character varying])::text[])))
+);
+
+
+--
+-- Name: webhook_delivery_attempt; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.webhook_delivery_attempt (
+    id bigint NOT NULL,
+    delivery_id bigint NOT NULL,
+    attempted_at timestamp with time zone NOT NULL,
+    status_code integer,
+    error character varying,
+    duration_ms integer NOT NULL
+);
+
+
+--
+-- Name: webhook_delivery_attempt_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.webhook_delivery_attempt ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.webhook_delivery_attempt_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: webhook_delivery_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.webhook_delivery ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.webhook_delivery_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: webhook_outbox; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.webhook_outbox (
+    id bigint NOT NULL,
+    event_type character varying NOT NULL,
+    household_id bigint,
+    payload jsonb NOT NULL,
+    occurred_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    dispatched_at timestamp with time zone
+);
+
+
+--
+-- Name: webhook_outbox_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.webhook_outbox ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.webhook_outbox_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: webhook_subscription; Type: TABLE; Schema: transactions; Owner: -
+--
+
+CREATE TABLE transactions.webhook_subscription (
+    id bigint NOT NULL,
+    url character varying NOT NULL,
+    event_types text[] DEFAULT '{}'::text[] NOT NULL,
+    household_id bigint,
+    secret character varying NOT NULL,
+    is_active boolean DEFAULT true NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+
+--
+-- Name: webhook_subscription_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+--
+
+ALTER TABLE transactions.webhook_subscription ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME transactions.webhook_subscription_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: budget_alert budget_alert_budget_line_id_threshold_percent_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_alert
+    ADD CONSTRAINT budget_alert_budget_line_id_threshold_percent_key UNIQUE (budget_line_id, threshold_percent);
+
+
+--
+-- Name: budget_alert budget_alert_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_alert
+    ADD CONSTRAINT budget_alert_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: budget_closing_line budget_closing_line_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_closing_line
+    ADD CONSTRAINT budget_closing_line_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: budget_closing budget_closing_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_closing
+    ADD CONSTRAINT budget_closing_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: budget_line_alert_rule budget_line_alert_rule_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_line_alert_rule
+    ADD CONSTRAINT budget_line_alert_rule_pkey PRIMARY KEY (budget_line_id, threshold_percent);
+
+
+--
+-- Name: budget_line budget_line_budget_id_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_line
+    ADD CONSTRAINT budget_line_budget_id_id_key UNIQUE (budget_id, id);
+
+
+--
+-- Name: budget_line budget_line_budget_id_sort_order_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_line
+    ADD CONSTRAINT budget_line_budget_id_sort_order_key UNIQUE (budget_id, sort_order);
+
+
+--
+-- Name: budget_line_category budget_line_category_budget_id_category_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_line_category
+    ADD CONSTRAINT budget_line_category_budget_id_category_id_key UNIQUE (budget_id, category_id);
+
+
+--
+-- Name: budget_line_category budget_line_category_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_line_category
+    ADD CONSTRAINT budget_line_category_pkey PRIMARY KEY (budget_line_id, category_id);
+
+
+--
+-- Name: budget_line_member_allocation budget_line_member_allocation_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_line_member_allocation
+    ADD CONSTRAINT budget_line_member_allocation_pkey PRIMARY KEY (budget_line_id, user_id);
+
+
+--
+-- Name: budget_line budget_line_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_line
+    ADD CONSTRAINT budget_line_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: budget budget_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget
+    ADD CONSTRAINT budget_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: budget excl_budget_household_period_overlap; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget
+    ADD CONSTRAINT excl_budget_household_period_overlap EXCLUDE USING gist (household_id WITH =, period_kind WITH =, daterange(period_start, period_end, '[]'::text) WITH &&) WHERE ((household_id IS NOT NULL));
+
+
+--
+-- Name: budget excl_budget_user_period_overlap; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget
+    ADD CONSTRAINT excl_budget_user_period_overlap EXCLUDE USING gist (user_id WITH =, period_kind WITH =, daterange(period_start, period_end, '[]'::text) WITH &&) WHERE ((user_id IS NOT NULL));
+
+
+--
+-- Name: budget_reallocation budget_reallocation_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_reallocation
+    ADD CONSTRAINT budget_reallocation_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: budget_template_line_category budget_template_line_category_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_template_line_category
+    ADD CONSTRAINT budget_template_line_category_pkey PRIMARY KEY (template_line_id, category_id);
+
+
+--
+-- Name: budget_template_line_category budget_template_line_category_template_id_category_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_template_line_category
+    ADD CONSTRAINT budget_template_line_category_template_id_category_id_key UNIQUE (template_id, category_id);
+
+
+--
+-- Name: budget_template_line budget_template_line_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_template_line
+    ADD CONSTRAINT budget_template_line_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: budget_template_line budget_template_line_template_id_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_template_line
+    ADD CONSTRAINT budget_template_line_template_id_id_key UNIQUE (template_id, id);
+
+
+--
+-- Name: budget_template_line budget_template_line_template_id_sort_order_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_template_line
+    ADD CONSTRAINT budget_template_line_template_id_sort_order_key UNIQUE (template_id, sort_order);
+
+
+--
+-- Name: budget_template budget_template_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_template
+    ADD CONSTRAINT budget_template_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: category category_code_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.category
+    ADD CONSTRAINT category_code_key UNIQUE (code);
+
+
+--
+-- Name: category category_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.category
+    ADD CONSTRAINT category_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: household household_guild_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.household
+    ADD CONSTRAINT household_guild_id_key UNIQUE (guild_id);
+
+
+--
+-- Name: household household_name_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.household
+    ADD CONSTRAINT household_name_key UNIQUE (name);
+
+
+--
+-- Name: household household_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.household
+    ADD CONSTRAINT household_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: household_user household_user_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.household_user
+    ADD CONSTRAINT household_user_pkey PRIMARY KEY (household_id, user_id);
+
+
+--
+-- Name: idempotency_key idempotency_key_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.idempotency_key
+    ADD CONSTRAINT idempotency_key_pkey PRIMARY KEY (key);
+
+
+--
+-- Name: llm_message llm_message_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.llm_message
+    ADD CONSTRAINT llm_message_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: llm_session llm_session_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.llm_session
+    ADD CONSTRAINT llm_session_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: saved_filter saved_filter_name_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.saved_filter
+    ADD CONSTRAINT saved_filter_name_key UNIQUE (name);
+
+
+--
+-- Name: saved_filter saved_filter_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.saved_filter
+    ADD CONSTRAINT saved_filter_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: savings_goal_category savings_goal_category_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.savings_goal_category
+    ADD CONSTRAINT savings_goal_category_pkey PRIMARY KEY (goal_id, category_id);
+
+
+--
+-- Name: savings_goal savings_goal_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.savings_goal
+    ADD CONSTRAINT savings_goal_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: schema_migrations schema_migrations_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.schema_migrations
+    ADD CONSTRAINT schema_migrations_pkey PRIMARY KEY (version);
+
+
+--
+-- Name: tag tag_name_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.tag
+    ADD CONSTRAINT tag_name_key UNIQUE (name);
+
+
+--
+-- Name: tag tag_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.tag
+    ADD CONSTRAINT tag_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: transaction_attachment transaction_attachment_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction_attachment
+    ADD CONSTRAINT transaction_attachment_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: transaction_attachment transaction_attachment_storage_key_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction_attachment
+    ADD CONSTRAINT transaction_attachment_storage_key_key UNIQUE (storage_key);
+
+
+--
+-- Name: transaction_history transaction_history_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction_history
+    ADD CONSTRAINT transaction_history_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: transaction transaction_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction
+    ADD CONSTRAINT transaction_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: transaction_tag transaction_tag_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction_tag
+    ADD CONSTRAINT transaction_tag_pkey PRIMARY KEY (transaction_id, tag_id);
+
+
+--
+-- Name: transaction transaction_transaction_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction
+    ADD CONSTRAINT transaction_transaction_id_key UNIQUE (transaction_id);
+
+
+--
+-- Name: users users_discord_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.users
+    ADD CONSTRAINT users_discord_id_key UNIQUE (discord_id);
+
+
+--
+-- Name: users users_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhook_delivery_attempt webhook_delivery_attempt_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.webhook_delivery_attempt
+    ADD CONSTRAINT webhook_delivery_attempt_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhook_delivery webhook_delivery_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.webhook_delivery
+    ADD CONSTRAINT webhook_delivery_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhook_delivery webhook_delivery_subscription_id_outbox_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.webhook_delivery
+    ADD CONSTRAINT webhook_delivery_subscription_id_outbox_id_key UNIQUE (subscription_id, outbox_id);
+
+
+--
+-- Name: webhook_outbox webhook_outbox_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.webhook_outbox
+    ADD CONSTRAINT webhook_outbox_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhook_subscription webhook_subscription_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.webhook_subscription
+    ADD CONSTRAINT webhook_subscription_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: idx_budget_alert_budget_id; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_budget_alert_budget_id ON transactions.budget_alert USING btree (budget_id);
+
+
+--
+-- Name: idx_budget_alert_created_at; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_budget_alert_created_at ON transactions.budget_alert USING btree (created_at);
+
+
+--
+-- Name: idx_budget_closing_line_closing_id; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_budget_closing_line_closing_id ON transactions.budget_closing_line USING btree (closing_id);
+
+
+--
+-- Name: idx_budget_closing_open_budget_id; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_budget_closing_open_budget_id ON transactions.budget_closing USING btree (budget_id) WHERE (reopened_at IS NULL);
+
+
+--
+-- Name: idx_budget_household_period_start; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_budget_household_period_start ON transactions.budget USING btree (household_id, period_start) WHERE (household_id IS NOT NULL);
+
+
+--
+-- Name: idx_budget_line_budget_id; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_budget_line_budget_id ON transactions.budget_line USING btree (budget_id);
+
+
+--
+-- Name: idx_budget_line_category_budget_id; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_budget_line_category_budget_id ON transactions.budget_line_category USING btree (budget_id);
+
+
+--
+-- Name: idx_budget_line_category_category_id; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_budget_line_category_category_id ON transactions.budget_line_category USING btree (category_id);
+
+
+--
+-- Name: idx_budget_reallocation_budget_id; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_budget_reallocation_budget_id ON transactions.budget_reallocation USING btree (budget_id);
+
+
+--
+-- Name: idx_budget_template_household_name; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_budget_template_household_name ON transactions.budget_template USING btree (household_id, lower((name)::text)) WHERE (household_id IS NOT NULL);
+
+
+--
+-- Name: idx_budget_template_line_category_category_id; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_budget_template_line_category_category_id ON transactions.budget_template_line_category USING btree (category_id);
+
+
+--
+-- Name: idx_budget_template_user_name; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE UNIQUE INDEX idx_budget_template_user_name ON transactions.budget_template USING btree (user_id, lower((name)::text)) WHERE (user_id IS NOT NULL);
+
+
+--
+-- Name: idx_budget_user_period_start; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_budget_user_period_start ON transactions.budget USING btree (user_id, period_start) WHERE (user_id IS NOT NULL);
+
+
+--
+-- Name: idx_category_code; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_category_code ON transactions.category USING btree (code);
+
+
+--
+-- Name: idx_category_is_active; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_category_is_active ON transactions.category USING btree (is_active);
+
+
+--
+-- Name: idx_household_guild_id; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_household_guild_id ON transactions.household USING btree (guild_id);
+
+
+--
+-- Name: idx_household_user_household_id; Type: INDEX; Schema: transactions; Owner: -
+--
+
+CREATE INDEX idx_household_user_household_id ON transactions.household_user USING btree (household_id);
+
+
+--
+-- Name: idx_household_user_user_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-COMMENT ON TABLE transactions.users IS 'Stores identity information for individuals linked to Discord accounts.';
+CREATE INDEX idx_household_user_user_id ON transactions.household_user USING btree (user_id);
 
 
 --
--- Name: COLUMN users.id; Type: COMMENT; Schema: transactions; Owner: -
+-- Name: idx_idempotency_key_expires_at; Type: INDEX; Schema: transactions; Owner: -
 --
 
-COMMENT ON COLUMN transactions.users.id IS 'Internal unique identifier for the user.';
+CREATE INDEX idx_idempotency_key_expires_at ON transactions.idempotency_key USING btree (expires_at);
 
 
 --
--- Name: COLUMN users.discord_id; Type: COMMENT; Schema: transactions; Owner: -
+-- Name: idx_llm_message_created_at; Type: INDEX; Schema: transactions; Owner: -
 --
 
-COMMENT ON COLUMN transactions.users.discord_id IS 'The unique ID provided by the Discord API.';
+CREATE INDEX idx_llm_message_created_at ON transactions.llm_message USING btree (created_at);
 
 
 --
--- Name: COLUMN users.name; Type: COMMENT; Schema: transactions; Owner: -
+-- Name: idx_llm_message_session_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-COMMENT ON COLUMN transactions.users.name IS 'The display name or username of the Discord user.';
+CREATE INDEX idx_llm_message_session_id ON transactions.llm_message USING btree (session_id);
 
 
 --
--- Name: COLUMN users.created_at; Type: COMMENT; Schema: transactions; Owner: -
+-- Name: idx_llm_session_source_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-COMMENT ON COLUMN transactions.users.created_at IS 'Timestamp when the user record was first created.';
+CREATE INDEX idx_llm_session_source_id ON transactions.llm_session USING btree (source_id);
 
 
 --
--- Name: COLUMN users.updated_at; Type: COMMENT; Schema: transactions; Owner: -
+-- Name: idx_llm_session_user_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-COMMENT ON COLUMN transactions.users.updated_at IS 'Timestamp of the most recent modification to the user record.';
+CREATE INDEX idx_llm_session_user_id ON transactions.llm_session USING btree (user_id);
 
 
 --
--- Name: users_id_seq; Type: SEQUENCE; Schema: transactions; Owner: -
+-- Name: idx_savings_goal_category_category_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE transactions.users ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
-    SEQUENCE NAME transactions.users_id_seq
-    START WITH 1
-    INCREMENT BY 1
-    NO MINVALUE
-    NO MAXVALUE
-    CACHE 1
-);
+CREATE INDEX idx_savings_goal_category_category_id ON transactions.savings_goal_category USING btree (category_id);
 
 
 --
--- Name: budget_line budget_line_budget_id_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_savings_goal_household_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget_line
-    ADD CONSTRAINT budget_line_budget_id_id_key UNIQUE (budget_id, id);
+CREATE INDEX idx_savings_goal_household_id ON transactions.savings_goal USING btree (household_id) WHERE (household_id IS NOT NULL);
 
 
 --
--- Name: budget_line budget_line_budget_id_sort_order_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_savings_goal_user_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget_line
-    ADD CONSTRAINT budget_line_budget_id_sort_order_key UNIQUE (budget_id, sort_order);
+CREATE INDEX idx_savings_goal_user_id ON transactions.savings_goal USING btree (user_id) WHERE (user_id IS NOT NULL);
 
 
 --
--- Name: budget_line_category budget_line_category_budget_id_category_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_transaction_attachment_transaction_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget_line_category
-    ADD CONSTRAINT budget_line_category_budget_id_category_id_key UNIQUE (budget_id, category_id);
+CREATE INDEX idx_transaction_attachment_transaction_id ON transactions.transaction_attachment USING btree (transaction_id);
 
 
 --
--- Name: budget_line_category budget_line_category_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_transaction_author_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget_line_category
-    ADD CONSTRAINT budget_line_category_pkey PRIMARY KEY (budget_line_id, category_id);
+CREATE INDEX idx_transaction_author_id ON transactions.transaction USING btree (author_id);
 
 
 --
--- Name: budget_line budget_line_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_transaction_category_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget_line
-    ADD CONSTRAINT budget_line_pkey PRIMARY KEY (id);
+CREATE INDEX idx_transaction_category_id ON transactions.transaction USING btree (category_id);
 
 
 --
--- Name: budget budget_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_transaction_deleted_at; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget
-    ADD CONSTRAINT budget_pkey PRIMARY KEY (id);
+CREATE INDEX idx_transaction_deleted_at ON transactions.transaction USING btree (deleted_at);
 
 
 --
--- Name: category category_code_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_transaction_change_seq; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.category
-    ADD CONSTRAINT category_code_key UNIQUE (code);
+CREATE UNIQUE INDEX idx_transaction_change_seq ON transactions.transaction USING btree (change_seq);
 
 
 --
--- Name: category category_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_transaction_history_transaction_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.category
-    ADD CONSTRAINT category_pkey PRIMARY KEY (id);
+CREATE INDEX idx_transaction_history_transaction_id ON transactions.transaction_history USING btree (transaction_id, id);
 
 
 --
--- Name: household household_guild_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_transaction_household_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.household
-    ADD CONSTRAINT household_guild_id_key UNIQUE (guild_id);
+CREATE INDEX idx_transaction_household_id ON transactions.transaction USING btree (household_id);
 
 
 --
--- Name: household household_name_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_transaction_search_trigram; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.household
-    ADD CONSTRAINT household_name_key UNIQUE (name);
+CREATE INDEX idx_transaction_search_trigram ON transactions.transaction USING gin ((((COALESCE(description, ''::character varying))::text || ' '::text) || COALESCE(notes, ''::text)) public.gin_trgm_ops);
 
 
 --
--- Name: household household_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_transaction_search_vector; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.household
-    ADD CONSTRAINT household_pkey PRIMARY KEY (id);
+CREATE INDEX idx_transaction_search_vector ON transactions.transaction USING gin (to_tsvector('simple'::regconfig, (((COALESCE(description, ''::character varying))::text || ' '::text) || COALESCE(notes, ''::text))));
 
 
 --
--- Name: household_user household_user_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_transaction_tag_tag_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.household_user
-    ADD CONSTRAINT household_user_pkey PRIMARY KEY (household_id, user_id);
+CREATE INDEX idx_transaction_tag_tag_id ON transactions.transaction_tag USING btree (tag_id);
 
 
 --
--- Name: llm_message llm_message_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_users_discord_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.llm_message
-    ADD CONSTRAINT llm_message_pkey PRIMARY KEY (id);
+CREATE INDEX idx_users_discord_id ON transactions.users USING btree (discord_id);
 
 
 --
--- Name: llm_session llm_session_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_users_discord_id_unique_not_null; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.llm_session
-    ADD CONSTRAINT llm_session_pkey PRIMARY KEY (id);
+CREATE UNIQUE INDEX idx_users_discord_id_unique_not_null ON transactions.users USING btree (discord_id) WHERE (discord_id IS NOT NULL);
 
 
 --
--- Name: schema_migrations schema_migrations_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_users_phone_number_unique_not_null; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.schema_migrations
-    ADD CONSTRAINT schema_migrations_pkey PRIMARY KEY (version);
+CREATE UNIQUE INDEX idx_users_phone_number_unique_not_null ON transactions.users USING btree (phone_number) WHERE (phone_number IS NOT NULL);
 
 
 --
--- Name: transaction transaction_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_users_telegram_id_unique_not_null; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.transaction
-    ADD CONSTRAINT transaction_pkey PRIMARY KEY (id);
+CREATE UNIQUE INDEX idx_users_telegram_id_unique_not_null ON transactions.users USING btree (telegram_id) WHERE (telegram_id IS NOT NULL);
 
 
 --
--- Name: transaction transaction_transaction_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_users_whatsapp_id_unique_not_null; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.transaction
-    ADD CONSTRAINT transaction_transaction_id_key UNIQUE (transaction_id);
+CREATE UNIQUE INDEX idx_users_whatsapp_id_unique_not_null ON transactions.users USING btree (whatsapp_id) WHERE (whatsapp_id IS NOT NULL);
 
 
 --
--- Name: users users_discord_id_key; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_webhook_delivery_attempt_delivery_id; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.users
-    ADD CONSTRAINT users_discord_id_key UNIQUE (discord_id);
+CREATE INDEX idx_webhook_delivery_attempt_delivery_id ON transactions.webhook_delivery_attempt USING btree (delivery_id);
 
 
 --
--- Name: users users_pkey; Type: CONSTRAINT; Schema: transactions; Owner: -
+-- Name: idx_webhook_delivery_due; Type: INDEX; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.users
-    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+CREATE INDEX idx_webhook_delivery_due ON transactions.webhook_delivery USING btree (next_attempt_at) WHERE ((status)::text = 'pending'::text);
 
 
 --
--- Name: idx_budget_household_period; Type: INDEX; Schema: transactions; Owner: -
+-- Name: idx_webhook_outbox_undispatched; Type: INDEX; Schema: transactions; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_budget_household_period ON transactions.budget USING btree (household_id, period_start, period_end) WHERE (household_id IS NOT NULL);
+CREATE INDEX idx_webhook_outbox_undispatched ON transactions.webhook_outbox USING btree (id) WHERE (dispatched_at IS NULL);
 
 
 --
--- Name: idx_budget_household_period_start; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_line budget_line_bump_version; Type: TRIGGER; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_budget_household_period_start ON transactions.budget USING btree (household_id, period_start) WHERE (household_id IS NOT NULL);
+CREATE TRIGGER budget_line_bump_version BEFORE UPDATE ON transactions.budget_line FOR EACH ROW EXECUTE FUNCTION transactions.bump_row_version();
 
 
 --
--- Name: idx_budget_line_budget_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: category category_bump_version; Type: TRIGGER; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_budget_line_budget_id ON transactions.budget_line USING btree (budget_id);
+CREATE TRIGGER category_bump_version BEFORE UPDATE ON transactions.category FOR EACH ROW EXECUTE FUNCTION transactions.bump_row_version();
 
 
 --
--- Name: idx_budget_line_category_budget_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: transaction transaction_bump_version; Type: TRIGGER; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_budget_line_category_budget_id ON transactions.budget_line_category USING btree (budget_id);
+CREATE TRIGGER transaction_bump_version BEFORE UPDATE ON transactions.transaction FOR EACH ROW EXECUTE FUNCTION transactions.bump_row_version();
 
 
 --
--- Name: idx_budget_line_category_category_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: transaction transaction_record_change; Type: TRIGGER; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_budget_line_category_category_id ON transactions.budget_line_category USING btree (category_id);
+CREATE TRIGGER transaction_record_change BEFORE INSERT OR UPDATE ON transactions.transaction FOR EACH ROW EXECUTE FUNCTION transactions.transaction_record_change();
 
 
 --
--- Name: idx_budget_user_period; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_alert budget_alert_budget_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_budget_user_period ON transactions.budget USING btree (user_id, period_start, period_end) WHERE (user_id IS NOT NULL);
+ALTER TABLE ONLY transactions.budget_alert
+    ADD CONSTRAINT budget_alert_budget_id_fkey FOREIGN KEY (budget_id) REFERENCES transactions.budget(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_budget_user_period_start; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_alert budget_alert_budget_line_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_budget_user_period_start ON transactions.budget USING btree (user_id, period_start) WHERE (user_id IS NOT NULL);
+ALTER TABLE ONLY transactions.budget_alert
+    ADD CONSTRAINT budget_alert_budget_line_id_fkey FOREIGN KEY (budget_line_id) REFERENCES transactions.budget_line(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_category_code; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_alert budget_alert_transaction_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_category_code ON transactions.category USING btree (code);
+ALTER TABLE ONLY transactions.budget_alert
+    ADD CONSTRAINT budget_alert_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions.transaction(id) ON DELETE SET NULL;
 
 
 --
--- Name: idx_category_is_active; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_closing budget_closing_budget_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_category_is_active ON transactions.category USING btree (is_active);
+ALTER TABLE ONLY transactions.budget_closing
+    ADD CONSTRAINT budget_closing_budget_id_fkey FOREIGN KEY (budget_id) REFERENCES transactions.budget(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_household_guild_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_closing budget_closing_closed_by_user_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_household_guild_id ON transactions.household USING btree (guild_id);
+ALTER TABLE ONLY transactions.budget_closing
+    ADD CONSTRAINT budget_closing_closed_by_user_id_fkey FOREIGN KEY (closed_by_user_id) REFERENCES transactions.users(id);
 
 
 --
--- Name: idx_household_user_household_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_closing_line budget_closing_line_budget_line_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_household_user_household_id ON transactions.household_user USING btree (household_id);
+ALTER TABLE ONLY transactions.budget_closing_line
+    ADD CONSTRAINT budget_closing_line_budget_line_id_fkey FOREIGN KEY (budget_line_id) REFERENCES transactions.budget_line(id) ON DELETE SET NULL;
 
 
 --
--- Name: idx_household_user_user_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_closing_line budget_closing_line_closing_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_household_user_user_id ON transactions.household_user USING btree (user_id);
+ALTER TABLE ONLY transactions.budget_closing_line
+    ADD CONSTRAINT budget_closing_line_closing_id_fkey FOREIGN KEY (closing_id) REFERENCES transactions.budget_closing(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_llm_message_created_at; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_closing budget_closing_reopened_by_user_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_llm_message_created_at ON transactions.llm_message USING btree (created_at);
+ALTER TABLE ONLY transactions.budget_closing
+    ADD CONSTRAINT budget_closing_reopened_by_user_id_fkey FOREIGN KEY (reopened_by_user_id) REFERENCES transactions.users(id);
 
 
 --
--- Name: idx_llm_message_session_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget budget_household_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_llm_message_session_id ON transactions.llm_message USING btree (session_id);
+ALTER TABLE ONLY transactions.budget
+    ADD CONSTRAINT budget_household_id_fkey FOREIGN KEY (household_id) REFERENCES transactions.household(id);
 
 
 --
--- Name: idx_llm_session_source_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_line_alert_rule budget_line_alert_rule_budget_line_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_llm_session_source_id ON transactions.llm_session USING btree (source_id);
+ALTER TABLE ONLY transactions.budget_line_alert_rule
+    ADD CONSTRAINT budget_line_alert_rule_budget_line_id_fkey FOREIGN KEY (budget_line_id) REFERENCES transactions.budget_line(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_llm_session_user_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_line budget_line_budget_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_llm_session_user_id ON transactions.llm_session USING btree (user_id);
+ALTER TABLE ONLY transactions.budget_line
+    ADD CONSTRAINT budget_line_budget_id_fkey FOREIGN KEY (budget_id) REFERENCES transactions.budget(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_transaction_author_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_line_category budget_line_category_budget_id_budget_line_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_transaction_author_id ON transactions.transaction USING btree (author_id);
+ALTER TABLE ONLY transactions.budget_line_category
+    ADD CONSTRAINT budget_line_category_budget_id_budget_line_id_fkey FOREIGN KEY (budget_id, budget_line_id) REFERENCES transactions.budget_line(budget_id, id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_transaction_category_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_line_category budget_line_category_budget_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_transaction_category_id ON transactions.transaction USING btree (category_id);
+ALTER TABLE ONLY transactions.budget_line_category
+    ADD CONSTRAINT budget_line_category_budget_id_fkey FOREIGN KEY (budget_id) REFERENCES transactions.budget(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_transaction_deleted_at; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_line_category budget_line_category_category_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_transaction_deleted_at ON transactions.transaction USING btree (deleted_at);
+ALTER TABLE ONLY transactions.budget_line_category
+    ADD CONSTRAINT budget_line_category_category_id_fkey FOREIGN KEY (category_id) REFERENCES transactions.category(id);
 
 
 --
--- Name: idx_transaction_household_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_line_member_allocation budget_line_member_allocation_budget_line_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_transaction_household_id ON transactions.transaction USING btree (household_id);
+ALTER TABLE ONLY transactions.budget_line_member_allocation
+    ADD CONSTRAINT budget_line_member_allocation_budget_line_id_fkey FOREIGN KEY (budget_line_id) REFERENCES transactions.budget_line(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_users_discord_id; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_line_member_allocation budget_line_member_allocation_user_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE INDEX idx_users_discord_id ON transactions.users USING btree (discord_id);
+ALTER TABLE ONLY transactions.budget_line_member_allocation
+    ADD CONSTRAINT budget_line_member_allocation_user_id_fkey FOREIGN KEY (user_id) REFERENCES transactions.users(id);
 
 
 --
--- Name: idx_users_discord_id_unique_not_null; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_reallocation budget_reallocation_budget_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_users_discord_id_unique_not_null ON transactions.users USING btree (discord_id) WHERE (discord_id IS NOT NULL);
+ALTER TABLE ONLY transactions.budget_reallocation
+    ADD CONSTRAINT budget_reallocation_budget_id_fkey FOREIGN KEY (budget_id) REFERENCES transactions.budget(id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_users_phone_number_unique_not_null; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_reallocation budget_reallocation_budget_id_from_line_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_users_phone_number_unique_not_null ON transactions.users USING btree (phone_number) WHERE (phone_number IS NOT NULL);
+ALTER TABLE ONLY transactions.budget_reallocation
+    ADD CONSTRAINT budget_reallocation_budget_id_from_line_id_fkey FOREIGN KEY (budget_id, from_line_id) REFERENCES transactions.budget_line(budget_id, id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_users_telegram_id_unique_not_null; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_reallocation budget_reallocation_budget_id_to_line_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_users_telegram_id_unique_not_null ON transactions.users USING btree (telegram_id) WHERE (telegram_id IS NOT NULL);
+ALTER TABLE ONLY transactions.budget_reallocation
+    ADD CONSTRAINT budget_reallocation_budget_id_to_line_id_fkey FOREIGN KEY (budget_id, to_line_id) REFERENCES transactions.budget_line(budget_id, id) ON DELETE CASCADE;
 
 
 --
--- Name: idx_users_whatsapp_id_unique_not_null; Type: INDEX; Schema: transactions; Owner: -
+-- Name: budget_reallocation budget_reallocation_created_by_user_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-CREATE UNIQUE INDEX idx_users_whatsapp_id_unique_not_null ON transactions.users USING btree (whatsapp_id) WHERE (whatsapp_id IS NOT NULL);
+ALTER TABLE ONLY transactions.budget_reallocation
+    ADD CONSTRAINT budget_reallocation_created_by_user_id_fkey FOREIGN KEY (created_by_user_id) REFERENCES transactions.users(id);
 
 
 --
--- Name: budget budget_household_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+-- Name: budget budget_source_budget_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
 ALTER TABLE ONLY transactions.budget
-    ADD CONSTRAINT budget_household_id_fkey FOREIGN KEY (household_id) REFERENCES transactions.household(id);
+    ADD CONSTRAINT budget_source_budget_id_fkey FOREIGN KEY (source_budget_id) REFERENCES transactions.budget(id);
 
 
 --
--- Name: budget_line budget_line_budget_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+-- Name: budget_template budget_template_household_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget_line
-    ADD CONSTRAINT budget_line_budget_id_fkey FOREIGN KEY (budget_id) REFERENCES transactions.budget(id) ON DELETE CASCADE;
+ALTER TABLE ONLY transactions.budget_template
+    ADD CONSTRAINT budget_template_household_id_fkey FOREIGN KEY (household_id) REFERENCES transactions.household(id);
 
 
 --
--- Name: budget_line_category budget_line_category_budget_id_budget_line_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+-- Name: budget_template_line_category budget_template_line_category_category_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget_line_category
-    ADD CONSTRAINT budget_line_category_budget_id_budget_line_id_fkey FOREIGN KEY (budget_id, budget_line_id) REFERENCES transactions.budget_line(budget_id, id) ON DELETE CASCADE;
+ALTER TABLE ONLY transactions.budget_template_line_category
+    ADD CONSTRAINT budget_template_line_category_category_id_fkey FOREIGN KEY (category_id) REFERENCES transactions.category(id);
 
 
 --
--- Name: budget_line_category budget_line_category_budget_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+-- Name: budget_template_line_category budget_template_line_category_template_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget_line_category
-    ADD CONSTRAINT budget_line_category_budget_id_fkey FOREIGN KEY (budget_id) REFERENCES transactions.budget(id) ON DELETE CASCADE;
+ALTER TABLE ONLY transactions.budget_template_line_category
+    ADD CONSTRAINT budget_template_line_category_template_id_fkey FOREIGN KEY (template_id) REFERENCES transactions.budget_template(id) ON DELETE CASCADE;
 
 
 --
--- Name: budget_line_category budget_line_category_category_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+-- Name: budget_template_line_category budget_template_line_category_template_id_template_line_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget_line_category
-    ADD CONSTRAINT budget_line_category_category_id_fkey FOREIGN KEY (category_id) REFERENCES transactions.category(id);
+ALTER TABLE ONLY transactions.budget_template_line_category
+    ADD CONSTRAINT budget_template_line_category_template_id_template_line_id_fkey FOREIGN KEY (template_id, template_line_id) REFERENCES transactions.budget_template_line(template_id, id) ON DELETE CASCADE;
 
 
 --
--- Name: budget budget_source_budget_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+-- Name: budget_template_line budget_template_line_template_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
 
-ALTER TABLE ONLY transactions.budget
-    ADD CONSTRAINT budget_source_budget_id_fkey FOREIGN KEY (source_budget_id) REFERENCES transactions.budget(id);
+ALTER TABLE ONLY transactions.budget_template_line
+    ADD CONSTRAINT budget_template_line_template_id_fkey FOREIGN KEY (template_id) REFERENCES transactions.budget_template(id) ON DELETE CASCADE;
+
+
+--
+-- Name: budget_template budget_template_user_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.budget_template
+    ADD CONSTRAINT budget_template_user_id_fkey FOREIGN KEY (user_id) REFERENCES transactions.users(id);
 
 
 --
@@ -941,6 +2117,46 @@ ALTER TABLE ONLY transactions.llm_session
     ADD CONSTRAINT llm_session_user_id_fkey FOREIGN KEY (user_id) REFERENCES transactions.users(id);
 
 
+--
+-- Name: savings_goal_category savings_goal_category_category_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.savings_goal_category
+    ADD CONSTRAINT savings_goal_category_category_id_fkey FOREIGN KEY (category_id) REFERENCES transactions.category(id);
+
+
+--
+-- Name: savings_goal_category savings_goal_category_goal_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.savings_goal_category
+    ADD CONSTRAINT savings_goal_category_goal_id_fkey FOREIGN KEY (goal_id) REFERENCES transactions.savings_goal(id) ON DELETE CASCADE;
+
+
+--
+-- Name: savings_goal savings_goal_household_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.savings_goal
+    ADD CONSTRAINT savings_goal_household_id_fkey FOREIGN KEY (household_id) REFERENCES transactions.household(id);
+
+
+--
+-- Name: savings_goal savings_goal_user_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.savings_goal
+    ADD CONSTRAINT savings_goal_user_id_fkey FOREIGN KEY (user_id) REFERENCES transactions.users(id);
+
+
+--
+-- Name: transaction_attachment transaction_attachment_transaction_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction_attachment
+    ADD CONSTRAINT transaction_attachment_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions.transaction(id) ON DELETE CASCADE;
+
+
 --
 -- Name: transaction transaction_author_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
@@ -965,6 +2181,22 @@ ALTER TABLE ONLY transactions.transaction
     ADD CONSTRAINT transaction_deleted_by_user_id_fkey FOREIGN KEY (deleted_by_user_id) REFERENCES transactions.users(id);
 
 
+--
+-- Name: transaction_history transaction_history_actor_user_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction_history
+    ADD CONSTRAINT transaction_history_actor_user_id_fkey FOREIGN KEY (actor_user_id) REFERENCES transactions.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: transaction_history transaction_history_transaction_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction_history
+    ADD CONSTRAINT transaction_history_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions.transaction(id) ON DELETE CASCADE;
+
+
 --
 -- Name: transaction transaction_household_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
 --
@@ -973,6 +2205,54 @@ ALTER TABLE ONLY transactions.transaction
     ADD CONSTRAINT transaction_household_id_fkey FOREIGN KEY (household_id) REFERENCES transactions.household(id);
 
 
+--
+-- Name: transaction_tag transaction_tag_tag_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction_tag
+    ADD CONSTRAINT transaction_tag_tag_id_fkey FOREIGN KEY (tag_id) REFERENCES transactions.tag(id) ON DELETE CASCADE;
+
+
+--
+-- Name: transaction_tag transaction_tag_transaction_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.transaction_tag
+    ADD CONSTRAINT transaction_tag_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions.transaction(id) ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_delivery_attempt webhook_delivery_attempt_delivery_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.webhook_delivery_attempt
+    ADD CONSTRAINT webhook_delivery_attempt_delivery_id_fkey FOREIGN KEY (delivery_id) REFERENCES transactions.webhook_delivery(id) ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_delivery webhook_delivery_outbox_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.webhook_delivery
+    ADD CONSTRAINT webhook_delivery_outbox_id_fkey FOREIGN KEY (outbox_id) REFERENCES transactions.webhook_outbox(id) ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_delivery webhook_delivery_subscription_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.webhook_delivery
+    ADD CONSTRAINT webhook_delivery_subscription_id_fkey FOREIGN KEY (subscription_id) REFERENCES transactions.webhook_subscription(id) ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_subscription webhook_subscription_household_id_fkey; Type: FK CONSTRAINT; Schema: transactions; Owner: -
+--
+
+ALTER TABLE ONLY transactions.webhook_subscription
+    ADD CONSTRAINT webhook_subscription_household_id_fkey FOREIGN KEY (household_id) REFERENCES transactions.household(id) ON DELETE CASCADE;
+
+
 --
 -- PostgreSQL database dump complete
 --
@@ -989,4 +2269,20 @@ INSERT INTO transactions.schema_migrations (version) VALUES
     ('20260120030638'),
     ('20260505000000'),
     ('20260508000000'),
-    ('20260510000000');
+    ('20260510000000'),
+    ('20260601000000'),
+    ('20260602000000'),
+    ('20260603000000'),
+    ('20260604000000'),
+    ('20260605000000'),
+    ('20260606000000'),
+    ('20260607000000'),
+    ('20260608000000'),
+    ('20260609000000'),
+    ('20260610000000'),
+    ('20260611000000'),
+    ('20260612000000'),
+    ('20260613000000'),
+    ('20260614000000'),
+    ('20260615000000'),
+    ('20260616000000');
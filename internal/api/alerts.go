@@ -0,0 +1,32 @@
+package api
+
+import "time"
+
+// BudgetAlert records that a budget line's actual spend reached one of its
+// alert thresholds. A line alerts at most once per threshold in its period.
+type BudgetAlert struct {
+	ID                int64      `json:"id"`
+	BudgetID          int64      `json:"budgetId"`
+	BudgetLineID      int64      `json:"budgetLineId"`
+	BudgetLineName    string     `json:"budgetLineName"`
+	HouseholdID       *int64     `json:"householdId,omitempty"`
+	UserID            *int64     `json:"userId,omitempty"`
+	PeriodKind        string     `json:"periodKind"`
+	PeriodStart       time.Time  `json:"periodStart"`
+	PeriodEnd         time.Time  `json:"periodEnd"`
+	ThresholdPercent  int32      `json:"thresholdPercent"`
+	AllocationAmount  string     `json:"allocationAmount"`
+	ActualAmount      string     `json:"actualAmount"`
+	TransactionID     *int64     `json:"transactionId,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	NotifiedAt        *time.Time `json:"notifiedAt,omitempty"`
+	NotificationError *string    `json:"notificationError,omitempty"`
+}
+
+// AlertQuery scopes alert reads, newest first. Limit defaults to 50.
+type AlertQuery struct {
+	HouseholdID *int64 `query:"householdId"`
+	UserID      *int64 `query:"userId"`
+	BudgetID    *int64 `query:"budgetId"`
+	Limit       int    `query:"limit"`
+}
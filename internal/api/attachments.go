@@ -0,0 +1,19 @@
+package api
+
+import "time"
+
+// AttachmentFileField is the multipart form field that carries an uploaded
+// attachment.
+const AttachmentFileField = "file"
+
+// Attachment is a receipt or document stored with a transaction. Size is in
+// bytes and SHA256 is the hex digest of the contents.
+type Attachment struct {
+	ID            int64     `json:"id"`
+	TransactionID int64     `json:"transactionId"`
+	FileName      string    `json:"fileName"`
+	ContentType   string    `json:"contentType"`
+	Size          int64     `json:"size"`
+	SHA256        string    `json:"sha256"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
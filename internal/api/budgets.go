@@ -9,17 +9,36 @@ type MonthlyBudgetQuery struct {
 	Month       int    `query:"month"`
 }
 
+// EnsureMonthlyBudgetRequest creates a missing budget from the named Template
+// when set and from the latest prior monthly budget otherwise.
 type EnsureMonthlyBudgetRequest struct {
 	HouseholdID *int64 `json:"householdId,omitempty"`
 	UserID      *int64 `json:"userId,omitempty"`
 	Year        int    `json:"year"`
 	Month       int    `json:"month"`
+	Template    string `json:"template,omitempty"`
+}
+
+// BudgetPeriodQuery selects the budget of one period kind for GET and PUT
+// /v1/budgets. Dates use YYYY-MM-DD; biweekly periods need Anchor and custom
+// periods use Start and End instead of Date. Template only applies to PUT and
+// names the budget template a missing budget is created from.
+type BudgetPeriodQuery struct {
+	HouseholdID *int64 `query:"householdId"`
+	UserID      *int64 `query:"userId"`
+	Period      string `query:"period"`
+	Date        string `query:"date"`
+	Anchor      string `query:"anchor"`
+	Start       string `query:"start"`
+	End         string `query:"end"`
+	Template    string `query:"template"`
 }
 
 type Budget struct {
 	ID             int64        `json:"id"`
 	HouseholdID    *int64       `json:"householdId,omitempty"`
 	UserID         *int64       `json:"userId,omitempty"`
+	PeriodKind     string       `json:"periodKind"`
 	PeriodStart    time.Time    `json:"periodStart"`
 	PeriodEnd      time.Time    `json:"periodEnd"`
 	SourceBudgetID *int64       `json:"sourceBudgetId,omitempty"`
@@ -33,9 +52,145 @@ type BudgetLine struct {
 	AllocationAmount string        `json:"allocationAmount"`
 	SortOrder        int32         `json:"sortOrder"`
 	Categories       []CategoryRef `json:"categories"`
+	AlertThresholds  []int32       `json:"alertThresholds"`
+	// MemberAllocations splits a household line between members.
+	MemberAllocations []BudgetMemberAllocation `json:"memberAllocations"`
+	// Version changes on every write to the line and is also its ETag.
+	Version int64 `json:"version"`
+}
+
+// BudgetMemberAllocation is one member's share of a line, either a fixed
+// Amount or a Percent of the line allocation.
+type BudgetMemberAllocation struct {
+	UserID   int64   `json:"userId"`
+	UserName string  `json:"userName"`
+	Amount   *string `json:"amount,omitempty"`
+	Percent  *string `json:"percent,omitempty"`
+}
+
+// BudgetMemberAllocationInput sets exactly one of Amount and Percent.
+type BudgetMemberAllocationInput struct {
+	UserID  int64   `json:"userId"`
+	Amount  *string `json:"amount,omitempty"`
+	Percent *string `json:"percent,omitempty"`
 }
 
 type CreateBudgetLineRequest struct {
+	Name              string                        `json:"name"`
+	AllocationAmount  string                        `json:"allocationAmount"`
+	CategoryIDs       []int64                       `json:"categoryIds,omitempty"`
+	CategoryCodes     []string                      `json:"categoryCodes,omitempty"`
+	SortOrder         *int32                        `json:"sortOrder,omitempty"`
+	AlertThresholds   []int32                       `json:"alertThresholds,omitempty"`
+	MemberAllocations []BudgetMemberAllocationInput `json:"memberAllocations,omitempty"`
+}
+
+type UpdateBudgetLineRequest struct {
+	Name              *string                        `json:"name,omitempty"`
+	AllocationAmount  *string                        `json:"allocationAmount,omitempty"`
+	CategoryIDs       *[]int64                       `json:"categoryIds,omitempty"`
+	CategoryCodes     *[]string                      `json:"categoryCodes,omitempty"`
+	SortOrder         *int32                         `json:"sortOrder,omitempty"`
+	AlertThresholds   *[]int32                       `json:"alertThresholds,omitempty"`
+	MemberAllocations *[]BudgetMemberAllocationInput `json:"memberAllocations,omitempty"`
+}
+
+// ReallocateBudgetRequest moves Amount of allocation between two lines of the
+// same budget. UserID identifies who made the move for the audit trail.
+type ReallocateBudgetRequest struct {
+	FromLineID int64   `json:"fromLineId"`
+	ToLineID   int64   `json:"toLineId"`
+	Amount     string  `json:"amount"`
+	Reason     *string `json:"reason,omitempty"`
+	UserID     int64   `json:"userId"`
+}
+
+type BudgetReallocation struct {
+	ID                int64         `json:"id"`
+	BudgetID          int64         `json:"budgetId"`
+	FromLine          BudgetLineRef `json:"fromLine"`
+	ToLine            BudgetLineRef `json:"toLine"`
+	Amount            string        `json:"amount"`
+	Reason            *string       `json:"reason,omitempty"`
+	CreatedByUserID   int64         `json:"createdByUserId"`
+	CreatedByUserName string        `json:"createdByUserName"`
+	CreatedAt         time.Time     `json:"createdAt"`
+}
+
+type BudgetLineRef struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// BudgetReallocationResult is the recorded move with both lines as adjusted.
+type BudgetReallocationResult struct {
+	Reallocation BudgetReallocation `json:"reallocation"`
+	FromLine     BudgetLine         `json:"fromLine"`
+	ToLine       BudgetLine         `json:"toLine"`
+}
+
+// CloseBudgetRequest closes a budget on behalf of UserID.
+type CloseBudgetRequest struct {
+	UserID int64 `json:"userId"`
+}
+
+// ReopenBudgetRequest reopens a closed budget. Reason is required and kept on
+// the closing record.
+type ReopenBudgetRequest struct {
+	UserID int64  `json:"userId"`
+	Reason string `json:"reason"`
+}
+
+// BudgetClosing is a budget's report frozen at close time. The reopen fields
+// are present once the budget has been reopened.
+type BudgetClosing struct {
+	ID                 int64               `json:"id"`
+	BudgetID           int64               `json:"budgetId"`
+	Lines              []BudgetClosingLine `json:"lines"`
+	Totals             BudgetReportTotals  `json:"totals"`
+	ClosedByUserID     int64               `json:"closedByUserId"`
+	ClosedByUserName   string              `json:"closedByUserName"`
+	ClosedAt           time.Time           `json:"closedAt"`
+	ReopenedByUserID   *int64              `json:"reopenedByUserId,omitempty"`
+	ReopenedByUserName *string             `json:"reopenedByUserName,omitempty"`
+	ReopenedAt         *time.Time          `json:"reopenedAt,omitempty"`
+	ReopenReason       *string             `json:"reopenReason,omitempty"`
+}
+
+// BudgetClosingLine is a line as it stood at close time. LineID is omitted
+// when the line was deleted after the budget was reopened.
+type BudgetClosingLine struct {
+	LineID           *int64 `json:"lineId,omitempty"`
+	Name             string `json:"name"`
+	AllocationAmount string `json:"allocationAmount"`
+	ActualAmount     string `json:"actualAmount"`
+	SortOrder        int32  `json:"sortOrder"`
+}
+
+// BudgetTemplate is a named budget layout. Line names are unique, ignoring
+// case and extra spaces, and each category maps to at most one line.
+type BudgetTemplate struct {
+	ID          int64                `json:"id"`
+	HouseholdID *int64               `json:"householdId,omitempty"`
+	UserID      *int64               `json:"userId,omitempty"`
+	Name        string               `json:"name"`
+	Lines       []BudgetTemplateLine `json:"lines"`
+}
+
+type BudgetTemplateLine struct {
+	ID               int64         `json:"id"`
+	Name             string        `json:"name"`
+	AllocationAmount string        `json:"allocationAmount"`
+	SortOrder        int32         `json:"sortOrder"`
+	Categories       []CategoryRef `json:"categories"`
+}
+
+type BudgetTemplateQuery struct {
+	HouseholdID *int64 `query:"householdId"`
+	UserID      *int64 `query:"userId"`
+}
+
+type BudgetTemplateLineRequest struct {
 	Name             string   `json:"name"`
 	AllocationAmount string   `json:"allocationAmount"`
 	CategoryIDs      []int64  `json:"categoryIds,omitempty"`
@@ -43,12 +198,76 @@ type CreateBudgetLineRequest struct {
 	SortOrder        *int32   `json:"sortOrder,omitempty"`
 }
 
-type UpdateBudgetLineRequest struct {
-	Name             *string   `json:"name,omitempty"`
-	AllocationAmount *string   `json:"allocationAmount,omitempty"`
-	CategoryIDs      *[]int64  `json:"categoryIds,omitempty"`
-	CategoryCodes    *[]string `json:"categoryCodes,omitempty"`
-	SortOrder        *int32    `json:"sortOrder,omitempty"`
+type CreateBudgetTemplateRequest struct {
+	HouseholdID *int64                      `json:"householdId,omitempty"`
+	UserID      *int64                      `json:"userId,omitempty"`
+	Name        string                      `json:"name"`
+	Lines       []BudgetTemplateLineRequest `json:"lines"`
+}
+
+// UpdateBudgetTemplateRequest replaces every template line when Lines is set.
+type UpdateBudgetTemplateRequest struct {
+	Name  *string                      `json:"name,omitempty"`
+	Lines *[]BudgetTemplateLineRequest `json:"lines,omitempty"`
+}
+
+type SaveBudgetTemplateRequest struct {
+	Name string `json:"name"`
+}
+
+// ApplyBudgetTemplateRequest names the template by TemplateID or by
+// TemplateName within the budget's owner. Mode is merge (default) or replace.
+type ApplyBudgetTemplateRequest struct {
+	TemplateID   *int64  `json:"templateId,omitempty"`
+	TemplateName *string `json:"templateName,omitempty"`
+	Mode         string  `json:"mode,omitempty"`
+	DryRun       bool    `json:"dryRun,omitempty"`
+}
+
+// BudgetTemplateApplication lists the line changes a template makes to a
+// budget. Budget is the result when Applied and the unchanged budget for a dry
+// run.
+type BudgetTemplateApplication struct {
+	Budget   Budget                 `json:"budget"`
+	Template BudgetTemplate         `json:"template"`
+	Mode     string                 `json:"mode"`
+	Applied  bool                   `json:"applied"`
+	Changes  []BudgetTemplateChange `json:"changes"`
+}
+
+// BudgetTemplateChange is one line change. Action is add, update or remove;
+// Before is omitted for added lines and After for removed lines.
+type BudgetTemplateChange struct {
+	Action string      `json:"action"`
+	Before *BudgetLine `json:"before,omitempty"`
+	After  *BudgetLine `json:"after,omitempty"`
+}
+
+// BudgetLint lists structure problems that skew a budget's report. Kind is
+// category_overlap, unmapped_category or empty_line.
+type BudgetLint struct {
+	Budget BudgetSummary     `json:"budget"`
+	Issues []BudgetLintIssue `json:"issues"`
+}
+
+type BudgetLintIssue struct {
+	Kind         string          `json:"kind"`
+	Message      string          `json:"message"`
+	Lines        []BudgetLineRef `json:"lines"`
+	Category     *CategoryRef    `json:"category,omitempty"`
+	ActualAmount string          `json:"actualAmount,omitempty"`
+}
+
+// Budget report formats for GET /v1/budgets/{id}/report?format=. JSON is the
+// default; PDF and XLSX download the detailed report with every transaction.
+const (
+	BudgetReportFormatJSON = "json"
+	BudgetReportFormatPDF  = "pdf"
+	BudgetReportFormatXLSX = "xlsx"
+)
+
+type BudgetReportQuery struct {
+	Format string `query:"format"`
 }
 
 type BudgetReport struct {
@@ -56,26 +275,55 @@ type BudgetReport struct {
 	Lines                []BudgetReportLine          `json:"lines"`
 	UnmappedTransactions []BudgetUnmappedTransaction `json:"unmappedTransactions"`
 	Totals               BudgetReportTotals          `json:"totals"`
+	Goals                []BudgetGoalProgress        `json:"goals"`
+	Reallocations        []BudgetReallocation        `json:"reallocations"`
+	Closing              *BudgetReportClosing        `json:"closing,omitempty"`
+}
+
+// BudgetReportClosing is present when the budget is closed. The report's line
+// actuals and totals then come from the closing snapshot. CurrentTotals and
+// Drift show the live figures, and Drifted is true when they differ.
+type BudgetReportClosing struct {
+	ID               int64              `json:"id"`
+	ClosedByUserID   int64              `json:"closedByUserId"`
+	ClosedByUserName string             `json:"closedByUserName"`
+	ClosedAt         time.Time          `json:"closedAt"`
+	Drifted          bool               `json:"drifted"`
+	CurrentTotals    BudgetReportTotals `json:"currentTotals"`
+	Drift            []BudgetLineDrift  `json:"drift"`
+}
+
+type BudgetLineDrift struct {
+	LineID              int64  `json:"lineId"`
+	Name                string `json:"name"`
+	ClosedActualAmount  string `json:"closedActualAmount"`
+	CurrentActualAmount string `json:"currentActualAmount"`
 }
 
 type BudgetSummary struct {
 	ID             int64     `json:"id"`
 	HouseholdID    *int64    `json:"householdId,omitempty"`
 	UserID         *int64    `json:"userId,omitempty"`
+	PeriodKind     string    `json:"periodKind"`
 	PeriodStart    time.Time `json:"periodStart"`
 	PeriodEnd      time.Time `json:"periodEnd"`
 	SourceBudgetID *int64    `json:"sourceBudgetId,omitempty"`
 }
 
+// BudgetReportLine reports actuals against the adjusted AllocationAmount.
+// ReallocatedAmount is the net allocation moved into the line since it was
+// planned at OriginalAllocationAmount; it is negative when more moved out.
 type BudgetReportLine struct {
-	ID               int64         `json:"id"`
-	BudgetID         int64         `json:"budgetId"`
-	Name             string        `json:"name"`
-	AllocationAmount string        `json:"allocationAmount"`
-	ActualAmount     string        `json:"actualAmount"`
-	RemainingAmount  string        `json:"remainingAmount"`
-	SortOrder        int32         `json:"sortOrder"`
-	Categories       []CategoryRef `json:"categories"`
+	ID                       int64         `json:"id"`
+	BudgetID                 int64         `json:"budgetId"`
+	Name                     string        `json:"name"`
+	AllocationAmount         string        `json:"allocationAmount"`
+	OriginalAllocationAmount string        `json:"originalAllocationAmount"`
+	ReallocatedAmount        string        `json:"reallocatedAmount"`
+	ActualAmount             string        `json:"actualAmount"`
+	RemainingAmount          string        `json:"remainingAmount"`
+	SortOrder                int32         `json:"sortOrder"`
+	Categories               []CategoryRef `json:"categories"`
 }
 
 type BudgetUnmappedTransaction struct {
@@ -93,3 +341,128 @@ type BudgetReportTotals struct {
 	UnmappedActualAmount      string `json:"unmappedActualAmount"`
 	UncategorizedActualAmount string `json:"uncategorizedActualAmount"`
 }
+
+// BudgetGoalProgress is a savings goal measured at the end of the budget
+// period. PeriodNetAmount is the net contribution made within the period.
+type BudgetGoalProgress struct {
+	ID                    int64     `json:"id"`
+	Name                  string    `json:"name"`
+	TargetAmount          string    `json:"targetAmount"`
+	TargetDate            time.Time `json:"targetDate"`
+	BalanceAmount         string    `json:"balanceAmount"`
+	RemainingAmount       string    `json:"remainingAmount"`
+	RequiredMonthlyAmount string    `json:"requiredMonthlyAmount"`
+	PeriodNetAmount       string    `json:"periodNetAmount"`
+	State                 string    `json:"state"`
+}
+
+// BudgetTrendQuery selects GET /v1/budgets/trends. From and To use YYYY-MM and
+// span at most 24 months.
+type BudgetTrendQuery struct {
+	HouseholdID *int64 `query:"householdId"`
+	UserID      *int64 `query:"userId"`
+	From        string `query:"from"`
+	To          string `query:"to"`
+}
+
+// BudgetTrends compares monthly allocations with actual spending. Lines are
+// matched across months by name. A category series carries the allocation of
+// the lines mapping it, split evenly when a line maps several categories.
+// YearToDate runs from January of the To year through To.
+type BudgetTrends struct {
+	HouseholdID *int64              `json:"householdId,omitempty"`
+	UserID      *int64              `json:"userId,omitempty"`
+	From        string              `json:"from"`
+	To          string              `json:"to"`
+	Months      []BudgetTrendMonth  `json:"months"`
+	Lines       []BudgetTrendSeries `json:"lines"`
+	Categories  []BudgetTrendSeries `json:"categories"`
+	Totals      BudgetTrendTotals   `json:"totals"`
+	YearToDate  BudgetTrendTotals   `json:"yearToDate"`
+}
+
+// BudgetComparisonQuery selects GET /v1/budgets/compare, which compares budget
+// B against budget A.
+type BudgetComparisonQuery struct {
+	A int64 `query:"a"`
+	B int64 `query:"b"`
+}
+
+// BudgetComparison aligns budget B's lines with budget A's. Deltas are B minus
+// A, counting a missing line as zero. Lineage is present when one budget was
+// copied from the other.
+type BudgetComparison struct {
+	A       BudgetSummary          `json:"a"`
+	B       BudgetSummary          `json:"b"`
+	Lineage *BudgetLineage         `json:"lineage,omitempty"`
+	Lines   []BudgetLineComparison `json:"lines"`
+	Totals  BudgetComparisonTotals `json:"totals"`
+}
+
+type BudgetLineage struct {
+	AncestorID   int64 `json:"ancestorId"`
+	DescendantID int64 `json:"descendantId"`
+	Generations  int   `json:"generations"`
+}
+
+// BudgetLineComparison is one aligned line. Status is matched, added or
+// removed; MatchedBy is name, or categories for a renamed line in related
+// budgets. A is omitted for added lines and B for removed lines.
+type BudgetLineComparison struct {
+	Status            string              `json:"status"`
+	MatchedBy         string              `json:"matchedBy,omitempty"`
+	A                 *BudgetComparedLine `json:"a,omitempty"`
+	B                 *BudgetComparedLine `json:"b,omitempty"`
+	AllocationDelta   string              `json:"allocationDelta"`
+	ActualDelta       string              `json:"actualDelta"`
+	AddedCategories   []CategoryRef       `json:"addedCategories"`
+	RemovedCategories []CategoryRef       `json:"removedCategories"`
+}
+
+type BudgetComparedLine struct {
+	ID               int64         `json:"id"`
+	Name             string        `json:"name"`
+	AllocationAmount string        `json:"allocationAmount"`
+	ActualAmount     string        `json:"actualAmount"`
+	Categories       []CategoryRef `json:"categories"`
+}
+
+type BudgetComparisonTotals struct {
+	A               BudgetReportTotals `json:"a"`
+	B               BudgetReportTotals `json:"b"`
+	AllocationDelta string             `json:"allocationDelta"`
+	ActualDelta     string             `json:"actualDelta"`
+}
+
+type BudgetTrendMonth struct {
+	Month            string `json:"month"`
+	BudgetID         *int64 `json:"budgetId,omitempty"`
+	AllocationAmount string `json:"allocationAmount"`
+	ActualAmount     string `json:"actualAmount"`
+	RemainingAmount  string `json:"remainingAmount"`
+}
+
+type BudgetTrendSeries struct {
+	Name       string             `json:"name"`
+	Category   *CategoryRef       `json:"category,omitempty"`
+	Months     []BudgetTrendPoint `json:"months"`
+	Totals     BudgetTrendTotals  `json:"totals"`
+	YearToDate BudgetTrendTotals  `json:"yearToDate"`
+}
+
+type BudgetTrendPoint struct {
+	Month            string `json:"month"`
+	Budgeted         bool   `json:"budgeted"`
+	AllocationAmount string `json:"allocationAmount"`
+	ActualAmount     string `json:"actualAmount"`
+	RemainingAmount  string `json:"remainingAmount"`
+}
+
+type BudgetTrendTotals struct {
+	Months                  int    `json:"months"`
+	AllocationAmount        string `json:"allocationAmount"`
+	ActualAmount            string `json:"actualAmount"`
+	RemainingAmount         string `json:"remainingAmount"`
+	AverageAllocationAmount string `json:"averageAllocationAmount"`
+	AverageActualAmount     string `json:"averageActualAmount"`
+}
@@ -6,6 +6,8 @@ type Category struct {
 	Name        string  `json:"name"`
 	Description *string `json:"description,omitempty"`
 	IsActive    bool    `json:"isActive"`
+	// Version changes on every write and is also the category's ETag.
+	Version int64 `json:"version"`
 }
 
 type CreateCategoryRequest struct {
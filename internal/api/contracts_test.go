@@ -2,17 +2,25 @@ package api
 
 import (
 	"encoding/json"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 )
 
 func TestVersionedRouteContracts(t *testing.T) {
 	routes := []string{
-		TransactionsPath, TransactionsBulkPath, TransactionsRestorePath, TransactionPath,
+		TransactionsPath, TransactionsBulkPath, TransactionsRestorePath, TransactionChangesPath, TransactionTagsPath, TransactionAggregatePath, TransactionPath, TransactionHistoryPath,
+		SavedFiltersPath, SavedFilterPath,
+		TransactionAttachmentsPath, AttachmentsPath, AttachmentPath, AttachmentContentPath,
 		UsersPath, UserPath, UserResolvePath,
 		HouseholdsPath, HouseholdPath, HouseholdUsersPath, HouseholdResolvePath,
 		CategoriesPath, CategoryPath,
-		MonthlyBudgetsPath, BudgetReportPath, BudgetLinesPath, BudgetLinePath,
+		BudgetsPath, MonthlyBudgetsPath, BudgetTrendsPath, BudgetComparePath, BudgetReportPath, BudgetLinesPath, BudgetReallocationsPath, BudgetLintPath, BudgetSaveTemplatePath, BudgetApplyTemplatePath, BudgetClosePath, BudgetReopenPath, BudgetLinePath,
+		BudgetTemplatesPath, BudgetTemplatePath,
+		GoalsPath, GoalPath,
+		AlertsPath, EventsPath, OpenAPIPath,
+		WebhooksPath, WebhookPath, WebhookDeliveriesPath, WebhookDeliveryRedeliverPath,
 	}
 	for _, route := range routes {
 		if !strings.HasPrefix(route, APIPrefix+"/") {
@@ -58,3 +66,67 @@ func TestCollectionContractsEncodeEmptyArrays(t *testing.T) {
 		t.Fatalf("empty bulk result = %s, want %s", got, want)
 	}
 }
+
+func TestOpenAPIDocumentResolvesEverySchema(t *testing.T) {
+	encoded, err := json.Marshal(OpenAPI())
+	if err != nil {
+		t.Fatalf("marshal OpenAPI document: %v", err)
+	}
+	var document struct {
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Required []string `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(encoded, &document); err != nil {
+		t.Fatalf("decode OpenAPI document: %v", err)
+	}
+	for _, match := range regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`).FindAllStringSubmatch(string(encoded), -1) {
+		if _, exists := document.Components.Schemas[match[1]]; !exists {
+			t.Errorf("schema %s is referenced but not defined", match[1])
+		}
+	}
+	operations := 0
+	for _, item := range document.Paths {
+		operations += len(item)
+	}
+	if operations != len(Routes) {
+		t.Fatalf("document has %d operations for %d routes", operations, len(Routes))
+	}
+	if got, want := document.Components.Schemas["Transaction"].Required, []string{"id", "amount", "transactionDate", "authorId", "version"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("transaction required=%v want %v", got, want)
+	}
+	if got := document.Components.Schemas["BulkUpdateTransaction"].Required; !reflect.DeepEqual(got, []string{"id"}) {
+		t.Fatalf("embedded update fields must be flattened and optional, required=%v", got)
+	}
+}
+
+func TestOpenAPIDescribesTheEventStream(t *testing.T) {
+	encoded, err := json.Marshal(OpenAPI()["paths"].(map[string]any)[EventsPath])
+	if err != nil {
+		t.Fatalf("marshal events path: %v", err)
+	}
+	for _, want := range []string{`"text/event-stream":{"schema":{"$ref":"#/components/schemas/Event"}}`, `{"in":"header","name":"Last-Event-ID","schema":{"type":"string"}}`} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("events operation lacks %s: %s", want, encoded)
+		}
+	}
+}
+
+func TestOpenAPIDescribesAttachmentUploadAndDownload(t *testing.T) {
+	paths := OpenAPI()["paths"].(map[string]any)
+	encoded, err := json.Marshal([]any{paths[TransactionAttachmentsPath], paths[AttachmentContentPath]})
+	if err != nil {
+		t.Fatalf("marshal attachment paths: %v", err)
+	}
+	for _, want := range []string{
+		`"multipart/form-data":{"schema":{"properties":{"file":{"format":"binary","type":"string"}},"required":["file"],"type":"object"}}`,
+		`"application/pdf":{"schema":{"format":"binary","type":"string"}}`,
+	} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("attachment operations lack %s: %s", want, encoded)
+		}
+	}
+}
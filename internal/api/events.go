@@ -0,0 +1,40 @@
+package api
+
+import "time"
+
+// Event types sent by GET /v1/events. EventStreamReset is sent first when the
+// events after Last-Event-ID are no longer available; the client should reload
+// what it caches and continue from the reset's id.
+const (
+	EventTransactionCreated  = "transaction.created"
+	EventTransactionUpdated  = "transaction.updated"
+	EventTransactionDeleted  = "transaction.deleted"
+	EventTransactionRestored = "transaction.restored"
+	EventBudgetLineChanged   = "budget.line.changed"
+	EventCategoryChanged     = "category.changed"
+	EventStreamReset         = "stream.reset"
+)
+
+// Event is the JSON data of one Server-Sent Event. It names the record that
+// changed; only the fields for that record's type are set.
+type Event struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	OccurredAt    time.Time `json:"occurredAt"`
+	HouseholdID   *int64    `json:"householdId,omitempty"`
+	TransactionID *int64    `json:"transactionId,omitempty"`
+	BudgetID      *int64    `json:"budgetId,omitempty"`
+	BudgetLineID  *int64    `json:"budgetLineId,omitempty"`
+	CategoryCode  *string   `json:"categoryCode,omitempty"`
+	// PreviousHouseholdID is the household a transaction update moved the
+	// transaction out of.
+	PreviousHouseholdID *int64 `json:"previousHouseholdId,omitempty"`
+}
+
+// EventsQuery opens GET /v1/events. With HouseholdID set the stream carries
+// that household's events plus those outside any household. LastEventID
+// resumes after an event received earlier.
+type EventsQuery struct {
+	HouseholdID *int64 `query:"householdId"`
+	LastEventID string `header:"Last-Event-ID"`
+}
@@ -0,0 +1,60 @@
+package api
+
+import "time"
+
+// Goal is a savings goal with its progress measured as of AsOf. Contributions
+// and withdrawals come from transactions in the linked categories.
+type Goal struct {
+	ID                    int64         `json:"id"`
+	HouseholdID           *int64        `json:"householdId,omitempty"`
+	UserID                *int64        `json:"userId,omitempty"`
+	Name                  string        `json:"name"`
+	TargetAmount          string        `json:"targetAmount"`
+	StartDate             time.Time     `json:"startDate"`
+	TargetDate            time.Time     `json:"targetDate"`
+	Categories            []CategoryRef `json:"categories"`
+	AsOf                  time.Time     `json:"asOf"`
+	ContributedAmount     string        `json:"contributedAmount"`
+	WithdrawnAmount       string        `json:"withdrawnAmount"`
+	BalanceAmount         string        `json:"balanceAmount"`
+	RemainingAmount       string        `json:"remainingAmount"`
+	PeriodNetAmount       string        `json:"periodNetAmount"`
+	MonthsRemaining       int           `json:"monthsRemaining"`
+	RequiredMonthlyAmount string        `json:"requiredMonthlyAmount"`
+	State                 string        `json:"state"`
+}
+
+// CreateGoalRequest dates use YYYY-MM-DD. StartDate defaults to the first day
+// of the current month.
+type CreateGoalRequest struct {
+	HouseholdID   *int64   `json:"householdId,omitempty"`
+	UserID        *int64   `json:"userId,omitempty"`
+	Name          string   `json:"name"`
+	TargetAmount  string   `json:"targetAmount"`
+	StartDate     *string  `json:"startDate,omitempty"`
+	TargetDate    string   `json:"targetDate"`
+	CategoryIDs   []int64  `json:"categoryIds,omitempty"`
+	CategoryCodes []string `json:"categoryCodes,omitempty"`
+}
+
+type UpdateGoalRequest struct {
+	Name          *string   `json:"name,omitempty"`
+	TargetAmount  *string   `json:"targetAmount,omitempty"`
+	StartDate     *string   `json:"startDate,omitempty"`
+	TargetDate    *string   `json:"targetDate,omitempty"`
+	CategoryIDs   *[]int64  `json:"categoryIds,omitempty"`
+	CategoryCodes *[]string `json:"categoryCodes,omitempty"`
+}
+
+// GetGoalQuery measures one goal as of AsOf, which uses YYYY-MM-DD and
+// defaults to today.
+type GetGoalQuery struct {
+	AsOf string `query:"asOf"`
+}
+
+// GoalQuery scopes goal reads. AsOf uses YYYY-MM-DD and defaults to today.
+type GoalQuery struct {
+	HouseholdID *int64 `query:"householdId"`
+	UserID      *int64 `query:"userId"`
+	AsOf        string `query:"asOf"`
+}
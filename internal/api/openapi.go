@@ -0,0 +1,209 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	pathParameters = regexp.MustCompile(`\{([^}]+)\}`)
+)
+
+// OpenAPI builds the OpenAPI 3.1 document for Routes. Schemas follow the wire
+// types' JSON tags: fields without omitempty are required and named structs
+// become shared components. Every operation needs the bearer API key.
+func OpenAPI() map[string]any {
+	generator := schemaGenerator{components: map[string]any{}}
+	paths := map[string]any{}
+	for _, route := range Routes {
+		item, exists := paths[route.Path].(map[string]any)
+		if !exists {
+			item = map[string]any{}
+			paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = generator.operation(route)
+	}
+	errorSchema := generator.schema(reflect.TypeOf(ErrorResponse{}))
+	return map[string]any{
+		"openapi":  "3.1.0",
+		"info":     map[string]any{"title": "Voltr Finance API", "version": strings.TrimPrefix(APIPrefix, "/")},
+		"security": []any{map[string]any{"bearerAuth": []string{}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"schemas": generator.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "The request failed. Code is stable and machine-readable.",
+					"content":     jsonContent(errorSchema),
+				},
+			},
+		},
+	}
+}
+
+type schemaGenerator struct {
+	components map[string]any
+}
+
+func (g schemaGenerator) operation(route Route) map[string]any {
+	operation := map[string]any{
+		"summary": route.Summary,
+		"tags":    []string{strings.Split(strings.TrimPrefix(route.Path, APIPrefix+"/"), "/")[0]},
+	}
+	var parameters []any
+	for _, match := range pathParameters.FindAllStringSubmatch(route.Path, -1) {
+		schema := map[string]any{"type": "string"}
+		if match[1] == "id" {
+			schema = map[string]any{"type": "integer", "format": "int64", "minimum": 1}
+		}
+		parameters = append(parameters, map[string]any{"name": match[1], "in": "path", "required": true, "schema": schema})
+	}
+	if route.Query != nil {
+		queryType := reflect.TypeOf(route.Query)
+		for i := range queryType.NumField() {
+			field := queryType.Field(i)
+			if name := field.Tag.Get("query"); name != "" {
+				parameters = append(parameters, map[string]any{"name": name, "in": "query", "schema": g.schema(field.Type)})
+			}
+			if name := field.Tag.Get("header"); name != "" {
+				parameters = append(parameters, map[string]any{"name": name, "in": "header", "schema": g.schema(field.Type)})
+			}
+		}
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+	if route.Request != nil {
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content":  jsonContent(g.schema(reflect.TypeOf(route.Request))),
+		}
+	}
+	if route.Upload != "" {
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{"multipart/form-data": map[string]any{"schema": map[string]any{
+				"type":       "object",
+				"required":   []string{route.Upload},
+				"properties": map[string]any{route.Upload: map[string]any{"type": "string", "format": "binary"}},
+			}}},
+		}
+	}
+	statuses := route.Statuses
+	if len(statuses) == 0 {
+		statuses = []int{http.StatusOK}
+	}
+	responses := map[string]any{"default": map[string]any{"$ref": "#/components/responses/Error"}}
+	for _, status := range statuses {
+		response := map[string]any{"description": http.StatusText(status)}
+		if route.Stream {
+			response["content"] = map[string]any{"text/event-stream": map[string]any{"schema": g.schema(reflect.TypeOf(route.Response))}}
+		} else if route.Response != nil && status != http.StatusNoContent {
+			content := jsonContent(g.schema(reflect.TypeOf(route.Response)))
+			for _, mediaType := range route.Downloads {
+				content[mediaType] = map[string]any{}
+			}
+			response["content"] = content
+		} else if len(route.Downloads) > 0 && status != http.StatusNoContent {
+			content := map[string]any{}
+			for _, mediaType := range route.Downloads {
+				content[mediaType] = map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}
+			}
+			response["content"] = content
+		}
+		responses[strconv.Itoa(status)] = response
+	}
+	operation["responses"] = responses
+	return operation
+}
+
+// schema describes how encoding/json writes a value of type t. Named structs
+// are added to the components once and referenced from then on.
+func (g schemaGenerator) schema(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, exists := g.components[t.Name()]; !exists {
+			g.components[t.Name()] = map[string]any{}
+			g.components[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	}
+	return map[string]any{}
+}
+
+func (g schemaGenerator) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	g.fields(t, properties, &required)
+	object := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+	return object
+}
+
+// fields adds t's JSON fields, flattening untagged embedded structs the way
+// encoding/json does. A required pointer may be null.
+func (g schemaGenerator) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.fields(field.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := g.schema(field.Type)
+		if strings.Contains(","+options+",", ",omitempty,") {
+			properties[name] = schema
+			continue
+		}
+		if field.Type.Kind() == reflect.Pointer {
+			schema = map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+		}
+		properties[name] = schema
+		*required = append(*required, name)
+	}
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
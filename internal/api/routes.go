@@ -1,13 +1,36 @@
 package api
 
+import "net/http"
+
+// Idempotency headers. A mutating request sent with IdempotencyKeyHeader runs
+// once; retries with the same key and request replay the first response and
+// carry IdempotentReplayedHeader.
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
 const (
-	APIPrefix = "/v1"
-	LivePath  = "/live"
+	APIPrefix   = "/v1"
+	LivePath    = "/live"
+	OpenAPIPath = APIPrefix + "/openapi.json"
 
-	TransactionsPath        = APIPrefix + "/transactions"
-	TransactionsBulkPath    = TransactionsPath + "/bulk"
-	TransactionsRestorePath = TransactionsPath + "/restore"
-	TransactionPath         = TransactionsPath + "/{id}"
+	TransactionsPath         = APIPrefix + "/transactions"
+	TransactionsBulkPath     = TransactionsPath + "/bulk"
+	TransactionsRestorePath  = TransactionsPath + "/restore"
+	TransactionChangesPath   = TransactionsPath + "/changes"
+	TransactionTagsPath      = TransactionsPath + "/tags"
+	TransactionAggregatePath = TransactionsPath + "/aggregate"
+	TransactionPath          = TransactionsPath + "/{id}"
+	TransactionHistoryPath   = TransactionPath + "/history"
+
+	SavedFiltersPath = APIPrefix + "/saved-filters"
+	SavedFilterPath  = SavedFiltersPath + "/{name}"
+
+	TransactionAttachmentsPath = TransactionPath + "/attachments"
+	AttachmentsPath            = APIPrefix + "/attachments"
+	AttachmentPath             = AttachmentsPath + "/{id}"
+	AttachmentContentPath      = AttachmentPath + "/content"
 
 	UsersPath       = APIPrefix + "/users"
 	UserPath        = UsersPath + "/{id}"
@@ -21,8 +44,143 @@ const (
 	CategoriesPath = APIPrefix + "/categories"
 	CategoryPath   = CategoriesPath + "/{code}"
 
-	MonthlyBudgetsPath = APIPrefix + "/budgets/monthly"
-	BudgetReportPath   = APIPrefix + "/budgets/{id}/report"
-	BudgetLinesPath    = APIPrefix + "/budgets/{id}/lines"
-	BudgetLinePath     = APIPrefix + "/budget-lines/{id}"
+	BudgetsPath             = APIPrefix + "/budgets"
+	MonthlyBudgetsPath      = BudgetsPath + "/monthly"
+	BudgetTrendsPath        = BudgetsPath + "/trends"
+	BudgetComparePath       = BudgetsPath + "/compare"
+	BudgetReportPath        = APIPrefix + "/budgets/{id}/report"
+	BudgetLinesPath         = APIPrefix + "/budgets/{id}/lines"
+	BudgetReallocationsPath = APIPrefix + "/budgets/{id}/reallocations"
+	BudgetLintPath          = APIPrefix + "/budgets/{id}/lint"
+	BudgetSaveTemplatePath  = APIPrefix + "/budgets/{id}/save-template"
+	BudgetApplyTemplatePath = APIPrefix + "/budgets/{id}/apply-template"
+	BudgetClosePath         = APIPrefix + "/budgets/{id}/close"
+	BudgetReopenPath        = APIPrefix + "/budgets/{id}/reopen"
+	BudgetLinePath          = APIPrefix + "/budget-lines/{id}"
+
+	BudgetTemplatesPath = APIPrefix + "/budget-templates"
+	BudgetTemplatePath  = BudgetTemplatesPath + "/{id}"
+
+	GoalsPath = APIPrefix + "/goals"
+	GoalPath  = GoalsPath + "/{id}"
+
+	AlertsPath = APIPrefix + "/alerts"
+
+	EventsPath = APIPrefix + "/events"
+
+	WebhooksPath                 = APIPrefix + "/webhooks"
+	WebhookPath                  = WebhooksPath + "/{id}"
+	WebhookDeliveriesPath        = WebhookPath + "/deliveries"
+	WebhookDeliveryRedeliverPath = APIPrefix + "/webhook-deliveries/{id}/redeliver"
 )
+
+// Route documents one operation for the OpenAPI document. Query, Request and
+// Response hold zero values of the wire types; nil means the operation has
+// none. Statuses defaults to 200, and Downloads lists media types served
+// instead of JSON on request, or the only ones when Response is nil. Upload
+// names the multipart form field of an operation that takes a file instead
+// of a JSON body. Stream marks a Server-Sent Events operation whose events
+// carry Response values.
+type Route struct {
+	Method    string
+	Path      string
+	Summary   string
+	Query     any
+	Request   any
+	Response  any
+	Statuses  []int
+	Downloads []string
+	Upload    string
+	Stream    bool
+}
+
+var created = []int{http.StatusCreated}
+
+// attachmentTypes are the media types an attachment download can have.
+var attachmentTypes = []string{"application/pdf", "image/gif", "image/jpeg", "image/png", "image/webp"}
+
+// Routes is every operation served under APIPrefix.
+var Routes = []Route{
+	{Method: http.MethodGet, Path: OpenAPIPath, Summary: "Get this OpenAPI document", Response: map[string]any{}},
+
+	{Method: http.MethodPost, Path: TransactionsPath, Summary: "Create a transaction", Request: CreateTransactionRequest{}, Response: Transaction{}, Statuses: created},
+	{Method: http.MethodGet, Path: TransactionsPath, Summary: "List transactions", Query: ListTransactionsQuery{}, Response: TransactionPage{}},
+	{Method: http.MethodDelete, Path: TransactionsPath, Summary: "Soft delete transactions", Request: DeleteTransactionsRequest{}, Response: BulkResult{}},
+	{Method: http.MethodPost, Path: TransactionsBulkPath, Summary: "Create transactions in bulk", Request: BulkCreateTransactionsRequest{}, Response: BulkResult{}},
+	{Method: http.MethodPatch, Path: TransactionsBulkPath, Summary: "Update transactions in bulk", Request: BulkUpdateTransactionsRequest{}, Response: BulkResult{}},
+	{Method: http.MethodPost, Path: TransactionsRestorePath, Summary: "Restore deleted transactions", Request: RestoreTransactionsRequest{}, Response: BulkResult{}},
+	{Method: http.MethodGet, Path: TransactionChangesPath, Summary: "List transaction changes since a token", Query: TransactionChangesQuery{}, Response: TransactionChanges{}},
+	{Method: http.MethodGet, Path: TransactionTagsPath, Summary: "Total transactions by tag", Query: TagTotalsQuery{}, Response: []TagTotal{}},
+	{Method: http.MethodGet, Path: TransactionAggregatePath, Summary: "Aggregate transactions by dimensions", Query: AggregateTransactionsQuery{}, Response: TransactionAggregate{}},
+	{Method: http.MethodGet, Path: TransactionPath, Summary: "Get a transaction", Query: GetTransactionQuery{}, Response: Transaction{}},
+	{Method: http.MethodPatch, Path: TransactionPath, Summary: "Update a transaction", Request: UpdateTransactionRequest{}, Response: Transaction{}},
+	{Method: http.MethodGet, Path: TransactionHistoryPath, Summary: "List a transaction's change history", Response: []TransactionHistoryEntry{}},
+
+	{Method: http.MethodGet, Path: SavedFiltersPath, Summary: "List saved transaction filters", Response: []SavedFilter{}},
+	{Method: http.MethodPut, Path: SavedFilterPath, Summary: "Save a transaction filter", Request: SaveFilterRequest{}, Response: SavedFilter{}},
+	{Method: http.MethodDelete, Path: SavedFilterPath, Summary: "Delete a saved transaction filter", Statuses: []int{http.StatusNoContent}},
+
+	{Method: http.MethodPost, Path: TransactionAttachmentsPath, Summary: "Attach a receipt or document to a transaction", Upload: AttachmentFileField, Response: Attachment{}, Statuses: created},
+	{Method: http.MethodGet, Path: TransactionAttachmentsPath, Summary: "List a transaction's attachments", Response: []Attachment{}},
+	{Method: http.MethodGet, Path: AttachmentPath, Summary: "Get an attachment", Response: Attachment{}},
+	{Method: http.MethodGet, Path: AttachmentContentPath, Summary: "Download an attachment", Downloads: attachmentTypes},
+	{Method: http.MethodDelete, Path: AttachmentPath, Summary: "Delete an attachment", Statuses: []int{http.StatusNoContent}},
+
+	{Method: http.MethodPost, Path: UsersPath, Summary: "Create a user", Request: CreateUserRequest{}, Response: User{}, Statuses: created},
+	{Method: http.MethodGet, Path: UsersPath, Summary: "List users", Response: []User{}},
+	{Method: http.MethodPost, Path: UserResolvePath, Summary: "Resolve a user by identity", Request: ResolveUserRequest{}, Response: User{}},
+	{Method: http.MethodGet, Path: UserPath, Summary: "Get a user", Response: User{}},
+	{Method: http.MethodPatch, Path: UserPath, Summary: "Update a user", Request: UpdateUserRequest{}, Response: User{}},
+
+	{Method: http.MethodGet, Path: HouseholdsPath, Summary: "List households", Response: []Household{}},
+	{Method: http.MethodGet, Path: HouseholdResolvePath, Summary: "Resolve a household by name or guild", Query: ResolveHouseholdQuery{}, Response: Household{}},
+	{Method: http.MethodGet, Path: HouseholdPath, Summary: "Get a household", Response: Household{}},
+	{Method: http.MethodGet, Path: HouseholdUsersPath, Summary: "List household members", Response: []User{}},
+
+	{Method: http.MethodPost, Path: CategoriesPath, Summary: "Create a category", Request: CreateCategoryRequest{}, Response: Category{}, Statuses: created},
+	{Method: http.MethodGet, Path: CategoriesPath, Summary: "List categories", Query: ListCategoriesQuery{}, Response: []Category{}},
+	{Method: http.MethodGet, Path: CategoryPath, Summary: "Get a category", Response: Category{}},
+	{Method: http.MethodPatch, Path: CategoryPath, Summary: "Update a category", Request: UpdateCategoryRequest{}, Response: Category{}},
+	{Method: http.MethodDelete, Path: CategoryPath, Summary: "Deactivate a category", Response: Category{}},
+
+	{Method: http.MethodGet, Path: BudgetsPath, Summary: "Get the budget for a period", Query: BudgetPeriodQuery{}, Response: Budget{}},
+	{Method: http.MethodPut, Path: BudgetsPath, Summary: "Ensure the budget for a period exists", Query: BudgetPeriodQuery{}, Response: Budget{}, Statuses: []int{http.StatusOK, http.StatusCreated}},
+	{Method: http.MethodGet, Path: MonthlyBudgetsPath, Summary: "Get a monthly budget", Query: MonthlyBudgetQuery{}, Response: Budget{}},
+	{Method: http.MethodPost, Path: MonthlyBudgetsPath, Summary: "Ensure a monthly budget exists", Request: EnsureMonthlyBudgetRequest{}, Response: Budget{}, Statuses: []int{http.StatusOK, http.StatusCreated}},
+	{Method: http.MethodGet, Path: BudgetTrendsPath, Summary: "Get monthly budget trends", Query: BudgetTrendQuery{}, Response: BudgetTrends{}},
+	{Method: http.MethodGet, Path: BudgetComparePath, Summary: "Compare two budgets", Query: BudgetComparisonQuery{}, Response: BudgetComparison{}},
+	{Method: http.MethodGet, Path: BudgetReportPath, Summary: "Get a budget report", Query: BudgetReportQuery{}, Response: BudgetReport{}, Downloads: []string{"application/pdf", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}},
+	{Method: http.MethodGet, Path: BudgetLintPath, Summary: "Lint a budget's structure", Response: BudgetLint{}},
+	{Method: http.MethodPost, Path: BudgetLinesPath, Summary: "Add a budget line", Request: CreateBudgetLineRequest{}, Response: BudgetLine{}, Statuses: created},
+	{Method: http.MethodPost, Path: BudgetReallocationsPath, Summary: "Move allocation between budget lines", Request: ReallocateBudgetRequest{}, Response: BudgetReallocationResult{}, Statuses: created},
+	{Method: http.MethodPatch, Path: BudgetLinePath, Summary: "Update a budget line", Request: UpdateBudgetLineRequest{}, Response: BudgetLine{}},
+	{Method: http.MethodDelete, Path: BudgetLinePath, Summary: "Delete a budget line", Statuses: []int{http.StatusNoContent}},
+	{Method: http.MethodPost, Path: BudgetSaveTemplatePath, Summary: "Save a budget as a template", Request: SaveBudgetTemplateRequest{}, Response: BudgetTemplate{}, Statuses: created},
+	{Method: http.MethodPost, Path: BudgetApplyTemplatePath, Summary: "Apply a template to a budget", Request: ApplyBudgetTemplateRequest{}, Response: BudgetTemplateApplication{}},
+	{Method: http.MethodPost, Path: BudgetClosePath, Summary: "Close a budget", Request: CloseBudgetRequest{}, Response: BudgetClosing{}, Statuses: created},
+	{Method: http.MethodPost, Path: BudgetReopenPath, Summary: "Reopen a closed budget", Request: ReopenBudgetRequest{}, Response: BudgetClosing{}},
+
+	{Method: http.MethodPost, Path: BudgetTemplatesPath, Summary: "Create a budget template", Request: CreateBudgetTemplateRequest{}, Response: BudgetTemplate{}, Statuses: created},
+	{Method: http.MethodGet, Path: BudgetTemplatesPath, Summary: "List budget templates", Query: BudgetTemplateQuery{}, Response: []BudgetTemplate{}},
+	{Method: http.MethodGet, Path: BudgetTemplatePath, Summary: "Get a budget template", Response: BudgetTemplate{}},
+	{Method: http.MethodPatch, Path: BudgetTemplatePath, Summary: "Update a budget template", Request: UpdateBudgetTemplateRequest{}, Response: BudgetTemplate{}},
+	{Method: http.MethodDelete, Path: BudgetTemplatePath, Summary: "Delete a budget template", Statuses: []int{http.StatusNoContent}},
+
+	{Method: http.MethodPost, Path: GoalsPath, Summary: "Create a savings goal", Request: CreateGoalRequest{}, Response: Goal{}, Statuses: created},
+	{Method: http.MethodGet, Path: GoalsPath, Summary: "List savings goals", Query: GoalQuery{}, Response: []Goal{}},
+	{Method: http.MethodGet, Path: GoalPath, Summary: "Get a savings goal", Query: GetGoalQuery{}, Response: Goal{}},
+	{Method: http.MethodPatch, Path: GoalPath, Summary: "Update a savings goal", Request: UpdateGoalRequest{}, Response: Goal{}},
+	{Method: http.MethodDelete, Path: GoalPath, Summary: "Delete a savings goal", Statuses: []int{http.StatusNoContent}},
+
+	{Method: http.MethodGet, Path: AlertsPath, Summary: "List budget alerts", Query: AlertQuery{}, Response: []BudgetAlert{}},
+
+	{Method: http.MethodGet, Path: EventsPath, Summary: "Stream finance events", Query: EventsQuery{}, Response: Event{}, Stream: true},
+
+	{Method: http.MethodPost, Path: WebhooksPath, Summary: "Create a webhook", Request: CreateWebhookRequest{}, Response: Webhook{}, Statuses: created},
+	{Method: http.MethodGet, Path: WebhooksPath, Summary: "List webhooks", Query: WebhookQuery{}, Response: []Webhook{}},
+	{Method: http.MethodGet, Path: WebhookPath, Summary: "Get a webhook", Response: Webhook{}},
+	{Method: http.MethodPatch, Path: WebhookPath, Summary: "Update a webhook", Request: UpdateWebhookRequest{}, Response: Webhook{}},
+	{Method: http.MethodDelete, Path: WebhookPath, Summary: "Delete a webhook", Statuses: []int{http.StatusNoContent}},
+	{Method: http.MethodGet, Path: WebhookDeliveriesPath, Summary: "List a webhook's deliveries", Query: WebhookDeliveryQuery{}, Response: []WebhookDelivery{}},
+	{Method: http.MethodPost, Path: WebhookDeliveryRedeliverPath, Summary: "Redeliver a webhook delivery", Response: WebhookDelivery{}, Statuses: []int{http.StatusAccepted}},
+}
@@ -23,6 +23,19 @@ type Transaction struct {
 	UpdatedAt       *time.Time   `json:"updatedAt,omitempty"`
 	DeletedAt       *time.Time   `json:"deletedAt,omitempty"`
 	DeleteReason    *string      `json:"deleteReason,omitempty"`
+	Tags            []string     `json:"tags,omitempty"`
+	// Version changes on every write. The ETag of single-transaction
+	// responses carries the same value.
+	Version int64 `json:"version"`
+	// Match is set on transactions listed with a search.
+	Match *SearchMatch `json:"match,omitempty"`
+}
+
+// SearchMatch tells how well a transaction matched the search. Snippet is the
+// description or notes with matched words wrapped in ** markers.
+type SearchMatch struct {
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
 }
 
 type CreateTransactionRequest struct {
@@ -34,6 +47,9 @@ type CreateTransactionRequest struct {
 	CategoryCode    *string          `json:"categoryCode,omitempty"`
 	HouseholdID     *int64           `json:"householdId,omitempty"`
 	Author          IdentitySelector `json:"author"`
+	// Tags are lowercased and deduplicated; each is 1 to 64 letters, digits,
+	// hyphens and underscores.
+	Tags []string `json:"tags,omitempty"`
 }
 
 type BulkCreateTransactionsRequest struct {
@@ -49,15 +65,26 @@ type UpdateTransactionRequest struct {
 	CategoryCode    *string           `json:"categoryCode,omitempty"`
 	HouseholdID     *int64            `json:"householdId,omitempty"`
 	Author          *IdentitySelector `json:"author,omitempty"`
+	// UpdatedByUserID is recorded in the transaction's history as the user
+	// who made the update.
+	UpdatedByUserID *int64 `json:"updatedByUserId,omitempty"`
+	// Tags replaces every tag of the transaction; ClearTags removes them.
+	Tags []string `json:"tags,omitempty"`
 
 	ClearDescription bool `json:"clearDescription,omitempty"`
 	ClearNotes       bool `json:"clearNotes,omitempty"`
 	ClearCategoryID  bool `json:"clearCategoryId,omitempty"`
 	ClearHouseholdID bool `json:"clearHouseholdId,omitempty"`
+	ClearTags        bool `json:"clearTags,omitempty"`
 }
 
+// BulkUpdateTransaction is one item of PATCH /v1/transactions/bulk.
+// ExpectedVersion plays the role If-Match has on the single-transaction
+// PATCH: the item fails with version_mismatch unless the transaction is still
+// at that version.
 type BulkUpdateTransaction struct {
-	ID int64 `json:"id"`
+	ID              int64  `json:"id"`
+	ExpectedVersion *int64 `json:"expectedVersion,omitempty"`
 	UpdateTransactionRequest
 }
 
@@ -65,10 +92,22 @@ type BulkUpdateTransactionsRequest struct {
 	Transactions []BulkUpdateTransaction `json:"transactions"`
 }
 
+// DeleteTransaction is one item of a DELETE /v1/transactions request that
+// sends transactions instead of ids. Like in the bulk update, ExpectedVersion
+// makes the item fail with version_mismatch unless the transaction is still at
+// that version.
+type DeleteTransaction struct {
+	ID              int64  `json:"id"`
+	ExpectedVersion *int64 `json:"expectedVersion,omitempty"`
+}
+
+// DeleteTransactionsRequest names the transactions to delete either by IDs or,
+// to make the deletes conditional, as Transactions; not both.
 type DeleteTransactionsRequest struct {
-	IDs             []int64 `json:"ids"`
-	DeletedByUserID int64   `json:"deletedByUserId"`
-	Reason          *string `json:"reason,omitempty"`
+	IDs             []int64             `json:"ids,omitempty"`
+	Transactions    []DeleteTransaction `json:"transactions,omitempty"`
+	DeletedByUserID int64               `json:"deletedByUserId"`
+	Reason          *string             `json:"reason,omitempty"`
 }
 
 type RestoreTransactionsRequest struct {
@@ -80,6 +119,13 @@ type GetTransactionQuery struct {
 	IncludeDeleted bool `query:"includeDeleted"`
 }
 
+// ListTransactionsQuery selects a page of GET /v1/transactions. Cursor is the
+// nextCursor of the previous page and only continues the same sort and order;
+// it cannot be combined with Offset. Tags matches transactions carrying any of
+// the tags, or all of them when TagMatch is "all". Query is a filter
+// expression, and Filter names a saved one; transactions must match both.
+// Search matches words of the description or notes, including misspelled
+// ones, and Sort "relevance" orders by how well they match.
 type ListTransactionsQuery struct {
 	IDs            []int64    `query:"ids"`
 	AuthorID       *int64     `query:"authorId"`
@@ -87,10 +133,145 @@ type ListTransactionsQuery struct {
 	FromDate       *time.Time `query:"fromDate"`
 	ToDate         *time.Time `query:"toDate"`
 	Search         *string    `query:"search"`
+	Tags           []string   `query:"tag"`
+	TagMatch       string     `query:"tagMatch"`
+	Query          string     `query:"q"`
+	Filter         string     `query:"filter"`
 	Sort           string     `query:"sort"`
 	SortOrder      string     `query:"sortOrder"`
 	Limit          int32      `query:"limit"`
 	Offset         int32      `query:"offset"`
+	Cursor         string     `query:"cursor"`
 	IncludeDeleted bool       `query:"includeDeleted"`
 	OnlyDeleted    bool       `query:"onlyDeleted"`
 }
+
+// TransactionPage is one page of GET /v1/transactions. NextCursor is absent on
+// the last page.
+type TransactionPage struct {
+	Items      []Transaction `json:"items"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+// TagTotalsQuery scopes GET /v1/transactions/tags to live transactions of an
+// author or household in a date range, narrowed by a filter expression or
+// saved filter as in ListTransactionsQuery.
+type TagTotalsQuery struct {
+	AuthorID    *int64     `query:"authorId"`
+	HouseholdID *int64     `query:"householdId"`
+	FromDate    *time.Time `query:"fromDate"`
+	ToDate      *time.Time `query:"toDate"`
+	Query       string     `query:"q"`
+	Filter      string     `query:"filter"`
+}
+
+// TagTotal is the number of transactions carrying a tag and their summed
+// amount. A transaction with several tags counts towards each of them.
+type TagTotal struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+	Total string `json:"total"`
+}
+
+// AggregateTransactionsQuery groups the transactions GET /v1/transactions
+// would list with the same filters. By holds the dimensions to group by:
+// category, author, household, day, week, month, tag and merchant, with at
+// most one of day, week and month; without any there is one group. Metrics
+// holds sum, count, avg, min and max and defaults to sum and count. Both are
+// repeated or comma-separated.
+type AggregateTransactionsQuery struct {
+	AuthorID       *int64     `query:"authorId"`
+	HouseholdID    *int64     `query:"householdId"`
+	FromDate       *time.Time `query:"fromDate"`
+	ToDate         *time.Time `query:"toDate"`
+	Search         *string    `query:"search"`
+	Tags           []string   `query:"tag"`
+	TagMatch       string     `query:"tagMatch"`
+	Query          string     `query:"q"`
+	Filter         string     `query:"filter"`
+	IncludeDeleted bool       `query:"includeDeleted"`
+	OnlyDeleted    bool       `query:"onlyDeleted"`
+	By             []string   `query:"by"`
+	Metrics        []string   `query:"metrics"`
+}
+
+// TransactionAggregate is the response of GET /v1/transactions/aggregate.
+// Groups are ordered by their dimension values, periods first.
+type TransactionAggregate struct {
+	By      []string         `json:"by"`
+	Metrics []string         `json:"metrics"`
+	Groups  []AggregateGroup `json:"groups"`
+}
+
+// AggregateGroup carries the values of the grouped dimensions and the
+// requested metrics. A grouped dimension is absent for transactions without
+// it, such as uncategorized ones. Period is the UTC start of the day, week
+// (a Monday) or month; merchant is the description, grouped ignoring case;
+// with tag, a transaction counts towards each of its tags. Amounts are
+// decimal strings rounded to cents.
+type AggregateGroup struct {
+	Period        *time.Time   `json:"period,omitempty"`
+	Category      *CategoryRef `json:"category,omitempty"`
+	AuthorID      *int64       `json:"authorId,omitempty"`
+	AuthorName    *string      `json:"authorName,omitempty"`
+	HouseholdID   *int64       `json:"householdId,omitempty"`
+	HouseholdName *string      `json:"householdName,omitempty"`
+	Tag           *string      `json:"tag,omitempty"`
+	Merchant      *string      `json:"merchant,omitempty"`
+	Count         *int64       `json:"count,omitempty"`
+	Sum           *string      `json:"sum,omitempty"`
+	Avg           *string      `json:"avg,omitempty"`
+	Min           *string      `json:"min,omitempty"`
+	Max           *string      `json:"max,omitempty"`
+}
+
+// SavedFilter is a transaction filter expression stored under a name, used
+// with the filter parameter of listings and reports.
+type SavedFilter struct {
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SaveFilterRequest is the body of PUT /v1/saved-filters/{name}, which
+// creates the filter or replaces its query.
+type SaveFilterRequest struct {
+	Query string `json:"query"`
+}
+
+// TransactionChangesQuery reads GET /v1/transactions/changes. Since is the
+// nextToken of an earlier response; leaving it empty starts from the beginning.
+type TransactionChangesQuery struct {
+	Since string `query:"since"`
+	Limit int32  `query:"limit"`
+}
+
+// TransactionChanges lists transactions created, updated, deleted or restored
+// after the requested token in the order they changed. Deleted transactions are
+// included with deletedAt set. Pass NextToken as since to continue; HasMore
+// means another request would return more changes right away.
+type TransactionChanges struct {
+	Items     []Transaction `json:"items"`
+	NextToken string        `json:"nextToken"`
+	HasMore   bool          `json:"hasMore"`
+}
+
+// TransactionHistoryEntry is one create, update, delete or restore of a
+// transaction. ActorUserID is absent for updates that did not name a user.
+type TransactionHistoryEntry struct {
+	ID          int64                    `json:"id"`
+	Action      string                   `json:"action"`
+	ActorUserID *int64                   `json:"actorUserId,omitempty"`
+	ActorName   *string                  `json:"actorName,omitempty"`
+	ChangedAt   time.Time                `json:"changedAt"`
+	Changes     []TransactionFieldChange `json:"changes"`
+}
+
+// TransactionFieldChange is one field's value before and after a change,
+// named as in Transaction. A null value means the field was unset.
+type TransactionFieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
@@ -0,0 +1,70 @@
+package api
+
+import "time"
+
+// Webhook subscribes a URL to finance events. An empty EventTypes receives
+// every type and a missing HouseholdID receives every household. Secret is
+// only returned when the webhook is created.
+type Webhook struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"eventTypes"`
+	HouseholdID *int64    `json:"householdId,omitempty"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// CreateWebhookRequest generates a secret when Secret is omitted.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"eventTypes,omitempty"`
+	HouseholdID *int64   `json:"householdId,omitempty"`
+	Secret      *string  `json:"secret,omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url,omitempty"`
+	EventTypes  *[]string `json:"eventTypes,omitempty"`
+	HouseholdID *int64    `json:"householdId,omitempty"`
+	Secret      *string   `json:"secret,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+
+	ClearHouseholdID bool `json:"clearHouseholdId,omitempty"`
+}
+
+type WebhookQuery struct {
+	HouseholdID *int64 `query:"householdId"`
+}
+
+// WebhookDeliveryQuery lists deliveries newest first. Limit defaults to 20.
+type WebhookDeliveryQuery struct {
+	Limit int `query:"limit"`
+}
+
+// WebhookDelivery is one event sent to one webhook. Status is pending,
+// succeeded or failed; pending deliveries are retried at NextAttemptAt.
+type WebhookDelivery struct {
+	ID            int64                    `json:"id"`
+	WebhookID     int64                    `json:"webhookId"`
+	EventID       int64                    `json:"eventId"`
+	EventType     string                   `json:"eventType"`
+	OccurredAt    time.Time                `json:"occurredAt"`
+	Status        string                   `json:"status"`
+	Attempts      int32                    `json:"attempts"`
+	NextAttemptAt *time.Time               `json:"nextAttemptAt,omitempty"`
+	LastError     *string                  `json:"lastError,omitempty"`
+	DeliveredAt   *time.Time               `json:"deliveredAt,omitempty"`
+	CreatedAt     time.Time                `json:"createdAt"`
+	History       []WebhookDeliveryAttempt `json:"history"`
+}
+
+// WebhookDeliveryAttempt is one request to the webhook URL. StatusCode is
+// absent when no response arrived.
+type WebhookDeliveryAttempt struct {
+	AttemptedAt time.Time `json:"attemptedAt"`
+	StatusCode  *int32    `json:"statusCode,omitempty"`
+	Error       *string   `json:"error,omitempty"`
+	DurationMs  int64     `json:"durationMs"`
+}
@@ -0,0 +1,152 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+type fakeRepository struct {
+	candidates []Candidate
+	recorded   map[[2]int64]bool
+	created    []NewAlert
+	notified   map[int64]*string
+	listFilter ListFilter
+}
+
+func (f *fakeRepository) ListCandidates(context.Context, int64) ([]Candidate, error) {
+	return f.candidates, nil
+}
+func (f *fakeRepository) Create(_ context.Context, input NewAlert) (Alert, bool, error) {
+	key := [2]int64{input.LineID, int64(input.ThresholdPercent)}
+	if f.recorded[key] {
+		return Alert{}, false, nil
+	}
+	if f.recorded == nil {
+		f.recorded = map[[2]int64]bool{}
+	}
+	f.recorded[key] = true
+	f.created = append(f.created, input)
+	return Alert{ID: int64(len(f.created)), BudgetID: input.BudgetID, LineID: input.LineID, ThresholdPercent: input.ThresholdPercent, ActualAmount: input.ActualAmount}, true, nil
+}
+func (f *fakeRepository) List(_ context.Context, filter ListFilter) ([]Alert, error) {
+	f.listFilter = filter
+	return nil, nil
+}
+func (f *fakeRepository) MarkNotified(_ context.Context, id int64, message *string) error {
+	if f.notified == nil {
+		f.notified = map[int64]*string{}
+	}
+	f.notified[id] = message
+	return nil
+}
+
+type notifierFunc func(context.Context, Alert) error
+
+func (f notifierFunc) Notify(ctx context.Context, alert Alert) error { return f(ctx, alert) }
+
+func TestEvaluateTransactionRecordsCrossedThresholdsOnce(t *testing.T) {
+	repo := &fakeRepository{candidates: []Candidate{
+		{BudgetID: 1, LineID: 10, ThresholdPercent: 80, AllocationAmount: "500.00", ActualAmount: "400.00"},
+		{BudgetID: 1, LineID: 10, ThresholdPercent: 100, AllocationAmount: "500.00", ActualAmount: "400.00"},
+		{BudgetID: 1, LineID: 11, ThresholdPercent: 100, AllocationAmount: "0.00", ActualAmount: "0.01"},
+		{BudgetID: 1, LineID: 12, ThresholdPercent: 50, AllocationAmount: "0.00", ActualAmount: "0.00"},
+	}}
+	var sent []int64
+	service := NewService(repo, notifierFunc(func(_ context.Context, alert Alert) error {
+		sent = append(sent, alert.LineID)
+		return nil
+	}))
+	service.now = func() time.Time { return time.Date(2026, 6, 3, 12, 0, 0, 0, time.UTC) }
+
+	created, err := service.EvaluateTransaction(context.Background(), 99)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 || created[0].ThresholdPercent != 80 || created[1].LineID != 11 || created[0].NotifiedAt != nil {
+		t.Fatalf("created=%+v", created)
+	}
+	if len(sent) != 0 || *repo.created[0].TransactionID != 99 {
+		t.Fatalf("sent before dispatch=%v created=%+v", sent, repo.created)
+	}
+	for range created {
+		alert, err := service.Dispatch(context.Background())
+		if err != nil || alert.NotifiedAt == nil || alert.NotificationError != nil {
+			t.Fatalf("dispatched=%+v err=%v", alert, err)
+		}
+	}
+	if !reflect.DeepEqual(sent, []int64{10, 11}) || len(repo.notified) != 2 {
+		t.Fatalf("sent=%v notified=%v", sent, repo.notified)
+	}
+
+	again, err := service.EvaluateTransaction(context.Background(), 100)
+	if err != nil || len(again) != 0 || len(service.queue) != 0 {
+		t.Fatalf("again=%+v err=%v queued=%d", again, err, len(service.queue))
+	}
+}
+
+func TestDispatchKeepsNotifierFailuresOnTheAlert(t *testing.T) {
+	repo := &fakeRepository{candidates: []Candidate{{BudgetID: 1, LineID: 10, ThresholdPercent: 100, AllocationAmount: "10", ActualAmount: "10.00"}}}
+	delivered := false
+	service := NewService(repo,
+		notifierFunc(func(context.Context, Alert) error { return errors.New("relay refused") }),
+		notifierFunc(func(ctx context.Context, _ Alert) error {
+			_, delivered = ctx.Deadline()
+			return nil
+		}),
+	)
+	if _, err := service.EvaluateTransaction(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+	alert, err := service.Dispatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !delivered || alert.NotificationError == nil || *alert.NotificationError != "relay refused" {
+		t.Fatalf("alert=%+v delivered with deadline=%v", alert, delivered)
+	}
+	if message := repo.notified[alert.ID]; message == nil || *message != "relay refused" {
+		t.Fatalf("notified=%v", repo.notified)
+	}
+}
+
+func TestEvaluateTransactionRecordsFullQueueWithoutBlocking(t *testing.T) {
+	repo := &fakeRepository{candidates: []Candidate{{BudgetID: 1, LineID: 10, ThresholdPercent: 100, AllocationAmount: "10", ActualAmount: "10.00"}}}
+	service := NewService(repo, notifierFunc(func(context.Context, Alert) error { return nil }))
+	service.queue = make(chan Alert)
+	created, err := service.EvaluateTransaction(context.Background(), 5)
+	if err != nil || len(created) != 1 || created[0].NotificationError == nil || *created[0].NotificationError != errQueueFull.Error() {
+		t.Fatalf("created=%+v err=%v", created, err)
+	}
+}
+
+func TestDispatchStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewService(&fakeRepository{}).Dispatch(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestListValidatesFilterAndDefaultsLimit(t *testing.T) {
+	repo := &fakeRepository{}
+	service := NewService(repo)
+	items, err := service.List(context.Background(), ListFilter{})
+	if err != nil || items == nil || repo.listFilter.Limit != defaultListLimit {
+		t.Fatalf("items=%v err=%v filter=%+v", items, err, repo.listFilter)
+	}
+	householdID, userID := int64(1), int64(2)
+	for _, filter := range []ListFilter{
+		{Owner: Owner{HouseholdID: &householdID, UserID: &userID}},
+		{Limit: -1},
+		{Limit: maxListLimit + 1},
+	} {
+		if _, err := service.List(context.Background(), filter); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("filter=%+v err=%v", filter, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package alerts
+
+import "time"
+
+// Owner mirrors budget ownership: exactly one of HouseholdID or UserID is set.
+type Owner struct {
+	HouseholdID *int64
+	UserID      *int64
+}
+
+// Alert records that a budget line's actual spend reached one of its alert
+// thresholds. A line alerts at most once per threshold, and since every
+// budget line belongs to exactly one period this is once per period.
+type Alert struct {
+	ID                int64
+	BudgetID          int64
+	LineID            int64
+	LineName          string
+	Owner             Owner
+	PeriodKind        string
+	PeriodStart       time.Time
+	PeriodEnd         time.Time
+	ThresholdPercent  int32
+	AllocationAmount  string
+	ActualAmount      string
+	TransactionID     *int64
+	CreatedAt         time.Time
+	NotifiedAt        *time.Time
+	NotificationError *string
+}
+
+// Candidate is an alert rule that has not fired yet on a line affected by a
+// transaction, together with the line's current actual amount.
+type Candidate struct {
+	BudgetID         int64
+	LineID           int64
+	ThresholdPercent int32
+	AllocationAmount string
+	ActualAmount     string
+}
+
+type NewAlert struct {
+	BudgetID         int64
+	LineID           int64
+	ThresholdPercent int32
+	AllocationAmount string
+	ActualAmount     string
+	TransactionID    *int64
+}
+
+type ListFilter struct {
+	Owner    Owner
+	BudgetID *int64
+	Limit    int32
+}
@@ -0,0 +1,17 @@
+package alerts
+
+import "context"
+
+// Repository owns alert persistence. Create reports false without an error
+// when the line already alerted at the threshold.
+type Repository interface {
+	ListCandidates(ctx context.Context, transactionID int64) ([]Candidate, error)
+	Create(context.Context, NewAlert) (Alert, bool, error)
+	List(context.Context, ListFilter) ([]Alert, error)
+	MarkNotified(ctx context.Context, id int64, notificationError *string) error
+}
+
+// Notifier delivers a newly recorded alert to the outside world.
+type Notifier interface {
+	Notify(context.Context, Alert) error
+}
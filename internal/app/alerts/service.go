@@ -0,0 +1,174 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+const (
+	defaultListLimit int32 = 50
+	maxListLimit     int32 = 500
+
+	// queueSize bounds the alerts waiting for Dispatch. Alerts beyond it are
+	// recorded with a notification error instead of blocking the write that
+	// crossed the threshold.
+	queueSize = 256
+	// notifyTimeout bounds each notifier, so one slow channel cannot hold up
+	// the alerts queued behind it.
+	notifyTimeout = 15 * time.Second
+)
+
+var errQueueFull = errors.New("alert notification queue is full")
+
+type Service struct {
+	repo      Repository
+	notifiers []Notifier
+	queue     chan Alert
+	now       func() time.Time
+}
+
+func NewService(repo Repository, notifiers ...Notifier) *Service {
+	return &Service{repo: repo, notifiers: notifiers, queue: make(chan Alert, queueSize), now: time.Now}
+}
+
+// EvaluateTransaction checks the alert rules of every budget line the
+// transaction counts towards and records the thresholds it pushed the line
+// across. New alerts are queued for Dispatch rather than sent here, so
+// transaction writes never wait on a webhook or mail relay.
+func (s *Service) EvaluateTransaction(ctx context.Context, transactionID int64) ([]Alert, error) {
+	if transactionID == 0 {
+		return nil, apperrors.Validation("transaction id is required")
+	}
+	candidates, err := s.repo.ListCandidates(ctx, transactionID)
+	if err != nil {
+		return nil, apperrors.WrapInternal("list budget alert candidates", err)
+	}
+	created := []Alert{}
+	for _, candidate := range candidates {
+		crossed, err := thresholdCrossed(candidate)
+		if err != nil {
+			return created, apperrors.WrapInternal("evaluate budget alert", err)
+		}
+		if !crossed {
+			continue
+		}
+		alert, isNew, err := s.repo.Create(ctx, NewAlert{
+			BudgetID: candidate.BudgetID, LineID: candidate.LineID, ThresholdPercent: candidate.ThresholdPercent,
+			AllocationAmount: candidate.AllocationAmount, ActualAmount: candidate.ActualAmount, TransactionID: &transactionID,
+		})
+		if err != nil {
+			return created, apperrors.WrapInternal("record budget alert", err)
+		}
+		if !isNew {
+			continue
+		}
+		if alert, err = s.enqueue(ctx, alert); err != nil {
+			return created, apperrors.WrapInternal("mark budget alert notified", err)
+		}
+		created = append(created, alert)
+	}
+	return created, nil
+}
+
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]Alert, error) {
+	if filter.Owner.HouseholdID != nil && filter.Owner.UserID != nil {
+		return nil, apperrors.Validation("at most one alert owner filter is allowed")
+	}
+	if filter.Limit < 0 || filter.Limit > maxListLimit {
+		return nil, apperrors.Validation("limit must be between 1 and 500")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+	items, err := s.repo.List(ctx, filter)
+	if items == nil && err == nil {
+		items = []Alert{}
+	}
+	return items, apperrors.WrapInternal("list budget alerts", err)
+}
+
+// Dispatch waits for the next queued alert and sends it to every notifier.
+// Delivery failures are kept on the returned alert rather than returned, and
+// an error means the attempt could not be recorded. Alerts still queued when
+// the process stops keep an empty notifiedAt.
+func (s *Service) Dispatch(ctx context.Context) (Alert, error) {
+	select {
+	case <-ctx.Done():
+		return Alert{}, ctx.Err()
+	case alert := <-s.queue:
+		var failures []error
+		for _, notifier := range s.notifiers {
+			if err := s.send(ctx, notifier, alert); err != nil {
+				failures = append(failures, err)
+			}
+		}
+		alert, err := s.markNotified(ctx, alert, errors.Join(failures...))
+		return alert, apperrors.WrapInternal("mark budget alert notified", err)
+	}
+}
+
+func (s *Service) send(ctx context.Context, notifier Notifier, alert Alert) error {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	return notifier.Notify(ctx, alert)
+}
+
+func (s *Service) enqueue(ctx context.Context, alert Alert) (Alert, error) {
+	if len(s.notifiers) == 0 {
+		return alert, nil
+	}
+	select {
+	case s.queue <- alert:
+		return alert, nil
+	default:
+		return s.markNotified(ctx, alert, errQueueFull)
+	}
+}
+
+func (s *Service) markNotified(ctx context.Context, alert Alert, failure error) (Alert, error) {
+	var message *string
+	if failure != nil {
+		value := failure.Error()
+		message = &value
+	}
+	if err := s.repo.MarkNotified(ctx, alert.ID, message); err != nil {
+		return alert, err
+	}
+	notifiedAt := s.now().UTC()
+	alert.NotifiedAt, alert.NotificationError = &notifiedAt, message
+	return alert, nil
+}
+
+// thresholdCrossed compares in cents so 80% of 0.10 is exact. A line with a
+// zero allocation crosses every threshold as soon as anything is spent.
+func thresholdCrossed(candidate Candidate) (bool, error) {
+	allocation, err := cents(candidate.AllocationAmount)
+	if err != nil {
+		return false, err
+	}
+	actual, err := cents(candidate.ActualAmount)
+	if err != nil {
+		return false, err
+	}
+	if allocation == 0 {
+		return actual > 0, nil
+	}
+	return actual*100 >= allocation*int64(candidate.ThresholdPercent), nil
+}
+
+func cents(value string) (int64, error) {
+	ratio, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return 0, errors.New("invalid decimal")
+	}
+	ratio.Mul(ratio, big.NewRat(100, 1))
+	if !ratio.IsInt() || !ratio.Num().IsInt64() {
+		return 0, errors.New("amount has more than two decimal places or is out of range")
+	}
+	return ratio.Num().Int64(), nil
+}
@@ -0,0 +1,135 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A receipt")
+
+type fakeRepository struct {
+	created   NewAttachment
+	createErr error
+	deleted   int64
+}
+
+func (f *fakeRepository) Create(_ context.Context, input NewAttachment) (Attachment, error) {
+	f.created = input
+	if f.createErr != nil {
+		return Attachment{}, f.createErr
+	}
+	return Attachment{ID: 3, TransactionID: input.TransactionID, FileName: input.FileName, ContentType: input.ContentType, Size: input.Size, SHA256: input.SHA256, StorageKey: input.StorageKey}, nil
+}
+func (f *fakeRepository) Get(_ context.Context, id int64) (Attachment, error) {
+	return Attachment{ID: id, StorageKey: "transactions/1/key"}, nil
+}
+func (f *fakeRepository) List(context.Context, int64) ([]Attachment, error) { return nil, nil }
+func (f *fakeRepository) Delete(_ context.Context, id int64) (Attachment, error) {
+	f.deleted = id
+	return Attachment{ID: id, StorageKey: "transactions/1/key"}, nil
+}
+
+type fakeBlobs struct {
+	blobs   map[string][]byte
+	types   map[string]string
+	deleted []string
+}
+
+func newFakeBlobs() *fakeBlobs {
+	return &fakeBlobs{blobs: map[string][]byte{}, types: map[string]string{}}
+}
+
+func (f *fakeBlobs) Put(_ context.Context, key, contentType string, content io.Reader, size int64) error {
+	data, err := io.ReadAll(content)
+	if err != nil || int64(len(data)) != size {
+		return errors.New("size mismatch")
+	}
+	f.blobs[key], f.types[key] = data, contentType
+	return nil
+}
+func (f *fakeBlobs) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := f.blobs[key]
+	if !ok {
+		return nil, errors.New("missing blob")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+func (f *fakeBlobs) Delete(_ context.Context, key string) error {
+	delete(f.blobs, key)
+	f.deleted = append(f.deleted, key)
+	return nil
+}
+
+func newTestService(repo *fakeRepository, blobs *fakeBlobs) *Service {
+	service := NewService(repo, blobs)
+	service.newKey = func() (string, error) { return "key", nil }
+	return service
+}
+
+func TestUploadDetectsTypeStoresContentsAndRecordsMetadata(t *testing.T) {
+	repo, blobs := &fakeRepository{}, newFakeBlobs()
+	item, err := newTestService(repo, blobs).Upload(context.Background(), UploadInput{
+		TransactionID: 1, FileName: `C:\Users\me\receipt.png`, Content: bytes.NewReader(pngHeader),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.FileName != "receipt.png" || item.ContentType != "image/png" || item.Size != int64(len(pngHeader)) || len(item.SHA256) != 64 {
+		t.Fatalf("attachment = %+v", item)
+	}
+	if item.StorageKey != "transactions/1/key" || !bytes.Equal(blobs.blobs[item.StorageKey], pngHeader) || blobs.types[item.StorageKey] != "image/png" {
+		t.Fatalf("stored blobs = %v", blobs.types)
+	}
+}
+
+func TestUploadRejectsInvalidFiles(t *testing.T) {
+	tests := map[string]UploadInput{
+		"transaction": {FileName: "a.png", Content: bytes.NewReader(pngHeader)},
+		"file name":   {TransactionID: 1, FileName: " ", Content: bytes.NewReader(pngHeader)},
+		"control":     {TransactionID: 1, FileName: "a\n.png", Content: bytes.NewReader(pngHeader)},
+		"empty":       {TransactionID: 1, FileName: "a.png", Content: strings.NewReader("")},
+		"type":        {TransactionID: 1, FileName: "a.html", Content: strings.NewReader("<html><script></script></html>")},
+		"size":        {TransactionID: 1, FileName: "a.png", Content: io.MultiReader(bytes.NewReader(pngHeader), bytes.NewReader(make([]byte, MaxSize)))},
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			blobs := newFakeBlobs()
+			_, err := newTestService(&fakeRepository{}, blobs).Upload(context.Background(), input)
+			if !apperrors.IsKind(err, apperrors.KindValidation) || len(blobs.blobs) != 0 {
+				t.Fatalf("error = %v, blobs = %d", err, len(blobs.blobs))
+			}
+		})
+	}
+}
+
+func TestUploadRemovesContentsWhenRecordFails(t *testing.T) {
+	repo, blobs := &fakeRepository{createErr: apperrors.NotFound(apperrors.CodeTransactionNotFound, "transaction not found", nil)}, newFakeBlobs()
+	_, err := newTestService(repo, blobs).Upload(context.Background(), UploadInput{TransactionID: 9, FileName: "a.png", Content: bytes.NewReader(pngHeader)})
+	if apperrors.CodeOf(err) != apperrors.CodeTransactionNotFound || len(blobs.blobs) != 0 || len(blobs.deleted) != 1 {
+		t.Fatalf("error = %v, blobs = %v, deleted = %v", err, blobs.blobs, blobs.deleted)
+	}
+}
+
+func TestOpenAndDeleteUseStorageKey(t *testing.T) {
+	repo, blobs := &fakeRepository{}, newFakeBlobs()
+	blobs.blobs["transactions/1/key"] = pngHeader
+	service := newTestService(repo, blobs)
+	_, content, err := service.Open(context.Background(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(content)
+	content.Close()
+	if !bytes.Equal(data, pngHeader) {
+		t.Fatalf("content = %q", data)
+	}
+	if err := service.Delete(context.Background(), 4); err != nil || repo.deleted != 4 || len(blobs.blobs) != 0 {
+		t.Fatalf("delete error = %v, deleted = %d, blobs = %d", err, repo.deleted, len(blobs.blobs))
+	}
+}
@@ -0,0 +1,37 @@
+package attachments
+
+import (
+	"io"
+	"time"
+)
+
+// Attachment is a receipt or other document stored with a transaction. The
+// contents live in the blob store under StorageKey.
+type Attachment struct {
+	ID            int64
+	TransactionID int64
+	FileName      string
+	ContentType   string
+	Size          int64
+	SHA256        string
+	StorageKey    string
+	CreatedAt     time.Time
+}
+
+type UploadInput struct {
+	TransactionID int64
+	FileName      string
+	// Content is read up to MaxSize bytes. Its type is detected from the
+	// bytes themselves rather than taken from the client.
+	Content io.Reader
+}
+
+// NewAttachment is an uploaded file whose contents are already stored.
+type NewAttachment struct {
+	TransactionID int64
+	FileName      string
+	ContentType   string
+	Size          int64
+	SHA256        string
+	StorageKey    string
+}
@@ -0,0 +1,26 @@
+package attachments
+
+import (
+	"context"
+	"io"
+)
+
+type Repository interface {
+	// Create fails with transaction_not_found unless the transaction exists
+	// and is not deleted.
+	Create(context.Context, NewAttachment) (Attachment, error)
+	Get(context.Context, int64) (Attachment, error)
+	// List fails with transaction_not_found when the transaction does not
+	// exist. Deleted transactions keep their attachments.
+	List(ctx context.Context, transactionID int64) ([]Attachment, error)
+	// Delete removes the attachment's row and returns it.
+	Delete(context.Context, int64) (Attachment, error)
+}
+
+// BlobStore keeps attachment contents by key.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds when the key is already gone.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,156 @@
+// Package attachments stores receipts and other documents with transactions.
+// Contents go to a BlobStore and their metadata to the repository.
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+const (
+	// MaxSize is the largest attachment accepted, in bytes.
+	MaxSize = 10 << 20
+
+	maxFileNameLength = 255
+)
+
+// ContentTypes are the accepted attachment types: photos of receipts and PDF
+// documents.
+var ContentTypes = []string{"application/pdf", "image/gif", "image/jpeg", "image/png", "image/webp"}
+
+type Service struct {
+	repo   Repository
+	blobs  BlobStore
+	newKey func() (string, error)
+}
+
+func NewService(repo Repository, blobs BlobStore) *Service {
+	return &Service{repo: repo, blobs: blobs, newKey: randomKey}
+}
+
+// Upload stores the contents and then records the attachment. When the
+// record cannot be written the stored contents are removed again.
+func (s *Service) Upload(ctx context.Context, input UploadInput) (Attachment, error) {
+	if input.TransactionID <= 0 {
+		return Attachment{}, apperrors.Validation("transaction id must be positive")
+	}
+	fileName, err := cleanFileName(input.FileName)
+	if err != nil {
+		return Attachment{}, err
+	}
+	if input.Content == nil {
+		return Attachment{}, apperrors.Validation("attachment is empty")
+	}
+	content, err := io.ReadAll(io.LimitReader(input.Content, MaxSize+1))
+	if err != nil {
+		return Attachment{}, apperrors.Validation("attachment could not be read")
+	}
+	if len(content) == 0 {
+		return Attachment{}, apperrors.Validation("attachment is empty")
+	}
+	if len(content) > MaxSize {
+		return Attachment{}, apperrors.Validation(fmt.Sprintf("attachment exceeds %d bytes", MaxSize))
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	if !slices.Contains(ContentTypes, contentType) {
+		return Attachment{}, apperrors.Validation("attachment must be one of " + strings.Join(ContentTypes, ", "))
+	}
+	key, err := s.newKey()
+	if err != nil {
+		return Attachment{}, apperrors.WrapInternal("generate attachment key", err)
+	}
+	key = fmt.Sprintf("transactions/%d/%s", input.TransactionID, key)
+	if err := s.blobs.Put(ctx, key, contentType, bytes.NewReader(content), int64(len(content))); err != nil {
+		return Attachment{}, apperrors.WrapInternal("store attachment", err)
+	}
+	digest := sha256.Sum256(content)
+	item, err := s.repo.Create(ctx, NewAttachment{
+		TransactionID: input.TransactionID, FileName: fileName, ContentType: contentType,
+		Size: int64(len(content)), SHA256: hex.EncodeToString(digest[:]), StorageKey: key,
+	})
+	if err != nil {
+		_ = s.blobs.Delete(context.WithoutCancel(ctx), key)
+		return Attachment{}, err
+	}
+	return item, nil
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (Attachment, error) {
+	if id <= 0 {
+		return Attachment{}, apperrors.Validation("attachment id must be positive")
+	}
+	return s.repo.Get(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context, transactionID int64) ([]Attachment, error) {
+	if transactionID <= 0 {
+		return nil, apperrors.Validation("transaction id must be positive")
+	}
+	return s.repo.List(ctx, transactionID)
+}
+
+// Open returns the attachment and its contents. The caller closes them.
+func (s *Service) Open(ctx context.Context, id int64) (Attachment, io.ReadCloser, error) {
+	item, err := s.Get(ctx, id)
+	if err != nil {
+		return Attachment{}, nil, err
+	}
+	content, err := s.blobs.Get(ctx, item.StorageKey)
+	if err != nil {
+		return Attachment{}, nil, apperrors.WrapInternal("read attachment", err)
+	}
+	return item, content, nil
+}
+
+// Delete removes the attachment. Its contents are deleted afterwards on a
+// best-effort basis: once the row is gone nothing refers to them.
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return apperrors.Validation("attachment id must be positive")
+	}
+	item, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	_ = s.blobs.Delete(context.WithoutCancel(ctx), item.StorageKey)
+	return nil
+}
+
+// cleanFileName keeps only the base name a client sent, since some send the
+// whole local path.
+func cleanFileName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "" || name == "." || name == "/" {
+		return "", apperrors.Validation("attachment file name is required")
+	}
+	if !utf8.ValidString(name) || utf8.RuneCountInString(name) > maxFileNameLength {
+		return "", apperrors.Validation(fmt.Sprintf("attachment file name must be valid UTF-8 of at most %d characters", maxFileNameLength))
+	}
+	for _, char := range name {
+		if char < 0x20 || char == 0x7f {
+			return "", apperrors.Validation("attachment file name cannot contain control characters")
+		}
+	}
+	return name, nil
+}
+
+func randomKey() (string, error) {
+	var key [16]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key[:]), nil
+}
@@ -2,11 +2,15 @@ package budgets
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"slices"
 	"testing"
 	"time"
 
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/app/events"
 )
 
 type fakeRepository struct {
@@ -15,7 +19,8 @@ type fakeRepository struct {
 	findErr          error
 	created          Budget
 	createErr        error
-	createInput      CreateMonthlyFromTemplateInput
+	createInput      CreateFromTemplateInput
+	findPeriod       Period
 	createdLine      Line
 	createLineErr    error
 	createLine       CreateLineInput
@@ -29,11 +34,35 @@ type fakeRepository struct {
 	detailedSnapshot DetailedReportSnapshot
 	detailedErr      error
 	detailedOwner    Owner
-	detailedStart    time.Time
-	detailedEnd      time.Time
+	detailedPeriod   Period
+	history          []HistoryTransaction
+	historyErr       error
+	historyFrom      time.Time
+	trendSnapshot    TrendSnapshot
+	trendOwner       Owner
+	trendPeriod      Period
+	reallocation     ReallocationResult
+	reallocateErr    error
+	reallocate       ReallocateInput
+	lintSnapshot     LintSnapshot
+	byID             Budget
+	template         Template
+	templateErr      error
+	templateName     string
+	applied          Budget
+	appliedChanges   []LineChange
+	createTemplate   CreateTemplateInput
+	saveTemplate     SaveTemplateInput
+	closing          Closing
+	closingErr       error
+	close            CloseInput
+	reopen           ReopenInput
+	snapshots        map[int64]ReportSnapshot
+	lineage          map[int64][]int64
 }
 
-func (f *fakeRepository) FindMonthly(context.Context, Owner, time.Time, time.Time) (Budget, error) {
+func (f *fakeRepository) FindByPeriod(_ context.Context, _ Owner, period Period) (Budget, error) {
+	f.findPeriod = period
 	if f.monthlyMisses > 0 {
 		f.monthlyMisses--
 		return Budget{}, apperrors.NotFound(apperrors.CodeBudgetNotFound, "budget not found", nil)
@@ -46,7 +75,7 @@ func (f *fakeRepository) FindMonthly(context.Context, Owner, time.Time, time.Tim
 	}
 	return f.monthly, nil
 }
-func (f *fakeRepository) CreateMonthlyFromTemplate(_ context.Context, input CreateMonthlyFromTemplateInput) (Budget, error) {
+func (f *fakeRepository) CreateFromTemplate(_ context.Context, input CreateFromTemplateInput) (Budget, error) {
 	f.createInput = input
 	return f.created, f.createErr
 }
@@ -58,17 +87,82 @@ func (f *fakeRepository) UpdateLineWithCategories(_ context.Context, input Updat
 	f.updateLine = input
 	return f.updatedLine, f.updateLineErr
 }
-func (f *fakeRepository) DeleteLine(_ context.Context, id int64) error {
+func (f *fakeRepository) DeleteLine(_ context.Context, id int64, _ *int64) (int64, error) {
 	f.deletedID = id
-	return f.deleteErr
+	return f.byID.ID, f.deleteErr
 }
-func (f *fakeRepository) LoadReportSnapshot(context.Context, int64) (ReportSnapshot, error) {
+func (f *fakeRepository) Reallocate(_ context.Context, input ReallocateInput) (ReallocationResult, error) {
+	f.reallocate = input
+	return f.reallocation, f.reallocateErr
+}
+func (f *fakeRepository) LoadLintSnapshot(context.Context, int64) (LintSnapshot, error) {
+	return f.lintSnapshot, f.reportErr
+}
+func (f *fakeRepository) LoadReportSnapshot(_ context.Context, id int64) (ReportSnapshot, error) {
+	if snapshot, ok := f.snapshots[id]; ok {
+		return snapshot, f.reportErr
+	}
 	return f.snapshot, f.reportErr
 }
-func (f *fakeRepository) LoadDetailedMonthlySnapshot(_ context.Context, owner Owner, start, end time.Time) (DetailedReportSnapshot, error) {
-	f.detailedOwner, f.detailedStart, f.detailedEnd = owner, start, end
+func (f *fakeRepository) ListLineage(_ context.Context, id int64) ([]int64, error) {
+	return f.lineage[id], nil
+}
+func (f *fakeRepository) LoadDetailedSnapshot(_ context.Context, owner Owner, period Period) (DetailedReportSnapshot, error) {
+	f.detailedOwner, f.detailedPeriod = owner, period
 	return f.detailedSnapshot, f.detailedErr
 }
+func (f *fakeRepository) LoadTrendSnapshot(_ context.Context, owner Owner, period Period) (TrendSnapshot, error) {
+	f.trendOwner, f.trendPeriod = owner, period
+	return f.trendSnapshot, nil
+}
+func (f *fakeRepository) ListLineHistory(_ context.Context, _ int64, from time.Time) ([]HistoryTransaction, error) {
+	f.historyFrom = from
+	return f.history, f.historyErr
+}
+
+func (f *fakeRepository) FindByID(context.Context, int64) (Budget, error) {
+	return f.byID, f.findErr
+}
+func (f *fakeRepository) ApplyLineChanges(_ context.Context, _ int64, changes []LineChange) (Budget, error) {
+	f.appliedChanges = changes
+	return f.applied, nil
+}
+func (f *fakeRepository) CreateTemplate(_ context.Context, input CreateTemplateInput) (Template, error) {
+	f.createTemplate = input
+	return f.template, f.templateErr
+}
+func (f *fakeRepository) GetTemplate(context.Context, int64) (Template, error) {
+	return f.template, f.templateErr
+}
+func (f *fakeRepository) FindTemplateByName(_ context.Context, _ Owner, name string) (Template, error) {
+	f.templateName = name
+	return f.template, f.templateErr
+}
+func (f *fakeRepository) ListTemplates(context.Context, Owner) ([]Template, error) {
+	return []Template{f.template}, f.templateErr
+}
+func (f *fakeRepository) UpdateTemplate(context.Context, UpdateTemplateInput) (Template, error) {
+	return f.template, f.templateErr
+}
+func (f *fakeRepository) DeleteTemplate(context.Context, int64) error {
+	return f.templateErr
+}
+func (f *fakeRepository) SaveTemplate(_ context.Context, input SaveTemplateInput) (Template, error) {
+	f.saveTemplate = input
+	return f.template, f.templateErr
+}
+func (f *fakeRepository) Close(_ context.Context, _ CloseInput, snapshot func(ReportSnapshot) (CloseInput, error)) (Closing, error) {
+	input, err := snapshot(f.snapshot)
+	if err != nil {
+		return Closing{}, err
+	}
+	f.close = input
+	return f.closing, f.closingErr
+}
+func (f *fakeRepository) Reopen(_ context.Context, input ReopenInput) (Closing, error) {
+	f.reopen = input
+	return f.closing, f.closingErr
+}
 
 func TestRepositoryPortExposesOnlyCohesiveOperations(t *testing.T) {
 	port := reflect.TypeOf((*Repository)(nil)).Elem()
@@ -76,7 +170,7 @@ func TestRepositoryPortExposesOnlyCohesiveOperations(t *testing.T) {
 	for i := range port.NumMethod() {
 		got[i] = port.Method(i).Name
 	}
-	want := []string{"CreateLineWithCategories", "CreateMonthlyFromTemplate", "DeleteLine", "FindMonthly", "LoadDetailedMonthlySnapshot", "LoadReportSnapshot", "UpdateLineWithCategories"}
+	want := []string{"ApplyLineChanges", "Close", "CreateFromTemplate", "CreateLineWithCategories", "CreateTemplate", "DeleteLine", "DeleteTemplate", "FindByID", "FindByPeriod", "FindTemplateByName", "GetTemplate", "ListLineHistory", "ListLineage", "ListTemplates", "LoadDetailedSnapshot", "LoadLintSnapshot", "LoadReportSnapshot", "LoadTrendSnapshot", "Reallocate", "Reopen", "SaveTemplate", "UpdateLineWithCategories", "UpdateTemplate"}
 	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("repository methods=%v want=%v", got, want)
 	}
@@ -97,7 +191,7 @@ func TestMonthlyReadAndEnsureUseAggregateRepositoryOperations(t *testing.T) {
 	if err != nil || !result.Created || result.Budget.ID != 20 || len(result.Budget.Lines[0].Categories) != 1 {
 		t.Fatalf("EnsureMonthly=%+v error=%v", result, err)
 	}
-	if repo.createInput.PeriodStart != time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC) || repo.createInput.PeriodEnd != time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC) {
+	if repo.createInput.Period != (Period{Kind: PeriodMonthly, Start: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC)}) {
 		t.Fatalf("create input=%+v", repo.createInput)
 	}
 }
@@ -142,6 +236,78 @@ func TestLineUpdateNormalizesAndDelegatesCategoryReplacement(t *testing.T) {
 	}
 }
 
+func TestLineAlertThresholdsAreValidatedSortedAndDeduplicated(t *testing.T) {
+	repo := &fakeRepository{createdLine: Line{ID: 100, BudgetID: 12}}
+	service := NewService(repo)
+	line, err := service.CreateLine(context.Background(), CreateLineInput{BudgetID: 12, Name: "Food", AllocationAmount: "1", AlertThresholds: []int32{100, 80, 100}})
+	if err != nil || line.AlertThresholds == nil {
+		t.Fatalf("line=%+v error=%v", line, err)
+	}
+	if !reflect.DeepEqual(repo.createLine.AlertThresholds, []int32{80, 100}) {
+		t.Fatalf("repository thresholds=%v", repo.createLine.AlertThresholds)
+	}
+	cleared := []int32{}
+	if _, err := service.UpdateLine(context.Background(), UpdateLineInput{LineID: 100, AlertThresholds: &cleared}); err != nil || repo.updateLine.AlertThresholds == nil || len(*repo.updateLine.AlertThresholds) != 0 {
+		t.Fatalf("update thresholds=%v error=%v", repo.updateLine.AlertThresholds, err)
+	}
+	for _, thresholds := range [][]int32{{0}, {80, 1001}} {
+		if _, err := service.CreateLine(context.Background(), CreateLineInput{BudgetID: 12, Name: "Food", AllocationAmount: "1", AlertThresholds: thresholds}); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("thresholds=%v error=%v", thresholds, err)
+		}
+	}
+}
+
+func TestLineMemberAllocationsAreValidatedAndNormalized(t *testing.T) {
+	amount, percent := "150", "40.5"
+	repo := &fakeRepository{createdLine: Line{ID: 100, BudgetID: 12}}
+	service := NewService(repo)
+	line, err := service.CreateLine(context.Background(), CreateLineInput{BudgetID: 12, Name: "Food", AllocationAmount: "400", MemberAllocations: []MemberAllocationInput{
+		{UserID: 2, Amount: &amount}, {UserID: 3, Percent: &percent},
+	}})
+	if err != nil || line.MemberAllocations == nil {
+		t.Fatalf("line=%+v error=%v", line, err)
+	}
+	if got := repo.createLine.MemberAllocations; len(got) != 2 || *got[0].Amount != "150.00" || *got[1].Percent != "40.50" {
+		t.Fatalf("repository members=%+v", got)
+	}
+	cleared := []MemberAllocationInput{}
+	if _, err := service.UpdateLine(context.Background(), UpdateLineInput{LineID: 100, MemberAllocations: &cleared}); err != nil || repo.updateLine.MemberAllocations == nil || len(*repo.updateLine.MemberAllocations) != 0 {
+		t.Fatalf("update members=%v error=%v", repo.updateLine.MemberAllocations, err)
+	}
+	sixty, zero, over := "60", "0", "100.01"
+	for name, members := range map[string][]MemberAllocationInput{
+		"missing user":  {{Amount: &amount}},
+		"duplicate":     {{UserID: 2, Amount: &amount}, {UserID: 2, Percent: &percent}},
+		"both kinds":    {{UserID: 2, Amount: &amount, Percent: &percent}},
+		"neither kind":  {{UserID: 2}},
+		"zero percent":  {{UserID: 2, Percent: &zero}},
+		"over 100":      {{UserID: 2, Percent: &over}},
+		"total percent": {{UserID: 2, Percent: &sixty}, {UserID: 3, Percent: &percent}},
+	} {
+		if _, err := service.CreateLine(context.Background(), CreateLineInput{BudgetID: 12, Name: "Food", AllocationAmount: "1", MemberAllocations: members}); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("%s: error=%v", name, err)
+		}
+	}
+
+	rest := "250.01"
+	overspent := []MemberAllocationInput{{UserID: 2, Amount: &amount}, {UserID: 3, Amount: &rest}}
+	if _, err := service.CreateLine(context.Background(), CreateLineInput{BudgetID: 12, Name: "Food", AllocationAmount: "400", MemberAllocations: overspent}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("fixed amounts above the allocation: error=%v", err)
+	}
+	allocation := "400.01"
+	if _, err := service.UpdateLine(context.Background(), UpdateLineInput{LineID: 100, AllocationAmount: &allocation, MemberAllocations: &overspent}); err != nil {
+		t.Fatalf("fixed amounts filling the allocation: error=%v", err)
+	}
+	// 150.00 plus 60% fills 375.00 exactly but not 374.99.
+	shared := []MemberAllocationInput{{UserID: 2, Amount: &amount}, {UserID: 4, Percent: &sixty}}
+	if _, err := service.CreateLine(context.Background(), CreateLineInput{BudgetID: 12, Name: "Food", AllocationAmount: "375", MemberAllocations: shared}); err != nil {
+		t.Fatalf("amounts and shares filling the allocation: error=%v", err)
+	}
+	if _, err := service.CreateLine(context.Background(), CreateLineInput{BudgetID: 12, Name: "Food", AllocationAmount: "374.99", MemberAllocations: shared}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("amounts and shares above the allocation: error=%v", err)
+	}
+}
+
 func TestLineRepositoryErrorsPreserveSafeKinds(t *testing.T) {
 	repo := &fakeRepository{createLineErr: apperrors.Conflict(apperrors.CodeBudgetConflict, "category already mapped to another budget line", nil)}
 	_, err := NewService(repo).CreateLine(context.Background(), CreateLineInput{BudgetID: 12, Name: "Food", AllocationAmount: "1", CategoryCodes: []string{"food"}})
@@ -172,6 +338,75 @@ func TestMonthlyValidation(t *testing.T) {
 	}
 }
 
+func TestPeriodResolution(t *testing.T) {
+	userID := int64(2)
+	owner := Owner{UserID: &userID}
+	date := time.Date(2026, 10, 19, 15, 30, 0, 0, time.UTC)
+	anchor := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	start, end := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 9, 0, 0, 0, 0, time.UTC)
+	utc := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	for name, test := range map[string]struct {
+		input PeriodInput
+		want  Period
+	}{
+		"weekly starts monday":      {PeriodInput{Kind: PeriodWeekly, Date: date}, Period{PeriodWeekly, utc(2026, 10, 19), utc(2026, 10, 25)}},
+		"weekly from sunday":        {PeriodInput{Kind: PeriodWeekly, Date: utc(2026, 10, 25)}, Period{PeriodWeekly, utc(2026, 10, 19), utc(2026, 10, 25)}},
+		"biweekly follows anchor":   {PeriodInput{Kind: PeriodBiweekly, Date: date, Anchor: &anchor}, Period{PeriodBiweekly, utc(2026, 10, 9), utc(2026, 10, 22)}},
+		"biweekly before anchor":    {PeriodInput{Kind: PeriodBiweekly, Date: utc(2025, 12, 25), Anchor: &anchor}, Period{PeriodBiweekly, utc(2025, 12, 19), utc(2026, 1, 1)}},
+		"biweekly on anchor cycle":  {PeriodInput{Kind: PeriodBiweekly, Date: utc(2025, 12, 19), Anchor: &anchor}, Period{PeriodBiweekly, utc(2025, 12, 19), utc(2026, 1, 1)}},
+		"monthly":                   {PeriodInput{Kind: PeriodMonthly, Date: date}, Period{PeriodMonthly, utc(2026, 10, 1), utc(2026, 10, 31)}},
+		"quarterly":                 {PeriodInput{Kind: PeriodQuarterly, Date: date}, Period{PeriodQuarterly, utc(2026, 10, 1), utc(2026, 12, 31)}},
+		"annual":                    {PeriodInput{Kind: PeriodAnnual, Date: date}, Period{PeriodAnnual, utc(2026, 1, 1), utc(2026, 12, 31)}},
+		"custom uses explicit span": {PeriodInput{Kind: PeriodCustom, Start: &start, End: &end}, Period{PeriodCustom, start, end}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := &fakeRepository{monthly: Budget{ID: 3}}
+			test.input.Owner = owner
+			if _, err := NewService(repo).GetPeriod(context.Background(), test.input); err != nil {
+				t.Fatal(err)
+			}
+			if repo.findPeriod != test.want {
+				t.Fatalf("period=%+v want=%+v", repo.findPeriod, test.want)
+			}
+		})
+	}
+}
+
+func TestPeriodValidation(t *testing.T) {
+	userID := int64(2)
+	owner := Owner{UserID: &userID}
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	earlier := date.AddDate(0, 0, -1)
+	for name, input := range map[string]PeriodInput{
+		"missing owner":            {Kind: PeriodMonthly, Date: date},
+		"unknown kind":             {Owner: owner, Kind: "fortnightly", Date: date},
+		"missing date":             {Owner: owner, Kind: PeriodWeekly},
+		"biweekly without anchor":  {Owner: owner, Kind: PeriodBiweekly, Date: date},
+		"anchor on monthly":        {Owner: owner, Kind: PeriodMonthly, Date: date, Anchor: &date},
+		"custom without end":       {Owner: owner, Kind: PeriodCustom, Start: &date},
+		"custom end before start":  {Owner: owner, Kind: PeriodCustom, Start: &date, End: &earlier},
+		"start on non-custom kind": {Owner: owner, Kind: PeriodQuarterly, Date: date, Start: &date, End: &date},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewService(&fakeRepository{}).EnsurePeriod(context.Background(), input); !apperrors.IsKind(err, apperrors.KindValidation) {
+				t.Fatalf("error=%v", err)
+			}
+		})
+	}
+}
+
+func TestEnsurePeriodSurfacesOverlapConflict(t *testing.T) {
+	userID := int64(2)
+	repo := &fakeRepository{createErr: apperrors.Conflict(apperrors.CodeBudgetConflict, "budget period overlaps", nil)}
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	_, err := NewService(repo).EnsurePeriod(context.Background(), PeriodInput{Owner: Owner{UserID: &userID}, Kind: PeriodCustom, Start: &date, End: &date})
+	if !apperrors.IsKind(err, apperrors.KindConflict) || repo.createInput.Period.Kind != PeriodCustom {
+		t.Fatalf("error=%v input=%+v", err, repo.createInput)
+	}
+}
+
 func TestLineInputValidationAndDelete(t *testing.T) {
 	repo := &fakeRepository{createdLine: Line{ID: 1, BudgetID: 12}}
 	service := NewService(repo)
@@ -182,11 +417,50 @@ func TestLineInputValidationAndDelete(t *testing.T) {
 			}
 		})
 	}
-	if err := service.DeleteLine(context.Background(), 9); err != nil || repo.deletedID != 9 {
+	if err := service.DeleteLine(context.Background(), 9, nil); err != nil || repo.deletedID != 9 {
 		t.Fatalf("deletedID=%d error=%v", repo.deletedID, err)
 	}
+	zero := int64(0)
+	if err := service.DeleteLine(context.Background(), 9, &zero); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("expected version error=%v", err)
+	}
+}
+
+func TestLineWritesPublishEventsForTheBudgetHousehold(t *testing.T) {
+	householdID := int64(4)
+	repo := &fakeRepository{
+		byID:         Budget{ID: 12, Owner: Owner{HouseholdID: &householdID}},
+		updatedLine:  Line{ID: 1, BudgetID: 12, AllocationAmount: "10"},
+		reallocation: ReallocationResult{FromLine: Line{ID: 1, BudgetID: 12}, ToLine: Line{ID: 2, BudgetID: 12}},
+	}
+	var published []events.Event
+	service := NewService(repo).WithEvents(publisherFunc(func(event events.Event) { published = append(published, event) }))
+	name := "Food"
+	if _, err := service.UpdateLine(context.Background(), UpdateLineInput{LineID: 1, Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Reallocate(context.Background(), ReallocateInput{BudgetID: 12, FromLineID: 1, ToLineID: 2, UserID: 3, Amount: "5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.DeleteLine(context.Background(), 2, nil); err != nil {
+		t.Fatal(err)
+	}
+	var lines []int64
+	for _, event := range published {
+		if event.Type != events.BudgetLineChanged || event.BudgetID != 12 || event.HouseholdID == nil || *event.HouseholdID != householdID {
+			t.Fatalf("event=%+v", event)
+		}
+		lines = append(lines, event.BudgetLineID)
+	}
+	if !slices.Equal(lines, []int64{1, 1, 2, 2}) {
+		t.Fatalf("changed lines=%v", lines)
+	}
 }
 
+type publisherFunc func(events.Event)
+
+func (f publisherFunc) Publish(_ context.Context, event events.Event) { f(event) }
+
 func TestReportAssemblesTotalsAndUnmappedRequirements(t *testing.T) {
 	householdID := int64(1)
 	repo := &fakeRepository{snapshot: ReportSnapshot{
@@ -201,6 +475,265 @@ func TestReportAssemblesTotalsAndUnmappedRequirements(t *testing.T) {
 	}
 }
 
+func TestReallocateNormalizesAndValidatesMoves(t *testing.T) {
+	repo := &fakeRepository{reallocation: ReallocationResult{Reallocation: Reallocation{ID: 5, Amount: "25.00"}, FromLine: Line{ID: 1}, ToLine: Line{ID: 2}}}
+	service := NewService(repo)
+	reason := "  groceries ran over  "
+	result, err := service.Reallocate(context.Background(), ReallocateInput{BudgetID: 12, FromLineID: 1, ToLineID: 2, Amount: "25", Reason: &reason, UserID: 3})
+	if err != nil || repo.reallocate.Amount != "25.00" || *repo.reallocate.Reason != "groceries ran over" || result.FromLine.Categories == nil || result.ToLine.AlertThresholds == nil {
+		t.Fatalf("result=%+v input=%+v error=%v", result, repo.reallocate, err)
+	}
+	blank := " "
+	if _, err := service.Reallocate(context.Background(), ReallocateInput{BudgetID: 12, FromLineID: 1, ToLineID: 2, Amount: "1", Reason: &blank, UserID: 3}); err != nil || repo.reallocate.Reason != nil {
+		t.Fatalf("blank reason=%v error=%v", repo.reallocate.Reason, err)
+	}
+	for _, input := range []ReallocateInput{
+		{FromLineID: 1, ToLineID: 2, Amount: "1", UserID: 3},
+		{BudgetID: 12, ToLineID: 2, Amount: "1", UserID: 3},
+		{BudgetID: 12, FromLineID: 2, ToLineID: 2, Amount: "1", UserID: 3},
+		{BudgetID: 12, FromLineID: 1, ToLineID: 2, Amount: "1"},
+		{BudgetID: 12, FromLineID: 1, ToLineID: 2, Amount: "0", UserID: 3},
+		{BudgetID: 12, FromLineID: 1, ToLineID: 2, Amount: "-4", UserID: 3},
+		{BudgetID: 12, FromLineID: 1, ToLineID: 2, Amount: "1.001", UserID: 3},
+	} {
+		if _, err := service.Reallocate(context.Background(), input); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("input=%+v error=%v", input, err)
+		}
+	}
+	repo.reallocateErr = apperrors.Conflict(apperrors.CodeBudgetConflict, "reallocation amount exceeds the source line allocation", nil)
+	if _, err := service.Reallocate(context.Background(), ReallocateInput{BudgetID: 12, FromLineID: 1, ToLineID: 2, Amount: "1", UserID: 3}); !apperrors.IsKind(err, apperrors.KindConflict) {
+		t.Fatalf("conflict error=%v", err)
+	}
+}
+
+func TestReportShowsOriginalAndReallocatedAllocations(t *testing.T) {
+	repo := &fakeRepository{snapshot: ReportSnapshot{
+		Budget: Budget{ID: 12},
+		Lines: []ReportLineData{
+			{Line: Line{ID: 1, BudgetID: 12, Name: "Groceries", AllocationAmount: "650.00"}, ActualAmount: "610.00", OriginalAllocationAmount: "600"},
+			{Line: Line{ID: 2, BudgetID: 12, Name: "Entertainment", AllocationAmount: "150.00"}, ActualAmount: "20.00", OriginalAllocationAmount: "200.00"},
+			{Line: Line{ID: 3, BudgetID: 12, Name: "Rent", AllocationAmount: "1000.00"}, ActualAmount: "1000.00"},
+		},
+		UncategorizedAmount: "0",
+		Reallocations:       []Reallocation{{ID: 5, FromLine: LineRef{ID: 2}, ToLine: LineRef{ID: 1}, Amount: "50", CreatedBy: Author{ID: 3, Name: "Alex"}}},
+	}}
+	report, err := NewService(repo).Report(context.Background(), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := [][2]string{}
+	for _, line := range report.Lines {
+		got = append(got, [2]string{line.OriginalAllocationAmount, line.ReallocatedAmount})
+	}
+	want := [][2]string{{"600.00", "50.00"}, {"200.00", "-50.00"}, {"1000.00", "0.00"}}
+	if !reflect.DeepEqual(got, want) || report.Totals.AllocationAmount != "1800.00" || len(report.Reallocations) != 1 || report.Reallocations[0].Amount != "50.00" {
+		t.Fatalf("lines=%v totals=%+v reallocations=%+v", got, report.Totals, report.Reallocations)
+	}
+
+	repo.snapshot.Reallocations = nil
+	if report, err := NewService(repo).Report(context.Background(), 12); err != nil || report.Reallocations == nil {
+		t.Fatalf("reallocations=%v error=%v", report.Reallocations, err)
+	}
+}
+
+func TestCloseSnapshotsReportAndReopenRequiresReason(t *testing.T) {
+	repo := &fakeRepository{
+		snapshot: ReportSnapshot{
+			Budget:              Budget{ID: 12},
+			Lines:               []ReportLineData{{Line: Line{ID: 1, BudgetID: 12, Name: "Food", AllocationAmount: "100.00", SortOrder: 1}, ActualAmount: "60.25"}},
+			UncategorizedAmount: "4.5",
+		},
+		closing: Closing{ID: 3, BudgetID: 12, Totals: ReportTotals{AllocationAmount: "100", ActualAmount: "60.25"}},
+	}
+	service := NewService(repo)
+	closing, err := service.Close(context.Background(), CloseInput{BudgetID: 12, UserID: 2})
+	if err != nil || len(repo.close.Lines) != 1 || *repo.close.Lines[0].LineID != 1 || repo.close.Lines[0].ActualAmount != "60.25" || repo.close.Totals.UncategorizedActualAmount != "4.50" {
+		t.Fatalf("close input=%+v error=%v", repo.close, err)
+	}
+	if closing.Lines == nil || closing.Totals.RemainingAmount != "39.75" || closing.Totals.UnmappedActualAmount != "0.00" {
+		t.Fatalf("closing=%+v", closing)
+	}
+	for _, input := range []CloseInput{{UserID: 2}, {BudgetID: 12}} {
+		if _, err := service.Close(context.Background(), input); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("input=%+v error=%v", input, err)
+		}
+	}
+	repo.snapshot.Closing = &Closing{ID: 3, Lines: []ClosingLine{}}
+	if _, err := service.Close(context.Background(), CloseInput{BudgetID: 12, UserID: 2}); apperrors.CodeOf(err) != apperrors.CodeBudgetClosed {
+		t.Fatalf("closed budget error=%v", err)
+	}
+
+	if _, err := service.Reopen(context.Background(), ReopenInput{BudgetID: 12, UserID: 2, Reason: "  late refund "}); err != nil || repo.reopen.Reason != "late refund" {
+		t.Fatalf("reopen input=%+v error=%v", repo.reopen, err)
+	}
+	for _, input := range []ReopenInput{{UserID: 2, Reason: "x"}, {BudgetID: 12, Reason: "x"}, {BudgetID: 12, UserID: 2, Reason: "  "}} {
+		if _, err := service.Reopen(context.Background(), input); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("input=%+v error=%v", input, err)
+		}
+	}
+}
+
+func TestReportServesClosedBudgetFromSnapshotAndFlagsDrift(t *testing.T) {
+	repo := &fakeRepository{snapshot: ReportSnapshot{
+		Budget: Budget{ID: 12},
+		Lines: []ReportLineData{
+			{Line: Line{ID: 1, BudgetID: 12, Name: "Food", AllocationAmount: "100.00"}, ActualAmount: "70.00"},
+			{Line: Line{ID: 2, BudgetID: 12, Name: "Rent", AllocationAmount: "900.00"}, ActualAmount: "900.00"},
+		},
+		UncategorizedAmount: "0",
+		Closing: &Closing{
+			ID: 3, ClosedBy: Author{ID: 2, Name: "Ana"},
+			Lines:  []ClosingLine{{LineID: int64Pointer(1), Name: "Food", ActualAmount: "60.00"}, {LineID: int64Pointer(2), Name: "Rent", ActualAmount: "900.00"}},
+			Totals: ReportTotals{AllocationAmount: "1000.00", ActualAmount: "960.00", UnmappedActualAmount: "0.00", UncategorizedActualAmount: "0.00"},
+		},
+	}}
+	report, err := NewService(repo).Report(context.Background(), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Lines[0].ActualAmount != "60.00" || report.Lines[0].RemainingAmount != "40.00" || report.Totals.ActualAmount != "960.00" || report.Totals.RemainingAmount != "40.00" {
+		t.Fatalf("lines=%+v totals=%+v", report.Lines, report.Totals)
+	}
+	want := []LineDrift{{LineID: 1, Name: "Food", ClosedActualAmount: "60.00", CurrentActualAmount: "70.00"}}
+	if report.Closing == nil || !report.Closing.Drifted || !reflect.DeepEqual(report.Closing.Drift, want) || report.Closing.CurrentTotals.ActualAmount != "970.00" {
+		t.Fatalf("closing=%+v", report.Closing)
+	}
+
+	repo.snapshot.Lines[0].ActualAmount = "60.00"
+	if report, err := NewService(repo).Report(context.Background(), 12); err != nil || report.Closing.Drifted || len(report.Closing.Drift) != 0 {
+		t.Fatalf("closing=%+v error=%v", report.Closing, err)
+	}
+}
+
+func TestDetailedReportServesClosedBudgetFromSnapshotWithoutForecasting(t *testing.T) {
+	userID := int64(8)
+	repo := &fakeRepository{
+		byID: Budget{ID: 12, Owner: Owner{UserID: &userID}, PeriodKind: PeriodMonthly, PeriodStart: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC)},
+		detailedSnapshot: DetailedReportSnapshot{
+			Budget: Budget{ID: 12, Owner: Owner{UserID: &userID}},
+			Lines: []DetailedReportLineData{
+				{ReportLineData: ReportLineData{Line: Line{ID: 1, BudgetID: 12, Name: "Food", AllocationAmount: "100.00"}, ActualAmount: "70.00"}, Transactions: []DetailedTransaction{{ID: 31, Amount: "70.00"}}},
+			},
+			UncategorizedAmount: "0",
+			Closing: &Closing{
+				ID: 3, ClosedBy: Author{ID: 2, Name: "Ana"},
+				Lines:  []ClosingLine{{LineID: int64Pointer(1), Name: "Food", ActualAmount: "60.00"}},
+				Totals: ReportTotals{AllocationAmount: "100.00", ActualAmount: "60.00", UnmappedActualAmount: "0.00", UncategorizedActualAmount: "0.00"},
+			},
+		},
+		historyErr: errors.New("history is not needed"),
+	}
+	service := NewService(repo)
+	service.now = func() time.Time { return time.Date(2026, 7, 11, 0, 0, 0, 0, time.UTC) }
+	report, err := service.DetailedReport(context.Background(), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Lines[0].ActualAmount != "60.00" || report.Lines[0].RemainingAmount != "40.00" || report.Totals.ActualAmount != "60.00" || report.Totals.RemainingAmount != "40.00" {
+		t.Fatalf("lines=%+v totals=%+v", report.Lines, report.Totals)
+	}
+	want := []LineDrift{{LineID: 1, Name: "Food", ClosedActualAmount: "60.00", CurrentActualAmount: "70.00"}}
+	if report.Closing == nil || !report.Closing.Drifted || !reflect.DeepEqual(report.Closing.Drift, want) || report.Closing.CurrentTotals.ActualAmount != "70.00" {
+		t.Fatalf("closing=%+v", report.Closing)
+	}
+	if got := report.Lines[0].Forecast; got.ProjectedAmount != "60.00" || got.RecurringPendingAmount != "0.00" || got.Risk != ForecastOnTrack {
+		t.Fatalf("line forecast=%+v", got)
+	}
+	if got := report.Forecast; got.ProjectedAmount != "60.00" || got.ProjectedRemainingAmount != "40.00" {
+		t.Fatalf("report forecast=%+v", got)
+	}
+}
+
+func TestCompareAlignsLinesByNameAndRenamesWithinLineage(t *testing.T) {
+	groceries, dining, rent := Category{ID: 3, Code: "groceries"}, Category{ID: 4, Code: "dining"}, Category{ID: 5, Code: "rent"}
+	repo := &fakeRepository{
+		snapshots: map[int64]ReportSnapshot{
+			12: {Budget: Budget{ID: 12}, UncategorizedAmount: "0", Lines: []ReportLineData{
+				{Line: Line{ID: 1, Name: "Food", AllocationAmount: "400.00", Categories: []Category{groceries}}, ActualAmount: "380.00"},
+				{Line: Line{ID: 2, Name: "Housing", AllocationAmount: "1000.00", Categories: []Category{rent}}, ActualAmount: "1000.00"},
+				{Line: Line{ID: 3, Name: "Gym", AllocationAmount: "40.00"}, ActualAmount: "40.00"},
+			}},
+			13: {Budget: Budget{ID: 13, SourceBudgetID: int64Pointer(12)}, UncategorizedAmount: "0", Lines: []ReportLineData{
+				{Line: Line{ID: 7, Name: " food ", AllocationAmount: "450.00", Categories: []Category{groceries, dining}}, ActualAmount: "100.00"},
+				{Line: Line{ID: 8, Name: "Rent", AllocationAmount: "1000.00", Categories: []Category{rent}}, ActualAmount: "0"},
+				{Line: Line{ID: 9, Name: "Travel", AllocationAmount: "200.00"}, ActualAmount: "0"},
+			}},
+		},
+		lineage: map[int64][]int64{13: {12}},
+	}
+	service := NewService(repo)
+	comparison, err := service.Compare(context.Background(), ComparisonInput{A: 12, B: 13})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type row struct {
+		status             LineComparisonStatus
+		matchedBy          LineMatch
+		allocation, actual string
+		added, removed     int
+	}
+	got := []row{}
+	for _, line := range comparison.Lines {
+		got = append(got, row{line.Status, line.MatchedBy, line.AllocationDelta, line.ActualDelta, len(line.AddedCategories), len(line.RemovedCategories)})
+	}
+	want := []row{
+		{LineMatched, LineMatchName, "50.00", "-280.00", 1, 0},
+		{LineMatched, LineMatchCategories, "0.00", "-1000.00", 0, 0},
+		{LineInBOnly, "", "200.00", "0.00", 0, 0},
+		{LineInAOnly, "", "-40.00", "-40.00", 0, 0},
+	}
+	if !reflect.DeepEqual(got, want) || comparison.Lines[1].A.Name != "Housing" || comparison.Lines[2].A != nil || comparison.Lines[3].B != nil {
+		t.Fatalf("lines=%+v", got)
+	}
+	if comparison.Lineage == nil || *comparison.Lineage != (Lineage{AncestorID: 12, DescendantID: 13, Generations: 1}) || comparison.Totals.AllocationDelta != "210.00" || comparison.Totals.ActualDelta != "-1320.00" {
+		t.Fatalf("lineage=%+v totals=%+v", comparison.Lineage, comparison.Totals)
+	}
+
+	repo.lineage = nil
+	comparison, err = service.Compare(context.Background(), ComparisonInput{A: 12, B: 13})
+	if err != nil || comparison.Lineage != nil || len(comparison.Lines) != 5 || comparison.Lines[1].Status != LineInBOnly {
+		t.Fatalf("unrelated comparison=%+v error=%v", comparison, err)
+	}
+	for _, input := range []ComparisonInput{{B: 13}, {A: 12}, {A: 12, B: 12}} {
+		if _, err := service.Compare(context.Background(), input); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("input=%+v error=%v", input, err)
+		}
+	}
+}
+
+func TestLintReportsOverlapsUnmappedCategoriesAndEmptyLines(t *testing.T) {
+	food, dining, gifts, fuel := Category{ID: 1, Code: "food", Name: "Food"}, Category{ID: 2, Code: "dining", Name: "Dining"}, Category{ID: 3, Code: "gifts", Name: "Gifts"}, Category{ID: 4, Code: "fuel", Name: "Fuel"}
+	repo := &fakeRepository{lintSnapshot: LintSnapshot{
+		Budget: Budget{ID: 12, Lines: []Line{
+			{ID: 7, Name: "Eating out", SortOrder: 2, Categories: []Category{dining, food}},
+			{ID: 6, Name: "Groceries", SortOrder: 1, Categories: []Category{food}},
+			{ID: 8, Name: "Misc", SortOrder: 3},
+		}},
+		ActiveCategories:     []Category{gifts, food, fuel, dining},
+		UnmappedTransactions: []UnmappedTransaction{{ID: 1, Amount: "25.5", Category: &gifts}, {ID: 2, Amount: "14.50", Category: &gifts}, {ID: 3, Amount: "9.00"}},
+	}}
+	lint, err := NewService(repo).Lint(context.Background(), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(lint.Issues))
+	for _, issue := range lint.Issues {
+		got = append(got, fmt.Sprintf("%s %v %s", issue.Kind, issue.Lines, issue.ActualAmount))
+	}
+	want := []string{"category_overlap [{6 Groceries} {7 Eating out}] ", "unmapped_category [] 0.00", "unmapped_category [] 40.00", "empty_line [{8 Misc}] "}
+	if lint.Budget.ID != 12 || !reflect.DeepEqual(got, want) || lint.Issues[1].Category.Code != "fuel" || lint.Issues[2].Category.Code != "gifts" {
+		t.Fatalf("issues=%v want=%v", got, want)
+	}
+
+	repo.lintSnapshot = LintSnapshot{Budget: Budget{ID: 13}}
+	if clean, err := NewService(repo).Lint(context.Background(), 13); err != nil || clean.Issues == nil || len(clean.Issues) != 0 {
+		t.Fatalf("clean lint=%+v error=%v", clean, err)
+	}
+	if _, err := NewService(repo).Lint(context.Background(), 0); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("validation error=%v", err)
+	}
+}
+
 func TestReportSupportsNegativeValuesAndEmptyCollections(t *testing.T) {
 	repo := &fakeRepository{snapshot: ReportSnapshot{Budget: Budget{ID: 12}, Lines: []ReportLineData{{Line: Line{ID: 1, BudgetID: 12, AllocationAmount: "0.00"}, ActualAmount: "-5.25"}}, UncategorizedAmount: "0"}}
 	report, err := NewService(repo).Report(context.Background(), 12)
@@ -248,8 +781,8 @@ func TestDetailedMonthlyReportMapsTransactionsAndPreservesAggregateTotals(t *tes
 	if err != nil || !reflect.DeepEqual(report.Totals, aggregate.Totals) || report.Lines[0].ActualAmount != aggregate.Lines[0].ActualAmount {
 		t.Fatalf("detailed report diverged from aggregate: detailed=%+v aggregate=%+v error=%v", report, aggregate, err)
 	}
-	if repo.detailedStart != time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC) || repo.detailedEnd != time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC) {
-		t.Fatalf("snapshot period=%s..%s", repo.detailedStart, repo.detailedEnd)
+	if repo.detailedPeriod.Kind != PeriodMonthly || repo.detailedPeriod.Start != time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC) || repo.detailedPeriod.End != time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("snapshot period=%+v", repo.detailedPeriod)
 	}
 }
 
@@ -269,4 +802,427 @@ func TestDetailedMonthlyReportNormalizesEmptyCollectionsAndErrors(t *testing.T)
 	}
 }
 
-func int64Pointer(value int64) *int64 { return &value }
+func TestDetailedMonthlyReportSplitsLineSpendingByMember(t *testing.T) {
+	householdID := int64(5)
+	amount, percent := "100", "33.33"
+	alex, sam, kim := Author{ID: 2, Name: "Alex"}, Author{ID: 3, Name: "Sam"}, Author{ID: 4, Name: "Kim"}
+	repo := &fakeRepository{detailedSnapshot: DetailedReportSnapshot{
+		Budget: Budget{ID: 12, Owner: Owner{HouseholdID: &householdID}},
+		Lines: []DetailedReportLineData{
+			{
+				ReportLineData: ReportLineData{Line: Line{ID: 1, BudgetID: 12, AllocationAmount: "300", MemberAllocations: []MemberAllocation{
+					{Member: alex, Amount: &amount}, {Member: sam, Percent: &percent},
+				}}, ActualAmount: "185.00"},
+				Transactions: []DetailedTransaction{
+					{ID: 21, Amount: "120", Author: alex}, {ID: 22, Amount: "40", Author: sam}, {ID: 23, Amount: "25", Author: kim},
+				},
+			},
+			{
+				ReportLineData: ReportLineData{Line: Line{ID: 2, BudgetID: 12, AllocationAmount: "50"}, ActualAmount: "10.00"},
+				Transactions:   []DetailedTransaction{{ID: 24, Amount: "10", Author: alex}},
+			},
+		},
+		UncategorizedAmount: "0",
+	}}
+	report, err := NewService(repo).DetailedMonthlyReport(context.Background(), MonthlyInput{Owner: Owner{HouseholdID: &householdID}, Year: 2026, Month: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MemberSpending{
+		{Member: alex, AllocationAmount: "100.00", ActualAmount: "120.00", RemainingAmount: "-20.00"},
+		{Member: sam, AllocationAmount: "99.99", ActualAmount: "40.00", RemainingAmount: "59.99"},
+		{Member: kim, AllocationAmount: "0.00", ActualAmount: "25.00", RemainingAmount: "-25.00"},
+	}
+	if !reflect.DeepEqual(report.Lines[0].Members, want) {
+		t.Fatalf("members=%+v", report.Lines[0].Members)
+	}
+	if report.Lines[1].Members == nil || len(report.Lines[1].Members) != 0 {
+		t.Fatalf("line without member allocations=%+v", report.Lines[1].Members)
+	}
+}
+
+func TestDetailedReportLoadsAnyBudgetPeriodByID(t *testing.T) {
+	userID := int64(8)
+	start, end := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+	budget := Budget{ID: 12, Owner: Owner{UserID: &userID}, PeriodKind: PeriodWeekly, PeriodStart: start, PeriodEnd: end}
+	repo := &fakeRepository{byID: budget, detailedSnapshot: DetailedReportSnapshot{Budget: budget, UncategorizedAmount: "0"}}
+	report, err := NewService(repo).DetailedReport(context.Background(), 12)
+	if err != nil || report.Budget.ID != 12 || report.Budget.PeriodKind != PeriodWeekly {
+		t.Fatalf("report=%+v error=%v", report, err)
+	}
+	if repo.detailedPeriod != (Period{Kind: PeriodWeekly, Start: start, End: end}) || repo.detailedOwner.UserID != &userID {
+		t.Fatalf("snapshot owner=%+v period=%+v", repo.detailedOwner, repo.detailedPeriod)
+	}
+	if _, err := NewService(repo).DetailedReport(context.Background(), 0); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("missing id error=%v", err)
+	}
+	repo.findErr = apperrors.NotFound(apperrors.CodeBudgetNotFound, "budget not found", nil)
+	if _, err := NewService(repo).DetailedReport(context.Background(), 12); !apperrors.IsKind(err, apperrors.KindNotFound) {
+		t.Fatalf("not-found error=%v", err)
+	}
+}
+
+func TestDetailedMonthlyReportForecastsMonthEndSpending(t *testing.T) {
+	userID := int64(8)
+	rent, streaming, groceries := "Rent", "Netflix", "Groceries"
+	history := func(month time.Month, description *string, amount string) HistoryTransaction {
+		return HistoryTransaction{LineID: 1, TransactionDate: time.Date(2026, month, 3, 0, 0, 0, 0, time.UTC), Description: description, Amount: amount}
+	}
+	repo := &fakeRepository{
+		detailedSnapshot: DetailedReportSnapshot{
+			Budget: Budget{ID: 12, Owner: Owner{UserID: &userID}},
+			Lines: []DetailedReportLineData{
+				{ReportLineData: ReportLineData{Line: Line{ID: 1, BudgetID: 12, AllocationAmount: "1000"}, ActualAmount: "610"}, Transactions: []DetailedTransaction{{ID: 31, Amount: "500", Description: &rent}, {ID: 32, Amount: "110", Description: &groceries}}},
+				{ReportLineData: ReportLineData{Line: Line{ID: 2, BudgetID: 12, AllocationAmount: "100"}, ActualAmount: "80"}, Transactions: []DetailedTransaction{{ID: 33, Amount: "80"}}},
+			},
+			UncategorizedAmount: "0",
+		},
+		history: []HistoryTransaction{
+			history(time.April, &rent, "500"), history(time.April, &groceries, "240"),
+			history(time.May, &rent, "500"), history(time.May, &streaming, "15.49"), history(time.May, &groceries, "300"),
+			history(time.June, &rent, "500"), history(time.June, &streaming, " 15.99"), history(time.June, &groceries, "360"),
+		},
+	}
+	service := NewService(repo)
+	service.now = func() time.Time { return time.Date(2026, 7, 11, 18, 30, 0, 0, time.UTC) }
+
+	report, err := service.DetailedMonthlyReport(context.Background(), MonthlyInput{Owner: Owner{UserID: &userID}, Year: 2026, Month: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repo.historyFrom.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("history from=%s", repo.historyFrom)
+	}
+	asOf := time.Date(2026, 7, 11, 0, 0, 0, 0, time.UTC)
+	// Rent has posted; Netflix is still expected at its latest amount; the
+	// remaining 20 days of groceries blend this month's pace with history.
+	if got, want := report.Lines[0].Forecast, (Forecast{AsOf: asOf, ProjectedAmount: "824.57", ProjectedRemainingAmount: "175.43", RecurringPendingAmount: "15.99", Risk: ForecastWatch}); got != want {
+		t.Fatalf("line forecast=%+v want %+v", got, want)
+	}
+	if got := report.Lines[1].Forecast; got.ProjectedAmount != "131.61" || got.ProjectedRemainingAmount != "-31.61" || got.Risk != ForecastAtRisk {
+		t.Fatalf("line without history forecast=%+v", got)
+	}
+	if got := report.Forecast; got.ProjectedAmount != "956.18" || got.ProjectedRemainingAmount != "143.82" || got.RecurringPendingAmount != "15.99" || got.Risk != ForecastWatch {
+		t.Fatalf("report forecast=%+v", got)
+	}
+}
+
+func TestDetailedReportForecastScalesToThePeriodLength(t *testing.T) {
+	userID := int64(8)
+	rent, groceries := "Rent", "Groceries"
+	date := func(month time.Month, day int) time.Time { return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC) }
+	history := func(when time.Time, description *string, amount string) HistoryTransaction {
+		return HistoryTransaction{LineID: 1, TransactionDate: when, Description: description, Amount: amount}
+	}
+	for _, test := range []struct {
+		name         string
+		kind         PeriodKind
+		start, end   time.Time
+		asOf         time.Time
+		history      []HistoryTransaction
+		actual       string
+		transactions []DetailedTransaction
+		want         Forecast
+	}{
+		{
+			// Rent falls due on the 1st, outside the week, and groceries run
+			// at their daily rate over May and June for the 4 days left.
+			name: "weekly", kind: PeriodWeekly, start: date(time.July, 6), end: date(time.July, 12), asOf: date(time.July, 8),
+			history: []HistoryTransaction{
+				history(date(time.May, 1), &rent, "500"), history(date(time.May, 10), &groceries, "240"),
+				history(date(time.June, 1), &rent, "500"), history(date(time.June, 10), &groceries, "360"),
+			},
+			actual: "40", transactions: []DetailedTransaction{{ID: 31, Amount: "40", Description: &groceries}},
+			want: Forecast{ProjectedAmount: "83.64", ProjectedRemainingAmount: "116.36", RecurringPendingAmount: "0.00", Risk: ForecastOnTrack},
+		},
+		{
+			// July's rent has posted and August's and September's are still
+			// due; groceries project about three months of spending.
+			name: "quarterly", kind: PeriodQuarterly, start: date(time.July, 1), end: date(time.September, 30), asOf: date(time.July, 11),
+			history: []HistoryTransaction{
+				history(date(time.April, 1), &rent, "500"), history(date(time.April, 10), &groceries, "240"),
+				history(date(time.May, 1), &rent, "500"), history(date(time.May, 10), &groceries, "300"),
+				history(date(time.June, 1), &rent, "500"), history(date(time.June, 10), &groceries, "360"),
+			},
+			actual: "600", transactions: []DetailedTransaction{{ID: 31, Amount: "500", Description: &rent}, {ID: 32, Amount: "100", Description: &groceries}},
+			want: Forecast{ProjectedAmount: "2393.36", ProjectedRemainingAmount: "-193.36", RecurringPendingAmount: "1000.00", Risk: ForecastAtRisk},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			allocation := map[PeriodKind]string{PeriodWeekly: "200", PeriodQuarterly: "2200"}[test.kind]
+			repo := &fakeRepository{
+				byID: Budget{ID: 12, Owner: Owner{UserID: &userID}, PeriodKind: test.kind, PeriodStart: test.start, PeriodEnd: test.end},
+				detailedSnapshot: DetailedReportSnapshot{
+					Budget:              Budget{ID: 12, Owner: Owner{UserID: &userID}},
+					Lines:               []DetailedReportLineData{{ReportLineData: ReportLineData{Line: Line{ID: 1, BudgetID: 12, AllocationAmount: allocation}, ActualAmount: test.actual}, Transactions: test.transactions}},
+					UncategorizedAmount: "0",
+				},
+				history: test.history,
+			}
+			service := NewService(repo)
+			service.now = func() time.Time { return test.asOf }
+			report, err := service.DetailedReport(context.Background(), 12)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := report.Lines[0].Forecast
+			got.AsOf = time.Time{}
+			if got != test.want {
+				t.Fatalf("forecast=%+v want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDetailedMonthlyReportForecastUsesActualsOnceThePeriodEnds(t *testing.T) {
+	userID := int64(8)
+	repo := &fakeRepository{detailedSnapshot: DetailedReportSnapshot{
+		Budget:              Budget{ID: 12, Owner: Owner{UserID: &userID}},
+		Lines:               []DetailedReportLineData{{ReportLineData: ReportLineData{Line: Line{ID: 1, BudgetID: 12, AllocationAmount: "50"}, ActualAmount: "75"}}},
+		UncategorizedAmount: "0",
+	}}
+	service := NewService(repo)
+	service.now = func() time.Time { return time.Date(2026, 8, 2, 0, 0, 0, 0, time.UTC) }
+	report, err := service.DetailedMonthlyReport(context.Background(), MonthlyInput{Owner: Owner{UserID: &userID}, Year: 2026, Month: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := report.Lines[0].Forecast; got.ProjectedAmount != "75.00" || got.ProjectedRemainingAmount != "-25.00" || got.RecurringPendingAmount != "0.00" || got.Risk != ForecastOverBudget {
+		t.Fatalf("closed period forecast=%+v", got)
+	}
+
+	repo.historyErr = errors.New("history unavailable")
+	if _, err := service.DetailedMonthlyReport(context.Background(), MonthlyInput{Owner: Owner{UserID: &userID}, Year: 2026, Month: 7}); !apperrors.IsKind(err, apperrors.KindInternal) {
+		t.Fatalf("history error=%v", err)
+	}
+}
+
+func TestTrendsMatchLinesByNameAndTotalYearToDate(t *testing.T) {
+	householdID := int64(2)
+	month := func(m time.Month) time.Time { return time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC) }
+	groceries := &Category{ID: 3, Code: "groceries", Name: "Groceries"}
+	dining, rent := Category{ID: 4, Code: "dining", Name: "Dining"}, Category{ID: 5, Code: "rent", Name: "Rent"}
+	repo := &fakeRepository{trendSnapshot: TrendSnapshot{
+		Lines: []TrendLineData{
+			{BudgetID: 10, PeriodStart: month(time.January), LineID: 1, Name: "Food", AllocationAmount: "100", ActualAmount: "90", SortOrder: 1, Categories: []Category{*groceries}},
+			{BudgetID: 11, PeriodStart: month(time.February), LineID: 4, Name: "Food", AllocationAmount: "100", ActualAmount: "120", SortOrder: 1, Categories: []Category{*groceries}},
+			{BudgetID: 11, PeriodStart: month(time.February), LineID: 5, Name: "Rent", AllocationAmount: "800", ActualAmount: "800", SortOrder: 0, Categories: []Category{rent}},
+			{BudgetID: 13, PeriodStart: month(time.April), LineID: 7, Name: " food ", AllocationAmount: "150", ActualAmount: "30", SortOrder: 2, Categories: []Category{*groceries, dining}},
+			{BudgetID: 13, PeriodStart: month(time.April), LineID: 8, Name: "Rent", AllocationAmount: "800", ActualAmount: "800", SortOrder: 0},
+		},
+		Categories: []TrendCategoryData{
+			{Month: month(time.January), Category: groceries, ActualAmount: "90"},
+			{Month: month(time.February), Category: groceries, ActualAmount: "120"},
+			{Month: month(time.March), ActualAmount: "15.50"},
+			{Month: month(time.April), Category: groceries, ActualAmount: "30"},
+		},
+	}}
+
+	report, err := NewService(repo).Trends(context.Background(), TrendInput{Owner: Owner{HouseholdID: &householdID}, From: time.Date(2026, 2, 14, 0, 0, 0, 0, time.UTC), To: month(time.April)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.trendPeriod.Start != month(time.January) || repo.trendPeriod.End != time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("trend snapshot period=%+v", repo.trendPeriod)
+	}
+	if len(report.Months) != 3 || report.Months[0].BudgetID == nil || *report.Months[0].BudgetID != 11 || report.Months[1].BudgetID != nil || report.Months[1].AllocationAmount != "0.00" {
+		t.Fatalf("months=%+v", report.Months)
+	}
+	if len(report.Lines) != 2 || report.Lines[0].Name != "Rent" || report.Lines[1].Name != "food" {
+		t.Fatalf("lines=%+v", report.Lines)
+	}
+	food := report.Lines[1]
+	if food.Months[1].Budgeted || !food.Months[2].Budgeted || food.Months[2].RemainingAmount != "120.00" {
+		t.Fatalf("food months=%+v", food.Months)
+	}
+	if want := (TrendTotals{Months: 3, AllocationAmount: "250.00", ActualAmount: "150.00", RemainingAmount: "100.00", AverageAllocationAmount: "83.33", AverageActualAmount: "50.00"}); food.Totals != want {
+		t.Fatalf("food totals=%+v", food.Totals)
+	}
+	if food.YearToDate.Months != 4 || food.YearToDate.ActualAmount != "240.00" || food.YearToDate.AverageActualAmount != "60.00" {
+		t.Fatalf("food year to date=%+v", food.YearToDate)
+	}
+	if report.Totals.ActualAmount != "1750.00" || report.YearToDate.AllocationAmount != "1950.00" || report.YearToDate.ActualAmount != "1840.00" {
+		t.Fatalf("totals=%+v year to date=%+v", report.Totals, report.YearToDate)
+	}
+	if len(report.Categories) != 4 || *report.Categories[1].Category != *groceries || report.Categories[3].Category != nil || report.Categories[3].Name != "Uncategorized" || report.Categories[3].Months[1].ActualAmount != "15.50" {
+		t.Fatalf("categories=%+v", report.Categories)
+	}
+	// Groceries share April's food allocation with dining, which spent nothing.
+	grocery := report.Categories[1]
+	if !grocery.Months[0].Budgeted || grocery.Months[1].Budgeted || grocery.Months[2].AllocationAmount != "75.00" || grocery.Months[2].RemainingAmount != "45.00" {
+		t.Fatalf("groceries months=%+v", grocery.Months)
+	}
+	if grocery.Totals.AllocationAmount != "175.00" || grocery.YearToDate.AllocationAmount != "275.00" || report.Categories[0].Name != "Dining" || report.Categories[0].Totals.AllocationAmount != "75.00" {
+		t.Fatalf("category allocations=%+v", report.Categories)
+	}
+	if uncategorized := report.Categories[3]; uncategorized.Totals.AllocationAmount != "0.00" || uncategorized.Months[1].Budgeted {
+		t.Fatalf("uncategorized=%+v", uncategorized)
+	}
+	if got := []any{splitAllocation(1001, 3), splitAllocation(-1001, 3)}; !reflect.DeepEqual(got, []any{[]int64{334, 334, 333}, []int64{-334, -334, -333}}) {
+		t.Fatalf("split allocations=%v", got)
+	}
+}
+
+func TestTrendsValidateRange(t *testing.T) {
+	userID := int64(8)
+	owner := Owner{UserID: &userID}
+	january := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for name, input := range map[string]TrendInput{
+		"missing owner": {From: january, To: january},
+		"missing month": {Owner: owner, From: january},
+		"reversed":      {Owner: owner, From: january, To: january.AddDate(0, -1, 0)},
+		"too long":      {Owner: owner, From: january, To: january.AddDate(0, MaxTrendMonths, 0)},
+	} {
+		if _, err := NewService(&fakeRepository{}).Trends(context.Background(), input); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Errorf("%s: error=%v", name, err)
+		}
+	}
+	if _, err := NewService(&fakeRepository{}).Trends(context.Background(), TrendInput{Owner: owner, From: january, To: january.AddDate(0, MaxTrendMonths-1, 0)}); err != nil {
+		t.Fatalf("longest range error=%v", err)
+	}
+}
+
+func int64Pointer(value int64) *int64    { return &value }
+func int32Pointer(value int32) *int32    { return &value }
+func stringPointer(value string) *string { return &value }
+
+type fakeGoalReader struct {
+	owner  Owner
+	period Period
+	items  []GoalProgress
+	err    error
+}
+
+func (f *fakeGoalReader) GoalProgress(_ context.Context, owner Owner, period Period) ([]GoalProgress, error) {
+	f.owner, f.period = owner, period
+	return f.items, f.err
+}
+
+func TestReportsIncludeGoalProgressForBudgetPeriod(t *testing.T) {
+	householdID := int64(1)
+	start, end := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC)
+	budget := Budget{ID: 12, Owner: Owner{HouseholdID: &householdID}, PeriodKind: PeriodMonthly, PeriodStart: start, PeriodEnd: end}
+	repo := &fakeRepository{snapshot: ReportSnapshot{Budget: budget, UncategorizedAmount: "0"}, detailedSnapshot: DetailedReportSnapshot{Budget: budget, UncategorizedAmount: "0"}}
+	reader := &fakeGoalReader{items: []GoalProgress{{ID: 4, Name: "Car insurance", RequiredMonthlyAmount: "100.00", State: "on_track"}}}
+
+	report, err := NewService(repo, reader).Report(context.Background(), 12)
+	if err != nil || len(report.Goals) != 1 || report.Goals[0].Name != "Car insurance" {
+		t.Fatalf("report=%+v error=%v", report, err)
+	}
+	if *reader.owner.HouseholdID != householdID || reader.period != (Period{Kind: PeriodMonthly, Start: start, End: end}) {
+		t.Fatalf("goal reader called with owner=%+v period=%+v", reader.owner, reader.period)
+	}
+	detailed, err := NewService(repo, reader).DetailedMonthlyReport(context.Background(), MonthlyInput{Owner: Owner{HouseholdID: &householdID}, Year: 2026, Month: 7})
+	if err != nil || len(detailed.Goals) != 1 {
+		t.Fatalf("detailed=%+v error=%v", detailed, err)
+	}
+
+	withoutGoals, err := NewService(repo).Report(context.Background(), 12)
+	if err != nil || withoutGoals.Goals == nil || len(withoutGoals.Goals) != 0 {
+		t.Fatalf("report without goal reader=%+v error=%v", withoutGoals, err)
+	}
+	reader.items, reader.err = nil, apperrors.Validation("boom")
+	if _, err := NewService(repo, reader).Report(context.Background(), 12); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("goal reader error=%v", err)
+	}
+}
+
+func TestEnsureMonthlyResolvesNamedTemplateBeforeCreating(t *testing.T) {
+	householdID := int64(7)
+	owner := Owner{HouseholdID: &householdID}
+	repo := &fakeRepository{monthlyMisses: 1, created: Budget{ID: 21, Owner: owner}, template: Template{ID: 4, Owner: owner, Name: "Standard"}}
+	result, err := NewService(repo).EnsureMonthly(context.Background(), MonthlyInput{Owner: owner, Year: 2026, Month: 7, Template: " Standard "})
+	if err != nil || !result.Created || repo.templateName != "Standard" || repo.createInput.TemplateID == nil || *repo.createInput.TemplateID != 4 {
+		t.Fatalf("EnsureMonthly=%+v create=%+v name=%q error=%v", result, repo.createInput, repo.templateName, err)
+	}
+
+	missing := &fakeRepository{monthlyMisses: 1, templateErr: apperrors.NotFound(apperrors.CodeBudgetTemplateNotFound, "budget template not found", nil)}
+	if _, err := NewService(missing).EnsureMonthly(context.Background(), MonthlyInput{Owner: owner, Year: 2026, Month: 7, Template: "Missing"}); apperrors.CodeOf(err) != apperrors.CodeBudgetTemplateNotFound || missing.createInput.Period.Start != (time.Time{}) {
+		t.Fatalf("missing template error=%v create=%+v", err, missing.createInput)
+	}
+}
+
+func TestTemplateLinesValidateNamesAndAssignSortOrders(t *testing.T) {
+	lines, err := templateLines([]TemplateLineInput{{Name: " Rent ", AllocationAmount: "1200", SortOrder: int32Pointer(5)}, {Name: "Food", AllocationAmount: "400.5"}})
+	if err != nil || lines[0].Name != "Rent" || lines[0].AllocationAmount != "1200.00" || *lines[1].SortOrder != 6 || lines[1].AllocationAmount != "400.50" {
+		t.Fatalf("lines=%+v error=%v", lines, err)
+	}
+	cases := map[string][]TemplateLineInput{
+		"duplicate name":       {{Name: "Food", AllocationAmount: "1"}, {Name: " food ", AllocationAmount: "1"}},
+		"duplicate sort order": {{Name: "Food", AllocationAmount: "1", SortOrder: int32Pointer(2)}, {Name: "Rent", AllocationAmount: "1", SortOrder: int32Pointer(2)}},
+		"negative amount":      {{Name: "Food", AllocationAmount: "-1"}},
+	}
+	for name, input := range cases {
+		if _, err := templateLines(input); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("%s error=%v", name, err)
+		}
+	}
+}
+
+func TestSaveTemplateRejectsDuplicateLineNames(t *testing.T) {
+	repo := &fakeRepository{byID: Budget{ID: 3, Lines: []Line{{ID: 1, Name: "Food"}, {ID: 2, Name: "FOOD"}}}}
+	if _, err := NewService(repo).SaveTemplate(context.Background(), SaveTemplateInput{BudgetID: 3, Name: "Standard"}); !apperrors.IsKind(err, apperrors.KindValidation) || repo.saveTemplate.BudgetID != 0 {
+		t.Fatalf("SaveTemplate error=%v input=%+v", err, repo.saveTemplate)
+	}
+	repo.byID.Lines[1].Name = "Rent"
+	if _, err := NewService(repo).SaveTemplate(context.Background(), SaveTemplateInput{BudgetID: 3, Name: " Standard "}); err != nil || repo.saveTemplate.Name != "Standard" {
+		t.Fatalf("SaveTemplate error=%v input=%+v", err, repo.saveTemplate)
+	}
+}
+
+func TestApplyTemplatePlansMergeAndReplace(t *testing.T) {
+	householdID := int64(7)
+	owner := Owner{HouseholdID: &householdID}
+	food, dining, restaurants, gifts := Category{ID: 1, Code: "food"}, Category{ID: 2, Code: "dining"}, Category{ID: 3, Code: "restaurants"}, Category{ID: 4, Code: "gifts"}
+	budget := Budget{ID: 9, Owner: owner, Lines: []Line{
+		{ID: 11, BudgetID: 9, Name: "Groceries", AllocationAmount: "800.00", SortOrder: 1, Categories: []Category{food}},
+		{ID: 12, BudgetID: 9, Name: "Dining", AllocationAmount: "100.00", SortOrder: 2, Categories: []Category{dining, restaurants}},
+		{ID: 13, BudgetID: 9, Name: "Misc", AllocationAmount: "50.00", SortOrder: 3, Categories: []Category{gifts}},
+	}}
+	template := Template{ID: 4, Owner: owner, Name: "Standard", Lines: []TemplateLine{
+		{Name: "groceries", AllocationAmount: "900.00", SortOrder: 1, Categories: []Category{food}},
+		{Name: "Dining", AllocationAmount: "100.00", SortOrder: 2, Categories: []Category{dining}},
+		{Name: "Treats", AllocationAmount: "30.00", SortOrder: 3, Categories: []Category{restaurants, gifts}},
+	}}
+	summarize := func(changes []LineChange) []string {
+		result := make([]string, 0, len(changes))
+		for _, change := range changes {
+			switch change.Action {
+			case LineAdded:
+				result = append(result, fmt.Sprintf("add %s %s %v order=%d", change.After.Name, change.After.AllocationAmount, categoryIDs(change.After.Categories), change.After.SortOrder))
+			case LineUpdated:
+				result = append(result, fmt.Sprintf("update %s %s %v", change.After.Name, change.After.AllocationAmount, categoryIDs(change.After.Categories)))
+			case LineRemoved:
+				result = append(result, fmt.Sprintf("remove %s", change.Before.Name))
+			}
+		}
+		return result
+	}
+
+	repo := &fakeRepository{byID: budget, template: template}
+	result, err := NewService(repo).ApplyTemplate(context.Background(), ApplyTemplateInput{BudgetID: 9, TemplateID: int64Pointer(4), DryRun: true})
+	want := []string{"update Groceries 900.00 [1]", "update Dining 100.00 [2]", "add Treats 30.00 [3 4] order=4", "update Misc 50.00 []"}
+	if err != nil || result.Applied || result.Mode != ApplyMerge || !reflect.DeepEqual(summarize(result.Changes), want) || repo.appliedChanges != nil {
+		t.Fatalf("merge dry run=%v applied=%v error=%v", summarize(result.Changes), result.Applied, err)
+	}
+
+	repo.applied = Budget{ID: 9, Owner: owner, Lines: []Line{{ID: 14, Name: "Treats", SortOrder: 4}}}
+	result, err = NewService(repo).ApplyTemplate(context.Background(), ApplyTemplateInput{BudgetID: 9, TemplateName: stringPointer("Standard"), Mode: ApplyReplace})
+	want = []string{"update Groceries 900.00 [1]", "update Dining 100.00 [2]", "add Treats 30.00 [3 4] order=4", "remove Misc"}
+	if err != nil || !result.Applied || !reflect.DeepEqual(summarize(repo.appliedChanges), want) || result.Changes[2].After.ID != 14 || repo.templateName != "Standard" {
+		t.Fatalf("replace=%v applied=%v error=%v", summarize(repo.appliedChanges), result.Applied, err)
+	}
+
+	otherID := int64(8)
+	repo.template.Owner = Owner{HouseholdID: &otherID}
+	invalid := []ApplyTemplateInput{
+		{BudgetID: 9, TemplateID: int64Pointer(4)},
+		{BudgetID: 9, TemplateID: int64Pointer(4), TemplateName: stringPointer("Standard")},
+		{BudgetID: 9, TemplateID: int64Pointer(4), Mode: "overwrite"},
+	}
+	for _, input := range invalid {
+		if _, err := NewService(repo).ApplyTemplate(context.Background(), input); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("ApplyTemplate(%+v) error=%v", input, err)
+		}
+	}
+}
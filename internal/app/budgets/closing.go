@@ -0,0 +1,164 @@
+package budgets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+// Close snapshots a budget's report and locks its lines and the transactions
+// dated in its period. The repository takes the snapshot after locking the
+// budget, so transactions written through the service are either in it or
+// rejected as closed. Transactions that change afterwards, for example
+// through direct database edits, show up as drift in later reports.
+func (s *Service) Close(ctx context.Context, input CloseInput) (Closing, error) {
+	if input.BudgetID == 0 {
+		return Closing{}, apperrors.Validation("budget id is required")
+	}
+	if input.UserID == 0 {
+		return Closing{}, apperrors.Validation("user id is required")
+	}
+	closing, err := s.repo.Close(ctx, input, func(snapshot ReportSnapshot) (CloseInput, error) {
+		if snapshot.Closing != nil {
+			return CloseInput{}, apperrors.Conflict(apperrors.CodeBudgetClosed, fmt.Sprintf("budget %d is already closed", input.BudgetID), nil)
+		}
+		report, err := liveReport(snapshot)
+		if err != nil {
+			return CloseInput{}, err
+		}
+		input.Lines = make([]ClosingLine, 0, len(report.Lines))
+		for _, line := range report.Lines {
+			input.Lines = append(input.Lines, ClosingLine{
+				LineID: &line.ID, Name: line.Name, AllocationAmount: line.AllocationAmount, ActualAmount: line.ActualAmount, SortOrder: line.SortOrder,
+			})
+		}
+		input.Totals = report.Totals
+		return input, nil
+	})
+	if err != nil {
+		return Closing{}, apperrors.WrapInternal("close budget", err)
+	}
+	return normalizeClosing(closing), nil
+}
+
+// Reopen unlocks a closed budget. The closing record is kept with the reason.
+func (s *Service) Reopen(ctx context.Context, input ReopenInput) (Closing, error) {
+	if input.BudgetID == 0 {
+		return Closing{}, apperrors.Validation("budget id is required")
+	}
+	if input.UserID == 0 {
+		return Closing{}, apperrors.Validation("user id is required")
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if input.Reason == "" {
+		return Closing{}, apperrors.Validation("reopen reason is required")
+	}
+	closing, err := s.repo.Reopen(ctx, input)
+	if err != nil {
+		return Closing{}, apperrors.WrapInternal("reopen budget", err)
+	}
+	return normalizeClosing(closing), nil
+}
+
+// applyClosing serves a closed budget's report from its closing snapshot and
+// records where the live figures have drifted from it.
+func applyClosing(report *Report, closing Closing) error {
+	lines := make([]*ReportLine, 0, len(report.Lines))
+	for i := range report.Lines {
+		lines = append(lines, &report.Lines[i])
+	}
+	totals, result, err := closeLines(lines, report.Totals, closing)
+	if err != nil {
+		return err
+	}
+	report.Totals, report.Closing = totals, result
+	return nil
+}
+
+// applyDetailedClosing does what applyClosing does for a detailed report.
+// Transactions and member spending stay live.
+func applyDetailedClosing(report *DetailedReport, closing Closing) error {
+	lines := make([]*ReportLine, 0, len(report.Lines))
+	for i := range report.Lines {
+		lines = append(lines, &report.Lines[i].ReportLine)
+	}
+	totals, result, err := closeLines(lines, report.Totals, closing)
+	if err != nil {
+		return err
+	}
+	report.Totals, report.Closing = totals, result
+	return nil
+}
+
+// closeLines replaces the live line actuals with the closing snapshot's and
+// returns the snapshot totals with the drift from the live totals. Lines
+// cannot change while the budget is closed, so live lines are matched to the
+// snapshot by ID; a live line missing from the snapshot counts as closed at
+// zero.
+func closeLines(lines []*ReportLine, current ReportTotals, closing Closing) (ReportTotals, *ReportClosing, error) {
+	closed := make(map[int64]string, len(closing.Lines))
+	for _, line := range closing.Lines {
+		if line.LineID != nil {
+			closed[*line.LineID] = line.ActualAmount
+		}
+	}
+	result := ReportClosing{ID: closing.ID, ClosedAt: closing.ClosedAt, ClosedBy: closing.ClosedBy, CurrentTotals: current, Drift: []LineDrift{}}
+	for _, line := range lines {
+		closedActual, err := cents(zeroIfBlank(closed[line.ID]))
+		if err != nil {
+			return ReportTotals{}, nil, fmt.Errorf("invalid closed actual amount for line %d: %w", line.ID, err)
+		}
+		currentActual, err := cents(line.ActualAmount)
+		if err != nil {
+			return ReportTotals{}, nil, fmt.Errorf("invalid actual amount for line %d: %w", line.ID, err)
+		}
+		allocation, err := cents(line.AllocationAmount)
+		if err != nil {
+			return ReportTotals{}, nil, fmt.Errorf("invalid allocation amount for line %d: %w", line.ID, err)
+		}
+		if closedActual != currentActual {
+			result.Drift = append(result.Drift, LineDrift{LineID: line.ID, Name: line.Name, ClosedActualAmount: formatCents(closedActual), CurrentActualAmount: formatCents(currentActual)})
+		}
+		line.ActualAmount, line.RemainingAmount = formatCents(closedActual), formatCents(allocation-closedActual)
+	}
+	totals, err := normalizeTotals(closing.Totals)
+	if err != nil {
+		return ReportTotals{}, nil, err
+	}
+	result.Drifted = len(result.Drift) > 0 || totals.ActualAmount != current.ActualAmount ||
+		totals.UnmappedActualAmount != current.UnmappedActualAmount || totals.UncategorizedActualAmount != current.UncategorizedActualAmount
+	return totals, &result, nil
+}
+
+func normalizeTotals(totals ReportTotals) (ReportTotals, error) {
+	values := []*string{&totals.AllocationAmount, &totals.ActualAmount, &totals.UnmappedActualAmount, &totals.UncategorizedActualAmount}
+	amounts := make([]int64, len(values))
+	for i, value := range values {
+		amount, err := cents(zeroIfBlank(*value))
+		if err != nil {
+			return ReportTotals{}, fmt.Errorf("invalid closing total: %w", err)
+		}
+		amounts[i], *value = amount, formatCents(amount)
+	}
+	totals.RemainingAmount = formatCents(amounts[0] - amounts[1])
+	return totals, nil
+}
+
+func normalizeClosing(closing Closing) Closing {
+	if closing.Lines == nil {
+		closing.Lines = []ClosingLine{}
+	}
+	if totals, err := normalizeTotals(closing.Totals); err == nil {
+		closing.Totals = totals
+	}
+	return closing
+}
+
+func zeroIfBlank(value string) string {
+	if value == "" {
+		return "0"
+	}
+	return value
+}
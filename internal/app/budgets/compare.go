@@ -0,0 +1,181 @@
+package budgets
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+// Compare aligns two budgets' report lines by name. When one budget descends
+// from the other, lines left unmatched are then paired by identical category
+// mappings, so a line renamed since the copy still lines up.
+func (s *Service) Compare(ctx context.Context, input ComparisonInput) (Comparison, error) {
+	if input.A == 0 || input.B == 0 {
+		return Comparison{}, apperrors.Validation("budget ids a and b are required")
+	}
+	if input.A == input.B {
+		return Comparison{}, apperrors.Validation("budgets a and b must differ")
+	}
+	a, err := s.Report(ctx, input.A)
+	if err != nil {
+		return Comparison{}, err
+	}
+	b, err := s.Report(ctx, input.B)
+	if err != nil {
+		return Comparison{}, err
+	}
+	lineage, err := s.lineage(ctx, input.A, input.B)
+	if err != nil {
+		return Comparison{}, err
+	}
+	lines, err := compareLines(a.Lines, b.Lines, lineage != nil)
+	if err != nil {
+		return Comparison{}, apperrors.WrapInternal("calculate budget comparison", err)
+	}
+	totals := ComparisonTotals{A: a.Totals, B: b.Totals}
+	if totals.AllocationDelta, err = delta(a.Totals.AllocationAmount, b.Totals.AllocationAmount); err != nil {
+		return Comparison{}, apperrors.WrapInternal("calculate budget comparison", err)
+	}
+	if totals.ActualDelta, err = delta(a.Totals.ActualAmount, b.Totals.ActualAmount); err != nil {
+		return Comparison{}, apperrors.WrapInternal("calculate budget comparison", err)
+	}
+	return Comparison{A: a.Budget, B: b.Budget, Lineage: lineage, Lines: lines, Totals: totals}, nil
+}
+
+// lineage reports which of two budgets was copied from the other, if either.
+func (s *Service) lineage(ctx context.Context, a, b int64) (*Lineage, error) {
+	for _, pair := range [][2]int64{{a, b}, {b, a}} {
+		ancestors, err := s.repo.ListLineage(ctx, pair[1])
+		if err != nil {
+			return nil, apperrors.WrapInternal("load budget lineage", err)
+		}
+		if index := slices.Index(ancestors, pair[0]); index >= 0 {
+			return &Lineage{AncestorID: pair[0], DescendantID: pair[1], Generations: index + 1}, nil
+		}
+	}
+	return nil, nil
+}
+
+// compareLines lists B's lines in order, matched or added, followed by A's
+// unmatched lines as removed.
+func compareLines(a, b []ReportLine, related bool) ([]LineComparison, error) {
+	byName := make(map[string]int, len(a))
+	for i, line := range a {
+		if _, ok := byName[trendLineKey(line.Name)]; !ok {
+			byName[trendLineKey(line.Name)] = i
+		}
+	}
+	pairs := make([]int, len(b))
+	matchedBy := make([]LineMatch, len(b))
+	paired := make([]bool, len(a))
+	for j, line := range b {
+		pairs[j] = -1
+		if i, ok := byName[trendLineKey(line.Name)]; ok && !paired[i] {
+			pairs[j], matchedBy[j], paired[i] = i, LineMatchName, true
+		}
+	}
+	if related {
+		for j, line := range b {
+			if pairs[j] >= 0 || len(line.Categories) == 0 {
+				continue
+			}
+			for i := range a {
+				if !paired[i] && slices.Equal(categoryIDs(a[i].Categories), categoryIDs(line.Categories)) {
+					pairs[j], matchedBy[j], paired[i] = i, LineMatchCategories, true
+					break
+				}
+			}
+		}
+	}
+
+	result := make([]LineComparison, 0, len(a)+len(b))
+	for j := range b {
+		var before *ReportLine
+		if pairs[j] >= 0 {
+			before = &a[pairs[j]]
+		}
+		item, err := compareLine(before, &b[j])
+		if err != nil {
+			return nil, err
+		}
+		item.MatchedBy = matchedBy[j]
+		result = append(result, item)
+	}
+	for i := range a {
+		if paired[i] {
+			continue
+		}
+		item, err := compareLine(&a[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func compareLine(a, b *ReportLine) (LineComparison, error) {
+	result := LineComparison{Status: LineMatched, A: comparedLine(a), B: comparedLine(b), AddedCategories: []Category{}, RemovedCategories: []Category{}}
+	switch {
+	case a == nil:
+		result.Status = LineInBOnly
+	case b == nil:
+		result.Status = LineInAOnly
+	default:
+		result.AddedCategories = missingCategories(b.Categories, a.Categories)
+		result.RemovedCategories = missingCategories(a.Categories, b.Categories)
+	}
+	beforeAllocation, beforeActual := lineAmounts(a)
+	afterAllocation, afterActual := lineAmounts(b)
+	var err error
+	if result.AllocationDelta, err = delta(beforeAllocation, afterAllocation); err != nil {
+		return LineComparison{}, fmt.Errorf("invalid allocation amount: %w", err)
+	}
+	if result.ActualDelta, err = delta(beforeActual, afterActual); err != nil {
+		return LineComparison{}, fmt.Errorf("invalid actual amount: %w", err)
+	}
+	return result, nil
+}
+
+func comparedLine(line *ReportLine) *ComparedLine {
+	if line == nil {
+		return nil
+	}
+	categories := line.Categories
+	if categories == nil {
+		categories = []Category{}
+	}
+	return &ComparedLine{ID: line.ID, Name: line.Name, AllocationAmount: line.AllocationAmount, ActualAmount: line.ActualAmount, Categories: categories}
+}
+
+func lineAmounts(line *ReportLine) (allocation, actual string) {
+	if line == nil {
+		return "0", "0"
+	}
+	return line.AllocationAmount, line.ActualAmount
+}
+
+// missingCategories lists the categories in items that others lacks.
+func missingCategories(items, others []Category) []Category {
+	result := []Category{}
+	for _, item := range items {
+		if !slices.ContainsFunc(others, func(other Category) bool { return other.ID == item.ID }) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func delta(a, b string) (string, error) {
+	before, err := cents(a)
+	if err != nil {
+		return "", err
+	}
+	after, err := cents(b)
+	if err != nil {
+		return "", err
+	}
+	return formatCents(after - before), nil
+}
@@ -0,0 +1,225 @@
+package budgets
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// forecastHistoryMonths is how many whole months before a period feed the
+// historical average and recurring transaction detection.
+const forecastHistoryMonths = 3
+
+// forecastWatchPercent matches the dashboard's warning threshold so a line is
+// flagged before it is projected to overspend.
+const forecastWatchPercent = 80
+
+// forecastRecurringTolerancePercent is how far a repeated transaction's amount
+// may drift from its latest occurrence and still count as recurring.
+const forecastRecurringTolerancePercent = 10
+
+// forecaster projects period-end spending for the lines of one budget. Spending
+// is split into recurring items, which are expected once a month on the day of
+// the month they last posted, at their most recent amount, and variable
+// spending, whose remaining days are projected at a blend of this period's
+// daily pace and the historical daily average. The pace gains weight as the
+// period progresses. Both scale with the period, so weekly and quarterly
+// budgets project a week's or a quarter's spending.
+type forecaster struct {
+	asOf        time.Time
+	start, end  time.Time
+	totalDays   int64
+	elapsedDays int64
+	historyDays int64
+	history     map[int64][]HistoryTransaction
+}
+
+func newForecaster(period Period, asOf time.Time, history []HistoryTransaction) forecaster {
+	f := forecaster{asOf: asOf, start: day(period.Start), end: day(period.End), totalDays: daysBetween(period.Start, period.End) + 1, history: make(map[int64][]HistoryTransaction)}
+	f.elapsedDays = min(max(daysBetween(period.Start, asOf)+1, 0), f.totalDays)
+	// History averages over whole months from the first one with spending,
+	// within the window the history was loaded for.
+	earliest := f.start
+	for _, item := range history {
+		f.history[item.LineID] = append(f.history[item.LineID], item)
+		date := day(item.TransactionDate)
+		earliest = minTime(earliest, date.AddDate(0, 0, 1-date.Day()))
+	}
+	f.historyDays = daysBetween(maxTime(earliest, f.start.AddDate(0, -forecastHistoryMonths, 0)), f.start)
+	return f
+}
+
+type recurringSeries struct {
+	months  map[int]struct{}
+	amounts []int64
+	// day is the day of the month the series last posted on.
+	day    int
+	posted int64
+}
+
+// recurring reports whether a description posted at most once per month in at
+// least two months for roughly the same amount, such as rent or subscriptions.
+func (s *recurringSeries) recurring() bool {
+	if len(s.months) < 2 || len(s.months) != len(s.amounts) {
+		return false
+	}
+	latest := s.latest()
+	for _, amount := range s.amounts {
+		if abs(amount-latest)*100 > abs(latest)*forecastRecurringTolerancePercent {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *recurringSeries) latest() int64 { return s.amounts[len(s.amounts)-1] }
+
+func (f forecaster) line(lineID, allocation, actual int64, transactions []DetailedTransaction) (Forecast, error) {
+	if f.elapsedDays >= f.totalDays {
+		return newForecast(f.asOf, allocation, actual, actual, 0), nil
+	}
+	series := make(map[string]*recurringSeries)
+	history := f.history[lineID]
+	for _, item := range history {
+		key := recurringKey(item.Description)
+		if key == "" {
+			continue
+		}
+		amount, err := cents(item.Amount)
+		if err != nil {
+			return Forecast{}, fmt.Errorf("invalid history amount: %w", err)
+		}
+		entry := series[key]
+		if entry == nil {
+			entry = &recurringSeries{months: make(map[int]struct{})}
+			series[key] = entry
+		}
+		entry.months[monthIndex(item.TransactionDate)] = struct{}{}
+		entry.amounts = append(entry.amounts, amount)
+		entry.day = item.TransactionDate.UTC().Day()
+	}
+	for key, entry := range series {
+		if !entry.recurring() {
+			delete(series, key)
+		}
+	}
+
+	variableHistory := int64(0)
+	for _, item := range history {
+		if series[recurringKey(item.Description)] != nil {
+			continue
+		}
+		amount, _ := cents(item.Amount)
+		variableHistory += amount
+	}
+	variableActual := actual
+	for _, transaction := range transactions {
+		entry := series[recurringKey(transaction.Description)]
+		if entry == nil {
+			continue
+		}
+		amount, _ := cents(transaction.Amount)
+		variableActual -= amount
+		entry.posted++
+	}
+	pending := int64(0)
+	for _, entry := range series {
+		pending += max(f.due(entry.day)-entry.posted, 0) * entry.latest()
+	}
+
+	remainingDays := f.totalDays - f.elapsedDays
+	remainingVariable := int64(0)
+	switch {
+	case f.historyDays > 0:
+		// Daily rate = w*pace + (1-w)*average with w = elapsed/total, where
+		// pace = variableActual/elapsed and average = variableHistory/historyDays.
+		remainingVariable = divRound(variableActual*f.historyDays*remainingDays+variableHistory*remainingDays*remainingDays, f.totalDays*f.historyDays)
+	case f.elapsedDays > 0:
+		remainingVariable = divRound(variableActual*remainingDays, f.elapsedDays)
+	}
+	projected := actual + pending + max(remainingVariable, 0)
+	return newForecast(f.asOf, allocation, actual, projected, pending), nil
+}
+
+// due counts the months overlapping the period whose day falls inside it. Days
+// past the end of a short month fall due on its last day.
+func (f forecaster) due(dayOfMonth int) int64 {
+	count := int64(0)
+	for month := f.start.AddDate(0, 0, 1-f.start.Day()); !month.After(f.end); month = month.AddDate(0, 1, 0) {
+		last := month.AddDate(0, 1, -1).Day()
+		date := month.AddDate(0, 0, min(dayOfMonth, last)-1)
+		if !date.Before(f.start) && !date.After(f.end) {
+			count++
+		}
+	}
+	return count
+}
+
+func newForecast(asOf time.Time, allocation, actual, projected, pending int64) Forecast {
+	return Forecast{
+		AsOf:                     asOf,
+		ProjectedAmount:          formatCents(projected),
+		ProjectedRemainingAmount: formatCents(allocation - projected),
+		RecurringPendingAmount:   formatCents(pending),
+		Risk:                     forecastRisk(allocation, actual, projected),
+	}
+}
+
+func forecastRisk(allocation, actual, projected int64) ForecastRisk {
+	switch {
+	case actual > allocation:
+		return ForecastOverBudget
+	case projected > allocation:
+		return ForecastAtRisk
+	case allocation > 0 && projected*100 >= allocation*forecastWatchPercent:
+		return ForecastWatch
+	default:
+		return ForecastOnTrack
+	}
+}
+
+// recurringKey matches transactions by normalized description; transactions
+// without one never count as recurring.
+func recurringKey(description *string) string {
+	if description == nil {
+		return ""
+	}
+	return strings.ToLower(strings.Join(strings.Fields(*description), " "))
+}
+
+func monthIndex(value time.Time) int {
+	value = value.UTC()
+	return value.Year()*12 + int(value.Month()) - 1
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func daysBetween(start, end time.Time) int64 {
+	return int64(day(end).Sub(day(start)).Hours() / 24)
+}
+
+func abs(value int64) int64 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func divRound(numerator, denominator int64) int64 {
+	if (numerator < 0) != (denominator < 0) {
+		return (numerator - denominator/2) / denominator
+	}
+	return (numerator + denominator/2) / denominator
+}
@@ -0,0 +1,97 @@
+package budgets
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+// Lint reports category overlaps, unmapped active categories and empty lines
+// of a budget, in that order.
+func (s *Service) Lint(ctx context.Context, budgetID int64) (Lint, error) {
+	if budgetID == 0 {
+		return Lint{}, apperrors.Validation("budget id is required")
+	}
+	snapshot, err := s.repo.LoadLintSnapshot(ctx, budgetID)
+	if err != nil {
+		return Lint{}, apperrors.WrapInternal("load budget lint snapshot", err)
+	}
+	issues, err := lintIssues(snapshot)
+	if err != nil {
+		return Lint{}, apperrors.WrapInternal("lint budget", err)
+	}
+	budget := snapshot.Budget
+	return Lint{
+		Budget: BudgetSummary{ID: budget.ID, Owner: budget.Owner, PeriodKind: budget.PeriodKind, PeriodStart: budget.PeriodStart, PeriodEnd: budget.PeriodEnd, SourceBudgetID: budget.SourceBudgetID},
+		Issues: issues,
+	}, nil
+}
+
+func lintIssues(snapshot LintSnapshot) ([]LintIssue, error) {
+	lines := slices.Clone(snapshot.Budget.Lines)
+	slices.SortStableFunc(lines, func(a, b Line) int { return cmp.Or(cmp.Compare(a.SortOrder, b.SortOrder), cmp.Compare(a.ID, b.ID)) })
+	mapped := make(map[int64][]LineRef)
+	categories := make(map[int64]Category)
+	for _, line := range lines {
+		for _, category := range line.Categories {
+			mapped[category.ID] = append(mapped[category.ID], LineRef{ID: line.ID, Name: line.Name})
+			categories[category.ID] = category
+		}
+	}
+
+	issues := []LintIssue{}
+	overlaps := make([]Category, 0)
+	for id, refs := range mapped {
+		if len(refs) > 1 {
+			overlaps = append(overlaps, categories[id])
+		}
+	}
+	slices.SortFunc(overlaps, compareCategories)
+	for _, category := range overlaps {
+		issues = append(issues, LintIssue{
+			Kind: LintCategoryOverlap, Message: fmt.Sprintf("category %q is mapped to %d lines and counted on each", category.Code, len(mapped[category.ID])),
+			Lines: mapped[category.ID], Category: &category,
+		})
+	}
+
+	spent := make(map[int64]int64)
+	for _, transaction := range snapshot.UnmappedTransactions {
+		if transaction.Category == nil {
+			continue
+		}
+		amount, err := cents(transaction.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unmapped amount: %w", err)
+		}
+		spent[transaction.Category.ID] += amount
+	}
+	active := slices.Clone(snapshot.ActiveCategories)
+	slices.SortFunc(active, compareCategories)
+	for _, category := range active {
+		if _, exists := mapped[category.ID]; exists {
+			continue
+		}
+		issues = append(issues, LintIssue{
+			Kind: LintUnmappedCategory, Message: fmt.Sprintf("category %q is not mapped to any line", category.Code),
+			Lines: []LineRef{}, Category: &category, ActualAmount: formatCents(spent[category.ID]),
+		})
+	}
+
+	for _, line := range lines {
+		if len(line.Categories) > 0 {
+			continue
+		}
+		issues = append(issues, LintIssue{
+			Kind: LintEmptyLine, Message: fmt.Sprintf("line %q has no categories and never records spending", line.Name),
+			Lines: []LineRef{{ID: line.ID, Name: line.Name}},
+		})
+	}
+	return issues, nil
+}
+
+func compareCategories(a, b Category) int {
+	return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+}
@@ -0,0 +1,148 @@
+package budgets
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+// memberAllocations validates member allocations and normalizes amounts and
+// percentages to two decimal places. When the line allocation is given the
+// shares must fit in it; otherwise the repository checks them against the
+// stored allocation, as it checks that the members belong to the budget's
+// household.
+func memberAllocations(values []MemberAllocationInput, allocation *string) ([]MemberAllocationInput, error) {
+	result := make([]MemberAllocationInput, 0, len(values))
+	seen := make(map[int64]bool, len(values))
+	totalPercent := int64(0)
+	for _, value := range values {
+		if value.UserID == 0 {
+			return nil, apperrors.Validation("member allocation user id is required")
+		}
+		if seen[value.UserID] {
+			return nil, apperrors.Validation(fmt.Sprintf("user %d has more than one member allocation", value.UserID))
+		}
+		seen[value.UserID] = true
+		if (value.Amount == nil) == (value.Percent == nil) {
+			return nil, apperrors.Validation("member allocations need exactly one of amount and percent")
+		}
+		if value.Amount != nil {
+			amount, err := amountString(*value.Amount)
+			if err != nil {
+				return nil, err
+			}
+			value.Amount = &amount
+		} else {
+			percent, err := cents(*value.Percent)
+			if err != nil || percent <= 0 || percent > 100_00 {
+				return nil, apperrors.Validation("member allocation percent must be above 0 and at most 100, with at most two decimal places")
+			}
+			totalPercent += percent
+			formatted := formatCents(percent)
+			value.Percent = &formatted
+		}
+		result = append(result, value)
+	}
+	if totalPercent > 100_00 {
+		return nil, apperrors.Validation("member allocation percentages add up to more than 100")
+	}
+	if allocation != nil {
+		if err := CheckMemberAmounts(*allocation, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// CheckMemberAmounts rejects member allocations whose fixed amounts, together
+// with the percentage shares of the line allocation, add up to more than the
+// line allocation. Repositories call it after changing a line's allocation or
+// members.
+func CheckMemberAmounts(allocation string, members []MemberAllocationInput) error {
+	limit, err := cents(allocation)
+	if err != nil {
+		return apperrors.Validation("allocation amount must be a decimal with at most two decimal places")
+	}
+	total, totalPercent := int64(0), int64(0)
+	for _, member := range members {
+		switch {
+		case member.Amount != nil:
+			value, err := cents(*member.Amount)
+			if err != nil {
+				return apperrors.Validation("member allocation amount must be a decimal with at most two decimal places")
+			}
+			total += value
+		case member.Percent != nil:
+			percent, err := cents(*member.Percent)
+			if err != nil {
+				return apperrors.Validation("member allocation percent must be a decimal with at most two decimal places")
+			}
+			totalPercent += percent
+		}
+	}
+	// Compare in hundredths of a cent so shares are not rounded.
+	if total*100_00+limit*totalPercent <= limit*100_00 {
+		return nil
+	}
+	if totalPercent == 0 {
+		return apperrors.Validation(fmt.Sprintf("member allocation amounts add up to %s, more than the line allocation of %s", formatCents(total), formatCents(limit)))
+	}
+	return apperrors.Validation(fmt.Sprintf("member allocation amounts of %s and shares of %s%% add up to more than the line allocation of %s", formatCents(total), formatCents(totalPercent), formatCents(limit)))
+}
+
+// memberShare resolves a member's allocation in cents. Percentages of the line
+// allocation round to the nearest cent.
+func memberShare(allocation int64, item MemberAllocation) (int64, error) {
+	if item.Amount != nil {
+		return cents(*item.Amount)
+	}
+	if item.Percent == nil {
+		return 0, fmt.Errorf("member allocation for user %d has neither amount nor percent", item.Member.ID)
+	}
+	percent, err := cents(*item.Percent)
+	if err != nil {
+		return 0, fmt.Errorf("invalid member allocation percent: %w", err)
+	}
+	return (allocation*percent + 50_00) / 100_00, nil
+}
+
+// memberSpending compares each member's spending on a line, by transaction
+// author, with their share of the allocation. Members with an allocation come
+// first in allocation order, followed by anyone else who spent, by name.
+func memberSpending(line Line, allocation int64, transactions []DetailedTransaction) ([]MemberSpending, error) {
+	if len(line.MemberAllocations) == 0 {
+		return []MemberSpending{}, nil
+	}
+	spent := make(map[int64]int64)
+	authors := make(map[int64]Author)
+	for _, transaction := range transactions {
+		amount, err := cents(transaction.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction amount: %w", err)
+		}
+		spent[transaction.Author.ID] += amount
+		authors[transaction.Author.ID] = transaction.Author
+	}
+	result := make([]MemberSpending, 0, len(line.MemberAllocations)+len(authors))
+	for _, item := range line.MemberAllocations {
+		share, err := memberShare(allocation, item)
+		if err != nil {
+			return nil, err
+		}
+		actual := spent[item.Member.ID]
+		delete(authors, item.Member.ID)
+		result = append(result, MemberSpending{Member: item.Member, AllocationAmount: formatCents(share), ActualAmount: formatCents(actual), RemainingAmount: formatCents(share - actual)})
+	}
+	unallocated := make([]Author, 0, len(authors))
+	for _, author := range authors {
+		unallocated = append(unallocated, author)
+	}
+	slices.SortFunc(unallocated, func(a, b Author) int { return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID)) })
+	for _, author := range unallocated {
+		actual := spent[author.ID]
+		result = append(result, MemberSpending{Member: author, AllocationAmount: formatCents(0), ActualAmount: formatCents(actual), RemainingAmount: formatCents(-actual)})
+	}
+	return result, nil
+}
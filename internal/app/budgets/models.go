@@ -7,15 +7,60 @@ type Owner struct {
 	UserID      *int64
 }
 
+// MonthlyInput selects a calendar month. Template optionally names the budget
+// template a missing budget is created from when ensuring it.
 type MonthlyInput struct {
-	Owner Owner
-	Year  int
-	Month int
+	Owner    Owner
+	Year     int
+	Month    int
+	Template string
+}
+
+// PeriodKind names how a budget period is laid out on the calendar. An owner
+// holds at most one budget of each kind for any given day.
+type PeriodKind string
+
+const (
+	PeriodWeekly    PeriodKind = "weekly"
+	PeriodBiweekly  PeriodKind = "biweekly"
+	PeriodMonthly   PeriodKind = "monthly"
+	PeriodQuarterly PeriodKind = "quarterly"
+	PeriodAnnual    PeriodKind = "annual"
+	PeriodCustom    PeriodKind = "custom"
+)
+
+// Alert thresholds are percentages of a line's allocation; the upper bound
+// matches the database check constraint.
+const (
+	MinAlertThreshold int32 = 1
+	MaxAlertThreshold int32 = 1000
+)
+
+// Period is a resolved, inclusive range of UTC calendar dates.
+type Period struct {
+	Kind  PeriodKind
+	Start time.Time
+	End   time.Time
+}
+
+// PeriodInput selects the period of Kind that contains Date. Weekly periods
+// start on Monday, biweekly periods repeat every 14 days from Anchor (usually
+// a payday), and custom periods use Start and End verbatim instead of Date.
+// Template works as in MonthlyInput.
+type PeriodInput struct {
+	Owner    Owner
+	Kind     PeriodKind
+	Date     time.Time
+	Anchor   *time.Time
+	Start    *time.Time
+	End      *time.Time
+	Template string
 }
 
 type Budget struct {
 	ID             int64
 	Owner          Owner
+	PeriodKind     PeriodKind
 	PeriodStart    time.Time
 	PeriodEnd      time.Time
 	SourceBudgetID *int64
@@ -29,6 +74,31 @@ type Line struct {
 	AllocationAmount string
 	SortOrder        int32
 	Categories       []Category
+	// AlertThresholds are the percentages of the allocation at which the
+	// line raises an overspend alert, in ascending order.
+	AlertThresholds []int32
+	// MemberAllocations split a household line's allocation between members,
+	// ordered by member name.
+	MemberAllocations []MemberAllocation
+	// Version counts the writes to the line and is served as its ETag.
+	Version int64
+}
+
+// MemberAllocation gives a household member either a fixed Amount or a
+// Percent of the line's allocation; exactly one is set.
+type MemberAllocation struct {
+	Member  Author
+	Amount  *string
+	Percent *string
+}
+
+// MemberAllocationInput sets a household member's share of a line. Exactly
+// one of Amount and Percent is required; percentages of a line add up to at
+// most 100.
+type MemberAllocationInput struct {
+	UserID  int64
+	Amount  *string
+	Percent *string
 }
 
 type Category struct {
@@ -37,10 +107,13 @@ type Category struct {
 	Name string
 }
 
-type CreateMonthlyFromTemplateInput struct {
-	Owner       Owner
-	PeriodStart time.Time
-	PeriodEnd   time.Time
+// CreateFromTemplateInput creates a budget for Period. The lines come from
+// the budget template TemplateID when set and from the owner's latest prior
+// budget of the same kind otherwise.
+type CreateFromTemplateInput struct {
+	Owner      Owner
+	Period     Period
+	TemplateID *int64
 }
 
 type CreateLineInput struct {
@@ -50,6 +123,10 @@ type CreateLineInput struct {
 	CategoryIDs      []int64
 	CategoryCodes    []string
 	SortOrder        *int32
+	AlertThresholds  []int32
+	// MemberAllocations are only accepted on household budgets, for members
+	// of the household.
+	MemberAllocations []MemberAllocationInput
 }
 
 type UpdateLineInput struct {
@@ -59,11 +136,55 @@ type UpdateLineInput struct {
 	CategoryIDs      *[]int64
 	CategoryCodes    *[]string
 	SortOrder        *int32
+	AlertThresholds  *[]int32
+	// MemberAllocations replaces the line's member allocations when set.
+	MemberAllocations *[]MemberAllocationInput
+	// ExpectedVersion, when set, makes the update fail with a precondition
+	// error unless the line is still at that version.
+	ExpectedVersion *int64
 }
 
 type ReportLineData struct {
 	Line
 	ActualAmount string
+	// OriginalAllocationAmount is the allocation before reallocations; empty
+	// means the same as AllocationAmount.
+	OriginalAllocationAmount string
+}
+
+// ReallocateInput moves Amount of allocation from one line of a budget to
+// another on behalf of UserID.
+type ReallocateInput struct {
+	BudgetID   int64
+	FromLineID int64
+	ToLineID   int64
+	Amount     string
+	Reason     *string
+	UserID     int64
+}
+
+// Reallocation is one recorded move of allocation between two lines.
+type Reallocation struct {
+	ID        int64
+	BudgetID  int64
+	FromLine  LineRef
+	ToLine    LineRef
+	Amount    string
+	Reason    *string
+	CreatedBy Author
+	CreatedAt time.Time
+}
+
+type LineRef struct {
+	ID   int64
+	Name string
+}
+
+// ReallocationResult is a recorded move with both lines as adjusted.
+type ReallocationResult struct {
+	Reallocation Reallocation
+	FromLine     Line
+	ToLine       Line
 }
 
 type UnmappedTransaction struct {
@@ -101,6 +222,8 @@ type DetailedReportSnapshot struct {
 	Lines                []DetailedReportLineData
 	UnmappedTransactions []DetailedTransaction
 	UncategorizedAmount  string
+	// Closing is the budget's open closing, if any.
+	Closing *Closing
 }
 
 type ReportSnapshot struct {
@@ -108,6 +231,9 @@ type ReportSnapshot struct {
 	Lines                []ReportLineData
 	UnmappedTransactions []UnmappedTransaction
 	UncategorizedAmount  string
+	Reallocations        []Reallocation
+	// Closing is the budget's open closing, if any.
+	Closing *Closing
 }
 
 type Report struct {
@@ -115,20 +241,91 @@ type Report struct {
 	Lines                []ReportLine
 	UnmappedTransactions []UnmappedTransaction
 	Totals               ReportTotals
+	Goals                []GoalProgress
+	Reallocations        []Reallocation
+	Closing              *ReportClosing
+}
+
+// ReportClosing marks a report of a closed budget. Line actuals and totals come
+// from the closing snapshot; CurrentTotals and Drift show what the live data
+// says now, and Drifted is set when the two differ.
+type ReportClosing struct {
+	ID            int64
+	ClosedAt      time.Time
+	ClosedBy      Author
+	Drifted       bool
+	CurrentTotals ReportTotals
+	Drift         []LineDrift
+}
+
+// LineDrift is a line whose live actual differs from its closing snapshot.
+type LineDrift struct {
+	LineID              int64
+	Name                string
+	ClosedActualAmount  string
+	CurrentActualAmount string
+}
+
+// Closing freezes a reviewed budget: its lines and the transactions dated in
+// its period cannot change until it is reopened. Lines and Totals are the
+// report as it stood at close time. The reopen fields are set once the budget
+// has been reopened.
+type Closing struct {
+	ID           int64
+	BudgetID     int64
+	Lines        []ClosingLine
+	Totals       ReportTotals
+	ClosedBy     Author
+	ClosedAt     time.Time
+	ReopenedBy   *Author
+	ReopenedAt   *time.Time
+	ReopenReason *string
+}
+
+// ClosingLine is a line as it stood at close time. LineID is nil when the
+// line was deleted after the budget was reopened.
+type ClosingLine struct {
+	LineID           *int64
+	Name             string
+	AllocationAmount string
+	ActualAmount     string
+	SortOrder        int32
+}
+
+// CloseInput closes a budget on behalf of UserID. The service fills Lines and
+// Totals from the report snapshot the repository takes under the budget lock.
+type CloseInput struct {
+	BudgetID int64
+	UserID   int64
+	Lines    []ClosingLine
+	Totals   ReportTotals
+}
+
+type ReopenInput struct {
+	BudgetID int64
+	UserID   int64
+	Reason   string
 }
 
 type BudgetSummary struct {
 	ID             int64
 	Owner          Owner
+	PeriodKind     PeriodKind
 	PeriodStart    time.Time
 	PeriodEnd      time.Time
 	SourceBudgetID *int64
 }
 
+// ReportLine reports actuals against the line's adjusted allocation.
+// ReallocatedAmount is the net allocation moved into the line, negative when
+// more moved out, so AllocationAmount is OriginalAllocationAmount plus
+// ReallocatedAmount.
 type ReportLine struct {
 	Line
-	ActualAmount    string
-	RemainingAmount string
+	ActualAmount             string
+	RemainingAmount          string
+	OriginalAllocationAmount string
+	ReallocatedAmount        string
 }
 
 type ReportTotals struct {
@@ -144,14 +341,377 @@ type DetailedReport struct {
 	Lines                []DetailedReportLine
 	UnmappedTransactions []DetailedTransaction
 	Totals               ReportTotals
+	Forecast             Forecast
+	Goals                []GoalProgress
+	// Closing is set for a closed budget, whose line actuals and totals come
+	// from the closing snapshot and whose forecasts project those actuals.
+	Closing *ReportClosing
 }
 
 type DetailedReportLine struct {
 	ReportLine
 	Transactions []DetailedTransaction
+	Forecast     Forecast
+	// Members compares each member's spending with their allocation. It is
+	// empty unless the line has member allocations.
+	Members []MemberSpending
+}
+
+// MemberSpending is one household member's spending on a line, by transaction
+// author. Members who spent without an allocation get a zero allocation.
+type MemberSpending struct {
+	Member           Author
+	AllocationAmount string
+	ActualAmount     string
+	RemainingAmount  string
+}
+
+// ForecastRisk classifies where a line is heading by the end of its period.
+type ForecastRisk string
+
+const (
+	ForecastOnTrack    ForecastRisk = "on_track"
+	ForecastWatch      ForecastRisk = "watch"
+	ForecastAtRisk     ForecastRisk = "at_risk"
+	ForecastOverBudget ForecastRisk = "over_budget"
+)
+
+// Forecast projects period-end spending from the pace so far, recurring
+// transactions that have not posted yet, and the line's recent history. AsOf
+// is the day the projection was made; past periods project their actuals.
+type Forecast struct {
+	AsOf                     time.Time
+	ProjectedAmount          string
+	ProjectedRemainingAmount string
+	RecurringPendingAmount   string
+	Risk                     ForecastRisk
+}
+
+// HistoryTransaction is spending from before a budget period in one of the
+// categories a line currently maps.
+type HistoryTransaction struct {
+	LineID          int64
+	ID              int64
+	TransactionDate time.Time
+	Description     *string
+	Amount          string
+}
+
+// MaxTrendMonths bounds how many months a trend report may span.
+const MaxTrendMonths = 24
+
+// TrendInput selects an owner's monthly budgets from the month containing
+// From through the month containing To.
+type TrendInput struct {
+	Owner Owner
+	From  time.Time
+	To    time.Time
+}
+
+// TrendLineData is one line of a monthly budget with its actual spending and
+// the categories it maps.
+type TrendLineData struct {
+	BudgetID         int64
+	PeriodStart      time.Time
+	LineID           int64
+	Name             string
+	AllocationAmount string
+	ActualAmount     string
+	SortOrder        int32
+	Categories       []Category
+}
+
+// TrendCategoryData is an owner's spending in one category during one
+// calendar month. Category is nil for uncategorized spending.
+type TrendCategoryData struct {
+	Month        time.Time
+	Category     *Category
+	ActualAmount string
+}
+
+type TrendSnapshot struct {
+	Lines      []TrendLineData
+	Categories []TrendCategoryData
+}
+
+// TrendReport compares allocations with actual spending across consecutive
+// months. Lines are matched across months by name because each month's lines
+// are copies of the previous month's. YearToDate always runs from January of
+// To's year through To, even when From is later.
+type TrendReport struct {
+	Owner      Owner
+	From       time.Time
+	To         time.Time
+	Months     []TrendMonth
+	Lines      []TrendSeries
+	Categories []TrendSeries
+	Totals     TrendTotals
+	YearToDate TrendTotals
+}
+
+// TrendMonth totals the budget lines of one month. BudgetID is nil when the
+// owner had no monthly budget that month.
+type TrendMonth struct {
+	Month            time.Time
+	BudgetID         *int64
+	AllocationAmount string
+	ActualAmount     string
+	RemainingAmount  string
+}
+
+// TrendSeries follows one budget line or one category across the report's
+// months. A category's allocation is that of the line mapping it, split evenly
+// when the line maps several categories, and a category month is budgeted
+// when a line mapped it. Category is nil for lines and for uncategorized
+// spending, which has no allocation.
+type TrendSeries struct {
+	Name       string
+	Category   *Category
+	Months     []TrendPoint
+	Totals     TrendTotals
+	YearToDate TrendTotals
+}
+
+// TrendPoint is one month of a series. Budgeted is false when the series had
+// no budget line that month.
+type TrendPoint struct {
+	Month            time.Time
+	Budgeted         bool
+	AllocationAmount string
+	ActualAmount     string
+	RemainingAmount  string
+}
+
+// TrendTotals sums a range of months; averages divide by every month in the
+// range, including months without a budget.
+type TrendTotals struct {
+	Months                  int
+	AllocationAmount        string
+	ActualAmount            string
+	RemainingAmount         string
+	AverageAllocationAmount string
+	AverageActualAmount     string
+}
+
+// GoalProgress is a savings goal measured at the end of a budget period.
+// PeriodNetAmount is the net contribution made within the period.
+type GoalProgress struct {
+	ID                    int64
+	Name                  string
+	TargetAmount          string
+	TargetDate            time.Time
+	BalanceAmount         string
+	RemainingAmount       string
+	RequiredMonthlyAmount string
+	PeriodNetAmount       string
+	State                 string
 }
 
 type EnsureResult struct {
 	Budget  Budget
 	Created bool
 }
+
+// LintIssueKind names a budget structure problem that skews its report.
+type LintIssueKind string
+
+const (
+	// LintCategoryOverlap is a category mapped to more than one line, which
+	// counts its spending once per line.
+	LintCategoryOverlap LintIssueKind = "category_overlap"
+	// LintUnmappedCategory is an active category no line maps, so its
+	// spending only appears among the unmapped transactions.
+	LintUnmappedCategory LintIssueKind = "unmapped_category"
+	// LintEmptyLine is a line without categories, which never records
+	// spending.
+	LintEmptyLine LintIssueKind = "empty_line"
+)
+
+// LintSnapshot is the budget structure with the active categories and the
+// period's unmapped transactions.
+type LintSnapshot struct {
+	Budget               Budget
+	ActiveCategories     []Category
+	UnmappedTransactions []UnmappedTransaction
+}
+
+type Lint struct {
+	Budget BudgetSummary
+	Issues []LintIssue
+}
+
+// LintIssue describes one problem. Category is set for overlaps and unmapped
+// categories, and ActualAmount is the unmapped category's spending in the
+// period.
+type LintIssue struct {
+	Kind         LintIssueKind
+	Message      string
+	Lines        []LineRef
+	Category     *Category
+	ActualAmount string
+}
+
+// Template is a named budget layout an owner can create budgets from, merge
+// into an existing budget, or save a budget as.
+type Template struct {
+	ID    int64
+	Owner Owner
+	Name  string
+	Lines []TemplateLine
+}
+
+type TemplateLine struct {
+	ID               int64
+	Name             string
+	AllocationAmount string
+	SortOrder        int32
+	Categories       []Category
+}
+
+// TemplateLineInput describes one template line. A nil SortOrder follows the
+// previous line.
+type TemplateLineInput struct {
+	Name             string
+	AllocationAmount string
+	CategoryIDs      []int64
+	CategoryCodes    []string
+	SortOrder        *int32
+}
+
+type CreateTemplateInput struct {
+	Owner Owner
+	Name  string
+	Lines []TemplateLineInput
+}
+
+// UpdateTemplateInput renames a template and, when Lines is set, replaces all
+// of its lines.
+type UpdateTemplateInput struct {
+	ID    int64
+	Name  *string
+	Lines *[]TemplateLineInput
+}
+
+// SaveTemplateInput saves the lines of a budget as a new template of the
+// budget's owner.
+type SaveTemplateInput struct {
+	BudgetID int64
+	Name     string
+}
+
+// ApplyMode selects what happens to budget lines the template does not have.
+type ApplyMode string
+
+const (
+	// ApplyMerge keeps lines missing from the template.
+	ApplyMerge ApplyMode = "merge"
+	// ApplyReplace removes lines missing from the template.
+	ApplyReplace ApplyMode = "replace"
+)
+
+// ApplyTemplateInput applies the template TemplateID, or the template named
+// TemplateName for the budget's owner, to a budget. DryRun only plans the
+// changes.
+type ApplyTemplateInput struct {
+	BudgetID     int64
+	TemplateID   *int64
+	TemplateName *string
+	Mode         ApplyMode
+	DryRun       bool
+}
+
+type LineChangeAction string
+
+const (
+	LineAdded   LineChangeAction = "add"
+	LineUpdated LineChangeAction = "update"
+	LineRemoved LineChangeAction = "remove"
+)
+
+// LineChange is one planned change to a budget line. Before is nil for added
+// lines and After is nil for removed lines; an added line has no ID until the
+// change is applied.
+type LineChange struct {
+	Action LineChangeAction
+	Before *Line
+	After  *Line
+}
+
+type ApplyTemplateResult struct {
+	Budget   Budget
+	Template Template
+	Mode     ApplyMode
+	Changes  []LineChange
+	Applied  bool
+}
+
+// ComparisonInput compares budget B against budget A.
+type ComparisonInput struct {
+	A int64
+	B int64
+}
+
+type LineComparisonStatus string
+
+const (
+	LineMatched LineComparisonStatus = "matched"
+	// LineInBOnly is a line budget B has and budget A does not.
+	LineInBOnly LineComparisonStatus = "added"
+	// LineInAOnly is a line budget A has and budget B does not.
+	LineInAOnly LineComparisonStatus = "removed"
+)
+
+type LineMatch string
+
+const (
+	LineMatchName LineMatch = "name"
+	// LineMatchCategories pairs a renamed line by its unchanged category
+	// mapping. It is only used when one budget descends from the other.
+	LineMatchCategories LineMatch = "categories"
+)
+
+// Comparison aligns the lines of budget B with those of budget A. Deltas are B
+// minus A, counting a missing line as zero.
+type Comparison struct {
+	A       BudgetSummary
+	B       BudgetSummary
+	Lineage *Lineage
+	Lines   []LineComparison
+	Totals  ComparisonTotals
+}
+
+// Lineage is set when DescendantID was created from AncestorID, directly or
+// through Generations copies.
+type Lineage struct {
+	AncestorID   int64
+	DescendantID int64
+	Generations  int
+}
+
+// LineComparison is one aligned line. A is nil for added lines and B for
+// removed lines. Category changes are only reported for matched lines.
+type LineComparison struct {
+	Status            LineComparisonStatus
+	MatchedBy         LineMatch
+	A                 *ComparedLine
+	B                 *ComparedLine
+	AllocationDelta   string
+	ActualDelta       string
+	AddedCategories   []Category
+	RemovedCategories []Category
+}
+
+type ComparedLine struct {
+	ID               int64
+	Name             string
+	AllocationAmount string
+	ActualAmount     string
+	Categories       []Category
+}
+
+type ComparisonTotals struct {
+	A               ReportTotals
+	B               ReportTotals
+	AllocationDelta string
+	ActualDelta     string
+}
@@ -0,0 +1,88 @@
+package budgets
+
+import (
+	"strings"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+const biweeklyDays = 14
+
+// ParsePeriodKind accepts the wire spelling of a period kind.
+func ParsePeriodKind(value string) (PeriodKind, error) {
+	kind := PeriodKind(strings.ToLower(strings.TrimSpace(value)))
+	switch kind {
+	case PeriodWeekly, PeriodBiweekly, PeriodMonthly, PeriodQuarterly, PeriodAnnual, PeriodCustom:
+		return kind, nil
+	}
+	return "", apperrors.Validation("period must be one of weekly, biweekly, monthly, quarterly, annual, custom")
+}
+
+func validatePeriod(input PeriodInput) (Period, error) {
+	if err := validateOwner(input.Owner); err != nil {
+		return Period{}, err
+	}
+	kind, err := ParsePeriodKind(string(input.Kind))
+	if err != nil {
+		return Period{}, err
+	}
+	if input.Anchor != nil && kind != PeriodBiweekly {
+		return Period{}, apperrors.Validation("anchor is only valid for biweekly periods")
+	}
+	if (input.Start != nil || input.End != nil) && kind != PeriodCustom {
+		return Period{}, apperrors.Validation("start and end are only valid for custom periods")
+	}
+	if kind == PeriodCustom {
+		if input.Start == nil || input.End == nil {
+			return Period{}, apperrors.Validation("custom periods require start and end")
+		}
+		start, end := day(*input.Start), day(*input.End)
+		if end.Before(start) {
+			return Period{}, apperrors.Validation("period end must not be before period start")
+		}
+		return Period{Kind: kind, Start: start, End: end}, nil
+	}
+	if input.Date.IsZero() {
+		return Period{}, apperrors.Validation("date is required")
+	}
+	date := day(input.Date)
+	switch kind {
+	case PeriodWeekly:
+		start := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+		return Period{Kind: kind, Start: start, End: start.AddDate(0, 0, 6)}, nil
+	case PeriodBiweekly:
+		if input.Anchor == nil {
+			return Period{}, apperrors.Validation("biweekly periods require an anchor date")
+		}
+		anchor := day(*input.Anchor)
+		offset := int(date.Sub(anchor).Hours() / 24)
+		cycles := offset / biweeklyDays
+		if offset < 0 && offset%biweeklyDays != 0 {
+			cycles--
+		}
+		start := anchor.AddDate(0, 0, cycles*biweeklyDays)
+		return Period{Kind: kind, Start: start, End: start.AddDate(0, 0, biweeklyDays-1)}, nil
+	case PeriodQuarterly:
+		start := time.Date(date.Year(), (date.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+		return Period{Kind: kind, Start: start, End: start.AddDate(0, 3, -1)}, nil
+	case PeriodAnnual:
+		start := time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return Period{Kind: kind, Start: start, End: start.AddDate(1, 0, -1)}, nil
+	default:
+		start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return Period{Kind: kind, Start: start, End: start.AddDate(0, 1, -1)}, nil
+	}
+}
+
+func validateOwner(owner Owner) error {
+	if (owner.HouseholdID == nil) == (owner.UserID == nil) {
+		return apperrors.Validation("exactly one budget owner is required")
+	}
+	return nil
+}
+
+// day truncates a timestamp to its calendar date in UTC.
+func day(value time.Time) time.Time {
+	return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -8,11 +8,37 @@ import (
 // Repository exposes use-case-level persistence operations. Implementations own
 // transaction boundaries, locking, aggregate loading, and join-table mechanics.
 type Repository interface {
-	FindMonthly(context.Context, Owner, time.Time, time.Time) (Budget, error)
-	CreateMonthlyFromTemplate(context.Context, CreateMonthlyFromTemplateInput) (Budget, error)
+	FindByPeriod(context.Context, Owner, Period) (Budget, error)
+	FindByID(context.Context, int64) (Budget, error)
+	CreateFromTemplate(context.Context, CreateFromTemplateInput) (Budget, error)
 	CreateLineWithCategories(context.Context, CreateLineInput) (Line, error)
 	UpdateLineWithCategories(context.Context, UpdateLineInput) (Line, error)
-	DeleteLine(context.Context, int64) error
+	// DeleteLine returns the ID of the budget the deleted line belonged to.
+	// A non-nil expected version must match the line's current version.
+	DeleteLine(ctx context.Context, id int64, expectedVersion *int64) (int64, error)
+	Reallocate(context.Context, ReallocateInput) (ReallocationResult, error)
 	LoadReportSnapshot(context.Context, int64) (ReportSnapshot, error)
-	LoadDetailedMonthlySnapshot(context.Context, Owner, time.Time, time.Time) (DetailedReportSnapshot, error)
+	ListLineage(context.Context, int64) ([]int64, error)
+	LoadDetailedSnapshot(context.Context, Owner, Period) (DetailedReportSnapshot, error)
+	ListLineHistory(context.Context, int64, time.Time) ([]HistoryTransaction, error)
+	LoadTrendSnapshot(context.Context, Owner, Period) (TrendSnapshot, error)
+	LoadLintSnapshot(context.Context, int64) (LintSnapshot, error)
+	ApplyLineChanges(context.Context, int64, []LineChange) (Budget, error)
+	CreateTemplate(context.Context, CreateTemplateInput) (Template, error)
+	GetTemplate(context.Context, int64) (Template, error)
+	FindTemplateByName(context.Context, Owner, string) (Template, error)
+	ListTemplates(context.Context, Owner) ([]Template, error)
+	UpdateTemplate(context.Context, UpdateTemplateInput) (Template, error)
+	DeleteTemplate(context.Context, int64) error
+	SaveTemplate(context.Context, SaveTemplateInput) (Template, error)
+	// Close locks the budget, loads its report snapshot in the same
+	// transaction and records the closing that snapshot turns into.
+	Close(ctx context.Context, input CloseInput, snapshot func(ReportSnapshot) (CloseInput, error)) (Closing, error)
+	Reopen(context.Context, ReopenInput) (Closing, error)
+}
+
+// GoalReader supplies savings goal progress for an owner's budget period. It is
+// optional; reports list no goals when the service is built without one.
+type GoalReader interface {
+	GoalProgress(context.Context, Owner, Period) ([]GoalProgress, error)
 }
@@ -5,50 +5,106 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 	"strings"
 	"time"
 
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/app/events"
 )
 
-type Service struct{ repo Repository }
+type Service struct {
+	repo   Repository
+	goals  GoalReader
+	events events.Publisher
+	now    func() time.Time
+}
 
-func NewService(repo Repository) *Service { return &Service{repo: repo} }
+func NewService(repo Repository, goals ...GoalReader) *Service {
+	service := &Service{repo: repo, events: events.Discard, now: time.Now}
+	if len(goals) > 0 {
+		service.goals = goals[0]
+	}
+	return service
+}
+
+// WithEvents announces every committed change to budget lines on publisher,
+// one event per line.
+func (s *Service) WithEvents(publisher events.Publisher) *Service {
+	s.events = publisher
+	return s
+}
 
 func (s *Service) GetMonthly(ctx context.Context, input MonthlyInput) (Budget, error) {
-	start, end, err := validateMonthly(input)
+	period, err := validateMonthly(input)
 	if err != nil {
 		return Budget{}, err
 	}
-	budget, err := s.repo.FindMonthly(ctx, input.Owner, start, end)
+	return s.get(ctx, input.Owner, period)
+}
+
+func (s *Service) GetPeriod(ctx context.Context, input PeriodInput) (Budget, error) {
+	period, err := validatePeriod(input)
 	if err != nil {
-		return Budget{}, apperrors.WrapInternal("get monthly budget", err)
+		return Budget{}, err
 	}
-	return normalizeBudget(budget), nil
+	return s.get(ctx, input.Owner, period)
 }
 
 func (s *Service) EnsureMonthly(ctx context.Context, input MonthlyInput) (EnsureResult, error) {
-	start, end, err := validateMonthly(input)
+	period, err := validateMonthly(input)
 	if err != nil {
 		return EnsureResult{}, err
 	}
-	existing, err := s.repo.FindMonthly(ctx, input.Owner, start, end)
+	return s.ensure(ctx, input.Owner, period, input.Template)
+}
+
+func (s *Service) EnsurePeriod(ctx context.Context, input PeriodInput) (EnsureResult, error) {
+	period, err := validatePeriod(input)
+	if err != nil {
+		return EnsureResult{}, err
+	}
+	return s.ensure(ctx, input.Owner, period, input.Template)
+}
+
+func (s *Service) get(ctx context.Context, owner Owner, period Period) (Budget, error) {
+	budget, err := s.repo.FindByPeriod(ctx, owner, period)
+	if err != nil {
+		return Budget{}, apperrors.WrapInternal("get budget", err)
+	}
+	return normalizeBudget(budget), nil
+}
+
+// ensure returns the owner's budget for the period, creating it when missing
+// from the named template or, without one, from the latest prior budget of the
+// same kind. The template is resolved even when the budget exists so a
+// misspelt name always fails.
+func (s *Service) ensure(ctx context.Context, owner Owner, period Period, templateName string) (EnsureResult, error) {
+	input := CreateFromTemplateInput{Owner: owner, Period: period}
+	if strings.TrimSpace(templateName) != "" {
+		template, err := s.findTemplate(ctx, owner, templateName)
+		if err != nil {
+			return EnsureResult{}, apperrors.WrapInternal("get budget template", err)
+		}
+		input.TemplateID = &template.ID
+	}
+	existing, err := s.repo.FindByPeriod(ctx, owner, period)
 	if err == nil {
 		return EnsureResult{Budget: normalizeBudget(existing)}, nil
 	}
 	if !apperrors.IsKind(err, apperrors.KindNotFound) {
-		return EnsureResult{}, apperrors.WrapInternal("find monthly budget", err)
+		return EnsureResult{}, apperrors.WrapInternal("find budget", err)
 	}
 
-	created, err := s.repo.CreateMonthlyFromTemplate(ctx, CreateMonthlyFromTemplateInput{Owner: input.Owner, PeriodStart: start, PeriodEnd: end})
+	created, err := s.repo.CreateFromTemplate(ctx, input)
 	if err != nil {
 		if apperrors.IsKind(err, apperrors.KindConflict) {
-			concurrent, findErr := s.repo.FindMonthly(ctx, input.Owner, start, end)
+			concurrent, findErr := s.repo.FindByPeriod(ctx, owner, period)
 			if findErr == nil {
 				return EnsureResult{Budget: normalizeBudget(concurrent)}, nil
 			}
 		}
-		return EnsureResult{}, apperrors.WrapInternal("ensure monthly budget", err)
+		return EnsureResult{}, apperrors.WrapInternal("ensure budget", err)
 	}
 	return EnsureResult{Budget: normalizeBudget(created), Created: true}, nil
 }
@@ -65,19 +121,30 @@ func (s *Service) CreateLine(ctx context.Context, input CreateLineInput) (Line,
 	if err != nil {
 		return Line{}, err
 	}
-	input.Name, input.AllocationAmount = name, amount
+	thresholds, err := alertThresholds(input.AlertThresholds)
+	if err != nil {
+		return Line{}, err
+	}
+	members, err := memberAllocations(input.MemberAllocations, &amount)
+	if err != nil {
+		return Line{}, err
+	}
+	input.Name, input.AllocationAmount, input.AlertThresholds, input.MemberAllocations = name, amount, thresholds, members
 	line, err := s.repo.CreateLineWithCategories(ctx, input)
 	if err != nil {
 		return Line{}, apperrors.WrapInternal("create budget line", err)
 	}
-	line.Categories = nonNilCategories(line.Categories)
-	return line, nil
+	s.linesChanged(ctx, line.BudgetID, line.ID)
+	return normalizeLine(line), nil
 }
 
 func (s *Service) UpdateLine(ctx context.Context, input UpdateLineInput) (Line, error) {
 	if input.LineID == 0 {
 		return Line{}, apperrors.Validation("budget line id is required")
 	}
+	if input.ExpectedVersion != nil && *input.ExpectedVersion <= 0 {
+		return Line{}, apperrors.Validation("expected version must be positive")
+	}
 	if input.Name != nil {
 		name, err := lineName(*input.Name)
 		if err != nil {
@@ -92,19 +159,93 @@ func (s *Service) UpdateLine(ctx context.Context, input UpdateLineInput) (Line,
 		}
 		input.AllocationAmount = &amount
 	}
+	if input.AlertThresholds != nil {
+		thresholds, err := alertThresholds(*input.AlertThresholds)
+		if err != nil {
+			return Line{}, err
+		}
+		input.AlertThresholds = &thresholds
+	}
+	if input.MemberAllocations != nil {
+		members, err := memberAllocations(*input.MemberAllocations, input.AllocationAmount)
+		if err != nil {
+			return Line{}, err
+		}
+		input.MemberAllocations = &members
+	}
 	line, err := s.repo.UpdateLineWithCategories(ctx, input)
 	if err != nil {
 		return Line{}, apperrors.WrapInternal("update budget line", err)
 	}
-	line.Categories = nonNilCategories(line.Categories)
-	return line, nil
+	s.linesChanged(ctx, line.BudgetID, line.ID)
+	return normalizeLine(line), nil
 }
 
-func (s *Service) DeleteLine(ctx context.Context, id int64) error {
+func (s *Service) DeleteLine(ctx context.Context, id int64, expectedVersion *int64) error {
 	if id == 0 {
 		return apperrors.Validation("budget line id is required")
 	}
-	return apperrors.WrapInternal("delete budget line", s.repo.DeleteLine(ctx, id))
+	if expectedVersion != nil && *expectedVersion <= 0 {
+		return apperrors.Validation("expected version must be positive")
+	}
+	budgetID, err := s.repo.DeleteLine(ctx, id, expectedVersion)
+	if err != nil {
+		return apperrors.WrapInternal("delete budget line", err)
+	}
+	s.linesChanged(ctx, budgetID, id)
+	return nil
+}
+
+// linesChanged publishes a budget.line.changed event per line. The budget is
+// read again only to learn its household; if that fails the events go out
+// without one rather than not at all.
+func (s *Service) linesChanged(ctx context.Context, budgetID int64, lineIDs ...int64) {
+	if s.events == events.Discard || len(lineIDs) == 0 {
+		return
+	}
+	var householdID *int64
+	if budget, err := s.repo.FindByID(ctx, budgetID); err == nil {
+		householdID = budget.Owner.HouseholdID
+	}
+	for _, lineID := range lineIDs {
+		s.events.Publish(ctx, events.Event{Type: events.BudgetLineChanged, HouseholdID: householdID, BudgetID: budgetID, BudgetLineID: lineID})
+	}
+}
+
+// Reallocate moves allocation between two lines of the same budget and records
+// the move. The source line's allocation cannot drop below zero.
+func (s *Service) Reallocate(ctx context.Context, input ReallocateInput) (ReallocationResult, error) {
+	if input.BudgetID == 0 {
+		return ReallocationResult{}, apperrors.Validation("budget id is required")
+	}
+	if input.FromLineID == 0 || input.ToLineID == 0 {
+		return ReallocationResult{}, apperrors.Validation("from and to line ids are required")
+	}
+	if input.FromLineID == input.ToLineID {
+		return ReallocationResult{}, apperrors.Validation("from and to lines must differ")
+	}
+	if input.UserID == 0 {
+		return ReallocationResult{}, apperrors.Validation("user id is required")
+	}
+	amount, err := cents(input.Amount)
+	if err != nil || amount <= 0 {
+		return ReallocationResult{}, apperrors.Validation("reallocation amount must be a positive number with at most two decimal places")
+	}
+	input.Amount = formatCents(amount)
+	if input.Reason != nil {
+		reason := strings.TrimSpace(*input.Reason)
+		input.Reason = &reason
+		if reason == "" {
+			input.Reason = nil
+		}
+	}
+	result, err := s.repo.Reallocate(ctx, input)
+	if err != nil {
+		return ReallocationResult{}, apperrors.WrapInternal("reallocate budget lines", err)
+	}
+	result.FromLine, result.ToLine = normalizeLine(result.FromLine), normalizeLine(result.ToLine)
+	s.linesChanged(ctx, input.BudgetID, result.FromLine.ID, result.ToLine.ID)
+	return result, nil
 }
 
 func (s *Service) Report(ctx context.Context, budgetID int64) (Report, error) {
@@ -115,7 +256,24 @@ func (s *Service) Report(ctx context.Context, budgetID int64) (Report, error) {
 	if err != nil {
 		return Report{}, apperrors.WrapInternal("load budget report snapshot", err)
 	}
+	report, err := liveReport(snapshot)
+	if err != nil {
+		return Report{}, err
+	}
+	if report.Goals, err = s.goalProgress(ctx, snapshot.Budget); err != nil {
+		return Report{}, err
+	}
+	if snapshot.Closing != nil {
+		if err := applyClosing(&report, *snapshot.Closing); err != nil {
+			return Report{}, apperrors.WrapInternal("calculate budget report", err)
+		}
+	}
+	return report, nil
+}
 
+// liveReport computes a budget's lines and totals from its current
+// transactions, without goals and ignoring any closing.
+func liveReport(snapshot ReportSnapshot) (Report, error) {
 	lines := make([]ReportLine, 0, len(snapshot.Lines))
 	totalAllocation, totalActual := int64(0), int64(0)
 	for _, row := range snapshot.Lines {
@@ -127,10 +285,13 @@ func (s *Service) Report(ctx context.Context, budgetID int64) (Report, error) {
 		if err != nil {
 			return Report{}, apperrors.WrapInternal("calculate budget report", fmt.Errorf("invalid actual amount: %w", err))
 		}
+		line, err := reportLine(row, allocation, actual)
+		if err != nil {
+			return Report{}, apperrors.WrapInternal("calculate budget report", err)
+		}
 		totalAllocation += allocation
 		totalActual += actual
-		row.Line.Categories = nonNilCategories(row.Line.Categories)
-		lines = append(lines, ReportLine{Line: row.Line, ActualAmount: formatCents(actual), RemainingAmount: formatCents(allocation - actual)})
+		lines = append(lines, line)
 	}
 	unmapped := nonNilUnmapped(snapshot.UnmappedTransactions)
 	unmappedTotal := int64(0)
@@ -148,24 +309,52 @@ func (s *Service) Report(ctx context.Context, budgetID int64) (Report, error) {
 	}
 	budget := snapshot.Budget
 	return Report{
-		Budget: BudgetSummary{ID: budget.ID, Owner: budget.Owner, PeriodStart: budget.PeriodStart, PeriodEnd: budget.PeriodEnd, SourceBudgetID: budget.SourceBudgetID},
+		Budget: BudgetSummary{ID: budget.ID, Owner: budget.Owner, PeriodKind: budget.PeriodKind, PeriodStart: budget.PeriodStart, PeriodEnd: budget.PeriodEnd, SourceBudgetID: budget.SourceBudgetID},
 		Lines:  lines, UnmappedTransactions: unmapped,
-		Totals: ReportTotals{AllocationAmount: formatCents(totalAllocation), ActualAmount: formatCents(totalActual), RemainingAmount: formatCents(totalAllocation - totalActual), UnmappedActualAmount: formatCents(unmappedTotal), UncategorizedActualAmount: formatCents(uncategorized)},
+		Totals:        ReportTotals{AllocationAmount: formatCents(totalAllocation), ActualAmount: formatCents(totalActual), RemainingAmount: formatCents(totalAllocation - totalActual), UnmappedActualAmount: formatCents(unmappedTotal), UncategorizedActualAmount: formatCents(uncategorized)},
+		Reallocations: normalizeReallocations(snapshot.Reallocations),
 	}, nil
 }
 
 func (s *Service) DetailedMonthlyReport(ctx context.Context, input MonthlyInput) (DetailedReport, error) {
-	start, end, err := validateMonthly(input)
+	period, err := validateMonthly(input)
 	if err != nil {
 		return DetailedReport{}, err
 	}
-	snapshot, err := s.repo.LoadDetailedMonthlySnapshot(ctx, input.Owner, start, end)
+	return s.detailedReport(ctx, input.Owner, period)
+}
+
+// DetailedReport is the detailed report of any budget by id, as exported to
+// PDF and XLSX.
+func (s *Service) DetailedReport(ctx context.Context, budgetID int64) (DetailedReport, error) {
+	if budgetID == 0 {
+		return DetailedReport{}, apperrors.Validation("budget id is required")
+	}
+	budget, err := s.repo.FindByID(ctx, budgetID)
+	if err != nil {
+		return DetailedReport{}, apperrors.WrapInternal("get budget", err)
+	}
+	return s.detailedReport(ctx, budget.Owner, Period{Kind: budget.PeriodKind, Start: budget.PeriodStart, End: budget.PeriodEnd})
+}
+
+func (s *Service) detailedReport(ctx context.Context, owner Owner, period Period) (DetailedReport, error) {
+	snapshot, err := s.repo.LoadDetailedSnapshot(ctx, owner, period)
 	if err != nil {
 		return DetailedReport{}, apperrors.WrapInternal("load detailed monthly budget report snapshot", err)
 	}
 
+	// A closed budget's forecasts project its frozen actuals, so it needs no
+	// history.
+	var history []HistoryTransaction
+	if snapshot.Closing == nil {
+		if history, err = s.repo.ListLineHistory(ctx, snapshot.Budget.ID, period.Start.AddDate(0, -forecastHistoryMonths, 0)); err != nil {
+			return DetailedReport{}, apperrors.WrapInternal("load budget line history", err)
+		}
+	}
+	forecasts := newForecaster(period, day(s.now().UTC()), history)
+
 	lines := make([]DetailedReportLine, 0, len(snapshot.Lines))
-	totalAllocation, totalActual := int64(0), int64(0)
+	totalAllocation, totalActual, totalProjected, totalPending := int64(0), int64(0), int64(0), int64(0)
 	for _, row := range snapshot.Lines {
 		allocation, err := cents(row.AllocationAmount)
 		if err != nil {
@@ -179,12 +368,31 @@ func (s *Service) DetailedMonthlyReport(ctx context.Context, input MonthlyInput)
 		if err != nil {
 			return DetailedReport{}, apperrors.WrapInternal("calculate detailed budget report", err)
 		}
+		var forecast Forecast
+		if snapshot.Closing == nil {
+			if forecast, err = forecasts.line(row.ID, allocation, actual, transactions); err != nil {
+				return DetailedReport{}, apperrors.WrapInternal("forecast detailed budget report", err)
+			}
+			projected, _ := cents(forecast.ProjectedAmount)
+			pending, _ := cents(forecast.RecurringPendingAmount)
+			totalProjected += projected
+			totalPending += pending
+		}
 		totalAllocation += allocation
 		totalActual += actual
-		row.Line.Categories = nonNilCategories(row.Line.Categories)
+		line, err := reportLine(row.ReportLineData, allocation, actual)
+		if err != nil {
+			return DetailedReport{}, apperrors.WrapInternal("calculate detailed budget report", err)
+		}
+		members, err := memberSpending(line.Line, allocation, transactions)
+		if err != nil {
+			return DetailedReport{}, apperrors.WrapInternal("calculate detailed budget report", err)
+		}
 		lines = append(lines, DetailedReportLine{
-			ReportLine:   ReportLine{Line: row.Line, ActualAmount: formatCents(actual), RemainingAmount: formatCents(allocation - actual)},
+			ReportLine:   line,
 			Transactions: transactions,
+			Forecast:     forecast,
+			Members:      members,
 		})
 	}
 	unmapped, err := normalizeDetailedTransactions(snapshot.UnmappedTransactions)
@@ -201,13 +409,89 @@ func (s *Service) DetailedMonthlyReport(ctx context.Context, input MonthlyInput)
 		return DetailedReport{}, apperrors.WrapInternal("calculate detailed budget report", fmt.Errorf("invalid uncategorized amount: %w", err))
 	}
 	budget := snapshot.Budget
-	return DetailedReport{
-		Budget: BudgetSummary{ID: budget.ID, Owner: budget.Owner, PeriodStart: budget.PeriodStart, PeriodEnd: budget.PeriodEnd, SourceBudgetID: budget.SourceBudgetID},
+	goals, err := s.goalProgress(ctx, budget)
+	if err != nil {
+		return DetailedReport{}, err
+	}
+	report := DetailedReport{
+		Budget: BudgetSummary{ID: budget.ID, Owner: budget.Owner, PeriodKind: budget.PeriodKind, PeriodStart: budget.PeriodStart, PeriodEnd: budget.PeriodEnd, SourceBudgetID: budget.SourceBudgetID},
 		Lines:  lines, UnmappedTransactions: unmapped,
-		Totals: ReportTotals{AllocationAmount: formatCents(totalAllocation), ActualAmount: formatCents(totalActual), RemainingAmount: formatCents(totalAllocation - totalActual), UnmappedActualAmount: formatCents(unmappedTotal), UncategorizedActualAmount: formatCents(uncategorized)},
+		Totals:   ReportTotals{AllocationAmount: formatCents(totalAllocation), ActualAmount: formatCents(totalActual), RemainingAmount: formatCents(totalAllocation - totalActual), UnmappedActualAmount: formatCents(unmappedTotal), UncategorizedActualAmount: formatCents(uncategorized)},
+		Forecast: newForecast(forecasts.asOf, totalAllocation, totalActual, totalProjected, totalPending),
+		Goals:    goals,
+	}
+	if snapshot.Closing != nil {
+		if err := applyDetailedClosing(&report, *snapshot.Closing); err != nil {
+			return DetailedReport{}, apperrors.WrapInternal("calculate detailed budget report", err)
+		}
+		if err := closedForecasts(&report, forecasts.asOf); err != nil {
+			return DetailedReport{}, apperrors.WrapInternal("forecast detailed budget report", err)
+		}
+	}
+	return report, nil
+}
+
+// closedForecasts projects a closed report's actuals as its period-end
+// spending, as forecasts of past periods do.
+func closedForecasts(report *DetailedReport, asOf time.Time) error {
+	for i, line := range report.Lines {
+		allocation, err := cents(line.AllocationAmount)
+		if err != nil {
+			return fmt.Errorf("invalid allocation amount for line %d: %w", line.ID, err)
+		}
+		actual, err := cents(line.ActualAmount)
+		if err != nil {
+			return fmt.Errorf("invalid actual amount for line %d: %w", line.ID, err)
+		}
+		report.Lines[i].Forecast = newForecast(asOf, allocation, actual, actual, 0)
+	}
+	allocation, _ := cents(report.Totals.AllocationAmount)
+	actual, _ := cents(report.Totals.ActualAmount)
+	report.Forecast = newForecast(asOf, allocation, actual, actual, 0)
+	return nil
+}
+
+func (s *Service) goalProgress(ctx context.Context, budget Budget) ([]GoalProgress, error) {
+	if s.goals == nil {
+		return []GoalProgress{}, nil
+	}
+	items, err := s.goals.GoalProgress(ctx, budget.Owner, Period{Kind: budget.PeriodKind, Start: budget.PeriodStart, End: budget.PeriodEnd})
+	if err != nil {
+		return nil, apperrors.WrapInternal("load savings goal progress", err)
+	}
+	if items == nil {
+		return []GoalProgress{}, nil
+	}
+	return items, nil
+}
+
+func reportLine(row ReportLineData, allocation, actual int64) (ReportLine, error) {
+	original := allocation
+	if row.OriginalAllocationAmount != "" {
+		value, err := cents(row.OriginalAllocationAmount)
+		if err != nil {
+			return ReportLine{}, fmt.Errorf("invalid original allocation amount: %w", err)
+		}
+		original = value
+	}
+	return ReportLine{
+		Line: normalizeLine(row.Line), ActualAmount: formatCents(actual), RemainingAmount: formatCents(allocation - actual),
+		OriginalAllocationAmount: formatCents(original), ReallocatedAmount: formatCents(allocation - original),
 	}, nil
 }
 
+func normalizeReallocations(items []Reallocation) []Reallocation {
+	if items == nil {
+		return []Reallocation{}
+	}
+	for i := range items {
+		if value, err := cents(items[i].Amount); err == nil {
+			items[i].Amount = formatCents(value)
+		}
+	}
+	return items
+}
+
 func normalizeDetailedTransactions(items []DetailedTransaction) ([]DetailedTransaction, error) {
 	if items == nil {
 		return []DetailedTransaction{}, nil
@@ -222,28 +506,39 @@ func normalizeDetailedTransactions(items []DetailedTransaction) ([]DetailedTrans
 	return items, nil
 }
 
-func validateMonthly(input MonthlyInput) (time.Time, time.Time, error) {
-	if (input.Owner.HouseholdID == nil) == (input.Owner.UserID == nil) {
-		return time.Time{}, time.Time{}, apperrors.Validation("exactly one budget owner is required")
+func validateMonthly(input MonthlyInput) (Period, error) {
+	if err := validateOwner(input.Owner); err != nil {
+		return Period{}, err
 	}
 	if input.Year < 1 {
-		return time.Time{}, time.Time{}, apperrors.Validation("year must be greater than 0")
+		return Period{}, apperrors.Validation("year must be greater than 0")
 	}
 	if input.Month < 1 || input.Month > 12 {
-		return time.Time{}, time.Time{}, apperrors.Validation("month must be between 1 and 12")
+		return Period{}, apperrors.Validation("month must be between 1 and 12")
 	}
 	start := time.Date(input.Year, time.Month(input.Month), 1, 0, 0, 0, 0, time.UTC)
-	return start, start.AddDate(0, 1, -1), nil
+	return Period{Kind: PeriodMonthly, Start: start, End: start.AddDate(0, 1, -1)}, nil
 }
 
 func normalizeBudget(budget Budget) Budget {
 	budget.Lines = nonNilLines(budget.Lines)
 	for i := range budget.Lines {
-		budget.Lines[i].Categories = nonNilCategories(budget.Lines[i].Categories)
+		budget.Lines[i] = normalizeLine(budget.Lines[i])
 	}
 	return budget
 }
 
+func normalizeLine(line Line) Line {
+	line.Categories = nonNilCategories(line.Categories)
+	if line.AlertThresholds == nil {
+		line.AlertThresholds = []int32{}
+	}
+	if line.MemberAllocations == nil {
+		line.MemberAllocations = []MemberAllocation{}
+	}
+	return line
+}
+
 func lineName(value string) (string, error) {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -252,6 +547,23 @@ func lineName(value string) (string, error) {
 	return value, nil
 }
 
+// alertThresholds validates alert percentages and returns them de-duplicated
+// in ascending order. Thresholds above 100 alert on overspend beyond the
+// allocation.
+func alertThresholds(values []int32) ([]int32, error) {
+	result := make([]int32, 0, len(values))
+	for _, value := range values {
+		if value < MinAlertThreshold || value > MaxAlertThreshold {
+			return nil, apperrors.Validation(fmt.Sprintf("alert thresholds must be between %d and %d percent", MinAlertThreshold, MaxAlertThreshold))
+		}
+		if !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
 func amountString(value string) (string, error) {
 	parsed, err := cents(value)
 	if err != nil || parsed < 0 {
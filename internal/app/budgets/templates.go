@@ -0,0 +1,345 @@
+package budgets
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+func (s *Service) CreateTemplate(ctx context.Context, input CreateTemplateInput) (Template, error) {
+	if err := validateOwner(input.Owner); err != nil {
+		return Template{}, err
+	}
+	name, err := templateName(input.Name)
+	if err != nil {
+		return Template{}, err
+	}
+	lines, err := templateLines(input.Lines)
+	if err != nil {
+		return Template{}, err
+	}
+	input.Name, input.Lines = name, lines
+	created, err := s.repo.CreateTemplate(ctx, input)
+	if err != nil {
+		return Template{}, apperrors.WrapInternal("create budget template", err)
+	}
+	return normalizeTemplate(created), nil
+}
+
+func (s *Service) GetTemplate(ctx context.Context, id int64) (Template, error) {
+	if id == 0 {
+		return Template{}, apperrors.Validation("budget template id is required")
+	}
+	template, err := s.repo.GetTemplate(ctx, id)
+	if err != nil {
+		return Template{}, apperrors.WrapInternal("get budget template", err)
+	}
+	return normalizeTemplate(template), nil
+}
+
+// ListTemplates returns templates ordered by name, optionally limited to one
+// owner.
+func (s *Service) ListTemplates(ctx context.Context, owner Owner) ([]Template, error) {
+	if owner.HouseholdID != nil && owner.UserID != nil {
+		return nil, apperrors.Validation("at most one budget template owner filter is allowed")
+	}
+	templates, err := s.repo.ListTemplates(ctx, owner)
+	if err != nil {
+		return nil, apperrors.WrapInternal("list budget templates", err)
+	}
+	result := make([]Template, 0, len(templates))
+	for _, template := range templates {
+		result = append(result, normalizeTemplate(template))
+	}
+	return result, nil
+}
+
+func (s *Service) UpdateTemplate(ctx context.Context, input UpdateTemplateInput) (Template, error) {
+	if input.ID == 0 {
+		return Template{}, apperrors.Validation("budget template id is required")
+	}
+	if input.Name == nil && input.Lines == nil {
+		return Template{}, apperrors.Validation("at least one budget template field is required")
+	}
+	if input.Name != nil {
+		name, err := templateName(*input.Name)
+		if err != nil {
+			return Template{}, err
+		}
+		input.Name = &name
+	}
+	if input.Lines != nil {
+		lines, err := templateLines(*input.Lines)
+		if err != nil {
+			return Template{}, err
+		}
+		input.Lines = &lines
+	}
+	updated, err := s.repo.UpdateTemplate(ctx, input)
+	if err != nil {
+		return Template{}, apperrors.WrapInternal("update budget template", err)
+	}
+	return normalizeTemplate(updated), nil
+}
+
+func (s *Service) DeleteTemplate(ctx context.Context, id int64) error {
+	if id == 0 {
+		return apperrors.Validation("budget template id is required")
+	}
+	return apperrors.WrapInternal("delete budget template", s.repo.DeleteTemplate(ctx, id))
+}
+
+// SaveTemplate saves a budget's lines as a new template. Allocations are saved
+// as originally planned, before any reallocations, and line names must be
+// unique because templates are applied by line name.
+func (s *Service) SaveTemplate(ctx context.Context, input SaveTemplateInput) (Template, error) {
+	if input.BudgetID == 0 {
+		return Template{}, apperrors.Validation("budget id is required")
+	}
+	name, err := templateName(input.Name)
+	if err != nil {
+		return Template{}, err
+	}
+	input.Name = name
+	budget, err := s.repo.FindByID(ctx, input.BudgetID)
+	if err != nil {
+		return Template{}, apperrors.WrapInternal("get budget", err)
+	}
+	names := make(map[string]struct{}, len(budget.Lines))
+	for _, line := range budget.Lines {
+		if _, exists := names[trendLineKey(line.Name)]; exists {
+			return Template{}, apperrors.Validation(fmt.Sprintf("budget line %q appears more than once; rename one before saving the budget as a template", line.Name))
+		}
+		names[trendLineKey(line.Name)] = struct{}{}
+	}
+	saved, err := s.repo.SaveTemplate(ctx, input)
+	if err != nil {
+		return Template{}, apperrors.WrapInternal("save budget as template", err)
+	}
+	return normalizeTemplate(saved), nil
+}
+
+// ApplyTemplate plans the line changes that make a budget follow a template of
+// the same owner and applies them unless DryRun is set.
+func (s *Service) ApplyTemplate(ctx context.Context, input ApplyTemplateInput) (ApplyTemplateResult, error) {
+	if input.BudgetID == 0 {
+		return ApplyTemplateResult{}, apperrors.Validation("budget id is required")
+	}
+	if (input.TemplateID == nil) == (input.TemplateName == nil) {
+		return ApplyTemplateResult{}, apperrors.Validation("exactly one of template id or template name is required")
+	}
+	if input.Mode == "" {
+		input.Mode = ApplyMerge
+	}
+	if input.Mode != ApplyMerge && input.Mode != ApplyReplace {
+		return ApplyTemplateResult{}, apperrors.Validation("apply mode must be merge or replace")
+	}
+	budget, err := s.repo.FindByID(ctx, input.BudgetID)
+	if err != nil {
+		return ApplyTemplateResult{}, apperrors.WrapInternal("get budget", err)
+	}
+	var template Template
+	if input.TemplateID != nil {
+		template, err = s.repo.GetTemplate(ctx, *input.TemplateID)
+	} else {
+		template, err = s.findTemplate(ctx, budget.Owner, *input.TemplateName)
+	}
+	if err != nil {
+		return ApplyTemplateResult{}, apperrors.WrapInternal("get budget template", err)
+	}
+	if !sameOwner(budget.Owner, template.Owner) {
+		return ApplyTemplateResult{}, apperrors.Validation("budget template belongs to a different owner than the budget")
+	}
+	changes, err := planTemplate(budget, template, input.Mode)
+	if err != nil {
+		return ApplyTemplateResult{}, apperrors.WrapInternal("plan budget template changes", err)
+	}
+	result := ApplyTemplateResult{Budget: normalizeBudget(budget), Template: normalizeTemplate(template), Mode: input.Mode, Changes: changes}
+	if input.DryRun || len(changes) == 0 {
+		return result, nil
+	}
+	applied, err := s.repo.ApplyLineChanges(ctx, budget.ID, changes)
+	if err != nil {
+		return ApplyTemplateResult{}, apperrors.WrapInternal("apply budget template", err)
+	}
+	result.Budget, result.Applied = normalizeBudget(applied), true
+	lineIDs := make([]int64, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		if change.Action == LineAdded {
+			index := slices.IndexFunc(result.Budget.Lines, func(line Line) bool { return line.SortOrder == change.After.SortOrder })
+			if index >= 0 {
+				change.After.ID = result.Budget.Lines[index].ID
+			}
+		}
+		if change.After != nil && change.After.ID != 0 {
+			lineIDs = append(lineIDs, change.After.ID)
+		} else if change.Before != nil {
+			lineIDs = append(lineIDs, change.Before.ID)
+		}
+	}
+	s.linesChanged(ctx, budget.ID, lineIDs...)
+	return result, nil
+}
+
+func (s *Service) findTemplate(ctx context.Context, owner Owner, name string) (Template, error) {
+	name, err := templateName(name)
+	if err != nil {
+		return Template{}, err
+	}
+	return s.repo.FindTemplateByName(ctx, owner, name)
+}
+
+// planTemplate matches budget lines to template lines by name, ignoring case
+// and extra spaces. Matched lines take the template allocation and categories,
+// unmatched template lines are appended after the existing lines in template
+// order, and a category the template maps is removed from any other line
+// holding it so no category is counted twice. In replace mode budget lines
+// missing from the template are removed instead.
+func planTemplate(budget Budget, template Template, mode ApplyMode) ([]LineChange, error) {
+	lines := slices.Clone(budget.Lines)
+	slices.SortStableFunc(lines, func(a, b Line) int { return cmp.Or(cmp.Compare(a.SortOrder, b.SortOrder), cmp.Compare(a.ID, b.ID)) })
+	byName := make(map[string]int, len(lines))
+	nextSortOrder := int32(0)
+	for i, line := range lines {
+		if _, exists := byName[trendLineKey(line.Name)]; !exists {
+			byName[trendLineKey(line.Name)] = i
+		}
+		nextSortOrder = max(nextSortOrder, line.SortOrder)
+	}
+	claimed := make(map[int64]struct{})
+	for _, line := range template.Lines {
+		for _, category := range line.Categories {
+			claimed[category.ID] = struct{}{}
+		}
+	}
+
+	changes := make([]LineChange, 0)
+	matched := make(map[int64]struct{}, len(template.Lines))
+	for _, source := range template.Lines {
+		index, exists := byName[trendLineKey(source.Name)]
+		if !exists {
+			nextSortOrder++
+			changes = append(changes, LineChange{Action: LineAdded, After: &Line{
+				BudgetID: budget.ID, Name: source.Name, AllocationAmount: source.AllocationAmount, SortOrder: nextSortOrder,
+				Categories: nonNilCategories(slices.Clone(source.Categories)), AlertThresholds: []int32{},
+			}})
+			continue
+		}
+		before := normalizeLine(lines[index])
+		matched[before.ID] = struct{}{}
+		after := before
+		after.AllocationAmount, after.Categories = source.AllocationAmount, nonNilCategories(slices.Clone(source.Categories))
+		changed, err := lineChanged(before, after)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			changes = append(changes, LineChange{Action: LineUpdated, Before: &before, After: &after})
+		}
+	}
+	for _, line := range lines {
+		if _, exists := matched[line.ID]; exists {
+			continue
+		}
+		before := normalizeLine(line)
+		if mode == ApplyReplace {
+			changes = append(changes, LineChange{Action: LineRemoved, Before: &before})
+			continue
+		}
+		kept := make([]Category, 0, len(before.Categories))
+		for _, category := range before.Categories {
+			if _, exists := claimed[category.ID]; !exists {
+				kept = append(kept, category)
+			}
+		}
+		if len(kept) != len(before.Categories) {
+			after := before
+			after.Categories = kept
+			changes = append(changes, LineChange{Action: LineUpdated, Before: &before, After: &after})
+		}
+	}
+	return changes, nil
+}
+
+func lineChanged(before, after Line) (bool, error) {
+	beforeAmount, err := cents(before.AllocationAmount)
+	if err != nil {
+		return false, fmt.Errorf("invalid allocation amount for line %d: %w", before.ID, err)
+	}
+	afterAmount, err := cents(after.AllocationAmount)
+	if err != nil {
+		return false, fmt.Errorf("invalid template allocation amount for line %q: %w", after.Name, err)
+	}
+	return beforeAmount != afterAmount || !slices.Equal(categoryIDs(before.Categories), categoryIDs(after.Categories)), nil
+}
+
+func categoryIDs(items []Category) []int64 {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func sameOwner(a, b Owner) bool {
+	equal := func(x, y *int64) bool { return (x == nil && y == nil) || (x != nil && y != nil && *x == *y) }
+	return equal(a.HouseholdID, b.HouseholdID) && equal(a.UserID, b.UserID)
+}
+
+func templateName(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", apperrors.Validation("budget template name is required")
+	}
+	return value, nil
+}
+
+// templateLines validates template lines and assigns missing sort orders. Line
+// names must be unique, ignoring case and extra spaces, because templates are
+// applied to budgets by line name.
+func templateLines(values []TemplateLineInput) ([]TemplateLineInput, error) {
+	result := make([]TemplateLineInput, 0, len(values))
+	names := make(map[string]struct{}, len(values))
+	orders := make(map[int32]struct{}, len(values))
+	previous := int32(0)
+	for _, value := range values {
+		name, err := lineName(value.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := names[trendLineKey(name)]; exists {
+			return nil, apperrors.Validation(fmt.Sprintf("template line %q appears more than once", name))
+		}
+		names[trendLineKey(name)] = struct{}{}
+		amount, err := amountString(value.AllocationAmount)
+		if err != nil {
+			return nil, err
+		}
+		order := previous + 1
+		if value.SortOrder != nil {
+			order = *value.SortOrder
+		}
+		if _, exists := orders[order]; exists {
+			return nil, apperrors.Validation(fmt.Sprintf("template sort order %d is used by more than one line", order))
+		}
+		orders[order], previous = struct{}{}, order
+		value.Name, value.AllocationAmount, value.SortOrder = name, amount, &order
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+func normalizeTemplate(template Template) Template {
+	if template.Lines == nil {
+		template.Lines = []TemplateLine{}
+	}
+	for i := range template.Lines {
+		template.Lines[i].Categories = nonNilCategories(template.Lines[i].Categories)
+	}
+	return template
+}
@@ -0,0 +1,247 @@
+package budgets
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+// Trends compares an owner's monthly budgets across a range of months, line by
+// line and category by category, with range and year-to-date totals.
+func (s *Service) Trends(ctx context.Context, input TrendInput) (TrendReport, error) {
+	if err := validateOwner(input.Owner); err != nil {
+		return TrendReport{}, err
+	}
+	if input.From.IsZero() || input.To.IsZero() {
+		return TrendReport{}, apperrors.Validation("from and to months are required")
+	}
+	from, to := startOfMonth(input.From), startOfMonth(input.To)
+	if to.Before(from) {
+		return TrendReport{}, apperrors.Validation("from month must not be after to month")
+	}
+	if monthIndex(to)-monthIndex(from) >= MaxTrendMonths {
+		return TrendReport{}, apperrors.Validation(fmt.Sprintf("trend range must not exceed %d months", MaxTrendMonths))
+	}
+	yearStart, loadStart := time.Date(to.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), from
+	if yearStart.Before(loadStart) {
+		loadStart = yearStart
+	}
+	snapshot, err := s.repo.LoadTrendSnapshot(ctx, input.Owner, Period{Kind: PeriodMonthly, Start: loadStart, End: to.AddDate(0, 1, -1)})
+	if err != nil {
+		return TrendReport{}, apperrors.WrapInternal("load budget trend snapshot", err)
+	}
+	report, err := buildTrends(snapshot, monthRange(from, to), monthRange(yearStart, to))
+	if err != nil {
+		return TrendReport{}, apperrors.WrapInternal("calculate budget trends", err)
+	}
+	report.Owner, report.From, report.To = input.Owner, from, to
+	return report, nil
+}
+
+// trendAccumulator collects the monthly amounts of one series, keyed by
+// monthIndex.
+type trendAccumulator struct {
+	name       string
+	category   *Category
+	latest     int
+	sortOrder  int32
+	allocation map[int]int64
+	actual     map[int]int64
+	budgeted   map[int]bool
+}
+
+func newTrendAccumulator() *trendAccumulator {
+	return &trendAccumulator{latest: -1, allocation: make(map[int]int64), actual: make(map[int]int64), budgeted: make(map[int]bool)}
+}
+
+func (a *trendAccumulator) series(months, yearToDate []int) TrendSeries {
+	result := TrendSeries{Name: a.name, Category: a.category, Months: make([]TrendPoint, 0, len(months))}
+	for _, month := range months {
+		allocation, actual := a.allocation[month], a.actual[month]
+		result.Months = append(result.Months, TrendPoint{
+			Month: monthFromIndex(month), Budgeted: a.budgeted[month],
+			AllocationAmount: formatCents(allocation), ActualAmount: formatCents(actual), RemainingAmount: formatCents(allocation - actual),
+		})
+	}
+	result.Totals = a.totals(months)
+	result.YearToDate = a.totals(yearToDate)
+	return result
+}
+
+func (a *trendAccumulator) totals(months []int) TrendTotals {
+	allocation, actual := int64(0), int64(0)
+	for _, month := range months {
+		allocation += a.allocation[month]
+		actual += a.actual[month]
+	}
+	count := max(int64(len(months)), 1)
+	return TrendTotals{
+		Months: len(months), AllocationAmount: formatCents(allocation), ActualAmount: formatCents(actual), RemainingAmount: formatCents(allocation - actual),
+		AverageAllocationAmount: formatCents(divRound(allocation, count)), AverageActualAmount: formatCents(divRound(actual, count)),
+	}
+}
+
+func (a *trendAccumulator) active(months []int) bool {
+	for _, month := range months {
+		if a.budgeted[month] || a.actual[month] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func buildTrends(snapshot TrendSnapshot, months, yearToDate []int) (TrendReport, error) {
+	total := newTrendAccumulator()
+	budgets := make(map[int]int64)
+	lines := make(map[string]*trendAccumulator)
+	categories := make(map[int64]*trendAccumulator)
+	for _, row := range snapshot.Lines {
+		allocation, err := cents(row.AllocationAmount)
+		if err != nil {
+			return TrendReport{}, fmt.Errorf("invalid allocation amount: %w", err)
+		}
+		actual, err := cents(row.ActualAmount)
+		if err != nil {
+			return TrendReport{}, fmt.Errorf("invalid actual amount: %w", err)
+		}
+		month := monthIndex(row.PeriodStart)
+		budgets[month] = row.BudgetID
+		total.allocation[month] += allocation
+		total.actual[month] += actual
+		key := trendLineKey(row.Name)
+		line := lines[key]
+		if line == nil {
+			line = newTrendAccumulator()
+			lines[key] = line
+		}
+		line.allocation[month] += allocation
+		line.actual[month] += actual
+		line.budgeted[month] = true
+		if month >= line.latest {
+			line.name, line.sortOrder, line.latest = strings.TrimSpace(row.Name), row.SortOrder, month
+		}
+		for i, share := range splitAllocation(allocation, len(row.Categories)) {
+			category := categoryAccumulator(categories, &row.Categories[i])
+			category.allocation[month] += share
+			category.budgeted[month] = true
+		}
+	}
+
+	for _, row := range snapshot.Categories {
+		actual, err := cents(row.ActualAmount)
+		if err != nil {
+			return TrendReport{}, fmt.Errorf("invalid category amount: %w", err)
+		}
+		categoryAccumulator(categories, row.Category).actual[monthIndex(row.Month)] += actual
+	}
+
+	report := TrendReport{
+		Months: make([]TrendMonth, 0, len(months)), Lines: []TrendSeries{}, Categories: []TrendSeries{},
+		Totals: total.totals(months), YearToDate: total.totals(yearToDate),
+	}
+	for _, month := range months {
+		allocation, actual := total.allocation[month], total.actual[month]
+		item := TrendMonth{Month: monthFromIndex(month), AllocationAmount: formatCents(allocation), ActualAmount: formatCents(actual), RemainingAmount: formatCents(allocation - actual)}
+		if budgetID, exists := budgets[month]; exists {
+			item.BudgetID = &budgetID
+		}
+		report.Months = append(report.Months, item)
+	}
+	lineSeries := activeSeries(lines, months)
+	slices.SortFunc(lineSeries, func(a, b *trendAccumulator) int {
+		return cmp.Or(cmp.Compare(a.sortOrder, b.sortOrder), cmp.Compare(a.name, b.name))
+	})
+	for _, line := range lineSeries {
+		report.Lines = append(report.Lines, line.series(months, yearToDate))
+	}
+	categorySeries := activeSeries(categories, months)
+	slices.SortFunc(categorySeries, func(a, b *trendAccumulator) int {
+		if (a.category == nil) != (b.category == nil) {
+			if a.category == nil {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(a.name, b.name)
+	})
+	for _, category := range categorySeries {
+		report.Categories = append(report.Categories, category.series(months, yearToDate))
+	}
+	return report, nil
+}
+
+// categoryAccumulator returns the series of a category, or of uncategorized
+// spending when category is nil, creating it on first use.
+func categoryAccumulator(categories map[int64]*trendAccumulator, category *Category) *trendAccumulator {
+	key := int64(0)
+	if category != nil {
+		key = category.ID
+	}
+	accumulator := categories[key]
+	if accumulator == nil {
+		accumulator = newTrendAccumulator()
+		accumulator.name, accumulator.category = "Uncategorized", category
+		if category != nil {
+			accumulator.name = category.Name
+		}
+		categories[key] = accumulator
+	}
+	return accumulator
+}
+
+// splitAllocation divides a line's allocation evenly among its categories. The
+// cents left over go one each to the first categories, so the shares add up to
+// the allocation.
+func splitAllocation(allocation int64, count int) []int64 {
+	shares := make([]int64, count)
+	if count == 0 {
+		return shares
+	}
+	share, rest := allocation/int64(count), allocation%int64(count)
+	for i := range shares {
+		shares[i] = share
+		switch {
+		case int64(i) < rest:
+			shares[i]++
+		case int64(i) < -rest:
+			shares[i]--
+		}
+	}
+	return shares
+}
+
+func activeSeries[K comparable](items map[K]*trendAccumulator, months []int) []*trendAccumulator {
+	result := make([]*trendAccumulator, 0, len(items))
+	for _, item := range items {
+		if item.active(months) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// trendLineKey matches lines across months by case-insensitive name.
+func trendLineKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func startOfMonth(value time.Time) time.Time {
+	return time.Date(value.Year(), value.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func monthFromIndex(index int) time.Time {
+	return time.Date(index/12, time.Month(index%12+1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func monthRange(from, to time.Time) []int {
+	result := make([]int, 0, monthIndex(to)-monthIndex(from)+1)
+	for month := monthIndex(from); month <= monthIndex(to); month++ {
+		result = append(result, month)
+	}
+	return result
+}
@@ -35,7 +35,7 @@ func (f *fakeRepository) Update(_ context.Context, code string, update Update) (
 	f.update = update
 	return Category{ID: 1, Code: code}, nil
 }
-func (*fakeRepository) Deactivate(_ context.Context, code string) (Category, error) {
+func (*fakeRepository) Deactivate(_ context.Context, code string, _ *int64) (Category, error) {
 	return Category{ID: 1, Code: code, IsActive: false}, nil
 }
 
@@ -60,7 +60,15 @@ func TestServiceCategoryLifecycleAndErrorContract(t *testing.T) {
 	if err != nil || items == nil || !repo.includeInactive {
 		t.Fatalf("List=%#v include=%v error=%v", items, repo.includeInactive, err)
 	}
-	if item, err := service.Deactivate(context.Background(), "food"); err != nil || item.IsActive {
+	if item, err := service.Deactivate(context.Background(), "food", nil); err != nil || item.IsActive {
 		t.Fatalf("Deactivate=%+v error=%v", item, err)
 	}
+	version := int64(2)
+	if _, err := service.Update(context.Background(), UpdateInput{Code: "food", Description: patch.Clear[string](), ExpectedVersion: &version}); err != nil || repo.update.ExpectedVersion != &version {
+		t.Fatalf("Update error=%v expected version=%v", err, repo.update.ExpectedVersion)
+	}
+	zero := int64(0)
+	if _, err := service.Deactivate(context.Background(), "food", &zero); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("Deactivate zero version error=%v", err)
+	}
 }
@@ -8,6 +8,8 @@ type Category struct {
 	Name        string
 	Description *string
 	IsActive    bool
+	// Version counts the writes to the category and is served as its ETag.
+	Version int64
 }
 
 type CreateInput struct {
@@ -20,9 +22,13 @@ type UpdateInput struct {
 	Code        string
 	Name        *string
 	Description patch.Field[string]
+	// ExpectedVersion, when set, makes the update fail with a precondition
+	// error unless the category is still at that version.
+	ExpectedVersion *int64
 }
 
 type Update struct {
-	Name        *string
-	Description patch.Field[string]
+	Name            *string
+	Description     patch.Field[string]
+	ExpectedVersion *int64
 }
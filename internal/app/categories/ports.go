@@ -3,7 +3,8 @@ package categories
 import "context"
 
 // Repository implementations translate missing rows and unique violations to
-// application not-found and conflict errors respectively.
+// application not-found and conflict errors respectively, and a write whose
+// expected version is stale to a precondition error.
 type Repository interface {
 	Create(context.Context, CreateInput) (Category, error)
 	List(context.Context, bool) ([]Category, error)
@@ -11,5 +12,5 @@ type Repository interface {
 	GetActiveByID(context.Context, int64) (Category, error)
 	GetActiveByCode(context.Context, string) (Category, error)
 	Update(context.Context, string, Update) (Category, error)
-	Deactivate(context.Context, string) (Category, error)
+	Deactivate(ctx context.Context, code string, expectedVersion *int64) (Category, error)
 }
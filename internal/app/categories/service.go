@@ -6,13 +6,24 @@ import (
 	"strings"
 
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/app/events"
 )
 
 var codePattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
 
-type Service struct{ repo Repository }
+type Service struct {
+	repo   Repository
+	events events.Publisher
+}
+
+func NewService(repo Repository) *Service { return &Service{repo: repo, events: events.Discard} }
 
-func NewService(repo Repository) *Service { return &Service{repo: repo} }
+// WithEvents announces every committed create, update and deactivation on
+// publisher.
+func (s *Service) WithEvents(publisher events.Publisher) *Service {
+	s.events = publisher
+	return s
+}
 
 func (s *Service) Create(ctx context.Context, input CreateInput) (Category, error) {
 	input.Name = strings.TrimSpace(input.Name)
@@ -28,7 +39,7 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (Category, erro
 	}
 	input.Code = &code
 	item, err := s.repo.Create(ctx, input)
-	return item, apperrors.WrapInternal("create category", err)
+	return s.changed(ctx, item, apperrors.WrapInternal("create category", err))
 }
 
 func (s *Service) List(ctx context.Context, includeInactive bool) ([]Category, error) {
@@ -89,17 +100,37 @@ func (s *Service) Update(ctx context.Context, input UpdateInput) (Category, erro
 		}
 		input.Name = &name
 	}
-	item, err := s.repo.Update(ctx, code, Update{Name: input.Name, Description: input.Description})
-	return item, apperrors.WrapInternal("update category", err)
+	if err := validateExpectedVersion(input.ExpectedVersion); err != nil {
+		return Category{}, err
+	}
+	item, err := s.repo.Update(ctx, code, Update{Name: input.Name, Description: input.Description, ExpectedVersion: input.ExpectedVersion})
+	return s.changed(ctx, item, apperrors.WrapInternal("update category", err))
 }
 
-func (s *Service) Deactivate(ctx context.Context, code string) (Category, error) {
+func (s *Service) Deactivate(ctx context.Context, code string, expectedVersion *int64) (Category, error) {
 	code, err := validateCode(code)
 	if err != nil {
 		return Category{}, err
 	}
-	item, repoErr := s.repo.Deactivate(ctx, code)
-	return item, apperrors.WrapInternal("deactivate category", repoErr)
+	if err := validateExpectedVersion(expectedVersion); err != nil {
+		return Category{}, err
+	}
+	item, repoErr := s.repo.Deactivate(ctx, code, expectedVersion)
+	return s.changed(ctx, item, apperrors.WrapInternal("deactivate category", repoErr))
+}
+
+func (s *Service) changed(ctx context.Context, item Category, err error) (Category, error) {
+	if err == nil {
+		s.events.Publish(ctx, events.Event{Type: events.CategoryChanged, CategoryCode: item.Code})
+	}
+	return item, err
+}
+
+func validateExpectedVersion(version *int64) error {
+	if version != nil && *version <= 0 {
+		return apperrors.Validation("expected version must be positive")
+	}
+	return nil
 }
 
 func validateCode(code string) (string, error) {
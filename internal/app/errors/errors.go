@@ -8,28 +8,46 @@ import (
 type Kind string
 
 const (
-	KindValidation Kind = "validation"
-	KindNotFound   Kind = "not_found"
-	KindConflict   Kind = "conflict"
-	KindInternal   Kind = "internal"
+	KindValidation    Kind = "validation"
+	KindNotFound      Kind = "not_found"
+	KindConflict      Kind = "conflict"
+	KindPrecondition  Kind = "precondition"
+	KindUnprocessable Kind = "unprocessable"
+	KindInternal      Kind = "internal"
 )
 
 type Code string
 
 const (
-	CodeValidation           Code = "validation_error"
-	CodeUserNotFound         Code = "user_not_found"
-	CodeUserConflict         Code = "user_conflict"
-	CodeHouseholdNotFound    Code = "household_not_found"
-	CodeHouseholdConflict    Code = "household_conflict"
-	CodeCategoryNotFound     Code = "category_not_found"
-	CodeCategoryConflict     Code = "category_conflict"
-	CodeTransactionNotFound  Code = "transaction_not_found"
-	CodeDuplicateTransaction Code = "duplicate_transaction"
-	CodeBudgetNotFound       Code = "budget_not_found"
-	CodeBudgetLineNotFound   Code = "budget_line_not_found"
-	CodeBudgetConflict       Code = "budget_conflict"
-	CodeInternal             Code = "internal_error"
+	CodeValidation              Code = "validation_error"
+	CodeUserNotFound            Code = "user_not_found"
+	CodeUserConflict            Code = "user_conflict"
+	CodeHouseholdNotFound       Code = "household_not_found"
+	CodeHouseholdConflict       Code = "household_conflict"
+	CodeCategoryNotFound        Code = "category_not_found"
+	CodeCategoryConflict        Code = "category_conflict"
+	CodeTransactionNotFound     Code = "transaction_not_found"
+	CodeDuplicateTransaction    Code = "duplicate_transaction"
+	CodeAttachmentNotFound      Code = "attachment_not_found"
+	CodeAttachmentConflict      Code = "attachment_conflict"
+	CodeSavedFilterNotFound     Code = "saved_filter_not_found"
+	CodeBudgetNotFound          Code = "budget_not_found"
+	CodeBudgetLineNotFound      Code = "budget_line_not_found"
+	CodeBudgetConflict          Code = "budget_conflict"
+	CodeBudgetCategoryOverlap   Code = "budget_category_overlap"
+	CodeBudgetClosed            Code = "budget_closed"
+	CodeBudgetTemplateNotFound  Code = "budget_template_not_found"
+	CodeBudgetTemplateConflict  Code = "budget_template_conflict"
+	CodeGoalNotFound            Code = "goal_not_found"
+	CodeGoalConflict            Code = "goal_conflict"
+	CodeWebhookNotFound         Code = "webhook_not_found"
+	CodeWebhookConflict         Code = "webhook_conflict"
+	CodeWebhookDeliveryNotFound Code = "webhook_delivery_not_found"
+	CodeVersionMismatch         Code = "version_mismatch"
+	CodeIdempotencyKeyNotFound  Code = "idempotency_key_not_found"
+	CodeIdempotencyKeyInUse     Code = "idempotency_key_in_use"
+	CodeIdempotencyKeyMismatch  Code = "idempotency_key_mismatch"
+	CodeInternal                Code = "internal_error"
 )
 
 type Error struct {
@@ -65,6 +83,12 @@ func NotFound(code Code, message string, cause error) error {
 func Conflict(code Code, message string, cause error) error {
 	return New(KindConflict, code, message, cause)
 }
+func PreconditionFailed(code Code, message string, cause error) error {
+	return New(KindPrecondition, code, message, cause)
+}
+func Unprocessable(code Code, message string, cause error) error {
+	return New(KindUnprocessable, code, message, cause)
+}
 func Internal(cause error) error {
 	return New(KindInternal, CodeInternal, "internal error", cause)
 }
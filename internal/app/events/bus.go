@@ -0,0 +1,131 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many undelivered events a subscriber may fall
+// behind before the bus drops it.
+const subscriberBuffer = 256
+
+// Bus is the in-process Publisher. It fans events out to subscribers and keeps
+// the most recent ones so a reconnecting subscriber can resume where it left
+// off. Event IDs start from the bus's creation time in microseconds, so they
+// keep increasing across restarts and an ID from an earlier process is
+// recognised as no longer buffered.
+type Bus struct {
+	mu          sync.Mutex
+	now         func() time.Time
+	lastID      int64
+	history     []Event
+	size        int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+func NewBus(historySize int) *Bus {
+	return newBus(historySize, time.Now)
+}
+
+func newBus(historySize int, now func() time.Time) *Bus {
+	return &Bus{now: now, lastID: now().UnixMicro(), size: max(historySize, 0), subscribers: map[*Subscription]struct{}{}}
+}
+
+func (b *Bus) Publish(_ context.Context, event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.lastID++
+	event.ID = b.lastID
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = b.now().UTC()
+	}
+	if b.size > 0 {
+		if len(b.history) == b.size {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, event)
+	}
+	for subscription := range b.subscribers {
+		select {
+		case subscription.events <- event:
+		default:
+			b.drop(subscription)
+		}
+	}
+}
+
+// Subscription receives the events published after it was opened.
+type Subscription struct {
+	// Replay holds the buffered events published after the ID passed to
+	// Subscribe, oldest first.
+	Replay []Event
+	// Missed reports that some events after that ID are no longer buffered.
+	// The subscriber must reload its state; Position is the ID to resume from
+	// afterwards.
+	Missed   bool
+	Position int64
+
+	bus    *Bus
+	events chan Event
+}
+
+// Subscribe starts delivering events. A lastID of zero starts from now;
+// otherwise the events after lastID are replayed first.
+func (b *Bus) Subscribe(lastID int64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subscription := &Subscription{Position: b.lastID, bus: b, events: make(chan Event, subscriberBuffer)}
+	if lastID != 0 && lastID != b.lastID {
+		oldest := b.lastID + 1
+		if len(b.history) > 0 {
+			oldest = b.history[0].ID
+		}
+		if lastID >= oldest-1 && lastID < b.lastID {
+			subscription.Replay = append([]Event(nil), b.history[lastID-oldest+1:]...)
+		} else {
+			subscription.Missed = true
+		}
+	}
+	if b.closed {
+		close(subscription.events)
+		return subscription
+	}
+	b.subscribers[subscription] = struct{}{}
+	return subscription
+}
+
+// Events delivers new events. It is closed when the subscription or the bus
+// closes, or when the subscriber falls too far behind; the subscriber can
+// then resubscribe from the last ID it handled.
+func (s *Subscription) Events() <-chan Event { return s.events }
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.drop(s)
+}
+
+// Close ends every subscription and ignores later events, letting open
+// streams finish before the server shuts down.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for subscription := range b.subscribers {
+		b.drop(subscription)
+	}
+}
+
+func (b *Bus) drop(subscription *Subscription) {
+	if _, exists := b.subscribers[subscription]; exists {
+		delete(b.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+var _ Publisher = (*Bus)(nil)
@@ -0,0 +1,51 @@
+// Package events carries notices of committed writes from the app services to
+// live subscribers such as the /v1/events stream.
+package events
+
+import (
+	"context"
+	"time"
+)
+
+type Type string
+
+const (
+	TransactionCreated  Type = "transaction.created"
+	TransactionUpdated  Type = "transaction.updated"
+	TransactionDeleted  Type = "transaction.deleted"
+	TransactionRestored Type = "transaction.restored"
+	BudgetLineChanged   Type = "budget.line.changed"
+	CategoryChanged     Type = "category.changed"
+)
+
+// Event names the record a write touched rather than carrying its new state;
+// subscribers fetch what they need. Only the fields of the event's subject are
+// set. HouseholdID is nil for records outside any household, such as
+// categories and personal budgets.
+type Event struct {
+	// ID is assigned by the Bus and increases with every event.
+	ID            int64
+	Type          Type
+	OccurredAt    time.Time
+	HouseholdID   *int64
+	TransactionID int64
+	BudgetID      int64
+	BudgetLineID  int64
+	CategoryCode  string
+	// PreviousHouseholdID is set when a transaction update moved the
+	// transaction out of that household, so its streams hear of the move.
+	PreviousHouseholdID *int64
+}
+
+// Publisher announces events. The write has already committed, so Publish
+// cannot fail it; implementations must not block on slow subscribers.
+type Publisher interface {
+	Publish(context.Context, Event)
+}
+
+// Discard is the Publisher of services built without one.
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(context.Context, Event) {}
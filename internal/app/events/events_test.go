@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBusReplaysBufferedEventsAfterTheLastID(t *testing.T) {
+	bus := newBus(3, func() time.Time { return time.UnixMicro(1000) })
+	for range 4 {
+		bus.Publish(context.Background(), Event{Type: TransactionCreated})
+	}
+	resumed := bus.Subscribe(1002)
+	defer resumed.Close()
+	if resumed.Missed || len(resumed.Replay) != 2 || resumed.Replay[0].ID != 1003 || resumed.Replay[1].ID != 1004 || resumed.Position != 1004 {
+		t.Fatalf("resumed=%+v", resumed)
+	}
+	for _, lastID := range []int64{1000, 999, 2000} {
+		if subscription := bus.Subscribe(lastID); !subscription.Missed || len(subscription.Replay) != 0 {
+			t.Fatalf("lastID=%d subscription=%+v", lastID, subscription)
+		}
+	}
+	if current := bus.Subscribe(1004); current.Missed || len(current.Replay) != 0 {
+		t.Fatalf("current=%+v", current)
+	}
+	bus.Publish(context.Background(), Event{Type: CategoryChanged, CategoryCode: "food"})
+	if event := <-resumed.Events(); event.ID != 1005 || event.CategoryCode != "food" || event.OccurredAt.IsZero() {
+		t.Fatalf("live event=%+v", event)
+	}
+}
+
+func TestBusDropsSubscribersThatFallBehind(t *testing.T) {
+	bus := NewBus(0)
+	slow, idle := bus.Subscribe(0), bus.Subscribe(0)
+	idle.Close()
+	for range subscriberBuffer + 1 {
+		bus.Publish(context.Background(), Event{Type: TransactionUpdated})
+	}
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Fatalf("received %d events before the drop, want %d", received, subscriberBuffer)
+	}
+	if _, open := <-idle.Events(); open {
+		t.Fatal("closed subscription still open")
+	}
+	bus.Close()
+	if _, open := <-bus.Subscribe(0).Events(); open {
+		t.Fatal("subscription to a closed bus is open")
+	}
+}
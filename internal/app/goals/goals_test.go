@@ -0,0 +1,170 @@
+package goals
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+type fakeRepository struct {
+	created     CreateInput
+	updated     UpdateInput
+	existing    Goal
+	getErr      error
+	deletedID   int64
+	totals      []GoalTotals
+	totalsQuery TotalsQuery
+}
+
+func (f *fakeRepository) Create(_ context.Context, input CreateInput) (Goal, error) {
+	f.created = input
+	return Goal{ID: 4}, nil
+}
+func (f *fakeRepository) Get(context.Context, int64) (Goal, error) { return f.existing, f.getErr }
+func (f *fakeRepository) Update(_ context.Context, input UpdateInput) (Goal, error) {
+	f.updated = input
+	return Goal{ID: input.ID}, nil
+}
+func (f *fakeRepository) Delete(_ context.Context, id int64) error {
+	f.deletedID = id
+	return nil
+}
+func (f *fakeRepository) ListTotals(_ context.Context, query TotalsQuery) ([]GoalTotals, error) {
+	f.totalsQuery = query
+	return f.totals, nil
+}
+
+func fixedService(repo *fakeRepository, now time.Time) *Service {
+	service := NewService(repo)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func utc(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestProgressSpreadsRemainingAmountOverMonthsLeft(t *testing.T) {
+	householdID := int64(3)
+	goal := Goal{ID: 4, Owner: Owner{HouseholdID: &householdID}, Name: "Car insurance", TargetAmount: "1200.00", StartDate: utc(2026, 1, 1), TargetDate: utc(2026, 12, 31)}
+	for name, test := range map[string]struct {
+		contributed, withdrawn string
+		asOf                   time.Time
+		months                 int
+		required, remaining    string
+		state                  State
+	}{
+		"on track":          {"450.00", "0", utc(2026, 4, 15), 9, "83.34", "750.00", StateOnTrack},
+		"behind":            {"100.00", "0", utc(2026, 4, 15), 9, "122.23", "1100.00", StateBehind},
+		"withdrawals count": {"500.00", "200.00", utc(2026, 4, 15), 9, "100.00", "900.00", StateOnTrack},
+		"achieved":          {"1250.00", "0", utc(2026, 10, 1), 3, "0.00", "0.00", StateAchieved},
+		"overdue":           {"1000.00", "0", utc(2027, 1, 2), 0, "200.00", "200.00", StateOverdue},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := &fakeRepository{totals: []GoalTotals{{Goal: goal, ContributedAmount: test.contributed, WithdrawnAmount: test.withdrawn, PeriodNetAmount: "50"}}}
+			progress, err := fixedService(repo, test.asOf).Get(context.Background(), 4, time.Time{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if progress.MonthsRemaining != test.months || progress.RequiredMonthlyAmount != test.required || progress.RemainingAmount != test.remaining || progress.State != test.state || progress.PeriodNetAmount != "50.00" || progress.Categories == nil {
+				t.Fatalf("progress=%+v", progress)
+			}
+			if repo.totalsQuery.AsOf != utc(test.asOf.Year(), test.asOf.Month(), test.asOf.Day()) || repo.totalsQuery.PeriodStart != utc(test.asOf.Year(), test.asOf.Month(), 1) {
+				t.Fatalf("totals query=%+v", repo.totalsQuery)
+			}
+		})
+	}
+}
+
+func TestCreateNormalizesAndDefaultsStartToCurrentMonth(t *testing.T) {
+	userID := int64(2)
+	repo := &fakeRepository{totals: []GoalTotals{{Goal: Goal{ID: 4, TargetAmount: "900.00", StartDate: utc(2026, 10, 1), TargetDate: utc(2027, 6, 30)}, ContributedAmount: "0", WithdrawnAmount: "0", PeriodNetAmount: "0"}}}
+	progress, err := fixedService(repo, time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)).Create(context.Background(), CreateInput{
+		Owner: Owner{UserID: &userID}, Name: " Holidays ", TargetAmount: "900", TargetDate: time.Date(2027, 6, 30, 22, 0, 0, 0, time.UTC), CategoryCodes: []string{"holiday-fund"},
+	})
+	if err != nil || progress.ID != 4 || progress.RequiredMonthlyAmount != "100.00" {
+		t.Fatalf("progress=%+v error=%v", progress, err)
+	}
+	if repo.created.Name != "Holidays" || repo.created.TargetAmount != "900.00" || *repo.created.StartDate != utc(2026, 10, 1) || repo.created.TargetDate != utc(2027, 6, 30) {
+		t.Fatalf("create input=%+v", repo.created)
+	}
+}
+
+func TestGoalValidation(t *testing.T) {
+	userID, householdID := int64(2), int64(3)
+	owner := Owner{UserID: &userID}
+	target := utc(2027, 1, 1)
+	late := utc(2027, 2, 1)
+	for name, input := range map[string]CreateInput{
+		"missing owner":          {Name: "Trip", TargetAmount: "10", TargetDate: target, CategoryIDs: []int64{1}},
+		"two owners":             {Owner: Owner{UserID: &userID, HouseholdID: &householdID}, Name: "Trip", TargetAmount: "10", TargetDate: target, CategoryIDs: []int64{1}},
+		"blank name":             {Owner: owner, Name: " ", TargetAmount: "10", TargetDate: target, CategoryIDs: []int64{1}},
+		"zero amount":            {Owner: owner, Name: "Trip", TargetAmount: "0", TargetDate: target, CategoryIDs: []int64{1}},
+		"missing target date":    {Owner: owner, Name: "Trip", TargetAmount: "10", CategoryIDs: []int64{1}},
+		"target before start":    {Owner: owner, Name: "Trip", TargetAmount: "10", StartDate: &late, TargetDate: target, CategoryIDs: []int64{1}},
+		"no linked categories":   {Owner: owner, Name: "Trip", TargetAmount: "10", TargetDate: target},
+		"too precise target amt": {Owner: owner, Name: "Trip", TargetAmount: "10.001", TargetDate: target, CategoryIDs: []int64{1}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := fixedService(&fakeRepository{}, utc(2026, 10, 1)).Create(context.Background(), input); !apperrors.IsKind(err, apperrors.KindValidation) {
+				t.Fatalf("error=%v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateValidatesMergedDatesAndCategoryReplacement(t *testing.T) {
+	repo := &fakeRepository{existing: Goal{ID: 4, StartDate: utc(2026, 1, 1), TargetDate: utc(2026, 12, 31)}}
+	service := fixedService(repo, utc(2026, 10, 1))
+	before := utc(2025, 12, 1)
+	if _, err := service.Update(context.Background(), UpdateInput{ID: 4, TargetDate: &before}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("date error=%v", err)
+	}
+	empty := []string{}
+	if _, err := service.Update(context.Background(), UpdateInput{ID: 4, CategoryCodes: &empty}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("category error=%v", err)
+	}
+	repo.getErr = apperrors.NotFound(apperrors.CodeGoalNotFound, "savings goal not found", nil)
+	name := "Trip"
+	if _, err := service.Update(context.Background(), UpdateInput{ID: 4, Name: &name}); apperrors.CodeOf(err) != apperrors.CodeGoalNotFound {
+		t.Fatalf("missing goal error=%v", err)
+	}
+	repo.getErr = nil
+	if _, err := service.Update(context.Background(), UpdateInput{ID: 4, Name: &name}); !apperrors.IsKind(err, apperrors.KindNotFound) || *repo.updated.Name != "Trip" {
+		t.Fatalf("reload after update error=%v input=%+v", err, repo.updated)
+	}
+}
+
+func TestForPeriodKeepsOverlappingGoalsAndMeasuresAtPeriodEnd(t *testing.T) {
+	userID := int64(2)
+	repo := &fakeRepository{totals: []GoalTotals{
+		{Goal: Goal{ID: 1, TargetAmount: "100", StartDate: utc(2026, 1, 1), TargetDate: utc(2026, 12, 31)}, ContributedAmount: "10", WithdrawnAmount: "0", PeriodNetAmount: "10"},
+		{Goal: Goal{ID: 2, TargetAmount: "100", StartDate: utc(2026, 11, 1), TargetDate: utc(2026, 12, 31)}, ContributedAmount: "0", WithdrawnAmount: "0", PeriodNetAmount: "0"},
+		{Goal: Goal{ID: 3, TargetAmount: "100", StartDate: utc(2025, 1, 1), TargetDate: utc(2025, 12, 31)}, ContributedAmount: "0", WithdrawnAmount: "0", PeriodNetAmount: "0"},
+	}}
+	items, err := NewService(repo).ForPeriod(context.Background(), Owner{UserID: &userID}, utc(2026, 10, 1), utc(2026, 10, 31))
+	if err != nil || len(items) != 1 || items[0].ID != 1 || items[0].MonthsRemaining != 3 {
+		t.Fatalf("items=%+v error=%v", items, err)
+	}
+	want := TotalsQuery{Owner: Owner{UserID: &userID}, PeriodStart: utc(2026, 10, 1), AsOf: utc(2026, 10, 31)}
+	if !reflect.DeepEqual(repo.totalsQuery, want) {
+		t.Fatalf("query=%+v want=%+v", repo.totalsQuery, want)
+	}
+}
+
+func TestGetMissingAndDelete(t *testing.T) {
+	repo := &fakeRepository{}
+	service := NewService(repo)
+	if _, err := service.Get(context.Background(), 9, time.Time{}); apperrors.CodeOf(err) != apperrors.CodeGoalNotFound {
+		t.Fatalf("get error=%v", err)
+	}
+	if err := service.Delete(context.Background(), 9); err != nil || repo.deletedID != 9 {
+		t.Fatalf("deleted=%d error=%v", repo.deletedID, err)
+	}
+	if err := service.Delete(context.Background(), 0); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("delete validation=%v", err)
+	}
+}
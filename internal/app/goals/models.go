@@ -0,0 +1,91 @@
+package goals
+
+import "time"
+
+// Owner mirrors budget ownership: exactly one of HouseholdID or UserID is set.
+type Owner struct {
+	HouseholdID *int64
+	UserID      *int64
+}
+
+type Category struct {
+	ID   int64
+	Code string
+	Name string
+}
+
+// Goal is a savings target funded by transactions in its linked categories.
+// Positive linked amounts are contributions; negative amounts are withdrawals.
+type Goal struct {
+	ID           int64
+	Owner        Owner
+	Name         string
+	TargetAmount string
+	StartDate    time.Time
+	TargetDate   time.Time
+	Categories   []Category
+}
+
+type CreateInput struct {
+	Owner         Owner
+	Name          string
+	TargetAmount  string
+	StartDate     *time.Time
+	TargetDate    time.Time
+	CategoryIDs   []int64
+	CategoryCodes []string
+}
+
+type UpdateInput struct {
+	ID            int64
+	Name          *string
+	TargetAmount  *string
+	StartDate     *time.Time
+	TargetDate    *time.Time
+	CategoryIDs   *[]int64
+	CategoryCodes *[]string
+}
+
+type ListFilter struct {
+	Owner Owner
+	AsOf  time.Time
+}
+
+// TotalsQuery selects goals and the window their linked transactions are
+// summed over. Totals run from each goal's start date through AsOf, and the
+// period net amount only counts transactions on or after PeriodStart.
+type TotalsQuery struct {
+	ID          *int64
+	Owner       Owner
+	PeriodStart time.Time
+	AsOf        time.Time
+}
+
+type GoalTotals struct {
+	Goal
+	ContributedAmount string
+	WithdrawnAmount   string
+	PeriodNetAmount   string
+}
+
+type State string
+
+const (
+	StateAchieved State = "achieved"
+	StateOnTrack  State = "on_track"
+	StateBehind   State = "behind"
+	StateOverdue  State = "overdue"
+)
+
+type Progress struct {
+	Goal
+	AsOf                  time.Time
+	ContributedAmount     string
+	WithdrawnAmount       string
+	BalanceAmount         string
+	RemainingAmount       string
+	PeriodNetAmount       string
+	MonthsRemaining       int
+	RequiredMonthlyAmount string
+	State                 State
+}
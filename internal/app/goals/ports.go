@@ -0,0 +1,13 @@
+package goals
+
+import "context"
+
+// Repository owns goal persistence and linked-category mechanics. Totals are
+// summed in the database using the same owner scope as budget reports.
+type Repository interface {
+	Create(context.Context, CreateInput) (Goal, error)
+	Get(context.Context, int64) (Goal, error)
+	Update(context.Context, UpdateInput) (Goal, error)
+	Delete(context.Context, int64) error
+	ListTotals(context.Context, TotalsQuery) ([]GoalTotals, error)
+}
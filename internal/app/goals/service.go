@@ -0,0 +1,290 @@
+package goals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+type Service struct {
+	repo Repository
+	now  func() time.Time
+}
+
+func NewService(repo Repository) *Service { return &Service{repo: repo, now: time.Now} }
+
+func (s *Service) Create(ctx context.Context, input CreateInput) (Progress, error) {
+	if err := validateOwner(input.Owner); err != nil {
+		return Progress{}, err
+	}
+	name, err := goalName(input.Name)
+	if err != nil {
+		return Progress{}, err
+	}
+	amount, err := targetAmount(input.TargetAmount)
+	if err != nil {
+		return Progress{}, err
+	}
+	if input.TargetDate.IsZero() {
+		return Progress{}, apperrors.Validation("target date is required")
+	}
+	start := s.today()
+	start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if input.StartDate != nil {
+		start = day(*input.StartDate)
+	}
+	target := day(input.TargetDate)
+	if target.Before(start) {
+		return Progress{}, apperrors.Validation("target date must not be before start date")
+	}
+	if len(input.CategoryIDs) == 0 && len(input.CategoryCodes) == 0 {
+		return Progress{}, apperrors.Validation("at least one linked category is required")
+	}
+	input.Name, input.TargetAmount, input.StartDate, input.TargetDate = name, amount, &start, target
+	created, err := s.repo.Create(ctx, input)
+	if err != nil {
+		return Progress{}, apperrors.WrapInternal("create savings goal", err)
+	}
+	return s.Get(ctx, created.ID, time.Time{})
+}
+
+// Get reports progress as of the given date, or today when asOf is zero.
+func (s *Service) Get(ctx context.Context, id int64, asOf time.Time) (Progress, error) {
+	if id == 0 {
+		return Progress{}, apperrors.Validation("goal id is required")
+	}
+	items, err := s.progress(ctx, TotalsQuery{ID: &id}, asOf)
+	if err != nil {
+		return Progress{}, apperrors.WrapInternal("get savings goal", err)
+	}
+	if len(items) == 0 {
+		return Progress{}, apperrors.NotFound(apperrors.CodeGoalNotFound, "savings goal not found", nil)
+	}
+	return items[0], nil
+}
+
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]Progress, error) {
+	if filter.Owner.HouseholdID != nil && filter.Owner.UserID != nil {
+		return nil, apperrors.Validation("at most one goal owner filter is allowed")
+	}
+	items, err := s.progress(ctx, TotalsQuery{Owner: filter.Owner}, filter.AsOf)
+	return items, apperrors.WrapInternal("list savings goals", err)
+}
+
+// ForPeriod returns the owner's goals whose saving window overlaps the period,
+// measured as of the period end with the period net amount limited to it.
+func (s *Service) ForPeriod(ctx context.Context, owner Owner, start, end time.Time) ([]Progress, error) {
+	if err := validateOwner(owner); err != nil {
+		return nil, err
+	}
+	start, end = day(start), day(end)
+	rows, err := s.repo.ListTotals(ctx, TotalsQuery{Owner: owner, PeriodStart: start, AsOf: end})
+	if err != nil {
+		return nil, apperrors.WrapInternal("list savings goals for period", err)
+	}
+	items := make([]Progress, 0, len(rows))
+	for _, row := range rows {
+		if row.StartDate.After(end) || row.TargetDate.Before(start) {
+			continue
+		}
+		item, err := calculate(row, end)
+		if err != nil {
+			return nil, apperrors.WrapInternal("calculate savings goal progress", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (s *Service) Update(ctx context.Context, input UpdateInput) (Progress, error) {
+	if input.ID == 0 {
+		return Progress{}, apperrors.Validation("goal id is required")
+	}
+	if input.Name == nil && input.TargetAmount == nil && input.StartDate == nil && input.TargetDate == nil && input.CategoryIDs == nil && input.CategoryCodes == nil {
+		return Progress{}, apperrors.Validation("at least one goal field is required")
+	}
+	existing, err := s.repo.Get(ctx, input.ID)
+	if err != nil {
+		return Progress{}, apperrors.WrapInternal("get savings goal", err)
+	}
+	if input.Name != nil {
+		name, err := goalName(*input.Name)
+		if err != nil {
+			return Progress{}, err
+		}
+		input.Name = &name
+	}
+	if input.TargetAmount != nil {
+		amount, err := targetAmount(*input.TargetAmount)
+		if err != nil {
+			return Progress{}, err
+		}
+		input.TargetAmount = &amount
+	}
+	start, target := existing.StartDate, existing.TargetDate
+	if input.StartDate != nil {
+		start = day(*input.StartDate)
+		input.StartDate = &start
+	}
+	if input.TargetDate != nil {
+		target = day(*input.TargetDate)
+		input.TargetDate = &target
+	}
+	if target.Before(start) {
+		return Progress{}, apperrors.Validation("target date must not be before start date")
+	}
+	if input.CategoryIDs != nil || input.CategoryCodes != nil {
+		if (input.CategoryIDs == nil || len(*input.CategoryIDs) == 0) && (input.CategoryCodes == nil || len(*input.CategoryCodes) == 0) {
+			return Progress{}, apperrors.Validation("at least one linked category is required")
+		}
+	}
+	if _, err := s.repo.Update(ctx, input); err != nil {
+		return Progress{}, apperrors.WrapInternal("update savings goal", err)
+	}
+	return s.Get(ctx, input.ID, time.Time{})
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	if id == 0 {
+		return apperrors.Validation("goal id is required")
+	}
+	return apperrors.WrapInternal("delete savings goal", s.repo.Delete(ctx, id))
+}
+
+func (s *Service) progress(ctx context.Context, query TotalsQuery, asOf time.Time) ([]Progress, error) {
+	if asOf.IsZero() {
+		asOf = s.today()
+	}
+	asOf = day(asOf)
+	query.AsOf = asOf
+	query.PeriodStart = time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, time.UTC)
+	rows, err := s.repo.ListTotals(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Progress, 0, len(rows))
+	for _, row := range rows {
+		item, err := calculate(row, asOf)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (s *Service) today() time.Time { return day(s.now().UTC()) }
+
+// calculate spreads the remaining amount evenly over the months left until the
+// target month, inclusive. A goal is on track when its balance covers the
+// straight-line share of the target for every month completed so far.
+func calculate(row GoalTotals, asOf time.Time) (Progress, error) {
+	target, err := cents(row.TargetAmount)
+	if err != nil {
+		return Progress{}, fmt.Errorf("invalid target amount: %w", err)
+	}
+	contributed, err := cents(row.ContributedAmount)
+	if err != nil {
+		return Progress{}, fmt.Errorf("invalid contributed amount: %w", err)
+	}
+	withdrawn, err := cents(row.WithdrawnAmount)
+	if err != nil {
+		return Progress{}, fmt.Errorf("invalid withdrawn amount: %w", err)
+	}
+	periodNet, err := cents(row.PeriodNetAmount)
+	if err != nil {
+		return Progress{}, fmt.Errorf("invalid period amount: %w", err)
+	}
+	balance := contributed - withdrawn
+	remaining := max(target-balance, 0)
+	monthsRemaining := 0
+	if !asOf.After(row.TargetDate) {
+		monthsRemaining = monthsBetween(asOf, row.TargetDate) + 1
+	}
+	required := remaining
+	if monthsRemaining > 0 {
+		required = (remaining + int64(monthsRemaining) - 1) / int64(monthsRemaining)
+	}
+	totalMonths := monthsBetween(row.StartDate, row.TargetDate) + 1
+	elapsed := min(max(monthsBetween(row.StartDate, asOf), 0), totalMonths)
+	state := StateOnTrack
+	switch {
+	case balance >= target:
+		state = StateAchieved
+	case asOf.After(row.TargetDate):
+		state = StateOverdue
+	case balance*int64(totalMonths) < target*int64(elapsed):
+		state = StateBehind
+	}
+	row.Goal.Categories = nonNilCategories(row.Goal.Categories)
+	return Progress{
+		Goal: row.Goal, AsOf: asOf,
+		ContributedAmount: formatCents(contributed), WithdrawnAmount: formatCents(withdrawn),
+		BalanceAmount: formatCents(balance), RemainingAmount: formatCents(remaining), PeriodNetAmount: formatCents(periodNet),
+		MonthsRemaining: monthsRemaining, RequiredMonthlyAmount: formatCents(required), State: state,
+	}, nil
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+func validateOwner(owner Owner) error {
+	if (owner.HouseholdID == nil) == (owner.UserID == nil) {
+		return apperrors.Validation("exactly one goal owner is required")
+	}
+	return nil
+}
+
+func goalName(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", apperrors.Validation("goal name is required")
+	}
+	return value, nil
+}
+
+func targetAmount(value string) (string, error) {
+	parsed, err := cents(value)
+	if err != nil || parsed <= 0 {
+		return "", apperrors.Validation("target amount must be a positive number with at most two decimal places")
+	}
+	return formatCents(parsed), nil
+}
+
+func day(value time.Time) time.Time {
+	return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func cents(value string) (int64, error) {
+	ratio, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return 0, errors.New("invalid decimal")
+	}
+	ratio.Mul(ratio, big.NewRat(100, 1))
+	if !ratio.IsInt() || !ratio.Num().IsInt64() {
+		return 0, errors.New("amount has more than two decimal places or is out of range")
+	}
+	return ratio.Num().Int64(), nil
+}
+
+func formatCents(value int64) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/100, value%100)
+}
+
+func nonNilCategories(items []Category) []Category {
+	if items == nil {
+		return []Category{}
+	}
+	return items
+}
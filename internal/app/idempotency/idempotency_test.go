@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+type storedRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+type fakeRepository struct{ records map[string]storedRecord }
+
+func (f *fakeRepository) Claim(_ context.Context, key, fingerprint string, now, expiresAt time.Time) (bool, error) {
+	if existing, ok := f.records[key]; ok && existing.expiresAt.After(now) {
+		return false, nil
+	}
+	f.records[key] = storedRecord{Record: Record{Key: key, Fingerprint: fingerprint}, expiresAt: expiresAt}
+	return true, nil
+}
+func (f *fakeRepository) Get(_ context.Context, key string, now time.Time) (Record, error) {
+	existing, ok := f.records[key]
+	if !ok || !existing.expiresAt.After(now) {
+		return Record{}, apperrors.NotFound(apperrors.CodeIdempotencyKeyNotFound, "idempotency key not found", nil)
+	}
+	return existing.Record, nil
+}
+func (f *fakeRepository) Complete(_ context.Context, key string, response Response) error {
+	existing := f.records[key]
+	existing.Response = &response
+	f.records[key] = existing
+	return nil
+}
+func (f *fakeRepository) Release(_ context.Context, key string) error {
+	if f.records[key].Response == nil {
+		delete(f.records, key)
+	}
+	return nil
+}
+func (f *fakeRepository) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	var count int64
+	for key, existing := range f.records {
+		if !existing.expiresAt.After(now) {
+			delete(f.records, key)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestBeginRunsOnceAndReplaysIdenticalRetries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeRepository{records: map[string]storedRecord{}}
+	service := NewService(repo, time.Hour)
+	service.now = func() time.Time { return now }
+
+	if replay, err := service.Begin(ctx, "key-1", "a"); replay != nil || err != nil {
+		t.Fatalf("first Begin=%v error=%v", replay, err)
+	}
+	if _, err := service.Begin(ctx, "key-1", "a"); apperrors.CodeOf(err) != apperrors.CodeIdempotencyKeyInUse {
+		t.Fatalf("in-flight Begin error=%v", err)
+	}
+	if _, err := service.Begin(ctx, "key-1", "b"); apperrors.CodeOf(err) != apperrors.CodeIdempotencyKeyMismatch || !apperrors.IsKind(err, apperrors.KindUnprocessable) {
+		t.Fatalf("mismatched Begin error=%v", err)
+	}
+	if err := service.Complete(ctx, "key-1", Response{StatusCode: 201, Body: []byte(`{"id":1}`)}); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := service.Begin(ctx, "key-1", "a")
+	if err != nil || replay == nil || replay.StatusCode != 201 || string(replay.Body) != `{"id":1}` {
+		t.Fatalf("replay=%+v error=%v", replay, err)
+	}
+
+	now = now.Add(time.Hour)
+	if replay, err := service.Begin(ctx, "key-1", "b"); replay != nil || err != nil {
+		t.Fatalf("expired key Begin=%v error=%v", replay, err)
+	}
+}
+
+func TestReleaseLetsARetryRunAgain(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeRepository{records: map[string]storedRecord{}}
+	service := NewService(repo, 0)
+	if service.ttl != DefaultTTL {
+		t.Fatalf("ttl=%s", service.ttl)
+	}
+	if _, err := service.Begin(ctx, "key-2", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Release(ctx, "key-2"); err != nil {
+		t.Fatal(err)
+	}
+	if replay, err := service.Begin(ctx, "key-2", "a"); replay != nil || err != nil {
+		t.Fatalf("Begin after release=%v error=%v", replay, err)
+	}
+}
+
+func TestPurgeExpiredDeletesOnlyExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeRepository{records: map[string]storedRecord{}}
+	service := NewService(repo, time.Hour)
+	service.now = func() time.Time { return now }
+	_, _ = service.Begin(ctx, "old", "a")
+	now = now.Add(30 * time.Minute)
+	_, _ = service.Begin(ctx, "new", "a")
+	now = now.Add(45 * time.Minute)
+	if count, err := service.PurgeExpired(ctx); err != nil || count != 1 || len(repo.records) != 1 {
+		t.Fatalf("purged=%d error=%v records=%v", count, err, repo.records)
+	}
+}
+
+func TestBeginRejectsMalformedKeys(t *testing.T) {
+	service := NewService(&fakeRepository{records: map[string]storedRecord{}}, time.Hour)
+	for _, key := range []string{"", "has space", strings.Repeat("k", 256), "naïve"} {
+		if _, err := service.Begin(context.Background(), key, "a"); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Errorf("Begin(%q) error=%v", key, err)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package idempotency
+
+// Response is the part of a completed response that a retry replays.
+type Response struct {
+	StatusCode int
+	// Header holds the replayed response headers, such as Content-Type and
+	// ETag.
+	Header map[string]string
+	Body   []byte
+}
+
+// Record is a live idempotency key. Response is nil while the first request
+// holding the key is still running.
+type Record struct {
+	Key         string
+	Fingerprint string
+	Response    *Response
+}
@@ -0,0 +1,21 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Repository stores idempotency keys. Records expiring at or before now are
+// treated as absent.
+type Repository interface {
+	// Claim records key as in flight for a new request and reports false
+	// when a live record already holds it.
+	Claim(ctx context.Context, key, fingerprint string, now, expiresAt time.Time) (bool, error)
+	// Get returns the live record for key or a not-found error.
+	Get(ctx context.Context, key string, now time.Time) (Record, error)
+	// Complete stores the response of the request holding key.
+	Complete(ctx context.Context, key string, response Response) error
+	// Release forgets an in-flight key so that a retry runs again.
+	Release(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,97 @@
+// Package idempotency lets clients retry mutating requests safely. The first
+// request with a key runs and its response is stored; retries with the same
+// key and request get that response back instead of running again.
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+const (
+	// DefaultTTL is how long a key is remembered when no TTL is configured.
+	DefaultTTL = 24 * time.Hour
+
+	maxKeyLength = 255
+)
+
+type Service struct {
+	repo Repository
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewService remembers keys for ttl, or DefaultTTL when ttl is not positive.
+func NewService(repo Repository, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{repo: repo, ttl: ttl, now: time.Now}
+}
+
+// Begin claims key for a request identified by fingerprint. It returns nil
+// when the request should run, or the stored response when an identical
+// request already completed. Reusing a key for a different request is
+// unprocessable, and a key whose first request is still running conflicts.
+func (s *Service) Begin(ctx context.Context, key, fingerprint string) (*Response, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	claimed, err := s.repo.Claim(ctx, key, fingerprint, now, now.Add(s.ttl))
+	if err != nil {
+		return nil, apperrors.WrapInternal("claim idempotency key", err)
+	}
+	if claimed {
+		return nil, nil
+	}
+	record, err := s.repo.Get(ctx, key, now)
+	if apperrors.IsKind(err, apperrors.KindNotFound) {
+		// The holder released or expired the key between the two calls.
+		return nil, inUse()
+	}
+	if err != nil {
+		return nil, apperrors.WrapInternal("read idempotency key", err)
+	}
+	if record.Fingerprint != fingerprint {
+		return nil, apperrors.Unprocessable(apperrors.CodeIdempotencyKeyMismatch, "idempotency key was already used for a different request", nil)
+	}
+	if record.Response == nil {
+		return nil, inUse()
+	}
+	return record.Response, nil
+}
+
+// Complete stores the response that retries with key replay.
+func (s *Service) Complete(ctx context.Context, key string, response Response) error {
+	return apperrors.WrapInternal("complete idempotency key", s.repo.Complete(ctx, key, response))
+}
+
+// Release forgets key after a failed request so that a retry runs again.
+func (s *Service) Release(ctx context.Context, key string) error {
+	return apperrors.WrapInternal("release idempotency key", s.repo.Release(ctx, key))
+}
+
+// PurgeExpired deletes expired keys and reports how many it removed.
+func (s *Service) PurgeExpired(ctx context.Context) (int64, error) {
+	count, err := s.repo.DeleteExpired(ctx, s.now())
+	return count, apperrors.WrapInternal("purge idempotency keys", err)
+}
+
+func validateKey(key string) error {
+	if key == "" || len(key) > maxKeyLength {
+		return apperrors.Validation("Idempotency-Key must be 1 to 255 characters")
+	}
+	for _, char := range key {
+		if char < 0x21 || char > 0x7e {
+			return apperrors.Validation("Idempotency-Key must contain only visible ASCII characters")
+		}
+	}
+	return nil
+}
+
+func inUse() error {
+	return apperrors.Conflict(apperrors.CodeIdempotencyKeyInUse, "a request with this idempotency key is still in progress", nil)
+}
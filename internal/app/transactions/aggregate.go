@@ -0,0 +1,180 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+// AggregateDimension is a way of grouping transactions for Aggregate.
+type AggregateDimension string
+
+const (
+	DimensionCategory  AggregateDimension = "category"
+	DimensionAuthor    AggregateDimension = "author"
+	DimensionHousehold AggregateDimension = "household"
+	DimensionDay       AggregateDimension = "day"
+	DimensionWeek      AggregateDimension = "week"
+	DimensionMonth     AggregateDimension = "month"
+	DimensionTag       AggregateDimension = "tag"
+	// DimensionMerchant groups by description, ignoring case and surrounding
+	// spaces.
+	DimensionMerchant AggregateDimension = "merchant"
+)
+
+// AggregateMetric is a summary of the amounts in each group.
+type AggregateMetric string
+
+const (
+	MetricSum   AggregateMetric = "sum"
+	MetricCount AggregateMetric = "count"
+	MetricAvg   AggregateMetric = "avg"
+	MetricMin   AggregateMetric = "min"
+	MetricMax   AggregateMetric = "max"
+)
+
+var (
+	aggregateDimensions = []AggregateDimension{DimensionCategory, DimensionAuthor, DimensionHousehold, DimensionDay, DimensionWeek, DimensionMonth, DimensionTag, DimensionMerchant}
+	periodDimensions    = []AggregateDimension{DimensionDay, DimensionWeek, DimensionMonth}
+	aggregateMetrics    = []AggregateMetric{MetricSum, MetricCount, MetricAvg, MetricMin, MetricMax}
+	defaultMetrics      = []AggregateMetric{MetricSum, MetricCount}
+)
+
+// AggregateFilter groups the transactions a ListFilter with the same fields
+// would list by the By dimensions, in any combination but with at most one of
+// day, week and month. Without dimensions every transaction falls in one
+// group. Metrics default to sum and count.
+type AggregateFilter struct {
+	AuthorID       *int64
+	HouseholdID    *int64
+	FromDate       *time.Time
+	ToDate         *time.Time
+	Search         *string
+	Tags           []string
+	TagMatch       string
+	Query          string
+	Filter         string
+	Where          Expr
+	IncludeDeleted bool
+	OnlyDeleted    bool
+	By             []AggregateDimension
+	Metrics        []AggregateMetric
+}
+
+// AggregateResult lists the groups of an aggregation in order of their
+// dimension values, with the dimensions and metrics the groups carry.
+type AggregateResult struct {
+	By      []AggregateDimension
+	Metrics []AggregateMetric
+	Groups  []AggregateGroup
+}
+
+// AggregateGroup is one combination of dimension values and its metrics.
+// Dimensions outside AggregateResult.By are nil, and so are grouped ones that
+// transactions lack, such as the category of uncategorized ones. Period is the
+// UTC start of the day, the Monday of the week or the first of the month.
+// Amounts are decimal strings rounded to cents, and metrics that were not
+// requested are nil.
+type AggregateGroup struct {
+	Period        *time.Time
+	Category      *CategoryRef
+	AuthorID      *int64
+	AuthorName    *string
+	HouseholdID   *int64
+	HouseholdName *string
+	Tag           *string
+	Merchant      *string
+	Count         *int64
+	Sum           *string
+	Avg           *string
+	Min           *string
+	Max           *string
+}
+
+// Aggregate summarizes the transactions matching the filter per group, so
+// reports and charts need not fetch every transaction.
+func (s *Service) Aggregate(ctx context.Context, filter AggregateFilter) (AggregateResult, error) {
+	if filter.FromDate != nil && filter.ToDate != nil && filter.ToDate.Before(*filter.FromDate) {
+		return AggregateResult{}, apperrors.Validation("to date must not be before from date")
+	}
+	by, err := normalizeNames(filter.By, aggregateDimensions, "group by")
+	if err != nil {
+		return AggregateResult{}, err
+	}
+	periods := 0
+	for _, dimension := range by {
+		if slices.Contains(periodDimensions, dimension) {
+			periods++
+		}
+	}
+	if periods > 1 {
+		return AggregateResult{}, apperrors.Validation("group by at most one of day, week and month")
+	}
+	metrics, err := normalizeNames(filter.Metrics, aggregateMetrics, "metric")
+	if err != nil {
+		return AggregateResult{}, err
+	}
+	if len(metrics) == 0 {
+		metrics = defaultMetrics
+	}
+	filter.By, filter.Metrics = by, metrics
+	if filter.Search != nil && strings.TrimSpace(*filter.Search) == "" {
+		filter.Search = nil
+	}
+	if filter.Tags, filter.TagMatch, err = tagScope(filter.Tags, filter.TagMatch); err != nil {
+		return AggregateResult{}, err
+	}
+	if filter.Where, err = s.where(ctx, filter.Query, filter.Filter); err != nil {
+		return AggregateResult{}, err
+	}
+	groups, err := s.repo.Aggregate(ctx, filter)
+	if err != nil {
+		return AggregateResult{}, apperrors.WrapInternal("aggregate transactions", err)
+	}
+	if groups == nil {
+		groups = []AggregateGroup{}
+	}
+	for index := range groups {
+		group := &groups[index]
+		if !slices.Contains(metrics, MetricCount) {
+			group.Count = nil
+		}
+		if !slices.Contains(metrics, MetricSum) {
+			group.Sum = nil
+		}
+		if !slices.Contains(metrics, MetricAvg) {
+			group.Avg = nil
+		}
+		if !slices.Contains(metrics, MetricMin) {
+			group.Min = nil
+		}
+		if !slices.Contains(metrics, MetricMax) {
+			group.Max = nil
+		}
+	}
+	return AggregateResult{By: by, Metrics: metrics, Groups: groups}, nil
+}
+
+// normalizeNames lowercases and trims names and drops duplicates, keeping
+// the order they were given in. Every name must be one of known.
+func normalizeNames[T ~string](names []T, known []T, label string) ([]T, error) {
+	normalized := make([]T, 0, len(names))
+	for _, name := range names {
+		name = T(strings.ToLower(strings.TrimSpace(string(name))))
+		if !slices.Contains(known, name) {
+			choices := make([]string, 0, len(known))
+			for _, choice := range known {
+				choices = append(choices, string(choice))
+			}
+			return nil, apperrors.Validation(fmt.Sprintf("unknown %s %q; use %s", label, name, strings.Join(choices, ", ")))
+		}
+		if !slices.Contains(normalized, name) {
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,101 @@
+package transactions
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+// Cursor is the keyset position of the last transaction on a page: the value
+// of the sort field and the ID that breaks ties. Time holds the transaction
+// date or creation time, Amount the amount and Relevance the search rank,
+// depending on Sort.
+type Cursor struct {
+	Sort      string
+	SortOrder string
+	Time      time.Time
+	Amount    float32
+	Relevance float64
+	ID        int64
+}
+
+// cursorToken is the encoded form of a Cursor. Clients treat it as opaque.
+type cursorToken struct {
+	Sort      string     `json:"s"`
+	SortOrder string     `json:"o"`
+	Time      *time.Time `json:"t,omitempty"`
+	Amount    *float32   `json:"a,omitempty"`
+	Relevance *float64   `json:"r,omitempty"`
+	ID        int64      `json:"i"`
+}
+
+// cursorAfter positions a cursor on item in the given listing order.
+func cursorAfter(item Transaction, sort, sortOrder string) Cursor {
+	cursor := Cursor{Sort: sort, SortOrder: sortOrder, ID: item.ID}
+	switch sort {
+	case "transaction_date":
+		cursor.Time = item.TransactionDate
+	case "created_at":
+		cursor.Time = item.TransactionDate
+		if item.CreatedAt != nil {
+			cursor.Time = *item.CreatedAt
+		}
+	case "amount":
+		cursor.Amount = item.Amount
+	case SortRelevance:
+		if item.Match != nil {
+			cursor.Relevance = item.Match.Rank
+		}
+	}
+	return cursor
+}
+
+func (c Cursor) encode() string {
+	token := cursorToken{Sort: c.Sort, SortOrder: c.SortOrder, ID: c.ID}
+	switch c.Sort {
+	case "transaction_date", "created_at":
+		token.Time = &c.Time
+	case "amount":
+		token.Amount = &c.Amount
+	case SortRelevance:
+		token.Relevance = &c.Relevance
+	}
+	encoded, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeCursor(value string) (Cursor, error) {
+	invalid := apperrors.Validation("cursor is invalid")
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, invalid
+	}
+	var token cursorToken
+	if err := json.Unmarshal(decoded, &token); err != nil || token.ID < 1 {
+		return Cursor{}, invalid
+	}
+	cursor := Cursor{Sort: token.Sort, SortOrder: token.SortOrder, ID: token.ID}
+	switch token.Sort {
+	case "transaction_date", "created_at":
+		if token.Time == nil {
+			return Cursor{}, invalid
+		}
+		cursor.Time = *token.Time
+	case "amount":
+		if token.Amount == nil {
+			return Cursor{}, invalid
+		}
+		cursor.Amount = *token.Amount
+	case SortRelevance:
+		if token.Relevance == nil {
+			return Cursor{}, invalid
+		}
+		cursor.Relevance = *token.Relevance
+	case "id":
+	default:
+		return Cursor{}, invalid
+	}
+	return cursor, nil
+}
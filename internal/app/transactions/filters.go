@@ -0,0 +1,68 @@
+package transactions
+
+import (
+	"context"
+	"strings"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+// SaveFilter stores a filter expression under a name, replacing the query of
+// an existing filter with that name. Names follow the rules of tags.
+func (s *Service) SaveFilter(ctx context.Context, name, query string) (SavedFilter, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !tagPattern.MatchString(name) {
+		return SavedFilter{}, apperrors.Validation("filter name must be 1 to 64 letters, digits, hyphens or underscores starting with a letter or digit")
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return SavedFilter{}, apperrors.Validation("filter query is required")
+	}
+	if _, err := ParseQuery(query); err != nil {
+		return SavedFilter{}, err
+	}
+	saved, err := s.repo.SaveFilter(ctx, name, query)
+	return saved, apperrors.WrapInternal("save filter", err)
+}
+
+// ListSavedFilters returns every saved filter in name order.
+func (s *Service) ListSavedFilters(ctx context.Context) ([]SavedFilter, error) {
+	filters, err := s.repo.ListSavedFilters(ctx)
+	if filters == nil && err == nil {
+		filters = []SavedFilter{}
+	}
+	return filters, apperrors.WrapInternal("list saved filters", err)
+}
+
+func (s *Service) DeleteSavedFilter(ctx context.Context, name string) error {
+	return apperrors.WrapInternal("delete saved filter", s.repo.DeleteSavedFilter(ctx, strings.ToLower(strings.TrimSpace(name))))
+}
+
+// where parses a filter expression and looks up a saved filter for a listing
+// or report. Given both, transactions must match both; given neither, where
+// returns nil.
+func (s *Service) where(ctx context.Context, query, filter string) (Expr, error) {
+	var where Expr
+	if strings.TrimSpace(query) != "" {
+		expr, err := ParseQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		where = expr
+	}
+	if filter == "" {
+		return where, nil
+	}
+	saved, err := s.repo.GetSavedFilter(ctx, strings.ToLower(strings.TrimSpace(filter)))
+	if err != nil {
+		return nil, apperrors.WrapInternal("get saved filter", err)
+	}
+	expr, err := ParseQuery(saved.Query)
+	if err != nil {
+		return nil, err
+	}
+	if where == nil {
+		return expr, nil
+	}
+	return AndExpr{Left: expr, Right: where}, nil
+}
@@ -1,6 +1,7 @@
 package transactions
 
 import (
+	"slices"
 	"time"
 
 	"rdmm404/voltr-finance/internal/app/patch"
@@ -30,6 +31,14 @@ type Transaction struct {
 	DeletedAt       *time.Time
 	DeletedByUserID *int64
 	DeleteReason    *string
+	// Tags are the transaction's normalized labels in name order.
+	Tags []string
+	// ChangeSequence orders the transaction's latest write in the change feed.
+	ChangeSequence int64
+	// Version counts the writes to the transaction and is served as its ETag.
+	Version int64
+	// Match is set on transactions listed by a search.
+	Match *SearchMatch
 }
 
 type IdentitySelector struct {
@@ -49,6 +58,7 @@ type CreateInput struct {
 	CategoryCode    *string
 	HouseholdID     *int64
 	Author          IdentitySelector
+	Tags            []string
 }
 
 type NewTransaction struct {
@@ -60,6 +70,7 @@ type NewTransaction struct {
 	CategoryID      *int64
 	HouseholdID     *int64
 	AuthorID        int64
+	Tags            []string
 }
 
 type CategorySelector struct {
@@ -76,6 +87,15 @@ type UpdateInput struct {
 	Category        patch.Field[CategorySelector]
 	HouseholdID     patch.Field[int64]
 	Author          *IdentitySelector
+	// Tags, when set, replaces the transaction's tags. An empty slice removes
+	// them all.
+	Tags *[]string
+	// UpdatedByUserID is recorded in the history as the user who made the
+	// update. It is optional, and the entry has no actor when it is unset.
+	UpdatedByUserID *int64
+	// ExpectedVersion, when set, makes the update fail with a precondition
+	// error unless the stored transaction is still at that version.
+	ExpectedVersion *int64
 }
 
 type Mutation struct {
@@ -86,26 +106,111 @@ type Mutation struct {
 	CategoryID      patch.Field[int64]
 	HouseholdID     patch.Field[int64]
 	AuthorID        *int64
+	Tags            *[]string
+	// ActorID is the user recorded in the history for the update. Apply
+	// ignores it.
+	ActorID *int64
+	// ExpectedVersion is checked against the locked row before the update.
+	// Apply ignores it.
+	ExpectedVersion *int64
 }
 
+// ListFilter selects one page of transactions. Cursor is the NextCursor of
+// the previous page; the service decodes it into After for the repository.
+// Search matches words of the description and notes in any order, close
+// misspellings of them, or the exact text, and allows sorting by relevance.
+// Transactions match Tags when they carry any of them, or every one of them
+// when TagMatch is TagMatchAll. Query is a filter expression and Filter names
+// a saved one; the service parses them into Where.
 type ListFilter struct {
 	AuthorID       *int64
 	HouseholdID    *int64
 	FromDate       *time.Time
 	ToDate         *time.Time
 	Search         *string
+	Tags           []string
+	TagMatch       string
+	Query          string
+	Filter         string
+	Where          Expr
 	Sort           string
 	SortOrder      string
 	Limit          int32
 	Offset         int32
+	Cursor         string
+	After          *Cursor
 	IncludeDeleted bool
 	OnlyDeleted    bool
 }
 
+// Page is one page of a listing. NextCursor is empty on the last page.
+type Page struct {
+	Items      []Transaction
+	NextCursor string
+}
+
+// SortRelevance orders a search by how well transactions match it.
+const SortRelevance = "relevance"
+
+// TagMatch values of ListFilter.
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// TagTotalsFilter scopes the live transactions summed by tag. Query, Filter
+// and Where narrow them as they do for ListFilter.
+type TagTotalsFilter struct {
+	AuthorID    *int64
+	HouseholdID *int64
+	FromDate    *time.Time
+	ToDate      *time.Time
+	Query       string
+	Filter      string
+	Where       Expr
+}
+
+// SavedFilter is a filter expression stored under a name, so listings and
+// reports can reuse it with ListFilter.Filter.
+type SavedFilter struct {
+	Name      string
+	Query     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TagTotal is the number and sum of the transactions carrying one tag. Total
+// is a decimal string rounded to cents.
+type TagTotal struct {
+	Tag   string
+	Count int64
+	Total string
+}
+
+// ChangesFilter reads the change feed after Since, the NextToken of an
+// earlier ChangeSet. An empty Since starts from the first change.
+type ChangesFilter struct {
+	Since string
+	Limit int32
+}
+
+// ChangeSet lists transactions in the order they were last written, each at
+// most once. Soft-deleted transactions are tombstones with DeletedAt set.
+// NextToken resumes after the last item, and HasMore reports that more changes
+// are already waiting.
+type ChangeSet struct {
+	Items     []Transaction
+	NextToken string
+	HasMore   bool
+}
+
+// DeleteInput soft-deletes a transaction. A non-nil ExpectedVersion must match
+// the transaction's current version.
 type DeleteInput struct {
 	ID              int64
 	DeletedByUserID int64
 	Reason          *string
+	ExpectedVersion *int64
 }
 
 type RestoreInput struct {
@@ -113,6 +218,36 @@ type RestoreInput struct {
 	RestoredByUserID int64
 }
 
+// Action is what a history entry did to a transaction.
+type Action string
+
+const (
+	ActionCreated  Action = "created"
+	ActionUpdated  Action = "updated"
+	ActionDeleted  Action = "deleted"
+	ActionRestored Action = "restored"
+)
+
+// FieldChange is one field's value before and after a change. Field uses the
+// API's field name, and a nil value means the field was unset.
+type FieldChange struct {
+	Field  string
+	Before any
+	After  any
+}
+
+// HistoryEntry is one recorded change to a transaction. ActorUserID is nil
+// when the change did not name the user who made it.
+type HistoryEntry struct {
+	ID            int64
+	TransactionID int64
+	Action        Action
+	ActorUserID   *int64
+	ActorName     *string
+	ChangedAt     time.Time
+	Changes       []FieldChange
+}
+
 type Succeeded struct {
 	Index int
 	ID    int64
@@ -154,5 +289,70 @@ func (update Mutation) Apply(item Transaction) Transaction {
 	if update.AuthorID != nil {
 		item.AuthorID = *update.AuthorID
 	}
+	if update.Tags != nil {
+		item.Tags = *update.Tags
+	}
+	return item
+}
+
+// auditedFields are the fields Diff compares, in the order it reports them.
+var auditedFields = []string{
+	"amount", "transactionDate", "description", "notes", "categoryId", "householdId", "authorId",
+	"deletedAt", "deletedByUserId", "deleteReason", "tags",
+}
+
+// Diff lists the audited fields that differ between two versions of a
+// transaction. Diffing from the zero Transaction lists every field a new
+// transaction sets, with nil Before values.
+func Diff(before, after Transaction) []FieldChange {
+	old, current := auditValues(before), auditValues(after)
+	changes := []FieldChange{}
+	for index, field := range auditedFields {
+		if !sameValue(old[index], current[index]) {
+			changes = append(changes, FieldChange{Field: field, Before: old[index], After: current[index]})
+		}
+	}
+	return changes
+}
+
+func auditValues(item Transaction) []any {
+	var transactionDate, deletedAt, tags any
+	if !item.TransactionDate.IsZero() {
+		transactionDate = item.TransactionDate.UTC()
+	}
+	if item.DeletedAt != nil {
+		deletedAt = item.DeletedAt.UTC()
+	}
+	if len(item.Tags) > 0 {
+		tags = slices.Clone(item.Tags)
+	}
+	return []any{
+		nonZero(item.Amount), transactionDate, value(item.Description), value(item.Notes), value(item.CategoryID),
+		value(item.HouseholdID), nonZero(item.AuthorID), deletedAt, value(item.DeletedByUserID), value(item.DeleteReason), tags,
+	}
+}
+
+// sameValue compares audit values, which are comparable except for tag
+// lists.
+func sameValue(a, b any) bool {
+	if tags, ok := a.([]string); ok {
+		other, ok := b.([]string)
+		return ok && slices.Equal(tags, other)
+	}
+	return a == b
+}
+
+func value[T any](pointer *T) any {
+	if pointer == nil {
+		return nil
+	}
+	return *pointer
+}
+
+func nonZero[T comparable](item T) any {
+	var zero T
+	if item == zero {
+		return nil
+	}
 	return item
 }
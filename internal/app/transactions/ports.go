@@ -6,9 +6,25 @@ type Repository interface {
 	Create(context.Context, NewTransaction) (Transaction, error)
 	Get(context.Context, int64, bool) (Transaction, error)
 	List(context.Context, ListFilter) ([]Transaction, error)
+	// ListChanges returns up to limit transactions, including deleted ones,
+	// with a ChangeSequence above since, in sequence order.
+	ListChanges(ctx context.Context, since int64, limit int32) ([]Transaction, error)
 	Update(context.Context, int64, Mutation) (Transaction, error)
+	// TagTotals sums the live transactions matching the filter by tag, in tag
+	// order.
+	TagTotals(context.Context, TagTotalsFilter) ([]TagTotal, error)
+	// Aggregate groups the transactions matching the filter by its By
+	// dimensions and returns every metric of each group.
+	Aggregate(context.Context, AggregateFilter) ([]AggregateGroup, error)
+	// History returns the transaction's recorded changes, oldest first.
+	History(context.Context, int64) ([]HistoryEntry, error)
 	SoftDelete(context.Context, DeleteInput) (Transaction, error)
 	Restore(context.Context, RestoreInput) (Transaction, error)
+	// SaveFilter creates the named filter or replaces its query.
+	SaveFilter(ctx context.Context, name, query string) (SavedFilter, error)
+	GetSavedFilter(ctx context.Context, name string) (SavedFilter, error)
+	ListSavedFilters(context.Context) ([]SavedFilter, error)
+	DeleteSavedFilter(ctx context.Context, name string) error
 }
 
 type IdentityResolver interface {
@@ -18,3 +34,10 @@ type IdentityResolver interface {
 type CategoryResolver interface {
 	ResolveActiveCategoryID(context.Context, *int64, *string) (*int64, error)
 }
+
+// AlertEvaluator checks budget alert rules after a transaction is written.
+// The write has already committed, so implementations handle their own
+// failures instead of failing the request.
+type AlertEvaluator interface {
+	EvaluateTransaction(context.Context, Transaction)
+}
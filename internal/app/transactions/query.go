@@ -0,0 +1,353 @@
+package transactions
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+// A filter expression such as
+//
+//	amount > 50 and category in (groceries, restaurants) and not tag:reimbursable
+//
+// is parsed into an Expr tree. Conditions compare one field with literal
+// values, and and, or, not and parentheses combine them; and binds tighter
+// than or. Values are checked and converted here, so a repository only maps
+// fields to columns and passes the values as parameters.
+
+// Expr is a node of a parsed filter expression: AndExpr, OrExpr, NotExpr or
+// Condition.
+type Expr interface{ expr() }
+
+type AndExpr struct{ Left, Right Expr }
+
+type OrExpr struct{ Left, Right Expr }
+
+type NotExpr struct{ Operand Expr }
+
+// Condition compares Field with Values. Values hold one value, or several for
+// QueryIn, typed by field: float32 for amount, time.Time for date, int64 for
+// author and household, and string otherwise.
+type Condition struct {
+	Field  QueryField
+	Op     QueryOp
+	Values []any
+}
+
+func (AndExpr) expr()   {}
+func (OrExpr) expr()    {}
+func (NotExpr) expr()   {}
+func (Condition) expr() {}
+
+type QueryField string
+
+const (
+	FieldAmount      QueryField = "amount"
+	FieldDate        QueryField = "date"
+	FieldCategory    QueryField = "category"
+	FieldDescription QueryField = "description"
+	FieldNotes       QueryField = "notes"
+	FieldAuthor      QueryField = "author"
+	FieldHousehold   QueryField = "household"
+	FieldTag         QueryField = "tag"
+)
+
+type QueryOp string
+
+const (
+	QueryEqual        QueryOp = "="
+	QueryNotEqual     QueryOp = "!="
+	QueryLess         QueryOp = "<"
+	QueryLessEqual    QueryOp = "<="
+	QueryGreater      QueryOp = ">"
+	QueryGreaterEqual QueryOp = ">="
+	// QueryContains matches text containing the value, ignoring case.
+	QueryContains QueryOp = "~"
+	QueryIn       QueryOp = "in"
+)
+
+const (
+	maxQueryLength     = 1000
+	maxQueryConditions = 32
+)
+
+var (
+	comparisonOps = []QueryOp{QueryEqual, QueryNotEqual, QueryLess, QueryLessEqual, QueryGreater, QueryGreaterEqual, QueryIn}
+	equalityOps   = []QueryOp{QueryEqual, QueryNotEqual, QueryIn}
+	fieldOps      = map[QueryField][]QueryOp{
+		FieldAmount:      comparisonOps,
+		FieldDate:        {QueryLess, QueryLessEqual, QueryGreater, QueryGreaterEqual},
+		FieldCategory:    equalityOps,
+		FieldDescription: {QueryEqual, QueryNotEqual, QueryContains},
+		FieldNotes:       {QueryEqual, QueryNotEqual, QueryContains},
+		FieldAuthor:      equalityOps,
+		FieldHousehold:   equalityOps,
+		FieldTag:         equalityOps,
+	}
+)
+
+// ParseQuery parses a filter expression. Fields are amount, date, category
+// (by code), description, notes, author and household (by ID) and tag, and
+// tag:name is short for tag = name. Values containing spaces or other
+// punctuation, such as RFC3339 times, are quoted; a bare date is midnight
+// UTC.
+func ParseQuery(text string) (Expr, error) {
+	if len(text) > maxQueryLength {
+		return nil, apperrors.Validation(fmt.Sprintf("query must be at most %d characters", maxQueryLength))
+	}
+	tokens, err := lexQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	parser := &queryParser{tokens: tokens}
+	expr, err := parser.or()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != tokenEnd {
+		return nil, token.errorf("unexpected %s", token)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenWord
+	tokenString
+	tokenSymbol
+)
+
+type queryToken struct {
+	kind  tokenKind
+	text  string
+	start int
+}
+
+func (t queryToken) String() string {
+	switch t.kind {
+	case tokenEnd:
+		return "end of query"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func (t queryToken) errorf(format string, args ...any) error {
+	return apperrors.Validation(fmt.Sprintf("query: %s at position %d", fmt.Sprintf(format, args...), t.start+1))
+}
+
+// keyword reports whether the token is the bare word, ignoring case.
+func (t queryToken) keyword(word string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, word)
+}
+
+func lexQuery(text string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(text); {
+		switch c := text[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(text[i+1:], c)
+			if end < 0 {
+				return nil, queryToken{start: i}.errorf("unterminated string")
+			}
+			tokens = append(tokens, queryToken{kind: tokenString, text: text[i+1 : i+1+end], start: i})
+			i += end + 2
+		case strings.HasPrefix(text[i:], "!=") || strings.HasPrefix(text[i:], "<=") || strings.HasPrefix(text[i:], ">="):
+			tokens = append(tokens, queryToken{kind: tokenSymbol, text: text[i : i+2], start: i})
+			i += 2
+		case strings.IndexByte("()=<>~,:", c) >= 0:
+			tokens = append(tokens, queryToken{kind: tokenSymbol, text: text[i : i+1], start: i})
+			i++
+		case isWordByte(c):
+			start := i
+			for i < len(text) && isWordByte(text[i]) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: tokenWord, text: text[start:i], start: start})
+		default:
+			return nil, queryToken{start: i}.errorf("unexpected character %q", c)
+		}
+	}
+	return append(tokens, queryToken{kind: tokenEnd, start: len(text)}), nil
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.'
+}
+
+type queryParser struct {
+	tokens     []queryToken
+	pos        int
+	conditions int
+}
+
+func (p *queryParser) peek() queryToken { return p.tokens[p.pos] }
+
+func (p *queryParser) next() queryToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEnd {
+		p.pos++
+	}
+	return token
+}
+
+func (p *queryParser) symbol(text string) bool {
+	if token := p.peek(); token.kind == tokenSymbol && token.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) or() (Expr, error) {
+	left, err := p.and()
+	for err == nil && p.peek().keyword("or") {
+		p.next()
+		var right Expr
+		if right, err = p.and(); err == nil {
+			left = OrExpr{Left: left, Right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *queryParser) and() (Expr, error) {
+	left, err := p.unary()
+	for err == nil && p.peek().keyword("and") {
+		p.next()
+		var right Expr
+		if right, err = p.unary(); err == nil {
+			left = AndExpr{Left: left, Right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *queryParser) unary() (Expr, error) {
+	if p.peek().keyword("not") {
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Operand: operand}, nil
+	}
+	if p.symbol("(") {
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if token := p.peek(); !p.symbol(")") {
+			return nil, token.errorf("expected \")\" but found %s", token)
+		}
+		return expr, nil
+	}
+	return p.condition()
+}
+
+func (p *queryParser) condition() (Expr, error) {
+	token := p.next()
+	field := QueryField(strings.ToLower(token.text))
+	ops, ok := fieldOps[field]
+	if token.kind != tokenWord || !ok {
+		return nil, token.errorf("expected a field but found %s", token)
+	}
+	if p.conditions++; p.conditions > maxQueryConditions {
+		return nil, token.errorf("query has more than %d conditions", maxQueryConditions)
+	}
+	opToken := p.next()
+	op := QueryOp(opToken.text)
+	negate := false
+	switch {
+	case field == FieldTag && opToken.kind == tokenSymbol && opToken.text == ":":
+		op = QueryEqual
+	case opToken.keyword("not") && p.peek().keyword("in"):
+		p.next()
+		op, negate = QueryIn, true
+	case opToken.keyword("in"):
+		op = QueryIn
+	case opToken.kind != tokenSymbol:
+		op = ""
+	}
+	if !slices.Contains(ops, op) {
+		return nil, opToken.errorf("%s cannot be compared with %s", field, opToken)
+	}
+	var raw []queryToken
+	if op == QueryIn {
+		if token := p.peek(); !p.symbol("(") {
+			return nil, token.errorf("expected \"(\" but found %s", token)
+		}
+		for {
+			raw = append(raw, p.next())
+			if p.symbol(")") {
+				break
+			}
+			if token := p.peek(); !p.symbol(",") {
+				return nil, token.errorf("expected \",\" or \")\" but found %s", token)
+			}
+		}
+	} else {
+		raw = append(raw, p.next())
+	}
+	condition := Condition{Field: field, Op: op, Values: make([]any, 0, len(raw))}
+	for _, token := range raw {
+		value, err := queryValue(field, token)
+		if err != nil {
+			return nil, err
+		}
+		condition.Values = append(condition.Values, value)
+	}
+	if negate {
+		return NotExpr{Operand: condition}, nil
+	}
+	return condition, nil
+}
+
+func queryValue(field QueryField, token queryToken) (any, error) {
+	if token.kind != tokenWord && token.kind != tokenString {
+		return nil, token.errorf("expected a value but found %s", token)
+	}
+	text := token.text
+	switch field {
+	case FieldAmount:
+		amount, err := strconv.ParseFloat(text, 32)
+		if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+			return nil, token.errorf("amount must be a number")
+		}
+		return float32(amount), nil
+	case FieldDate:
+		if date, err := time.Parse(time.DateOnly, text); err == nil {
+			return date, nil
+		}
+		date, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return nil, token.errorf("date must be YYYY-MM-DD or a quoted RFC3339 time")
+		}
+		return date, nil
+	case FieldAuthor, FieldHousehold:
+		id, err := strconv.ParseInt(text, 10, 64)
+		if err != nil || id < 1 {
+			return nil, token.errorf("%s must be a positive ID", field)
+		}
+		return id, nil
+	case FieldCategory:
+		return strings.ToLower(strings.TrimSpace(text)), nil
+	case FieldTag:
+		tags, err := NormalizeTags([]string{text})
+		if err != nil {
+			return nil, token.errorf("%s", apperrors.MessageOf(err))
+		}
+		return tags[0], nil
+	}
+	return text, nil
+}
@@ -0,0 +1,150 @@
+package transactions
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Snippets mark each matched word with HighlightMarker on both sides, as
+// Markdown bold, so chat clients render them as is.
+const HighlightMarker = "**"
+
+const (
+	// snippetWords is the most words a snippet shows before it is cut.
+	snippetWords = 12
+	// fuzzyThreshold is the trigram similarity above which a word counts as
+	// a misspelling of a search term, as in pg_trgm.
+	fuzzyThreshold = 0.5
+)
+
+// SearchMatch describes how a transaction matched ListFilter.Search. Rank
+// orders matches by relevance, and Snippet is the description or notes with
+// the matched words highlighted.
+type SearchMatch struct {
+	Rank    float64
+	Snippet string
+}
+
+// Snippet highlights the words of the description, or else the notes, that
+// match the search: words containing a search term or spelled close to one.
+// Long text is cut to the words around the first match.
+func Snippet(description, notes *string, search string) string {
+	terms := words(strings.ToLower(search))
+	var fallback string
+	for _, text := range []*string{description, notes} {
+		if text == nil || strings.TrimSpace(*text) == "" {
+			continue
+		}
+		if snippet, ok := highlight(*text, terms); ok {
+			return snippet
+		}
+		if fallback == "" {
+			fallback, _ = highlight(*text, nil)
+		}
+	}
+	return fallback
+}
+
+// highlight marks the words of text matching terms and cuts it to
+// snippetWords words. It reports whether any word matched.
+func highlight(text string, terms []string) (string, bool) {
+	fields := strings.Fields(text)
+	first := -1
+	for index, field := range fields {
+		if matchesTerm(field, terms) {
+			fields[index] = markWords(field, terms)
+			if first < 0 {
+				first = index
+			}
+		}
+	}
+	start := 0
+	if first > snippetWords/3 && len(fields) > snippetWords {
+		start = min(first-snippetWords/3, len(fields)-snippetWords)
+	}
+	end := min(start+snippetWords, len(fields))
+	snippet := strings.Join(fields[start:end], " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(fields) {
+		snippet += "…"
+	}
+	return snippet, first >= 0
+}
+
+func matchesTerm(field string, terms []string) bool {
+	for _, word := range words(strings.ToLower(field)) {
+		if termMatch(word, terms) {
+			return true
+		}
+	}
+	return false
+}
+
+// markWords highlights the matching words within one whitespace-separated
+// field, leaving punctuation such as a trailing comma outside the markers.
+func markWords(field string, terms []string) string {
+	var marked strings.Builder
+	runes := []rune(field)
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			marked.WriteRune(runes[start])
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		word := string(runes[start:end])
+		if termMatch(strings.ToLower(word), terms) {
+			word = HighlightMarker + word + HighlightMarker
+		}
+		marked.WriteString(word)
+		start = end
+	}
+	return marked.String()
+}
+
+func termMatch(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.Contains(word, term) || trigramSimilarity(word, term) >= fuzzyThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// words splits text into runs of letters and digits.
+func words(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool { return !isWordRune(r) })
+}
+
+func isWordRune(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+// trigramSimilarity compares two lowercase words the way pg_trgm does: the
+// share of their padded three-letter sequences that they have in common.
+func trigramSimilarity(a, b string) float64 {
+	left, right := trigrams(a), trigrams(b)
+	shared := 0
+	for trigram := range left {
+		if right[trigram] {
+			shared++
+		}
+	}
+	union := len(left) + len(right) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+func trigrams(word string) map[string]bool {
+	padded := []rune("  " + word + " ")
+	set := make(map[string]bool, len(padded))
+	for index := 0; index+3 <= len(padded); index++ {
+		set[string(padded[index:index+3])] = true
+	}
+	return set
+}
@@ -3,12 +3,15 @@ package transactions
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cespare/xxhash"
 	"github.com/jxskiss/base62"
 
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/app/events"
 	"rdmm404/voltr-finance/internal/app/patch"
 )
 
@@ -16,10 +19,23 @@ type Service struct {
 	repo       Repository
 	identities IdentityResolver
 	categories CategoryResolver
+	alerts     AlertEvaluator
+	events     events.Publisher
 }
 
-func NewService(repo Repository, identities IdentityResolver, categories CategoryResolver) *Service {
-	return &Service{repo: repo, identities: identities, categories: categories}
+func NewService(repo Repository, identities IdentityResolver, categories CategoryResolver, alerts ...AlertEvaluator) *Service {
+	service := &Service{repo: repo, identities: identities, categories: categories, events: events.Discard}
+	if len(alerts) > 0 {
+		service.alerts = alerts[0]
+	}
+	return service
+}
+
+// WithEvents announces every committed create, update, delete and restore on
+// publisher.
+func (s *Service) WithEvents(publisher events.Publisher) *Service {
+	s.events = publisher
+	return s
 }
 
 func (s *Service) Create(ctx context.Context, input CreateInput) (Transaction, error) {
@@ -28,7 +44,12 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (Transaction, e
 		return Transaction{}, err
 	}
 	item, err := s.repo.Create(ctx, newTransaction)
-	return item, apperrors.WrapInternal("create transaction", err)
+	if err != nil {
+		return Transaction{}, apperrors.WrapInternal("create transaction", err)
+	}
+	s.publish(ctx, events.TransactionCreated, item)
+	s.evaluateAlerts(ctx, item)
+	return item, nil
 }
 
 func (s *Service) CreateBatch(ctx context.Context, inputs []CreateInput) BulkResult {
@@ -58,7 +79,11 @@ func (s *Service) GetMany(ctx context.Context, ids []int64, includeDeleted bool)
 	return items, nil
 }
 
-func (s *Service) List(ctx context.Context, filter ListFilter) ([]Transaction, error) {
+// List returns one page of transactions. Pages are keyset based: the
+// NextCursor of one page continues exactly after its last transaction, so rows
+// written while a caller pages through history are neither skipped nor
+// repeated.
+func (s *Service) List(ctx context.Context, filter ListFilter) (Page, error) {
 	if filter.Sort == "" {
 		filter.Sort = "transaction_date"
 	}
@@ -68,11 +93,115 @@ func (s *Service) List(ctx context.Context, filter ListFilter) ([]Transaction, e
 	if filter.Limit == 0 {
 		filter.Limit = 100
 	}
+	if filter.Search != nil && strings.TrimSpace(*filter.Search) == "" {
+		filter.Search = nil
+	}
+	if filter.Sort == SortRelevance && filter.Search == nil {
+		return Page{}, apperrors.Validation("sorting by relevance requires a search")
+	}
+	var err error
+	if filter.Tags, filter.TagMatch, err = tagScope(filter.Tags, filter.TagMatch); err != nil {
+		return Page{}, err
+	}
+	if filter.Where, err = s.where(ctx, filter.Query, filter.Filter); err != nil {
+		return Page{}, err
+	}
+	if filter.Cursor != "" {
+		if filter.Offset != 0 {
+			return Page{}, apperrors.Validation("cursor and offset cannot be combined")
+		}
+		after, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		if after.Sort != filter.Sort || after.SortOrder != filter.SortOrder {
+			return Page{}, apperrors.Validation("cursor belongs to a different sort")
+		}
+		filter.After = &after
+	}
+	limit := filter.Limit
+	filter.Limit++
 	items, err := s.repo.List(ctx, filter)
-	if items == nil && err == nil {
-		items = []Transaction{}
+	if err != nil {
+		return Page{}, apperrors.WrapInternal("list transactions", err)
 	}
-	return items, apperrors.WrapInternal("list transactions", err)
+	page := Page{Items: items}
+	if len(items) > int(limit) {
+		page.Items = items[:limit]
+		page.NextCursor = cursorAfter(page.Items[limit-1], filter.Sort, filter.SortOrder).encode()
+	}
+	for index, item := range page.Items {
+		if item.Match != nil {
+			page.Items[index].Match = &SearchMatch{Rank: item.Match.Rank, Snippet: Snippet(item.Description, item.Notes, *filter.Search)}
+		}
+	}
+	if page.Items == nil {
+		page.Items = []Transaction{}
+	}
+	return page, nil
+}
+
+// TagTotals counts and sums the live transactions in scope for every tag in
+// use, in tag order. Tags without matching transactions are left out.
+func (s *Service) TagTotals(ctx context.Context, filter TagTotalsFilter) ([]TagTotal, error) {
+	if filter.FromDate != nil && filter.ToDate != nil && filter.ToDate.Before(*filter.FromDate) {
+		return nil, apperrors.Validation("to date must not be before from date")
+	}
+	where, err := s.where(ctx, filter.Query, filter.Filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.Where = where
+	totals, err := s.repo.TagTotals(ctx, filter)
+	if totals == nil && err == nil {
+		totals = []TagTotal{}
+	}
+	return totals, apperrors.WrapInternal("total transactions by tag", err)
+}
+
+// Changes reads the change feed so clients can sync a local copy
+// incrementally. A transaction written several times since the token appears
+// once, at its latest position.
+func (s *Service) Changes(ctx context.Context, filter ChangesFilter) (ChangeSet, error) {
+	var since int64
+	if filter.Since != "" {
+		parsed, err := strconv.ParseInt(filter.Since, 10, 64)
+		if err != nil || parsed < 0 {
+			return ChangeSet{}, apperrors.Validation("since must be a change token")
+		}
+		since = parsed
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 500
+	}
+	items, err := s.repo.ListChanges(ctx, since, filter.Limit+1)
+	if err != nil {
+		return ChangeSet{}, apperrors.WrapInternal("list transaction changes", err)
+	}
+	set := ChangeSet{Items: items, NextToken: strconv.FormatInt(since, 10)}
+	if len(items) > int(filter.Limit) {
+		set.Items, set.HasMore = items[:filter.Limit], true
+	}
+	if len(set.Items) > 0 {
+		set.NextToken = strconv.FormatInt(set.Items[len(set.Items)-1].ChangeSequence, 10)
+	}
+	if set.Items == nil {
+		set.Items = []Transaction{}
+	}
+	return set, nil
+}
+
+// History returns every recorded create, update, delete and restore of the
+// transaction, oldest first, with the fields each one changed.
+func (s *Service) History(ctx context.Context, id int64) ([]HistoryEntry, error) {
+	if _, err := s.Get(ctx, id, true); err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.History(ctx, id)
+	if entries == nil && err == nil {
+		entries = []HistoryEntry{}
+	}
+	return entries, apperrors.WrapInternal("get transaction history", err)
 }
 
 func (s *Service) Update(ctx context.Context, input UpdateInput) (Transaction, error) {
@@ -83,8 +212,37 @@ func (s *Service) Update(ctx context.Context, input UpdateInput) (Transaction, e
 	if err != nil {
 		return Transaction{}, err
 	}
+	// A household change is also announced to the household the transaction
+	// leaves, so that its streams drop it.
+	var previous *int64
+	if mutation.HouseholdID.Present() {
+		before, err := s.repo.Get(ctx, input.ID, false)
+		if err != nil {
+			return Transaction{}, apperrors.WrapInternal("get transaction", err)
+		}
+		previous = before.HouseholdID
+	}
 	item, err := s.repo.Update(ctx, input.ID, mutation)
-	return item, apperrors.WrapInternal("update transaction", err)
+	if err != nil {
+		return Transaction{}, apperrors.WrapInternal("update transaction", err)
+	}
+	event := events.Event{Type: events.TransactionUpdated, HouseholdID: item.HouseholdID, TransactionID: item.ID}
+	if previous != nil && (item.HouseholdID == nil || *item.HouseholdID != *previous) {
+		event.PreviousHouseholdID = previous
+	}
+	s.events.Publish(ctx, event)
+	s.evaluateAlerts(ctx, item)
+	return item, nil
+}
+
+func (s *Service) evaluateAlerts(ctx context.Context, item Transaction) {
+	if s.alerts != nil {
+		s.alerts.EvaluateTransaction(ctx, item)
+	}
+}
+
+func (s *Service) publish(ctx context.Context, eventType events.Type, item Transaction) {
+	s.events.Publish(ctx, events.Event{Type: eventType, HouseholdID: item.HouseholdID, TransactionID: item.ID})
 }
 
 func (s *Service) UpdateBatch(ctx context.Context, inputs []UpdateInput) BulkResult {
@@ -98,13 +256,20 @@ func (s *Service) SoftDelete(ctx context.Context, input DeleteInput) (Transactio
 	if input.ID == 0 || input.DeletedByUserID == 0 {
 		return Transaction{}, apperrors.Validation("transaction id and deleted by user id are required")
 	}
+	if input.ExpectedVersion != nil && *input.ExpectedVersion <= 0 {
+		return Transaction{}, apperrors.Validation("expected version must be positive")
+	}
 	item, err := s.repo.SoftDelete(ctx, input)
-	return item, apperrors.WrapInternal("delete transaction", err)
+	if err != nil {
+		return Transaction{}, apperrors.WrapInternal("delete transaction", err)
+	}
+	s.publish(ctx, events.TransactionDeleted, item)
+	return item, nil
 }
 
-func (s *Service) DeleteBatch(ctx context.Context, ids []int64, deletedByUserID int64, reason *string) BulkResult {
-	return runBulk(ids, knownID, func(id int64) (int64, error) {
-		item, err := s.SoftDelete(ctx, DeleteInput{ID: id, DeletedByUserID: deletedByUserID, Reason: reason})
+func (s *Service) DeleteBatch(ctx context.Context, inputs []DeleteInput) BulkResult {
+	return runBulk(inputs, func(input DeleteInput) *int64 { return knownID(input.ID) }, func(input DeleteInput) (int64, error) {
+		item, err := s.SoftDelete(ctx, input)
 		return item.ID, err
 	})
 }
@@ -114,7 +279,11 @@ func (s *Service) Restore(ctx context.Context, input RestoreInput) (Transaction,
 		return Transaction{}, apperrors.Validation("transaction id and restored by user id are required")
 	}
 	item, err := s.repo.Restore(ctx, input)
-	return item, apperrors.WrapInternal("restore transaction", err)
+	if err != nil {
+		return Transaction{}, apperrors.WrapInternal("restore transaction", err)
+	}
+	s.publish(ctx, events.TransactionRestored, item)
+	return item, nil
 }
 
 func (s *Service) RestoreBatch(ctx context.Context, ids []int64, restoredByUserID int64) BulkResult {
@@ -142,21 +311,38 @@ func (s *Service) prepareCreate(ctx context.Context, input CreateInput) (NewTran
 	if err != nil {
 		return NewTransaction{}, apperrors.Normalize(err)
 	}
+	tags, err := NormalizeTags(input.Tags)
+	if err != nil {
+		return NewTransaction{}, err
+	}
 	hash, err := Hash(input.Description, input.TransactionDate, authorID, input.HouseholdID, categoryID, input.Amount)
 	if err != nil {
 		return NewTransaction{}, err
 	}
-	return NewTransaction{Hash: hash, Amount: input.Amount, TransactionDate: input.TransactionDate, Description: input.Description, Notes: input.Notes, CategoryID: categoryID, HouseholdID: input.HouseholdID, AuthorID: authorID}, nil
+	return NewTransaction{Hash: hash, Amount: input.Amount, TransactionDate: input.TransactionDate, Description: input.Description, Notes: input.Notes, CategoryID: categoryID, HouseholdID: input.HouseholdID, AuthorID: authorID, Tags: tags}, nil
 }
 
 func (s *Service) prepareUpdate(ctx context.Context, input UpdateInput) (Mutation, error) {
-	mutation := Mutation{Amount: input.Amount, TransactionDate: input.TransactionDate, Description: input.Description, Notes: input.Notes, HouseholdID: input.HouseholdID}
+	mutation := Mutation{Amount: input.Amount, TransactionDate: input.TransactionDate, Description: input.Description, Notes: input.Notes, HouseholdID: input.HouseholdID, ActorID: input.UpdatedByUserID, ExpectedVersion: input.ExpectedVersion}
+	if input.UpdatedByUserID != nil && *input.UpdatedByUserID <= 0 {
+		return Mutation{}, apperrors.Validation("updated by user id must be positive")
+	}
+	if input.ExpectedVersion != nil && *input.ExpectedVersion <= 0 {
+		return Mutation{}, apperrors.Validation("expected version must be positive")
+	}
 	if input.Amount != nil && *input.Amount == 0 {
 		return Mutation{}, apperrors.Validation("amount is required")
 	}
 	if input.TransactionDate != nil && input.TransactionDate.IsZero() {
 		return Mutation{}, apperrors.Validation("transaction date is required")
 	}
+	if input.Tags != nil {
+		tags, err := NormalizeTags(*input.Tags)
+		if err != nil {
+			return Mutation{}, err
+		}
+		mutation.Tags = &tags
+	}
 	if input.Category.Present() {
 		selector := input.Category.Value()
 		if selector == nil {
@@ -0,0 +1,47 @@
+package transactions
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+)
+
+// MaxTags is the most tags one transaction can carry.
+const MaxTags = 20
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// NormalizeTags lowercases and trims tags, drops duplicates and sorts them,
+// so trip-Japan-2026 and trip-japan-2026 name the same tag. Each tag is 1 to
+// 64 letters, digits, hyphens and underscores starting with a letter or digit.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) {
+			return nil, apperrors.Validation(fmt.Sprintf("tag %q must be 1 to 64 letters, digits, hyphens or underscores starting with a letter or digit", tag))
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) > MaxTags {
+		return nil, apperrors.Validation(fmt.Sprintf("a transaction can have at most %d tags", MaxTags))
+	}
+	return normalized, nil
+}
+
+// tagScope normalizes the tags of a filter and defaults how they match.
+func tagScope(tags []string, match string) ([]string, string, error) {
+	if match == "" {
+		match = TagMatchAny
+	}
+	if match != TagMatchAny && match != TagMatchAll {
+		return nil, "", apperrors.Validation("tag match must be any or all")
+	}
+	tags, err := NormalizeTags(tags)
+	return tags, match, err
+}
@@ -3,10 +3,14 @@ package transactions
 import (
 	"context"
 	"errors"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/app/events"
 	"rdmm404/voltr-finance/internal/app/patch"
 )
 
@@ -16,10 +20,16 @@ type fakeRepository struct {
 	hashes         map[string]int64
 	createCalls    int
 	failCreateCall int
+	changeSequence int64
+	lastMutation   Mutation
+	history        map[int64][]HistoryEntry
+	lastFilter     ListFilter
+	lastAggregate  AggregateFilter
+	savedFilters   map[string]SavedFilter
 }
 
 func newFakeRepository() *fakeRepository {
-	return &fakeRepository{nextID: 100, items: map[int64]Transaction{}, hashes: map[string]int64{}}
+	return &fakeRepository{nextID: 100, items: map[int64]Transaction{}, hashes: map[string]int64{}, savedFilters: map[string]SavedFilter{}}
 }
 func (f *fakeRepository) Create(_ context.Context, input NewTransaction) (Transaction, error) {
 	f.createCalls++
@@ -30,9 +40,25 @@ func (f *fakeRepository) Create(_ context.Context, input NewTransaction) (Transa
 		return Transaction{}, apperrors.Conflict(apperrors.CodeDuplicateTransaction, "duplicate transaction", nil)
 	}
 	f.nextID++
-	item := Transaction{ID: f.nextID, Hash: input.Hash, Amount: input.Amount, TransactionDate: input.TransactionDate, AuthorID: input.AuthorID, HouseholdID: input.HouseholdID, CategoryID: input.CategoryID, Description: input.Description, Notes: input.Notes}
-	f.items[item.ID], f.hashes[item.Hash] = item, item.ID
-	return item, nil
+	item := Transaction{ID: f.nextID, Hash: input.Hash, Amount: input.Amount, TransactionDate: input.TransactionDate, AuthorID: input.AuthorID, HouseholdID: input.HouseholdID, CategoryID: input.CategoryID, Description: input.Description, Notes: input.Notes, Tags: input.Tags}
+	f.hashes[item.Hash] = item.ID
+	return f.write(item), nil
+}
+func (f *fakeRepository) write(item Transaction) Transaction {
+	f.changeSequence++
+	item.ChangeSequence = f.changeSequence
+	f.items[item.ID] = item
+	return item
+}
+func (f *fakeRepository) ListChanges(_ context.Context, since int64, limit int32) ([]Transaction, error) {
+	var items []Transaction
+	for _, item := range f.items {
+		if item.ChangeSequence > since {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ChangeSequence < items[j].ChangeSequence })
+	return items[:min(len(items), int(limit))], nil
 }
 func (f *fakeRepository) Get(_ context.Context, id int64, includeDeleted bool) (Transaction, error) {
 	item, ok := f.items[id]
@@ -41,16 +67,49 @@ func (f *fakeRepository) Get(_ context.Context, id int64, includeDeleted bool) (
 	}
 	return item, nil
 }
-func (*fakeRepository) List(context.Context, ListFilter) ([]Transaction, error) { return nil, nil }
+func (f *fakeRepository) List(_ context.Context, filter ListFilter) ([]Transaction, error) {
+	f.lastFilter = filter
+	key := func(item Transaction) Cursor { return cursorAfter(item, filter.Sort, filter.SortOrder) }
+	before := func(a, b Cursor) bool {
+		switch {
+		case !a.Time.Equal(b.Time):
+			return a.Time.Before(b.Time) == (filter.SortOrder == "asc")
+		case a.Amount != b.Amount:
+			return (a.Amount < b.Amount) == (filter.SortOrder == "asc")
+		case a.ID == b.ID:
+			return false
+		}
+		return (a.ID < b.ID) == (filter.SortOrder == "asc")
+	}
+	var items []Transaction
+	for _, item := range f.items {
+		if item.DeletedAt == nil && (filter.After == nil || before(*filter.After, key(item))) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return before(key(items[i]), key(items[j])) })
+	return items[:min(len(items), int(filter.Limit))], nil
+}
 func (f *fakeRepository) Update(_ context.Context, id int64, update Mutation) (Transaction, error) {
 	item, ok := f.items[id]
 	if !ok {
 		return Transaction{}, apperrors.NotFound(apperrors.CodeTransactionNotFound, "transaction not found", nil)
 	}
+	f.lastMutation = update
 	item = update.Apply(item)
 	item.Hash, _ = Hash(item.Description, item.TransactionDate, item.AuthorID, item.HouseholdID, item.CategoryID, item.Amount)
-	f.items[id] = item
-	return item, nil
+	return f.write(item), nil
+}
+func (f *fakeRepository) TagTotals(context.Context, TagTotalsFilter) ([]TagTotal, error) {
+	return nil, nil
+}
+func (f *fakeRepository) Aggregate(_ context.Context, filter AggregateFilter) ([]AggregateGroup, error) {
+	f.lastAggregate = filter
+	count, sum, avg, low, high := int64(2), "30.00", "15.00", "10.00", "20.00"
+	return []AggregateGroup{{Count: &count, Sum: &sum, Avg: &avg, Min: &low, Max: &high}}, nil
+}
+func (f *fakeRepository) History(_ context.Context, id int64) ([]HistoryEntry, error) {
+	return f.history[id], nil
 }
 func (f *fakeRepository) SoftDelete(_ context.Context, input DeleteInput) (Transaction, error) {
 	item, ok := f.items[input.ID]
@@ -60,8 +119,7 @@ func (f *fakeRepository) SoftDelete(_ context.Context, input DeleteInput) (Trans
 	now := time.Now()
 	item.DeletedAt = &now
 	item.DeletedByUserID = &input.DeletedByUserID
-	f.items[input.ID] = item
-	return item, nil
+	return f.write(item), nil
 }
 func (f *fakeRepository) Restore(_ context.Context, input RestoreInput) (Transaction, error) {
 	item, ok := f.items[input.ID]
@@ -69,8 +127,32 @@ func (f *fakeRepository) Restore(_ context.Context, input RestoreInput) (Transac
 		return Transaction{}, apperrors.NotFound(apperrors.CodeTransactionNotFound, "transaction not found", nil)
 	}
 	item.DeletedAt = nil
-	f.items[input.ID] = item
-	return item, nil
+	return f.write(item), nil
+}
+func (f *fakeRepository) SaveFilter(_ context.Context, name, query string) (SavedFilter, error) {
+	f.savedFilters[name] = SavedFilter{Name: name, Query: query}
+	return f.savedFilters[name], nil
+}
+func (f *fakeRepository) GetSavedFilter(_ context.Context, name string) (SavedFilter, error) {
+	saved, ok := f.savedFilters[name]
+	if !ok {
+		return SavedFilter{}, apperrors.NotFound(apperrors.CodeSavedFilterNotFound, "saved filter not found", nil)
+	}
+	return saved, nil
+}
+func (f *fakeRepository) ListSavedFilters(context.Context) ([]SavedFilter, error) {
+	var filters []SavedFilter
+	for _, saved := range f.savedFilters {
+		filters = append(filters, saved)
+	}
+	return filters, nil
+}
+func (f *fakeRepository) DeleteSavedFilter(_ context.Context, name string) error {
+	if _, ok := f.savedFilters[name]; !ok {
+		return apperrors.NotFound(apperrors.CodeSavedFilterNotFound, "saved filter not found", nil)
+	}
+	delete(f.savedFilters, name)
+	return nil
 }
 
 type fakeIdentities struct{}
@@ -111,9 +193,9 @@ func TestSingleTransactionLifecycleAndHash(t *testing.T) {
 	if _, err := service.Restore(context.Background(), RestoreInput{ID: created.ID, RestoredByUserID: 7}); err != nil {
 		t.Fatalf("Restore error=%v", err)
 	}
-	items, err := service.List(context.Background(), ListFilter{})
-	if err != nil || items == nil {
-		t.Fatalf("List=%#v error=%v", items, err)
+	page, err := service.List(context.Background(), ListFilter{})
+	if err != nil || page.Items == nil {
+		t.Fatalf("List=%#v error=%v", page, err)
 	}
 }
 
@@ -153,7 +235,7 @@ func TestBatchesAccountForEveryInputInOrder(t *testing.T) {
 		t.Fatalf("Failed=%+v", result.Failed)
 	}
 
-	deleteResult := service.DeleteBatch(context.Background(), []int64{result.Succeeded[0].ID, 999}, 7, nil)
+	deleteResult := service.DeleteBatch(context.Background(), []DeleteInput{{ID: result.Succeeded[0].ID, DeletedByUserID: 7}, {ID: 999, DeletedByUserID: 7}})
 	if len(deleteResult.Succeeded) != 1 || deleteResult.Succeeded[0].Index != 0 || len(deleteResult.Failed) != 1 || deleteResult.Failed[0].Index != 1 || deleteResult.Failed[0].ID == nil || *deleteResult.Failed[0].ID != 999 {
 		t.Fatalf("DeleteBatch=%+v", deleteResult)
 	}
@@ -162,3 +244,427 @@ func TestBatchesAccountForEveryInputInOrder(t *testing.T) {
 		t.Fatalf("RestoreBatch=%+v", restoreResult)
 	}
 }
+
+type recordingAlerts struct{ evaluated []int64 }
+
+func (r *recordingAlerts) EvaluateTransaction(_ context.Context, item Transaction) {
+	r.evaluated = append(r.evaluated, item.ID)
+}
+
+func TestCreateAndUpdateEvaluateBudgetAlertsAfterWriting(t *testing.T) {
+	repo := newFakeRepository()
+	alerts := &recordingAlerts{}
+	service := NewService(repo, fakeIdentities{}, fakeCategories{}, alerts)
+	householdID := int64(2)
+	date := time.Date(2026, 5, 8, 12, 0, 0, 0, time.UTC)
+	created, err := service.Create(context.Background(), CreateInput{Amount: 10, TransactionDate: date, HouseholdID: &householdID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := float32(12)
+	if _, err := service.Update(context.Background(), UpdateInput{ID: created.ID, Amount: &amount}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Update(context.Background(), UpdateInput{ID: 999, Amount: &amount}); err == nil {
+		t.Fatal("expected missing transaction error")
+	}
+	if len(alerts.evaluated) != 2 || alerts.evaluated[0] != created.ID || alerts.evaluated[1] != created.ID {
+		t.Fatalf("evaluated=%v", alerts.evaluated)
+	}
+}
+
+func TestListPagesByKeysetCursorWithoutSkipsOrRepeats(t *testing.T) {
+	repo := newFakeRepository()
+	service := NewService(repo, fakeIdentities{}, fakeCategories{})
+	day := time.Date(2026, 5, 8, 0, 0, 0, 0, time.UTC)
+	for i, date := range []time.Time{day, day, day.AddDate(0, 0, 1), day.AddDate(0, 0, -1), day} {
+		repo.items[int64(i+1)] = Transaction{ID: int64(i + 1), TransactionDate: date}
+	}
+	var seen []int64
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		page, err := service.List(context.Background(), ListFilter{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range page.Items {
+			seen = append(seen, item.ID)
+		}
+		if pages == 0 {
+			repo.items[6] = Transaction{ID: 6, TransactionDate: day.AddDate(0, 0, 2)}
+		}
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if want := []int64{3, 5, 2, 1, 4}; !reflect.DeepEqual(seen, want) {
+		t.Fatalf("seen=%v want %v", seen, want)
+	}
+}
+
+func TestListRejectsCursorsThatDoNotFitTheRequest(t *testing.T) {
+	service := NewService(newFakeRepository(), fakeIdentities{}, fakeCategories{})
+	amountCursor := cursorAfter(Transaction{ID: 4, Amount: 12.5}, "amount", "asc").encode()
+	for name, filter := range map[string]ListFilter{
+		"garbage":     {Cursor: "not-a-cursor"},
+		"other sort":  {Cursor: amountCursor},
+		"with offset": {Sort: "amount", SortOrder: "asc", Offset: 2, Cursor: amountCursor},
+	} {
+		if _, err := service.List(context.Background(), filter); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Errorf("%s error=%v", name, err)
+		}
+	}
+	decoded, err := decodeCursor(amountCursor)
+	if err != nil || decoded != (Cursor{Sort: "amount", SortOrder: "asc", Amount: 12.5, ID: 4}) {
+		t.Fatalf("decoded=%+v error=%v", decoded, err)
+	}
+}
+
+func TestChangesFollowTokensAndIncludeTombstones(t *testing.T) {
+	repo := newFakeRepository()
+	service := NewService(repo, fakeIdentities{}, fakeCategories{})
+	householdID := int64(2)
+	date := time.Date(2026, 5, 8, 12, 0, 0, 0, time.UTC)
+	var ids []int64
+	for _, amount := range []float32{10, 20, 30} {
+		item, err := service.Create(context.Background(), CreateInput{Amount: amount, TransactionDate: date, HouseholdID: &householdID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, item.ID)
+	}
+	first, err := service.Changes(context.Background(), ChangesFilter{Limit: 2})
+	if err != nil || len(first.Items) != 2 || !first.HasMore || first.NextToken != "2" {
+		t.Fatalf("first=%+v error=%v", first, err)
+	}
+	if _, err := service.SoftDelete(context.Background(), DeleteInput{ID: ids[0], DeletedByUserID: 7}); err != nil {
+		t.Fatal(err)
+	}
+	rest, err := service.Changes(context.Background(), ChangesFilter{Since: first.NextToken})
+	if err != nil || len(rest.Items) != 2 || rest.HasMore || rest.Items[0].ID != ids[2] || rest.Items[1].ID != ids[0] || rest.Items[1].DeletedAt == nil {
+		t.Fatalf("rest=%+v error=%v", rest, err)
+	}
+	idle, err := service.Changes(context.Background(), ChangesFilter{Since: rest.NextToken})
+	if err != nil || len(idle.Items) != 0 || idle.NextToken != rest.NextToken {
+		t.Fatalf("idle=%+v error=%v", idle, err)
+	}
+	if _, err := service.Changes(context.Background(), ChangesFilter{Since: "yesterday"}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("bad token error=%v", err)
+	}
+}
+
+type recordingPublisher []events.Event
+
+func (p *recordingPublisher) Publish(_ context.Context, event events.Event) { *p = append(*p, event) }
+
+func TestLifecyclePublishesEventsForCommittedWrites(t *testing.T) {
+	var published recordingPublisher
+	service := NewService(newFakeRepository(), fakeIdentities{}, fakeCategories{}).WithEvents(&published)
+	householdID := int64(2)
+	item, err := service.Create(context.Background(), CreateInput{Amount: 10, TransactionDate: time.Date(2026, 5, 8, 0, 0, 0, 0, time.UTC), HouseholdID: &householdID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := float32(11)
+	if _, err := service.Update(context.Background(), UpdateInput{ID: item.ID, Amount: &amount}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.SoftDelete(context.Background(), DeleteInput{ID: item.ID, DeletedByUserID: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Restore(context.Background(), RestoreInput{ID: item.ID, RestoredByUserID: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.SoftDelete(context.Background(), DeleteInput{ID: 404, DeletedByUserID: 7}); err == nil {
+		t.Fatal("deleting a missing transaction succeeded")
+	}
+	zero := int64(0)
+	if _, err := service.SoftDelete(context.Background(), DeleteInput{ID: item.ID, DeletedByUserID: 7, ExpectedVersion: &zero}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("zero expected version error=%v", err)
+	}
+	want := []events.Type{events.TransactionCreated, events.TransactionUpdated, events.TransactionDeleted, events.TransactionRestored}
+	if len(published) != len(want) {
+		t.Fatalf("published=%+v", published)
+	}
+	for index, event := range published {
+		if event.Type != want[index] || event.TransactionID != item.ID || event.HouseholdID == nil || *event.HouseholdID != householdID || event.PreviousHouseholdID != nil {
+			t.Fatalf("event %d=%+v", index, event)
+		}
+	}
+
+	published = nil
+	if _, err := service.Update(context.Background(), UpdateInput{ID: item.ID, HouseholdID: patch.Set(int64(3))}); err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 1 || published[0].HouseholdID == nil || *published[0].HouseholdID != 3 || published[0].PreviousHouseholdID == nil || *published[0].PreviousHouseholdID != householdID {
+		t.Fatalf("moved transaction events=%+v", published)
+	}
+}
+
+func TestDiffReportsChangedFieldsByAPIName(t *testing.T) {
+	date := time.Date(2026, 5, 8, 12, 0, 0, 0, time.UTC)
+	description, categoryID, householdID := "Coffee", int64(42), int64(2)
+	created := Transaction{ID: 1, Amount: 4.25, TransactionDate: date, Description: &description, CategoryID: &categoryID, HouseholdID: &householdID, AuthorID: 7}
+	want := []FieldChange{
+		{Field: "amount", After: float32(4.25)},
+		{Field: "transactionDate", After: date},
+		{Field: "description", After: "Coffee"},
+		{Field: "categoryId", After: int64(42)},
+		{Field: "householdId", After: int64(2)},
+		{Field: "authorId", After: int64(7)},
+	}
+	if got := Diff(Transaction{}, created); !reflect.DeepEqual(got, want) {
+		t.Fatalf("created diff=%+v", got)
+	}
+	amount, sameInstant := float32(5), date.In(time.FixedZone("EDT", -4*3600))
+	updated := Mutation{Amount: &amount, CategoryID: patch.Clear[int64](), TransactionDate: &sameInstant}.Apply(created)
+	want = []FieldChange{{Field: "amount", Before: float32(4.25), After: float32(5)}, {Field: "categoryId", Before: int64(42)}}
+	if got := Diff(created, updated); !reflect.DeepEqual(got, want) {
+		t.Fatalf("updated diff=%+v", got)
+	}
+	if got := Diff(updated, updated); len(got) != 0 || got == nil {
+		t.Fatalf("unchanged diff=%#v", got)
+	}
+}
+
+func TestTagsAreNormalizedAndReplacedAsASet(t *testing.T) {
+	repo := newFakeRepository()
+	service := NewService(repo, fakeIdentities{}, fakeCategories{})
+	ctx, householdID, date := context.Background(), int64(2), time.Date(2026, 5, 8, 0, 0, 0, 0, time.UTC)
+	item, err := service.Create(ctx, CreateInput{Amount: 10, TransactionDate: date, HouseholdID: &householdID, Tags: []string{" Trip-Japan-2026", "reimbursable", "REIMBURSABLE"}})
+	if err != nil || !reflect.DeepEqual(item.Tags, []string{"reimbursable", "trip-japan-2026"}) {
+		t.Fatalf("created=%+v error=%v", item, err)
+	}
+	for _, tags := range [][]string{{""}, {"tax deductible"}, {"-leading"}, {strings.Repeat("a", 65)}, make([]string, MaxTags+1)} {
+		if _, err := service.Create(ctx, CreateInput{Amount: 11, TransactionDate: date, HouseholdID: &householdID, Tags: tags}); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("tags %q error=%v", tags, err)
+		}
+	}
+	replaced := []string{"tax-deductible"}
+	updated, err := service.Update(ctx, UpdateInput{ID: item.ID, Tags: &replaced})
+	if err != nil || !reflect.DeepEqual(updated.Tags, replaced) {
+		t.Fatalf("updated=%+v error=%v", updated, err)
+	}
+	want := []FieldChange{{Field: "tags", Before: []string{"reimbursable", "trip-japan-2026"}, After: []string{"tax-deductible"}}}
+	if got := Diff(item, updated); !reflect.DeepEqual(got, want) {
+		t.Fatalf("tags diff=%+v", got)
+	}
+	amount := float32(12)
+	unchanged, err := service.Update(ctx, UpdateInput{ID: item.ID, Amount: &amount})
+	if err != nil || !reflect.DeepEqual(unchanged.Tags, replaced) {
+		t.Fatalf("unchanged=%+v error=%v", unchanged, err)
+	}
+	cleared, err := service.Update(ctx, UpdateInput{ID: item.ID, Tags: &[]string{}})
+	if diff := Diff(unchanged, cleared); err != nil || len(cleared.Tags) != 0 || len(diff) != 1 || diff[0].After != nil {
+		t.Fatalf("cleared=%+v diff=%+v error=%v", cleared, diff, err)
+	}
+
+	if _, err := service.List(ctx, ListFilter{Tags: []string{"Reimbursable", "trip-japan-2026"}}); err != nil || repo.lastFilter.TagMatch != TagMatchAny || !reflect.DeepEqual(repo.lastFilter.Tags, []string{"reimbursable", "trip-japan-2026"}) {
+		t.Fatalf("filter=%+v error=%v", repo.lastFilter, err)
+	}
+	if _, err := service.List(ctx, ListFilter{Tags: []string{"a"}, TagMatch: "some"}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("tag match error=%v", err)
+	}
+	from := date
+	to := date.AddDate(0, 0, -1)
+	if _, err := service.TagTotals(ctx, TagTotalsFilter{FromDate: &from, ToDate: &to}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("reversed range error=%v", err)
+	}
+	if totals, err := service.TagTotals(ctx, TagTotalsFilter{}); err != nil || totals == nil {
+		t.Fatalf("totals=%#v error=%v", totals, err)
+	}
+}
+
+func TestParseQueryBuildsTypedConditions(t *testing.T) {
+	expr, err := ParseQuery(`amount > 50 and category in (Groceries, restaurants) and not tag:Reimbursable or date >= 2026-05-01 AND notes ~ "costco run"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := OrExpr{
+		Left: AndExpr{
+			Left: AndExpr{
+				Left:  Condition{Field: FieldAmount, Op: QueryGreater, Values: []any{float32(50)}},
+				Right: Condition{Field: FieldCategory, Op: QueryIn, Values: []any{"groceries", "restaurants"}},
+			},
+			Right: NotExpr{Operand: Condition{Field: FieldTag, Op: QueryEqual, Values: []any{"reimbursable"}}},
+		},
+		Right: AndExpr{
+			Left:  Condition{Field: FieldDate, Op: QueryGreaterEqual, Values: []any{time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)}},
+			Right: Condition{Field: FieldNotes, Op: QueryContains, Values: []any{"costco run"}},
+		},
+	}
+	if !reflect.DeepEqual(expr, want) {
+		t.Fatalf("expr=%#v", expr)
+	}
+	expr, err = ParseQuery("(author = 1 or household != 2) and author not in (3)")
+	want2 := AndExpr{
+		Left: OrExpr{
+			Left:  Condition{Field: FieldAuthor, Op: QueryEqual, Values: []any{int64(1)}},
+			Right: Condition{Field: FieldHousehold, Op: QueryNotEqual, Values: []any{int64(2)}},
+		},
+		Right: NotExpr{Operand: Condition{Field: FieldAuthor, Op: QueryIn, Values: []any{int64(3)}}},
+	}
+	if err != nil || !reflect.DeepEqual(expr, want2) {
+		t.Fatalf("expr=%#v error=%v", expr, err)
+	}
+
+	for query, message := range map[string]string{
+		"":                           "expected a field but found end of query at position 1",
+		"amount >":                   "expected a value but found end of query at position 9",
+		"amount ~ 5":                 `amount cannot be compared with "~" at position 8`,
+		"date = 2026-05-01":          `date cannot be compared with "=" at position 6`,
+		"amount > lots":              "amount must be a number at position 10",
+		"amount > NaN":               "amount must be a number at position 10",
+		"date < 05/01/2026":          `unexpected character '/' at position 10`,
+		"author = 0":                 "author must be a positive ID at position 10",
+		"tag:'tax deductible'":       `tag "tax deductible" must be 1 to 64 letters, digits, hyphens or underscores starting with a letter or digit at position 5`,
+		"balance > 5":                `expected a field but found "balance" at position 1`,
+		"(amount > 5":                "expected \")\" but found end of query at position 12",
+		"amount in (1 2)":            `expected "," or ")" but found "2" at position 14`,
+		"amount > 5 amount < 9":      `unexpected "amount" at position 12`,
+		`description = "unclosed`:    "unterminated string at position 15",
+		"amount > 1; drop table tag": `unexpected character ';' at position 11`,
+	} {
+		if _, err := ParseQuery(query); !apperrors.IsKind(err, apperrors.KindValidation) || apperrors.MessageOf(err) != "query: "+message {
+			t.Errorf("ParseQuery(%q) error=%v, want %q", query, err, message)
+		}
+	}
+	if _, err := ParseQuery(strings.Repeat("amount > 1 or ", maxQueryConditions) + "amount > 1"); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("too many conditions error=%v", err)
+	}
+}
+
+func TestListAndTotalsApplyQueriesAndSavedFilters(t *testing.T) {
+	repo := newFakeRepository()
+	service := NewService(repo, fakeIdentities{}, fakeCategories{})
+	ctx := context.Background()
+	for _, name := range []string{"", "big spend", "-big"} {
+		if _, err := service.SaveFilter(ctx, name, "amount > 100"); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("name %q error=%v", name, err)
+		}
+	}
+	for _, query := range []string{" ", "amount >"} {
+		if _, err := service.SaveFilter(ctx, "big", query); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Fatalf("query %q error=%v", query, err)
+		}
+	}
+	saved, err := service.SaveFilter(ctx, " Big-Spend ", " amount > 100 ")
+	if err != nil || saved.Name != "big-spend" || saved.Query != "amount > 100" {
+		t.Fatalf("saved=%+v error=%v", saved, err)
+	}
+
+	big := Condition{Field: FieldAmount, Op: QueryGreater, Values: []any{float32(100)}}
+	groceries := Condition{Field: FieldCategory, Op: QueryEqual, Values: []any{"groceries"}}
+	if _, err := service.List(ctx, ListFilter{Query: "category = groceries", Filter: "big-spend"}); err != nil || !reflect.DeepEqual(repo.lastFilter.Where, AndExpr{Left: big, Right: groceries}) {
+		t.Fatalf("where=%#v error=%v", repo.lastFilter.Where, err)
+	}
+	if _, err := service.List(ctx, ListFilter{}); err != nil || repo.lastFilter.Where != nil {
+		t.Fatalf("where=%#v error=%v", repo.lastFilter.Where, err)
+	}
+	if _, err := service.List(ctx, ListFilter{Query: "amount >"}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("invalid query error=%v", err)
+	}
+	if _, err := service.TagTotals(ctx, TagTotalsFilter{Filter: "missing"}); apperrors.CodeOf(err) != apperrors.CodeSavedFilterNotFound {
+		t.Fatalf("missing filter error=%v", err)
+	}
+	if err := service.DeleteSavedFilter(ctx, "BIG-SPEND"); err != nil {
+		t.Fatal(err)
+	}
+	if filters, err := service.ListSavedFilters(ctx); err != nil || filters == nil || len(filters) != 0 {
+		t.Fatalf("filters=%#v error=%v", filters, err)
+	}
+}
+
+func TestSearchHighlightsMatchesAndSortsByRelevance(t *testing.T) {
+	text := func(value string) *string { return &value }
+	for _, test := range []struct {
+		description, notes *string
+		search, want       string
+	}{
+		{text("Coffee at Starbucks, downtown"), nil, "starbuks", "Coffee at **Starbucks**, downtown"},
+		{text("Groceries"), text("split with Ana"), "ana", "split with **Ana**"},
+		{nil, text("one two three four five six seven eight nine ten eleven twelve thirteen rent fifteen sixteen"), "rent", "…five six seven eight nine ten eleven twelve thirteen **rent** fifteen sixteen"},
+		{text("one two three four five six seven eight nine ten eleven twelve thirteen"), nil, "zzz", "one two three four five six seven eight nine ten eleven twelve…"},
+	} {
+		if got := Snippet(test.description, test.notes, test.search); got != test.want {
+			t.Errorf("Snippet(%q) = %q, want %q", test.search, got, test.want)
+		}
+	}
+
+	repo := newFakeRepository()
+	service := NewService(repo, fakeIdentities{}, fakeCategories{})
+	ctx := context.Background()
+	if _, err := service.List(ctx, ListFilter{Sort: SortRelevance, Search: text("  ")}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("relevance without search error=%v", err)
+	}
+	repo.items[1] = Transaction{ID: 1, Description: text("Starbucks"), Match: &SearchMatch{Rank: 0.75}}
+	page, err := service.List(ctx, ListFilter{Sort: SortRelevance, SortOrder: "desc", Search: text("starbuks"), Limit: 1})
+	if err != nil || len(page.Items) != 1 || *page.Items[0].Match != (SearchMatch{Rank: 0.75, Snippet: "**Starbucks**"}) {
+		t.Fatalf("page=%+v error=%v", page, err)
+	}
+	decoded, err := decodeCursor(cursorAfter(page.Items[0], SortRelevance, "desc").encode())
+	if err != nil || decoded != (Cursor{Sort: SortRelevance, SortOrder: "desc", Relevance: 0.75, ID: 1}) {
+		t.Fatalf("decoded=%+v error=%v", decoded, err)
+	}
+}
+
+func TestAggregateNormalizesDimensionsAndKeepsRequestedMetrics(t *testing.T) {
+	repo := newFakeRepository()
+	service := NewService(repo, fakeIdentities{}, fakeCategories{})
+	ctx := context.Background()
+	for name, filter := range map[string]AggregateFilter{
+		"unknown dimension": {By: []AggregateDimension{"year"}},
+		"two periods":       {By: []AggregateDimension{DimensionDay, DimensionMonth}},
+		"unknown metric":    {Metrics: []AggregateMetric{"median"}},
+		"bad tag match":     {TagMatch: "some"},
+	} {
+		if _, err := service.Aggregate(ctx, filter); !apperrors.IsKind(err, apperrors.KindValidation) {
+			t.Errorf("%s error=%v", name, err)
+		}
+	}
+
+	result, err := service.Aggregate(ctx, AggregateFilter{By: []AggregateDimension{" Category", "month", "category"}, Tags: []string{"Trip"}, Query: "amount > 5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []AggregateDimension{DimensionCategory, DimensionMonth}; !reflect.DeepEqual(result.By, want) || !reflect.DeepEqual(repo.lastAggregate.By, want) {
+		t.Fatalf("by=%v repository by=%v", result.By, repo.lastAggregate.By)
+	}
+	if !reflect.DeepEqual(result.Metrics, []AggregateMetric{MetricSum, MetricCount}) || repo.lastAggregate.TagMatch != TagMatchAny || !reflect.DeepEqual(repo.lastAggregate.Tags, []string{"trip"}) || repo.lastAggregate.Where == nil {
+		t.Fatalf("result=%+v repository filter=%+v", result, repo.lastAggregate)
+	}
+	if group := result.Groups[0]; *group.Count != 2 || *group.Sum != "30.00" || group.Avg != nil || group.Min != nil || group.Max != nil {
+		t.Fatalf("group=%+v", group)
+	}
+	result, err = service.Aggregate(ctx, AggregateFilter{Metrics: []AggregateMetric{MetricMax, MetricAvg}})
+	if group := result.Groups[0]; err != nil || group.Count != nil || group.Sum != nil || group.Min != nil || *group.Avg != "15.00" || *group.Max != "20.00" {
+		t.Fatalf("group=%+v error=%v", group, err)
+	}
+}
+
+func TestHistoryRequiresAKnownTransactionAndUpdatesNameTheirActor(t *testing.T) {
+	repo := newFakeRepository()
+	service := NewService(repo, fakeIdentities{}, fakeCategories{})
+	if _, err := service.History(context.Background(), 404); !apperrors.IsKind(err, apperrors.KindNotFound) {
+		t.Fatalf("missing history error=%v", err)
+	}
+	householdID := int64(2)
+	item, err := service.Create(context.Background(), CreateInput{Amount: 10, TransactionDate: time.Date(2026, 5, 8, 0, 0, 0, 0, time.UTC), HouseholdID: &householdID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := service.History(context.Background(), item.ID)
+	if err != nil || history == nil {
+		t.Fatalf("history=%#v error=%v", history, err)
+	}
+	amount, actor, invalid := float32(11), int64(7), int64(0)
+	if _, err := service.Update(context.Background(), UpdateInput{ID: item.ID, Amount: &amount, UpdatedByUserID: &invalid}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("invalid actor error=%v", err)
+	}
+	if _, err := service.Update(context.Background(), UpdateInput{ID: item.ID, Amount: &amount, UpdatedByUserID: &actor}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.lastMutation.ActorID == nil || *repo.lastMutation.ActorID != actor {
+		t.Fatalf("mutation=%+v", repo.lastMutation)
+	}
+}
@@ -0,0 +1,107 @@
+package webhooks
+
+import (
+	"time"
+
+	"rdmm404/voltr-finance/internal/app/patch"
+)
+
+// Webhook subscribes a URL to outbox events. An empty EventTypes receives
+// every type, and a nil HouseholdID receives events from every household and
+// from personal records.
+type Webhook struct {
+	ID          int64
+	URL         string
+	EventTypes  []string
+	HouseholdID *int64
+	Secret      string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type CreateInput struct {
+	URL         string
+	EventTypes  []string
+	HouseholdID *int64
+	// Secret signs deliveries. A random one is generated when it is empty.
+	Secret string
+}
+
+type UpdateInput struct {
+	ID          int64
+	URL         *string
+	EventTypes  *[]string
+	HouseholdID patch.Field[int64]
+	Secret      *string
+	Active      *bool
+}
+
+type ListFilter struct {
+	HouseholdID *int64
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery is one outbox event sent to one webhook. A pending delivery is
+// retried at NextAttemptAt until it succeeds or runs out of attempts.
+type Delivery struct {
+	ID            int64
+	WebhookID     int64
+	EventID       int64
+	EventType     string
+	OccurredAt    time.Time
+	Status        DeliveryStatus
+	Attempts      int32
+	NextAttemptAt *time.Time
+	LastError     *string
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+	History       []Attempt
+}
+
+// Attempt records one request to the webhook URL. StatusCode is nil when no
+// response arrived.
+type Attempt struct {
+	AttemptedAt time.Time
+	StatusCode  *int32
+	Error       *string
+	Duration    time.Duration
+}
+
+// PendingDelivery is a due delivery claimed by the worker, carrying what it
+// needs to sign and send the request. Payload is the event's JSON data.
+type PendingDelivery struct {
+	ID          int64
+	WebhookID   int64
+	URL         string
+	Secret      string
+	EventID     int64
+	EventType   string
+	HouseholdID *int64
+	OccurredAt  time.Time
+	Payload     []byte
+	Attempts    int32
+}
+
+// AttemptResult records an attempt and moves its delivery to Status. A
+// pending result is retried at NextAttemptAt.
+type AttemptResult struct {
+	DeliveryID    int64
+	Attempt       Attempt
+	Status        DeliveryStatus
+	NextAttemptAt *time.Time
+}
+
+// Message is a signed webhook request.
+type Message struct {
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
@@ -0,0 +1,36 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+)
+
+// Repository owns webhook subscriptions and their deliveries. Outbox events
+// are written by the other repositories alongside the changes they describe.
+type Repository interface {
+	Create(context.Context, CreateInput) (Webhook, error)
+	Get(context.Context, int64) (Webhook, error)
+	List(context.Context, ListFilter) ([]Webhook, error)
+	Update(context.Context, UpdateInput) (Webhook, error)
+	Delete(context.Context, int64) error
+	// ListDeliveries returns a webhook's most recent deliveries first, with
+	// their attempt history.
+	ListDeliveries(ctx context.Context, webhookID int64, limit int32) ([]Delivery, error)
+	GetDelivery(context.Context, int64) (Delivery, error)
+	// Redeliver makes a delivery pending and due now with no attempts used.
+	Redeliver(context.Context, int64) error
+
+	// Dispatch turns up to limit outbox events into deliveries for the
+	// matching webhooks and reports how many events it handled.
+	Dispatch(ctx context.Context, limit int32) (int64, error)
+	// ClaimDue leases up to limit due deliveries until leaseUntil, so that
+	// no other worker claims them in the meantime.
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int32) ([]PendingDelivery, error)
+	RecordAttempt(context.Context, AttemptResult) error
+}
+
+// Sender posts a webhook request and returns the response status code. Only
+// transport failures are errors.
+type Sender interface {
+	Send(context.Context, Message) (int, error)
+}
@@ -0,0 +1,337 @@
+// Package webhooks posts outbox events to subscribed URLs. Each request is
+// signed with the webhook's secret, and failed deliveries are retried with
+// exponential backoff until they succeed or run out of attempts.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/app/events"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is sent before it fails.
+	MaxAttempts = 8
+
+	firstRetryDelay = 30 * time.Second
+	maxRetryDelay   = 6 * time.Hour
+	// sendTimeout bounds each delivery request, whatever the sender's own
+	// timeout is.
+	sendTimeout = 10 * time.Second
+	// deliveryLease must cover the whole claimed batch: claimSize deliveries
+	// are sent one after another, so it outlasts that many sendTimeouts with
+	// one to spare for recording the attempts. A lease that ran out mid-pass
+	// would let another worker claim and send the same delivery again.
+	deliveryLease       = 2 * time.Minute
+	claimSize     int32 = int32(deliveryLease/sendTimeout) - 1
+	batchSize           = 50
+	minSecretSize       = 16
+
+	defaultDeliveryLimit int32 = 20
+	maxDeliveryLimit     int32 = 100
+)
+
+// Signing headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Voltr-Event"
+	HeaderDelivery  = "X-Voltr-Delivery"
+	HeaderTimestamp = "X-Voltr-Timestamp"
+	HeaderSignature = "X-Voltr-Signature"
+)
+
+// EventTypes are the event types written to the outbox.
+var EventTypes = []string{
+	string(events.TransactionCreated),
+	string(events.TransactionUpdated),
+	string(events.TransactionDeleted),
+	string(events.TransactionRestored),
+}
+
+type Service struct {
+	repo   Repository
+	sender Sender
+	now    func() time.Time
+}
+
+// NewService builds the webhook service. The sender is only used by Deliver
+// and may be nil for callers that only manage subscriptions.
+func NewService(repo Repository, sender Sender) *Service {
+	return &Service{repo: repo, sender: sender, now: time.Now}
+}
+
+func (s *Service) Create(ctx context.Context, input CreateInput) (Webhook, error) {
+	address, err := webhookURL(input.URL)
+	if err != nil {
+		return Webhook{}, err
+	}
+	types, err := eventTypes(input.EventTypes)
+	if err != nil {
+		return Webhook{}, err
+	}
+	if input.HouseholdID != nil && *input.HouseholdID <= 0 {
+		return Webhook{}, apperrors.Validation("household id must be positive")
+	}
+	secret := strings.TrimSpace(input.Secret)
+	if secret == "" {
+		if secret, err = randomSecret(); err != nil {
+			return Webhook{}, apperrors.WrapInternal("generate webhook secret", err)
+		}
+	} else if len(secret) < minSecretSize {
+		return Webhook{}, apperrors.Validation(fmt.Sprintf("secret must be at least %d characters", minSecretSize))
+	}
+	input.URL, input.EventTypes, input.Secret = address, types, secret
+	item, err := s.repo.Create(ctx, input)
+	return item, apperrors.WrapInternal("create webhook", err)
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (Webhook, error) {
+	if id <= 0 {
+		return Webhook{}, apperrors.Validation("webhook id is required")
+	}
+	item, err := s.repo.Get(ctx, id)
+	return item, apperrors.WrapInternal("get webhook", err)
+}
+
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]Webhook, error) {
+	items, err := s.repo.List(ctx, filter)
+	if items == nil && err == nil {
+		items = []Webhook{}
+	}
+	return items, apperrors.WrapInternal("list webhooks", err)
+}
+
+func (s *Service) Update(ctx context.Context, input UpdateInput) (Webhook, error) {
+	if input.ID <= 0 {
+		return Webhook{}, apperrors.Validation("webhook id is required")
+	}
+	if input.URL == nil && input.EventTypes == nil && !input.HouseholdID.Present() && input.Secret == nil && input.Active == nil {
+		return Webhook{}, apperrors.Validation("at least one webhook field is required")
+	}
+	if input.URL != nil {
+		address, err := webhookURL(*input.URL)
+		if err != nil {
+			return Webhook{}, err
+		}
+		input.URL = &address
+	}
+	if input.EventTypes != nil {
+		types, err := eventTypes(*input.EventTypes)
+		if err != nil {
+			return Webhook{}, err
+		}
+		input.EventTypes = &types
+	}
+	if id := input.HouseholdID.Value(); id != nil && *id <= 0 {
+		return Webhook{}, apperrors.Validation("household id must be positive")
+	}
+	if input.Secret != nil {
+		secret := strings.TrimSpace(*input.Secret)
+		if len(secret) < minSecretSize {
+			return Webhook{}, apperrors.Validation(fmt.Sprintf("secret must be at least %d characters", minSecretSize))
+		}
+		input.Secret = &secret
+	}
+	item, err := s.repo.Update(ctx, input)
+	return item, apperrors.WrapInternal("update webhook", err)
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return apperrors.Validation("webhook id is required")
+	}
+	return apperrors.WrapInternal("delete webhook", s.repo.Delete(ctx, id))
+}
+
+// ListDeliveries returns the webhook's most recent deliveries with their
+// attempt history. A zero limit returns the last 20.
+func (s *Service) ListDeliveries(ctx context.Context, webhookID int64, limit int32) ([]Delivery, error) {
+	if limit < 0 || limit > maxDeliveryLimit {
+		return nil, apperrors.Validation(fmt.Sprintf("limit must be between 1 and %d", maxDeliveryLimit))
+	}
+	if limit == 0 {
+		limit = defaultDeliveryLimit
+	}
+	if _, err := s.Get(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListDeliveries(ctx, webhookID, limit)
+	if items == nil && err == nil {
+		items = []Delivery{}
+	}
+	return items, apperrors.WrapInternal("list webhook deliveries", err)
+}
+
+// Redeliver queues a delivery to be sent again on the worker's next pass,
+// whatever its status, with a fresh set of attempts.
+func (s *Service) Redeliver(ctx context.Context, id int64) (Delivery, error) {
+	if id <= 0 {
+		return Delivery{}, apperrors.Validation("delivery id is required")
+	}
+	if err := s.repo.Redeliver(ctx, id); err != nil {
+		return Delivery{}, apperrors.WrapInternal("redeliver webhook delivery", err)
+	}
+	item, err := s.repo.GetDelivery(ctx, id)
+	return item, apperrors.WrapInternal("get webhook delivery", err)
+}
+
+// Deliver runs one worker pass: it turns new outbox events into deliveries,
+// then sends the deliveries that are due and records each attempt. It
+// returns how many requests it sent. Failed requests are retried on a later
+// pass rather than returned as errors.
+func (s *Service) Deliver(ctx context.Context) (int, error) {
+	if s.sender == nil {
+		return 0, apperrors.Internal(fmt.Errorf("webhook sender is not configured"))
+	}
+	if _, err := s.repo.Dispatch(ctx, batchSize); err != nil {
+		return 0, apperrors.WrapInternal("dispatch webhook events", err)
+	}
+	now := s.now().UTC()
+	leaseUntil := now.Add(deliveryLease)
+	due, err := s.repo.ClaimDue(ctx, now, leaseUntil, claimSize)
+	if err != nil {
+		return 0, apperrors.WrapInternal("claim webhook deliveries", err)
+	}
+	for sent, delivery := range due {
+		// Slow attempts can still use up the lease. The deliveries left over
+		// are claimed again once it expires instead of being sent unleased.
+		if s.now().Add(sendTimeout).After(leaseUntil) {
+			return sent, nil
+		}
+		if err := s.repo.RecordAttempt(ctx, s.attempt(ctx, delivery)); err != nil {
+			return sent, apperrors.WrapInternal("record webhook delivery attempt", err)
+		}
+	}
+	return len(due), nil
+}
+
+func (s *Service) attempt(ctx context.Context, delivery PendingDelivery) AttemptResult {
+	started := s.now().UTC()
+	result := AttemptResult{DeliveryID: delivery.ID, Attempt: Attempt{AttemptedAt: started}}
+	var failure string
+	message, err := newMessage(delivery, started)
+	if err != nil {
+		failure = err.Error()
+	} else {
+		sendContext, cancel := context.WithTimeout(ctx, sendTimeout)
+		status, err := s.sender.Send(sendContext, message)
+		cancel()
+		result.Attempt.Duration = s.now().Sub(started)
+		switch {
+		case err != nil:
+			failure = err.Error()
+		case status < 200 || status > 299:
+			code := int32(status)
+			result.Attempt.StatusCode = &code
+			failure = fmt.Sprintf("webhook responded with status %d", status)
+		default:
+			code := int32(status)
+			result.Attempt.StatusCode = &code
+			result.Status = DeliverySucceeded
+			return result
+		}
+	}
+	result.Attempt.Error = &failure
+	if attempts := delivery.Attempts + 1; attempts < MaxAttempts {
+		next := started.Add(RetryDelay(attempts))
+		result.Status, result.NextAttemptAt = DeliveryPending, &next
+	} else {
+		result.Status = DeliveryFailed
+	}
+	return result
+}
+
+// RetryDelay is how long a delivery waits after its nth failed attempt:
+// 30 seconds, doubling each time up to six hours.
+func RetryDelay(attempts int32) time.Duration {
+	delay := firstRetryDelay
+	for range attempts - 1 {
+		if delay *= 2; delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// Sign returns the X-Voltr-Signature value for a delivery: the hex
+// HMAC-SHA256 of the timestamp header, a period and the raw body, keyed by the
+// webhook's secret. Receivers recompute it and compare in constant time.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// body is the JSON posted for every event. Data holds the record the event
+// describes as it stood when the change committed.
+type body struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	OccurredAt  time.Time       `json:"occurredAt"`
+	HouseholdID *int64          `json:"householdId,omitempty"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func newMessage(delivery PendingDelivery, at time.Time) (Message, error) {
+	encoded, err := json.Marshal(body{
+		ID: delivery.EventID, Type: delivery.EventType, OccurredAt: delivery.OccurredAt.UTC(),
+		HouseholdID: delivery.HouseholdID, Data: delivery.Payload,
+	})
+	if err != nil {
+		return Message{}, fmt.Errorf("encode webhook event: %w", err)
+	}
+	timestamp := at.Unix()
+	return Message{URL: delivery.URL, Body: encoded, Headers: map[string]string{
+		"Content-Type":  "application/json",
+		HeaderEvent:     delivery.EventType,
+		HeaderDelivery:  strconv.FormatInt(delivery.ID, 10),
+		HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+		HeaderSignature: Sign(delivery.Secret, timestamp, encoded),
+	}}, nil
+}
+
+func webhookURL(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", apperrors.Validation("url must be an absolute http or https URL")
+	}
+	return value, nil
+}
+
+// eventTypes validates a subscription's event types, dropping duplicates.
+// An empty list subscribes to every type.
+func eventTypes(values []string) ([]string, error) {
+	types := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if !slices.Contains(EventTypes, value) {
+			return nil, apperrors.Validation(fmt.Sprintf("event type must be one of %s", strings.Join(EventTypes, ", ")))
+		}
+		if !slices.Contains(types, value) {
+			types = append(types, value)
+		}
+	}
+	return types, nil
+}
+
+func randomSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,215 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/app/patch"
+)
+
+type fakeRepository struct {
+	created     CreateInput
+	updated     UpdateInput
+	getErr      error
+	dispatched  int32
+	due         []PendingDelivery
+	claimedNow  time.Time
+	claimedTill time.Time
+	claimLimit  int32
+	recorded    []AttemptResult
+	redelivered int64
+}
+
+func (f *fakeRepository) Create(_ context.Context, input CreateInput) (Webhook, error) {
+	f.created = input
+	return Webhook{ID: 5, URL: input.URL, EventTypes: input.EventTypes, Secret: input.Secret, Active: true}, nil
+}
+func (f *fakeRepository) Get(_ context.Context, id int64) (Webhook, error) {
+	return Webhook{ID: id}, f.getErr
+}
+func (f *fakeRepository) List(context.Context, ListFilter) ([]Webhook, error) { return nil, nil }
+func (f *fakeRepository) Update(_ context.Context, input UpdateInput) (Webhook, error) {
+	f.updated = input
+	return Webhook{ID: input.ID}, nil
+}
+func (f *fakeRepository) Delete(context.Context, int64) error { return nil }
+func (f *fakeRepository) ListDeliveries(context.Context, int64, int32) ([]Delivery, error) {
+	return nil, nil
+}
+func (f *fakeRepository) GetDelivery(_ context.Context, id int64) (Delivery, error) {
+	return Delivery{ID: id, Status: DeliveryPending}, nil
+}
+func (f *fakeRepository) Redeliver(_ context.Context, id int64) error {
+	f.redelivered = id
+	return nil
+}
+func (f *fakeRepository) Dispatch(_ context.Context, limit int32) (int64, error) {
+	f.dispatched = limit
+	return 0, nil
+}
+func (f *fakeRepository) ClaimDue(_ context.Context, now, leaseUntil time.Time, limit int32) ([]PendingDelivery, error) {
+	f.claimedNow, f.claimedTill, f.claimLimit = now, leaseUntil, limit
+	return f.due, nil
+}
+func (f *fakeRepository) RecordAttempt(_ context.Context, result AttemptResult) error {
+	f.recorded = append(f.recorded, result)
+	return nil
+}
+
+type fakeSender struct {
+	statuses []int
+	err      error
+	messages []Message
+}
+
+func (f *fakeSender) Send(_ context.Context, message Message) (int, error) {
+	f.messages = append(f.messages, message)
+	status := 0
+	if len(f.statuses) > 0 {
+		status, f.statuses = f.statuses[0], f.statuses[1:]
+	}
+	return status, f.err
+}
+
+func TestCreateValidatesAndGeneratesSecret(t *testing.T) {
+	repo := &fakeRepository{}
+	service := NewService(repo, nil)
+	item, err := service.Create(context.Background(), CreateInput{URL: " https://hooks.example.com/voltr ", EventTypes: []string{"transaction.created", "transaction.created"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.created.URL != "https://hooks.example.com/voltr" || !reflect.DeepEqual(repo.created.EventTypes, []string{"transaction.created"}) {
+		t.Fatalf("created=%+v", repo.created)
+	}
+	if len(item.Secret) != 64 {
+		t.Fatalf("generated secret=%q", item.Secret)
+	}
+	for name, input := range map[string]CreateInput{
+		"relative url":  {URL: "/hooks"},
+		"ftp url":       {URL: "ftp://example.com"},
+		"unknown type":  {URL: "https://example.com", EventTypes: []string{"budget.line.changed"}},
+		"short secret":  {URL: "https://example.com", Secret: "too-short"},
+		"bad household": {URL: "https://example.com", HouseholdID: new(int64)},
+	} {
+		if _, err := service.Create(context.Background(), input); apperrors.CodeOf(err) != apperrors.CodeValidation {
+			t.Fatalf("%s: err=%v", name, err)
+		}
+	}
+}
+
+func TestUpdateRequiresAFieldAndKeepsClears(t *testing.T) {
+	repo := &fakeRepository{}
+	service := NewService(repo, nil)
+	if _, err := service.Update(context.Background(), UpdateInput{ID: 5}); apperrors.CodeOf(err) != apperrors.CodeValidation {
+		t.Fatalf("empty update err=%v", err)
+	}
+	active := false
+	if _, err := service.Update(context.Background(), UpdateInput{ID: 5, Active: &active, HouseholdID: patch.Clear[int64]()}); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.updated.HouseholdID.Present() || repo.updated.HouseholdID.Value() != nil || *repo.updated.Active {
+		t.Fatalf("updated=%+v", repo.updated)
+	}
+}
+
+func TestListDeliveriesReportsUnknownWebhook(t *testing.T) {
+	repo := &fakeRepository{getErr: apperrors.NotFound(apperrors.CodeWebhookNotFound, "webhook not found", nil)}
+	_, err := NewService(repo, nil).ListDeliveries(context.Background(), 9, 0)
+	if apperrors.CodeOf(err) != apperrors.CodeWebhookNotFound {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestDeliverSignsRequestsAndSchedulesRetries(t *testing.T) {
+	householdID := int64(3)
+	occurred := time.Date(2026, 6, 9, 12, 0, 0, 0, time.UTC)
+	repo := &fakeRepository{due: []PendingDelivery{
+		{ID: 11, URL: "https://a.example.com", Secret: "first-secret-value", EventID: 7, EventType: "transaction.created", HouseholdID: &householdID, OccurredAt: occurred, Payload: []byte(`{"id":42}`)},
+		{ID: 12, URL: "https://b.example.com", Secret: "second-secret-value", EventID: 7, EventType: "transaction.created", OccurredAt: occurred, Payload: []byte(`{"id":42}`), Attempts: 2},
+		{ID: 13, URL: "https://c.example.com", Secret: "third-secret-value", EventID: 7, EventType: "transaction.created", OccurredAt: occurred, Payload: []byte(`{"id":42}`), Attempts: MaxAttempts - 1},
+	}}
+	sender := &fakeSender{statuses: []int{204, 500, 502}}
+	now := time.Date(2026, 6, 9, 12, 0, 5, 0, time.UTC)
+	service := NewService(repo, sender)
+	service.now = func() time.Time { return now }
+
+	sent, err := service.Deliver(context.Background())
+	if err != nil || sent != 3 {
+		t.Fatalf("sent=%d err=%v", sent, err)
+	}
+	if repo.dispatched != batchSize || !repo.claimedNow.Equal(now) || !repo.claimedTill.Equal(now.Add(deliveryLease)) {
+		t.Fatalf("repo=%+v", repo)
+	}
+	if time.Duration(repo.claimLimit)*sendTimeout >= deliveryLease {
+		t.Fatalf("claimed %d deliveries, more than a %s lease covers", repo.claimLimit, deliveryLease)
+	}
+
+	message := sender.messages[0]
+	if message.Headers[HeaderSignature] != Sign("first-secret-value", now.Unix(), message.Body) || message.Headers[HeaderDelivery] != "11" || message.Headers[HeaderEvent] != "transaction.created" {
+		t.Fatalf("headers=%v", message.Headers)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(message.Body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["id"] != float64(7) || decoded["householdId"] != float64(3) || decoded["data"].(map[string]any)["id"] != float64(42) {
+		t.Fatalf("body=%s", message.Body)
+	}
+
+	succeeded, retried, failed := repo.recorded[0], repo.recorded[1], repo.recorded[2]
+	if succeeded.Status != DeliverySucceeded || *succeeded.Attempt.StatusCode != 204 || succeeded.Attempt.Error != nil || succeeded.NextAttemptAt != nil {
+		t.Fatalf("succeeded=%+v", succeeded)
+	}
+	if retried.Status != DeliveryPending || !retried.NextAttemptAt.Equal(now.Add(2*time.Minute)) || *retried.Attempt.Error != "webhook responded with status 500" {
+		t.Fatalf("retried=%+v", retried)
+	}
+	if failed.Status != DeliveryFailed || failed.NextAttemptAt != nil {
+		t.Fatalf("failed=%+v", failed)
+	}
+}
+
+func TestDeliverRetriesTransportFailures(t *testing.T) {
+	repo := &fakeRepository{due: []PendingDelivery{{ID: 11, URL: "https://a.example.com", Secret: "first-secret-value", Payload: []byte(`{}`)}}}
+	service := NewService(repo, &fakeSender{err: errors.New("connection refused")})
+	if _, err := service.Deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	result := repo.recorded[0]
+	if result.Status != DeliveryPending || result.Attempt.StatusCode != nil || *result.Attempt.Error != "connection refused" {
+		t.Fatalf("result=%+v", result)
+	}
+}
+
+func TestDeliverLeavesDeliveriesTheLeaseNoLongerCovers(t *testing.T) {
+	repo := &fakeRepository{due: []PendingDelivery{
+		{ID: 11, URL: "https://a.example.com", Secret: "first-secret-value", Payload: []byte(`{}`)},
+		{ID: 12, URL: "https://b.example.com", Secret: "second-secret-value", Payload: []byte(`{}`)},
+	}}
+	sender := &fakeSender{statuses: []int{204, 204}}
+	service := NewService(repo, sender)
+	now := time.Date(2026, 6, 9, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time {
+		// Each clock reading is a quarter lease later, so the first attempt
+		// leaves too little of the lease for a second one.
+		current := now
+		now = now.Add(deliveryLease / 4)
+		return current
+	}
+	sent, err := service.Deliver(context.Background())
+	if err != nil || sent != 1 || len(sender.messages) != 1 || len(repo.recorded) != 1 || repo.recorded[0].DeliveryID != 11 {
+		t.Fatalf("sent=%d err=%v recorded=%+v", sent, err, repo.recorded)
+	}
+}
+
+func TestRetryDelayDoublesUpToSixHours(t *testing.T) {
+	for attempts, want := range map[int32]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 7: 32 * time.Minute, 20: 6 * time.Hour} {
+		if got := RetryDelay(attempts); got != want {
+			t.Fatalf("RetryDelay(%d)=%s want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package blobstore keeps attachment contents on the local filesystem or in
+// an S3-compatible bucket.
+package blobstore
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	appattachments "rdmm404/voltr-finance/internal/app/attachments"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Config selects the store. The S3 bucket is used when S3.Bucket is set and
+// the directory otherwise.
+type Config struct {
+	Dir string
+	S3  S3Config
+}
+
+// S3Config points at an S3-compatible bucket. PathStyle addresses the bucket
+// as the first path segment instead of a subdomain, which MinIO and most
+// self-hosted stores need.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool
+	Timeout         time.Duration
+}
+
+func (c S3Config) enabled() bool { return strings.TrimSpace(c.Bucket) != "" }
+
+func (c Config) Validate() error {
+	if !c.S3.enabled() {
+		if strings.TrimSpace(c.Dir) == "" {
+			return errors.New("attachment directory is required when no S3 bucket is configured")
+		}
+		return nil
+	}
+	var errs []error
+	parsed, err := url.Parse(c.S3.Endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.Path != "" && parsed.Path != "/" {
+		errs = append(errs, errors.New("attachment S3 endpoint must be an http or https URL without a path"))
+	}
+	if strings.TrimSpace(c.S3.Region) == "" {
+		errs = append(errs, errors.New("attachment S3 region is required"))
+	}
+	if c.S3.AccessKeyID == "" || c.S3.SecretAccessKey == "" {
+		errs = append(errs, errors.New("attachment S3 access key id and secret access key are required"))
+	}
+	if c.S3.Timeout < 0 {
+		errs = append(errs, errors.New("attachment S3 timeout cannot be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// New builds the store selected by the configuration.
+func New(cfg Config) (appattachments.BlobStore, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.S3.enabled() {
+		return NewS3(cfg.S3), nil
+	}
+	return NewFileSystem(cfg.Dir), nil
+}
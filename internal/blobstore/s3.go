@@ -0,0 +1,199 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	appattachments "rdmm404/voltr-finance/internal/app/attachments"
+)
+
+const (
+	amzDateFormat = "20060102T150405Z"
+	// emptySHA256 is the payload hash of a request without a body.
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3 stores blobs as objects in an S3-compatible bucket. Requests are signed
+// with AWS Signature Version 4.
+type S3 struct {
+	config   S3Config
+	endpoint *url.URL
+	http     *http.Client
+	now      func() time.Time
+}
+
+// NewS3 expects a configuration that passed Config.Validate.
+func NewS3(config S3Config) *S3 {
+	endpoint, _ := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return &S3{config: config, endpoint: endpoint, http: &http.Client{Timeout: timeout}, now: time.Now}
+}
+
+func (s *S3) Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error {
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return fmt.Errorf("read blob: %w", err)
+	}
+	if int64(len(body)) != size {
+		return fmt.Errorf("blob is %d bytes, expected %d", len(body), size)
+	}
+	request, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+	response, err := s.do(request, body)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	request, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.do(request, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	request, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	response, err := s.do(request, nil)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+func (s *S3) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	endpoint := *s.endpoint
+	if s.config.PathStyle {
+		endpoint.Path = "/" + s.config.Bucket + "/" + key
+		endpoint.RawPath = "/" + uriEncode(s.config.Bucket) + "/" + uriEncode(key)
+	} else {
+		endpoint.Host = s.config.Bucket + "." + endpoint.Host
+		endpoint.Path = "/" + key
+		endpoint.RawPath = "/" + uriEncode(key)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create S3 request: %w", err)
+	}
+	if body == nil {
+		request.Body, request.ContentLength = http.NoBody, 0
+	}
+	return request, nil
+}
+
+// do signs and sends request. Error responses, including a missing object,
+// are returned as errors with the S3 error code.
+func (s *S3) do(request *http.Request, body []byte) (*http.Response, error) {
+	payloadHash := emptySHA256
+	if body != nil {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	s.sign(request, payloadHash, s.now().UTC())
+	response, err := s.http.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("send S3 request: %w", err)
+	}
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return response, nil
+	}
+	defer response.Body.Close()
+	var failure struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	_ = xml.NewDecoder(io.LimitReader(response.Body, 64<<10)).Decode(&failure)
+	return nil, fmt.Errorf("S3 %s %s failed with status %d: %s %s", request.Method, request.URL.Path, response.StatusCode, failure.Code, failure.Message)
+}
+
+// sign adds the Signature Version 4 Authorization header. Every header set
+// on the request is signed, along with the host.
+func (s *S3) sign(request *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format(amzDateFormat)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		request.Method, request.URL.EscapedPath(), request.URL.Query().Encode(), canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := now.Format("20060102") + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256(canonicalRequest)
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), now.Format("20060102"))
+	for _, part := range []string{s.config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s", s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// uriEncode escapes every byte of an object path outside the unreserved set
+// and the slash, as SigV4 requires.
+func uriEncode(value string) string {
+	var encoded strings.Builder
+	for _, char := range []byte(value) {
+		switch {
+		case 'A' <= char && char <= 'Z', 'a' <= char && char <= 'z', '0' <= char && char <= '9',
+			char == '-', char == '_', char == '.', char == '~', char == '/':
+			encoded.WriteByte(char)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", char)
+		}
+	}
+	return encoded.String()
+}
+
+func hexSHA256(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+var _ appattachments.BlobStore = (*S3)(nil)
@@ -0,0 +1,22 @@
+package cli
+
+import "rdmm404/voltr-finance/internal/api"
+
+type AlertsCmd struct {
+	List AlertListCmd `cmd:"" help:"List budget alerts, newest first."`
+}
+
+type AlertListCmd struct {
+	HouseholdID *int64 `placeholder:"INT-64" help:"Only alerts on budgets owned by this household."`
+	UserID      *int64 `placeholder:"INT-64" help:"Only alerts on personal budgets of this user."`
+	BudgetID    *int64 `placeholder:"INT-64" help:"Only alerts on this budget."`
+	Limit       int    `help:"Maximum number of alerts to return. Defaults to 50."`
+}
+
+func (c *AlertListCmd) Run(ctx *runContext) error {
+	alerts, err := ctx.alerts.ListAlerts(ctx.Context, api.AlertQuery{HouseholdID: c.HouseholdID, UserID: c.UserID, BudgetID: c.BudgetID, Limit: c.Limit})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, alerts)
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type TransactionAttachCmd struct {
+	ID   int64  `required:"" help:"Internal transaction ID."`
+	File string `required:"" type:"existingfile" help:"Receipt photo or PDF to attach: JPEG, PNG, GIF, WebP or PDF up to 10 MiB."`
+}
+
+func (c *TransactionAttachCmd) Run(ctx *runContext) error {
+	file, err := os.Open(c.File)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", c.File, err)
+	}
+	defer file.Close()
+	attachment, err := ctx.attachments.UploadAttachment(ctx.Context, c.ID, filepath.Base(c.File), file)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, attachment)
+}
+
+type TransactionAttachmentsCmd struct {
+	ID int64 `required:"" help:"Internal transaction ID."`
+}
+
+func (c *TransactionAttachmentsCmd) Run(ctx *runContext) error {
+	attachments, err := ctx.attachments.ListAttachments(ctx.Context, c.ID)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, attachments)
+}
+
+type AttachmentsCmd struct {
+	Get      AttachmentGetCmd      `cmd:"" help:"Show one attachment."`
+	Download AttachmentDownloadCmd `cmd:"" help:"Download an attachment's file."`
+	Delete   AttachmentDeleteCmd   `cmd:"" help:"Delete an attachment and its file."`
+}
+
+type AttachmentGetCmd struct {
+	ID int64 `arg:"" required:"" help:"Attachment ID."`
+}
+
+func (c *AttachmentGetCmd) Run(ctx *runContext) error {
+	attachment, err := ctx.attachments.GetAttachment(ctx.Context, c.ID)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, attachment)
+}
+
+type AttachmentDownloadCmd struct {
+	ID     int64  `arg:"" required:"" help:"Attachment ID."`
+	Output string `short:"o" required:"" placeholder:"FILE" help:"File the attachment is written to. Use - for stdout."`
+}
+
+func (c *AttachmentDownloadCmd) Run(ctx *runContext) error {
+	if c.Output == "-" {
+		return ctx.attachments.DownloadAttachment(ctx.Context, c.ID, ctx.stdout)
+	}
+	// Download fully before creating the file so a failed request leaves no
+	// partial file behind.
+	var content bytes.Buffer
+	if err := ctx.attachments.DownloadAttachment(ctx.Context, c.ID, &content); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.Output, content.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", c.Output, err)
+	}
+	_, err := fmt.Fprintf(ctx.stderr, "Wrote attachment %d to %s\n", c.ID, c.Output)
+	return err
+}
+
+type AttachmentDeleteCmd struct {
+	ID int64 `arg:"" required:"" help:"Attachment ID."`
+}
+
+func (c *AttachmentDeleteCmd) Run(ctx *runContext) error {
+	return ctx.attachments.DeleteAttachment(ctx.Context, c.ID)
+}
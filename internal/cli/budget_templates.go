@@ -0,0 +1,135 @@
+package cli
+
+import "rdmm404/voltr-finance/internal/api"
+
+type BudgetTemplatesCmd struct {
+	List   BudgetTemplateListCmd   `cmd:"" help:"List budget templates."`
+	Get    BudgetTemplateGetCmd    `cmd:"" help:"Show one budget template."`
+	Create BudgetTemplateCreateCmd `cmd:"" help:"Create a budget template."`
+	Update BudgetTemplateUpdateCmd `cmd:"" help:"Rename a budget template or replace its lines."`
+	Delete BudgetTemplateDeleteCmd `cmd:"" help:"Delete a budget template."`
+	Save   BudgetTemplateSaveCmd   `cmd:"" help:"Save a budget's lines as a new template."`
+	Apply  BudgetTemplateApplyCmd  `cmd:"" help:"Merge a template into an existing budget."`
+}
+
+// templateLinesInput is the JSON document template create and update read
+// their lines from.
+type templateLinesInput struct {
+	Lines []api.BudgetTemplateLineRequest `json:"lines"`
+}
+
+type BudgetTemplateListCmd struct {
+	HouseholdID *int64 `placeholder:"INT-64" help:"Only templates owned by this household."`
+	UserID      *int64 `placeholder:"INT-64" help:"Only personal templates of this user."`
+}
+
+func (c *BudgetTemplateListCmd) Run(ctx *runContext) error {
+	templates, err := ctx.budgets.ListBudgetTemplates(ctx.Context, api.BudgetTemplateQuery{HouseholdID: c.HouseholdID, UserID: c.UserID})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, templates)
+}
+
+type BudgetTemplateGetCmd struct {
+	ID int64 `arg:"" required:"" help:"Budget template ID."`
+}
+
+func (c *BudgetTemplateGetCmd) Run(ctx *runContext) error {
+	template, err := ctx.budgets.GetBudgetTemplate(ctx.Context, c.ID)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, template)
+}
+
+type BudgetTemplateCreateCmd struct {
+	HouseholdID *int64  `placeholder:"INT-64" help:"Household template owner."`
+	UserID      *int64  `placeholder:"INT-64" help:"Personal template owner."`
+	Name        string  `required:"" help:"Template name, unique per owner."`
+	Input       *string `help:"Path to a JSON file with the template lines. Reads stdin when omitted. Expected shape: {\"lines\":[{\"name\":...,\"allocationAmount\":...,\"categoryCodes\":[...]}]}."`
+}
+
+func (c *BudgetTemplateCreateCmd) Run(ctx *runContext) error {
+	var input templateLinesInput
+	if err := decodeJSONInput(ctx.stdin, c.Input, &input); err != nil {
+		return err
+	}
+	template, err := ctx.budgets.CreateBudgetTemplate(ctx.Context, api.CreateBudgetTemplateRequest{
+		HouseholdID: c.HouseholdID, UserID: c.UserID, Name: c.Name, Lines: input.Lines,
+	})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, template)
+}
+
+type BudgetTemplateUpdateCmd struct {
+	ID    int64   `arg:"" required:"" help:"Budget template ID."`
+	Name  *string `help:"Replacement template name."`
+	Input *string `help:"Path to a JSON file with replacement lines, shaped like the create input. Lines are kept when omitted."`
+}
+
+func (c *BudgetTemplateUpdateCmd) Run(ctx *runContext) error {
+	request := api.UpdateBudgetTemplateRequest{Name: c.Name}
+	if c.Input != nil {
+		var input templateLinesInput
+		if err := decodeJSONInput(ctx.stdin, c.Input, &input); err != nil {
+			return err
+		}
+		request.Lines = &input.Lines
+	}
+	template, err := ctx.budgets.UpdateBudgetTemplate(ctx.Context, c.ID, request)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, template)
+}
+
+type BudgetTemplateDeleteCmd struct {
+	ID int64 `arg:"" required:"" help:"Budget template ID."`
+}
+
+func (c *BudgetTemplateDeleteCmd) Run(ctx *runContext) error {
+	return ctx.budgets.DeleteBudgetTemplate(ctx.Context, c.ID)
+}
+
+type BudgetTemplateSaveCmd struct {
+	BudgetID int64  `arg:"" required:"" help:"Budget ID."`
+	Name     string `required:"" help:"New template name, unique per owner."`
+}
+
+func (c *BudgetTemplateSaveCmd) Run(ctx *runContext) error {
+	template, err := ctx.budgets.SaveBudgetTemplate(ctx.Context, c.BudgetID, api.SaveBudgetTemplateRequest{Name: c.Name})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, template)
+}
+
+type BudgetTemplateApplyCmd struct {
+	BudgetID   int64  `arg:"" required:"" help:"Budget ID."`
+	Template   string `help:"Template name within the budget's owner."`
+	TemplateID *int64 `placeholder:"INT-64" help:"Template ID, instead of --template."`
+	Mode       string `default:"merge" enum:"merge,replace" help:"Keep (merge) or remove (replace) budget lines the template does not have."`
+	DryRun     bool   `help:"Show the changes without applying them."`
+	Format     string `default:"table" enum:"table,json" help:"Output format: table or json."`
+}
+
+func (c *BudgetTemplateApplyCmd) Run(ctx *runContext) error {
+	if (c.Template == "") == (c.TemplateID == nil) {
+		return NewCLIError("exactly one of --template or --template-id is required")
+	}
+	request := api.ApplyBudgetTemplateRequest{TemplateID: c.TemplateID, Mode: c.Mode, DryRun: c.DryRun}
+	if c.Template != "" {
+		request.TemplateName = &c.Template
+	}
+	application, err := ctx.budgets.ApplyBudgetTemplate(ctx.Context, c.BudgetID, request)
+	if err != nil {
+		return err
+	}
+	if c.Format == "json" {
+		return RenderJSON(ctx.stdout, application)
+	}
+	return RenderBudgetTemplateApplication(ctx.stdout, application)
+}
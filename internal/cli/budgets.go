@@ -1,28 +1,55 @@
 package cli
 
-import "rdmm404/voltr-finance/internal/api"
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"rdmm404/voltr-finance/internal/api"
+)
 
 type BudgetsCmd struct {
-	Get    BudgetGetCmd    `cmd:"" help:"Get a monthly budget."`
-	Report BudgetReportCmd `cmd:"" help:"Show a budget report."`
-	Lines  BudgetLinesCmd  `cmd:"" help:"Manage budget lines."`
+	Get       BudgetGetCmd       `cmd:"" help:"Get a budget for one period."`
+	Report    BudgetReportCmd    `cmd:"" help:"Show a budget report."`
+	Trends    BudgetTrendsCmd    `cmd:"" help:"Compare monthly budgets across a range of months."`
+	Compare   BudgetCompareCmd   `cmd:"" help:"Compare the lines of two budgets."`
+	Lint      BudgetLintCmd      `cmd:"" help:"Report category overlaps, unmapped categories and empty lines."`
+	Close     BudgetCloseCmd     `cmd:"" help:"Close a finished budget and freeze its report."`
+	Reopen    BudgetReopenCmd    `cmd:"" help:"Reopen a closed budget."`
+	Lines     BudgetLinesCmd     `cmd:"" help:"Manage budget lines."`
+	Templates BudgetTemplatesCmd `cmd:"" help:"Manage budget templates."`
 }
 
 type BudgetGetCmd struct {
 	HouseholdID *int64 `placeholder:"INT-64" help:"Household budget owner."`
 	UserID      *int64 `placeholder:"INT-64" help:"Personal budget owner."`
-	Month       string `required:"" help:"Budget month in YYYY-MM format."`
-	Create      bool   `help:"Create the monthly budget if missing."`
+	Month       string `help:"Budget month in YYYY-MM format."`
+	Period      string `default:"monthly" enum:"weekly,biweekly,monthly,quarterly,annual,custom" help:"Budget period kind (${enum})."`
+	Date        string `help:"Any date inside the period in YYYY-MM-DD format."`
+	Anchor      string `help:"Biweekly pay date the periods repeat from, in YYYY-MM-DD format."`
+	Start       string `help:"Custom period start in YYYY-MM-DD format."`
+	End         string `help:"Custom period end in YYYY-MM-DD format."`
+	Create      bool   `help:"Create the budget if missing."`
+	Template    string `help:"Budget template to create a missing budget from instead of the latest prior budget. Requires --create."`
 }
 
 func (c *BudgetGetCmd) Run(ctx *runContext) error {
+	if c.Template != "" && !c.Create {
+		return NewCLIError("--template requires --create")
+	}
+	if c.Month == "" {
+		return c.runPeriod(ctx)
+	}
+	if c.Period != "monthly" || c.Date != "" {
+		return NewCLIError("--month cannot be combined with --date or a non-monthly --period")
+	}
 	year, month, err := parseBudgetMonth(c.Month)
 	if err != nil {
 		return err
 	}
 	var budget api.Budget
 	if c.Create {
-		request := api.EnsureMonthlyBudgetRequest{HouseholdID: c.HouseholdID, UserID: c.UserID, Year: year, Month: month}
+		request := api.EnsureMonthlyBudgetRequest{HouseholdID: c.HouseholdID, UserID: c.UserID, Year: year, Month: month, Template: c.Template}
 		budget, err = ctx.budgets.EnsureMonthlyBudget(ctx.Context, request)
 	} else {
 		query := api.MonthlyBudgetQuery{HouseholdID: c.HouseholdID, UserID: c.UserID, Year: year, Month: month}
@@ -34,22 +61,144 @@ func (c *BudgetGetCmd) Run(ctx *runContext) error {
 	return RenderJSON(ctx.stdout, budget)
 }
 
+func (c *BudgetGetCmd) runPeriod(ctx *runContext) error {
+	query := api.BudgetPeriodQuery{
+		HouseholdID: c.HouseholdID, UserID: c.UserID, Period: c.Period,
+		Date: c.Date, Anchor: c.Anchor, Start: c.Start, End: c.End, Template: c.Template,
+	}
+	var budget api.Budget
+	var err error
+	if c.Create {
+		budget, err = ctx.budgets.EnsureBudget(ctx.Context, query)
+	} else {
+		budget, err = ctx.budgets.GetBudget(ctx.Context, query)
+	}
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, budget)
+}
+
 type BudgetReportCmd struct {
-	ID int64 `arg:"" required:"" help:"Budget ID."`
+	ID     int64  `arg:"" required:"" help:"Budget ID."`
+	Format string `default:"json" enum:"json,pdf,xlsx" help:"Output format: json, or a pdf or xlsx document of the detailed report."`
+	Output string `short:"o" placeholder:"FILE" help:"File the pdf or xlsx document is written to. Use - for stdout."`
 }
 
 func (c *BudgetReportCmd) Run(ctx *runContext) error {
-	report, err := ctx.budgets.GetBudgetReport(ctx.Context, c.ID)
+	if c.Format == api.BudgetReportFormatJSON {
+		if c.Output != "" {
+			return NewCLIError("--output only applies to the pdf and xlsx formats")
+		}
+		report, err := ctx.budgets.GetBudgetReport(ctx.Context, c.ID)
+		if err != nil {
+			return err
+		}
+		return RenderJSON(ctx.stdout, report)
+	}
+	switch c.Output {
+	case "":
+		return NewCLIError(fmt.Sprintf("--output is required for the %s format; use - for stdout", c.Format))
+	case "-":
+		return ctx.budgets.ExportBudgetReport(ctx.Context, c.ID, c.Format, ctx.stdout)
+	}
+	// Download fully before creating the file so a failed request leaves no
+	// partial document behind.
+	var document bytes.Buffer
+	if err := ctx.budgets.ExportBudgetReport(ctx.Context, c.ID, c.Format, &document); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.Output, document.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", c.Output, err)
+	}
+	_, err := fmt.Fprintf(ctx.stderr, "Wrote %s report for budget %d to %s\n", c.Format, c.ID, c.Output)
+	return err
+}
+
+type BudgetTrendsCmd struct {
+	HouseholdID *int64 `placeholder:"INT-64" help:"Household budget owner."`
+	UserID      *int64 `placeholder:"INT-64" help:"Personal budget owner."`
+	From        string `required:"" help:"First month in YYYY-MM format."`
+	To          string `required:"" help:"Last month in YYYY-MM format; the range spans at most 24 months."`
+	Format      string `default:"table" enum:"table,json" help:"Output format: table or json."`
+}
+
+func (c *BudgetTrendsCmd) Run(ctx *runContext) error {
+	trends, err := ctx.budgets.GetBudgetTrends(ctx.Context, api.BudgetTrendQuery{HouseholdID: c.HouseholdID, UserID: c.UserID, From: c.From, To: c.To})
+	if err != nil {
+		return err
+	}
+	if c.Format == "json" {
+		return RenderJSON(ctx.stdout, trends)
+	}
+	return RenderBudgetTrendsTable(ctx.stdout, trends)
+}
+
+type BudgetCompareCmd struct {
+	A      int64  `arg:"" required:"" help:"Budget ID to compare against, such as last month's budget."`
+	B      int64  `arg:"" required:"" help:"Budget ID to compare."`
+	Format string `default:"table" enum:"table,json" help:"Output format: table or json."`
+}
+
+func (c *BudgetCompareCmd) Run(ctx *runContext) error {
+	comparison, err := ctx.budgets.CompareBudgets(ctx.Context, api.BudgetComparisonQuery{A: c.A, B: c.B})
+	if err != nil {
+		return err
+	}
+	if c.Format == "json" {
+		return RenderJSON(ctx.stdout, comparison)
+	}
+	return RenderBudgetComparison(ctx.stdout, comparison)
+}
+
+type BudgetLintCmd struct {
+	ID     int64  `arg:"" required:"" help:"Budget ID."`
+	Format string `default:"table" enum:"table,json" help:"Output format: table or json."`
+}
+
+func (c *BudgetLintCmd) Run(ctx *runContext) error {
+	lint, err := ctx.budgets.GetBudgetLint(ctx.Context, c.ID)
 	if err != nil {
 		return err
 	}
-	return RenderJSON(ctx.stdout, report)
+	if c.Format == "json" {
+		return RenderJSON(ctx.stdout, lint)
+	}
+	return RenderBudgetLintTable(ctx.stdout, lint)
+}
+
+type BudgetCloseCmd struct {
+	ID     int64 `arg:"" required:"" help:"Budget ID."`
+	UserID int64 `required:"" placeholder:"INT-64" help:"Internal user ID of the person closing the budget."`
+}
+
+func (c *BudgetCloseCmd) Run(ctx *runContext) error {
+	closing, err := ctx.budgets.CloseBudget(ctx.Context, c.ID, api.CloseBudgetRequest{UserID: c.UserID})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, closing)
+}
+
+type BudgetReopenCmd struct {
+	ID     int64  `arg:"" required:"" help:"Budget ID."`
+	UserID int64  `required:"" placeholder:"INT-64" help:"Internal user ID of the person reopening the budget."`
+	Reason string `required:"" help:"Why the budget is being reopened."`
+}
+
+func (c *BudgetReopenCmd) Run(ctx *runContext) error {
+	closing, err := ctx.budgets.ReopenBudget(ctx.Context, c.ID, api.ReopenBudgetRequest{UserID: c.UserID, Reason: c.Reason})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, closing)
 }
 
 type BudgetLinesCmd struct {
 	Add    BudgetLineAddCmd    `cmd:"" help:"Add a budget line."`
 	Update BudgetLineUpdateCmd `cmd:"" help:"Update a budget line."`
 	Delete BudgetLineDeleteCmd `cmd:"" help:"Delete a budget line."`
+	Move   BudgetLineMoveCmd   `cmd:"" help:"Move allocation from one budget line to another."`
 }
 
 type BudgetLineAddCmd struct {
@@ -58,14 +207,26 @@ type BudgetLineAddCmd struct {
 	Amount     string  `required:"" help:"Allocation amount."`
 	Categories *string `help:"Comma-separated category codes."`
 	SortOrder  *int32  `help:"Display sort order."`
+	Alerts     string  `placeholder:"80,100" help:"Comma-separated percentages of the allocation that raise an alert."`
+	Members    string  `placeholder:"2=150.00,3=40%" help:"Comma-separated household member shares as USER-ID=AMOUNT or USER-ID=PERCENT%."`
 }
 
 func (c *BudgetLineAddCmd) Run(ctx *runContext) error {
+	thresholds, err := parseThresholds(c.Alerts)
+	if err != nil {
+		return err
+	}
+	members, err := parseMemberAllocations(c.Members)
+	if err != nil {
+		return err
+	}
 	line, err := ctx.budgets.CreateBudgetLine(ctx.Context, c.BudgetID, api.CreateBudgetLineRequest{
-		Name:             c.Name,
-		AllocationAmount: c.Amount,
-		CategoryCodes:    parseOptionalCSV(c.Categories),
-		SortOrder:        c.SortOrder,
+		Name:              c.Name,
+		AllocationAmount:  c.Amount,
+		CategoryCodes:     parseOptionalCSV(c.Categories),
+		SortOrder:         c.SortOrder,
+		AlertThresholds:   thresholds,
+		MemberAllocations: members,
 	})
 	if err != nil {
 		return err
@@ -79,6 +240,9 @@ type BudgetLineUpdateCmd struct {
 	Amount     *string `help:"Replacement allocation amount."`
 	Categories *string `help:"Replacement comma-separated category codes."`
 	SortOrder  *int32  `help:"Replacement display sort order."`
+	Alerts     *string `placeholder:"80,100" help:"Replacement comma-separated alert percentages. Pass an empty value to remove all alerts."`
+	Members    *string `placeholder:"2=150.00,3=40%" help:"Replacement household member shares. Pass an empty value to remove all member allocations."`
+	IfMatch    *int64  `placeholder:"VERSION" help:"Only update while the line is still at this version; fails otherwise."`
 }
 
 func (c *BudgetLineUpdateCmd) Run(ctx *runContext) error {
@@ -87,12 +251,30 @@ func (c *BudgetLineUpdateCmd) Run(ctx *runContext) error {
 		parsed := parseOptionalCSV(c.Categories)
 		categoryCodes = &parsed
 	}
+	var thresholds *[]int32
+	if c.Alerts != nil {
+		parsed, err := parseThresholds(*c.Alerts)
+		if err != nil {
+			return err
+		}
+		thresholds = &parsed
+	}
+	var members *[]api.BudgetMemberAllocationInput
+	if c.Members != nil {
+		parsed, err := parseMemberAllocations(*c.Members)
+		if err != nil {
+			return err
+		}
+		members = &parsed
+	}
 	line, err := ctx.budgets.UpdateBudgetLine(ctx.Context, c.ID, api.UpdateBudgetLineRequest{
-		Name:             c.Name,
-		AllocationAmount: c.Amount,
-		CategoryCodes:    categoryCodes,
-		SortOrder:        c.SortOrder,
-	})
+		Name:              c.Name,
+		AllocationAmount:  c.Amount,
+		CategoryCodes:     categoryCodes,
+		SortOrder:         c.SortOrder,
+		AlertThresholds:   thresholds,
+		MemberAllocations: members,
+	}, ifMatch(c.IfMatch)...)
 	if err != nil {
 		return err
 	}
@@ -100,9 +282,33 @@ func (c *BudgetLineUpdateCmd) Run(ctx *runContext) error {
 }
 
 type BudgetLineDeleteCmd struct {
-	ID int64 `arg:"" required:"" help:"Budget line ID."`
+	ID      int64  `arg:"" required:"" help:"Budget line ID."`
+	IfMatch *int64 `placeholder:"VERSION" help:"Only delete while the line is still at this version; fails otherwise."`
 }
 
 func (c *BudgetLineDeleteCmd) Run(ctx *runContext) error {
-	return ctx.budgets.DeleteBudgetLine(ctx.Context, c.ID)
+	return ctx.budgets.DeleteBudgetLine(ctx.Context, c.ID, ifMatch(c.IfMatch)...)
+}
+
+type BudgetLineMoveCmd struct {
+	BudgetID int64   `required:"" placeholder:"INT-64" help:"Budget ID."`
+	From     int64   `required:"" placeholder:"INT-64" help:"Budget line ID to take the allocation from."`
+	To       int64   `required:"" placeholder:"INT-64" help:"Budget line ID to give the allocation to."`
+	Amount   string  `required:"" help:"Allocation amount to move."`
+	Reason   *string `help:"Why the allocation moved."`
+	UserID   int64   `required:"" placeholder:"INT-64" help:"Internal user ID of the person moving the allocation."`
+}
+
+func (c *BudgetLineMoveCmd) Run(ctx *runContext) error {
+	result, err := ctx.budgets.ReallocateBudget(ctx.Context, c.BudgetID, api.ReallocateBudgetRequest{
+		FromLineID: c.From,
+		ToLineID:   c.To,
+		Amount:     c.Amount,
+		Reason:     c.Reason,
+		UserID:     c.UserID,
+	})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, result)
 }
@@ -40,12 +40,13 @@ func (c *CategoryListCmd) Run(ctx *runContext) error {
 }
 
 type CategoryRenameCmd struct {
-	Code string `arg:"" required:"" help:"Existing category code."`
-	Name string `arg:"" required:"" help:"New category display name."`
+	Code    string `arg:"" required:"" help:"Existing category code."`
+	Name    string `arg:"" required:"" help:"New category display name."`
+	IfMatch *int64 `placeholder:"VERSION" help:"Only rename while the category is still at this version; fails otherwise."`
 }
 
 func (c *CategoryRenameCmd) Run(ctx *runContext) error {
-	category, err := ctx.categories.UpdateCategory(ctx.Context, c.Code, api.UpdateCategoryRequest{Name: &c.Name})
+	category, err := ctx.categories.UpdateCategory(ctx.Context, c.Code, api.UpdateCategoryRequest{Name: &c.Name}, ifMatch(c.IfMatch)...)
 	if err != nil {
 		return err
 	}
@@ -53,11 +54,12 @@ func (c *CategoryRenameCmd) Run(ctx *runContext) error {
 }
 
 type CategoryDeactivateCmd struct {
-	Code string `arg:"" required:"" help:"Category code to deactivate."`
+	Code    string `arg:"" required:"" help:"Category code to deactivate."`
+	IfMatch *int64 `placeholder:"VERSION" help:"Only deactivate while the category is still at this version; fails otherwise."`
 }
 
 func (c *CategoryDeactivateCmd) Run(ctx *runContext) error {
-	category, err := ctx.categories.DeactivateCategory(ctx.Context, c.Code)
+	category, err := ctx.categories.DeactivateCategory(ctx.Context, c.Code, ifMatch(c.IfMatch)...)
 	if err != nil {
 		return err
 	}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 	"strconv"
 	"strings"
@@ -20,8 +21,15 @@ import (
 type transactionClient interface {
 	CreateTransaction(context.Context, api.CreateTransactionRequest) (api.Transaction, error)
 	CreateTransactions(context.Context, api.BulkCreateTransactionsRequest) (api.BulkResult, error)
-	ListTransactions(context.Context, api.ListTransactionsQuery) ([]api.Transaction, error)
-	UpdateTransaction(context.Context, int64, api.UpdateTransactionRequest) (api.Transaction, error)
+	ListTransactions(context.Context, api.ListTransactionsQuery) (api.TransactionPage, error)
+	AllTransactions(context.Context, api.ListTransactionsQuery) iter.Seq2[api.Transaction, error]
+	UpdateTransaction(context.Context, int64, api.UpdateTransactionRequest, ...restclient.RequestOption) (api.Transaction, error)
+	TransactionHistory(context.Context, int64) ([]api.TransactionHistoryEntry, error)
+	TransactionTagTotals(context.Context, api.TagTotalsQuery) ([]api.TagTotal, error)
+	AggregateTransactions(context.Context, api.AggregateTransactionsQuery) (api.TransactionAggregate, error)
+	ListSavedFilters(context.Context) ([]api.SavedFilter, error)
+	SaveFilter(context.Context, string, api.SaveFilterRequest) (api.SavedFilter, error)
+	DeleteSavedFilter(context.Context, string) error
 	UpdateTransactions(context.Context, api.BulkUpdateTransactionsRequest) (api.BulkResult, error)
 	DeleteTransactions(context.Context, api.DeleteTransactionsRequest) (api.BulkResult, error)
 	RestoreTransactions(context.Context, api.RestoreTransactionsRequest) (api.BulkResult, error)
@@ -45,17 +53,67 @@ type householdClient interface {
 type categoryClient interface {
 	CreateCategory(context.Context, api.CreateCategoryRequest) (api.Category, error)
 	ListCategories(context.Context, api.ListCategoriesQuery) ([]api.Category, error)
-	UpdateCategory(context.Context, string, api.UpdateCategoryRequest) (api.Category, error)
-	DeactivateCategory(context.Context, string) (api.Category, error)
+	UpdateCategory(context.Context, string, api.UpdateCategoryRequest, ...restclient.RequestOption) (api.Category, error)
+	DeactivateCategory(context.Context, string, ...restclient.RequestOption) (api.Category, error)
 }
 
 type budgetClient interface {
 	GetMonthlyBudget(context.Context, api.MonthlyBudgetQuery) (api.Budget, error)
 	EnsureMonthlyBudget(context.Context, api.EnsureMonthlyBudgetRequest) (api.Budget, error)
+	GetBudget(context.Context, api.BudgetPeriodQuery) (api.Budget, error)
+	EnsureBudget(context.Context, api.BudgetPeriodQuery) (api.Budget, error)
 	CreateBudgetLine(context.Context, int64, api.CreateBudgetLineRequest) (api.BudgetLine, error)
-	UpdateBudgetLine(context.Context, int64, api.UpdateBudgetLineRequest) (api.BudgetLine, error)
-	DeleteBudgetLine(context.Context, int64) error
+	UpdateBudgetLine(context.Context, int64, api.UpdateBudgetLineRequest, ...restclient.RequestOption) (api.BudgetLine, error)
+	DeleteBudgetLine(context.Context, int64, ...restclient.RequestOption) error
+	ReallocateBudget(context.Context, int64, api.ReallocateBudgetRequest) (api.BudgetReallocationResult, error)
+	CloseBudget(context.Context, int64, api.CloseBudgetRequest) (api.BudgetClosing, error)
+	ReopenBudget(context.Context, int64, api.ReopenBudgetRequest) (api.BudgetClosing, error)
 	GetBudgetReport(context.Context, int64) (api.BudgetReport, error)
+	ExportBudgetReport(context.Context, int64, string, io.Writer) error
+	GetBudgetTrends(context.Context, api.BudgetTrendQuery) (api.BudgetTrends, error)
+	CompareBudgets(context.Context, api.BudgetComparisonQuery) (api.BudgetComparison, error)
+	GetBudgetLint(context.Context, int64) (api.BudgetLint, error)
+	CreateBudgetTemplate(context.Context, api.CreateBudgetTemplateRequest) (api.BudgetTemplate, error)
+	ListBudgetTemplates(context.Context, api.BudgetTemplateQuery) ([]api.BudgetTemplate, error)
+	GetBudgetTemplate(context.Context, int64) (api.BudgetTemplate, error)
+	UpdateBudgetTemplate(context.Context, int64, api.UpdateBudgetTemplateRequest) (api.BudgetTemplate, error)
+	DeleteBudgetTemplate(context.Context, int64) error
+	SaveBudgetTemplate(context.Context, int64, api.SaveBudgetTemplateRequest) (api.BudgetTemplate, error)
+	ApplyBudgetTemplate(context.Context, int64, api.ApplyBudgetTemplateRequest) (api.BudgetTemplateApplication, error)
+}
+
+type goalClient interface {
+	CreateGoal(context.Context, api.CreateGoalRequest) (api.Goal, error)
+	ListGoals(context.Context, api.GoalQuery) ([]api.Goal, error)
+	GetGoal(context.Context, int64, string) (api.Goal, error)
+	UpdateGoal(context.Context, int64, api.UpdateGoalRequest) (api.Goal, error)
+	DeleteGoal(context.Context, int64) error
+}
+
+type alertClient interface {
+	ListAlerts(context.Context, api.AlertQuery) ([]api.BudgetAlert, error)
+}
+
+type eventClient interface {
+	Events(context.Context, api.EventsQuery) iter.Seq2[api.Event, error]
+}
+
+type webhookClient interface {
+	CreateWebhook(context.Context, api.CreateWebhookRequest) (api.Webhook, error)
+	ListWebhooks(context.Context, api.WebhookQuery) ([]api.Webhook, error)
+	GetWebhook(context.Context, int64) (api.Webhook, error)
+	UpdateWebhook(context.Context, int64, api.UpdateWebhookRequest) (api.Webhook, error)
+	DeleteWebhook(context.Context, int64) error
+	ListWebhookDeliveries(context.Context, int64, api.WebhookDeliveryQuery) ([]api.WebhookDelivery, error)
+	RedeliverWebhookDelivery(context.Context, int64) (api.WebhookDelivery, error)
+}
+
+type attachmentClient interface {
+	UploadAttachment(ctx context.Context, transactionID int64, fileName string, content io.Reader) (api.Attachment, error)
+	ListAttachments(ctx context.Context, transactionID int64) ([]api.Attachment, error)
+	GetAttachment(context.Context, int64) (api.Attachment, error)
+	DownloadAttachment(context.Context, int64, io.Writer) error
+	DeleteAttachment(context.Context, int64) error
 }
 
 type APIClient interface {
@@ -64,6 +122,11 @@ type APIClient interface {
 	householdClient
 	categoryClient
 	budgetClient
+	goalClient
+	alertClient
+	eventClient
+	webhookClient
+	attachmentClient
 }
 
 var _ APIClient = (*restclient.Client)(nil)
@@ -74,6 +137,11 @@ type CLI struct {
 	Households   HouseholdsCmd   `cmd:"" help:"Read households."`
 	Categories   CategoriesCmd   `cmd:"" help:"Manage transaction categories."`
 	Budgets      BudgetsCmd      `cmd:"" help:"Manage budgets."`
+	Goals        GoalsCmd        `cmd:"" help:"Manage savings goals."`
+	Alerts       AlertsCmd       `cmd:"" help:"Read budget overspend alerts."`
+	Events       EventsCmd       `cmd:"" help:"Follow live finance events."`
+	Webhooks     WebhooksCmd     `cmd:"" help:"Manage outbound webhooks."`
+	Attachments  AttachmentsCmd  `cmd:"" help:"Read and delete transaction attachments."`
 }
 
 type runContext struct {
@@ -86,6 +154,11 @@ type runContext struct {
 	households   householdClient
 	categories   categoryClient
 	budgets      budgetClient
+	goals        goalClient
+	alerts       alertClient
+	events       eventClient
+	webhooks     webhookClient
+	attachments  attachmentClient
 }
 
 func Run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, client APIClient) int {
@@ -109,7 +182,7 @@ func Run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer,
 	if isHelpArgs(args) {
 		return 0
 	}
-	if err := kctx.Run(&runContext{Context: ctx, stdin: stdin, stdout: stdout, stderr: stderr, transactions: client, users: client, households: client, categories: client, budgets: client}); err != nil {
+	if err := kctx.Run(&runContext{Context: ctx, stdin: stdin, stdout: stdout, stderr: stderr, transactions: client, users: client, households: client, categories: client, budgets: client, goals: client, alerts: client, events: client, webhooks: client, attachments: client}); err != nil {
 		if isExpectedError(err) {
 			fmt.Fprintln(stderr, expectedErrorMessage(err))
 			return 2
@@ -166,6 +239,14 @@ func expectedErrorMessage(err error) string {
 	return err.Error()
 }
 
+// ifMatch turns an optional --if-match version into request options.
+func ifMatch(version *int64) []restclient.RequestOption {
+	if version == nil {
+		return nil
+	}
+	return []restclient.RequestOption{restclient.IfMatch(*version)}
+}
+
 func decodeJSONInput(stdin io.Reader, input *string, value any) error {
 	reader := stdin
 	if input != nil {
@@ -204,6 +285,48 @@ func parseIDs(raw string) ([]int64, error) {
 	return ids, nil
 }
 
+// parseThresholds reads comma-separated alert percentages; an empty value
+// yields an empty list so updates can clear every threshold.
+func parseThresholds(value string) ([]int32, error) {
+	parts := parseOptionalCSV(&value)
+	thresholds := make([]int32, 0, len(parts))
+	for _, part := range parts {
+		parsed, err := strconv.ParseInt(strings.TrimSuffix(part, "%"), 10, 32)
+		if err != nil {
+			return nil, NewCLIError(fmt.Sprintf("alert threshold %q must be a whole percentage", part))
+		}
+		thresholds = append(thresholds, int32(parsed))
+	}
+	return thresholds, nil
+}
+
+// parseMemberAllocations reads comma-separated USER-ID=AMOUNT or
+// USER-ID=PERCENT% shares; an empty value yields an empty list so updates can
+// clear every member allocation.
+func parseMemberAllocations(value string) ([]api.BudgetMemberAllocationInput, error) {
+	parts := parseOptionalCSV(&value)
+	members := make([]api.BudgetMemberAllocationInput, 0, len(parts))
+	for _, part := range parts {
+		rawID, share, ok := strings.Cut(part, "=")
+		share = strings.TrimSpace(share)
+		if !ok || share == "" {
+			return nil, NewCLIError(fmt.Sprintf("member allocation %q must be USER-ID=AMOUNT or USER-ID=PERCENT%%", part))
+		}
+		userID, err := strconv.ParseInt(strings.TrimSpace(rawID), 10, 64)
+		if err != nil {
+			return nil, NewCLIError(fmt.Sprintf("member allocation %q must start with a user ID", part))
+		}
+		member := api.BudgetMemberAllocationInput{UserID: userID}
+		if percent, isPercent := strings.CutSuffix(share, "%"); isPercent {
+			member.Percent = &percent
+		} else {
+			member.Amount = &share
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
 func parseBudgetMonth(value string) (int, int, error) {
 	parsed, err := time.Parse("2006-01", value)
 	if err != nil {
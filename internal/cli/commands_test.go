@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -28,12 +31,22 @@ func TestEveryCommandUsesOneAuthenticatedFeatureRequest(t *testing.T) {
 	}{
 		{"transaction create", http.MethodPost, "/v1/transactions", []string{"transactions", "create", "--amount=12.5", "--transaction-date=2026-07-01T00:00:00Z", "--household-id=2", "--author-id=1"}, "", `{}`, 200},
 		{"transaction create bulk", http.MethodPost, "/v1/transactions/bulk", []string{"transactions", "create-bulk"}, `{"transactions":[]}`, `{"succeeded":[],"failed":[]}`, 200},
-		{"transaction update", http.MethodPatch, "/v1/transactions/1", []string{"transactions", "update", "--id=1", "--amount=13"}, "", `{}`, 200},
+		{"transaction update", http.MethodPatch, "/v1/transactions/1", []string{"transactions", "update", "--id=1", "--amount=13", "--updated-by-user-id=2"}, "", `{}`, 200},
 		{"transaction update bulk", http.MethodPatch, "/v1/transactions/bulk", []string{"transactions", "update-bulk"}, `{"transactions":[]}`, `{"succeeded":[],"failed":[]}`, 200},
-		{"transaction get", http.MethodGet, "/v1/transactions", []string{"transactions", "get", "--ids=1"}, "", `[]`, 200},
-		{"transaction list", http.MethodGet, "/v1/transactions", []string{"transactions", "list"}, "", `[]`, 200},
+		{"transaction get", http.MethodGet, "/v1/transactions", []string{"transactions", "get", "--ids=1"}, "", `{"items":[]}`, 200},
+		{"transaction list", http.MethodGet, "/v1/transactions", []string{"transactions", "list"}, "", `{"items":[]}`, 200},
 		{"transaction delete", http.MethodDelete, "/v1/transactions", []string{"transactions", "delete", "--ids=1", "--deleted-by-user-id=2"}, "", `{"succeeded":[],"failed":[]}`, 200},
 		{"transaction restore", http.MethodPost, "/v1/transactions/restore", []string{"transactions", "restore", "--ids=1", "--restored-by-user-id=2"}, "", `{"succeeded":[],"failed":[]}`, 200},
+		{"transaction tags", http.MethodGet, "/v1/transactions/tags", []string{"transactions", "tags", "--household-id=1"}, "", `[]`, 200},
+		{"transaction aggregate", http.MethodGet, "/v1/transactions/aggregate", []string{"transactions", "aggregate", "--by=category,month"}, "", `{"by":["category","month"],"metrics":["sum","count"],"groups":[]}`, 200},
+		{"transaction filters list", http.MethodGet, "/v1/saved-filters", []string{"transactions", "filters", "list"}, "", `[]`, 200},
+		{"transaction filters save", http.MethodPut, "/v1/saved-filters/big-spend", []string{"transactions", "filters", "save", "big-spend", "--query=amount > 100"}, "", `{}`, 200},
+		{"transaction filters delete", http.MethodDelete, "/v1/saved-filters/big-spend", []string{"transactions", "filters", "delete", "big-spend"}, "", "", http.StatusNoContent},
+		{"transaction history", http.MethodGet, "/v1/transactions/1/history", []string{"transactions", "history", "--id=1"}, "", `[]`, 200},
+		{"transaction attachments", http.MethodGet, "/v1/transactions/1/attachments", []string{"transactions", "attachments", "--id=1"}, "", `[]`, 200},
+		{"attachment get", http.MethodGet, "/v1/attachments/3", []string{"attachments", "get", "3"}, "", `{}`, 200},
+		{"attachment download to stdout", http.MethodGet, "/v1/attachments/3/content", []string{"attachments", "download", "3", "--output=-"}, "", "%PDF-1.7", 200},
+		{"attachment delete", http.MethodDelete, "/v1/attachments/3", []string{"attachments", "delete", "3"}, "", "", http.StatusNoContent},
 		{"user create", http.MethodPost, "/v1/users", []string{"users", "create", "--name=Alice"}, "", `{}`, 200},
 		{"user update", http.MethodPatch, "/v1/users/1", []string{"users", "update", "--id=1", "--name=Bob"}, "", `{}`, 200},
 		{"user get", http.MethodGet, "/v1/users/1", []string{"users", "get", "--id=1"}, "", `{}`, 200},
@@ -47,10 +60,41 @@ func TestEveryCommandUsesOneAuthenticatedFeatureRequest(t *testing.T) {
 		{"category rename", http.MethodPatch, "/v1/categories/food", []string{"categories", "rename", "food", "Groceries"}, "", `{}`, 200},
 		{"category deactivate", http.MethodDelete, "/v1/categories/food", []string{"categories", "deactivate", "food"}, "", `{}`, 200},
 		{"budget get", http.MethodGet, "/v1/budgets/monthly", []string{"budgets", "get", "--household-id=1", "--month=2026-07"}, "", `{"lines":[]}`, 200},
+		{"budget get period", http.MethodGet, "/v1/budgets", []string{"budgets", "get", "--user-id=1", "--period=weekly", "--date=2026-10-19"}, "", `{"lines":[]}`, 200},
+		{"budget ensure period", http.MethodPut, "/v1/budgets", []string{"budgets", "get", "--household-id=1", "--period=custom", "--start=2026-10-01", "--end=2026-10-15", "--create"}, "", `{"lines":[]}`, 201},
+		{"budget compare", http.MethodGet, "/v1/budgets/compare", []string{"budgets", "compare", "12", "13", "--format=json"}, "", `{"lines":[],"totals":{}}`, 200},
+		{"budget trends", http.MethodGet, "/v1/budgets/trends", []string{"budgets", "trends", "--household-id=1", "--from=2026-01", "--to=2026-06"}, "", `{"months":[],"lines":[],"categories":[]}`, 200},
 		{"budget report", http.MethodGet, "/v1/budgets/1/report", []string{"budgets", "report", "1"}, "", `{}`, 200},
+		{"budget report pdf to stdout", http.MethodGet, "/v1/budgets/1/report", []string{"budgets", "report", "1", "--format=pdf", "--output=-"}, "", "%PDF-1.3", 200},
+		{"budget lint", http.MethodGet, "/v1/budgets/1/lint", []string{"budgets", "lint", "1", "--format=json"}, "", `{"issues":[]}`, 200},
 		{"budget line add", http.MethodPost, "/v1/budgets/1/lines", []string{"budgets", "lines", "add", "--budget-id=1", "--name=Food", "--amount=100"}, "", `{"categories":[]}`, 200},
+		{"budget line add alerts", http.MethodPost, "/v1/budgets/1/lines", []string{"budgets", "lines", "add", "--budget-id=1", "--name=Food", "--amount=100", "--alerts=80,100%"}, "", `{"categories":[],"alertThresholds":[80,100]}`, 200},
 		{"budget line update", http.MethodPatch, "/v1/budget-lines/1", []string{"budgets", "lines", "update", "1", "--name=Food"}, "", `{"categories":[]}`, 200},
+		{"budget line add members", http.MethodPost, "/v1/budgets/1/lines", []string{"budgets", "lines", "add", "--budget-id=1", "--name=Food", "--amount=400", "--members=2=150,3=40%"}, "", `{"categories":[],"memberAllocations":[]}`, 200},
+		{"budget line clear members", http.MethodPatch, "/v1/budget-lines/1", []string{"budgets", "lines", "update", "1", "--members="}, "", `{"categories":[],"memberAllocations":[]}`, 200},
+		{"budget line clear alerts", http.MethodPatch, "/v1/budget-lines/1", []string{"budgets", "lines", "update", "1", "--alerts="}, "", `{"categories":[],"alertThresholds":[]}`, 200},
 		{"budget line delete", http.MethodDelete, "/v1/budget-lines/1", []string{"budgets", "lines", "delete", "1"}, "", "", http.StatusNoContent},
+		{"budget line move", http.MethodPost, "/v1/budgets/1/reallocations", []string{"budgets", "lines", "move", "--budget-id=1", "--from=2", "--to=3", "--amount=50", "--reason=Groceries ran over", "--user-id=7"}, "", `{"reallocation":{"amount":"50.00"}}`, 201},
+		{"budget close", http.MethodPost, "/v1/budgets/1/close", []string{"budgets", "close", "1", "--user-id=7"}, "", `{"lines":[],"totals":{}}`, 201},
+		{"budget reopen", http.MethodPost, "/v1/budgets/1/reopen", []string{"budgets", "reopen", "1", "--user-id=7", "--reason=Late refund"}, "", `{"lines":[],"totals":{},"reopenReason":"Late refund"}`, 200},
+		{"budget ensure from template", http.MethodPost, "/v1/budgets/monthly", []string{"budgets", "get", "--household-id=1", "--month=2026-07", "--create", "--template=Standard"}, "", `{"lines":[]}`, 201},
+		{"budget template list", http.MethodGet, "/v1/budget-templates", []string{"budgets", "templates", "list", "--household-id=1"}, "", `[]`, 200},
+		{"budget template get", http.MethodGet, "/v1/budget-templates/4", []string{"budgets", "templates", "get", "4"}, "", `{"lines":[]}`, 200},
+		{"budget template create", http.MethodPost, "/v1/budget-templates", []string{"budgets", "templates", "create", "--household-id=1", "--name=Standard"}, `{"lines":[{"name":"Food","allocationAmount":"400","categoryCodes":["groceries"]}]}`, `{"lines":[]}`, 201},
+		{"budget template rename", http.MethodPatch, "/v1/budget-templates/4", []string{"budgets", "templates", "update", "4", "--name=Lean"}, "", `{"lines":[]}`, 200},
+		{"budget template delete", http.MethodDelete, "/v1/budget-templates/4", []string{"budgets", "templates", "delete", "4"}, "", "", http.StatusNoContent},
+		{"budget template save", http.MethodPost, "/v1/budgets/1/save-template", []string{"budgets", "templates", "save", "1", "--name=Standard"}, "", `{"lines":[]}`, 201},
+		{"budget template apply", http.MethodPost, "/v1/budgets/1/apply-template", []string{"budgets", "templates", "apply", "1", "--template=Standard", "--dry-run"}, "", `{"budget":{"id":1},"template":{"name":"Standard"},"changes":[]}`, 200},
+		{"goal create", http.MethodPost, "/v1/goals", []string{"goals", "create", "--household-id=1", "--name=Holidays", "--target=1200", "--by=2027-06-30", "--categories=holiday-fund"}, "", `{"categories":[]}`, 201},
+		{"goal list", http.MethodGet, "/v1/goals", []string{"goals", "list", "--household-id=1", "--as-of=2026-10-31"}, "", `[]`, 200},
+		{"goal get", http.MethodGet, "/v1/goals/1", []string{"goals", "get", "1"}, "", `{"categories":[]}`, 200},
+		{"goal update", http.MethodPatch, "/v1/goals/1", []string{"goals", "update", "1", "--target=1500", "--categories=holiday-fund,travel"}, "", `{"categories":[]}`, 200},
+		{"goal delete", http.MethodDelete, "/v1/goals/1", []string{"goals", "delete", "1"}, "", "", http.StatusNoContent},
+		{"alert list", http.MethodGet, "/v1/alerts", []string{"alerts", "list", "--household-id=1", "--limit=10"}, "", `[]`, 200},
+		{"webhook create", http.MethodPost, "/v1/webhooks", []string{"webhooks", "create", "--url=https://hooks.example.com", "--events=transaction.created", "--household-id=1"}, "", `{"eventTypes":[]}`, 201},
+		{"webhook update", http.MethodPatch, "/v1/webhooks/1", []string{"webhooks", "update", "1", "--disable", "--events="}, "", `{"eventTypes":[]}`, 200},
+		{"webhook deliveries", http.MethodGet, "/v1/webhooks/1/deliveries", []string{"webhooks", "deliveries", "1", "--limit=5"}, "", `[]`, 200},
+		{"webhook redeliver", http.MethodPost, "/v1/webhook-deliveries/7/redeliver", []string{"webhooks", "redeliver", "7"}, "", `{"history":[]}`, 202},
 	}
 
 	for _, test := range tests {
@@ -85,6 +129,59 @@ func TestEveryCommandUsesOneAuthenticatedFeatureRequest(t *testing.T) {
 	}
 }
 
+func TestIfMatchFlagsSendVersionAndStaleVersionsExitAsExpected(t *testing.T) {
+	for _, args := range [][]string{
+		{"transactions", "update", "--id=1", "--amount=13", "--if-match=4"},
+		{"categories", "rename", "food", "Groceries", "--if-match=4"},
+		{"categories", "deactivate", "food", "--if-match=4"},
+		{"budgets", "lines", "update", "1", "--name=Food", "--if-match=4"},
+		{"budgets", "lines", "delete", "1", "--if-match=4"},
+	} {
+		t.Run(strings.Join(args[:3], " "), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				if request.Header.Get("If-Match") != `"4"` {
+					t.Errorf("If-Match=%q", request.Header.Get("If-Match"))
+				}
+				w.WriteHeader(http.StatusPreconditionFailed)
+				_, _ = w.Write([]byte(`{"error":{"code":"version_mismatch","message":"version mismatch"}}`))
+			}))
+			defer server.Close()
+			client, _ := restclient.New(restclient.Config{BaseURL: server.URL, APIKey: "key"})
+			var stdout, stderr bytes.Buffer
+			if code := Run(context.Background(), args, nil, &stdout, &stderr, client); code != 2 || !strings.Contains(stderr.String(), "version mismatch") {
+				t.Fatalf("code=%d stderr=%s", code, stderr.String())
+			}
+		})
+	}
+}
+
+func TestTransactionDeleteSendsVersionsPerItem(t *testing.T) {
+	for _, test := range []struct{ ids, want string }{
+		{"1,2", `{"ids":[1,2],"deletedByUserId":3}`},
+		{"1@4, 2", `{"transactions":[{"id":1,"expectedVersion":4},{"id":2}],"deletedByUserId":3}`},
+	} {
+		t.Run(test.ids, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				body, _ := io.ReadAll(request.Body)
+				if strings.TrimSpace(string(body)) != test.want {
+					t.Errorf("body=%s want %s", body, test.want)
+				}
+				_, _ = w.Write([]byte(`{"succeeded":[],"failed":[]}`))
+			}))
+			defer server.Close()
+			client, _ := restclient.New(restclient.Config{BaseURL: server.URL, APIKey: "key"})
+			var stdout, stderr bytes.Buffer
+			if code := Run(context.Background(), []string{"transactions", "delete", "--ids=" + test.ids, "--deleted-by-user-id=3"}, nil, &stdout, &stderr, client); code != 0 {
+				t.Fatalf("code=%d stderr=%s", code, stderr.String())
+			}
+		})
+	}
+	var stdout, stderr bytes.Buffer
+	if code := Run(context.Background(), []string{"transactions", "delete", "--ids=1@x", "--deleted-by-user-id=3"}, nil, &stdout, &stderr, nil); code == 0 {
+		t.Fatalf("bad version accepted: stdout=%s", stdout.String())
+	}
+}
+
 func TestBudgetCreateAndBulkPartialResultSemantics(t *testing.T) {
 	for _, test := range []struct {
 		name, path, response string
@@ -127,3 +224,212 @@ func TestExitClassesAPIValidationAndTransport(t *testing.T) {
 		})
 	}
 }
+
+func TestBudgetLineAlertsRejectNonNumericThresholds(t *testing.T) {
+	client, _ := restclient.New(restclient.Config{BaseURL: "http://127.0.0.1:1", APIKey: "key"})
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), []string{"budgets", "lines", "add", "--budget-id=1", "--name=Food", "--amount=100", "--alerts=80,most"}, nil, &stdout, &stderr, client)
+	if code != 2 || !strings.Contains(stderr.String(), `alert threshold "most" must be a whole percentage`) {
+		t.Fatalf("code=%d stderr=%s", code, stderr.String())
+	}
+}
+
+func TestBudgetLineMembersParseAmountsAndPercentages(t *testing.T) {
+	members, err := parseMemberAllocations("2=150.00, 3=40%")
+	if err != nil || len(members) != 2 || members[0].UserID != 2 || *members[0].Amount != "150.00" || members[0].Percent != nil || *members[1].Percent != "40" || members[1].Amount != nil {
+		t.Fatalf("members=%+v error=%v", members, err)
+	}
+	client, _ := restclient.New(restclient.Config{BaseURL: "http://127.0.0.1:1", APIKey: "key"})
+	for _, value := range []string{"--members=2", "--members=alex=10", "--members=2="} {
+		var stdout, stderr bytes.Buffer
+		code := Run(context.Background(), []string{"budgets", "lines", "add", "--budget-id=1", "--name=Food", "--amount=100", value}, nil, &stdout, &stderr, client)
+		if code != 2 || !strings.Contains(stderr.String(), "member allocation") {
+			t.Fatalf("%s: code=%d stderr=%s", value, code, stderr.String())
+		}
+	}
+}
+
+func TestTransactionListAllFollowsEveryCursor(t *testing.T) {
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		cursor := request.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		if request.URL.Query().Get("limit") != "2" {
+			t.Errorf("query=%s", request.URL.RawQuery)
+		}
+		if cursor == "" {
+			_, _ = w.Write([]byte(`{"items":[{"id":1},{"id":2}],"nextCursor":"next"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"items":[{"id":3}]}`))
+	}))
+	defer server.Close()
+	client, _ := restclient.New(restclient.Config{BaseURL: server.URL, APIKey: "key"})
+	var stdout, stderr bytes.Buffer
+	if code := Run(context.Background(), []string{"transactions", "list", "--all", "--limit=2", "--format=csv"}, nil, &stdout, &stderr, client); code != 0 {
+		t.Fatalf("code=%d stderr=%s", code, stderr.String())
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout.String()), "\n"); len(lines) != 4 || len(cursors) != 2 || cursors[1] != "next" {
+		t.Fatalf("cursors=%q output=%s", cursors, stdout.String())
+	}
+
+	stdout.Reset()
+	cursors = nil
+	if code := Run(context.Background(), []string{"transactions", "list", "--limit=2"}, nil, &stdout, &stderr, client); code != 0 || len(cursors) != 1 || !strings.Contains(stderr.String(), "--cursor next") {
+		t.Fatalf("code=%d cursors=%q stderr=%s", code, cursors, stderr.String())
+	}
+	stderr.Reset()
+	if code := Run(context.Background(), []string{"transactions", "list", "--all", "--offset=5"}, nil, &stdout, &stderr, client); code != 2 || len(cursors) != 1 {
+		t.Fatalf("all with offset code=%d stderr=%s", code, stderr.String())
+	}
+}
+
+func TestTagAndQueryFlagsReachTransactionRequests(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		requests = append(requests, request.Method+" "+request.URL.RequestURI()+" "+string(body))
+		if request.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"items":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client, _ := restclient.New(restclient.Config{BaseURL: server.URL, APIKey: "key"})
+	for _, args := range [][]string{
+		{"transactions", "create", "--amount=12.5", "--transaction-date=2026-07-01T00:00:00Z", "--household-id=2", "--tag=reimbursable,trip-japan-2026", "--tag=tax-deductible"},
+		{"transactions", "update", "--id=1", "--clear-tags"},
+		{"transactions", "list", "--tag=reimbursable", "--tag=travel", "--tag-match=all"},
+		{"transactions", "list"},
+		{"transactions", "list", "--query=amount > 50 and not tag:reimbursable", "--filter=big-spend"},
+		{"transactions", "filters", "save", "big-spend", "--query=amount > 100"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(context.Background(), args, nil, &stdout, &stderr, client); code != 0 {
+			t.Fatalf("%v code=%d stderr=%s", args, code, stderr.String())
+		}
+	}
+	for index, want := range []string{
+		`"tags":["reimbursable","trip-japan-2026","tax-deductible"]`,
+		`"clearTags":true`,
+		"/v1/transactions?limit=100&tag=reimbursable&tag=travel&tagMatch=all ",
+		"/v1/transactions?limit=100 ",
+		"/v1/transactions?filter=big-spend&limit=100&q=amount+%3E+50+and+not+tag%3Areimbursable ",
+		`PUT /v1/saved-filters/big-spend {"query":"amount \u003e 100"}`,
+	} {
+		if !strings.Contains(requests[index], want) {
+			t.Errorf("request %d = %s, want %s", index, requests[index], want)
+		}
+	}
+}
+
+func TestBudgetReportExportWritesFileOnlyAfterDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/v1/budgets/404/report" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"budget_not_found","message":"budget not found"}}`))
+			return
+		}
+		if request.URL.Query().Get("format") != "xlsx" {
+			t.Errorf("query=%s", request.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte("PK workbook"))
+	}))
+	defer server.Close()
+	client, _ := restclient.New(restclient.Config{BaseURL: server.URL, APIKey: "key"})
+	directory := t.TempDir()
+	path := filepath.Join(directory, "report.xlsx")
+	var stdout, stderr bytes.Buffer
+	if code := Run(context.Background(), []string{"budgets", "report", "12", "--format", "xlsx", "-o", path}, nil, &stdout, &stderr, client); code != 0 {
+		t.Fatalf("code=%d stderr=%s", code, stderr.String())
+	}
+	if written, err := os.ReadFile(path); err != nil || string(written) != "PK workbook" || stdout.Len() != 0 || !strings.Contains(stderr.String(), "Wrote xlsx report for budget 12") {
+		t.Fatalf("written=%q error=%v stdout=%q stderr=%q", written, err, stdout.String(), stderr.String())
+	}
+	missing := filepath.Join(directory, "missing.pdf")
+	for _, args := range [][]string{
+		{"budgets", "report", "404", "--format", "pdf", "-o", missing},
+		{"budgets", "report", "12", "--format", "pdf"},
+		{"budgets", "report", "12", "-o", missing},
+	} {
+		stdout.Reset()
+		stderr.Reset()
+		if code := Run(context.Background(), args, nil, &stdout, &stderr, client); code != 2 {
+			t.Fatalf("%v: code=%d stderr=%s", args, code, stderr.String())
+		}
+	}
+	if _, err := os.Stat(missing); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("failed export left a file: %v", err)
+	}
+}
+
+func TestTransactionAttachUploadsFileAndDownloadWritesIt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodGet {
+			_, _ = w.Write([]byte("%PDF-1.7"))
+			return
+		}
+		if request.URL.Path != "/v1/transactions/4/attachments" {
+			t.Errorf("path=%s", request.URL.Path)
+		}
+		file, header, err := request.FormFile("file")
+		if err != nil {
+			t.Fatalf("form file: %v", err)
+		}
+		content, _ := io.ReadAll(file)
+		if header.Filename != "receipt.pdf" || string(content) != "%PDF-1.7" {
+			t.Errorf("file=%s content=%q", header.Filename, content)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":3,"transactionId":4,"fileName":"receipt.pdf","contentType":"application/pdf","size":8,"sha256":"x","createdAt":"2026-06-13T00:00:00Z"}`))
+	}))
+	defer server.Close()
+	client, _ := restclient.New(restclient.Config{BaseURL: server.URL, APIKey: "key"})
+	directory := t.TempDir()
+	receipt := filepath.Join(directory, "receipt.pdf")
+	if err := os.WriteFile(receipt, []byte("%PDF-1.7"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := Run(context.Background(), []string{"transactions", "attach", "--id=4", "--file", receipt}, nil, &stdout, &stderr, client); code != 0 || !strings.Contains(stdout.String(), `"fileName": "receipt.pdf"`) {
+		t.Fatalf("code=%d stdout=%s stderr=%s", code, stdout.String(), stderr.String())
+	}
+	output := filepath.Join(directory, "copy.pdf")
+	if code := Run(context.Background(), []string{"attachments", "download", "3", "-o", output}, nil, &stdout, &stderr, client); code != 0 {
+		t.Fatalf("code=%d stderr=%s", code, stderr.String())
+	}
+	if written, err := os.ReadFile(output); err != nil || string(written) != "%PDF-1.7" {
+		t.Fatalf("written=%q error=%v", written, err)
+	}
+	if code := Run(context.Background(), []string{"transactions", "attach", "--id=4", "--file", filepath.Join(directory, "missing.png")}, nil, &stdout, &stderr, client); code != 2 {
+		t.Fatalf("missing file code=%d", code)
+	}
+}
+
+func TestEventsTailPrintsOneLinePerEventUntilTheStreamFails(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		calls++
+		if request.URL.RequestURI() != "/v1/events?householdId=2" {
+			t.Errorf("request=%s", request.URL.RequestURI())
+		}
+		if calls == 1 {
+			_, _ = w.Write([]byte("retry: 1\n\nid: 4\nevent: category.changed\ndata: {\"id\":\"4\",\"type\":\"category.changed\",\"occurredAt\":\"2026-06-01T00:00:00Z\",\"categoryCode\":\"food\"}\n\n"))
+			return
+		}
+		if request.Header.Get("Last-Event-ID") != "4" {
+			t.Errorf("Last-Event-ID=%q", request.Header.Get("Last-Event-ID"))
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"events are unavailable"}}`))
+	}))
+	defer server.Close()
+	client, _ := restclient.New(restclient.Config{BaseURL: server.URL, APIKey: "key"})
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), []string{"events", "tail", "--household-id=2"}, nil, &stdout, &stderr, client)
+	want := `{"id":"4","type":"category.changed","occurredAt":"2026-06-01T00:00:00Z","categoryCode":"food"}` + "\n"
+	if code != 2 || stdout.String() != want || !strings.Contains(stderr.String(), "events are unavailable") {
+		t.Fatalf("code=%d stdout=%q stderr=%q", code, stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"encoding/json"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+type EventsCmd struct {
+	Tail EventTailCmd `cmd:"" help:"Follow live finance events, one JSON object per line."`
+}
+
+type EventTailCmd struct {
+	HouseholdID *int64 `placeholder:"INT-64" help:"Only this household's events, plus those outside any household."`
+	LastEventID string `help:"Resume after this event ID, replaying what the server still buffers."`
+}
+
+func (c *EventTailCmd) Run(ctx *runContext) error {
+	encoder := json.NewEncoder(ctx.stdout)
+	for event, err := range ctx.events.Events(ctx.Context, api.EventsQuery{HouseholdID: c.HouseholdID, LastEventID: c.LastEventID}) {
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import "rdmm404/voltr-finance/internal/api"
+
+type GoalsCmd struct {
+	Create GoalCreateCmd `cmd:"" help:"Create a savings goal."`
+	List   GoalListCmd   `cmd:"" help:"List savings goals with progress."`
+	Get    GoalGetCmd    `cmd:"" help:"Show one savings goal with progress."`
+	Update GoalUpdateCmd `cmd:"" help:"Update a savings goal."`
+	Delete GoalDeleteCmd `cmd:"" help:"Delete a savings goal."`
+}
+
+type GoalCreateCmd struct {
+	HouseholdID *int64  `placeholder:"INT-64" help:"Household goal owner."`
+	UserID      *int64  `placeholder:"INT-64" help:"Personal goal owner."`
+	Name        string  `required:"" help:"Goal name."`
+	Target      string  `required:"" help:"Target amount."`
+	By          string  `required:"" help:"Target date in YYYY-MM-DD format."`
+	Start       *string `help:"Saving start date in YYYY-MM-DD format. Defaults to the first day of this month."`
+	Categories  string  `required:"" help:"Comma-separated linked category codes."`
+}
+
+func (c *GoalCreateCmd) Run(ctx *runContext) error {
+	goal, err := ctx.goals.CreateGoal(ctx.Context, api.CreateGoalRequest{
+		HouseholdID:   c.HouseholdID,
+		UserID:        c.UserID,
+		Name:          c.Name,
+		TargetAmount:  c.Target,
+		StartDate:     c.Start,
+		TargetDate:    c.By,
+		CategoryCodes: parseOptionalCSV(&c.Categories),
+	})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, goal)
+}
+
+type GoalListCmd struct {
+	HouseholdID *int64 `placeholder:"INT-64" help:"Only goals owned by this household."`
+	UserID      *int64 `placeholder:"INT-64" help:"Only personal goals of this user."`
+	AsOf        string `help:"Measure progress as of this date in YYYY-MM-DD format. Defaults to today."`
+}
+
+func (c *GoalListCmd) Run(ctx *runContext) error {
+	goals, err := ctx.goals.ListGoals(ctx.Context, api.GoalQuery{HouseholdID: c.HouseholdID, UserID: c.UserID, AsOf: c.AsOf})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, goals)
+}
+
+type GoalGetCmd struct {
+	ID   int64  `arg:"" required:"" help:"Goal ID."`
+	AsOf string `help:"Measure progress as of this date in YYYY-MM-DD format. Defaults to today."`
+}
+
+func (c *GoalGetCmd) Run(ctx *runContext) error {
+	goal, err := ctx.goals.GetGoal(ctx.Context, c.ID, c.AsOf)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, goal)
+}
+
+type GoalUpdateCmd struct {
+	ID         int64   `arg:"" required:"" help:"Goal ID."`
+	Name       *string `help:"Replacement goal name."`
+	Target     *string `help:"Replacement target amount."`
+	By         *string `help:"Replacement target date in YYYY-MM-DD format."`
+	Start      *string `help:"Replacement start date in YYYY-MM-DD format."`
+	Categories *string `help:"Replacement comma-separated linked category codes."`
+}
+
+func (c *GoalUpdateCmd) Run(ctx *runContext) error {
+	var categoryCodes *[]string
+	if c.Categories != nil {
+		parsed := parseOptionalCSV(c.Categories)
+		categoryCodes = &parsed
+	}
+	goal, err := ctx.goals.UpdateGoal(ctx.Context, c.ID, api.UpdateGoalRequest{
+		Name:          c.Name,
+		TargetAmount:  c.Target,
+		StartDate:     c.Start,
+		TargetDate:    c.By,
+		CategoryCodes: categoryCodes,
+	})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, goal)
+}
+
+type GoalDeleteCmd struct {
+	ID int64 `arg:"" required:"" help:"Goal ID."`
+}
+
+func (c *GoalDeleteCmd) Run(ctx *runContext) error {
+	return ctx.goals.DeleteGoal(ctx.Context, c.ID)
+}
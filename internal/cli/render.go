@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"rdmm404/voltr-finance/internal/api"
@@ -86,6 +88,253 @@ func RenderTransactionsCSV(w io.Writer, txs []api.Transaction) error {
 	return writer.Error()
 }
 
+// RenderBudgetTrendsTable prints one row per budget line as actual/allocation
+// for each month, followed by one row per category with actual spending only.
+// Months without a budget line show "-".
+func RenderBudgetTrendsTable(w io.Writer, trends api.BudgetTrends) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := func(label string) {
+		columns := []string{label}
+		for _, month := range trends.Months {
+			columns = append(columns, month.Month)
+		}
+		fmt.Fprintln(table, strings.Join(append(columns, "TOTAL", "AVG/MONTH", "YTD"), "\t"))
+	}
+	header("LINE")
+	for _, line := range trends.Lines {
+		columns := []string{line.Name}
+		for _, point := range line.Months {
+			if !point.Budgeted {
+				columns = append(columns, "-")
+				continue
+			}
+			columns = append(columns, point.ActualAmount+"/"+point.AllocationAmount)
+		}
+		columns = append(columns, line.Totals.ActualAmount+"/"+line.Totals.AllocationAmount, line.Totals.AverageActualAmount, line.YearToDate.ActualAmount+"/"+line.YearToDate.AllocationAmount)
+		fmt.Fprintln(table, strings.Join(columns, "\t"))
+	}
+	columns := []string{"Total"}
+	for _, month := range trends.Months {
+		columns = append(columns, month.ActualAmount+"/"+month.AllocationAmount)
+	}
+	columns = append(columns, trends.Totals.ActualAmount+"/"+trends.Totals.AllocationAmount, trends.Totals.AverageActualAmount, trends.YearToDate.ActualAmount+"/"+trends.YearToDate.AllocationAmount)
+	fmt.Fprintln(table, strings.Join(columns, "\t"))
+	if len(trends.Categories) > 0 {
+		fmt.Fprintln(table)
+		header("CATEGORY")
+		for _, category := range trends.Categories {
+			columns := []string{category.Name}
+			budgeted := false
+			for _, point := range category.Months {
+				if point.Budgeted {
+					budgeted = true
+					columns = append(columns, point.ActualAmount+"/"+point.AllocationAmount)
+					continue
+				}
+				columns = append(columns, point.ActualAmount)
+			}
+			if budgeted {
+				columns = append(columns, category.Totals.ActualAmount+"/"+category.Totals.AllocationAmount, category.Totals.AverageActualAmount, category.YearToDate.ActualAmount+"/"+category.YearToDate.AllocationAmount)
+			} else {
+				columns = append(columns, category.Totals.ActualAmount, category.Totals.AverageActualAmount, category.YearToDate.ActualAmount)
+			}
+			fmt.Fprintln(table, strings.Join(columns, "\t"))
+		}
+	}
+	return table.Flush()
+}
+
+// RenderTransactionAggregateTable prints one row per group with a column for
+// each grouped dimension and then each metric. Dimensions a group lacks, such
+// as the category of uncategorized transactions, show "-".
+func RenderTransactionAggregateTable(w io.Writer, aggregate api.TransactionAggregate) error {
+	if len(aggregate.Groups) == 0 {
+		_, err := fmt.Fprintln(w, "No transactions matched.")
+		return err
+	}
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, strings.ToUpper(strings.Join(append(append([]string{}, aggregate.By...), aggregate.Metrics...), "\t")))
+	columns := make([]string, 0, len(aggregate.By)+len(aggregate.Metrics))
+	for _, group := range aggregate.Groups {
+		columns = columns[:0]
+		for _, dimension := range aggregate.By {
+			columns = append(columns, dash(aggregateDimension(group, dimension)))
+		}
+		for _, metric := range aggregate.Metrics {
+			columns = append(columns, dash(aggregateMetric(group, metric)))
+		}
+		fmt.Fprintln(table, strings.Join(columns, "\t"))
+	}
+	return table.Flush()
+}
+
+func aggregateDimension(group api.AggregateGroup, dimension string) string {
+	switch dimension {
+	case "day", "week":
+		if group.Period != nil {
+			return group.Period.Format(time.DateOnly)
+		}
+	case "month":
+		if group.Period != nil {
+			return group.Period.Format("2006-01")
+		}
+	case "category":
+		return categoryValue(group.Category)
+	case "author":
+		if group.AuthorName != nil {
+			return *group.AuthorName
+		}
+		return formatInt(group.AuthorID)
+	case "household":
+		if group.HouseholdName != nil {
+			return *group.HouseholdName
+		}
+		return formatInt(group.HouseholdID)
+	case "tag":
+		return stringValue(group.Tag)
+	case "merchant":
+		return stringValue(group.Merchant)
+	}
+	return ""
+}
+
+func aggregateMetric(group api.AggregateGroup, metric string) string {
+	switch metric {
+	case "count":
+		return formatInt(group.Count)
+	case "sum":
+		return stringValue(group.Sum)
+	case "avg":
+		return stringValue(group.Avg)
+	case "min":
+		return stringValue(group.Min)
+	case "max":
+		return stringValue(group.Max)
+	}
+	return ""
+}
+
+func RenderBudgetLintTable(w io.Writer, lint api.BudgetLint) error {
+	if len(lint.Issues) == 0 {
+		_, err := fmt.Fprintln(w, "No issues found.")
+		return err
+	}
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "KIND\tLINES\tCATEGORY\tUNMAPPED\tMESSAGE")
+	for _, issue := range lint.Issues {
+		lines := make([]string, 0, len(issue.Lines))
+		for _, line := range issue.Lines {
+			lines = append(lines, fmt.Sprintf("%s (%d)", line.Name, line.ID))
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", issue.Kind, dash(strings.Join(lines, ", ")), dash(categoryCode(issue.Category)), dash(issue.ActualAmount), issue.Message)
+	}
+	return table.Flush()
+}
+
+// RenderBudgetTemplateApplication prints one row per line change with the
+// before and after values of what changed, followed by a summary line.
+func RenderBudgetTemplateApplication(w io.Writer, application api.BudgetTemplateApplication) error {
+	if len(application.Changes) == 0 {
+		_, err := fmt.Fprintf(w, "Budget %d already matches template %q.\n", application.Budget.ID, application.Template.Name)
+		return err
+	}
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ACTION\tLINE\tALLOCATION\tCATEGORIES")
+	for _, change := range application.Changes {
+		var name, allocation, categories string
+		switch {
+		case change.Before == nil:
+			name, allocation, categories = change.After.Name, change.After.AllocationAmount, lineCategoryCodes(*change.After)
+		case change.After == nil:
+			name, allocation, categories = change.Before.Name, change.Before.AllocationAmount, lineCategoryCodes(*change.Before)
+		default:
+			name = change.Before.Name
+			allocation = changedValue(change.Before.AllocationAmount, change.After.AllocationAmount)
+			categories = changedValue(dash(lineCategoryCodes(*change.Before)), dash(lineCategoryCodes(*change.After)))
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", change.Action, name, allocation, dash(categories))
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	if !application.Applied {
+		_, err := fmt.Fprintln(w, "Dry run: no changes applied.")
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Applied %d changes to budget %d.\n", len(application.Changes), application.Budget.ID)
+	return err
+}
+
+// RenderBudgetComparison prints one row per aligned line with the values of
+// budget A and budget B, the deltas and the category mapping changes.
+func RenderBudgetComparison(w io.Writer, comparison api.BudgetComparison) error {
+	fmt.Fprintf(w, "Budget %d (%s) -> budget %d (%s)\n", comparison.A.ID, comparison.A.PeriodStart.Format(time.DateOnly), comparison.B.ID, comparison.B.PeriodStart.Format(time.DateOnly))
+	switch lineage := comparison.Lineage; {
+	case lineage == nil:
+	case lineage.Generations == 1:
+		fmt.Fprintf(w, "Budget %d was copied from budget %d.\n", lineage.DescendantID, lineage.AncestorID)
+	default:
+		fmt.Fprintf(w, "Budget %d descends from budget %d through %d copies.\n", lineage.DescendantID, lineage.AncestorID, lineage.Generations)
+	}
+	fmt.Fprintln(w)
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "STATUS\tLINE\tALLOCATION\tDELTA\tACTUAL\tDELTA\tCATEGORIES")
+	amounts := func(line *api.BudgetComparedLine) (string, string) {
+		if line == nil {
+			return "-", "-"
+		}
+		return line.AllocationAmount, line.ActualAmount
+	}
+	for _, line := range comparison.Lines {
+		beforeAllocation, beforeActual := amounts(line.A)
+		afterAllocation, afterActual := amounts(line.B)
+		var name string
+		switch {
+		case line.A == nil:
+			name = line.B.Name
+		case line.B == nil:
+			name = line.A.Name
+		default:
+			name = changedValue(line.A.Name, line.B.Name)
+		}
+		changes := make([]string, 0, len(line.AddedCategories)+len(line.RemovedCategories))
+		for _, category := range line.AddedCategories {
+			changes = append(changes, "+"+category.Code)
+		}
+		for _, category := range line.RemovedCategories {
+			changes = append(changes, "-"+category.Code)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s -> %s\t%s\t%s -> %s\t%s\t%s\n", line.Status, name, beforeAllocation, afterAllocation, line.AllocationDelta,
+			beforeActual, afterActual, line.ActualDelta, dash(strings.Join(changes, ",")))
+	}
+	totals := comparison.Totals
+	fmt.Fprintf(table, "\tTotal\t%s -> %s\t%s\t%s -> %s\t%s\n", totals.A.AllocationAmount, totals.B.AllocationAmount, totals.AllocationDelta,
+		totals.A.ActualAmount, totals.B.ActualAmount, totals.ActualDelta)
+	return table.Flush()
+}
+
+func lineCategoryCodes(line api.BudgetLine) string {
+	codes := make([]string, 0, len(line.Categories))
+	for _, category := range line.Categories {
+		codes = append(codes, category.Code)
+	}
+	return strings.Join(codes, ",")
+}
+
+func changedValue(before, after string) string {
+	if before == after {
+		return after
+	}
+	return before + " -> " + after
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
 func stringValue(value *string) string {
 	if value == nil {
 		return ""
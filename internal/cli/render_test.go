@@ -98,3 +98,151 @@ func strPtr(value string) *string {
 func intPtr(value int64) *int64 {
 	return &value
 }
+
+func TestRenderBudgetTrendsTable(t *testing.T) {
+	totals := api.BudgetTrendTotals{Months: 2, AllocationAmount: "200.00", ActualAmount: "150.00", AverageActualAmount: "75.00"}
+	uncategorized := api.BudgetTrendTotals{Months: 2, AllocationAmount: "0.00", ActualAmount: "12.50", AverageActualAmount: "6.25"}
+	trends := api.BudgetTrends{
+		Months: []api.BudgetTrendMonth{{Month: "2026-01", AllocationAmount: "100.00", ActualAmount: "90.00"}, {Month: "2026-02", AllocationAmount: "100.00", ActualAmount: "60.00"}},
+		Lines: []api.BudgetTrendSeries{{Name: "Food", Totals: totals, YearToDate: totals, Months: []api.BudgetTrendPoint{
+			{Month: "2026-01", Budgeted: true, AllocationAmount: "100.00", ActualAmount: "90.00"},
+			{Month: "2026-02", Budgeted: false, AllocationAmount: "0.00", ActualAmount: "0.00"},
+		}}},
+		Categories: []api.BudgetTrendSeries{
+			{Name: "Groceries", Totals: totals, YearToDate: totals, Months: []api.BudgetTrendPoint{{Budgeted: true, AllocationAmount: "100.00", ActualAmount: "90.00"}, {ActualAmount: "60.00"}}},
+			{Name: "Uncategorized", Totals: uncategorized, YearToDate: uncategorized, Months: []api.BudgetTrendPoint{{ActualAmount: "0.00"}, {ActualAmount: "12.50"}}},
+		},
+		Totals: totals, YearToDate: totals,
+	}
+	var out bytes.Buffer
+	if err := RenderBudgetTrendsTable(&out, trends); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"LINE   2026-01       2026-02       TOTAL          AVG/MONTH  YTD",
+		"Food   90.00/100.00  -             150.00/200.00  75.00      150.00/200.00",
+		"Total  90.00/100.00  60.00/100.00  150.00/200.00  75.00      150.00/200.00",
+		"",
+		"CATEGORY       2026-01       2026-02  TOTAL          AVG/MONTH  YTD",
+		"Groceries      90.00/100.00  60.00    150.00/200.00  75.00      150.00/200.00",
+		"Uncategorized  0.00          12.50    12.50          6.25       12.50",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Fatalf("table:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRenderTransactionAggregateTable(t *testing.T) {
+	may, june := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	count, sum, uncategorized := int64(3), "41.50", "12.00"
+	aggregate := api.TransactionAggregate{By: []string{"category", "month"}, Metrics: []string{"sum", "count"}, Groups: []api.AggregateGroup{
+		{Period: &may, Category: &api.CategoryRef{ID: 2, Code: "groceries", Name: "Groceries"}, Count: &count, Sum: &sum},
+		{Period: &june, Count: &count, Sum: &uncategorized},
+	}}
+	var out bytes.Buffer
+	if err := RenderTransactionAggregateTable(&out, aggregate); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"CATEGORY   MONTH    SUM    COUNT",
+		"Groceries  2026-05  41.50  3",
+		"-          2026-06  12.00  3",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Fatalf("table:\n%s\nwant:\n%s", out.String(), want)
+	}
+	out.Reset()
+	if err := RenderTransactionAggregateTable(&out, api.TransactionAggregate{}); err != nil || out.String() != "No transactions matched.\n" {
+		t.Fatalf("empty table=%q error=%v", out.String(), err)
+	}
+}
+
+func TestRenderBudgetLintTable(t *testing.T) {
+	lint := api.BudgetLint{Issues: []api.BudgetLintIssue{
+		{Kind: "unmapped_category", Message: `category "gifts" is not mapped to any line`, Lines: []api.BudgetLineRef{}, Category: &api.CategoryRef{Code: "gifts"}, ActualAmount: "40.00"},
+		{Kind: "empty_line", Message: `line "Misc" has no categories and never records spending`, Lines: []api.BudgetLineRef{{ID: 9, Name: "Misc"}}},
+	}}
+	var out bytes.Buffer
+	if err := RenderBudgetLintTable(&out, lint); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"KIND               LINES     CATEGORY  UNMAPPED  MESSAGE",
+		`unmapped_category  -         gifts     40.00     category "gifts" is not mapped to any line`,
+		`empty_line         Misc (9)  -         -         line "Misc" has no categories and never records spending`,
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Fatalf("table:\n%s\nwant:\n%s", out.String(), want)
+	}
+	out.Reset()
+	if err := RenderBudgetLintTable(&out, api.BudgetLint{Issues: []api.BudgetLintIssue{}}); err != nil || out.String() != "No issues found.\n" {
+		t.Fatalf("empty lint=%q error=%v", out.String(), err)
+	}
+}
+
+func TestRenderBudgetTemplateApplication(t *testing.T) {
+	food := api.CategoryRef{Code: "food"}
+	application := api.BudgetTemplateApplication{Budget: api.Budget{ID: 4}, Template: api.BudgetTemplate{Name: "Standard"}, Applied: true, Changes: []api.BudgetTemplateChange{
+		{Action: "update", Before: &api.BudgetLine{Name: "Groceries", AllocationAmount: "800.00", Categories: []api.CategoryRef{}}, After: &api.BudgetLine{Name: "Groceries", AllocationAmount: "900.00", Categories: []api.CategoryRef{food}}},
+		{Action: "add", After: &api.BudgetLine{Name: "Treats", AllocationAmount: "30.00", Categories: []api.CategoryRef{}}},
+		{Action: "remove", Before: &api.BudgetLine{Name: "Misc", AllocationAmount: "50.00", Categories: []api.CategoryRef{food}}},
+	}}
+	var out bytes.Buffer
+	if err := RenderBudgetTemplateApplication(&out, application); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"ACTION  LINE       ALLOCATION        CATEGORIES",
+		"update  Groceries  800.00 -> 900.00  - -> food",
+		"add     Treats     30.00             -",
+		"remove  Misc       50.00             food",
+		"Applied 3 changes to budget 4.",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Fatalf("table:\n%s\nwant:\n%s", out.String(), want)
+	}
+	out.Reset()
+	application.Changes = []api.BudgetTemplateChange{}
+	if err := RenderBudgetTemplateApplication(&out, application); err != nil || out.String() != "Budget 4 already matches template \"Standard\".\n" {
+		t.Fatalf("unchanged=%q error=%v", out.String(), err)
+	}
+}
+
+func TestRenderBudgetComparison(t *testing.T) {
+	june, july := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	comparison := api.BudgetComparison{
+		A: api.BudgetSummary{ID: 12, PeriodStart: june}, B: api.BudgetSummary{ID: 13, PeriodStart: july},
+		Lineage: &api.BudgetLineage{AncestorID: 12, DescendantID: 13, Generations: 1},
+		Lines: []api.BudgetLineComparison{
+			{Status: "matched", A: &api.BudgetComparedLine{Name: "Food", AllocationAmount: "400.00", ActualAmount: "380.00"}, B: &api.BudgetComparedLine{Name: "Food", AllocationAmount: "450.00", ActualAmount: "100.00"}, AllocationDelta: "50.00", ActualDelta: "-280.00", AddedCategories: []api.CategoryRef{{Code: "dining"}}},
+			{Status: "matched", A: &api.BudgetComparedLine{Name: "Housing", AllocationAmount: "1000.00", ActualAmount: "1000.00"}, B: &api.BudgetComparedLine{Name: "Rent", AllocationAmount: "1000.00", ActualAmount: "0.00"}, AllocationDelta: "0.00", ActualDelta: "-1000.00"},
+			{Status: "removed", A: &api.BudgetComparedLine{Name: "Gym", AllocationAmount: "40.00", ActualAmount: "40.00"}, AllocationDelta: "-40.00", ActualDelta: "-40.00", RemovedCategories: []api.CategoryRef{}},
+		},
+		Totals: api.BudgetComparisonTotals{
+			A: api.BudgetReportTotals{AllocationAmount: "1440.00", ActualAmount: "1420.00"}, B: api.BudgetReportTotals{AllocationAmount: "1450.00", ActualAmount: "100.00"},
+			AllocationDelta: "10.00", ActualDelta: "-1320.00",
+		},
+	}
+	var out bytes.Buffer
+	if err := RenderBudgetComparison(&out, comparison); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"Budget 12 (2026-06-01) -> budget 13 (2026-07-01)",
+		"Budget 13 was copied from budget 12.",
+		"",
+		"STATUS   LINE             ALLOCATION          DELTA   ACTUAL             DELTA     CATEGORIES",
+		"matched  Food             400.00 -> 450.00    50.00   380.00 -> 100.00   -280.00   +dining",
+		"matched  Housing -> Rent  1000.00 -> 1000.00  0.00    1000.00 -> 0.00    -1000.00  -",
+		"removed  Gym              40.00 -> -          -40.00  40.00 -> -         -40.00    -",
+		"         Total            1440.00 -> 1450.00  10.00   1420.00 -> 100.00  -1320.00",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Fatalf("table:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -1,20 +1,29 @@
 package cli
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"rdmm404/voltr-finance/internal/api"
 )
 
 type TransactionsCmd struct {
-	Create     TransactionCreateCmd     `cmd:"" help:"Create one transaction."`
-	CreateBulk TransactionCreateBulkCmd `cmd:"create-bulk" help:"Create multiple transactions from JSON."`
-	Update     TransactionUpdateCmd     `cmd:"" help:"Update one transaction by internal ID."`
-	UpdateBulk TransactionUpdateBulkCmd `cmd:"update-bulk" help:"Update multiple transactions from JSON."`
-	Get        TransactionGetCmd        `cmd:"" help:"Get transactions by internal ID."`
-	List       TransactionListCmd       `cmd:"" help:"List transactions with filters, sorting, and pagination."`
-	Delete     TransactionDeleteCmd     `cmd:"" help:"Soft-delete transactions by internal ID."`
-	Restore    TransactionRestoreCmd    `cmd:"" help:"Restore soft-deleted transactions by internal ID."`
+	Create      TransactionCreateCmd      `cmd:"" help:"Create one transaction."`
+	CreateBulk  TransactionCreateBulkCmd  `cmd:"create-bulk" help:"Create multiple transactions from JSON."`
+	Update      TransactionUpdateCmd      `cmd:"" help:"Update one transaction by internal ID."`
+	UpdateBulk  TransactionUpdateBulkCmd  `cmd:"update-bulk" help:"Update multiple transactions from JSON."`
+	Get         TransactionGetCmd         `cmd:"" help:"Get transactions by internal ID."`
+	List        TransactionListCmd        `cmd:"" help:"List transactions with filters, sorting, and pagination."`
+	Delete      TransactionDeleteCmd      `cmd:"" help:"Soft-delete transactions by internal ID."`
+	Restore     TransactionRestoreCmd     `cmd:"" help:"Restore soft-deleted transactions by internal ID."`
+	History     TransactionHistoryCmd     `cmd:"" help:"Show the change history of one transaction."`
+	Tags        TransactionTagsCmd        `cmd:"" help:"Count and total transactions by tag."`
+	Aggregate   TransactionAggregateCmd   `cmd:"" help:"Summarize transactions grouped by category, author, household, period, tag, or merchant."`
+	Filters     TransactionFiltersCmd     `cmd:"" help:"Manage saved transaction filters."`
+	Attach      TransactionAttachCmd      `cmd:"" help:"Attach a receipt or document to one transaction."`
+	Attachments TransactionAttachmentsCmd `cmd:"" help:"List the attachments of one transaction."`
 }
 
 type TransactionCreateCmd struct {
@@ -29,6 +38,7 @@ type TransactionCreateCmd struct {
 	AuthorTelegramID  *string   `help:"Author Telegram user ID. Exactly one author selector may be provided."`
 	AuthorPhoneNumber *string   `help:"Author phone number. Exactly one author selector may be provided."`
 	AuthorWhatsappID  *string   `help:"Author WhatsApp ID. Exactly one author selector may be provided."`
+	Tags              []string  `name:"tag" placeholder:"TAG" help:"Label for the transaction, for example reimbursable. Repeat or comma-separate for several."`
 }
 
 func (c *TransactionCreateCmd) Run(ctx *runContext) error {
@@ -36,6 +46,7 @@ func (c *TransactionCreateCmd) Run(ctx *runContext) error {
 		Amount: c.Amount, TransactionDate: c.TransactionDate, Description: c.Description, Notes: c.Notes,
 		CategoryCode: c.Category, HouseholdID: c.HouseholdID,
 		Author: identity(c.AuthorID, c.AuthorDiscordID, c.AuthorTelegramID, c.AuthorPhoneNumber, c.AuthorWhatsappID),
+		Tags:   c.Tags,
 	})
 	if err != nil {
 		return err
@@ -76,6 +87,10 @@ type TransactionUpdateCmd struct {
 	ClearNotes        bool       `help:"Clear the transaction notes."`
 	ClearCategory     bool       `help:"Clear the transaction category."`
 	ClearHouseholdID  bool       `help:"Clear the household ID."`
+	Tags              []string   `name:"tag" placeholder:"TAG" help:"Replacement labels. Repeat or comma-separate for several; the transaction keeps only these."`
+	ClearTags         bool       `help:"Remove every label from the transaction."`
+	UpdatedByUserID   *int64     `placeholder:"INT-64" help:"Internal user ID of the person performing the update, recorded in the transaction history."`
+	IfMatch           *int64     `placeholder:"VERSION" help:"Only update while the transaction is still at this version; fails otherwise."`
 }
 
 func (c *TransactionUpdateCmd) Run(ctx *runContext) error {
@@ -91,11 +106,14 @@ func (c *TransactionUpdateCmd) Run(ctx *runContext) error {
 		ClearNotes:       c.ClearNotes,
 		ClearCategoryID:  c.ClearCategory,
 		ClearHouseholdID: c.ClearHouseholdID,
+		Tags:             c.Tags,
+		ClearTags:        c.ClearTags,
+		UpdatedByUserID:  c.UpdatedByUserID,
 	}
 	if selector != (api.IdentitySelector{}) {
 		req.Author = &selector
 	}
-	transaction, err := ctx.transactions.UpdateTransaction(ctx.Context, c.ID, req)
+	transaction, err := ctx.transactions.UpdateTransaction(ctx.Context, c.ID, req, ifMatch(c.IfMatch)...)
 	if err != nil {
 		return err
 	}
@@ -129,10 +147,11 @@ func (c *TransactionGetCmd) Run(ctx *runContext) error {
 	if err != nil {
 		return err
 	}
-	txs, err := ctx.transactions.ListTransactions(ctx.Context, api.ListTransactionsQuery{IDs: ids, IncludeDeleted: c.IncludeDeleted})
+	page, err := ctx.transactions.ListTransactions(ctx.Context, api.ListTransactionsQuery{IDs: ids, IncludeDeleted: c.IncludeDeleted})
 	if err != nil {
 		return err
 	}
+	txs := page.Items
 	if c.Format == "compact" && len(txs) == 1 {
 		return RenderTransactionCompact(ctx.stdout, txs[0])
 	}
@@ -145,31 +164,64 @@ type TransactionListCmd struct {
 	HouseholdID    *int64     `placeholder:"INT-64" help:"Filter by internal household ID."`
 	FromDate       *time.Time `placeholder:"RFC3339" help:"Include transactions on or after this RFC3339 timestamp."`
 	ToDate         *time.Time `placeholder:"RFC3339" help:"Include transactions on or before this RFC3339 timestamp."`
-	Search         *string    `help:"Case-insensitive search across description and notes."`
-	Sort           string     `help:"Sort field: transaction_date, created_at, amount, or id. Defaults to transaction_date."`
+	Search         *string    `help:"Search description and notes by word, tolerating misspellings. Matches carry a highlighted snippet."`
+	Tags           []string   `name:"tag" placeholder:"TAG" help:"Only transactions with this label. Repeat or comma-separate for several."`
+	TagMatch       string     `default:"any" enum:"any,all" help:"With several --tag values, match transactions carrying any or all of them."`
+	Query          string     `placeholder:"EXPRESSION" help:"Filter expression, for example 'amount > 50 and not tag:reimbursable'."`
+	Filter         string     `placeholder:"NAME" help:"Only transactions matching this saved filter."`
+	Sort           string     `help:"Sort field: transaction_date, created_at, amount, id, or relevance (requires --search). Defaults to transaction_date."`
 	Order          string     `name:"order" help:"Sort order: asc or desc. Defaults to desc."`
-	Limit          int32      `default:"100" help:"Maximum number of transactions to return."`
+	Limit          int32      `default:"100" help:"Maximum number of transactions to return, or the page size with --all."`
 	Offset         int32      `help:"Number of matching transactions to skip before returning results."`
+	Cursor         string     `help:"Continue after the page that printed this cursor. Only valid with the same --sort and --order."`
+	All            bool       `help:"Follow cursors and return every matching transaction."`
 	IncludeDeleted bool       `help:"Include soft-deleted transactions."`
 	OnlyDeleted    bool       `help:"Return only soft-deleted transactions."`
 }
 
 func (c *TransactionListCmd) Run(ctx *runContext) error {
-	txs, err := ctx.transactions.ListTransactions(ctx.Context, api.ListTransactionsQuery{
+	if c.Offset != 0 && (c.All || c.Cursor != "") {
+		return NewCLIError("--offset cannot be combined with --all or --cursor")
+	}
+	query := api.ListTransactionsQuery{
 		AuthorID:       c.AuthorID,
 		HouseholdID:    c.HouseholdID,
 		FromDate:       c.FromDate,
 		ToDate:         c.ToDate,
 		Search:         c.Search,
+		Tags:           c.Tags,
+		Query:          c.Query,
+		Filter:         c.Filter,
 		Sort:           c.Sort,
 		SortOrder:      c.Order,
 		Limit:          c.Limit,
 		Offset:         c.Offset,
+		Cursor:         c.Cursor,
 		IncludeDeleted: c.IncludeDeleted,
 		OnlyDeleted:    c.OnlyDeleted,
-	})
-	if err != nil {
-		return err
+	}
+	if len(c.Tags) > 0 {
+		query.TagMatch = c.TagMatch
+	}
+	txs := []api.Transaction{}
+	if c.All {
+		for tx, err := range ctx.transactions.AllTransactions(ctx.Context, query) {
+			if err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+		}
+	} else {
+		page, err := ctx.transactions.ListTransactions(ctx.Context, query)
+		if err != nil {
+			return err
+		}
+		txs = page.Items
+		if page.NextCursor != "" {
+			if _, err := fmt.Fprintf(ctx.stderr, "More transactions match; continue with --cursor %s or use --all\n", page.NextCursor); err != nil {
+				return err
+			}
+		}
 	}
 	if c.Format == "csv" {
 		return RenderTransactionsCSV(ctx.stdout, txs)
@@ -178,17 +230,38 @@ func (c *TransactionListCmd) Run(ctx *runContext) error {
 }
 
 type TransactionDeleteCmd struct {
-	IDs             string  `name:"ids" required:"" help:"Comma-separated internal transaction IDs, for example 101,102,103."`
+	IDs             string  `name:"ids" required:"" help:"Comma-separated internal transaction IDs, for example 101,102,103. Write ID@VERSION, as in 101@4, to delete a transaction only while it is still at that version."`
 	Reason          *string `help:"Optional reason stored with the soft delete."`
 	DeletedByUserID int64   `required:"" help:"Internal user ID of the person performing the delete."`
 }
 
 func (c *TransactionDeleteCmd) Run(ctx *runContext) error {
-	ids, err := parseIDs(c.IDs)
-	if err != nil {
-		return err
+	request := api.DeleteTransactionsRequest{DeletedByUserID: c.DeletedByUserID, Reason: c.Reason}
+	versioned := false
+	for _, part := range strings.Split(c.IDs, ",") {
+		rawID, rawVersion, hasVersion := strings.Cut(strings.TrimSpace(part), "@")
+		id, err := strconv.ParseInt(rawID, 10, 64)
+		if err != nil {
+			return NewCLIError(fmt.Sprintf("transaction ID %q must be a number", rawID))
+		}
+		item := api.DeleteTransaction{ID: id}
+		if hasVersion {
+			version, err := strconv.ParseInt(rawVersion, 10, 64)
+			if err != nil {
+				return NewCLIError(fmt.Sprintf("version %q of transaction %d must be a number", rawVersion, id))
+			}
+			item.ExpectedVersion, versioned = &version, true
+		}
+		request.Transactions = append(request.Transactions, item)
+	}
+	// Without versions, send plain ids, which older servers also accept.
+	if !versioned {
+		for _, item := range request.Transactions {
+			request.IDs = append(request.IDs, item.ID)
+		}
+		request.Transactions = nil
 	}
-	result, err := ctx.transactions.DeleteTransactions(ctx.Context, api.DeleteTransactionsRequest{IDs: ids, DeletedByUserID: c.DeletedByUserID, Reason: c.Reason})
+	result, err := ctx.transactions.DeleteTransactions(ctx.Context, request)
 	if err != nil {
 		return err
 	}
@@ -211,3 +284,100 @@ func (c *TransactionRestoreCmd) Run(ctx *runContext) error {
 	}
 	return renderBulkResult(ctx.stdout, result)
 }
+
+type TransactionHistoryCmd struct {
+	ID int64 `required:"" help:"Internal transaction ID."`
+}
+
+func (c *TransactionHistoryCmd) Run(ctx *runContext) error {
+	history, err := ctx.transactions.TransactionHistory(ctx.Context, c.ID)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, history)
+}
+
+type TransactionTagsCmd struct {
+	AuthorID    *int64     `placeholder:"INT-64" help:"Only count transactions by this internal author user ID."`
+	HouseholdID *int64     `placeholder:"INT-64" help:"Only count transactions of this internal household ID."`
+	FromDate    *time.Time `placeholder:"RFC3339" help:"Include transactions on or after this RFC3339 timestamp."`
+	ToDate      *time.Time `placeholder:"RFC3339" help:"Include transactions on or before this RFC3339 timestamp."`
+	Query       string     `placeholder:"EXPRESSION" help:"Only count transactions matching this filter expression."`
+	Filter      string     `placeholder:"NAME" help:"Only count transactions matching this saved filter."`
+}
+
+func (c *TransactionTagsCmd) Run(ctx *runContext) error {
+	totals, err := ctx.transactions.TransactionTagTotals(ctx.Context, api.TagTotalsQuery{AuthorID: c.AuthorID, HouseholdID: c.HouseholdID, FromDate: c.FromDate, ToDate: c.ToDate, Query: c.Query, Filter: c.Filter})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, totals)
+}
+
+type TransactionAggregateCmd struct {
+	By             []string   `placeholder:"DIMENSION" help:"Group by category, author, household, day, week, month, tag, or merchant. Repeat or comma-separate; use at most one of day, week, and month."`
+	Metrics        []string   `placeholder:"METRIC" help:"Summarize with sum, count, avg, min, or max. Repeat or comma-separate. Defaults to sum and count."`
+	AuthorID       *int64     `placeholder:"INT-64" help:"Filter by internal author user ID."`
+	HouseholdID    *int64     `placeholder:"INT-64" help:"Filter by internal household ID."`
+	FromDate       *time.Time `placeholder:"RFC3339" help:"Include transactions on or after this RFC3339 timestamp."`
+	ToDate         *time.Time `placeholder:"RFC3339" help:"Include transactions on or before this RFC3339 timestamp."`
+	Search         *string    `help:"Search description and notes by word, tolerating misspellings."`
+	Tags           []string   `name:"tag" placeholder:"TAG" help:"Only transactions with this label. Repeat or comma-separate for several."`
+	TagMatch       string     `default:"any" enum:"any,all" help:"With several --tag values, match transactions carrying any or all of them."`
+	Query          string     `placeholder:"EXPRESSION" help:"Filter expression, for example 'amount > 50 and not tag:reimbursable'."`
+	Filter         string     `placeholder:"NAME" help:"Only transactions matching this saved filter."`
+	IncludeDeleted bool       `help:"Include soft-deleted transactions."`
+	OnlyDeleted    bool       `help:"Only soft-deleted transactions."`
+	Format         string     `default:"table" enum:"table,json" help:"Output format: table or json."`
+}
+
+func (c *TransactionAggregateCmd) Run(ctx *runContext) error {
+	aggregate, err := ctx.transactions.AggregateTransactions(ctx.Context, api.AggregateTransactionsQuery{
+		AuthorID: c.AuthorID, HouseholdID: c.HouseholdID, FromDate: c.FromDate, ToDate: c.ToDate, Search: c.Search, Tags: c.Tags, TagMatch: c.TagMatch,
+		Query: c.Query, Filter: c.Filter, IncludeDeleted: c.IncludeDeleted, OnlyDeleted: c.OnlyDeleted, By: c.By, Metrics: c.Metrics,
+	})
+	if err != nil {
+		return err
+	}
+	if c.Format == "json" {
+		return RenderJSON(ctx.stdout, aggregate)
+	}
+	return RenderTransactionAggregateTable(ctx.stdout, aggregate)
+}
+
+type TransactionFiltersCmd struct {
+	List   TransactionFilterListCmd   `cmd:"" help:"List saved filters."`
+	Save   TransactionFilterSaveCmd   `cmd:"" help:"Save a filter expression under a name, replacing any filter with that name."`
+	Delete TransactionFilterDeleteCmd `cmd:"" help:"Delete a saved filter."`
+}
+
+type TransactionFilterListCmd struct{}
+
+func (c *TransactionFilterListCmd) Run(ctx *runContext) error {
+	filters, err := ctx.transactions.ListSavedFilters(ctx.Context)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, filters)
+}
+
+type TransactionFilterSaveCmd struct {
+	Name  string `arg:"" required:"" help:"Filter name, for example big-spend."`
+	Query string `required:"" placeholder:"EXPRESSION" help:"Filter expression, for example 'amount > 100'."`
+}
+
+func (c *TransactionFilterSaveCmd) Run(ctx *runContext) error {
+	filter, err := ctx.transactions.SaveFilter(ctx.Context, c.Name, api.SaveFilterRequest{Query: c.Query})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, filter)
+}
+
+type TransactionFilterDeleteCmd struct {
+	Name string `arg:"" required:"" help:"Filter name."`
+}
+
+func (c *TransactionFilterDeleteCmd) Run(ctx *runContext) error {
+	return ctx.transactions.DeleteSavedFilter(ctx.Context, c.Name)
+}
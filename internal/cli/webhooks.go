@@ -0,0 +1,121 @@
+package cli
+
+import "rdmm404/voltr-finance/internal/api"
+
+type WebhooksCmd struct {
+	Create     WebhookCreateCmd     `cmd:"" help:"Subscribe a URL to finance events. The signing secret is only shown here."`
+	List       WebhookListCmd       `cmd:"" help:"List webhooks."`
+	Get        WebhookGetCmd        `cmd:"" help:"Show one webhook."`
+	Update     WebhookUpdateCmd     `cmd:"" help:"Update a webhook."`
+	Delete     WebhookDeleteCmd     `cmd:"" help:"Delete a webhook and its delivery history."`
+	Deliveries WebhookDeliveriesCmd `cmd:"" help:"List a webhook's recent deliveries with their attempts."`
+	Redeliver  WebhookRedeliverCmd  `cmd:"" help:"Queue a delivery to be sent again."`
+}
+
+type WebhookCreateCmd struct {
+	URL         string  `required:"" help:"Absolute http or https URL that receives the events."`
+	Events      *string `help:"Comma-separated event types. Defaults to every type."`
+	HouseholdID *int64  `placeholder:"INT-64" help:"Only this household's events."`
+	Secret      *string `help:"Signing secret of at least 16 characters. Generated when omitted."`
+}
+
+func (c *WebhookCreateCmd) Run(ctx *runContext) error {
+	webhook, err := ctx.webhooks.CreateWebhook(ctx.Context, api.CreateWebhookRequest{
+		URL: c.URL, EventTypes: parseOptionalCSV(c.Events), HouseholdID: c.HouseholdID, Secret: c.Secret,
+	})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, webhook)
+}
+
+type WebhookListCmd struct {
+	HouseholdID *int64 `placeholder:"INT-64" help:"Only webhooks filtered to this household."`
+}
+
+func (c *WebhookListCmd) Run(ctx *runContext) error {
+	webhooks, err := ctx.webhooks.ListWebhooks(ctx.Context, api.WebhookQuery{HouseholdID: c.HouseholdID})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, webhooks)
+}
+
+type WebhookGetCmd struct {
+	ID int64 `arg:"" required:"" help:"Webhook ID."`
+}
+
+func (c *WebhookGetCmd) Run(ctx *runContext) error {
+	webhook, err := ctx.webhooks.GetWebhook(ctx.Context, c.ID)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, webhook)
+}
+
+type WebhookUpdateCmd struct {
+	ID               int64   `arg:"" required:"" help:"Webhook ID."`
+	URL              *string `help:"Replacement URL."`
+	Events           *string `help:"Replacement comma-separated event types. An empty value subscribes to every type."`
+	HouseholdID      *int64  `placeholder:"INT-64" help:"Replacement household filter."`
+	Secret           *string `help:"Replacement signing secret of at least 16 characters."`
+	Enable           bool    `help:"Resume deliveries."`
+	Disable          bool    `help:"Pause deliveries. Events are still recorded but not sent."`
+	ClearHouseholdID bool    `help:"Receive events from every household."`
+}
+
+func (c *WebhookUpdateCmd) Run(ctx *runContext) error {
+	if c.Enable && c.Disable {
+		return NewCLIError("--enable and --disable are mutually exclusive")
+	}
+	request := api.UpdateWebhookRequest{URL: c.URL, HouseholdID: c.HouseholdID, Secret: c.Secret, ClearHouseholdID: c.ClearHouseholdID}
+	if c.Events != nil {
+		types := parseOptionalCSV(c.Events)
+		if types == nil {
+			types = []string{}
+		}
+		request.EventTypes = &types
+	}
+	if c.Enable || c.Disable {
+		active := c.Enable
+		request.Active = &active
+	}
+	webhook, err := ctx.webhooks.UpdateWebhook(ctx.Context, c.ID, request)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, webhook)
+}
+
+type WebhookDeleteCmd struct {
+	ID int64 `arg:"" required:"" help:"Webhook ID."`
+}
+
+func (c *WebhookDeleteCmd) Run(ctx *runContext) error {
+	return ctx.webhooks.DeleteWebhook(ctx.Context, c.ID)
+}
+
+type WebhookDeliveriesCmd struct {
+	ID    int64 `arg:"" required:"" help:"Webhook ID."`
+	Limit int   `help:"Maximum deliveries to return, newest first. Defaults to 20."`
+}
+
+func (c *WebhookDeliveriesCmd) Run(ctx *runContext) error {
+	deliveries, err := ctx.webhooks.ListWebhookDeliveries(ctx.Context, c.ID, api.WebhookDeliveryQuery{Limit: c.Limit})
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, deliveries)
+}
+
+type WebhookRedeliverCmd struct {
+	ID int64 `arg:"" required:"" help:"Delivery ID."`
+}
+
+func (c *WebhookRedeliverCmd) Run(ctx *runContext) error {
+	delivery, err := ctx.webhooks.RedeliverWebhookDelivery(ctx.Context, c.ID)
+	if err != nil {
+		return err
+	}
+	return RenderJSON(ctx.stdout, delivery)
+}
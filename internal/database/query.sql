@@ -44,13 +44,15 @@ SET
     END,
     updated_at = CURRENT_TIMESTAMP
 WHERE code = sqlc.arg(code)::VARCHAR
+  AND (sqlc.narg(expected_version)::BIGINT IS NULL OR version = sqlc.narg(expected_version)::BIGINT)
 RETURNING *;
 
 -- name: DeactivateCategory :one
 UPDATE category
 SET is_active = false,
     updated_at = CURRENT_TIMESTAMP
-WHERE code = $1
+WHERE code = sqlc.arg(code)::VARCHAR
+  AND (sqlc.narg(expected_version)::BIGINT IS NULL OR version = sqlc.narg(expected_version)::BIGINT)
 RETURNING *;
 
 -- ******************* budget *******************
@@ -60,6 +62,7 @@ RETURNING *;
 SELECT * FROM budget
 WHERE household_id = sqlc.arg(household_id)::BIGINT
   AND user_id IS NULL
+  AND period_kind = sqlc.arg(period_kind)::VARCHAR
   AND period_start = sqlc.arg(period_start)::DATE
   AND period_end = sqlc.arg(period_end)::DATE;
 
@@ -67,6 +70,7 @@ WHERE household_id = sqlc.arg(household_id)::BIGINT
 SELECT * FROM budget
 WHERE user_id = sqlc.arg(user_id)::BIGINT
   AND household_id IS NULL
+  AND period_kind = sqlc.arg(period_kind)::VARCHAR
   AND period_start = sqlc.arg(period_start)::DATE
   AND period_end = sqlc.arg(period_end)::DATE;
 
@@ -83,6 +87,7 @@ FOR UPDATE;
 SELECT * FROM budget
 WHERE household_id = sqlc.arg(household_id)::BIGINT
   AND user_id IS NULL
+  AND period_kind = sqlc.arg(period_kind)::VARCHAR
   AND period_start < sqlc.arg(period_start)::DATE
 ORDER BY period_start DESC, id DESC
 LIMIT 1;
@@ -91,6 +96,7 @@ LIMIT 1;
 SELECT * FROM budget
 WHERE user_id = sqlc.arg(user_id)::BIGINT
   AND household_id IS NULL
+  AND period_kind = sqlc.arg(period_kind)::VARCHAR
   AND period_start < sqlc.arg(period_start)::DATE
 ORDER BY period_start DESC, id DESC
 LIMIT 1;
@@ -112,6 +118,71 @@ JOIN category c ON c.id = blc.category_id
 WHERE blc.budget_id = sqlc.arg(budget_id)::BIGINT
 ORDER BY blc.budget_line_id ASC, c.name ASC, c.id ASC;
 
+-- name: ListBudgetLineAlertRules :many
+SELECT r.budget_line_id, r.threshold_percent
+FROM budget_line_alert_rule r
+JOIN budget_line bl ON bl.id = r.budget_line_id
+WHERE bl.budget_id = sqlc.arg(budget_id)::BIGINT
+ORDER BY r.budget_line_id ASC, r.threshold_percent ASC;
+
+-- name: ListBudgetLineMemberAllocations :many
+SELECT
+    a.budget_line_id,
+    a.user_id,
+    u.name AS user_name,
+    a.allocation_amount,
+    a.allocation_percent
+FROM budget_line_member_allocation a
+JOIN budget_line bl ON bl.id = a.budget_line_id
+JOIN users u ON u.id = a.user_id
+WHERE bl.budget_id = sqlc.arg(budget_id)::BIGINT
+ORDER BY a.budget_line_id ASC, u.name ASC, u.id ASC;
+
+-- name: ListBudgetHouseholdMemberIds :many
+SELECT hu.user_id
+FROM budget b
+JOIN household_user hu ON hu.household_id = b.household_id
+WHERE b.id = sqlc.arg(budget_id)::BIGINT
+ORDER BY hu.user_id ASC;
+
+-- name: ListBudgetReallocations :many
+SELECT
+    r.id,
+    r.budget_id,
+    r.from_line_id,
+    fl.name AS from_line_name,
+    r.to_line_id,
+    tl.name AS to_line_name,
+    r.amount,
+    r.reason,
+    r.created_by_user_id,
+    u.name AS created_by_user_name,
+    r.created_at
+FROM budget_reallocation r
+JOIN budget_line fl ON fl.id = r.from_line_id
+JOIN budget_line tl ON tl.id = r.to_line_id
+JOIN users u ON u.id = r.created_by_user_id
+WHERE r.budget_id = sqlc.arg(budget_id)::BIGINT
+ORDER BY r.created_at ASC, r.id ASC;
+
+-- name: ListBudgetLineOriginalAllocations :many
+-- Each line's allocation before reallocations: its current allocation minus
+-- the net amount moved into it.
+SELECT
+    bl.id AS budget_line_id,
+    (bl.allocation_amount - COALESCE(SUM(moves.amount), 0))::NUMERIC AS original_allocation_amount
+FROM budget_line bl
+LEFT JOIN (
+    SELECT to_line_id AS line_id, amount FROM budget_reallocation
+    WHERE budget_id = sqlc.arg(budget_id)::BIGINT
+    UNION ALL
+    SELECT from_line_id AS line_id, -amount FROM budget_reallocation
+    WHERE budget_id = sqlc.arg(budget_id)::BIGINT
+) moves ON moves.line_id = bl.id
+WHERE bl.budget_id = sqlc.arg(budget_id)::BIGINT
+GROUP BY bl.id, bl.allocation_amount
+ORDER BY bl.id ASC;
+
 -- name: GetBudgetLineById :one
 SELECT * FROM budget_line
 WHERE id = sqlc.arg(id)::BIGINT;
@@ -123,6 +194,53 @@ SELECT COALESCE(MAX(sort_order), 0)::INTEGER AS sort_order
 FROM budget_line
 WHERE budget_id = sqlc.arg(budget_id)::BIGINT;
 
+-- name: ListBudgetLineage :many
+WITH RECURSIVE lineage (id, generation) AS (
+    SELECT b.source_budget_id, 1
+    FROM budget b
+    WHERE b.id = sqlc.arg(id)::BIGINT
+      AND b.source_budget_id IS NOT NULL
+    UNION ALL
+    SELECT b.source_budget_id, l.generation + 1
+    FROM budget b
+    JOIN lineage l ON l.id = b.id
+    WHERE b.source_budget_id IS NOT NULL
+      AND l.generation < sqlc.arg(max_generations)::INTEGER
+)
+SELECT id::BIGINT AS id
+FROM lineage
+ORDER BY generation;
+
+-- name: GetOpenBudgetClosing :one
+SELECT id FROM budget_closing
+WHERE budget_id = sqlc.arg(budget_id)::BIGINT
+  AND reopened_at IS NULL;
+
+-- name: GetBudgetClosingById :one
+SELECT
+    bc.id,
+    bc.budget_id,
+    bc.allocation_amount,
+    bc.actual_amount,
+    bc.unmapped_actual_amount,
+    bc.uncategorized_actual_amount,
+    bc.closed_by_user_id,
+    cu.name AS closed_by_user_name,
+    bc.closed_at,
+    bc.reopened_by_user_id,
+    ru.name AS reopened_by_user_name,
+    bc.reopened_at,
+    bc.reopen_reason
+FROM budget_closing bc
+JOIN users cu ON cu.id = bc.closed_by_user_id
+LEFT JOIN users ru ON ru.id = bc.reopened_by_user_id
+WHERE bc.id = sqlc.arg(id)::BIGINT;
+
+-- name: ListBudgetClosingLines :many
+SELECT * FROM budget_closing_line
+WHERE closing_id = sqlc.arg(closing_id)::BIGINT
+ORDER BY sort_order ASC, id ASC;
+
 -- name: ListBudgetReportLines :many
 SELECT
     bl.id,
@@ -226,13 +344,99 @@ LEFT JOIN budget_line_category blc
 WHERE b.id = sqlc.arg(budget_id)::BIGINT
 ORDER BY blc.budget_line_id ASC NULLS LAST, t.transaction_date ASC, t.id ASC;
 
+-- name: ListBudgetLineHistoryTransactions :many
+-- Transactions in the budget's owner scope and line categories from
+-- history_start up to the day before the budget period starts. Forecasts use
+-- the current line mappings, so history follows the line rather than the
+-- budgets that existed at the time.
+SELECT
+    blc.budget_line_id,
+    t.id,
+    t.transaction_date,
+    ROUND(t.amount::NUMERIC, 2) AS amount,
+    t.description
+FROM budget b
+JOIN budget_line_category blc ON blc.budget_id = b.id
+JOIN transaction t
+    ON t.deleted_at IS NULL
+   AND t.category_id = blc.category_id
+   AND t.transaction_date >= (sqlc.arg(history_start)::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+   AND t.transaction_date < (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+   AND (
+       (b.household_id IS NOT NULL AND t.household_id = b.household_id)
+       OR
+       (b.user_id IS NOT NULL AND t.author_id = b.user_id AND t.household_id IS NULL)
+   )
+WHERE b.id = sqlc.arg(budget_id)::BIGINT
+ORDER BY blc.budget_line_id ASC, t.transaction_date ASC, t.id ASC;
+
+-- name: ListBudgetTrendLines :many
+-- Lines of an owner's monthly budgets that start between period_start and
+-- period_end, with each line's actual spending in its own period.
+SELECT
+    b.id AS budget_id,
+    b.period_start,
+    bl.id,
+    bl.name,
+    bl.allocation_amount,
+    ROUND(COALESCE(SUM(t.amount), 0)::NUMERIC, 2) AS actual_amount,
+    bl.sort_order
+FROM budget b
+JOIN budget_line bl ON bl.budget_id = b.id
+LEFT JOIN budget_line_category blc
+    ON blc.budget_id = b.id
+   AND blc.budget_line_id = bl.id
+LEFT JOIN transaction t
+    ON t.deleted_at IS NULL
+   AND t.category_id = blc.category_id
+   AND t.transaction_date >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+   AND t.transaction_date < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+   AND (
+       (b.household_id IS NOT NULL AND t.household_id = b.household_id)
+       OR
+       (b.user_id IS NOT NULL AND t.author_id = b.user_id AND t.household_id IS NULL)
+   )
+WHERE b.period_kind = 'monthly'
+  AND b.period_start >= sqlc.arg(period_start)::DATE
+  AND b.period_start <= sqlc.arg(period_end)::DATE
+  AND (
+      (sqlc.narg(household_id)::BIGINT IS NOT NULL AND b.household_id = sqlc.narg(household_id)::BIGINT AND b.user_id IS NULL)
+      OR
+      (sqlc.narg(user_id)::BIGINT IS NOT NULL AND b.user_id = sqlc.narg(user_id)::BIGINT AND b.household_id IS NULL)
+  )
+GROUP BY b.id, b.period_start, bl.id, bl.name, bl.allocation_amount, bl.sort_order
+ORDER BY b.period_start ASC, bl.sort_order ASC, bl.id ASC;
+
+-- name: ListBudgetTrendCategoryActuals :many
+-- Owner-scoped spending per UTC calendar month and category, including
+-- uncategorized spending, whether or not a budget existed for the month.
+SELECT
+    DATE_TRUNC('month', t.transaction_date AT TIME ZONE 'UTC')::DATE AS month,
+    c.id AS category_id,
+    c.code AS category_code,
+    c.name AS category_name,
+    ROUND(SUM(t.amount)::NUMERIC, 2) AS actual_amount
+FROM transaction t
+LEFT JOIN category c ON c.id = t.category_id
+WHERE t.deleted_at IS NULL
+  AND t.transaction_date >= (sqlc.arg(period_start)::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+  AND t.transaction_date < ((sqlc.arg(period_end)::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+  AND (
+      (sqlc.narg(household_id)::BIGINT IS NOT NULL AND t.household_id = sqlc.narg(household_id)::BIGINT)
+      OR
+      (sqlc.narg(user_id)::BIGINT IS NOT NULL AND t.author_id = sqlc.narg(user_id)::BIGINT AND t.household_id IS NULL)
+  )
+GROUP BY 1, c.id, c.code, c.name
+ORDER BY 1 ASC, c.name ASC NULLS LAST;
+
 -- WRITES
 
 -- name: CreateHouseholdBudget :one
-INSERT INTO budget (household_id, user_id, period_start, period_end, source_budget_id)
+INSERT INTO budget (household_id, user_id, period_kind, period_start, period_end, source_budget_id)
 VALUES (
     sqlc.arg(household_id)::BIGINT,
     NULL,
+    sqlc.arg(period_kind)::VARCHAR,
     sqlc.arg(period_start)::DATE,
     sqlc.arg(period_end)::DATE,
     sqlc.narg(source_budget_id)::BIGINT
@@ -240,10 +444,11 @@ VALUES (
 RETURNING *;
 
 -- name: CreateUserBudget :one
-INSERT INTO budget (household_id, user_id, period_start, period_end, source_budget_id)
+INSERT INTO budget (household_id, user_id, period_kind, period_start, period_end, source_budget_id)
 VALUES (
     NULL,
     sqlc.arg(user_id)::BIGINT,
+    sqlc.arg(period_kind)::VARCHAR,
     sqlc.arg(period_start)::DATE,
     sqlc.arg(period_end)::DATE,
     sqlc.narg(source_budget_id)::BIGINT
@@ -277,11 +482,13 @@ SET
     END,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg(id)::BIGINT
+  AND (sqlc.narg(expected_version)::BIGINT IS NULL OR version = sqlc.narg(expected_version)::BIGINT)
 RETURNING *;
 
--- name: DeleteBudgetLine :exec
+-- name: DeleteBudgetLine :execrows
 DELETE FROM budget_line
-WHERE id = sqlc.arg(id)::BIGINT;
+WHERE id = sqlc.arg(id)::BIGINT
+  AND (sqlc.narg(expected_version)::BIGINT IS NULL OR version = sqlc.narg(expected_version)::BIGINT);
 
 -- name: DeleteBudgetLineCategories :exec
 DELETE FROM budget_line_category
@@ -295,6 +502,392 @@ VALUES (
     sqlc.arg(category_id)::BIGINT
 );
 
+-- name: DeleteBudgetLineAlertRules :exec
+DELETE FROM budget_line_alert_rule
+WHERE budget_line_id = sqlc.arg(budget_line_id)::BIGINT;
+
+-- name: CreateBudgetLineAlertRule :exec
+INSERT INTO budget_line_alert_rule (budget_line_id, threshold_percent)
+VALUES (
+    sqlc.arg(budget_line_id)::BIGINT,
+    sqlc.arg(threshold_percent)::INTEGER
+);
+
+-- name: DeleteBudgetLineMemberAllocations :exec
+DELETE FROM budget_line_member_allocation
+WHERE budget_line_id = sqlc.arg(budget_line_id)::BIGINT;
+
+-- name: CreateBudgetLineMemberAllocation :exec
+INSERT INTO budget_line_member_allocation (budget_line_id, user_id, allocation_amount, allocation_percent)
+VALUES (
+    sqlc.arg(budget_line_id)::BIGINT,
+    sqlc.arg(user_id)::BIGINT,
+    sqlc.narg(allocation_amount)::NUMERIC,
+    sqlc.narg(allocation_percent)::NUMERIC
+);
+
+-- name: AdjustBudgetLineAllocation :one
+UPDATE budget_line
+SET
+    allocation_amount = allocation_amount + sqlc.arg(delta)::NUMERIC,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)::BIGINT
+  AND budget_id = sqlc.arg(budget_id)::BIGINT
+RETURNING *;
+
+-- name: CreateBudgetReallocation :one
+INSERT INTO budget_reallocation (budget_id, from_line_id, to_line_id, amount, reason, created_by_user_id)
+VALUES (
+    sqlc.arg(budget_id)::BIGINT,
+    sqlc.arg(from_line_id)::BIGINT,
+    sqlc.arg(to_line_id)::BIGINT,
+    sqlc.arg(amount)::NUMERIC,
+    sqlc.narg(reason)::VARCHAR,
+    sqlc.arg(created_by_user_id)::BIGINT
+)
+RETURNING id;
+
+-- name: CreateBudgetClosing :one
+INSERT INTO budget_closing (
+    budget_id,
+    allocation_amount,
+    actual_amount,
+    unmapped_actual_amount,
+    uncategorized_actual_amount,
+    closed_by_user_id
+)
+VALUES (
+    sqlc.arg(budget_id)::BIGINT,
+    sqlc.arg(allocation_amount)::NUMERIC,
+    sqlc.arg(actual_amount)::NUMERIC,
+    sqlc.arg(unmapped_actual_amount)::NUMERIC,
+    sqlc.arg(uncategorized_actual_amount)::NUMERIC,
+    sqlc.arg(closed_by_user_id)::BIGINT
+)
+RETURNING id;
+
+-- name: CreateBudgetClosingLine :exec
+INSERT INTO budget_closing_line (closing_id, budget_line_id, name, allocation_amount, actual_amount, sort_order)
+VALUES (
+    sqlc.arg(closing_id)::BIGINT,
+    sqlc.narg(budget_line_id)::BIGINT,
+    sqlc.arg(name)::VARCHAR,
+    sqlc.arg(allocation_amount)::NUMERIC,
+    sqlc.arg(actual_amount)::NUMERIC,
+    sqlc.arg(sort_order)::INTEGER
+);
+
+-- name: ReopenBudgetClosing :one
+UPDATE budget_closing
+SET
+    reopened_by_user_id = sqlc.arg(reopened_by_user_id)::BIGINT,
+    reopened_at = CURRENT_TIMESTAMP,
+    reopen_reason = sqlc.arg(reopen_reason)::VARCHAR
+WHERE budget_id = sqlc.arg(budget_id)::BIGINT
+  AND reopened_at IS NULL
+RETURNING id;
+
+-- ******************* budget alert *******************
+-- READS
+
+-- name: ListBudgetAlertCandidates :many
+-- Alert rules not yet triggered on lines that map the transaction's category,
+-- in every budget whose owner scope and period contain the transaction, with
+-- the line's current actual amount.
+SELECT
+    b.id AS budget_id,
+    bl.id AS budget_line_id,
+    bl.allocation_amount,
+    r.threshold_percent,
+    actual.actual_amount
+FROM transaction t
+JOIN budget_line_category blc ON blc.category_id = t.category_id
+JOIN budget b ON b.id = blc.budget_id
+JOIN budget_line bl ON bl.id = blc.budget_line_id
+JOIN budget_line_alert_rule r ON r.budget_line_id = bl.id
+CROSS JOIN LATERAL (
+    SELECT ROUND(COALESCE(SUM(lt.amount), 0)::NUMERIC, 2)::NUMERIC AS actual_amount
+    FROM budget_line_category lc
+    JOIN transaction lt
+        ON lt.deleted_at IS NULL
+       AND lt.category_id = lc.category_id
+       AND lt.transaction_date >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+       AND lt.transaction_date < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+       AND (
+           (b.household_id IS NOT NULL AND lt.household_id = b.household_id)
+           OR
+           (b.user_id IS NOT NULL AND lt.author_id = b.user_id AND lt.household_id IS NULL)
+       )
+    WHERE lc.budget_line_id = bl.id
+) actual
+WHERE t.id = sqlc.arg(transaction_id)::BIGINT
+  AND t.deleted_at IS NULL
+  AND t.transaction_date >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+  AND t.transaction_date < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+  AND (
+      (b.household_id IS NOT NULL AND t.household_id = b.household_id)
+      OR
+      (b.user_id IS NOT NULL AND t.author_id = b.user_id AND t.household_id IS NULL)
+  )
+  AND NOT EXISTS (
+      SELECT 1
+      FROM budget_alert a
+      WHERE a.budget_line_id = bl.id
+        AND a.threshold_percent = r.threshold_percent
+  )
+ORDER BY b.id ASC, bl.sort_order ASC, bl.id ASC, r.threshold_percent ASC;
+
+-- name: ListBudgetAlerts :many
+SELECT
+    a.id,
+    a.budget_id,
+    a.budget_line_id,
+    bl.name AS budget_line_name,
+    b.household_id,
+    b.user_id,
+    b.period_kind,
+    b.period_start,
+    b.period_end,
+    a.threshold_percent,
+    a.allocation_amount,
+    a.actual_amount,
+    a.transaction_id,
+    a.notified_at,
+    a.notification_error,
+    a.created_at
+FROM budget_alert a
+JOIN budget b ON b.id = a.budget_id
+JOIN budget_line bl ON bl.id = a.budget_line_id
+WHERE (sqlc.narg(id)::BIGINT IS NULL OR a.id = sqlc.narg(id)::BIGINT)
+  AND (sqlc.narg(budget_id)::BIGINT IS NULL OR a.budget_id = sqlc.narg(budget_id)::BIGINT)
+  AND (sqlc.narg(household_id)::BIGINT IS NULL OR b.household_id = sqlc.narg(household_id)::BIGINT)
+  AND (sqlc.narg(user_id)::BIGINT IS NULL OR b.user_id = sqlc.narg(user_id)::BIGINT)
+ORDER BY a.created_at DESC, a.id DESC
+LIMIT sqlc.arg(row_limit)::INTEGER;
+
+-- WRITES
+
+-- name: CreateBudgetAlert :one
+-- Returns no row when the line already alerted at this threshold.
+INSERT INTO budget_alert (budget_id, budget_line_id, threshold_percent, allocation_amount, actual_amount, transaction_id)
+VALUES (
+    sqlc.arg(budget_id)::BIGINT,
+    sqlc.arg(budget_line_id)::BIGINT,
+    sqlc.arg(threshold_percent)::INTEGER,
+    sqlc.arg(allocation_amount)::NUMERIC,
+    sqlc.arg(actual_amount)::NUMERIC,
+    sqlc.narg(transaction_id)::BIGINT
+)
+ON CONFLICT (budget_line_id, threshold_percent) DO NOTHING
+RETURNING id;
+
+-- name: MarkBudgetAlertNotified :exec
+UPDATE budget_alert
+SET
+    notified_at = CURRENT_TIMESTAMP,
+    notification_error = sqlc.narg(notification_error)::VARCHAR
+WHERE id = sqlc.arg(id)::BIGINT;
+
+-- ******************* budget template *******************
+-- READS
+
+-- name: GetBudgetTemplateById :one
+SELECT * FROM budget_template
+WHERE id = sqlc.arg(id)::BIGINT;
+
+-- name: GetHouseholdBudgetTemplateByName :one
+SELECT * FROM budget_template
+WHERE household_id = sqlc.arg(household_id)::BIGINT
+  AND lower(name) = lower(sqlc.arg(name)::VARCHAR);
+
+-- name: GetUserBudgetTemplateByName :one
+SELECT * FROM budget_template
+WHERE user_id = sqlc.arg(user_id)::BIGINT
+  AND lower(name) = lower(sqlc.arg(name)::VARCHAR);
+
+-- name: ListBudgetTemplates :many
+SELECT * FROM budget_template
+WHERE (sqlc.narg(household_id)::BIGINT IS NULL OR household_id = sqlc.narg(household_id)::BIGINT)
+  AND (sqlc.narg(user_id)::BIGINT IS NULL OR user_id = sqlc.narg(user_id)::BIGINT)
+ORDER BY lower(name) ASC, id ASC;
+
+-- name: ListBudgetTemplateLines :many
+SELECT * FROM budget_template_line
+WHERE template_id = ANY(sqlc.arg(template_ids)::BIGINT[])
+ORDER BY template_id ASC, sort_order ASC, id ASC;
+
+-- name: ListBudgetTemplateLineCategories :many
+SELECT
+    tlc.template_id,
+    tlc.template_line_id,
+    tlc.category_id,
+    c.code AS category_code,
+    c.name AS category_name
+FROM budget_template_line_category tlc
+JOIN category c ON c.id = tlc.category_id
+WHERE tlc.template_id = ANY(sqlc.arg(template_ids)::BIGINT[])
+ORDER BY tlc.template_line_id ASC, c.name ASC, c.id ASC;
+
+-- WRITES
+
+-- name: CreateBudgetTemplate :one
+INSERT INTO budget_template (household_id, user_id, name)
+VALUES (
+    sqlc.narg(household_id)::BIGINT,
+    sqlc.narg(user_id)::BIGINT,
+    sqlc.arg(name)::VARCHAR
+)
+RETURNING *;
+
+-- name: UpdateBudgetTemplate :one
+UPDATE budget_template
+SET
+    name = CASE
+        WHEN sqlc.arg(set_name)::bool THEN sqlc.arg(name)::VARCHAR
+        ELSE name
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)::BIGINT
+RETURNING *;
+
+-- name: DeleteBudgetTemplate :exec
+DELETE FROM budget_template
+WHERE id = sqlc.arg(id)::BIGINT;
+
+-- name: DeleteBudgetTemplateLines :exec
+DELETE FROM budget_template_line
+WHERE template_id = sqlc.arg(template_id)::BIGINT;
+
+-- name: CreateBudgetTemplateLine :one
+INSERT INTO budget_template_line (template_id, name, allocation_amount, sort_order)
+VALUES (
+    sqlc.arg(template_id)::BIGINT,
+    sqlc.arg(name)::VARCHAR,
+    sqlc.arg(allocation_amount)::NUMERIC,
+    sqlc.arg(sort_order)::INTEGER
+)
+RETURNING *;
+
+-- name: CreateBudgetTemplateLineCategory :exec
+INSERT INTO budget_template_line_category (template_id, template_line_id, category_id)
+VALUES (
+    sqlc.arg(template_id)::BIGINT,
+    sqlc.arg(template_line_id)::BIGINT,
+    sqlc.arg(category_id)::BIGINT
+);
+
+-- ******************* savings goal *******************
+-- READS
+
+-- name: GetSavingsGoalById :one
+SELECT * FROM savings_goal
+WHERE id = sqlc.arg(id)::BIGINT;
+
+-- name: ListSavingsGoalProgress :many
+-- Linked transactions follow the budget owner scope. Positive amounts are
+-- contributions into the fund and negative amounts are withdrawals from it.
+SELECT
+    g.id,
+    g.household_id,
+    g.user_id,
+    g.name,
+    g.target_amount,
+    g.start_date,
+    g.target_date,
+    totals.contributed_amount,
+    totals.withdrawn_amount,
+    totals.period_net_amount
+FROM savings_goal g
+CROSS JOIN LATERAL (
+    SELECT
+        ROUND(COALESCE(SUM(t.amount) FILTER (WHERE t.amount > 0), 0)::NUMERIC, 2)::NUMERIC AS contributed_amount,
+        ROUND(COALESCE(-SUM(t.amount) FILTER (WHERE t.amount < 0), 0)::NUMERIC, 2)::NUMERIC AS withdrawn_amount,
+        ROUND(COALESCE(SUM(t.amount) FILTER (
+            WHERE t.transaction_date >= (sqlc.arg(period_start)::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+        ), 0)::NUMERIC, 2)::NUMERIC AS period_net_amount
+    FROM transaction t
+    WHERE t.deleted_at IS NULL
+      AND t.category_id IN (
+          SELECT gc.category_id
+          FROM savings_goal_category gc
+          WHERE gc.goal_id = g.id
+      )
+      AND t.transaction_date >= (g.start_date::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+      AND t.transaction_date < ((sqlc.arg(as_of)::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+      AND (
+          (g.household_id IS NOT NULL AND t.household_id = g.household_id)
+          OR
+          (g.user_id IS NOT NULL AND t.author_id = g.user_id AND t.household_id IS NULL)
+      )
+) totals
+WHERE (sqlc.narg(id)::BIGINT IS NULL OR g.id = sqlc.narg(id)::BIGINT)
+  AND (sqlc.narg(household_id)::BIGINT IS NULL OR g.household_id = sqlc.narg(household_id)::BIGINT)
+  AND (sqlc.narg(user_id)::BIGINT IS NULL OR g.user_id = sqlc.narg(user_id)::BIGINT)
+ORDER BY g.target_date ASC, g.id ASC;
+
+-- name: ListSavingsGoalCategories :many
+SELECT
+    gc.goal_id,
+    gc.category_id,
+    c.code AS category_code,
+    c.name AS category_name
+FROM savings_goal_category gc
+JOIN category c ON c.id = gc.category_id
+WHERE gc.goal_id = ANY(sqlc.arg(goal_ids)::BIGINT[])
+ORDER BY gc.goal_id ASC, c.name ASC, c.id ASC;
+
+-- WRITES
+
+-- name: CreateSavingsGoal :one
+INSERT INTO savings_goal (household_id, user_id, name, target_amount, start_date, target_date)
+VALUES (
+    sqlc.narg(household_id)::BIGINT,
+    sqlc.narg(user_id)::BIGINT,
+    sqlc.arg(name)::VARCHAR,
+    sqlc.arg(target_amount)::NUMERIC,
+    sqlc.arg(start_date)::DATE,
+    sqlc.arg(target_date)::DATE
+)
+RETURNING *;
+
+-- name: UpdateSavingsGoal :one
+UPDATE savings_goal
+SET
+    name = CASE
+        WHEN sqlc.arg(set_name)::bool THEN sqlc.arg(name)::VARCHAR
+        ELSE name
+    END,
+    target_amount = CASE
+        WHEN sqlc.arg(set_target_amount)::bool THEN sqlc.arg(target_amount)::NUMERIC
+        ELSE target_amount
+    END,
+    start_date = CASE
+        WHEN sqlc.arg(set_start_date)::bool THEN sqlc.arg(start_date)::DATE
+        ELSE start_date
+    END,
+    target_date = CASE
+        WHEN sqlc.arg(set_target_date)::bool THEN sqlc.arg(target_date)::DATE
+        ELSE target_date
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)::BIGINT
+RETURNING *;
+
+-- name: DeleteSavingsGoal :exec
+DELETE FROM savings_goal
+WHERE id = sqlc.arg(id)::BIGINT;
+
+-- name: DeleteSavingsGoalCategories :exec
+DELETE FROM savings_goal_category
+WHERE goal_id = sqlc.arg(goal_id)::BIGINT;
+
+-- name: CreateSavingsGoalCategory :exec
+INSERT INTO savings_goal_category (goal_id, category_id)
+VALUES (
+    sqlc.arg(goal_id)::BIGINT,
+    sqlc.arg(category_id)::BIGINT
+);
+
 -- ******************* transaction *******************
 -- READS
 
@@ -315,6 +908,42 @@ SELECT * FROM transaction
 WHERE id = ANY(sqlc.arg(ids)::BIGINT[])
   AND deleted_at IS NULL;
 
+-- name: LockBudgetsForTransaction :exec
+-- Share-locks every budget whose period and owner cover a transaction with
+-- the given date, household and author, so closing one of them waits for the
+-- transaction write to commit. Run GetClosedBudgetForTransaction afterwards in
+-- its own statement so it sees a closing that committed during the wait.
+SELECT b.id
+FROM budget b
+WHERE sqlc.arg(transaction_date)::TIMESTAMPTZ >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+  AND sqlc.arg(transaction_date)::TIMESTAMPTZ < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+  AND (
+      (b.household_id IS NOT NULL AND b.household_id = sqlc.narg(household_id)::BIGINT)
+      OR
+      (b.user_id IS NOT NULL AND b.user_id = sqlc.arg(author_id)::BIGINT AND sqlc.narg(household_id)::BIGINT IS NULL)
+  )
+ORDER BY b.id
+FOR SHARE;
+
+-- name: GetClosedBudgetForTransaction :one
+-- The earliest closed budget whose period and owner cover a transaction with
+-- the given date, household and author, using the same scope as budget
+-- reports.
+SELECT b.id, b.period_start, b.period_end
+FROM budget b
+JOIN budget_closing bc
+    ON bc.budget_id = b.id
+   AND bc.reopened_at IS NULL
+WHERE sqlc.arg(transaction_date)::TIMESTAMPTZ >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+  AND sqlc.arg(transaction_date)::TIMESTAMPTZ < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+  AND (
+      (b.household_id IS NOT NULL AND b.household_id = sqlc.narg(household_id)::BIGINT)
+      OR
+      (b.user_id IS NOT NULL AND b.user_id = sqlc.arg(author_id)::BIGINT AND sqlc.narg(household_id)::BIGINT IS NULL)
+  )
+ORDER BY b.period_start ASC, b.id ASC
+LIMIT 1;
+
 -- name: GetTransactionsByIdWithDetails :many
 SELECT
     sqlc.embed(t),
@@ -324,7 +953,11 @@ SELECT
     h.name AS household_name,
     c.id AS category_id,
     c.code AS category_code,
-    c.name AS category_name
+    c.name AS category_name,
+    ARRAY(
+        SELECT tg.name FROM transaction_tag tt JOIN tag tg ON tg.id = tt.tag_id
+        WHERE tt.transaction_id = t.id ORDER BY tg.name
+    )::TEXT[] AS tags
 FROM transaction t
 JOIN users u ON u.id = t.author_id
 LEFT JOIN household h ON h.id = t.household_id
@@ -351,11 +984,25 @@ SELECT
     h.name AS household_name,
     c.id AS category_id,
     c.code AS category_code,
-    c.name AS category_name
+    c.name AS category_name,
+    ARRAY(
+        SELECT tg.name FROM transaction_tag tt JOIN tag tg ON tg.id = tt.tag_id
+        WHERE tt.transaction_id = t.id ORDER BY tg.name
+    )::TEXT[] AS tags,
+    r.relevance
 FROM transaction t
 JOIN users u ON u.id = t.author_id
 LEFT JOIN household h ON h.id = t.household_id
 LEFT JOIN category c ON c.id = t.category_id
+CROSS JOIN LATERAL (
+    -- Relevance to the search: the full-text rank of the description and
+    -- notes plus how closely the search resembles a run of their words, or 0
+    -- without a search. The document expression matches the search indexes.
+    SELECT (CASE WHEN sqlc.narg(search)::TEXT IS NULL THEN 0 ELSE
+        ts_rank(to_tsvector('simple', COALESCE(t.description, '') || ' ' || COALESCE(t.notes, '')), websearch_to_tsquery('simple', sqlc.narg(search)::TEXT))
+        + public.word_similarity(sqlc.narg(search)::TEXT, COALESCE(t.description, '') || ' ' || COALESCE(t.notes, ''))
+    END)::FLOAT8 AS relevance
+) r
 WHERE
     (NOT sqlc.arg(only_deleted)::bool OR t.deleted_at IS NOT NULL)
     AND (sqlc.arg(include_deleted)::bool OR sqlc.arg(only_deleted)::bool OR t.deleted_at IS NULL)
@@ -363,25 +1010,173 @@ WHERE
     AND (sqlc.narg(household_id)::BIGINT IS NULL OR t.household_id = sqlc.narg(household_id)::BIGINT)
     AND (sqlc.narg(from_date)::TIMESTAMPTZ IS NULL OR t.transaction_date >= sqlc.narg(from_date)::TIMESTAMPTZ)
     AND (sqlc.narg(to_date)::TIMESTAMPTZ IS NULL OR t.transaction_date <= sqlc.narg(to_date)::TIMESTAMPTZ)
+    -- Search: matching words in any order, a close spelling of them, or the
+    -- exact text anywhere in the description or notes.
     AND (
         sqlc.narg(search)::TEXT IS NULL
+        OR to_tsvector('simple', COALESCE(t.description, '') || ' ' || COALESCE(t.notes, '')) @@ websearch_to_tsquery('simple', sqlc.narg(search)::TEXT)
+        OR sqlc.narg(search)::TEXT OPERATOR(public.<%) (COALESCE(t.description, '') || ' ' || COALESCE(t.notes, ''))
         OR t.description ILIKE '%' || sqlc.narg(search)::TEXT || '%'
         OR t.notes ILIKE '%' || sqlc.narg(search)::TEXT || '%'
     )
+    -- Tag filter: any one of the tags, or all of them when tag_match is 'all'.
+    AND (
+        cardinality(sqlc.arg(tags)::TEXT[]) = 0
+        OR (
+            SELECT COUNT(*)
+            FROM transaction_tag tt
+            JOIN tag tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id AND tg.name = ANY(sqlc.arg(tags)::TEXT[])
+        ) >= CASE WHEN sqlc.arg(tag_match)::TEXT = 'all' THEN cardinality(sqlc.arg(tags)::TEXT[]) ELSE 1 END
+    )
+    -- The repository replaces this TRUE with the compiled filter expression.
+    AND /* filter expression */ TRUE
+    -- Keyset cursor: rows strictly after (sort value, id) in the listing order.
+    -- created_at falls back to transaction_date for rows that predate it.
+    AND (
+        sqlc.narg(after_id)::BIGINT IS NULL
+        OR (sqlc.arg(sort)::TEXT = 'transaction_date' AND sqlc.arg(sort_order)::TEXT = 'asc' AND (t.transaction_date, t.id) > (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::BIGINT))
+        OR (sqlc.arg(sort)::TEXT = 'transaction_date' AND sqlc.arg(sort_order)::TEXT = 'desc' AND (t.transaction_date, t.id) < (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::BIGINT))
+        OR (sqlc.arg(sort)::TEXT = 'created_at' AND sqlc.arg(sort_order)::TEXT = 'asc' AND (COALESCE(t.created_at, t.transaction_date), t.id) > (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::BIGINT))
+        OR (sqlc.arg(sort)::TEXT = 'created_at' AND sqlc.arg(sort_order)::TEXT = 'desc' AND (COALESCE(t.created_at, t.transaction_date), t.id) < (sqlc.narg(after_time)::TIMESTAMPTZ, sqlc.narg(after_id)::BIGINT))
+        OR (sqlc.arg(sort)::TEXT = 'amount' AND sqlc.arg(sort_order)::TEXT = 'asc' AND (t.amount, t.id) > (sqlc.narg(after_amount)::REAL, sqlc.narg(after_id)::BIGINT))
+        OR (sqlc.arg(sort)::TEXT = 'amount' AND sqlc.arg(sort_order)::TEXT = 'desc' AND (t.amount, t.id) < (sqlc.narg(after_amount)::REAL, sqlc.narg(after_id)::BIGINT))
+        OR (sqlc.arg(sort)::TEXT = 'relevance' AND sqlc.arg(sort_order)::TEXT = 'asc' AND (r.relevance, t.id) > (sqlc.narg(after_relevance)::FLOAT8, sqlc.narg(after_id)::BIGINT))
+        OR (sqlc.arg(sort)::TEXT = 'relevance' AND sqlc.arg(sort_order)::TEXT = 'desc' AND (r.relevance, t.id) < (sqlc.narg(after_relevance)::FLOAT8, sqlc.narg(after_id)::BIGINT))
+        OR (sqlc.arg(sort)::TEXT = 'id' AND sqlc.arg(sort_order)::TEXT = 'asc' AND t.id > sqlc.narg(after_id)::BIGINT)
+        OR (sqlc.arg(sort)::TEXT = 'id' AND sqlc.arg(sort_order)::TEXT = 'desc' AND t.id < sqlc.narg(after_id)::BIGINT)
+    )
 ORDER BY
     CASE WHEN sqlc.arg(sort)::TEXT = 'transaction_date' AND sqlc.arg(sort_order)::TEXT = 'asc' THEN t.transaction_date END ASC,
     CASE WHEN sqlc.arg(sort)::TEXT = 'transaction_date' AND sqlc.arg(sort_order)::TEXT = 'desc' THEN t.transaction_date END DESC,
-    CASE WHEN sqlc.arg(sort)::TEXT = 'created_at' AND sqlc.arg(sort_order)::TEXT = 'asc' THEN t.created_at END ASC,
-    CASE WHEN sqlc.arg(sort)::TEXT = 'created_at' AND sqlc.arg(sort_order)::TEXT = 'desc' THEN t.created_at END DESC,
+    CASE WHEN sqlc.arg(sort)::TEXT = 'created_at' AND sqlc.arg(sort_order)::TEXT = 'asc' THEN COALESCE(t.created_at, t.transaction_date) END ASC,
+    CASE WHEN sqlc.arg(sort)::TEXT = 'created_at' AND sqlc.arg(sort_order)::TEXT = 'desc' THEN COALESCE(t.created_at, t.transaction_date) END DESC,
     CASE WHEN sqlc.arg(sort)::TEXT = 'amount' AND sqlc.arg(sort_order)::TEXT = 'asc' THEN t.amount END ASC,
     CASE WHEN sqlc.arg(sort)::TEXT = 'amount' AND sqlc.arg(sort_order)::TEXT = 'desc' THEN t.amount END DESC,
-    CASE WHEN sqlc.arg(sort)::TEXT = 'id' AND sqlc.arg(sort_order)::TEXT = 'asc' THEN t.id END ASC,
-    CASE WHEN sqlc.arg(sort)::TEXT = 'id' AND sqlc.arg(sort_order)::TEXT = 'desc' THEN t.id END DESC,
-    t.transaction_date DESC,
+    CASE WHEN sqlc.arg(sort)::TEXT = 'relevance' AND sqlc.arg(sort_order)::TEXT = 'asc' THEN r.relevance END ASC,
+    CASE WHEN sqlc.arg(sort)::TEXT = 'relevance' AND sqlc.arg(sort_order)::TEXT = 'desc' THEN r.relevance END DESC,
+    CASE WHEN sqlc.arg(sort_order)::TEXT = 'asc' THEN t.id END ASC,
     t.id DESC
 LIMIT sqlc.arg(result_limit)::INT
 OFFSET sqlc.arg(result_offset)::INT;
 
+-- name: ListTransactionHistory :many
+-- Oldest change first.
+SELECT sqlc.embed(th), u.name AS actor_name
+FROM transaction_history th
+LEFT JOIN users u ON u.id = th.actor_user_id
+WHERE th.transaction_id = sqlc.arg(transaction_id)::BIGINT
+ORDER BY th.id;
+
+-- name: ListTransactionTags :many
+SELECT tg.name
+FROM transaction_tag tt
+JOIN tag tg ON tg.id = tt.tag_id
+WHERE tt.transaction_id = sqlc.arg(transaction_id)::BIGINT
+ORDER BY tg.name;
+
+-- name: ListTransactionTagTotals :many
+-- Count and total of the live transactions carrying each tag. A transaction
+-- with several tags counts towards each of them.
+SELECT
+    tg.name AS tag,
+    COUNT(*)::BIGINT AS transaction_count,
+    ROUND(SUM(t.amount)::NUMERIC, 2) AS total_amount
+FROM tag tg
+JOIN transaction_tag tt ON tt.tag_id = tg.id
+JOIN transaction t ON t.id = tt.transaction_id AND t.deleted_at IS NULL
+WHERE
+    (sqlc.narg(author_id)::BIGINT IS NULL OR t.author_id = sqlc.narg(author_id)::BIGINT)
+    AND (sqlc.narg(household_id)::BIGINT IS NULL OR t.household_id = sqlc.narg(household_id)::BIGINT)
+    AND (sqlc.narg(from_date)::TIMESTAMPTZ IS NULL OR t.transaction_date >= sqlc.narg(from_date)::TIMESTAMPTZ)
+    AND (sqlc.narg(to_date)::TIMESTAMPTZ IS NULL OR t.transaction_date <= sqlc.narg(to_date)::TIMESTAMPTZ)
+    -- The repository replaces this TRUE with the compiled filter expression.
+    AND /* filter expression */ TRUE
+GROUP BY tg.name
+ORDER BY tg.name;
+
+-- name: AggregateTransactions :many
+-- Groups the transactions a listing would match and summarizes each group.
+-- Each dimension joins only when it is in the dimensions array, and is NULL
+-- on every row otherwise so it does not split groups; likewise the period
+-- with a NULL period unit. Periods start in UTC, and merchants are
+-- descriptions compared ignoring case and surrounding spaces. With the tag
+-- dimension a transaction counts once per tag, or once with a NULL tag when it
+-- has none.
+SELECT
+    date_trunc(sqlc.narg(period_unit)::TEXT, t.transaction_date AT TIME ZONE 'UTC')::DATE AS period,
+    gc.id AS category_id,
+    gc.code AS category_code,
+    gc.name AS category_name,
+    gu.id AS author_id,
+    gu.name AS author_name,
+    gh.id AS household_id,
+    gh.name AS household_name,
+    gtg.name AS tag,
+    MIN(NULLIF(BTRIM(gm.description), '')) AS merchant,
+    COUNT(*)::BIGINT AS transaction_count,
+    ROUND(SUM(t.amount)::NUMERIC, 2) AS total_amount,
+    ROUND(AVG(t.amount)::NUMERIC, 2) AS average_amount,
+    ROUND(MIN(t.amount)::NUMERIC, 2) AS min_amount,
+    ROUND(MAX(t.amount)::NUMERIC, 2) AS max_amount
+FROM transaction t
+LEFT JOIN category gc ON gc.id = t.category_id AND 'category' = ANY(sqlc.arg(dimensions)::TEXT[])
+LEFT JOIN users gu ON gu.id = t.author_id AND 'author' = ANY(sqlc.arg(dimensions)::TEXT[])
+LEFT JOIN household gh ON gh.id = t.household_id AND 'household' = ANY(sqlc.arg(dimensions)::TEXT[])
+LEFT JOIN transaction_tag gtt ON gtt.transaction_id = t.id AND 'tag' = ANY(sqlc.arg(dimensions)::TEXT[])
+LEFT JOIN tag gtg ON gtg.id = gtt.tag_id
+LEFT JOIN transaction gm ON gm.id = t.id AND 'merchant' = ANY(sqlc.arg(dimensions)::TEXT[])
+WHERE
+    (NOT sqlc.arg(only_deleted)::bool OR t.deleted_at IS NOT NULL)
+    AND (sqlc.arg(include_deleted)::bool OR sqlc.arg(only_deleted)::bool OR t.deleted_at IS NULL)
+    AND (sqlc.narg(author_id)::BIGINT IS NULL OR t.author_id = sqlc.narg(author_id)::BIGINT)
+    AND (sqlc.narg(household_id)::BIGINT IS NULL OR t.household_id = sqlc.narg(household_id)::BIGINT)
+    AND (sqlc.narg(from_date)::TIMESTAMPTZ IS NULL OR t.transaction_date >= sqlc.narg(from_date)::TIMESTAMPTZ)
+    AND (sqlc.narg(to_date)::TIMESTAMPTZ IS NULL OR t.transaction_date <= sqlc.narg(to_date)::TIMESTAMPTZ)
+    -- The search and tag filters repeat those of ListTransactions.
+    AND (
+        sqlc.narg(search)::TEXT IS NULL
+        OR to_tsvector('simple', COALESCE(t.description, '') || ' ' || COALESCE(t.notes, '')) @@ websearch_to_tsquery('simple', sqlc.narg(search)::TEXT)
+        OR sqlc.narg(search)::TEXT OPERATOR(public.<%) (COALESCE(t.description, '') || ' ' || COALESCE(t.notes, ''))
+        OR t.description ILIKE '%' || sqlc.narg(search)::TEXT || '%'
+        OR t.notes ILIKE '%' || sqlc.narg(search)::TEXT || '%'
+    )
+    AND (
+        cardinality(sqlc.arg(tags)::TEXT[]) = 0
+        OR (
+            SELECT COUNT(*)
+            FROM transaction_tag tt
+            JOIN tag tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id AND tg.name = ANY(sqlc.arg(tags)::TEXT[])
+        ) >= CASE WHEN sqlc.arg(tag_match)::TEXT = 'all' THEN cardinality(sqlc.arg(tags)::TEXT[]) ELSE 1 END
+    )
+    -- The repository replaces this TRUE with the compiled filter expression.
+    AND /* filter expression */ TRUE
+GROUP BY 1, gc.id, gu.id, gh.id, gtg.name, NULLIF(LOWER(BTRIM(gm.description)), '')
+ORDER BY 1, gc.name, gu.name, gh.name, gtg.name, NULLIF(LOWER(BTRIM(gm.description)), '');
+
+-- name: ListTransactionChanges :many
+SELECT
+    sqlc.embed(t),
+    u.id AS author_id,
+    u.name AS author_name,
+    h.id AS household_id,
+    h.name AS household_name,
+    c.id AS category_id,
+    c.code AS category_code,
+    c.name AS category_name,
+    ARRAY(
+        SELECT tg.name FROM transaction_tag tt JOIN tag tg ON tg.id = tt.tag_id
+        WHERE tt.transaction_id = t.id ORDER BY tg.name
+    )::TEXT[] AS tags
+FROM transaction t
+JOIN users u ON u.id = t.author_id
+LEFT JOIN household h ON h.id = t.household_id
+LEFT JOIN category c ON c.id = t.category_id
+WHERE t.change_seq > sqlc.arg(since)::BIGINT
+ORDER BY t.change_seq
+LIMIT sqlc.arg(result_limit)::INT;
+
 -- WRITES
 
 -- name: CreateTransaction :one
@@ -463,6 +1258,320 @@ WHERE id = ANY(sqlc.arg(ids)::BIGINT[])
   AND deleted_at IS NOT NULL
 RETURNING *;
 
+-- name: CreateTags :exec
+INSERT INTO tag (name)
+SELECT unnest(sqlc.arg(names)::TEXT[])
+ON CONFLICT (name) DO NOTHING;
+
+-- name: DeleteTransactionTags :exec
+DELETE FROM transaction_tag
+WHERE transaction_id = sqlc.arg(transaction_id)::BIGINT;
+
+-- name: CreateTransactionTags :exec
+-- Links the transaction to the named tags, which CreateTags must have created.
+INSERT INTO transaction_tag (transaction_id, tag_id)
+SELECT sqlc.arg(transaction_id)::BIGINT, tg.id
+FROM tag tg
+WHERE tg.name = ANY(sqlc.arg(names)::TEXT[])
+ON CONFLICT DO NOTHING;
+
+-- name: UpsertSavedFilter :one
+INSERT INTO saved_filter (name, query)
+VALUES (sqlc.arg(name), sqlc.arg(query))
+ON CONFLICT (name) DO UPDATE
+SET query = EXCLUDED.query, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetSavedFilter :one
+SELECT * FROM saved_filter
+WHERE name = sqlc.arg(name);
+
+-- name: ListSavedFilters :many
+SELECT * FROM saved_filter
+ORDER BY name;
+
+-- name: DeleteSavedFilter :execrows
+DELETE FROM saved_filter
+WHERE name = sqlc.arg(name);
+
+-- name: CreateTransactionWebhookEvent :exec
+-- Records the transaction as it now stands in the webhook outbox. Call it in
+-- the same database transaction as the change so the event commits with it.
+-- The payload matches the API's transaction representation.
+INSERT INTO webhook_outbox (event_type, household_id, payload)
+SELECT
+    sqlc.arg(event_type)::VARCHAR,
+    t.household_id,
+    jsonb_strip_nulls(jsonb_build_object(
+        'id', t.id,
+        'amount', t.amount,
+        'transactionDate', t.transaction_date,
+        'authorId', t.author_id,
+        'authorName', u.name,
+        'householdId', t.household_id,
+        'householdName', h.name,
+        'category', CASE WHEN c.id IS NULL THEN NULL ELSE jsonb_build_object('id', c.id, 'code', c.code, 'name', c.name) END,
+        'description', t.description,
+        'notes', t.notes,
+        'createdAt', t.created_at,
+        'updatedAt', t.updated_at,
+        'deletedAt', t.deleted_at,
+        'deleteReason', t.delete_reason,
+        'tags', NULLIF(ARRAY(
+            SELECT tg.name FROM transaction_tag tt JOIN tag tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id ORDER BY tg.name
+        ), '{}'),
+        'version', t.version
+    ))
+FROM transaction t
+JOIN users u ON u.id = t.author_id
+LEFT JOIN household h ON h.id = t.household_id
+LEFT JOIN category c ON c.id = t.category_id
+WHERE t.id = sqlc.arg(transaction_id)::BIGINT;
+
+-- name: CreateTransactionHistory :exec
+INSERT INTO transaction_history (transaction_id, action, actor_user_id, changes)
+VALUES (sqlc.arg(transaction_id)::BIGINT, sqlc.arg(action)::VARCHAR, sqlc.narg(actor_user_id)::BIGINT, sqlc.arg(changes)::JSONB);
+
+-- ******************* transaction attachment *******************
+-- READS
+
+-- name: GetTransactionAttachmentById :one
+SELECT * FROM transaction_attachment
+WHERE id = sqlc.arg(id)::BIGINT;
+
+-- name: ListTransactionAttachments :many
+SELECT * FROM transaction_attachment
+WHERE transaction_id = sqlc.arg(transaction_id)::BIGINT
+ORDER BY id ASC;
+
+-- WRITES
+
+-- name: CreateTransactionAttachment :one
+-- Attaches only to a transaction that exists and is not deleted; otherwise no
+-- row is returned.
+INSERT INTO transaction_attachment (transaction_id, file_name, content_type, size_bytes, sha256, storage_key)
+SELECT t.id, sqlc.arg(file_name)::VARCHAR, sqlc.arg(content_type)::VARCHAR, sqlc.arg(size_bytes)::BIGINT, sqlc.arg(sha256)::VARCHAR, sqlc.arg(storage_key)::VARCHAR
+FROM transaction t
+WHERE t.id = sqlc.arg(transaction_id)::BIGINT
+  AND t.deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteTransactionAttachment :one
+DELETE FROM transaction_attachment
+WHERE id = sqlc.arg(id)::BIGINT
+RETURNING *;
+
+-- ******************* webhook *******************
+-- READS
+
+-- name: GetWebhookSubscriptionById :one
+SELECT * FROM webhook_subscription
+WHERE id = sqlc.arg(id)::BIGINT;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscription
+WHERE sqlc.narg(household_id)::BIGINT IS NULL OR household_id = sqlc.narg(household_id)::BIGINT
+ORDER BY id ASC;
+
+-- name: ListWebhookDeliveries :many
+SELECT
+    sqlc.embed(d),
+    o.event_type,
+    o.occurred_at
+FROM webhook_delivery d
+JOIN webhook_outbox o ON o.id = d.outbox_id
+WHERE (sqlc.narg(id)::BIGINT IS NULL OR d.id = sqlc.narg(id)::BIGINT)
+  AND (sqlc.narg(subscription_id)::BIGINT IS NULL OR d.subscription_id = sqlc.narg(subscription_id)::BIGINT)
+ORDER BY d.id DESC
+LIMIT sqlc.arg(row_limit)::INTEGER;
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT * FROM webhook_delivery_attempt
+WHERE delivery_id = ANY(sqlc.arg(delivery_ids)::BIGINT[])
+ORDER BY delivery_id ASC, attempted_at ASC, id ASC;
+
+-- WRITES
+
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscription (url, event_types, household_id, secret)
+VALUES (
+    sqlc.arg(url)::VARCHAR,
+    sqlc.arg(event_types)::TEXT[],
+    sqlc.narg(household_id)::BIGINT,
+    sqlc.arg(secret)::VARCHAR
+)
+RETURNING *;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscription
+SET
+    url = CASE
+        WHEN sqlc.arg(set_url)::bool THEN sqlc.arg(url)::VARCHAR
+        ELSE url
+    END,
+    event_types = CASE
+        WHEN sqlc.arg(set_event_types)::bool THEN sqlc.arg(event_types)::TEXT[]
+        ELSE event_types
+    END,
+    household_id = CASE
+        WHEN sqlc.arg(set_household_id)::bool THEN sqlc.narg(household_id)::BIGINT
+        ELSE household_id
+    END,
+    secret = CASE
+        WHEN sqlc.arg(set_secret)::bool THEN sqlc.arg(secret)::VARCHAR
+        ELSE secret
+    END,
+    is_active = CASE
+        WHEN sqlc.arg(set_is_active)::bool THEN sqlc.arg(is_active)::bool
+        ELSE is_active
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)::BIGINT
+RETURNING *;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscription
+WHERE id = sqlc.arg(id)::BIGINT;
+
+-- name: DispatchWebhookEvents :execrows
+-- Fans the oldest undispatched outbox events out to the active subscriptions
+-- whose household and event type filters match, then marks them dispatched.
+-- Locked rows are skipped so concurrent workers never dispatch one twice.
+WITH events AS (
+    SELECT id, event_type, household_id
+    FROM webhook_outbox
+    WHERE dispatched_at IS NULL
+    ORDER BY id ASC
+    LIMIT sqlc.arg(row_limit)::INTEGER
+    FOR UPDATE SKIP LOCKED
+), deliveries AS (
+    INSERT INTO webhook_delivery (subscription_id, outbox_id, next_attempt_at)
+    SELECT s.id, e.id, CURRENT_TIMESTAMP
+    FROM events e
+    JOIN webhook_subscription s
+        ON s.is_active
+       AND (s.household_id IS NULL OR s.household_id = e.household_id)
+       AND (cardinality(s.event_types) = 0 OR e.event_type = ANY(s.event_types))
+    ON CONFLICT (subscription_id, outbox_id) DO NOTHING
+)
+UPDATE webhook_outbox
+SET dispatched_at = CURRENT_TIMESTAMP
+WHERE id IN (SELECT id FROM events);
+
+-- name: ClaimDueWebhookDeliveries :many
+-- Leases pending deliveries that are due by pushing their next attempt to
+-- lease_until, so another worker does not send them while this one does. A
+-- worker that dies mid-send leaves them to be retried once the lease ends.
+UPDATE webhook_delivery d
+SET
+    next_attempt_at = sqlc.arg(lease_until)::TIMESTAMPTZ,
+    updated_at = CURRENT_TIMESTAMP
+FROM webhook_subscription s, webhook_outbox o
+WHERE d.id IN (
+    SELECT due.id
+    FROM webhook_delivery due
+    JOIN webhook_subscription active ON active.id = due.subscription_id AND active.is_active
+    WHERE due.status = 'pending'
+      AND due.next_attempt_at <= sqlc.arg(now)::TIMESTAMPTZ
+    ORDER BY due.next_attempt_at ASC, due.id ASC
+    LIMIT sqlc.arg(row_limit)::INTEGER
+    FOR UPDATE OF due SKIP LOCKED
+)
+  AND s.id = d.subscription_id
+  AND o.id = d.outbox_id
+RETURNING
+    d.id,
+    d.subscription_id,
+    d.outbox_id,
+    d.attempts,
+    s.url,
+    s.secret,
+    o.event_type,
+    o.household_id,
+    o.payload,
+    o.occurred_at;
+
+-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempt (delivery_id, attempted_at, status_code, error, duration_ms)
+VALUES (
+    sqlc.arg(delivery_id)::BIGINT,
+    sqlc.arg(attempted_at)::TIMESTAMPTZ,
+    sqlc.narg(status_code)::INTEGER,
+    sqlc.narg(error)::VARCHAR,
+    sqlc.arg(duration_ms)::INTEGER
+);
+
+-- name: UpdateWebhookDeliveryResult :exec
+UPDATE webhook_delivery
+SET
+    status = sqlc.arg(status)::VARCHAR,
+    attempts = attempts + 1,
+    next_attempt_at = sqlc.narg(next_attempt_at)::TIMESTAMPTZ,
+    last_error = sqlc.narg(last_error)::VARCHAR,
+    delivered_at = CASE
+        WHEN sqlc.arg(status)::VARCHAR = 'succeeded' THEN sqlc.arg(attempted_at)::TIMESTAMPTZ
+        ELSE delivered_at
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)::BIGINT;
+
+-- name: RedeliverWebhookDelivery :one
+-- Queues the delivery to be sent again straight away with a fresh retry
+-- budget. Earlier attempts stay in its history.
+UPDATE webhook_delivery
+SET
+    status = 'pending',
+    attempts = 0,
+    next_attempt_at = CURRENT_TIMESTAMP,
+    last_error = NULL,
+    delivered_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)::BIGINT
+RETURNING id;
+
+-- ******************* idempotency *******************
+-- READS
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_key
+WHERE key = sqlc.arg(key)::VARCHAR
+  AND expires_at > sqlc.arg(now)::TIMESTAMPTZ;
+
+-- WRITES
+-- name: ClaimIdempotencyKey :one
+-- Claims a key for a new request. A key whose record has expired is claimed
+-- afresh; a live one returns no row and the caller reads it instead.
+INSERT INTO idempotency_key (key, fingerprint, expires_at)
+VALUES (sqlc.arg(key)::VARCHAR, sqlc.arg(fingerprint)::VARCHAR, sqlc.arg(expires_at)::TIMESTAMPTZ)
+ON CONFLICT (key) DO UPDATE
+SET
+    fingerprint = EXCLUDED.fingerprint,
+    status_code = NULL,
+    response_headers = '{}',
+    response_body = NULL,
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_key.expires_at <= sqlc.arg(now)::TIMESTAMPTZ
+RETURNING key;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_key
+SET
+    status_code = sqlc.arg(status_code)::INTEGER,
+    response_headers = sqlc.arg(response_headers)::JSONB,
+    response_body = sqlc.arg(response_body)::BYTEA
+WHERE key = sqlc.arg(key)::VARCHAR
+  AND status_code IS NULL;
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_key
+WHERE key = sqlc.arg(key)::VARCHAR
+  AND status_code IS NULL;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_key
+WHERE expires_at <= sqlc.arg(now)::TIMESTAMPTZ;
+
 -- ******************* users *******************
 -- READS
 
@@ -21,6 +21,44 @@ type Budget struct {
 	PeriodStart    pgtype.Date        `json:"periodStart"`
 	PeriodEnd      pgtype.Date        `json:"periodEnd"`
 	SourceBudgetID *int64             `json:"sourceBudgetId"`
+	PeriodKind     string             `json:"periodKind"`
+}
+
+type BudgetAlert struct {
+	ID                int64              `json:"id"`
+	BudgetID          int64              `json:"budgetId"`
+	BudgetLineID      int64              `json:"budgetLineId"`
+	ThresholdPercent  int32              `json:"thresholdPercent"`
+	AllocationAmount  pgtype.Numeric     `json:"allocationAmount"`
+	ActualAmount      pgtype.Numeric     `json:"actualAmount"`
+	TransactionID     *int64             `json:"transactionId"`
+	NotifiedAt        pgtype.Timestamptz `json:"notifiedAt"`
+	NotificationError *string            `json:"notificationError"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+}
+
+type BudgetClosing struct {
+	ID                        int64              `json:"id"`
+	BudgetID                  int64              `json:"budgetId"`
+	AllocationAmount          pgtype.Numeric     `json:"allocationAmount"`
+	ActualAmount              pgtype.Numeric     `json:"actualAmount"`
+	UnmappedActualAmount      pgtype.Numeric     `json:"unmappedActualAmount"`
+	UncategorizedActualAmount pgtype.Numeric     `json:"uncategorizedActualAmount"`
+	ClosedByUserID            int64              `json:"closedByUserId"`
+	ClosedAt                  pgtype.Timestamptz `json:"closedAt"`
+	ReopenedByUserID          *int64             `json:"reopenedByUserId"`
+	ReopenedAt                pgtype.Timestamptz `json:"reopenedAt"`
+	ReopenReason              *string            `json:"reopenReason"`
+}
+
+type BudgetClosingLine struct {
+	ID               int64          `json:"id"`
+	ClosingID        int64          `json:"closingId"`
+	BudgetLineID     *int64         `json:"budgetLineId"`
+	Name             string         `json:"name"`
+	AllocationAmount pgtype.Numeric `json:"allocationAmount"`
+	ActualAmount     pgtype.Numeric `json:"actualAmount"`
+	SortOrder        int32          `json:"sortOrder"`
 }
 
 type BudgetLine struct {
@@ -31,6 +69,13 @@ type BudgetLine struct {
 	SortOrder        int32              `json:"sortOrder"`
 	CreatedAt        pgtype.Timestamptz `json:"createdAt"`
 	UpdatedAt        pgtype.Timestamptz `json:"updatedAt"`
+	Version          int64              `json:"version"`
+}
+
+type BudgetLineAlertRule struct {
+	BudgetLineID     int64              `json:"budgetLineId"`
+	ThresholdPercent int32              `json:"thresholdPercent"`
+	CreatedAt        pgtype.Timestamptz `json:"createdAt"`
 }
 
 type BudgetLineCategory struct {
@@ -40,6 +85,48 @@ type BudgetLineCategory struct {
 	CreatedAt    pgtype.Timestamptz `json:"createdAt"`
 }
 
+type BudgetLineMemberAllocation struct {
+	BudgetLineID      int64              `json:"budgetLineId"`
+	UserID            int64              `json:"userId"`
+	AllocationAmount  pgtype.Numeric     `json:"allocationAmount"`
+	AllocationPercent pgtype.Numeric     `json:"allocationPercent"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+}
+
+type BudgetReallocation struct {
+	ID              int64              `json:"id"`
+	BudgetID        int64              `json:"budgetId"`
+	FromLineID      int64              `json:"fromLineId"`
+	ToLineID        int64              `json:"toLineId"`
+	Amount          pgtype.Numeric     `json:"amount"`
+	Reason          *string            `json:"reason"`
+	CreatedByUserID int64              `json:"createdByUserId"`
+	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+}
+
+type BudgetTemplate struct {
+	ID          int64              `json:"id"`
+	HouseholdID *int64             `json:"householdId"`
+	UserID      *int64             `json:"userId"`
+	Name        string             `json:"name"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type BudgetTemplateLine struct {
+	ID               int64          `json:"id"`
+	TemplateID       int64          `json:"templateId"`
+	Name             string         `json:"name"`
+	AllocationAmount pgtype.Numeric `json:"allocationAmount"`
+	SortOrder        int32          `json:"sortOrder"`
+}
+
+type BudgetTemplateLineCategory struct {
+	TemplateID     int64 `json:"templateId"`
+	TemplateLineID int64 `json:"templateLineId"`
+	CategoryID     int64 `json:"categoryId"`
+}
+
 type Category struct {
 	ID          int64              `json:"id"`
 	Code        string             `json:"code"`
@@ -48,6 +135,7 @@ type Category struct {
 	IsActive    bool               `json:"isActive"`
 	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
 	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+	Version     int64              `json:"version"`
 }
 
 // Groups users together into a shared financial unit, linked to a Discord server.
@@ -76,6 +164,16 @@ type HouseholdUser struct {
 	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
 }
 
+type IdempotencyKey struct {
+	Key             string             `json:"key"`
+	Fingerprint     string             `json:"fingerprint"`
+	StatusCode      *int32             `json:"statusCode"`
+	ResponseHeaders []byte             `json:"responseHeaders"`
+	ResponseBody    []byte             `json:"responseBody"`
+	CreatedAt       pgtype.Timestamptz `json:"createdAt"`
+	ExpiresAt       pgtype.Timestamptz `json:"expiresAt"`
+}
+
 type LlmMessage struct {
 	ID        int64              `json:"id"`
 	SessionID int64              `json:"sessionId"`
@@ -95,6 +193,38 @@ type LlmSession struct {
 	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
 }
 
+type SavedFilter struct {
+	ID        int64              `json:"id"`
+	Name      string             `json:"name"`
+	Query     string             `json:"query"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type SavingsGoal struct {
+	ID           int64              `json:"id"`
+	HouseholdID  *int64             `json:"householdId"`
+	UserID       *int64             `json:"userId"`
+	Name         string             `json:"name"`
+	TargetAmount pgtype.Numeric     `json:"targetAmount"`
+	StartDate    pgtype.Date        `json:"startDate"`
+	TargetDate   pgtype.Date        `json:"targetDate"`
+	CreatedAt    pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt    pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type SavingsGoalCategory struct {
+	GoalID     int64              `json:"goalId"`
+	CategoryID int64              `json:"categoryId"`
+	CreatedAt  pgtype.Timestamptz `json:"createdAt"`
+}
+
+type Tag struct {
+	ID        int64              `json:"id"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"createdAt"`
+}
+
 // Records individual financial movements including amount, author, and categorization.
 type Transaction struct {
 	// Internal unique identifier for the transaction.
@@ -119,6 +249,33 @@ type Transaction struct {
 	DeletedByUserID *int64             `json:"deletedByUserId"`
 	DeleteReason    *string            `json:"deleteReason"`
 	CategoryID      *int64             `json:"categoryId"`
+	ChangeSeq       int64              `json:"changeSeq"`
+	Version         int64              `json:"version"`
+}
+
+type TransactionAttachment struct {
+	ID            int64              `json:"id"`
+	TransactionID int64              `json:"transactionId"`
+	FileName      string             `json:"fileName"`
+	ContentType   string             `json:"contentType"`
+	SizeBytes     int64              `json:"sizeBytes"`
+	Sha256        string             `json:"sha256"`
+	StorageKey    string             `json:"storageKey"`
+	CreatedAt     pgtype.Timestamptz `json:"createdAt"`
+}
+
+type TransactionHistory struct {
+	ID            int64              `json:"id"`
+	TransactionID int64              `json:"transactionId"`
+	Action        string             `json:"action"`
+	ActorUserID   *int64             `json:"actorUserId"`
+	Changes       []byte             `json:"changes"`
+	ChangedAt     pgtype.Timestamptz `json:"changedAt"`
+}
+
+type TransactionTag struct {
+	TransactionID int64 `json:"transactionId"`
+	TagID         int64 `json:"tagId"`
 }
 
 // Stores identity information for individuals linked to Discord accounts.
@@ -137,3 +294,45 @@ type User struct {
 	PhoneNumber *string            `json:"phoneNumber"`
 	WhatsappID  *string            `json:"whatsappId"`
 }
+
+type WebhookDelivery struct {
+	ID             int64              `json:"id"`
+	SubscriptionID int64              `json:"subscriptionId"`
+	OutboxID       int64              `json:"outboxId"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"nextAttemptAt"`
+	LastError      *string            `json:"lastError"`
+	DeliveredAt    pgtype.Timestamptz `json:"deliveredAt"`
+	CreatedAt      pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt      pgtype.Timestamptz `json:"updatedAt"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID          int64              `json:"id"`
+	DeliveryID  int64              `json:"deliveryId"`
+	AttemptedAt pgtype.Timestamptz `json:"attemptedAt"`
+	StatusCode  *int32             `json:"statusCode"`
+	Error       *string            `json:"error"`
+	DurationMs  int32              `json:"durationMs"`
+}
+
+type WebhookOutbox struct {
+	ID           int64              `json:"id"`
+	EventType    string             `json:"eventType"`
+	HouseholdID  *int64             `json:"householdId"`
+	Payload      []byte             `json:"payload"`
+	OccurredAt   pgtype.Timestamptz `json:"occurredAt"`
+	DispatchedAt pgtype.Timestamptz `json:"dispatchedAt"`
+}
+
+type WebhookSubscription struct {
+	ID          int64              `json:"id"`
+	Url         string             `json:"url"`
+	EventTypes  []string           `json:"eventTypes"`
+	HouseholdID *int64             `json:"householdId"`
+	Secret      string             `json:"secret"`
+	IsActive    bool               `json:"isActive"`
+	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt   pgtype.Timestamptz `json:"updatedAt"`
+}
@@ -11,6 +11,443 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const adjustBudgetLineAllocation = `-- name: AdjustBudgetLineAllocation :one
+UPDATE budget_line
+SET
+    allocation_amount = allocation_amount + $1::NUMERIC,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2::BIGINT
+  AND budget_id = $3::BIGINT
+RETURNING id, budget_id, name, allocation_amount, sort_order, created_at, updated_at, version
+`
+
+type AdjustBudgetLineAllocationParams struct {
+	Delta    pgtype.Numeric `json:"delta"`
+	ID       int64          `json:"id"`
+	BudgetID int64          `json:"budgetId"`
+}
+
+func (q *Queries) AdjustBudgetLineAllocation(ctx context.Context, arg AdjustBudgetLineAllocationParams) (BudgetLine, error) {
+	row := q.db.QueryRow(ctx, adjustBudgetLineAllocation, arg.Delta, arg.ID, arg.BudgetID)
+	var i BudgetLine
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.Name,
+		&i.AllocationAmount,
+		&i.SortOrder,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const aggregateTransactions = `-- name: AggregateTransactions :many
+SELECT
+    date_trunc($1::TEXT, t.transaction_date AT TIME ZONE 'UTC')::DATE AS period,
+    gc.id AS category_id,
+    gc.code AS category_code,
+    gc.name AS category_name,
+    gu.id AS author_id,
+    gu.name AS author_name,
+    gh.id AS household_id,
+    gh.name AS household_name,
+    gtg.name AS tag,
+    MIN(NULLIF(BTRIM(gm.description), '')) AS merchant,
+    COUNT(*)::BIGINT AS transaction_count,
+    ROUND(SUM(t.amount)::NUMERIC, 2) AS total_amount,
+    ROUND(AVG(t.amount)::NUMERIC, 2) AS average_amount,
+    ROUND(MIN(t.amount)::NUMERIC, 2) AS min_amount,
+    ROUND(MAX(t.amount)::NUMERIC, 2) AS max_amount
+FROM transaction t
+LEFT JOIN category gc ON gc.id = t.category_id AND 'category' = ANY($2::TEXT[])
+LEFT JOIN users gu ON gu.id = t.author_id AND 'author' = ANY($2::TEXT[])
+LEFT JOIN household gh ON gh.id = t.household_id AND 'household' = ANY($2::TEXT[])
+LEFT JOIN transaction_tag gtt ON gtt.transaction_id = t.id AND 'tag' = ANY($2::TEXT[])
+LEFT JOIN tag gtg ON gtg.id = gtt.tag_id
+LEFT JOIN transaction gm ON gm.id = t.id AND 'merchant' = ANY($2::TEXT[])
+WHERE
+    (NOT $3::bool OR t.deleted_at IS NOT NULL)
+    AND ($4::bool OR $3::bool OR t.deleted_at IS NULL)
+    AND ($5::BIGINT IS NULL OR t.author_id = $5::BIGINT)
+    AND ($6::BIGINT IS NULL OR t.household_id = $6::BIGINT)
+    AND ($7::TIMESTAMPTZ IS NULL OR t.transaction_date >= $7::TIMESTAMPTZ)
+    AND ($8::TIMESTAMPTZ IS NULL OR t.transaction_date <= $8::TIMESTAMPTZ)
+    -- The search and tag filters repeat those of ListTransactions.
+    AND (
+        $9::TEXT IS NULL
+        OR to_tsvector('simple', COALESCE(t.description, '') || ' ' || COALESCE(t.notes, '')) @@ websearch_to_tsquery('simple', $9::TEXT)
+        OR $9::TEXT OPERATOR(public.<%) (COALESCE(t.description, '') || ' ' || COALESCE(t.notes, ''))
+        OR t.description ILIKE '%' || $9::TEXT || '%'
+        OR t.notes ILIKE '%' || $9::TEXT || '%'
+    )
+    AND (
+        cardinality($10::TEXT[]) = 0
+        OR (
+            SELECT COUNT(*)
+            FROM transaction_tag tt
+            JOIN tag tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id AND tg.name = ANY($10::TEXT[])
+        ) >= CASE WHEN $11::TEXT = 'all' THEN cardinality($10::TEXT[]) ELSE 1 END
+    )
+    -- The repository replaces this TRUE with the compiled filter expression.
+    AND /* filter expression */ TRUE
+GROUP BY 1, gc.id, gu.id, gh.id, gtg.name, NULLIF(LOWER(BTRIM(gm.description)), '')
+ORDER BY 1, gc.name, gu.name, gh.name, gtg.name, NULLIF(LOWER(BTRIM(gm.description)), '')
+`
+
+type AggregateTransactionsParams struct {
+	PeriodUnit     *string            `json:"periodUnit"`
+	Dimensions     []string           `json:"dimensions"`
+	OnlyDeleted    bool               `json:"onlyDeleted"`
+	IncludeDeleted bool               `json:"includeDeleted"`
+	AuthorID       *int64             `json:"authorId"`
+	HouseholdID    *int64             `json:"householdId"`
+	FromDate       pgtype.Timestamptz `json:"fromDate"`
+	ToDate         pgtype.Timestamptz `json:"toDate"`
+	Search         *string            `json:"search"`
+	Tags           []string           `json:"tags"`
+	TagMatch       string             `json:"tagMatch"`
+}
+
+type AggregateTransactionsRow struct {
+	Period           pgtype.Date    `json:"period"`
+	CategoryID       *int64         `json:"categoryId"`
+	CategoryCode     *string        `json:"categoryCode"`
+	CategoryName     *string        `json:"categoryName"`
+	AuthorID         *int64         `json:"authorId"`
+	AuthorName       *string        `json:"authorName"`
+	HouseholdID      *int64         `json:"householdId"`
+	HouseholdName    *string        `json:"householdName"`
+	Tag              *string        `json:"tag"`
+	Merchant         interface{}    `json:"merchant"`
+	TransactionCount int64          `json:"transactionCount"`
+	TotalAmount      pgtype.Numeric `json:"totalAmount"`
+	AverageAmount    pgtype.Numeric `json:"averageAmount"`
+	MinAmount        pgtype.Numeric `json:"minAmount"`
+	MaxAmount        pgtype.Numeric `json:"maxAmount"`
+}
+
+// Groups the transactions a listing would match and summarizes each group.
+// Each dimension joins only when it is in the dimensions array, and is NULL
+// on every row otherwise so it does not split groups; likewise the period
+// with a NULL period unit. Periods start in UTC, and merchants are
+// descriptions compared ignoring case and surrounding spaces. With the tag
+// dimension a transaction counts once per tag, or once with a NULL tag when it
+// has none.
+func (q *Queries) AggregateTransactions(ctx context.Context, arg AggregateTransactionsParams) ([]AggregateTransactionsRow, error) {
+	rows, err := q.db.Query(ctx, aggregateTransactions,
+		arg.PeriodUnit,
+		arg.Dimensions,
+		arg.OnlyDeleted,
+		arg.IncludeDeleted,
+		arg.AuthorID,
+		arg.HouseholdID,
+		arg.FromDate,
+		arg.ToDate,
+		arg.Search,
+		arg.Tags,
+		arg.TagMatch,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AggregateTransactionsRow
+	for rows.Next() {
+		var i AggregateTransactionsRow
+		if err := rows.Scan(
+			&i.Period,
+			&i.CategoryID,
+			&i.CategoryCode,
+			&i.CategoryName,
+			&i.AuthorID,
+			&i.AuthorName,
+			&i.HouseholdID,
+			&i.HouseholdName,
+			&i.Tag,
+			&i.Merchant,
+			&i.TransactionCount,
+			&i.TotalAmount,
+			&i.AverageAmount,
+			&i.MinAmount,
+			&i.MaxAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_delivery d
+SET
+    next_attempt_at = $1::TIMESTAMPTZ,
+    updated_at = CURRENT_TIMESTAMP
+FROM webhook_subscription s, webhook_outbox o
+WHERE d.id IN (
+    SELECT due.id
+    FROM webhook_delivery due
+    JOIN webhook_subscription active ON active.id = due.subscription_id AND active.is_active
+    WHERE due.status = 'pending'
+      AND due.next_attempt_at <= $2::TIMESTAMPTZ
+    ORDER BY due.next_attempt_at ASC, due.id ASC
+    LIMIT $3::INTEGER
+    FOR UPDATE OF due SKIP LOCKED
+)
+  AND s.id = d.subscription_id
+  AND o.id = d.outbox_id
+RETURNING
+    d.id,
+    d.subscription_id,
+    d.outbox_id,
+    d.attempts,
+    s.url,
+    s.secret,
+    o.event_type,
+    o.household_id,
+    o.payload,
+    o.occurred_at
+`
+
+type ClaimDueWebhookDeliveriesParams struct {
+	LeaseUntil pgtype.Timestamptz `json:"leaseUntil"`
+	Now        pgtype.Timestamptz `json:"now"`
+	RowLimit   int32              `json:"rowLimit"`
+}
+
+type ClaimDueWebhookDeliveriesRow struct {
+	ID             int64              `json:"id"`
+	SubscriptionID int64              `json:"subscriptionId"`
+	OutboxID       int64              `json:"outboxId"`
+	Attempts       int32              `json:"attempts"`
+	Url            string             `json:"url"`
+	Secret         string             `json:"secret"`
+	EventType      string             `json:"eventType"`
+	HouseholdID    *int64             `json:"householdId"`
+	Payload        []byte             `json:"payload"`
+	OccurredAt     pgtype.Timestamptz `json:"occurredAt"`
+}
+
+// Leases pending deliveries that are due by pushing their next attempt to
+// lease_until, so another worker does not send them while this one does. A
+// worker that dies mid-send leaves them to be retried once the lease ends.
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, claimDueWebhookDeliveries, arg.LeaseUntil, arg.Now, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimDueWebhookDeliveriesRow
+	for rows.Next() {
+		var i ClaimDueWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.OutboxID,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
+			&i.EventType,
+			&i.HouseholdID,
+			&i.Payload,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimIdempotencyKey = `-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_key (key, fingerprint, expires_at)
+VALUES ($1::VARCHAR, $2::VARCHAR, $3::TIMESTAMPTZ)
+ON CONFLICT (key) DO UPDATE
+SET
+    fingerprint = EXCLUDED.fingerprint,
+    status_code = NULL,
+    response_headers = '{}',
+    response_body = NULL,
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_key.expires_at <= $4::TIMESTAMPTZ
+RETURNING key
+`
+
+type ClaimIdempotencyKeyParams struct {
+	Key         string             `json:"key"`
+	Fingerprint string             `json:"fingerprint"`
+	ExpiresAt   pgtype.Timestamptz `json:"expiresAt"`
+	Now         pgtype.Timestamptz `json:"now"`
+}
+
+// WRITES
+// Claims a key for a new request. A key whose record has expired is claimed
+// afresh; a live one returns no row and the caller reads it instead.
+func (q *Queries) ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (string, error) {
+	row := q.db.QueryRow(ctx, claimIdempotencyKey,
+		arg.Key,
+		arg.Fingerprint,
+		arg.ExpiresAt,
+		arg.Now,
+	)
+	var key string
+	err := row.Scan(&key)
+	return key, err
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_key
+SET
+    status_code = $1::INTEGER,
+    response_headers = $2::JSONB,
+    response_body = $3::BYTEA
+WHERE key = $4::VARCHAR
+  AND status_code IS NULL
+`
+
+type CompleteIdempotencyKeyParams struct {
+	StatusCode      int32  `json:"statusCode"`
+	ResponseHeaders []byte `json:"responseHeaders"`
+	ResponseBody    []byte `json:"responseBody"`
+	Key             string `json:"key"`
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, completeIdempotencyKey,
+		arg.StatusCode,
+		arg.ResponseHeaders,
+		arg.ResponseBody,
+		arg.Key,
+	)
+	return err
+}
+
+const createBudgetAlert = `-- name: CreateBudgetAlert :one
+
+INSERT INTO budget_alert (budget_id, budget_line_id, threshold_percent, allocation_amount, actual_amount, transaction_id)
+VALUES (
+    $1::BIGINT,
+    $2::BIGINT,
+    $3::INTEGER,
+    $4::NUMERIC,
+    $5::NUMERIC,
+    $6::BIGINT
+)
+ON CONFLICT (budget_line_id, threshold_percent) DO NOTHING
+RETURNING id
+`
+
+type CreateBudgetAlertParams struct {
+	BudgetID         int64          `json:"budgetId"`
+	BudgetLineID     int64          `json:"budgetLineId"`
+	ThresholdPercent int32          `json:"thresholdPercent"`
+	AllocationAmount pgtype.Numeric `json:"allocationAmount"`
+	ActualAmount     pgtype.Numeric `json:"actualAmount"`
+	TransactionID    *int64         `json:"transactionId"`
+}
+
+// WRITES
+// Returns no row when the line already alerted at this threshold.
+func (q *Queries) CreateBudgetAlert(ctx context.Context, arg CreateBudgetAlertParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createBudgetAlert,
+		arg.BudgetID,
+		arg.BudgetLineID,
+		arg.ThresholdPercent,
+		arg.AllocationAmount,
+		arg.ActualAmount,
+		arg.TransactionID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createBudgetClosing = `-- name: CreateBudgetClosing :one
+INSERT INTO budget_closing (
+    budget_id,
+    allocation_amount,
+    actual_amount,
+    unmapped_actual_amount,
+    uncategorized_actual_amount,
+    closed_by_user_id
+)
+VALUES (
+    $1::BIGINT,
+    $2::NUMERIC,
+    $3::NUMERIC,
+    $4::NUMERIC,
+    $5::NUMERIC,
+    $6::BIGINT
+)
+RETURNING id
+`
+
+type CreateBudgetClosingParams struct {
+	BudgetID                  int64          `json:"budgetId"`
+	AllocationAmount          pgtype.Numeric `json:"allocationAmount"`
+	ActualAmount              pgtype.Numeric `json:"actualAmount"`
+	UnmappedActualAmount      pgtype.Numeric `json:"unmappedActualAmount"`
+	UncategorizedActualAmount pgtype.Numeric `json:"uncategorizedActualAmount"`
+	ClosedByUserID            int64          `json:"closedByUserId"`
+}
+
+func (q *Queries) CreateBudgetClosing(ctx context.Context, arg CreateBudgetClosingParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createBudgetClosing,
+		arg.BudgetID,
+		arg.AllocationAmount,
+		arg.ActualAmount,
+		arg.UnmappedActualAmount,
+		arg.UncategorizedActualAmount,
+		arg.ClosedByUserID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createBudgetClosingLine = `-- name: CreateBudgetClosingLine :exec
+INSERT INTO budget_closing_line (closing_id, budget_line_id, name, allocation_amount, actual_amount, sort_order)
+VALUES (
+    $1::BIGINT,
+    $2::BIGINT,
+    $3::VARCHAR,
+    $4::NUMERIC,
+    $5::NUMERIC,
+    $6::INTEGER
+)
+`
+
+type CreateBudgetClosingLineParams struct {
+	ClosingID        int64          `json:"closingId"`
+	BudgetLineID     *int64         `json:"budgetLineId"`
+	Name             string         `json:"name"`
+	AllocationAmount pgtype.Numeric `json:"allocationAmount"`
+	ActualAmount     pgtype.Numeric `json:"actualAmount"`
+	SortOrder        int32          `json:"sortOrder"`
+}
+
+func (q *Queries) CreateBudgetClosingLine(ctx context.Context, arg CreateBudgetClosingLineParams) error {
+	_, err := q.db.Exec(ctx, createBudgetClosingLine,
+		arg.ClosingID,
+		arg.BudgetLineID,
+		arg.Name,
+		arg.AllocationAmount,
+		arg.ActualAmount,
+		arg.SortOrder,
+	)
+	return err
+}
+
 const createBudgetLine = `-- name: CreateBudgetLine :one
 INSERT INTO budget_line (budget_id, name, allocation_amount, sort_order)
 VALUES (
@@ -19,7 +456,7 @@ VALUES (
     $3::NUMERIC,
     $4::INTEGER
 )
-RETURNING id, budget_id, name, allocation_amount, sort_order, created_at, updated_at
+RETURNING id, budget_id, name, allocation_amount, sort_order, created_at, updated_at, version
 `
 
 type CreateBudgetLineParams struct {
@@ -45,10 +482,29 @@ func (q *Queries) CreateBudgetLine(ctx context.Context, arg CreateBudgetLinePara
 		&i.SortOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
+const createBudgetLineAlertRule = `-- name: CreateBudgetLineAlertRule :exec
+INSERT INTO budget_line_alert_rule (budget_line_id, threshold_percent)
+VALUES (
+    $1::BIGINT,
+    $2::INTEGER
+)
+`
+
+type CreateBudgetLineAlertRuleParams struct {
+	BudgetLineID     int64 `json:"budgetLineId"`
+	ThresholdPercent int32 `json:"thresholdPercent"`
+}
+
+func (q *Queries) CreateBudgetLineAlertRule(ctx context.Context, arg CreateBudgetLineAlertRuleParams) error {
+	_, err := q.db.Exec(ctx, createBudgetLineAlertRule, arg.BudgetLineID, arg.ThresholdPercent)
+	return err
+}
+
 const createBudgetLineCategory = `-- name: CreateBudgetLineCategory :exec
 INSERT INTO budget_line_category (budget_id, budget_line_id, category_id)
 VALUES (
@@ -69,74 +525,230 @@ func (q *Queries) CreateBudgetLineCategory(ctx context.Context, arg CreateBudget
 	return err
 }
 
-const createCategory = `-- name: CreateCategory :one
+const createBudgetLineMemberAllocation = `-- name: CreateBudgetLineMemberAllocation :exec
+INSERT INTO budget_line_member_allocation (budget_line_id, user_id, allocation_amount, allocation_percent)
+VALUES (
+    $1::BIGINT,
+    $2::BIGINT,
+    $3::NUMERIC,
+    $4::NUMERIC
+)
+`
 
-INSERT INTO category (code, name, description)
-VALUES ($1, $2, $3)
-RETURNING id, code, name, description, is_active, created_at, updated_at
+type CreateBudgetLineMemberAllocationParams struct {
+	BudgetLineID      int64          `json:"budgetLineId"`
+	UserID            int64          `json:"userId"`
+	AllocationAmount  pgtype.Numeric `json:"allocationAmount"`
+	AllocationPercent pgtype.Numeric `json:"allocationPercent"`
+}
+
+func (q *Queries) CreateBudgetLineMemberAllocation(ctx context.Context, arg CreateBudgetLineMemberAllocationParams) error {
+	_, err := q.db.Exec(ctx, createBudgetLineMemberAllocation,
+		arg.BudgetLineID,
+		arg.UserID,
+		arg.AllocationAmount,
+		arg.AllocationPercent,
+	)
+	return err
+}
+
+const createBudgetReallocation = `-- name: CreateBudgetReallocation :one
+INSERT INTO budget_reallocation (budget_id, from_line_id, to_line_id, amount, reason, created_by_user_id)
+VALUES (
+    $1::BIGINT,
+    $2::BIGINT,
+    $3::BIGINT,
+    $4::NUMERIC,
+    $5::VARCHAR,
+    $6::BIGINT
+)
+RETURNING id
 `
 
-type CreateCategoryParams struct {
-	Code        string  `json:"code"`
-	Name        string  `json:"name"`
-	Description *string `json:"description"`
+type CreateBudgetReallocationParams struct {
+	BudgetID        int64          `json:"budgetId"`
+	FromLineID      int64          `json:"fromLineId"`
+	ToLineID        int64          `json:"toLineId"`
+	Amount          pgtype.Numeric `json:"amount"`
+	Reason          *string        `json:"reason"`
+	CreatedByUserID int64          `json:"createdByUserId"`
 }
 
-// ******************* category *******************
-// WRITES
-func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
-	row := q.db.QueryRow(ctx, createCategory, arg.Code, arg.Name, arg.Description)
-	var i Category
-	err := row.Scan(
-		&i.ID,
-		&i.Code,
-		&i.Name,
-		&i.Description,
-		&i.IsActive,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+func (q *Queries) CreateBudgetReallocation(ctx context.Context, arg CreateBudgetReallocationParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createBudgetReallocation,
+		arg.BudgetID,
+		arg.FromLineID,
+		arg.ToLineID,
+		arg.Amount,
+		arg.Reason,
+		arg.CreatedByUserID,
 	)
-	return i, err
+	var id int64
+	err := row.Scan(&id)
+	return id, err
 }
 
-const createHouseholdBudget = `-- name: CreateHouseholdBudget :one
+const createBudgetTemplate = `-- name: CreateBudgetTemplate :one
 
-INSERT INTO budget (household_id, user_id, period_start, period_end, source_budget_id)
+INSERT INTO budget_template (household_id, user_id, name)
 VALUES (
     $1::BIGINT,
-    NULL,
-    $2::DATE,
-    $3::DATE,
-    $4::BIGINT
+    $2::BIGINT,
+    $3::VARCHAR
 )
-RETURNING id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id
+RETURNING id, household_id, user_id, name, created_at, updated_at
 `
 
-type CreateHouseholdBudgetParams struct {
-	HouseholdID    int64       `json:"householdId"`
-	PeriodStart    pgtype.Date `json:"periodStart"`
-	PeriodEnd      pgtype.Date `json:"periodEnd"`
-	SourceBudgetID *int64      `json:"sourceBudgetId"`
+type CreateBudgetTemplateParams struct {
+	HouseholdID *int64 `json:"householdId"`
+	UserID      *int64 `json:"userId"`
+	Name        string `json:"name"`
 }
 
 // WRITES
-func (q *Queries) CreateHouseholdBudget(ctx context.Context, arg CreateHouseholdBudgetParams) (Budget, error) {
-	row := q.db.QueryRow(ctx, createHouseholdBudget,
-		arg.HouseholdID,
-		arg.PeriodStart,
-		arg.PeriodEnd,
-		arg.SourceBudgetID,
-	)
-	var i Budget
+func (q *Queries) CreateBudgetTemplate(ctx context.Context, arg CreateBudgetTemplateParams) (BudgetTemplate, error) {
+	row := q.db.QueryRow(ctx, createBudgetTemplate, arg.HouseholdID, arg.UserID, arg.Name)
+	var i BudgetTemplate
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
 		&i.HouseholdID,
+		&i.UserID,
+		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PeriodStart,
-		&i.PeriodEnd,
+	)
+	return i, err
+}
+
+const createBudgetTemplateLine = `-- name: CreateBudgetTemplateLine :one
+INSERT INTO budget_template_line (template_id, name, allocation_amount, sort_order)
+VALUES (
+    $1::BIGINT,
+    $2::VARCHAR,
+    $3::NUMERIC,
+    $4::INTEGER
+)
+RETURNING id, template_id, name, allocation_amount, sort_order
+`
+
+type CreateBudgetTemplateLineParams struct {
+	TemplateID       int64          `json:"templateId"`
+	Name             string         `json:"name"`
+	AllocationAmount pgtype.Numeric `json:"allocationAmount"`
+	SortOrder        int32          `json:"sortOrder"`
+}
+
+func (q *Queries) CreateBudgetTemplateLine(ctx context.Context, arg CreateBudgetTemplateLineParams) (BudgetTemplateLine, error) {
+	row := q.db.QueryRow(ctx, createBudgetTemplateLine,
+		arg.TemplateID,
+		arg.Name,
+		arg.AllocationAmount,
+		arg.SortOrder,
+	)
+	var i BudgetTemplateLine
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.Name,
+		&i.AllocationAmount,
+		&i.SortOrder,
+	)
+	return i, err
+}
+
+const createBudgetTemplateLineCategory = `-- name: CreateBudgetTemplateLineCategory :exec
+INSERT INTO budget_template_line_category (template_id, template_line_id, category_id)
+VALUES (
+    $1::BIGINT,
+    $2::BIGINT,
+    $3::BIGINT
+)
+`
+
+type CreateBudgetTemplateLineCategoryParams struct {
+	TemplateID     int64 `json:"templateId"`
+	TemplateLineID int64 `json:"templateLineId"`
+	CategoryID     int64 `json:"categoryId"`
+}
+
+func (q *Queries) CreateBudgetTemplateLineCategory(ctx context.Context, arg CreateBudgetTemplateLineCategoryParams) error {
+	_, err := q.db.Exec(ctx, createBudgetTemplateLineCategory, arg.TemplateID, arg.TemplateLineID, arg.CategoryID)
+	return err
+}
+
+const createCategory = `-- name: CreateCategory :one
+
+INSERT INTO category (code, name, description)
+VALUES ($1, $2, $3)
+RETURNING id, code, name, description, is_active, created_at, updated_at, version
+`
+
+type CreateCategoryParams struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+// ******************* category *******************
+// WRITES
+func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
+	row := q.db.QueryRow(ctx, createCategory, arg.Code, arg.Name, arg.Description)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const createHouseholdBudget = `-- name: CreateHouseholdBudget :one
+
+INSERT INTO budget (household_id, user_id, period_kind, period_start, period_end, source_budget_id)
+VALUES (
+    $1::BIGINT,
+    NULL,
+    $2::VARCHAR,
+    $3::DATE,
+    $4::DATE,
+    $5::BIGINT
+)
+RETURNING id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id, period_kind
+`
+
+type CreateHouseholdBudgetParams struct {
+	HouseholdID    int64       `json:"householdId"`
+	PeriodKind     string      `json:"periodKind"`
+	PeriodStart    pgtype.Date `json:"periodStart"`
+	PeriodEnd      pgtype.Date `json:"periodEnd"`
+	SourceBudgetID *int64      `json:"sourceBudgetId"`
+}
+
+// WRITES
+func (q *Queries) CreateHouseholdBudget(ctx context.Context, arg CreateHouseholdBudgetParams) (Budget, error) {
+	row := q.db.QueryRow(ctx, createHouseholdBudget,
+		arg.HouseholdID,
+		arg.PeriodKind,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+		arg.SourceBudgetID,
+	)
+	var i Budget
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.HouseholdID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PeriodStart,
+		&i.PeriodEnd,
 		&i.SourceBudgetID,
+		&i.PeriodKind,
 	)
 	return i, err
 }
@@ -198,6 +810,83 @@ func (q *Queries) CreateLlmSession(ctx context.Context, arg CreateLlmSessionPara
 	return i, err
 }
 
+const createSavingsGoal = `-- name: CreateSavingsGoal :one
+
+INSERT INTO savings_goal (household_id, user_id, name, target_amount, start_date, target_date)
+VALUES (
+    $1::BIGINT,
+    $2::BIGINT,
+    $3::VARCHAR,
+    $4::NUMERIC,
+    $5::DATE,
+    $6::DATE
+)
+RETURNING id, household_id, user_id, name, target_amount, start_date, target_date, created_at, updated_at
+`
+
+type CreateSavingsGoalParams struct {
+	HouseholdID  *int64         `json:"householdId"`
+	UserID       *int64         `json:"userId"`
+	Name         string         `json:"name"`
+	TargetAmount pgtype.Numeric `json:"targetAmount"`
+	StartDate    pgtype.Date    `json:"startDate"`
+	TargetDate   pgtype.Date    `json:"targetDate"`
+}
+
+// WRITES
+func (q *Queries) CreateSavingsGoal(ctx context.Context, arg CreateSavingsGoalParams) (SavingsGoal, error) {
+	row := q.db.QueryRow(ctx, createSavingsGoal,
+		arg.HouseholdID,
+		arg.UserID,
+		arg.Name,
+		arg.TargetAmount,
+		arg.StartDate,
+		arg.TargetDate,
+	)
+	var i SavingsGoal
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.Name,
+		&i.TargetAmount,
+		&i.StartDate,
+		&i.TargetDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createSavingsGoalCategory = `-- name: CreateSavingsGoalCategory :exec
+INSERT INTO savings_goal_category (goal_id, category_id)
+VALUES (
+    $1::BIGINT,
+    $2::BIGINT
+)
+`
+
+type CreateSavingsGoalCategoryParams struct {
+	GoalID     int64 `json:"goalId"`
+	CategoryID int64 `json:"categoryId"`
+}
+
+func (q *Queries) CreateSavingsGoalCategory(ctx context.Context, arg CreateSavingsGoalCategoryParams) error {
+	_, err := q.db.Exec(ctx, createSavingsGoalCategory, arg.GoalID, arg.CategoryID)
+	return err
+}
+
+const createTags = `-- name: CreateTags :exec
+INSERT INTO tag (name)
+SELECT unnest($1::TEXT[])
+ON CONFLICT (name) DO NOTHING
+`
+
+func (q *Queries) CreateTags(ctx context.Context, names []string) error {
+	_, err := q.db.Exec(ctx, createTags, names)
+	return err
+}
+
 const createTransaction = `-- name: CreateTransaction :one
 
 INSERT INTO transaction
@@ -213,7 +902,7 @@ INSERT INTO transaction
 )
 VALUES
 ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id
+RETURNING id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id, change_seq, version
 `
 
 type CreateTransactionParams struct {
@@ -255,10 +944,144 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.DeletedByUserID,
 		&i.DeleteReason,
 		&i.CategoryID,
+		&i.ChangeSeq,
+		&i.Version,
+	)
+	return i, err
+}
+
+const createTransactionAttachment = `-- name: CreateTransactionAttachment :one
+
+INSERT INTO transaction_attachment (transaction_id, file_name, content_type, size_bytes, sha256, storage_key)
+SELECT t.id, $1::VARCHAR, $2::VARCHAR, $3::BIGINT, $4::VARCHAR, $5::VARCHAR
+FROM transaction t
+WHERE t.id = $6::BIGINT
+  AND t.deleted_at IS NULL
+RETURNING id, transaction_id, file_name, content_type, size_bytes, sha256, storage_key, created_at
+`
+
+type CreateTransactionAttachmentParams struct {
+	FileName      string `json:"fileName"`
+	ContentType   string `json:"contentType"`
+	SizeBytes     int64  `json:"sizeBytes"`
+	Sha256        string `json:"sha256"`
+	StorageKey    string `json:"storageKey"`
+	TransactionID int64  `json:"transactionId"`
+}
+
+// WRITES
+// Attaches only to a transaction that exists and is not deleted; otherwise no
+// row is returned.
+func (q *Queries) CreateTransactionAttachment(ctx context.Context, arg CreateTransactionAttachmentParams) (TransactionAttachment, error) {
+	row := q.db.QueryRow(ctx, createTransactionAttachment,
+		arg.FileName,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Sha256,
+		arg.StorageKey,
+		arg.TransactionID,
+	)
+	var i TransactionAttachment
+	err := row.Scan(
+		&i.ID,
+		&i.TransactionID,
+		&i.FileName,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Sha256,
+		&i.StorageKey,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const createTransactionHistory = `-- name: CreateTransactionHistory :exec
+INSERT INTO transaction_history (transaction_id, action, actor_user_id, changes)
+VALUES ($1::BIGINT, $2::VARCHAR, $3::BIGINT, $4::JSONB)
+`
+
+type CreateTransactionHistoryParams struct {
+	TransactionID int64  `json:"transactionId"`
+	Action        string `json:"action"`
+	ActorUserID   *int64 `json:"actorUserId"`
+	Changes       []byte `json:"changes"`
+}
+
+func (q *Queries) CreateTransactionHistory(ctx context.Context, arg CreateTransactionHistoryParams) error {
+	_, err := q.db.Exec(ctx, createTransactionHistory,
+		arg.TransactionID,
+		arg.Action,
+		arg.ActorUserID,
+		arg.Changes,
+	)
+	return err
+}
+
+const createTransactionTags = `-- name: CreateTransactionTags :exec
+INSERT INTO transaction_tag (transaction_id, tag_id)
+SELECT $1::BIGINT, tg.id
+FROM tag tg
+WHERE tg.name = ANY($2::TEXT[])
+ON CONFLICT DO NOTHING
+`
+
+type CreateTransactionTagsParams struct {
+	TransactionID int64    `json:"transactionId"`
+	Names         []string `json:"names"`
+}
+
+// Links the transaction to the named tags, which CreateTags must have created.
+func (q *Queries) CreateTransactionTags(ctx context.Context, arg CreateTransactionTagsParams) error {
+	_, err := q.db.Exec(ctx, createTransactionTags, arg.TransactionID, arg.Names)
+	return err
+}
+
+const createTransactionWebhookEvent = `-- name: CreateTransactionWebhookEvent :exec
+INSERT INTO webhook_outbox (event_type, household_id, payload)
+SELECT
+    $1::VARCHAR,
+    t.household_id,
+    jsonb_strip_nulls(jsonb_build_object(
+        'id', t.id,
+        'amount', t.amount,
+        'transactionDate', t.transaction_date,
+        'authorId', t.author_id,
+        'authorName', u.name,
+        'householdId', t.household_id,
+        'householdName', h.name,
+        'category', CASE WHEN c.id IS NULL THEN NULL ELSE jsonb_build_object('id', c.id, 'code', c.code, 'name', c.name) END,
+        'description', t.description,
+        'notes', t.notes,
+        'createdAt', t.created_at,
+        'updatedAt', t.updated_at,
+        'deletedAt', t.deleted_at,
+        'deleteReason', t.delete_reason,
+        'tags', NULLIF(ARRAY(
+            SELECT tg.name FROM transaction_tag tt JOIN tag tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id ORDER BY tg.name
+        ), '{}'),
+        'version', t.version
+    ))
+FROM transaction t
+JOIN users u ON u.id = t.author_id
+LEFT JOIN household h ON h.id = t.household_id
+LEFT JOIN category c ON c.id = t.category_id
+WHERE t.id = $2::BIGINT
+`
+
+type CreateTransactionWebhookEventParams struct {
+	EventType     string `json:"eventType"`
+	TransactionID int64  `json:"transactionId"`
+}
+
+// Records the transaction as it now stands in the webhook outbox. Call it in
+// the same database transaction as the change so the event commits with it.
+// The payload matches the API's transaction representation.
+func (q *Queries) CreateTransactionWebhookEvent(ctx context.Context, arg CreateTransactionWebhookEventParams) error {
+	_, err := q.db.Exec(ctx, createTransactionWebhookEvent, arg.EventType, arg.TransactionID)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (discord_id, telegram_id, phone_number, whatsapp_id, name)
 VALUES ($1, $2, $3, $4, $5)
@@ -296,19 +1119,21 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 }
 
 const createUserBudget = `-- name: CreateUserBudget :one
-INSERT INTO budget (household_id, user_id, period_start, period_end, source_budget_id)
+INSERT INTO budget (household_id, user_id, period_kind, period_start, period_end, source_budget_id)
 VALUES (
     NULL,
     $1::BIGINT,
-    $2::DATE,
+    $2::VARCHAR,
     $3::DATE,
-    $4::BIGINT
+    $4::DATE,
+    $5::BIGINT
 )
-RETURNING id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id
+RETURNING id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id, period_kind
 `
 
 type CreateUserBudgetParams struct {
 	UserID         int64       `json:"userId"`
+	PeriodKind     string      `json:"periodKind"`
 	PeriodStart    pgtype.Date `json:"periodStart"`
 	PeriodEnd      pgtype.Date `json:"periodEnd"`
 	SourceBudgetID *int64      `json:"sourceBudgetId"`
@@ -317,6 +1142,7 @@ type CreateUserBudgetParams struct {
 func (q *Queries) CreateUserBudget(ctx context.Context, arg CreateUserBudgetParams) (Budget, error) {
 	row := q.db.QueryRow(ctx, createUserBudget,
 		arg.UserID,
+		arg.PeriodKind,
 		arg.PeriodStart,
 		arg.PeriodEnd,
 		arg.SourceBudgetID,
@@ -331,6 +1157,78 @@ func (q *Queries) CreateUserBudget(ctx context.Context, arg CreateUserBudgetPara
 		&i.PeriodStart,
 		&i.PeriodEnd,
 		&i.SourceBudgetID,
+		&i.PeriodKind,
+	)
+	return i, err
+}
+
+const createWebhookDeliveryAttempt = `-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempt (delivery_id, attempted_at, status_code, error, duration_ms)
+VALUES (
+    $1::BIGINT,
+    $2::TIMESTAMPTZ,
+    $3::INTEGER,
+    $4::VARCHAR,
+    $5::INTEGER
+)
+`
+
+type CreateWebhookDeliveryAttemptParams struct {
+	DeliveryID  int64              `json:"deliveryId"`
+	AttemptedAt pgtype.Timestamptz `json:"attemptedAt"`
+	StatusCode  *int32             `json:"statusCode"`
+	Error       *string            `json:"error"`
+	DurationMs  int32              `json:"durationMs"`
+}
+
+func (q *Queries) CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) error {
+	_, err := q.db.Exec(ctx, createWebhookDeliveryAttempt,
+		arg.DeliveryID,
+		arg.AttemptedAt,
+		arg.StatusCode,
+		arg.Error,
+		arg.DurationMs,
+	)
+	return err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+
+INSERT INTO webhook_subscription (url, event_types, household_id, secret)
+VALUES (
+    $1::VARCHAR,
+    $2::TEXT[],
+    $3::BIGINT,
+    $4::VARCHAR
+)
+RETURNING id, url, event_types, household_id, secret, is_active, created_at, updated_at
+`
+
+type CreateWebhookSubscriptionParams struct {
+	Url         string   `json:"url"`
+	EventTypes  []string `json:"eventTypes"`
+	HouseholdID *int64   `json:"householdId"`
+	Secret      string   `json:"secret"`
+}
+
+// WRITES
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, createWebhookSubscription,
+		arg.Url,
+		arg.EventTypes,
+		arg.HouseholdID,
+		arg.Secret,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.EventTypes,
+		&i.HouseholdID,
+		&i.Secret,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -339,12 +1237,18 @@ const deactivateCategory = `-- name: DeactivateCategory :one
 UPDATE category
 SET is_active = false,
     updated_at = CURRENT_TIMESTAMP
-WHERE code = $1
-RETURNING id, code, name, description, is_active, created_at, updated_at
+WHERE code = $1::VARCHAR
+  AND ($2::BIGINT IS NULL OR version = $2::BIGINT)
+RETURNING id, code, name, description, is_active, created_at, updated_at, version
 `
 
-func (q *Queries) DeactivateCategory(ctx context.Context, code string) (Category, error) {
-	row := q.db.QueryRow(ctx, deactivateCategory, code)
+type DeactivateCategoryParams struct {
+	Code            string `json:"code"`
+	ExpectedVersion *int64 `json:"expectedVersion"`
+}
+
+func (q *Queries) DeactivateCategory(ctx context.Context, arg DeactivateCategoryParams) (Category, error) {
+	row := q.db.QueryRow(ctx, deactivateCategory, arg.Code, arg.ExpectedVersion)
 	var i Category
 	err := row.Scan(
 		&i.ID,
@@ -354,17 +1258,37 @@ func (q *Queries) DeactivateCategory(ctx context.Context, code string) (Category
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
-const deleteBudgetLine = `-- name: DeleteBudgetLine :exec
+const deleteBudgetLine = `-- name: DeleteBudgetLine :execrows
 DELETE FROM budget_line
 WHERE id = $1::BIGINT
+  AND ($2::BIGINT IS NULL OR version = $2::BIGINT)
+`
+
+type DeleteBudgetLineParams struct {
+	ID              int64  `json:"id"`
+	ExpectedVersion *int64 `json:"expectedVersion"`
+}
+
+func (q *Queries) DeleteBudgetLine(ctx context.Context, arg DeleteBudgetLineParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteBudgetLine, arg.ID, arg.ExpectedVersion)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteBudgetLineAlertRules = `-- name: DeleteBudgetLineAlertRules :exec
+DELETE FROM budget_line_alert_rule
+WHERE budget_line_id = $1::BIGINT
 `
 
-func (q *Queries) DeleteBudgetLine(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteBudgetLine, id)
+func (q *Queries) DeleteBudgetLineAlertRules(ctx context.Context, budgetLineID int64) error {
+	_, err := q.db.Exec(ctx, deleteBudgetLineAlertRules, budgetLineID)
 	return err
 }
 
@@ -378,33 +1302,189 @@ func (q *Queries) DeleteBudgetLineCategories(ctx context.Context, budgetLineID i
 	return err
 }
 
-const getActiveCategoryByCode = `-- name: GetActiveCategoryByCode :one
-SELECT id, code, name, description, is_active, created_at, updated_at FROM category
-WHERE code = $1 AND is_active
+const deleteBudgetLineMemberAllocations = `-- name: DeleteBudgetLineMemberAllocations :exec
+DELETE FROM budget_line_member_allocation
+WHERE budget_line_id = $1::BIGINT
 `
 
-func (q *Queries) GetActiveCategoryByCode(ctx context.Context, code string) (Category, error) {
-	row := q.db.QueryRow(ctx, getActiveCategoryByCode, code)
-	var i Category
-	err := row.Scan(
-		&i.ID,
-		&i.Code,
-		&i.Name,
-		&i.Description,
-		&i.IsActive,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+func (q *Queries) DeleteBudgetLineMemberAllocations(ctx context.Context, budgetLineID int64) error {
+	_, err := q.db.Exec(ctx, deleteBudgetLineMemberAllocations, budgetLineID)
+	return err
 }
 
-const getActiveCategoryById = `-- name: GetActiveCategoryById :one
-SELECT id, code, name, description, is_active, created_at, updated_at FROM category
-WHERE id = $1 AND is_active
+const deleteBudgetTemplate = `-- name: DeleteBudgetTemplate :exec
+DELETE FROM budget_template
+WHERE id = $1::BIGINT
 `
 
-func (q *Queries) GetActiveCategoryById(ctx context.Context, id int64) (Category, error) {
-	row := q.db.QueryRow(ctx, getActiveCategoryById, id)
+func (q *Queries) DeleteBudgetTemplate(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteBudgetTemplate, id)
+	return err
+}
+
+const deleteBudgetTemplateLines = `-- name: DeleteBudgetTemplateLines :exec
+DELETE FROM budget_template_line
+WHERE template_id = $1::BIGINT
+`
+
+func (q *Queries) DeleteBudgetTemplateLines(ctx context.Context, templateID int64) error {
+	_, err := q.db.Exec(ctx, deleteBudgetTemplateLines, templateID)
+	return err
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_key
+WHERE expires_at <= $1::TIMESTAMPTZ
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, now pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSavedFilter = `-- name: DeleteSavedFilter :execrows
+DELETE FROM saved_filter
+WHERE name = $1
+`
+
+func (q *Queries) DeleteSavedFilter(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSavedFilter, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSavingsGoal = `-- name: DeleteSavingsGoal :exec
+DELETE FROM savings_goal
+WHERE id = $1::BIGINT
+`
+
+func (q *Queries) DeleteSavingsGoal(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteSavingsGoal, id)
+	return err
+}
+
+const deleteSavingsGoalCategories = `-- name: DeleteSavingsGoalCategories :exec
+DELETE FROM savings_goal_category
+WHERE goal_id = $1::BIGINT
+`
+
+func (q *Queries) DeleteSavingsGoalCategories(ctx context.Context, goalID int64) error {
+	_, err := q.db.Exec(ctx, deleteSavingsGoalCategories, goalID)
+	return err
+}
+
+const deleteTransactionAttachment = `-- name: DeleteTransactionAttachment :one
+DELETE FROM transaction_attachment
+WHERE id = $1::BIGINT
+RETURNING id, transaction_id, file_name, content_type, size_bytes, sha256, storage_key, created_at
+`
+
+func (q *Queries) DeleteTransactionAttachment(ctx context.Context, id int64) (TransactionAttachment, error) {
+	row := q.db.QueryRow(ctx, deleteTransactionAttachment, id)
+	var i TransactionAttachment
+	err := row.Scan(
+		&i.ID,
+		&i.TransactionID,
+		&i.FileName,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Sha256,
+		&i.StorageKey,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteTransactionTags = `-- name: DeleteTransactionTags :exec
+DELETE FROM transaction_tag
+WHERE transaction_id = $1::BIGINT
+`
+
+func (q *Queries) DeleteTransactionTags(ctx context.Context, transactionID int64) error {
+	_, err := q.db.Exec(ctx, deleteTransactionTags, transactionID)
+	return err
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscription
+WHERE id = $1::BIGINT
+`
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWebhookSubscription, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const dispatchWebhookEvents = `-- name: DispatchWebhookEvents :execrows
+WITH events AS (
+    SELECT id, event_type, household_id
+    FROM webhook_outbox
+    WHERE dispatched_at IS NULL
+    ORDER BY id ASC
+    LIMIT $1::INTEGER
+    FOR UPDATE SKIP LOCKED
+), deliveries AS (
+    INSERT INTO webhook_delivery (subscription_id, outbox_id, next_attempt_at)
+    SELECT s.id, e.id, CURRENT_TIMESTAMP
+    FROM events e
+    JOIN webhook_subscription s
+        ON s.is_active
+       AND (s.household_id IS NULL OR s.household_id = e.household_id)
+       AND (cardinality(s.event_types) = 0 OR e.event_type = ANY(s.event_types))
+    ON CONFLICT (subscription_id, outbox_id) DO NOTHING
+)
+UPDATE webhook_outbox
+SET dispatched_at = CURRENT_TIMESTAMP
+WHERE id IN (SELECT id FROM events)
+`
+
+// Fans the oldest undispatched outbox events out to the active subscriptions
+// whose household and event type filters match, then marks them dispatched.
+// Locked rows are skipped so concurrent workers never dispatch one twice.
+func (q *Queries) DispatchWebhookEvents(ctx context.Context, rowLimit int32) (int64, error) {
+	result, err := q.db.Exec(ctx, dispatchWebhookEvents, rowLimit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getActiveCategoryByCode = `-- name: GetActiveCategoryByCode :one
+SELECT id, code, name, description, is_active, created_at, updated_at, version FROM category
+WHERE code = $1 AND is_active
+`
+
+func (q *Queries) GetActiveCategoryByCode(ctx context.Context, code string) (Category, error) {
+	row := q.db.QueryRow(ctx, getActiveCategoryByCode, code)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getActiveCategoryById = `-- name: GetActiveCategoryById :one
+SELECT id, code, name, description, is_active, created_at, updated_at, version FROM category
+WHERE id = $1 AND is_active
+`
+
+func (q *Queries) GetActiveCategoryById(ctx context.Context, id int64) (Category, error) {
+	row := q.db.QueryRow(ctx, getActiveCategoryById, id)
 	var i Category
 	err := row.Scan(
 		&i.ID,
@@ -414,6 +1494,7 @@ func (q *Queries) GetActiveCategoryById(ctx context.Context, id int64) (Category
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -442,7 +1523,7 @@ func (q *Queries) GetActiveSessionBySourceId(ctx context.Context, sourceID strin
 }
 
 const getBudgetById = `-- name: GetBudgetById :one
-SELECT id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id FROM budget
+SELECT id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id, period_kind FROM budget
 WHERE id = $1::BIGINT
 `
 
@@ -458,12 +1539,71 @@ func (q *Queries) GetBudgetById(ctx context.Context, id int64) (Budget, error) {
 		&i.PeriodStart,
 		&i.PeriodEnd,
 		&i.SourceBudgetID,
+		&i.PeriodKind,
+	)
+	return i, err
+}
+
+const getBudgetClosingById = `-- name: GetBudgetClosingById :one
+SELECT
+    bc.id,
+    bc.budget_id,
+    bc.allocation_amount,
+    bc.actual_amount,
+    bc.unmapped_actual_amount,
+    bc.uncategorized_actual_amount,
+    bc.closed_by_user_id,
+    cu.name AS closed_by_user_name,
+    bc.closed_at,
+    bc.reopened_by_user_id,
+    ru.name AS reopened_by_user_name,
+    bc.reopened_at,
+    bc.reopen_reason
+FROM budget_closing bc
+JOIN users cu ON cu.id = bc.closed_by_user_id
+LEFT JOIN users ru ON ru.id = bc.reopened_by_user_id
+WHERE bc.id = $1::BIGINT
+`
+
+type GetBudgetClosingByIdRow struct {
+	ID                        int64              `json:"id"`
+	BudgetID                  int64              `json:"budgetId"`
+	AllocationAmount          pgtype.Numeric     `json:"allocationAmount"`
+	ActualAmount              pgtype.Numeric     `json:"actualAmount"`
+	UnmappedActualAmount      pgtype.Numeric     `json:"unmappedActualAmount"`
+	UncategorizedActualAmount pgtype.Numeric     `json:"uncategorizedActualAmount"`
+	ClosedByUserID            int64              `json:"closedByUserId"`
+	ClosedByUserName          string             `json:"closedByUserName"`
+	ClosedAt                  pgtype.Timestamptz `json:"closedAt"`
+	ReopenedByUserID          *int64             `json:"reopenedByUserId"`
+	ReopenedByUserName        *string            `json:"reopenedByUserName"`
+	ReopenedAt                pgtype.Timestamptz `json:"reopenedAt"`
+	ReopenReason              *string            `json:"reopenReason"`
+}
+
+func (q *Queries) GetBudgetClosingById(ctx context.Context, id int64) (GetBudgetClosingByIdRow, error) {
+	row := q.db.QueryRow(ctx, getBudgetClosingById, id)
+	var i GetBudgetClosingByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.BudgetID,
+		&i.AllocationAmount,
+		&i.ActualAmount,
+		&i.UnmappedActualAmount,
+		&i.UncategorizedActualAmount,
+		&i.ClosedByUserID,
+		&i.ClosedByUserName,
+		&i.ClosedAt,
+		&i.ReopenedByUserID,
+		&i.ReopenedByUserName,
+		&i.ReopenedAt,
+		&i.ReopenReason,
 	)
 	return i, err
 }
 
 const getBudgetLineById = `-- name: GetBudgetLineById :one
-SELECT id, budget_id, name, allocation_amount, sort_order, created_at, updated_at FROM budget_line
+SELECT id, budget_id, name, allocation_amount, sort_order, created_at, updated_at, version FROM budget_line
 WHERE id = $1::BIGINT
 `
 
@@ -478,12 +1618,35 @@ func (q *Queries) GetBudgetLineById(ctx context.Context, id int64) (BudgetLine,
 		&i.SortOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getBudgetTemplateById = `-- name: GetBudgetTemplateById :one
+
+SELECT id, household_id, user_id, name, created_at, updated_at FROM budget_template
+WHERE id = $1::BIGINT
+`
+
+// ******************* budget template *******************
+// READS
+func (q *Queries) GetBudgetTemplateById(ctx context.Context, id int64) (BudgetTemplate, error) {
+	row := q.db.QueryRow(ctx, getBudgetTemplateById, id)
+	var i BudgetTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
 const getCategoryByCode = `-- name: GetCategoryByCode :one
-SELECT id, code, name, description, is_active, created_at, updated_at FROM category
+SELECT id, code, name, description, is_active, created_at, updated_at, version FROM category
 WHERE code = $1
 `
 
@@ -498,12 +1661,13 @@ func (q *Queries) GetCategoryByCode(ctx context.Context, code string) (Category,
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getCategoryById = `-- name: GetCategoryById :one
-SELECT id, code, name, description, is_active, created_at, updated_at FROM category
+SELECT id, code, name, description, is_active, created_at, updated_at, version FROM category
 WHERE id = $1
 `
 
@@ -518,21 +1682,63 @@ func (q *Queries) GetCategoryById(ctx context.Context, id int64) (Category, erro
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
+const getClosedBudgetForTransaction = `-- name: GetClosedBudgetForTransaction :one
+SELECT b.id, b.period_start, b.period_end
+FROM budget b
+JOIN budget_closing bc
+    ON bc.budget_id = b.id
+   AND bc.reopened_at IS NULL
+WHERE $1::TIMESTAMPTZ >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+  AND $1::TIMESTAMPTZ < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+  AND (
+      (b.household_id IS NOT NULL AND b.household_id = $2::BIGINT)
+      OR
+      (b.user_id IS NOT NULL AND b.user_id = $3::BIGINT AND $2::BIGINT IS NULL)
+  )
+ORDER BY b.period_start ASC, b.id ASC
+LIMIT 1
+`
+
+type GetClosedBudgetForTransactionParams struct {
+	TransactionDate pgtype.Timestamptz `json:"transactionDate"`
+	HouseholdID     *int64             `json:"householdId"`
+	AuthorID        int64              `json:"authorId"`
+}
+
+type GetClosedBudgetForTransactionRow struct {
+	ID          int64       `json:"id"`
+	PeriodStart pgtype.Date `json:"periodStart"`
+	PeriodEnd   pgtype.Date `json:"periodEnd"`
+}
+
+// The earliest closed budget whose period and owner cover a transaction with
+// the given date, household and author, using the same scope as budget
+// reports.
+func (q *Queries) GetClosedBudgetForTransaction(ctx context.Context, arg GetClosedBudgetForTransactionParams) (GetClosedBudgetForTransactionRow, error) {
+	row := q.db.QueryRow(ctx, getClosedBudgetForTransaction, arg.TransactionDate, arg.HouseholdID, arg.AuthorID)
+	var i GetClosedBudgetForTransactionRow
+	err := row.Scan(&i.ID, &i.PeriodStart, &i.PeriodEnd)
+	return i, err
+}
+
 const getHouseholdBudgetByPeriod = `-- name: GetHouseholdBudgetByPeriod :one
 
-SELECT id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id FROM budget
+SELECT id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id, period_kind FROM budget
 WHERE household_id = $1::BIGINT
   AND user_id IS NULL
-  AND period_start = $2::DATE
-  AND period_end = $3::DATE
+  AND period_kind = $2::VARCHAR
+  AND period_start = $3::DATE
+  AND period_end = $4::DATE
 `
 
 type GetHouseholdBudgetByPeriodParams struct {
 	HouseholdID int64       `json:"householdId"`
+	PeriodKind  string      `json:"periodKind"`
 	PeriodStart pgtype.Date `json:"periodStart"`
 	PeriodEnd   pgtype.Date `json:"periodEnd"`
 }
@@ -540,7 +1746,12 @@ type GetHouseholdBudgetByPeriodParams struct {
 // ******************* budget *******************
 // READS
 func (q *Queries) GetHouseholdBudgetByPeriod(ctx context.Context, arg GetHouseholdBudgetByPeriodParams) (Budget, error) {
-	row := q.db.QueryRow(ctx, getHouseholdBudgetByPeriod, arg.HouseholdID, arg.PeriodStart, arg.PeriodEnd)
+	row := q.db.QueryRow(ctx, getHouseholdBudgetByPeriod,
+		arg.HouseholdID,
+		arg.PeriodKind,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+	)
 	var i Budget
 	err := row.Scan(
 		&i.ID,
@@ -551,6 +1762,32 @@ func (q *Queries) GetHouseholdBudgetByPeriod(ctx context.Context, arg GetHouseho
 		&i.PeriodStart,
 		&i.PeriodEnd,
 		&i.SourceBudgetID,
+		&i.PeriodKind,
+	)
+	return i, err
+}
+
+const getHouseholdBudgetTemplateByName = `-- name: GetHouseholdBudgetTemplateByName :one
+SELECT id, household_id, user_id, name, created_at, updated_at FROM budget_template
+WHERE household_id = $1::BIGINT
+  AND lower(name) = lower($2::VARCHAR)
+`
+
+type GetHouseholdBudgetTemplateByNameParams struct {
+	HouseholdID int64  `json:"householdId"`
+	Name        string `json:"name"`
+}
+
+func (q *Queries) GetHouseholdBudgetTemplateByName(ctx context.Context, arg GetHouseholdBudgetTemplateByNameParams) (BudgetTemplate, error) {
+	row := q.db.QueryRow(ctx, getHouseholdBudgetTemplateByName, arg.HouseholdID, arg.Name)
+	var i BudgetTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -653,22 +1890,52 @@ func (q *Queries) GetIdByTransactionId(ctx context.Context, transactionID string
 	return id, err
 }
 
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, fingerprint, status_code, response_headers, response_body, created_at, expires_at FROM idempotency_key
+WHERE key = $1::VARCHAR
+  AND expires_at > $2::TIMESTAMPTZ
+`
+
+type GetIdempotencyKeyParams struct {
+	Key string             `json:"key"`
+	Now pgtype.Timestamptz `json:"now"`
+}
+
+// ******************* idempotency *******************
+// READS
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.Key, arg.Now)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.Fingerprint,
+		&i.StatusCode,
+		&i.ResponseHeaders,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
 const getLatestPriorHouseholdBudget = `-- name: GetLatestPriorHouseholdBudget :one
-SELECT id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id FROM budget
+SELECT id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id, period_kind FROM budget
 WHERE household_id = $1::BIGINT
   AND user_id IS NULL
-  AND period_start < $2::DATE
+  AND period_kind = $2::VARCHAR
+  AND period_start < $3::DATE
 ORDER BY period_start DESC, id DESC
 LIMIT 1
 `
 
 type GetLatestPriorHouseholdBudgetParams struct {
 	HouseholdID int64       `json:"householdId"`
+	PeriodKind  string      `json:"periodKind"`
 	PeriodStart pgtype.Date `json:"periodStart"`
 }
 
 func (q *Queries) GetLatestPriorHouseholdBudget(ctx context.Context, arg GetLatestPriorHouseholdBudgetParams) (Budget, error) {
-	row := q.db.QueryRow(ctx, getLatestPriorHouseholdBudget, arg.HouseholdID, arg.PeriodStart)
+	row := q.db.QueryRow(ctx, getLatestPriorHouseholdBudget, arg.HouseholdID, arg.PeriodKind, arg.PeriodStart)
 	var i Budget
 	err := row.Scan(
 		&i.ID,
@@ -679,26 +1946,29 @@ func (q *Queries) GetLatestPriorHouseholdBudget(ctx context.Context, arg GetLate
 		&i.PeriodStart,
 		&i.PeriodEnd,
 		&i.SourceBudgetID,
+		&i.PeriodKind,
 	)
 	return i, err
 }
 
 const getLatestPriorUserBudget = `-- name: GetLatestPriorUserBudget :one
-SELECT id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id FROM budget
+SELECT id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id, period_kind FROM budget
 WHERE user_id = $1::BIGINT
   AND household_id IS NULL
-  AND period_start < $2::DATE
+  AND period_kind = $2::VARCHAR
+  AND period_start < $3::DATE
 ORDER BY period_start DESC, id DESC
 LIMIT 1
 `
 
 type GetLatestPriorUserBudgetParams struct {
 	UserID      int64       `json:"userId"`
+	PeriodKind  string      `json:"periodKind"`
 	PeriodStart pgtype.Date `json:"periodStart"`
 }
 
 func (q *Queries) GetLatestPriorUserBudget(ctx context.Context, arg GetLatestPriorUserBudgetParams) (Budget, error) {
-	row := q.db.QueryRow(ctx, getLatestPriorUserBudget, arg.UserID, arg.PeriodStart)
+	row := q.db.QueryRow(ctx, getLatestPriorUserBudget, arg.UserID, arg.PeriodKind, arg.PeriodStart)
 	var i Budget
 	err := row.Scan(
 		&i.ID,
@@ -709,6 +1979,7 @@ func (q *Queries) GetLatestPriorUserBudget(ctx context.Context, arg GetLatestPri
 		&i.PeriodStart,
 		&i.PeriodEnd,
 		&i.SourceBudgetID,
+		&i.PeriodKind,
 	)
 	return i, err
 }
@@ -728,6 +1999,62 @@ func (q *Queries) GetMaxBudgetLineSortOrder(ctx context.Context, budgetID int64)
 	return sort_order, err
 }
 
+const getOpenBudgetClosing = `-- name: GetOpenBudgetClosing :one
+SELECT id FROM budget_closing
+WHERE budget_id = $1::BIGINT
+  AND reopened_at IS NULL
+`
+
+func (q *Queries) GetOpenBudgetClosing(ctx context.Context, budgetID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getOpenBudgetClosing, budgetID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getSavedFilter = `-- name: GetSavedFilter :one
+SELECT id, name, query, created_at, updated_at FROM saved_filter
+WHERE name = $1
+`
+
+func (q *Queries) GetSavedFilter(ctx context.Context, name string) (SavedFilter, error) {
+	row := q.db.QueryRow(ctx, getSavedFilter, name)
+	var i SavedFilter
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Query,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSavingsGoalById = `-- name: GetSavingsGoalById :one
+
+SELECT id, household_id, user_id, name, target_amount, start_date, target_date, created_at, updated_at FROM savings_goal
+WHERE id = $1::BIGINT
+`
+
+// ******************* savings goal *******************
+// READS
+func (q *Queries) GetSavingsGoalById(ctx context.Context, id int64) (SavingsGoal, error) {
+	row := q.db.QueryRow(ctx, getSavingsGoalById, id)
+	var i SavingsGoal
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.Name,
+		&i.TargetAmount,
+		&i.StartDate,
+		&i.TargetDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getTableAndColumnMetadata = `-- name: GetTableAndColumnMetadata :many
 
 SELECT
@@ -837,9 +2164,33 @@ func (q *Queries) GetTableAndColumnMetadata(ctx context.Context, tableNames []st
 	return items, nil
 }
 
+const getTransactionAttachmentById = `-- name: GetTransactionAttachmentById :one
+
+SELECT id, transaction_id, file_name, content_type, size_bytes, sha256, storage_key, created_at FROM transaction_attachment
+WHERE id = $1::BIGINT
+`
+
+// ******************* transaction attachment *******************
+// READS
+func (q *Queries) GetTransactionAttachmentById(ctx context.Context, id int64) (TransactionAttachment, error) {
+	row := q.db.QueryRow(ctx, getTransactionAttachmentById, id)
+	var i TransactionAttachment
+	err := row.Scan(
+		&i.ID,
+		&i.TransactionID,
+		&i.FileName,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Sha256,
+		&i.StorageKey,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getTransactionById = `-- name: GetTransactionById :one
 
-SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id FROM transaction
+SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id, change_seq, version FROM transaction
 WHERE id = $1
 `
 
@@ -863,12 +2214,14 @@ func (q *Queries) GetTransactionById(ctx context.Context, id int64) (Transaction
 		&i.DeletedByUserID,
 		&i.DeleteReason,
 		&i.CategoryID,
+		&i.ChangeSeq,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getTransactionByIdActive = `-- name: GetTransactionByIdActive :one
-SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id FROM transaction
+SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id, change_seq, version FROM transaction
 WHERE id = $1 AND deleted_at IS NULL
 `
 
@@ -890,12 +2243,14 @@ func (q *Queries) GetTransactionByIdActive(ctx context.Context, id int64) (Trans
 		&i.DeletedByUserID,
 		&i.DeleteReason,
 		&i.CategoryID,
+		&i.ChangeSeq,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getTransactionByIdForUpdate = `-- name: GetTransactionByIdForUpdate :one
-SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id FROM transaction
+SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id, change_seq, version FROM transaction
 WHERE id = $1::BIGINT
 FOR UPDATE
 `
@@ -918,12 +2273,14 @@ func (q *Queries) GetTransactionByIdForUpdate(ctx context.Context, id int64) (Tr
 		&i.DeletedByUserID,
 		&i.DeleteReason,
 		&i.CategoryID,
+		&i.ChangeSeq,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getTransactionsById = `-- name: GetTransactionsById :many
-SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id FROM transaction
+SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id, change_seq, version FROM transaction
 WHERE id = ANY($1::BIGINT[])
 `
 
@@ -951,6 +2308,8 @@ func (q *Queries) GetTransactionsById(ctx context.Context, ids []int64) ([]Trans
 			&i.DeletedByUserID,
 			&i.DeleteReason,
 			&i.CategoryID,
+			&i.ChangeSeq,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -963,7 +2322,7 @@ func (q *Queries) GetTransactionsById(ctx context.Context, ids []int64) ([]Trans
 }
 
 const getTransactionsByIdActive = `-- name: GetTransactionsByIdActive :many
-SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id FROM transaction
+SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id, change_seq, version FROM transaction
 WHERE id = ANY($1::BIGINT[])
   AND deleted_at IS NULL
 `
@@ -992,6 +2351,8 @@ func (q *Queries) GetTransactionsByIdActive(ctx context.Context, ids []int64) ([
 			&i.DeletedByUserID,
 			&i.DeleteReason,
 			&i.CategoryID,
+			&i.ChangeSeq,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -1005,14 +2366,18 @@ func (q *Queries) GetTransactionsByIdActive(ctx context.Context, ids []int64) ([
 
 const getTransactionsByIdWithDetails = `-- name: GetTransactionsByIdWithDetails :many
 SELECT
-    t.id, t.amount, t.author_id, t.description, t.transaction_date, t.transaction_id, t.household_id, t.notes, t.created_at, t.updated_at, t.deleted_at, t.deleted_by_user_id, t.delete_reason, t.category_id,
+    t.id, t.amount, t.author_id, t.description, t.transaction_date, t.transaction_id, t.household_id, t.notes, t.created_at, t.updated_at, t.deleted_at, t.deleted_by_user_id, t.delete_reason, t.category_id, t.change_seq, t.version,
     u.id AS author_id,
     u.name AS author_name,
     h.id AS household_id,
     h.name AS household_name,
     c.id AS category_id,
     c.code AS category_code,
-    c.name AS category_name
+    c.name AS category_name,
+    ARRAY(
+        SELECT tg.name FROM transaction_tag tt JOIN tag tg ON tg.id = tt.tag_id
+        WHERE tt.transaction_id = t.id ORDER BY tg.name
+    )::TEXT[] AS tags
 FROM transaction t
 JOIN users u ON u.id = t.author_id
 LEFT JOIN household h ON h.id = t.household_id
@@ -1036,6 +2401,7 @@ type GetTransactionsByIdWithDetailsRow struct {
 	CategoryID    *int64      `json:"categoryId"`
 	CategoryCode  *string     `json:"categoryCode"`
 	CategoryName  *string     `json:"categoryName"`
+	Tags          []string    `json:"tags"`
 }
 
 func (q *Queries) GetTransactionsByIdWithDetails(ctx context.Context, arg GetTransactionsByIdWithDetailsParams) ([]GetTransactionsByIdWithDetailsRow, error) {
@@ -1062,6 +2428,8 @@ func (q *Queries) GetTransactionsByIdWithDetails(ctx context.Context, arg GetTra
 			&i.Transaction.DeletedByUserID,
 			&i.Transaction.DeleteReason,
 			&i.Transaction.CategoryID,
+			&i.Transaction.ChangeSeq,
+			&i.Transaction.Version,
 			&i.AuthorID,
 			&i.AuthorName,
 			&i.HouseholdID,
@@ -1069,6 +2437,7 @@ func (q *Queries) GetTransactionsByIdWithDetails(ctx context.Context, arg GetTra
 			&i.CategoryID,
 			&i.CategoryCode,
 			&i.CategoryName,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -1081,21 +2450,28 @@ func (q *Queries) GetTransactionsByIdWithDetails(ctx context.Context, arg GetTra
 }
 
 const getUserBudgetByPeriod = `-- name: GetUserBudgetByPeriod :one
-SELECT id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id FROM budget
+SELECT id, user_id, household_id, created_at, updated_at, period_start, period_end, source_budget_id, period_kind FROM budget
 WHERE user_id = $1::BIGINT
   AND household_id IS NULL
-  AND period_start = $2::DATE
-  AND period_end = $3::DATE
+  AND period_kind = $2::VARCHAR
+  AND period_start = $3::DATE
+  AND period_end = $4::DATE
 `
 
 type GetUserBudgetByPeriodParams struct {
 	UserID      int64       `json:"userId"`
+	PeriodKind  string      `json:"periodKind"`
 	PeriodStart pgtype.Date `json:"periodStart"`
 	PeriodEnd   pgtype.Date `json:"periodEnd"`
 }
 
 func (q *Queries) GetUserBudgetByPeriod(ctx context.Context, arg GetUserBudgetByPeriodParams) (Budget, error) {
-	row := q.db.QueryRow(ctx, getUserBudgetByPeriod, arg.UserID, arg.PeriodStart, arg.PeriodEnd)
+	row := q.db.QueryRow(ctx, getUserBudgetByPeriod,
+		arg.UserID,
+		arg.PeriodKind,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+	)
 	var i Budget
 	err := row.Scan(
 		&i.ID,
@@ -1106,6 +2482,32 @@ func (q *Queries) GetUserBudgetByPeriod(ctx context.Context, arg GetUserBudgetBy
 		&i.PeriodStart,
 		&i.PeriodEnd,
 		&i.SourceBudgetID,
+		&i.PeriodKind,
+	)
+	return i, err
+}
+
+const getUserBudgetTemplateByName = `-- name: GetUserBudgetTemplateByName :one
+SELECT id, household_id, user_id, name, created_at, updated_at FROM budget_template
+WHERE user_id = $1::BIGINT
+  AND lower(name) = lower($2::VARCHAR)
+`
+
+type GetUserBudgetTemplateByNameParams struct {
+	UserID int64  `json:"userId"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) GetUserBudgetTemplateByName(ctx context.Context, arg GetUserBudgetTemplateByNameParams) (BudgetTemplate, error) {
+	row := q.db.QueryRow(ctx, getUserBudgetTemplateByName, arg.UserID, arg.Name)
+	var i BudgetTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -1273,42 +2675,1219 @@ func (q *Queries) GetUserDetailsByDiscordId(ctx context.Context, discordID *stri
 	return i, err
 }
 
-const listBudgetLineCategories = `-- name: ListBudgetLineCategories :many
-SELECT
-    blc.budget_id,
-    blc.budget_line_id,
-    blc.category_id,
-    c.code AS category_code,
-    c.name AS category_name
-FROM budget_line_category blc
-JOIN category c ON c.id = blc.category_id
-WHERE blc.budget_id = $1::BIGINT
-ORDER BY blc.budget_line_id ASC, c.name ASC, c.id ASC
+const getWebhookSubscriptionById = `-- name: GetWebhookSubscriptionById :one
+
+SELECT id, url, event_types, household_id, secret, is_active, created_at, updated_at FROM webhook_subscription
+WHERE id = $1::BIGINT
+`
+
+// ******************* webhook *******************
+// READS
+func (q *Queries) GetWebhookSubscriptionById(ctx context.Context, id int64) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, getWebhookSubscriptionById, id)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.EventTypes,
+		&i.HouseholdID,
+		&i.Secret,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listBudgetAlertCandidates = `-- name: ListBudgetAlertCandidates :many
+
+SELECT
+    b.id AS budget_id,
+    bl.id AS budget_line_id,
+    bl.allocation_amount,
+    r.threshold_percent,
+    actual.actual_amount
+FROM transaction t
+JOIN budget_line_category blc ON blc.category_id = t.category_id
+JOIN budget b ON b.id = blc.budget_id
+JOIN budget_line bl ON bl.id = blc.budget_line_id
+JOIN budget_line_alert_rule r ON r.budget_line_id = bl.id
+CROSS JOIN LATERAL (
+    SELECT ROUND(COALESCE(SUM(lt.amount), 0)::NUMERIC, 2)::NUMERIC AS actual_amount
+    FROM budget_line_category lc
+    JOIN transaction lt
+        ON lt.deleted_at IS NULL
+       AND lt.category_id = lc.category_id
+       AND lt.transaction_date >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+       AND lt.transaction_date < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+       AND (
+           (b.household_id IS NOT NULL AND lt.household_id = b.household_id)
+           OR
+           (b.user_id IS NOT NULL AND lt.author_id = b.user_id AND lt.household_id IS NULL)
+       )
+    WHERE lc.budget_line_id = bl.id
+) actual
+WHERE t.id = $1::BIGINT
+  AND t.deleted_at IS NULL
+  AND t.transaction_date >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+  AND t.transaction_date < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+  AND (
+      (b.household_id IS NOT NULL AND t.household_id = b.household_id)
+      OR
+      (b.user_id IS NOT NULL AND t.author_id = b.user_id AND t.household_id IS NULL)
+  )
+  AND NOT EXISTS (
+      SELECT 1
+      FROM budget_alert a
+      WHERE a.budget_line_id = bl.id
+        AND a.threshold_percent = r.threshold_percent
+  )
+ORDER BY b.id ASC, bl.sort_order ASC, bl.id ASC, r.threshold_percent ASC
+`
+
+type ListBudgetAlertCandidatesRow struct {
+	BudgetID         int64          `json:"budgetId"`
+	BudgetLineID     int64          `json:"budgetLineId"`
+	AllocationAmount pgtype.Numeric `json:"allocationAmount"`
+	ThresholdPercent int32          `json:"thresholdPercent"`
+	ActualAmount     pgtype.Numeric `json:"actualAmount"`
+}
+
+// ******************* budget alert *******************
+// READS
+// Alert rules not yet triggered on lines that map the transaction's category,
+// in every budget whose owner scope and period contain the transaction, with
+// the line's current actual amount.
+func (q *Queries) ListBudgetAlertCandidates(ctx context.Context, transactionID int64) ([]ListBudgetAlertCandidatesRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetAlertCandidates, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetAlertCandidatesRow
+	for rows.Next() {
+		var i ListBudgetAlertCandidatesRow
+		if err := rows.Scan(
+			&i.BudgetID,
+			&i.BudgetLineID,
+			&i.AllocationAmount,
+			&i.ThresholdPercent,
+			&i.ActualAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetAlerts = `-- name: ListBudgetAlerts :many
+SELECT
+    a.id,
+    a.budget_id,
+    a.budget_line_id,
+    bl.name AS budget_line_name,
+    b.household_id,
+    b.user_id,
+    b.period_kind,
+    b.period_start,
+    b.period_end,
+    a.threshold_percent,
+    a.allocation_amount,
+    a.actual_amount,
+    a.transaction_id,
+    a.notified_at,
+    a.notification_error,
+    a.created_at
+FROM budget_alert a
+JOIN budget b ON b.id = a.budget_id
+JOIN budget_line bl ON bl.id = a.budget_line_id
+WHERE ($1::BIGINT IS NULL OR a.id = $1::BIGINT)
+  AND ($2::BIGINT IS NULL OR a.budget_id = $2::BIGINT)
+  AND ($3::BIGINT IS NULL OR b.household_id = $3::BIGINT)
+  AND ($4::BIGINT IS NULL OR b.user_id = $4::BIGINT)
+ORDER BY a.created_at DESC, a.id DESC
+LIMIT $5::INTEGER
+`
+
+type ListBudgetAlertsParams struct {
+	ID          *int64 `json:"id"`
+	BudgetID    *int64 `json:"budgetId"`
+	HouseholdID *int64 `json:"householdId"`
+	UserID      *int64 `json:"userId"`
+	RowLimit    int32  `json:"rowLimit"`
+}
+
+type ListBudgetAlertsRow struct {
+	ID                int64              `json:"id"`
+	BudgetID          int64              `json:"budgetId"`
+	BudgetLineID      int64              `json:"budgetLineId"`
+	BudgetLineName    string             `json:"budgetLineName"`
+	HouseholdID       *int64             `json:"householdId"`
+	UserID            *int64             `json:"userId"`
+	PeriodKind        string             `json:"periodKind"`
+	PeriodStart       pgtype.Date        `json:"periodStart"`
+	PeriodEnd         pgtype.Date        `json:"periodEnd"`
+	ThresholdPercent  int32              `json:"thresholdPercent"`
+	AllocationAmount  pgtype.Numeric     `json:"allocationAmount"`
+	ActualAmount      pgtype.Numeric     `json:"actualAmount"`
+	TransactionID     *int64             `json:"transactionId"`
+	NotifiedAt        pgtype.Timestamptz `json:"notifiedAt"`
+	NotificationError *string            `json:"notificationError"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+}
+
+func (q *Queries) ListBudgetAlerts(ctx context.Context, arg ListBudgetAlertsParams) ([]ListBudgetAlertsRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetAlerts,
+		arg.ID,
+		arg.BudgetID,
+		arg.HouseholdID,
+		arg.UserID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetAlertsRow
+	for rows.Next() {
+		var i ListBudgetAlertsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
+			&i.BudgetLineID,
+			&i.BudgetLineName,
+			&i.HouseholdID,
+			&i.UserID,
+			&i.PeriodKind,
+			&i.PeriodStart,
+			&i.PeriodEnd,
+			&i.ThresholdPercent,
+			&i.AllocationAmount,
+			&i.ActualAmount,
+			&i.TransactionID,
+			&i.NotifiedAt,
+			&i.NotificationError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetClosingLines = `-- name: ListBudgetClosingLines :many
+SELECT id, closing_id, budget_line_id, name, allocation_amount, actual_amount, sort_order FROM budget_closing_line
+WHERE closing_id = $1::BIGINT
+ORDER BY sort_order ASC, id ASC
+`
+
+func (q *Queries) ListBudgetClosingLines(ctx context.Context, closingID int64) ([]BudgetClosingLine, error) {
+	rows, err := q.db.Query(ctx, listBudgetClosingLines, closingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BudgetClosingLine
+	for rows.Next() {
+		var i BudgetClosingLine
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClosingID,
+			&i.BudgetLineID,
+			&i.Name,
+			&i.AllocationAmount,
+			&i.ActualAmount,
+			&i.SortOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetHouseholdMemberIds = `-- name: ListBudgetHouseholdMemberIds :many
+SELECT hu.user_id
+FROM budget b
+JOIN household_user hu ON hu.household_id = b.household_id
+WHERE b.id = $1::BIGINT
+ORDER BY hu.user_id ASC
+`
+
+func (q *Queries) ListBudgetHouseholdMemberIds(ctx context.Context, budgetID int64) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listBudgetHouseholdMemberIds, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var user_id int64
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetLineAlertRules = `-- name: ListBudgetLineAlertRules :many
+SELECT r.budget_line_id, r.threshold_percent
+FROM budget_line_alert_rule r
+JOIN budget_line bl ON bl.id = r.budget_line_id
+WHERE bl.budget_id = $1::BIGINT
+ORDER BY r.budget_line_id ASC, r.threshold_percent ASC
+`
+
+type ListBudgetLineAlertRulesRow struct {
+	BudgetLineID     int64 `json:"budgetLineId"`
+	ThresholdPercent int32 `json:"thresholdPercent"`
+}
+
+func (q *Queries) ListBudgetLineAlertRules(ctx context.Context, budgetID int64) ([]ListBudgetLineAlertRulesRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetLineAlertRules, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetLineAlertRulesRow
+	for rows.Next() {
+		var i ListBudgetLineAlertRulesRow
+		if err := rows.Scan(&i.BudgetLineID, &i.ThresholdPercent); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetLineCategories = `-- name: ListBudgetLineCategories :many
+SELECT
+    blc.budget_id,
+    blc.budget_line_id,
+    blc.category_id,
+    c.code AS category_code,
+    c.name AS category_name
+FROM budget_line_category blc
+JOIN category c ON c.id = blc.category_id
+WHERE blc.budget_id = $1::BIGINT
+ORDER BY blc.budget_line_id ASC, c.name ASC, c.id ASC
+`
+
+type ListBudgetLineCategoriesRow struct {
+	BudgetID     int64  `json:"budgetId"`
+	BudgetLineID int64  `json:"budgetLineId"`
+	CategoryID   int64  `json:"categoryId"`
+	CategoryCode string `json:"categoryCode"`
+	CategoryName string `json:"categoryName"`
+}
+
+func (q *Queries) ListBudgetLineCategories(ctx context.Context, budgetID int64) ([]ListBudgetLineCategoriesRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetLineCategories, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetLineCategoriesRow
+	for rows.Next() {
+		var i ListBudgetLineCategoriesRow
+		if err := rows.Scan(
+			&i.BudgetID,
+			&i.BudgetLineID,
+			&i.CategoryID,
+			&i.CategoryCode,
+			&i.CategoryName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetLineHistoryTransactions = `-- name: ListBudgetLineHistoryTransactions :many
+SELECT
+    blc.budget_line_id,
+    t.id,
+    t.transaction_date,
+    ROUND(t.amount::NUMERIC, 2) AS amount,
+    t.description
+FROM budget b
+JOIN budget_line_category blc ON blc.budget_id = b.id
+JOIN transaction t
+    ON t.deleted_at IS NULL
+   AND t.category_id = blc.category_id
+   AND t.transaction_date >= ($1::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+   AND t.transaction_date < (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+   AND (
+       (b.household_id IS NOT NULL AND t.household_id = b.household_id)
+       OR
+       (b.user_id IS NOT NULL AND t.author_id = b.user_id AND t.household_id IS NULL)
+   )
+WHERE b.id = $2::BIGINT
+ORDER BY blc.budget_line_id ASC, t.transaction_date ASC, t.id ASC
+`
+
+type ListBudgetLineHistoryTransactionsParams struct {
+	HistoryStart pgtype.Date `json:"historyStart"`
+	BudgetID     int64       `json:"budgetId"`
+}
+
+type ListBudgetLineHistoryTransactionsRow struct {
+	BudgetLineID    int64              `json:"budgetLineId"`
+	ID              int64              `json:"id"`
+	TransactionDate pgtype.Timestamptz `json:"transactionDate"`
+	Amount          pgtype.Numeric     `json:"amount"`
+	Description     *string            `json:"description"`
+}
+
+// Transactions in the budget's owner scope and line categories from
+// history_start up to the day before the budget period starts. Forecasts use
+// the current line mappings, so history follows the line rather than the
+// budgets that existed at the time.
+func (q *Queries) ListBudgetLineHistoryTransactions(ctx context.Context, arg ListBudgetLineHistoryTransactionsParams) ([]ListBudgetLineHistoryTransactionsRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetLineHistoryTransactions, arg.HistoryStart, arg.BudgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetLineHistoryTransactionsRow
+	for rows.Next() {
+		var i ListBudgetLineHistoryTransactionsRow
+		if err := rows.Scan(
+			&i.BudgetLineID,
+			&i.ID,
+			&i.TransactionDate,
+			&i.Amount,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetLineMemberAllocations = `-- name: ListBudgetLineMemberAllocations :many
+SELECT
+    a.budget_line_id,
+    a.user_id,
+    u.name AS user_name,
+    a.allocation_amount,
+    a.allocation_percent
+FROM budget_line_member_allocation a
+JOIN budget_line bl ON bl.id = a.budget_line_id
+JOIN users u ON u.id = a.user_id
+WHERE bl.budget_id = $1::BIGINT
+ORDER BY a.budget_line_id ASC, u.name ASC, u.id ASC
+`
+
+type ListBudgetLineMemberAllocationsRow struct {
+	BudgetLineID      int64          `json:"budgetLineId"`
+	UserID            int64          `json:"userId"`
+	UserName          string         `json:"userName"`
+	AllocationAmount  pgtype.Numeric `json:"allocationAmount"`
+	AllocationPercent pgtype.Numeric `json:"allocationPercent"`
+}
+
+func (q *Queries) ListBudgetLineMemberAllocations(ctx context.Context, budgetID int64) ([]ListBudgetLineMemberAllocationsRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetLineMemberAllocations, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetLineMemberAllocationsRow
+	for rows.Next() {
+		var i ListBudgetLineMemberAllocationsRow
+		if err := rows.Scan(
+			&i.BudgetLineID,
+			&i.UserID,
+			&i.UserName,
+			&i.AllocationAmount,
+			&i.AllocationPercent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetLineOriginalAllocations = `-- name: ListBudgetLineOriginalAllocations :many
+SELECT
+    bl.id AS budget_line_id,
+    (bl.allocation_amount - COALESCE(SUM(moves.amount), 0))::NUMERIC AS original_allocation_amount
+FROM budget_line bl
+LEFT JOIN (
+    SELECT to_line_id AS line_id, amount FROM budget_reallocation
+    WHERE budget_id = $1::BIGINT
+    UNION ALL
+    SELECT from_line_id AS line_id, -amount FROM budget_reallocation
+    WHERE budget_id = $1::BIGINT
+) moves ON moves.line_id = bl.id
+WHERE bl.budget_id = $1::BIGINT
+GROUP BY bl.id, bl.allocation_amount
+ORDER BY bl.id ASC
+`
+
+type ListBudgetLineOriginalAllocationsRow struct {
+	BudgetLineID             int64          `json:"budgetLineId"`
+	OriginalAllocationAmount pgtype.Numeric `json:"originalAllocationAmount"`
+}
+
+// Each line's allocation before reallocations: its current allocation minus
+// the net amount moved into it.
+func (q *Queries) ListBudgetLineOriginalAllocations(ctx context.Context, budgetID int64) ([]ListBudgetLineOriginalAllocationsRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetLineOriginalAllocations, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetLineOriginalAllocationsRow
+	for rows.Next() {
+		var i ListBudgetLineOriginalAllocationsRow
+		if err := rows.Scan(&i.BudgetLineID, &i.OriginalAllocationAmount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetLineage = `-- name: ListBudgetLineage :many
+WITH RECURSIVE lineage (id, generation) AS (
+    SELECT b.source_budget_id, 1
+    FROM budget b
+    WHERE b.id = $1::BIGINT
+      AND b.source_budget_id IS NOT NULL
+    UNION ALL
+    SELECT b.source_budget_id, l.generation + 1
+    FROM budget b
+    JOIN lineage l ON l.id = b.id
+    WHERE b.source_budget_id IS NOT NULL
+      AND l.generation < $2::INTEGER
+)
+SELECT id::BIGINT AS id
+FROM lineage
+ORDER BY generation
+`
+
+type ListBudgetLineageParams struct {
+	ID             int64 `json:"id"`
+	MaxGenerations int32 `json:"maxGenerations"`
+}
+
+func (q *Queries) ListBudgetLineage(ctx context.Context, arg ListBudgetLineageParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listBudgetLineage, arg.ID, arg.MaxGenerations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetLines = `-- name: ListBudgetLines :many
+SELECT id, budget_id, name, allocation_amount, sort_order, created_at, updated_at, version FROM budget_line
+WHERE budget_id = $1::BIGINT
+ORDER BY sort_order ASC, id ASC
+`
+
+func (q *Queries) ListBudgetLines(ctx context.Context, budgetID int64) ([]BudgetLine, error) {
+	rows, err := q.db.Query(ctx, listBudgetLines, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BudgetLine
+	for rows.Next() {
+		var i BudgetLine
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
+			&i.Name,
+			&i.AllocationAmount,
+			&i.SortOrder,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetReallocations = `-- name: ListBudgetReallocations :many
+SELECT
+    r.id,
+    r.budget_id,
+    r.from_line_id,
+    fl.name AS from_line_name,
+    r.to_line_id,
+    tl.name AS to_line_name,
+    r.amount,
+    r.reason,
+    r.created_by_user_id,
+    u.name AS created_by_user_name,
+    r.created_at
+FROM budget_reallocation r
+JOIN budget_line fl ON fl.id = r.from_line_id
+JOIN budget_line tl ON tl.id = r.to_line_id
+JOIN users u ON u.id = r.created_by_user_id
+WHERE r.budget_id = $1::BIGINT
+ORDER BY r.created_at ASC, r.id ASC
+`
+
+type ListBudgetReallocationsRow struct {
+	ID                int64              `json:"id"`
+	BudgetID          int64              `json:"budgetId"`
+	FromLineID        int64              `json:"fromLineId"`
+	FromLineName      string             `json:"fromLineName"`
+	ToLineID          int64              `json:"toLineId"`
+	ToLineName        string             `json:"toLineName"`
+	Amount            pgtype.Numeric     `json:"amount"`
+	Reason            *string            `json:"reason"`
+	CreatedByUserID   int64              `json:"createdByUserId"`
+	CreatedByUserName string             `json:"createdByUserName"`
+	CreatedAt         pgtype.Timestamptz `json:"createdAt"`
+}
+
+func (q *Queries) ListBudgetReallocations(ctx context.Context, budgetID int64) ([]ListBudgetReallocationsRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetReallocations, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetReallocationsRow
+	for rows.Next() {
+		var i ListBudgetReallocationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
+			&i.FromLineID,
+			&i.FromLineName,
+			&i.ToLineID,
+			&i.ToLineName,
+			&i.Amount,
+			&i.Reason,
+			&i.CreatedByUserID,
+			&i.CreatedByUserName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetReportLines = `-- name: ListBudgetReportLines :many
+SELECT
+    bl.id,
+    bl.budget_id,
+    bl.name,
+    bl.allocation_amount,
+    ROUND(COALESCE(SUM(t.amount), 0)::NUMERIC, 2) AS actual_amount,
+    bl.sort_order
+FROM budget b
+JOIN budget_line bl ON bl.budget_id = b.id
+LEFT JOIN budget_line_category blc
+    ON blc.budget_id = b.id
+   AND blc.budget_line_id = bl.id
+LEFT JOIN transaction t
+    ON t.deleted_at IS NULL
+   AND t.category_id = blc.category_id
+   AND t.transaction_date >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+   AND t.transaction_date < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+   AND (
+       (b.household_id IS NOT NULL AND t.household_id = b.household_id)
+       OR
+       (b.user_id IS NOT NULL AND t.author_id = b.user_id AND t.household_id IS NULL)
+   )
+WHERE b.id = $1::BIGINT
+GROUP BY bl.id, bl.budget_id, bl.name, bl.allocation_amount, bl.sort_order
+ORDER BY bl.sort_order ASC, bl.id ASC
+`
+
+type ListBudgetReportLinesRow struct {
+	ID               int64          `json:"id"`
+	BudgetID         int64          `json:"budgetId"`
+	Name             string         `json:"name"`
+	AllocationAmount pgtype.Numeric `json:"allocationAmount"`
+	ActualAmount     pgtype.Numeric `json:"actualAmount"`
+	SortOrder        int32          `json:"sortOrder"`
+}
+
+func (q *Queries) ListBudgetReportLines(ctx context.Context, budgetID int64) ([]ListBudgetReportLinesRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetReportLines, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetReportLinesRow
+	for rows.Next() {
+		var i ListBudgetReportLinesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BudgetID,
+			&i.Name,
+			&i.AllocationAmount,
+			&i.ActualAmount,
+			&i.SortOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetTemplateLineCategories = `-- name: ListBudgetTemplateLineCategories :many
+SELECT
+    tlc.template_id,
+    tlc.template_line_id,
+    tlc.category_id,
+    c.code AS category_code,
+    c.name AS category_name
+FROM budget_template_line_category tlc
+JOIN category c ON c.id = tlc.category_id
+WHERE tlc.template_id = ANY($1::BIGINT[])
+ORDER BY tlc.template_line_id ASC, c.name ASC, c.id ASC
+`
+
+type ListBudgetTemplateLineCategoriesRow struct {
+	TemplateID     int64  `json:"templateId"`
+	TemplateLineID int64  `json:"templateLineId"`
+	CategoryID     int64  `json:"categoryId"`
+	CategoryCode   string `json:"categoryCode"`
+	CategoryName   string `json:"categoryName"`
+}
+
+func (q *Queries) ListBudgetTemplateLineCategories(ctx context.Context, templateIds []int64) ([]ListBudgetTemplateLineCategoriesRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetTemplateLineCategories, templateIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetTemplateLineCategoriesRow
+	for rows.Next() {
+		var i ListBudgetTemplateLineCategoriesRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.TemplateLineID,
+			&i.CategoryID,
+			&i.CategoryCode,
+			&i.CategoryName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetTemplateLines = `-- name: ListBudgetTemplateLines :many
+SELECT id, template_id, name, allocation_amount, sort_order FROM budget_template_line
+WHERE template_id = ANY($1::BIGINT[])
+ORDER BY template_id ASC, sort_order ASC, id ASC
+`
+
+func (q *Queries) ListBudgetTemplateLines(ctx context.Context, templateIds []int64) ([]BudgetTemplateLine, error) {
+	rows, err := q.db.Query(ctx, listBudgetTemplateLines, templateIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BudgetTemplateLine
+	for rows.Next() {
+		var i BudgetTemplateLine
+		if err := rows.Scan(
+			&i.ID,
+			&i.TemplateID,
+			&i.Name,
+			&i.AllocationAmount,
+			&i.SortOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetTemplates = `-- name: ListBudgetTemplates :many
+SELECT id, household_id, user_id, name, created_at, updated_at FROM budget_template
+WHERE ($1::BIGINT IS NULL OR household_id = $1::BIGINT)
+  AND ($2::BIGINT IS NULL OR user_id = $2::BIGINT)
+ORDER BY lower(name) ASC, id ASC
+`
+
+type ListBudgetTemplatesParams struct {
+	HouseholdID *int64 `json:"householdId"`
+	UserID      *int64 `json:"userId"`
+}
+
+func (q *Queries) ListBudgetTemplates(ctx context.Context, arg ListBudgetTemplatesParams) ([]BudgetTemplate, error) {
+	rows, err := q.db.Query(ctx, listBudgetTemplates, arg.HouseholdID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BudgetTemplate
+	for rows.Next() {
+		var i BudgetTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.HouseholdID,
+			&i.UserID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetTrendCategoryActuals = `-- name: ListBudgetTrendCategoryActuals :many
+SELECT
+    DATE_TRUNC('month', t.transaction_date AT TIME ZONE 'UTC')::DATE AS month,
+    c.id AS category_id,
+    c.code AS category_code,
+    c.name AS category_name,
+    ROUND(SUM(t.amount)::NUMERIC, 2) AS actual_amount
+FROM transaction t
+LEFT JOIN category c ON c.id = t.category_id
+WHERE t.deleted_at IS NULL
+  AND t.transaction_date >= ($1::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+  AND t.transaction_date < (($2::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+  AND (
+      ($3::BIGINT IS NOT NULL AND t.household_id = $3::BIGINT)
+      OR
+      ($4::BIGINT IS NOT NULL AND t.author_id = $4::BIGINT AND t.household_id IS NULL)
+  )
+GROUP BY 1, c.id, c.code, c.name
+ORDER BY 1 ASC, c.name ASC NULLS LAST
+`
+
+type ListBudgetTrendCategoryActualsParams struct {
+	PeriodStart pgtype.Date `json:"periodStart"`
+	PeriodEnd   pgtype.Date `json:"periodEnd"`
+	HouseholdID *int64      `json:"householdId"`
+	UserID      *int64      `json:"userId"`
+}
+
+type ListBudgetTrendCategoryActualsRow struct {
+	Month        pgtype.Date    `json:"month"`
+	CategoryID   *int64         `json:"categoryId"`
+	CategoryCode *string        `json:"categoryCode"`
+	CategoryName *string        `json:"categoryName"`
+	ActualAmount pgtype.Numeric `json:"actualAmount"`
+}
+
+// Owner-scoped spending per UTC calendar month and category, including
+// uncategorized spending, whether or not a budget existed for the month.
+func (q *Queries) ListBudgetTrendCategoryActuals(ctx context.Context, arg ListBudgetTrendCategoryActualsParams) ([]ListBudgetTrendCategoryActualsRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetTrendCategoryActuals,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+		arg.HouseholdID,
+		arg.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetTrendCategoryActualsRow
+	for rows.Next() {
+		var i ListBudgetTrendCategoryActualsRow
+		if err := rows.Scan(
+			&i.Month,
+			&i.CategoryID,
+			&i.CategoryCode,
+			&i.CategoryName,
+			&i.ActualAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBudgetTrendLines = `-- name: ListBudgetTrendLines :many
+SELECT
+    b.id AS budget_id,
+    b.period_start,
+    bl.id,
+    bl.name,
+    bl.allocation_amount,
+    ROUND(COALESCE(SUM(t.amount), 0)::NUMERIC, 2) AS actual_amount,
+    bl.sort_order
+FROM budget b
+JOIN budget_line bl ON bl.budget_id = b.id
+LEFT JOIN budget_line_category blc
+    ON blc.budget_id = b.id
+   AND blc.budget_line_id = bl.id
+LEFT JOIN transaction t
+    ON t.deleted_at IS NULL
+   AND t.category_id = blc.category_id
+   AND t.transaction_date >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+   AND t.transaction_date < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+   AND (
+       (b.household_id IS NOT NULL AND t.household_id = b.household_id)
+       OR
+       (b.user_id IS NOT NULL AND t.author_id = b.user_id AND t.household_id IS NULL)
+   )
+WHERE b.period_kind = 'monthly'
+  AND b.period_start >= $1::DATE
+  AND b.period_start <= $2::DATE
+  AND (
+      ($3::BIGINT IS NOT NULL AND b.household_id = $3::BIGINT AND b.user_id IS NULL)
+      OR
+      ($4::BIGINT IS NOT NULL AND b.user_id = $4::BIGINT AND b.household_id IS NULL)
+  )
+GROUP BY b.id, b.period_start, bl.id, bl.name, bl.allocation_amount, bl.sort_order
+ORDER BY b.period_start ASC, bl.sort_order ASC, bl.id ASC
+`
+
+type ListBudgetTrendLinesParams struct {
+	PeriodStart pgtype.Date `json:"periodStart"`
+	PeriodEnd   pgtype.Date `json:"periodEnd"`
+	HouseholdID *int64      `json:"householdId"`
+	UserID      *int64      `json:"userId"`
+}
+
+type ListBudgetTrendLinesRow struct {
+	BudgetID         int64          `json:"budgetId"`
+	PeriodStart      pgtype.Date    `json:"periodStart"`
+	ID               int64          `json:"id"`
+	Name             string         `json:"name"`
+	AllocationAmount pgtype.Numeric `json:"allocationAmount"`
+	ActualAmount     pgtype.Numeric `json:"actualAmount"`
+	SortOrder        int32          `json:"sortOrder"`
+}
+
+// Lines of an owner's monthly budgets that start between period_start and
+// period_end, with each line's actual spending in its own period.
+func (q *Queries) ListBudgetTrendLines(ctx context.Context, arg ListBudgetTrendLinesParams) ([]ListBudgetTrendLinesRow, error) {
+	rows, err := q.db.Query(ctx, listBudgetTrendLines,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+		arg.HouseholdID,
+		arg.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBudgetTrendLinesRow
+	for rows.Next() {
+		var i ListBudgetTrendLinesRow
+		if err := rows.Scan(
+			&i.BudgetID,
+			&i.PeriodStart,
+			&i.ID,
+			&i.Name,
+			&i.AllocationAmount,
+			&i.ActualAmount,
+			&i.SortOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategories = `-- name: ListCategories :many
+
+SELECT id, code, name, description, is_active, created_at, updated_at, version FROM category
+WHERE ($1::bool OR is_active)
+ORDER BY name ASC, id ASC
+`
+
+// READS
+func (q *Queries) ListCategories(ctx context.Context, includeInactive bool) ([]Category, error) {
+	rows, err := q.db.Query(ctx, listCategories, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Category
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDetailedBudgetTransactions = `-- name: ListDetailedBudgetTransactions :many
+SELECT
+    blc.budget_line_id,
+    t.id,
+    t.transaction_date,
+    ROUND(t.amount::NUMERIC, 2) AS amount,
+    t.description,
+    t.notes,
+    c.id AS category_id,
+    c.code AS category_code,
+    c.name AS category_name,
+    u.id AS author_id,
+    u.name AS author_name
+FROM budget b
+JOIN transaction t
+    ON t.deleted_at IS NULL
+   AND t.transaction_date >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+   AND t.transaction_date < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+   AND (
+       (b.household_id IS NOT NULL AND t.household_id = b.household_id)
+       OR
+       (b.user_id IS NOT NULL AND t.author_id = b.user_id AND t.household_id IS NULL)
+   )
+JOIN users u ON u.id = t.author_id
+LEFT JOIN category c ON c.id = t.category_id
+LEFT JOIN budget_line_category blc
+    ON blc.budget_id = b.id
+   AND blc.category_id = t.category_id
+WHERE b.id = $1::BIGINT
+ORDER BY blc.budget_line_id ASC NULLS LAST, t.transaction_date ASC, t.id ASC
+`
+
+type ListDetailedBudgetTransactionsRow struct {
+	BudgetLineID    *int64             `json:"budgetLineId"`
+	ID              int64              `json:"id"`
+	TransactionDate pgtype.Timestamptz `json:"transactionDate"`
+	Amount          pgtype.Numeric     `json:"amount"`
+	Description     *string            `json:"description"`
+	Notes           *string            `json:"notes"`
+	CategoryID      *int64             `json:"categoryId"`
+	CategoryCode    *string            `json:"categoryCode"`
+	CategoryName    *string            `json:"categoryName"`
+	AuthorID        int64              `json:"authorId"`
+	AuthorName      string             `json:"authorName"`
+}
+
+func (q *Queries) ListDetailedBudgetTransactions(ctx context.Context, budgetID int64) ([]ListDetailedBudgetTransactionsRow, error) {
+	rows, err := q.db.Query(ctx, listDetailedBudgetTransactions, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDetailedBudgetTransactionsRow
+	for rows.Next() {
+		var i ListDetailedBudgetTransactionsRow
+		if err := rows.Scan(
+			&i.BudgetLineID,
+			&i.ID,
+			&i.TransactionDate,
+			&i.Amount,
+			&i.Description,
+			&i.Notes,
+			&i.CategoryID,
+			&i.CategoryCode,
+			&i.CategoryName,
+			&i.AuthorID,
+			&i.AuthorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHouseholds = `-- name: ListHouseholds :many
+SELECT id, name, guild_id, created_at, updated_at FROM household
+ORDER BY name ASC, id ASC
+`
+
+func (q *Queries) ListHouseholds(ctx context.Context) ([]Household, error) {
+	rows, err := q.db.Query(ctx, listHouseholds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Household
+	for rows.Next() {
+		var i Household
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.GuildID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLlmMessagesBySessionId = `-- name: ListLlmMessagesBySessionId :many
+SELECT
+    m.id, m.session_id, m.parent_id, m.role, m.contents, m.user_id, m.created_at, m.updated_at, u.id, u.discord_id, u.name, u.created_at, u.updated_at, u.telegram_id, u.phone_number, u.whatsapp_id
+FROM
+    llm_message m
+JOIN
+    users u on u.id = m.user_id
+WHERE
+    m.session_id = $1
+ORDER BY
+    m.created_at ASC
+`
+
+type ListLlmMessagesBySessionIdRow struct {
+	LlmMessage LlmMessage `json:"llmMessage"`
+	User       User       `json:"user"`
+}
+
+func (q *Queries) ListLlmMessagesBySessionId(ctx context.Context, sessionID int64) ([]ListLlmMessagesBySessionIdRow, error) {
+	rows, err := q.db.Query(ctx, listLlmMessagesBySessionId, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLlmMessagesBySessionIdRow
+	for rows.Next() {
+		var i ListLlmMessagesBySessionIdRow
+		if err := rows.Scan(
+			&i.LlmMessage.ID,
+			&i.LlmMessage.SessionID,
+			&i.LlmMessage.ParentID,
+			&i.LlmMessage.Role,
+			&i.LlmMessage.Contents,
+			&i.LlmMessage.UserID,
+			&i.LlmMessage.CreatedAt,
+			&i.LlmMessage.UpdatedAt,
+			&i.User.ID,
+			&i.User.DiscordID,
+			&i.User.Name,
+			&i.User.CreatedAt,
+			&i.User.UpdatedAt,
+			&i.User.TelegramID,
+			&i.User.PhoneNumber,
+			&i.User.WhatsappID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSavedFilters = `-- name: ListSavedFilters :many
+SELECT id, name, query, created_at, updated_at FROM saved_filter
+ORDER BY name
 `
 
-type ListBudgetLineCategoriesRow struct {
-	BudgetID     int64  `json:"budgetId"`
-	BudgetLineID int64  `json:"budgetLineId"`
-	CategoryID   int64  `json:"categoryId"`
-	CategoryCode string `json:"categoryCode"`
-	CategoryName string `json:"categoryName"`
-}
-
-func (q *Queries) ListBudgetLineCategories(ctx context.Context, budgetID int64) ([]ListBudgetLineCategoriesRow, error) {
-	rows, err := q.db.Query(ctx, listBudgetLineCategories, budgetID)
+func (q *Queries) ListSavedFilters(ctx context.Context) ([]SavedFilter, error) {
+	rows, err := q.db.Query(ctx, listSavedFilters)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListBudgetLineCategoriesRow
+	var items []SavedFilter
 	for rows.Next() {
-		var i ListBudgetLineCategoriesRow
+		var i SavedFilter
 		if err := rows.Scan(
-			&i.BudgetID,
-			&i.BudgetLineID,
-			&i.CategoryID,
-			&i.CategoryCode,
-			&i.CategoryName,
+			&i.ID,
+			&i.Name,
+			&i.Query,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1320,29 +3899,39 @@ func (q *Queries) ListBudgetLineCategories(ctx context.Context, budgetID int64)
 	return items, nil
 }
 
-const listBudgetLines = `-- name: ListBudgetLines :many
-SELECT id, budget_id, name, allocation_amount, sort_order, created_at, updated_at FROM budget_line
-WHERE budget_id = $1::BIGINT
-ORDER BY sort_order ASC, id ASC
+const listSavingsGoalCategories = `-- name: ListSavingsGoalCategories :many
+SELECT
+    gc.goal_id,
+    gc.category_id,
+    c.code AS category_code,
+    c.name AS category_name
+FROM savings_goal_category gc
+JOIN category c ON c.id = gc.category_id
+WHERE gc.goal_id = ANY($1::BIGINT[])
+ORDER BY gc.goal_id ASC, c.name ASC, c.id ASC
 `
 
-func (q *Queries) ListBudgetLines(ctx context.Context, budgetID int64) ([]BudgetLine, error) {
-	rows, err := q.db.Query(ctx, listBudgetLines, budgetID)
+type ListSavingsGoalCategoriesRow struct {
+	GoalID       int64  `json:"goalId"`
+	CategoryID   int64  `json:"categoryId"`
+	CategoryCode string `json:"categoryCode"`
+	CategoryName string `json:"categoryName"`
+}
+
+func (q *Queries) ListSavingsGoalCategories(ctx context.Context, goalIds []int64) ([]ListSavingsGoalCategoriesRow, error) {
+	rows, err := q.db.Query(ctx, listSavingsGoalCategories, goalIds)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []BudgetLine
+	var items []ListSavingsGoalCategoriesRow
 	for rows.Next() {
-		var i BudgetLine
+		var i ListSavingsGoalCategoriesRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.BudgetID,
-			&i.Name,
-			&i.AllocationAmount,
-			&i.SortOrder,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.GoalID,
+			&i.CategoryID,
+			&i.CategoryCode,
+			&i.CategoryName,
 		); err != nil {
 			return nil, err
 		}
@@ -1354,59 +3943,96 @@ func (q *Queries) ListBudgetLines(ctx context.Context, budgetID int64) ([]Budget
 	return items, nil
 }
 
-const listBudgetReportLines = `-- name: ListBudgetReportLines :many
+const listSavingsGoalProgress = `-- name: ListSavingsGoalProgress :many
 SELECT
-    bl.id,
-    bl.budget_id,
-    bl.name,
-    bl.allocation_amount,
-    ROUND(COALESCE(SUM(t.amount), 0)::NUMERIC, 2) AS actual_amount,
-    bl.sort_order
-FROM budget b
-JOIN budget_line bl ON bl.budget_id = b.id
-LEFT JOIN budget_line_category blc
-    ON blc.budget_id = b.id
-   AND blc.budget_line_id = bl.id
-LEFT JOIN transaction t
-    ON t.deleted_at IS NULL
-   AND t.category_id = blc.category_id
-   AND t.transaction_date >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
-   AND t.transaction_date < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
-   AND (
-       (b.household_id IS NOT NULL AND t.household_id = b.household_id)
-       OR
-       (b.user_id IS NOT NULL AND t.author_id = b.user_id AND t.household_id IS NULL)
-   )
-WHERE b.id = $1::BIGINT
-GROUP BY bl.id, bl.budget_id, bl.name, bl.allocation_amount, bl.sort_order
-ORDER BY bl.sort_order ASC, bl.id ASC
+    g.id,
+    g.household_id,
+    g.user_id,
+    g.name,
+    g.target_amount,
+    g.start_date,
+    g.target_date,
+    totals.contributed_amount,
+    totals.withdrawn_amount,
+    totals.period_net_amount
+FROM savings_goal g
+CROSS JOIN LATERAL (
+    SELECT
+        ROUND(COALESCE(SUM(t.amount) FILTER (WHERE t.amount > 0), 0)::NUMERIC, 2)::NUMERIC AS contributed_amount,
+        ROUND(COALESCE(-SUM(t.amount) FILTER (WHERE t.amount < 0), 0)::NUMERIC, 2)::NUMERIC AS withdrawn_amount,
+        ROUND(COALESCE(SUM(t.amount) FILTER (
+            WHERE t.transaction_date >= ($1::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+        ), 0)::NUMERIC, 2)::NUMERIC AS period_net_amount
+    FROM transaction t
+    WHERE t.deleted_at IS NULL
+      AND t.category_id IN (
+          SELECT gc.category_id
+          FROM savings_goal_category gc
+          WHERE gc.goal_id = g.id
+      )
+      AND t.transaction_date >= (g.start_date::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+      AND t.transaction_date < (($2::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+      AND (
+          (g.household_id IS NOT NULL AND t.household_id = g.household_id)
+          OR
+          (g.user_id IS NOT NULL AND t.author_id = g.user_id AND t.household_id IS NULL)
+      )
+) totals
+WHERE ($3::BIGINT IS NULL OR g.id = $3::BIGINT)
+  AND ($4::BIGINT IS NULL OR g.household_id = $4::BIGINT)
+  AND ($5::BIGINT IS NULL OR g.user_id = $5::BIGINT)
+ORDER BY g.target_date ASC, g.id ASC
 `
 
-type ListBudgetReportLinesRow struct {
-	ID               int64          `json:"id"`
-	BudgetID         int64          `json:"budgetId"`
-	Name             string         `json:"name"`
-	AllocationAmount pgtype.Numeric `json:"allocationAmount"`
-	ActualAmount     pgtype.Numeric `json:"actualAmount"`
-	SortOrder        int32          `json:"sortOrder"`
+type ListSavingsGoalProgressParams struct {
+	PeriodStart pgtype.Date `json:"periodStart"`
+	AsOf        pgtype.Date `json:"asOf"`
+	ID          *int64      `json:"id"`
+	HouseholdID *int64      `json:"householdId"`
+	UserID      *int64      `json:"userId"`
 }
 
-func (q *Queries) ListBudgetReportLines(ctx context.Context, budgetID int64) ([]ListBudgetReportLinesRow, error) {
-	rows, err := q.db.Query(ctx, listBudgetReportLines, budgetID)
+type ListSavingsGoalProgressRow struct {
+	ID                int64          `json:"id"`
+	HouseholdID       *int64         `json:"householdId"`
+	UserID            *int64         `json:"userId"`
+	Name              string         `json:"name"`
+	TargetAmount      pgtype.Numeric `json:"targetAmount"`
+	StartDate         pgtype.Date    `json:"startDate"`
+	TargetDate        pgtype.Date    `json:"targetDate"`
+	ContributedAmount pgtype.Numeric `json:"contributedAmount"`
+	WithdrawnAmount   pgtype.Numeric `json:"withdrawnAmount"`
+	PeriodNetAmount   pgtype.Numeric `json:"periodNetAmount"`
+}
+
+// Linked transactions follow the budget owner scope. Positive amounts are
+// contributions into the fund and negative amounts are withdrawals from it.
+func (q *Queries) ListSavingsGoalProgress(ctx context.Context, arg ListSavingsGoalProgressParams) ([]ListSavingsGoalProgressRow, error) {
+	rows, err := q.db.Query(ctx, listSavingsGoalProgress,
+		arg.PeriodStart,
+		arg.AsOf,
+		arg.ID,
+		arg.HouseholdID,
+		arg.UserID,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListBudgetReportLinesRow
+	var items []ListSavingsGoalProgressRow
 	for rows.Next() {
-		var i ListBudgetReportLinesRow
+		var i ListSavingsGoalProgressRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.BudgetID,
+			&i.HouseholdID,
+			&i.UserID,
 			&i.Name,
-			&i.AllocationAmount,
-			&i.ActualAmount,
-			&i.SortOrder,
+			&i.TargetAmount,
+			&i.StartDate,
+			&i.TargetDate,
+			&i.ContributedAmount,
+			&i.WithdrawnAmount,
+			&i.PeriodNetAmount,
 		); err != nil {
 			return nil, err
 		}
@@ -1418,31 +4044,30 @@ func (q *Queries) ListBudgetReportLines(ctx context.Context, budgetID int64) ([]
 	return items, nil
 }
 
-const listCategories = `-- name: ListCategories :many
-
-SELECT id, code, name, description, is_active, created_at, updated_at FROM category
-WHERE ($1::bool OR is_active)
-ORDER BY name ASC, id ASC
+const listTransactionAttachments = `-- name: ListTransactionAttachments :many
+SELECT id, transaction_id, file_name, content_type, size_bytes, sha256, storage_key, created_at FROM transaction_attachment
+WHERE transaction_id = $1::BIGINT
+ORDER BY id ASC
 `
 
-// READS
-func (q *Queries) ListCategories(ctx context.Context, includeInactive bool) ([]Category, error) {
-	rows, err := q.db.Query(ctx, listCategories, includeInactive)
+func (q *Queries) ListTransactionAttachments(ctx context.Context, transactionID int64) ([]TransactionAttachment, error) {
+	rows, err := q.db.Query(ctx, listTransactionAttachments, transactionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Category
+	var items []TransactionAttachment
 	for rows.Next() {
-		var i Category
+		var i TransactionAttachment
 		if err := rows.Scan(
 			&i.ID,
-			&i.Code,
-			&i.Name,
-			&i.Description,
-			&i.IsActive,
+			&i.TransactionID,
+			&i.FileName,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Sha256,
+			&i.StorageKey,
 			&i.CreatedAt,
-			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1454,73 +4079,80 @@ func (q *Queries) ListCategories(ctx context.Context, includeInactive bool) ([]C
 	return items, nil
 }
 
-const listDetailedBudgetTransactions = `-- name: ListDetailedBudgetTransactions :many
+const listTransactionChanges = `-- name: ListTransactionChanges :many
 SELECT
-    blc.budget_line_id,
-    t.id,
-    t.transaction_date,
-    ROUND(t.amount::NUMERIC, 2) AS amount,
-    t.description,
-    t.notes,
+    t.id, t.amount, t.author_id, t.description, t.transaction_date, t.transaction_id, t.household_id, t.notes, t.created_at, t.updated_at, t.deleted_at, t.deleted_by_user_id, t.delete_reason, t.category_id, t.change_seq, t.version,
+    u.id AS author_id,
+    u.name AS author_name,
+    h.id AS household_id,
+    h.name AS household_name,
     c.id AS category_id,
     c.code AS category_code,
     c.name AS category_name,
-    u.id AS author_id,
-    u.name AS author_name
-FROM budget b
-JOIN transaction t
-    ON t.deleted_at IS NULL
-   AND t.transaction_date >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
-   AND t.transaction_date < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
-   AND (
-       (b.household_id IS NOT NULL AND t.household_id = b.household_id)
-       OR
-       (b.user_id IS NOT NULL AND t.author_id = b.user_id AND t.household_id IS NULL)
-   )
+    ARRAY(
+        SELECT tg.name FROM transaction_tag tt JOIN tag tg ON tg.id = tt.tag_id
+        WHERE tt.transaction_id = t.id ORDER BY tg.name
+    )::TEXT[] AS tags
+FROM transaction t
 JOIN users u ON u.id = t.author_id
+LEFT JOIN household h ON h.id = t.household_id
 LEFT JOIN category c ON c.id = t.category_id
-LEFT JOIN budget_line_category blc
-    ON blc.budget_id = b.id
-   AND blc.category_id = t.category_id
-WHERE b.id = $1::BIGINT
-ORDER BY blc.budget_line_id ASC NULLS LAST, t.transaction_date ASC, t.id ASC
+WHERE t.change_seq > $1::BIGINT
+ORDER BY t.change_seq
+LIMIT $2::INT
 `
 
-type ListDetailedBudgetTransactionsRow struct {
-	BudgetLineID    *int64             `json:"budgetLineId"`
-	ID              int64              `json:"id"`
-	TransactionDate pgtype.Timestamptz `json:"transactionDate"`
-	Amount          pgtype.Numeric     `json:"amount"`
-	Description     *string            `json:"description"`
-	Notes           *string            `json:"notes"`
-	CategoryID      *int64             `json:"categoryId"`
-	CategoryCode    *string            `json:"categoryCode"`
-	CategoryName    *string            `json:"categoryName"`
-	AuthorID        int64              `json:"authorId"`
-	AuthorName      string             `json:"authorName"`
+type ListTransactionChangesParams struct {
+	Since       int64 `json:"since"`
+	ResultLimit int32 `json:"resultLimit"`
 }
 
-func (q *Queries) ListDetailedBudgetTransactions(ctx context.Context, budgetID int64) ([]ListDetailedBudgetTransactionsRow, error) {
-	rows, err := q.db.Query(ctx, listDetailedBudgetTransactions, budgetID)
+type ListTransactionChangesRow struct {
+	Transaction   Transaction `json:"transaction"`
+	AuthorID      int64       `json:"authorId"`
+	AuthorName    string      `json:"authorName"`
+	HouseholdID   *int64      `json:"householdId"`
+	HouseholdName *string     `json:"householdName"`
+	CategoryID    *int64      `json:"categoryId"`
+	CategoryCode  *string     `json:"categoryCode"`
+	CategoryName  *string     `json:"categoryName"`
+	Tags          []string    `json:"tags"`
+}
+
+func (q *Queries) ListTransactionChanges(ctx context.Context, arg ListTransactionChangesParams) ([]ListTransactionChangesRow, error) {
+	rows, err := q.db.Query(ctx, listTransactionChanges, arg.Since, arg.ResultLimit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListDetailedBudgetTransactionsRow
+	var items []ListTransactionChangesRow
 	for rows.Next() {
-		var i ListDetailedBudgetTransactionsRow
+		var i ListTransactionChangesRow
 		if err := rows.Scan(
-			&i.BudgetLineID,
-			&i.ID,
-			&i.TransactionDate,
-			&i.Amount,
-			&i.Description,
-			&i.Notes,
+			&i.Transaction.ID,
+			&i.Transaction.Amount,
+			&i.Transaction.AuthorID,
+			&i.Transaction.Description,
+			&i.Transaction.TransactionDate,
+			&i.Transaction.TransactionID,
+			&i.Transaction.HouseholdID,
+			&i.Transaction.Notes,
+			&i.Transaction.CreatedAt,
+			&i.Transaction.UpdatedAt,
+			&i.Transaction.DeletedAt,
+			&i.Transaction.DeletedByUserID,
+			&i.Transaction.DeleteReason,
+			&i.Transaction.CategoryID,
+			&i.Transaction.ChangeSeq,
+			&i.Transaction.Version,
+			&i.AuthorID,
+			&i.AuthorName,
+			&i.HouseholdID,
+			&i.HouseholdName,
 			&i.CategoryID,
 			&i.CategoryCode,
 			&i.CategoryName,
-			&i.AuthorID,
-			&i.AuthorName,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -1532,26 +4164,37 @@ func (q *Queries) ListDetailedBudgetTransactions(ctx context.Context, budgetID i
 	return items, nil
 }
 
-const listHouseholds = `-- name: ListHouseholds :many
-SELECT id, name, guild_id, created_at, updated_at FROM household
-ORDER BY name ASC, id ASC
+const listTransactionHistory = `-- name: ListTransactionHistory :many
+SELECT th.id, th.transaction_id, th.action, th.actor_user_id, th.changes, th.changed_at, u.name AS actor_name
+FROM transaction_history th
+LEFT JOIN users u ON u.id = th.actor_user_id
+WHERE th.transaction_id = $1::BIGINT
+ORDER BY th.id
 `
 
-func (q *Queries) ListHouseholds(ctx context.Context) ([]Household, error) {
-	rows, err := q.db.Query(ctx, listHouseholds)
+type ListTransactionHistoryRow struct {
+	TransactionHistory TransactionHistory `json:"transactionHistory"`
+	ActorName          *string            `json:"actorName"`
+}
+
+// Oldest change first.
+func (q *Queries) ListTransactionHistory(ctx context.Context, transactionID int64) ([]ListTransactionHistoryRow, error) {
+	rows, err := q.db.Query(ctx, listTransactionHistory, transactionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Household
+	var items []ListTransactionHistoryRow
 	for rows.Next() {
-		var i Household
+		var i ListTransactionHistoryRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.GuildID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.TransactionHistory.ID,
+			&i.TransactionHistory.TransactionID,
+			&i.TransactionHistory.Action,
+			&i.TransactionHistory.ActorUserID,
+			&i.TransactionHistory.Changes,
+			&i.TransactionHistory.ChangedAt,
+			&i.ActorName,
 		); err != nil {
 			return nil, err
 		}
@@ -1563,54 +4206,86 @@ func (q *Queries) ListHouseholds(ctx context.Context) ([]Household, error) {
 	return items, nil
 }
 
-const listLlmMessagesBySessionId = `-- name: ListLlmMessagesBySessionId :many
+const listTransactionTagTotals = `-- name: ListTransactionTagTotals :many
 SELECT
-    m.id, m.session_id, m.parent_id, m.role, m.contents, m.user_id, m.created_at, m.updated_at, u.id, u.discord_id, u.name, u.created_at, u.updated_at, u.telegram_id, u.phone_number, u.whatsapp_id
-FROM
-    llm_message m
-JOIN
-    users u on u.id = m.user_id
+    tg.name AS tag,
+    COUNT(*)::BIGINT AS transaction_count,
+    ROUND(SUM(t.amount)::NUMERIC, 2) AS total_amount
+FROM tag tg
+JOIN transaction_tag tt ON tt.tag_id = tg.id
+JOIN transaction t ON t.id = tt.transaction_id AND t.deleted_at IS NULL
 WHERE
-    m.session_id = $1
-ORDER BY
-    m.created_at ASC
+    ($1::BIGINT IS NULL OR t.author_id = $1::BIGINT)
+    AND ($2::BIGINT IS NULL OR t.household_id = $2::BIGINT)
+    AND ($3::TIMESTAMPTZ IS NULL OR t.transaction_date >= $3::TIMESTAMPTZ)
+    AND ($4::TIMESTAMPTZ IS NULL OR t.transaction_date <= $4::TIMESTAMPTZ)
+    -- The repository replaces this TRUE with the compiled filter expression.
+    AND /* filter expression */ TRUE
+GROUP BY tg.name
+ORDER BY tg.name
 `
 
-type ListLlmMessagesBySessionIdRow struct {
-	LlmMessage LlmMessage `json:"llmMessage"`
-	User       User       `json:"user"`
+type ListTransactionTagTotalsParams struct {
+	AuthorID    *int64             `json:"authorId"`
+	HouseholdID *int64             `json:"householdId"`
+	FromDate    pgtype.Timestamptz `json:"fromDate"`
+	ToDate      pgtype.Timestamptz `json:"toDate"`
+}
+
+type ListTransactionTagTotalsRow struct {
+	Tag              string         `json:"tag"`
+	TransactionCount int64          `json:"transactionCount"`
+	TotalAmount      pgtype.Numeric `json:"totalAmount"`
+}
+
+// Count and total of the live transactions carrying each tag. A transaction
+// with several tags counts towards each of them.
+func (q *Queries) ListTransactionTagTotals(ctx context.Context, arg ListTransactionTagTotalsParams) ([]ListTransactionTagTotalsRow, error) {
+	rows, err := q.db.Query(ctx, listTransactionTagTotals,
+		arg.AuthorID,
+		arg.HouseholdID,
+		arg.FromDate,
+		arg.ToDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTransactionTagTotalsRow
+	for rows.Next() {
+		var i ListTransactionTagTotalsRow
+		if err := rows.Scan(&i.Tag, &i.TransactionCount, &i.TotalAmount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *Queries) ListLlmMessagesBySessionId(ctx context.Context, sessionID int64) ([]ListLlmMessagesBySessionIdRow, error) {
-	rows, err := q.db.Query(ctx, listLlmMessagesBySessionId, sessionID)
+const listTransactionTags = `-- name: ListTransactionTags :many
+SELECT tg.name
+FROM transaction_tag tt
+JOIN tag tg ON tg.id = tt.tag_id
+WHERE tt.transaction_id = $1::BIGINT
+ORDER BY tg.name
+`
+
+func (q *Queries) ListTransactionTags(ctx context.Context, transactionID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, listTransactionTags, transactionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListLlmMessagesBySessionIdRow
+	var items []string
 	for rows.Next() {
-		var i ListLlmMessagesBySessionIdRow
-		if err := rows.Scan(
-			&i.LlmMessage.ID,
-			&i.LlmMessage.SessionID,
-			&i.LlmMessage.ParentID,
-			&i.LlmMessage.Role,
-			&i.LlmMessage.Contents,
-			&i.LlmMessage.UserID,
-			&i.LlmMessage.CreatedAt,
-			&i.LlmMessage.UpdatedAt,
-			&i.User.ID,
-			&i.User.DiscordID,
-			&i.User.Name,
-			&i.User.CreatedAt,
-			&i.User.UpdatedAt,
-			&i.User.TelegramID,
-			&i.User.PhoneNumber,
-			&i.User.WhatsappID,
-		); err != nil {
+		var name string
+		if err := rows.Scan(&name); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, name)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -1620,55 +4295,106 @@ func (q *Queries) ListLlmMessagesBySessionId(ctx context.Context, sessionID int6
 
 const listTransactions = `-- name: ListTransactions :many
 SELECT
-    t.id, t.amount, t.author_id, t.description, t.transaction_date, t.transaction_id, t.household_id, t.notes, t.created_at, t.updated_at, t.deleted_at, t.deleted_by_user_id, t.delete_reason, t.category_id,
+    t.id, t.amount, t.author_id, t.description, t.transaction_date, t.transaction_id, t.household_id, t.notes, t.created_at, t.updated_at, t.deleted_at, t.deleted_by_user_id, t.delete_reason, t.category_id, t.change_seq, t.version,
     u.id AS author_id,
     u.name AS author_name,
     h.id AS household_id,
     h.name AS household_name,
     c.id AS category_id,
     c.code AS category_code,
-    c.name AS category_name
+    c.name AS category_name,
+    ARRAY(
+        SELECT tg.name FROM transaction_tag tt JOIN tag tg ON tg.id = tt.tag_id
+        WHERE tt.transaction_id = t.id ORDER BY tg.name
+    )::TEXT[] AS tags,
+    r.relevance
 FROM transaction t
 JOIN users u ON u.id = t.author_id
 LEFT JOIN household h ON h.id = t.household_id
 LEFT JOIN category c ON c.id = t.category_id
+CROSS JOIN LATERAL (
+    -- Relevance to the search: the full-text rank of the description and
+    -- notes plus how closely the search resembles a run of their words, or 0
+    -- without a search. The document expression matches the search indexes.
+    SELECT (CASE WHEN $1::TEXT IS NULL THEN 0 ELSE
+        ts_rank(to_tsvector('simple', COALESCE(t.description, '') || ' ' || COALESCE(t.notes, '')), websearch_to_tsquery('simple', $1::TEXT))
+        + public.word_similarity($1::TEXT, COALESCE(t.description, '') || ' ' || COALESCE(t.notes, ''))
+    END)::FLOAT8 AS relevance
+) r
 WHERE
-    (NOT $1::bool OR t.deleted_at IS NOT NULL)
-    AND ($2::bool OR $1::bool OR t.deleted_at IS NULL)
-    AND ($3::BIGINT IS NULL OR t.author_id = $3::BIGINT)
-    AND ($4::BIGINT IS NULL OR t.household_id = $4::BIGINT)
-    AND ($5::TIMESTAMPTZ IS NULL OR t.transaction_date >= $5::TIMESTAMPTZ)
-    AND ($6::TIMESTAMPTZ IS NULL OR t.transaction_date <= $6::TIMESTAMPTZ)
+    (NOT $2::bool OR t.deleted_at IS NOT NULL)
+    AND ($3::bool OR $2::bool OR t.deleted_at IS NULL)
+    AND ($4::BIGINT IS NULL OR t.author_id = $4::BIGINT)
+    AND ($5::BIGINT IS NULL OR t.household_id = $5::BIGINT)
+    AND ($6::TIMESTAMPTZ IS NULL OR t.transaction_date >= $6::TIMESTAMPTZ)
+    AND ($7::TIMESTAMPTZ IS NULL OR t.transaction_date <= $7::TIMESTAMPTZ)
+    -- Search: matching words in any order, a close spelling of them, or the
+    -- exact text anywhere in the description or notes.
+    AND (
+        $1::TEXT IS NULL
+        OR to_tsvector('simple', COALESCE(t.description, '') || ' ' || COALESCE(t.notes, '')) @@ websearch_to_tsquery('simple', $1::TEXT)
+        OR $1::TEXT OPERATOR(public.<%) (COALESCE(t.description, '') || ' ' || COALESCE(t.notes, ''))
+        OR t.description ILIKE '%' || $1::TEXT || '%'
+        OR t.notes ILIKE '%' || $1::TEXT || '%'
+    )
+    -- Tag filter: any one of the tags, or all of them when tag_match is 'all'.
+    AND (
+        cardinality($8::TEXT[]) = 0
+        OR (
+            SELECT COUNT(*)
+            FROM transaction_tag tt
+            JOIN tag tg ON tg.id = tt.tag_id
+            WHERE tt.transaction_id = t.id AND tg.name = ANY($8::TEXT[])
+        ) >= CASE WHEN $9::TEXT = 'all' THEN cardinality($8::TEXT[]) ELSE 1 END
+    )
+    -- The repository replaces this TRUE with the compiled filter expression.
+    AND /* filter expression */ TRUE
+    -- Keyset cursor: rows strictly after (sort value, id) in the listing order.
+    -- created_at falls back to transaction_date for rows that predate it.
     AND (
-        $7::TEXT IS NULL
-        OR t.description ILIKE '%' || $7::TEXT || '%'
-        OR t.notes ILIKE '%' || $7::TEXT || '%'
+        $10::BIGINT IS NULL
+        OR ($11::TEXT = 'transaction_date' AND $12::TEXT = 'asc' AND (t.transaction_date, t.id) > ($13::TIMESTAMPTZ, $10::BIGINT))
+        OR ($11::TEXT = 'transaction_date' AND $12::TEXT = 'desc' AND (t.transaction_date, t.id) < ($13::TIMESTAMPTZ, $10::BIGINT))
+        OR ($11::TEXT = 'created_at' AND $12::TEXT = 'asc' AND (COALESCE(t.created_at, t.transaction_date), t.id) > ($13::TIMESTAMPTZ, $10::BIGINT))
+        OR ($11::TEXT = 'created_at' AND $12::TEXT = 'desc' AND (COALESCE(t.created_at, t.transaction_date), t.id) < ($13::TIMESTAMPTZ, $10::BIGINT))
+        OR ($11::TEXT = 'amount' AND $12::TEXT = 'asc' AND (t.amount, t.id) > ($14::REAL, $10::BIGINT))
+        OR ($11::TEXT = 'amount' AND $12::TEXT = 'desc' AND (t.amount, t.id) < ($14::REAL, $10::BIGINT))
+        OR ($11::TEXT = 'relevance' AND $12::TEXT = 'asc' AND (r.relevance, t.id) > ($15::FLOAT8, $10::BIGINT))
+        OR ($11::TEXT = 'relevance' AND $12::TEXT = 'desc' AND (r.relevance, t.id) < ($15::FLOAT8, $10::BIGINT))
+        OR ($11::TEXT = 'id' AND $12::TEXT = 'asc' AND t.id > $10::BIGINT)
+        OR ($11::TEXT = 'id' AND $12::TEXT = 'desc' AND t.id < $10::BIGINT)
     )
 ORDER BY
-    CASE WHEN $8::TEXT = 'transaction_date' AND $9::TEXT = 'asc' THEN t.transaction_date END ASC,
-    CASE WHEN $8::TEXT = 'transaction_date' AND $9::TEXT = 'desc' THEN t.transaction_date END DESC,
-    CASE WHEN $8::TEXT = 'created_at' AND $9::TEXT = 'asc' THEN t.created_at END ASC,
-    CASE WHEN $8::TEXT = 'created_at' AND $9::TEXT = 'desc' THEN t.created_at END DESC,
-    CASE WHEN $8::TEXT = 'amount' AND $9::TEXT = 'asc' THEN t.amount END ASC,
-    CASE WHEN $8::TEXT = 'amount' AND $9::TEXT = 'desc' THEN t.amount END DESC,
-    CASE WHEN $8::TEXT = 'id' AND $9::TEXT = 'asc' THEN t.id END ASC,
-    CASE WHEN $8::TEXT = 'id' AND $9::TEXT = 'desc' THEN t.id END DESC,
-    t.transaction_date DESC,
+    CASE WHEN $11::TEXT = 'transaction_date' AND $12::TEXT = 'asc' THEN t.transaction_date END ASC,
+    CASE WHEN $11::TEXT = 'transaction_date' AND $12::TEXT = 'desc' THEN t.transaction_date END DESC,
+    CASE WHEN $11::TEXT = 'created_at' AND $12::TEXT = 'asc' THEN COALESCE(t.created_at, t.transaction_date) END ASC,
+    CASE WHEN $11::TEXT = 'created_at' AND $12::TEXT = 'desc' THEN COALESCE(t.created_at, t.transaction_date) END DESC,
+    CASE WHEN $11::TEXT = 'amount' AND $12::TEXT = 'asc' THEN t.amount END ASC,
+    CASE WHEN $11::TEXT = 'amount' AND $12::TEXT = 'desc' THEN t.amount END DESC,
+    CASE WHEN $11::TEXT = 'relevance' AND $12::TEXT = 'asc' THEN r.relevance END ASC,
+    CASE WHEN $11::TEXT = 'relevance' AND $12::TEXT = 'desc' THEN r.relevance END DESC,
+    CASE WHEN $12::TEXT = 'asc' THEN t.id END ASC,
     t.id DESC
-LIMIT $11::INT
-OFFSET $10::INT
+LIMIT $17::INT
+OFFSET $16::INT
 `
 
 type ListTransactionsParams struct {
+	Search         *string            `json:"search"`
 	OnlyDeleted    bool               `json:"onlyDeleted"`
 	IncludeDeleted bool               `json:"includeDeleted"`
 	AuthorID       *int64             `json:"authorId"`
 	HouseholdID    *int64             `json:"householdId"`
 	FromDate       pgtype.Timestamptz `json:"fromDate"`
 	ToDate         pgtype.Timestamptz `json:"toDate"`
-	Search         *string            `json:"search"`
+	Tags           []string           `json:"tags"`
+	TagMatch       string             `json:"tagMatch"`
+	AfterID        *int64             `json:"afterId"`
 	Sort           string             `json:"sort"`
 	SortOrder      string             `json:"sortOrder"`
+	AfterTime      pgtype.Timestamptz `json:"afterTime"`
+	AfterAmount    *float32           `json:"afterAmount"`
+	AfterRelevance *float64           `json:"afterRelevance"`
 	ResultOffset   int32              `json:"resultOffset"`
 	ResultLimit    int32              `json:"resultLimit"`
 }
@@ -1682,19 +4408,27 @@ type ListTransactionsRow struct {
 	CategoryID    *int64      `json:"categoryId"`
 	CategoryCode  *string     `json:"categoryCode"`
 	CategoryName  *string     `json:"categoryName"`
+	Tags          []string    `json:"tags"`
+	Relevance     float64     `json:"relevance"`
 }
 
 func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]ListTransactionsRow, error) {
 	rows, err := q.db.Query(ctx, listTransactions,
+		arg.Search,
 		arg.OnlyDeleted,
 		arg.IncludeDeleted,
 		arg.AuthorID,
 		arg.HouseholdID,
 		arg.FromDate,
 		arg.ToDate,
-		arg.Search,
+		arg.Tags,
+		arg.TagMatch,
+		arg.AfterID,
 		arg.Sort,
 		arg.SortOrder,
+		arg.AfterTime,
+		arg.AfterAmount,
+		arg.AfterRelevance,
 		arg.ResultOffset,
 		arg.ResultLimit,
 	)
@@ -1720,6 +4454,8 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 			&i.Transaction.DeletedByUserID,
 			&i.Transaction.DeleteReason,
 			&i.Transaction.CategoryID,
+			&i.Transaction.ChangeSeq,
+			&i.Transaction.Version,
 			&i.AuthorID,
 			&i.AuthorName,
 			&i.HouseholdID,
@@ -1727,6 +4463,8 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 			&i.CategoryID,
 			&i.CategoryCode,
 			&i.CategoryName,
+			&i.Tags,
+			&i.Relevance,
 		); err != nil {
 			return nil, err
 		}
@@ -1739,7 +4477,7 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 }
 
 const listTransactionsByHousehold = `-- name: ListTransactionsByHousehold :many
-SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id FROM transaction
+SELECT id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id, change_seq, version FROM transaction
 WHERE transaction_type=2 AND household_id = $1
 `
 
@@ -1767,6 +4505,8 @@ func (q *Queries) ListTransactionsByHousehold(ctx context.Context, householdID *
 			&i.DeletedByUserID,
 			&i.DeleteReason,
 			&i.CategoryID,
+			&i.ChangeSeq,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -1880,6 +4620,132 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT
+    d.id, d.subscription_id, d.outbox_id, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at, d.created_at, d.updated_at,
+    o.event_type,
+    o.occurred_at
+FROM webhook_delivery d
+JOIN webhook_outbox o ON o.id = d.outbox_id
+WHERE ($1::BIGINT IS NULL OR d.id = $1::BIGINT)
+  AND ($2::BIGINT IS NULL OR d.subscription_id = $2::BIGINT)
+ORDER BY d.id DESC
+LIMIT $3::INTEGER
+`
+
+type ListWebhookDeliveriesParams struct {
+	ID             *int64 `json:"id"`
+	SubscriptionID *int64 `json:"subscriptionId"`
+	RowLimit       int32  `json:"rowLimit"`
+}
+
+type ListWebhookDeliveriesRow struct {
+	WebhookDelivery WebhookDelivery    `json:"webhookDelivery"`
+	EventType       string             `json:"eventType"`
+	OccurredAt      pgtype.Timestamptz `json:"occurredAt"`
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]ListWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveries, arg.ID, arg.SubscriptionID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWebhookDeliveriesRow
+	for rows.Next() {
+		var i ListWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.WebhookDelivery.ID,
+			&i.WebhookDelivery.SubscriptionID,
+			&i.WebhookDelivery.OutboxID,
+			&i.WebhookDelivery.Status,
+			&i.WebhookDelivery.Attempts,
+			&i.WebhookDelivery.NextAttemptAt,
+			&i.WebhookDelivery.LastError,
+			&i.WebhookDelivery.DeliveredAt,
+			&i.WebhookDelivery.CreatedAt,
+			&i.WebhookDelivery.UpdatedAt,
+			&i.EventType,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveryAttempts = `-- name: ListWebhookDeliveryAttempts :many
+SELECT id, delivery_id, attempted_at, status_code, error, duration_ms FROM webhook_delivery_attempt
+WHERE delivery_id = ANY($1::BIGINT[])
+ORDER BY delivery_id ASC, attempted_at ASC, id ASC
+`
+
+func (q *Queries) ListWebhookDeliveryAttempts(ctx context.Context, deliveryIds []int64) ([]WebhookDeliveryAttempt, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveryAttempts, deliveryIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDeliveryAttempt
+	for rows.Next() {
+		var i WebhookDeliveryAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeliveryID,
+			&i.AttemptedAt,
+			&i.StatusCode,
+			&i.Error,
+			&i.DurationMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
+SELECT id, url, event_types, household_id, secret, is_active, created_at, updated_at FROM webhook_subscription
+WHERE $1::BIGINT IS NULL OR household_id = $1::BIGINT
+ORDER BY id ASC
+`
+
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context, householdID *int64) ([]WebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listWebhookSubscriptions, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.EventTypes,
+			&i.HouseholdID,
+			&i.Secret,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const lockBudgetForUpdate = `-- name: LockBudgetForUpdate :one
 SELECT id FROM budget
 WHERE id = $1::BIGINT
@@ -1893,6 +4759,110 @@ func (q *Queries) LockBudgetForUpdate(ctx context.Context, id int64) (int64, err
 	return id_2, err
 }
 
+const lockBudgetsForTransaction = `-- name: LockBudgetsForTransaction :exec
+SELECT b.id
+FROM budget b
+WHERE $1::TIMESTAMPTZ >= (b.period_start::DATE::TIMESTAMP AT TIME ZONE 'UTC')
+  AND $1::TIMESTAMPTZ < ((b.period_end::DATE + INTERVAL '1 day')::TIMESTAMP AT TIME ZONE 'UTC')
+  AND (
+      (b.household_id IS NOT NULL AND b.household_id = $2::BIGINT)
+      OR
+      (b.user_id IS NOT NULL AND b.user_id = $3::BIGINT AND $2::BIGINT IS NULL)
+  )
+ORDER BY b.id
+FOR SHARE
+`
+
+type LockBudgetsForTransactionParams struct {
+	TransactionDate pgtype.Timestamptz `json:"transactionDate"`
+	HouseholdID     *int64             `json:"householdId"`
+	AuthorID        int64              `json:"authorId"`
+}
+
+// Share-locks every budget whose period and owner cover a transaction with
+// the given date, household and author, so closing one of them waits for the
+// transaction write to commit. Run GetClosedBudgetForTransaction afterwards in
+// its own statement so it sees a closing that committed during the wait.
+func (q *Queries) LockBudgetsForTransaction(ctx context.Context, arg LockBudgetsForTransactionParams) error {
+	_, err := q.db.Exec(ctx, lockBudgetsForTransaction, arg.TransactionDate, arg.HouseholdID, arg.AuthorID)
+	return err
+}
+
+const markBudgetAlertNotified = `-- name: MarkBudgetAlertNotified :exec
+UPDATE budget_alert
+SET
+    notified_at = CURRENT_TIMESTAMP,
+    notification_error = $1::VARCHAR
+WHERE id = $2::BIGINT
+`
+
+type MarkBudgetAlertNotifiedParams struct {
+	NotificationError *string `json:"notificationError"`
+	ID                int64   `json:"id"`
+}
+
+func (q *Queries) MarkBudgetAlertNotified(ctx context.Context, arg MarkBudgetAlertNotifiedParams) error {
+	_, err := q.db.Exec(ctx, markBudgetAlertNotified, arg.NotificationError, arg.ID)
+	return err
+}
+
+const redeliverWebhookDelivery = `-- name: RedeliverWebhookDelivery :one
+UPDATE webhook_delivery
+SET
+    status = 'pending',
+    attempts = 0,
+    next_attempt_at = CURRENT_TIMESTAMP,
+    last_error = NULL,
+    delivered_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1::BIGINT
+RETURNING id
+`
+
+// Queues the delivery to be sent again straight away with a fresh retry
+// budget. Earlier attempts stay in its history.
+func (q *Queries) RedeliverWebhookDelivery(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, redeliverWebhookDelivery, id)
+	var id_2 int64
+	err := row.Scan(&id_2)
+	return id_2, err
+}
+
+const releaseIdempotencyKey = `-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_key
+WHERE key = $1::VARCHAR
+  AND status_code IS NULL
+`
+
+func (q *Queries) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	_, err := q.db.Exec(ctx, releaseIdempotencyKey, key)
+	return err
+}
+
+const reopenBudgetClosing = `-- name: ReopenBudgetClosing :one
+UPDATE budget_closing
+SET
+    reopened_by_user_id = $1::BIGINT,
+    reopened_at = CURRENT_TIMESTAMP,
+    reopen_reason = $2::VARCHAR
+WHERE budget_id = $3::BIGINT
+  AND reopened_at IS NULL
+RETURNING id
+`
+
+type ReopenBudgetClosingParams struct {
+	ReopenedByUserID int64  `json:"reopenedByUserId"`
+	ReopenReason     string `json:"reopenReason"`
+	BudgetID         int64  `json:"budgetId"`
+}
+
+func (q *Queries) ReopenBudgetClosing(ctx context.Context, arg ReopenBudgetClosingParams) (int64, error) {
+	row := q.db.QueryRow(ctx, reopenBudgetClosing, arg.ReopenedByUserID, arg.ReopenReason, arg.BudgetID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const restoreTransactionsById = `-- name: RestoreTransactionsById :many
 UPDATE transaction
 SET
@@ -1902,7 +4872,7 @@ SET
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ANY($1::BIGINT[])
   AND deleted_at IS NOT NULL
-RETURNING id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id
+RETURNING id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id, change_seq, version
 `
 
 func (q *Queries) RestoreTransactionsById(ctx context.Context, ids []int64) ([]Transaction, error) {
@@ -1929,6 +4899,8 @@ func (q *Queries) RestoreTransactionsById(ctx context.Context, ids []int64) ([]T
 			&i.DeletedByUserID,
 			&i.DeleteReason,
 			&i.CategoryID,
+			&i.ChangeSeq,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -1949,7 +4921,7 @@ SET
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ANY($3::BIGINT[])
   AND deleted_at IS NULL
-RETURNING id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id
+RETURNING id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id, change_seq, version
 `
 
 type SoftDeleteTransactionsByIdParams struct {
@@ -1982,6 +4954,8 @@ func (q *Queries) SoftDeleteTransactionsById(ctx context.Context, arg SoftDelete
 			&i.DeletedByUserID,
 			&i.DeleteReason,
 			&i.CategoryID,
+			&i.ChangeSeq,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -2034,7 +5008,8 @@ SET
     END,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $7::BIGINT
-RETURNING id, budget_id, name, allocation_amount, sort_order, created_at, updated_at
+  AND ($8::BIGINT IS NULL OR version = $8::BIGINT)
+RETURNING id, budget_id, name, allocation_amount, sort_order, created_at, updated_at, version
 `
 
 type UpdateBudgetLineParams struct {
@@ -2045,6 +5020,7 @@ type UpdateBudgetLineParams struct {
 	SetSortOrder        bool           `json:"setSortOrder"`
 	SortOrder           int32          `json:"sortOrder"`
 	ID                  int64          `json:"id"`
+	ExpectedVersion     *int64         `json:"expectedVersion"`
 }
 
 func (q *Queries) UpdateBudgetLine(ctx context.Context, arg UpdateBudgetLineParams) (BudgetLine, error) {
@@ -2056,6 +5032,7 @@ func (q *Queries) UpdateBudgetLine(ctx context.Context, arg UpdateBudgetLinePara
 		arg.SetSortOrder,
 		arg.SortOrder,
 		arg.ID,
+		arg.ExpectedVersion,
 	)
 	var i BudgetLine
 	err := row.Scan(
@@ -2066,6 +5043,39 @@ func (q *Queries) UpdateBudgetLine(ctx context.Context, arg UpdateBudgetLinePara
 		&i.SortOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateBudgetTemplate = `-- name: UpdateBudgetTemplate :one
+UPDATE budget_template
+SET
+    name = CASE
+        WHEN $1::bool THEN $2::VARCHAR
+        ELSE name
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $3::BIGINT
+RETURNING id, household_id, user_id, name, created_at, updated_at
+`
+
+type UpdateBudgetTemplateParams struct {
+	SetName bool   `json:"setName"`
+	Name    string `json:"name"`
+	ID      int64  `json:"id"`
+}
+
+func (q *Queries) UpdateBudgetTemplate(ctx context.Context, arg UpdateBudgetTemplateParams) (BudgetTemplate, error) {
+	row := q.db.QueryRow(ctx, updateBudgetTemplate, arg.SetName, arg.Name, arg.ID)
+	var i BudgetTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -2084,15 +5094,17 @@ SET
     END,
     updated_at = CURRENT_TIMESTAMP
 WHERE code = $5::VARCHAR
-RETURNING id, code, name, description, is_active, created_at, updated_at
+  AND ($6::BIGINT IS NULL OR version = $6::BIGINT)
+RETURNING id, code, name, description, is_active, created_at, updated_at, version
 `
 
 type UpdateCategoryParams struct {
-	SetName        bool    `json:"setName"`
-	Name           string  `json:"name"`
-	SetDescription bool    `json:"setDescription"`
-	Description    *string `json:"description"`
-	Code           string  `json:"code"`
+	SetName         bool    `json:"setName"`
+	Name            string  `json:"name"`
+	SetDescription  bool    `json:"setDescription"`
+	Description     *string `json:"description"`
+	Code            string  `json:"code"`
+	ExpectedVersion *int64  `json:"expectedVersion"`
 }
 
 // WRITES
@@ -2103,6 +5115,7 @@ func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams)
 		arg.SetDescription,
 		arg.Description,
 		arg.Code,
+		arg.ExpectedVersion,
 	)
 	var i Category
 	err := row.Scan(
@@ -2113,6 +5126,7 @@ func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams)
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -2131,6 +5145,69 @@ func (q *Queries) UpdateMessageContents(ctx context.Context, arg UpdateMessageCo
 	return err
 }
 
+const updateSavingsGoal = `-- name: UpdateSavingsGoal :one
+UPDATE savings_goal
+SET
+    name = CASE
+        WHEN $1::bool THEN $2::VARCHAR
+        ELSE name
+    END,
+    target_amount = CASE
+        WHEN $3::bool THEN $4::NUMERIC
+        ELSE target_amount
+    END,
+    start_date = CASE
+        WHEN $5::bool THEN $6::DATE
+        ELSE start_date
+    END,
+    target_date = CASE
+        WHEN $7::bool THEN $8::DATE
+        ELSE target_date
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $9::BIGINT
+RETURNING id, household_id, user_id, name, target_amount, start_date, target_date, created_at, updated_at
+`
+
+type UpdateSavingsGoalParams struct {
+	SetName         bool           `json:"setName"`
+	Name            string         `json:"name"`
+	SetTargetAmount bool           `json:"setTargetAmount"`
+	TargetAmount    pgtype.Numeric `json:"targetAmount"`
+	SetStartDate    bool           `json:"setStartDate"`
+	StartDate       pgtype.Date    `json:"startDate"`
+	SetTargetDate   bool           `json:"setTargetDate"`
+	TargetDate      pgtype.Date    `json:"targetDate"`
+	ID              int64          `json:"id"`
+}
+
+func (q *Queries) UpdateSavingsGoal(ctx context.Context, arg UpdateSavingsGoalParams) (SavingsGoal, error) {
+	row := q.db.QueryRow(ctx, updateSavingsGoal,
+		arg.SetName,
+		arg.Name,
+		arg.SetTargetAmount,
+		arg.TargetAmount,
+		arg.SetStartDate,
+		arg.StartDate,
+		arg.SetTargetDate,
+		arg.TargetDate,
+		arg.ID,
+	)
+	var i SavingsGoal
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.Name,
+		&i.TargetAmount,
+		&i.StartDate,
+		&i.TargetDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateTransactionById = `-- name: UpdateTransactionById :one
 UPDATE
     transaction
@@ -2165,7 +5242,7 @@ SET
     END,
     transaction_id = $2
 WHERE
-    id = $1 RETURNING id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id
+    id = $1 RETURNING id, amount, author_id, description, transaction_date, transaction_id, household_id, notes, created_at, updated_at, deleted_at, deleted_by_user_id, delete_reason, category_id, change_seq, version
 `
 
 type UpdateTransactionByIdParams struct {
@@ -2222,6 +5299,8 @@ func (q *Queries) UpdateTransactionById(ctx context.Context, arg UpdateTransacti
 		&i.DeletedByUserID,
 		&i.DeleteReason,
 		&i.CategoryID,
+		&i.ChangeSeq,
+		&i.Version,
 	)
 	return i, err
 }
@@ -2295,3 +5374,133 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 	)
 	return i, err
 }
+
+const updateWebhookDeliveryResult = `-- name: UpdateWebhookDeliveryResult :exec
+UPDATE webhook_delivery
+SET
+    status = $1::VARCHAR,
+    attempts = attempts + 1,
+    next_attempt_at = $2::TIMESTAMPTZ,
+    last_error = $3::VARCHAR,
+    delivered_at = CASE
+        WHEN $1::VARCHAR = 'succeeded' THEN $4::TIMESTAMPTZ
+        ELSE delivered_at
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $5::BIGINT
+`
+
+type UpdateWebhookDeliveryResultParams struct {
+	Status        string             `json:"status"`
+	NextAttemptAt pgtype.Timestamptz `json:"nextAttemptAt"`
+	LastError     *string            `json:"lastError"`
+	AttemptedAt   pgtype.Timestamptz `json:"attemptedAt"`
+	ID            int64              `json:"id"`
+}
+
+func (q *Queries) UpdateWebhookDeliveryResult(ctx context.Context, arg UpdateWebhookDeliveryResultParams) error {
+	_, err := q.db.Exec(ctx, updateWebhookDeliveryResult,
+		arg.Status,
+		arg.NextAttemptAt,
+		arg.LastError,
+		arg.AttemptedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const updateWebhookSubscription = `-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscription
+SET
+    url = CASE
+        WHEN $1::bool THEN $2::VARCHAR
+        ELSE url
+    END,
+    event_types = CASE
+        WHEN $3::bool THEN $4::TEXT[]
+        ELSE event_types
+    END,
+    household_id = CASE
+        WHEN $5::bool THEN $6::BIGINT
+        ELSE household_id
+    END,
+    secret = CASE
+        WHEN $7::bool THEN $8::VARCHAR
+        ELSE secret
+    END,
+    is_active = CASE
+        WHEN $9::bool THEN $10::bool
+        ELSE is_active
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $11::BIGINT
+RETURNING id, url, event_types, household_id, secret, is_active, created_at, updated_at
+`
+
+type UpdateWebhookSubscriptionParams struct {
+	SetUrl         bool     `json:"setUrl"`
+	Url            string   `json:"url"`
+	SetEventTypes  bool     `json:"setEventTypes"`
+	EventTypes     []string `json:"eventTypes"`
+	SetHouseholdID bool     `json:"setHouseholdId"`
+	HouseholdID    *int64   `json:"householdId"`
+	SetSecret      bool     `json:"setSecret"`
+	Secret         string   `json:"secret"`
+	SetIsActive    bool     `json:"setIsActive"`
+	IsActive       bool     `json:"isActive"`
+	ID             int64    `json:"id"`
+}
+
+func (q *Queries) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, updateWebhookSubscription,
+		arg.SetUrl,
+		arg.Url,
+		arg.SetEventTypes,
+		arg.EventTypes,
+		arg.SetHouseholdID,
+		arg.HouseholdID,
+		arg.SetSecret,
+		arg.Secret,
+		arg.SetIsActive,
+		arg.IsActive,
+		arg.ID,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.EventTypes,
+		&i.HouseholdID,
+		&i.Secret,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertSavedFilter = `-- name: UpsertSavedFilter :one
+INSERT INTO saved_filter (name, query)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE
+SET query = EXCLUDED.query, updated_at = CURRENT_TIMESTAMP
+RETURNING id, name, query, created_at, updated_at
+`
+
+type UpsertSavedFilterParams struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+func (q *Queries) UpsertSavedFilter(ctx context.Context, arg UpsertSavedFilterParams) (SavedFilter, error) {
+	row := q.db.QueryRow(ctx, upsertSavedFilter, arg.Name, arg.Query)
+	var i SavedFilter
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Query,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
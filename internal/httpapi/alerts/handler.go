@@ -0,0 +1,79 @@
+package alerts
+
+import (
+	"context"
+	"net/http"
+
+	"rdmm404/voltr-finance/internal/api"
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+type Service interface {
+	List(context.Context, appalerts.ListFilter) ([]appalerts.Alert, error)
+}
+
+type Handler struct {
+	service Service
+	support *httpapi.HandlerSupport
+}
+
+func New(service Service, support ...*httpapi.HandlerSupport) *Handler {
+	return &Handler{service: service, support: httpapi.HandlerSupportOrDefault(support...)}
+}
+
+func (h *Handler) Register(router *httpapi.Router) {
+	router.HandleFunc(http.MethodGet, api.AlertsPath, h.list)
+}
+
+func (h *Handler) list(w http.ResponseWriter, request *http.Request) {
+	query, err := alertQuery(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	items, err := h.service.List(request.Context(), appalerts.ListFilter{
+		Owner:    appalerts.Owner{HouseholdID: query.HouseholdID, UserID: query.UserID},
+		BudgetID: query.BudgetID, Limit: int32(query.Limit),
+	})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := make([]api.BudgetAlert, 0, len(items))
+	for _, item := range items {
+		response = append(response, alert(item))
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+func alertQuery(request *http.Request) (api.AlertQuery, error) {
+	householdID, err := httpapi.QueryInt64(request, "householdId")
+	if err != nil {
+		return api.AlertQuery{}, err
+	}
+	userID, err := httpapi.QueryInt64(request, "userId")
+	if err != nil {
+		return api.AlertQuery{}, err
+	}
+	budgetID, err := httpapi.QueryInt64(request, "budgetId")
+	if err != nil {
+		return api.AlertQuery{}, err
+	}
+	limit, err := httpapi.QueryInt(request, "limit", 0)
+	if err != nil {
+		return api.AlertQuery{}, err
+	}
+	return api.AlertQuery{HouseholdID: householdID, UserID: userID, BudgetID: budgetID, Limit: limit}, nil
+}
+
+func alert(item appalerts.Alert) api.BudgetAlert {
+	return api.BudgetAlert{
+		ID: item.ID, BudgetID: item.BudgetID, BudgetLineID: item.LineID, BudgetLineName: item.LineName,
+		HouseholdID: item.Owner.HouseholdID, UserID: item.Owner.UserID,
+		PeriodKind: item.PeriodKind, PeriodStart: item.PeriodStart, PeriodEnd: item.PeriodEnd,
+		ThresholdPercent: item.ThresholdPercent, AllocationAmount: item.AllocationAmount, ActualAmount: item.ActualAmount,
+		TransactionID: item.TransactionID, CreatedAt: item.CreatedAt,
+		NotifiedAt: item.NotifiedAt, NotificationError: item.NotificationError,
+	}
+}
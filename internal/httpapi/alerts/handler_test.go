@@ -0,0 +1,56 @@
+package alerts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+type alertServiceStub struct{ filter appalerts.ListFilter }
+
+func (s *alertServiceStub) List(_ context.Context, filter appalerts.ListFilter) ([]appalerts.Alert, error) {
+	s.filter = filter
+	if filter.Owner.HouseholdID != nil && filter.Owner.UserID != nil {
+		return nil, apperrors.Validation("at most one alert owner filter is allowed")
+	}
+	householdID := int64(2)
+	return []appalerts.Alert{{
+		ID: 9, BudgetID: 1, LineID: 10, LineName: "Groceries", Owner: appalerts.Owner{HouseholdID: &householdID},
+		PeriodKind: "monthly", PeriodStart: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+		ThresholdPercent: 80, AllocationAmount: "500.00", ActualAmount: "412.50",
+	}}, nil
+}
+
+func TestAlertRoutesParseFiltersAndMapAlerts(t *testing.T) {
+	service := &alertServiceStub{}
+	router := httpapi.NewRouter()
+	New(service).Register(router)
+	tests := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{"/v1/alerts?householdId=2&budgetId=1&limit=20", http.StatusOK, `"budgetLineName":"Groceries","householdId":2`},
+		{"/v1/alerts?budgetId=abc", http.StatusBadRequest, "budgetId must be a positive integer"},
+		{"/v1/alerts?limit=many", http.StatusBadRequest, "limit must be an integer"},
+		{"/v1/alerts?householdId=2&userId=3", http.StatusBadRequest, "at most one alert owner filter"},
+	}
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), test.contains) {
+			t.Errorf("GET %s = %d: %s", test.path, response.Code, response.Body.String())
+		}
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/alerts?userId=4&limit=5", nil))
+	if *service.filter.Owner.UserID != 4 || service.filter.Limit != 5 || service.filter.BudgetID != nil {
+		t.Fatalf("filter=%+v", service.filter)
+	}
+}
@@ -0,0 +1,159 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"rdmm404/voltr-finance/internal/api"
+	appattachments "rdmm404/voltr-finance/internal/app/attachments"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+// multipartOverhead is room for the multipart framing around an upload of
+// the largest accepted size.
+const multipartOverhead = 64 << 10
+
+type Service interface {
+	Upload(context.Context, appattachments.UploadInput) (appattachments.Attachment, error)
+	List(ctx context.Context, transactionID int64) ([]appattachments.Attachment, error)
+	Get(context.Context, int64) (appattachments.Attachment, error)
+	Open(context.Context, int64) (appattachments.Attachment, io.ReadCloser, error)
+	Delete(context.Context, int64) error
+}
+
+type Handler struct {
+	service Service
+	support *httpapi.HandlerSupport
+}
+
+func New(service Service, support ...*httpapi.HandlerSupport) *Handler {
+	return &Handler{service: service, support: httpapi.HandlerSupportOrDefault(support...)}
+}
+
+func (h *Handler) Register(router *httpapi.Router) {
+	router.HandleFunc(http.MethodPost, api.TransactionAttachmentsPath, h.upload)
+	router.HandleFunc(http.MethodGet, api.TransactionAttachmentsPath, h.list)
+	router.HandleFunc(http.MethodGet, api.AttachmentPath, h.get)
+	router.HandleFunc(http.MethodGet, api.AttachmentContentPath, h.download)
+	router.HandleFunc(http.MethodDelete, api.AttachmentPath, h.delete)
+}
+
+// upload streams the first file form field to the service; other fields are
+// ignored.
+func (h *Handler) upload(w http.ResponseWriter, request *http.Request) {
+	transactionID, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	request.Body = http.MaxBytesReader(w, request.Body, appattachments.MaxSize+multipartOverhead)
+	reader, err := request.MultipartReader()
+	if err != nil {
+		httpapi.WriteValidationError(w, "request body must be multipart/form-data")
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			httpapi.WriteValidationError(w, "multipart field "+api.AttachmentFileField+" is required")
+			return
+		}
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			httpapi.WriteValidationError(w, "attachment exceeds "+strconv.Itoa(appattachments.MaxSize)+" bytes")
+			return
+		}
+		if err != nil {
+			httpapi.WriteValidationError(w, "request body is not valid multipart/form-data")
+			return
+		}
+		if part.FormName() != api.AttachmentFileField {
+			continue
+		}
+		item, err := h.service.Upload(request.Context(), appattachments.UploadInput{TransactionID: transactionID, FileName: part.FileName(), Content: part})
+		if err != nil {
+			h.support.Fail(w, request, err)
+			return
+		}
+		httpapi.WriteJSON(w, http.StatusCreated, attachment(item))
+		return
+	}
+}
+
+func (h *Handler) list(w http.ResponseWriter, request *http.Request) {
+	transactionID, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	items, err := h.service.List(request.Context(), transactionID)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := make([]api.Attachment, 0, len(items))
+	for _, item := range items {
+		response = append(response, attachment(item))
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) get(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.Get(request.Context(), id)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, attachment(item))
+}
+
+// download serves the stored contents with the detected type. nosniff keeps
+// browsers from reinterpreting an upload as something else.
+func (h *Handler) download(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, content, err := h.service.Open(request.Context(), id)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	defer content.Close()
+	w.Header().Set("Content-Type", item.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": item.FileName}))
+	w.Header().Set("Content-Length", strconv.FormatInt(item.Size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, content)
+}
+
+func (h *Handler) delete(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	if err := h.service.Delete(request.Context(), id); err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func attachment(item appattachments.Attachment) api.Attachment {
+	return api.Attachment{
+		ID: item.ID, TransactionID: item.TransactionID, FileName: item.FileName, ContentType: item.ContentType,
+		Size: item.Size, SHA256: item.SHA256, CreatedAt: item.CreatedAt,
+	}
+}
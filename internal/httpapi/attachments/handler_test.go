@@ -0,0 +1,140 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appattachments "rdmm404/voltr-finance/internal/app/attachments"
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+type attachmentServiceStub struct {
+	uploaded appattachments.UploadInput
+	content  string
+	deleted  int64
+}
+
+func (s *attachmentServiceStub) Upload(_ context.Context, input appattachments.UploadInput) (appattachments.Attachment, error) {
+	data, err := io.ReadAll(input.Content)
+	if err != nil {
+		return appattachments.Attachment{}, err
+	}
+	s.uploaded, s.content = input, string(data)
+	return appattachments.Attachment{ID: 3, TransactionID: input.TransactionID, FileName: input.FileName, ContentType: "image/png", Size: int64(len(data))}, nil
+}
+func (s *attachmentServiceStub) List(_ context.Context, transactionID int64) ([]appattachments.Attachment, error) {
+	if transactionID == 9 {
+		return nil, apperrors.NotFound(apperrors.CodeTransactionNotFound, "transaction not found", nil)
+	}
+	return nil, nil
+}
+func (s *attachmentServiceStub) Get(_ context.Context, id int64) (appattachments.Attachment, error) {
+	if id == 9 {
+		return appattachments.Attachment{}, apperrors.NotFound(apperrors.CodeAttachmentNotFound, "attachment not found", nil)
+	}
+	return appattachments.Attachment{ID: id, FileName: "receipt.pdf", StorageKey: "transactions/1/key"}, nil
+}
+func (s *attachmentServiceStub) Open(_ context.Context, id int64) (appattachments.Attachment, io.ReadCloser, error) {
+	return appattachments.Attachment{ID: id, FileName: "March receipt.pdf", ContentType: "application/pdf", Size: 8}, io.NopCloser(strings.NewReader("%PDF-1.7")), nil
+}
+func (s *attachmentServiceStub) Delete(_ context.Context, id int64) error {
+	s.deleted = id
+	return nil
+}
+
+func multipartBody(t *testing.T, field, fileName, content string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("note", "ignored"); err != nil {
+		t.Fatal(err)
+	}
+	part, err := writer.CreateFormFile(field, fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.WriteString(part, content)
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &body, writer.FormDataContentType()
+}
+
+func TestAttachmentUploadReadsTheFileField(t *testing.T) {
+	service := &attachmentServiceStub{}
+	router := httpapi.NewRouter()
+	New(service).Register(router)
+
+	body, contentType := multipartBody(t, "file", "receipt.png", "png bytes")
+	request := httptest.NewRequest(http.MethodPost, "/v1/transactions/4/attachments", body)
+	request.Header.Set("Content-Type", contentType)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	if response.Code != http.StatusCreated || !strings.Contains(response.Body.String(), `"fileName":"receipt.png"`) {
+		t.Fatalf("upload = %d: %s", response.Code, response.Body.String())
+	}
+	if service.uploaded.TransactionID != 4 || service.content != "png bytes" {
+		t.Fatalf("uploaded = %+v content = %q", service.uploaded, service.content)
+	}
+
+	for name, build := range map[string]func() *http.Request{
+		"not multipart": func() *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/v1/transactions/4/attachments", strings.NewReader(`{"file":"x"}`))
+		},
+		"missing field": func() *http.Request {
+			body, contentType := multipartBody(t, "other", "receipt.png", "png bytes")
+			request := httptest.NewRequest(http.MethodPost, "/v1/transactions/4/attachments", body)
+			request.Header.Set("Content-Type", contentType)
+			return request
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, build())
+			if response.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d: %s", response.Code, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestAttachmentRoutesServeMetadataAndContents(t *testing.T) {
+	service := &attachmentServiceStub{}
+	router := httpapi.NewRouter()
+	New(service).Register(router)
+	tests := []struct {
+		method, path string
+		status       int
+		contains     string
+	}{
+		{http.MethodGet, "/v1/transactions/4/attachments", http.StatusOK, "[]"},
+		{http.MethodGet, "/v1/transactions/9/attachments", http.StatusNotFound, "transaction_not_found"},
+		{http.MethodGet, "/v1/attachments/3", http.StatusOK, `"fileName":"receipt.pdf"`},
+		{http.MethodGet, "/v1/attachments/9", http.StatusNotFound, "attachment_not_found"},
+		{http.MethodDelete, "/v1/attachments/3", http.StatusNoContent, ""},
+	}
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(test.method, test.path, nil))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), test.contains) {
+			t.Errorf("%s %s = %d: %s", test.method, test.path, response.Code, response.Body.String())
+		}
+	}
+	if service.deleted != 3 {
+		t.Fatalf("deleted = %d", service.deleted)
+	}
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/attachments/3/content", nil))
+	if response.Code != http.StatusOK || response.Body.String() != "%PDF-1.7" || response.Header().Get("Content-Type") != "application/pdf" ||
+		response.Header().Get("Content-Disposition") != `attachment; filename="March receipt.pdf"` || response.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatalf("download = %d %v: %s", response.Code, response.Header(), response.Body.String())
+	}
+}
@@ -1,21 +1,44 @@
 package budgets
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"mime"
 	"net/http"
+	"strconv"
+	"time"
 
 	"rdmm404/voltr-finance/internal/api"
 	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
 	"rdmm404/voltr-finance/internal/httpapi"
+	"rdmm404/voltr-finance/internal/reportexport"
 )
 
 type Service interface {
 	GetMonthly(context.Context, appbudgets.MonthlyInput) (appbudgets.Budget, error)
 	EnsureMonthly(context.Context, appbudgets.MonthlyInput) (appbudgets.EnsureResult, error)
+	GetPeriod(context.Context, appbudgets.PeriodInput) (appbudgets.Budget, error)
+	EnsurePeriod(context.Context, appbudgets.PeriodInput) (appbudgets.EnsureResult, error)
 	CreateLine(context.Context, appbudgets.CreateLineInput) (appbudgets.Line, error)
 	UpdateLine(context.Context, appbudgets.UpdateLineInput) (appbudgets.Line, error)
-	DeleteLine(context.Context, int64) error
+	DeleteLine(ctx context.Context, id int64, expectedVersion *int64) error
+	Reallocate(context.Context, appbudgets.ReallocateInput) (appbudgets.ReallocationResult, error)
 	Report(context.Context, int64) (appbudgets.Report, error)
+	DetailedReport(context.Context, int64) (appbudgets.DetailedReport, error)
+	Lint(context.Context, int64) (appbudgets.Lint, error)
+	Trends(context.Context, appbudgets.TrendInput) (appbudgets.TrendReport, error)
+	CreateTemplate(context.Context, appbudgets.CreateTemplateInput) (appbudgets.Template, error)
+	GetTemplate(context.Context, int64) (appbudgets.Template, error)
+	ListTemplates(context.Context, appbudgets.Owner) ([]appbudgets.Template, error)
+	UpdateTemplate(context.Context, appbudgets.UpdateTemplateInput) (appbudgets.Template, error)
+	DeleteTemplate(context.Context, int64) error
+	SaveTemplate(context.Context, appbudgets.SaveTemplateInput) (appbudgets.Template, error)
+	ApplyTemplate(context.Context, appbudgets.ApplyTemplateInput) (appbudgets.ApplyTemplateResult, error)
+	Close(context.Context, appbudgets.CloseInput) (appbudgets.Closing, error)
+	Reopen(context.Context, appbudgets.ReopenInput) (appbudgets.Closing, error)
+	Compare(context.Context, appbudgets.ComparisonInput) (appbudgets.Comparison, error)
 }
 
 type Handler struct {
@@ -28,12 +51,27 @@ func New(service Service, support ...*httpapi.HandlerSupport) *Handler {
 }
 
 func (h *Handler) Register(router *httpapi.Router) {
+	router.HandleFunc(http.MethodGet, api.BudgetsPath, h.getPeriod)
+	router.HandleFunc(http.MethodPut, api.BudgetsPath, h.ensurePeriod)
 	router.HandleFunc(http.MethodGet, api.MonthlyBudgetsPath, h.getMonthly)
 	router.HandleFunc(http.MethodPost, api.MonthlyBudgetsPath, h.ensureMonthly)
+	router.HandleFunc(http.MethodGet, api.BudgetTrendsPath, h.trends)
+	router.HandleFunc(http.MethodGet, api.BudgetComparePath, h.compare)
 	router.HandleFunc(http.MethodGet, api.BudgetReportPath, h.report)
+	router.HandleFunc(http.MethodGet, api.BudgetLintPath, h.lint)
 	router.HandleFunc(http.MethodPost, api.BudgetLinesPath, h.createLine)
+	router.HandleFunc(http.MethodPost, api.BudgetReallocationsPath, h.reallocate)
 	router.HandleFunc(http.MethodPatch, api.BudgetLinePath, h.updateLine)
 	router.HandleFunc(http.MethodDelete, api.BudgetLinePath, h.deleteLine)
+	router.HandleFunc(http.MethodPost, api.BudgetSaveTemplatePath, h.saveTemplate)
+	router.HandleFunc(http.MethodPost, api.BudgetApplyTemplatePath, h.applyTemplate)
+	router.HandleFunc(http.MethodPost, api.BudgetClosePath, h.close)
+	router.HandleFunc(http.MethodPost, api.BudgetReopenPath, h.reopen)
+	router.HandleFunc(http.MethodPost, api.BudgetTemplatesPath, h.createTemplate)
+	router.HandleFunc(http.MethodGet, api.BudgetTemplatesPath, h.listTemplates)
+	router.HandleFunc(http.MethodGet, api.BudgetTemplatePath, h.getTemplate)
+	router.HandleFunc(http.MethodPatch, api.BudgetTemplatePath, h.updateTemplate)
+	router.HandleFunc(http.MethodDelete, api.BudgetTemplatePath, h.deleteTemplate)
 }
 
 func (h *Handler) getMonthly(w http.ResponseWriter, request *http.Request) {
@@ -67,6 +105,51 @@ func (h *Handler) ensureMonthly(w http.ResponseWriter, request *http.Request) {
 	httpapi.WriteJSON(w, status, budget(result.Budget))
 }
 
+func (h *Handler) getPeriod(w http.ResponseWriter, request *http.Request) {
+	query, err := periodQuery(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	input, err := periodInput(query)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.GetPeriod(request.Context(), input)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, budget(item))
+}
+
+// ensurePeriod is idempotent: it returns 201 when the budget was created from
+// the named template or the latest prior budget of the same kind and 200 when
+// it already existed.
+func (h *Handler) ensurePeriod(w http.ResponseWriter, request *http.Request) {
+	query, err := periodQuery(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	input, err := periodInput(query)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	result, err := h.service.EnsurePeriod(request.Context(), input)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	status := http.StatusOK
+	if result.Created {
+		status = http.StatusCreated
+	}
+	httpapi.WriteJSON(w, status, budget(result.Budget))
+}
+
 func (h *Handler) createLine(w http.ResponseWriter, request *http.Request) {
 	budgetID, err := httpapi.ParsePathID(request, "id")
 	if err != nil {
@@ -80,11 +163,13 @@ func (h *Handler) createLine(w http.ResponseWriter, request *http.Request) {
 	item, err := h.service.CreateLine(request.Context(), appbudgets.CreateLineInput{
 		BudgetID: budgetID, Name: body.Name, AllocationAmount: body.AllocationAmount,
 		CategoryIDs: body.CategoryIDs, CategoryCodes: body.CategoryCodes, SortOrder: body.SortOrder,
+		AlertThresholds: body.AlertThresholds, MemberAllocations: memberAllocationInputs(body.MemberAllocations),
 	})
 	if err != nil {
 		h.support.Fail(w, request, err)
 		return
 	}
+	httpapi.SetETag(w, item.Version)
 	httpapi.WriteJSON(w, http.StatusCreated, line(item))
 }
 
@@ -94,18 +179,30 @@ func (h *Handler) updateLine(w http.ResponseWriter, request *http.Request) {
 		httpapi.WriteValidationError(w, err.Error())
 		return
 	}
+	expectedVersion, err := httpapi.IfMatch(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
 	var body api.UpdateBudgetLineRequest
 	if !h.support.Decode(w, request, &body) {
 		return
 	}
-	item, err := h.service.UpdateLine(request.Context(), appbudgets.UpdateLineInput{
+	input := appbudgets.UpdateLineInput{
 		LineID: lineID, Name: body.Name, AllocationAmount: body.AllocationAmount,
 		CategoryIDs: body.CategoryIDs, CategoryCodes: body.CategoryCodes, SortOrder: body.SortOrder,
-	})
+		AlertThresholds: body.AlertThresholds, ExpectedVersion: expectedVersion,
+	}
+	if body.MemberAllocations != nil {
+		members := memberAllocationInputs(*body.MemberAllocations)
+		input.MemberAllocations = &members
+	}
+	item, err := h.service.UpdateLine(request.Context(), input)
 	if err != nil {
 		h.support.Fail(w, request, err)
 		return
 	}
+	httpapi.SetETag(w, item.Version)
 	httpapi.WriteJSON(w, http.StatusOK, line(item))
 }
 
@@ -115,19 +212,86 @@ func (h *Handler) deleteLine(w http.ResponseWriter, request *http.Request) {
 		httpapi.WriteValidationError(w, err.Error())
 		return
 	}
-	if err := h.service.DeleteLine(request.Context(), lineID); err != nil {
+	expectedVersion, err := httpapi.IfMatch(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	if err := h.service.DeleteLine(request.Context(), lineID, expectedVersion); err != nil {
 		h.support.Fail(w, request, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *Handler) reallocate(w http.ResponseWriter, request *http.Request) {
+	budgetID, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	var body api.ReallocateBudgetRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	item, err := h.service.Reallocate(request.Context(), appbudgets.ReallocateInput{
+		BudgetID: budgetID, FromLineID: body.FromLineID, ToLineID: body.ToLineID,
+		Amount: body.Amount, Reason: body.Reason, UserID: body.UserID,
+	})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusCreated, api.BudgetReallocationResult{Reallocation: reallocation(item.Reallocation), FromLine: line(item.FromLine), ToLine: line(item.ToLine)})
+}
+
+func (h *Handler) close(w http.ResponseWriter, request *http.Request) {
+	budgetID, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	var body api.CloseBudgetRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	item, err := h.service.Close(request.Context(), appbudgets.CloseInput{BudgetID: budgetID, UserID: body.UserID})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusCreated, closing(item))
+}
+
+func (h *Handler) reopen(w http.ResponseWriter, request *http.Request) {
+	budgetID, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	var body api.ReopenBudgetRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	item, err := h.service.Reopen(request.Context(), appbudgets.ReopenInput{BudgetID: budgetID, UserID: body.UserID, Reason: body.Reason})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, closing(item))
+}
+
 func (h *Handler) report(w http.ResponseWriter, request *http.Request) {
 	budgetID, err := httpapi.ParsePathID(request, "id")
 	if err != nil {
 		httpapi.WriteValidationError(w, err.Error())
 		return
 	}
+	format := request.URL.Query().Get("format")
+	if format != "" && format != api.BudgetReportFormatJSON {
+		h.exportReport(w, request, budgetID, format)
+		return
+	}
 	item, err := h.service.Report(request.Context(), budgetID)
 	if err != nil {
 		h.support.Fail(w, request, err)
@@ -136,6 +300,79 @@ func (h *Handler) report(w http.ResponseWriter, request *http.Request) {
 	httpapi.WriteJSON(w, http.StatusOK, report(item))
 }
 
+// exportReport serves the detailed report as a PDF or XLSX attachment. The
+// document is rendered in full before any of it is written so failures still
+// get a JSON error.
+func (h *Handler) exportReport(w http.ResponseWriter, request *http.Request, budgetID int64, value string) {
+	format, err := reportexport.ParseFormat(value)
+	if err != nil {
+		httpapi.WriteValidationError(w, "format must be json, pdf or xlsx")
+		return
+	}
+	item, err := h.service.DetailedReport(request.Context(), budgetID)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	var document bytes.Buffer
+	if err := reportexport.Write(&document, format, item); err != nil {
+		h.support.Fail(w, request, apperrors.WrapInternal("render budget report", err))
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": reportexport.Filename(item, format)}))
+	w.Header().Set("Content-Length", strconv.Itoa(document.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = document.WriteTo(w)
+}
+
+func (h *Handler) lint(w http.ResponseWriter, request *http.Request) {
+	budgetID, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.Lint(request.Context(), budgetID)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, lint(item))
+}
+
+func (h *Handler) trends(w http.ResponseWriter, request *http.Request) {
+	query, err := trendQuery(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	input, err := trendInput(query)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.Trends(request.Context(), input)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, trends(item))
+}
+
+func (h *Handler) compare(w http.ResponseWriter, request *http.Request) {
+	query, err := comparisonQuery(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.Compare(request.Context(), appbudgets.ComparisonInput{A: query.A, B: query.B})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, comparison(item))
+}
+
 func monthlyQuery(request *http.Request) (api.MonthlyBudgetQuery, error) {
 	householdID, err := httpapi.QueryInt64(request, "householdId")
 	if err != nil {
@@ -156,17 +393,115 @@ func monthlyQuery(request *http.Request) (api.MonthlyBudgetQuery, error) {
 	return api.MonthlyBudgetQuery{HouseholdID: householdID, UserID: userID, Year: year, Month: month}, nil
 }
 
+func periodQuery(request *http.Request) (api.BudgetPeriodQuery, error) {
+	householdID, err := httpapi.QueryInt64(request, "householdId")
+	if err != nil {
+		return api.BudgetPeriodQuery{}, err
+	}
+	userID, err := httpapi.QueryInt64(request, "userId")
+	if err != nil {
+		return api.BudgetPeriodQuery{}, err
+	}
+	values := request.URL.Query()
+	return api.BudgetPeriodQuery{
+		HouseholdID: householdID, UserID: userID, Period: values.Get("period"),
+		Date: values.Get("date"), Anchor: values.Get("anchor"), Start: values.Get("start"), End: values.Get("end"),
+		Template: values.Get("template"),
+	}, nil
+}
+
+func trendQuery(request *http.Request) (api.BudgetTrendQuery, error) {
+	householdID, err := httpapi.QueryInt64(request, "householdId")
+	if err != nil {
+		return api.BudgetTrendQuery{}, err
+	}
+	userID, err := httpapi.QueryInt64(request, "userId")
+	if err != nil {
+		return api.BudgetTrendQuery{}, err
+	}
+	values := request.URL.Query()
+	return api.BudgetTrendQuery{HouseholdID: householdID, UserID: userID, From: values.Get("from"), To: values.Get("to")}, nil
+}
+
+func comparisonQuery(request *http.Request) (api.BudgetComparisonQuery, error) {
+	var query api.BudgetComparisonQuery
+	for _, field := range []struct {
+		name   string
+		target *int64
+	}{{"a", &query.A}, {"b", &query.B}} {
+		value, err := httpapi.QueryInt64(request, field.name)
+		if err != nil {
+			return api.BudgetComparisonQuery{}, err
+		}
+		if value == nil {
+			return api.BudgetComparisonQuery{}, fmt.Errorf("%s is required", field.name)
+		}
+		*field.target = *value
+	}
+	return query, nil
+}
+
+func trendInput(value api.BudgetTrendQuery) (appbudgets.TrendInput, error) {
+	input := appbudgets.TrendInput{Owner: appbudgets.Owner{HouseholdID: value.HouseholdID, UserID: value.UserID}}
+	for _, field := range []struct {
+		name, value string
+		target      *time.Time
+	}{{"from", value.From, &input.From}, {"to", value.To, &input.To}} {
+		if field.value == "" {
+			return appbudgets.TrendInput{}, fmt.Errorf("%s is required", field.name)
+		}
+		parsed, err := time.Parse(trendMonthLayout, field.value)
+		if err != nil {
+			return appbudgets.TrendInput{}, fmt.Errorf("%s must use YYYY-MM", field.name)
+		}
+		*field.target = parsed
+	}
+	return input, nil
+}
+
+func periodInput(value api.BudgetPeriodQuery) (appbudgets.PeriodInput, error) {
+	input := appbudgets.PeriodInput{Owner: appbudgets.Owner{HouseholdID: value.HouseholdID, UserID: value.UserID}, Kind: appbudgets.PeriodKind(value.Period), Template: value.Template}
+	date, err := parseDate("date", value.Date)
+	if err != nil {
+		return appbudgets.PeriodInput{}, err
+	}
+	if date != nil {
+		input.Date = *date
+	}
+	if input.Anchor, err = parseDate("anchor", value.Anchor); err != nil {
+		return appbudgets.PeriodInput{}, err
+	}
+	if input.Start, err = parseDate("start", value.Start); err != nil {
+		return appbudgets.PeriodInput{}, err
+	}
+	if input.End, err = parseDate("end", value.End); err != nil {
+		return appbudgets.PeriodInput{}, err
+	}
+	return input, nil
+}
+
+func parseDate(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must use YYYY-MM-DD", name)
+	}
+	return &parsed, nil
+}
+
 func monthlyQueryInput(value api.MonthlyBudgetQuery) appbudgets.MonthlyInput {
 	return appbudgets.MonthlyInput{Owner: appbudgets.Owner{HouseholdID: value.HouseholdID, UserID: value.UserID}, Year: value.Year, Month: value.Month}
 }
 
 func monthlyInput(value api.EnsureMonthlyBudgetRequest) appbudgets.MonthlyInput {
-	return appbudgets.MonthlyInput{Owner: appbudgets.Owner{HouseholdID: value.HouseholdID, UserID: value.UserID}, Year: value.Year, Month: value.Month}
+	return appbudgets.MonthlyInput{Owner: appbudgets.Owner{HouseholdID: value.HouseholdID, UserID: value.UserID}, Year: value.Year, Month: value.Month, Template: value.Template}
 }
 
 func budget(item appbudgets.Budget) api.Budget {
 	result := api.Budget{
-		ID: item.ID, HouseholdID: item.Owner.HouseholdID, UserID: item.Owner.UserID,
+		ID: item.ID, HouseholdID: item.Owner.HouseholdID, UserID: item.Owner.UserID, PeriodKind: string(item.PeriodKind),
 		PeriodStart: item.PeriodStart, PeriodEnd: item.PeriodEnd, SourceBudgetID: item.SourceBudgetID,
 		Lines: make([]api.BudgetLine, 0, len(item.Lines)),
 	}
@@ -180,31 +515,48 @@ func line(item appbudgets.Line) api.BudgetLine {
 	result := api.BudgetLine{
 		ID: item.ID, BudgetID: item.BudgetID, Name: item.Name, AllocationAmount: item.AllocationAmount,
 		SortOrder: item.SortOrder, Categories: make([]api.CategoryRef, 0, len(item.Categories)),
+		AlertThresholds: item.AlertThresholds, Version: item.Version,
+	}
+	if result.AlertThresholds == nil {
+		result.AlertThresholds = []int32{}
 	}
 	for _, value := range item.Categories {
 		result.Categories = append(result.Categories, api.CategoryRef{ID: value.ID, Code: value.Code, Name: value.Name})
 	}
+	result.MemberAllocations = make([]api.BudgetMemberAllocation, 0, len(item.MemberAllocations))
+	for _, value := range item.MemberAllocations {
+		result.MemberAllocations = append(result.MemberAllocations, api.BudgetMemberAllocation{
+			UserID: value.Member.ID, UserName: value.Member.Name, Amount: value.Amount, Percent: value.Percent,
+		})
+	}
+	return result
+}
+
+func memberAllocationInputs(values []api.BudgetMemberAllocationInput) []appbudgets.MemberAllocationInput {
+	if values == nil {
+		return nil
+	}
+	result := make([]appbudgets.MemberAllocationInput, 0, len(values))
+	for _, value := range values {
+		result = append(result, appbudgets.MemberAllocationInput{UserID: value.UserID, Amount: value.Amount, Percent: value.Percent})
+	}
 	return result
 }
 
 func report(item appbudgets.Report) api.BudgetReport {
 	result := api.BudgetReport{
-		Budget: api.BudgetSummary{
-			ID: item.Budget.ID, HouseholdID: item.Budget.Owner.HouseholdID, UserID: item.Budget.Owner.UserID,
-			PeriodStart: item.Budget.PeriodStart, PeriodEnd: item.Budget.PeriodEnd, SourceBudgetID: item.Budget.SourceBudgetID,
-		},
+		Budget:               budgetSummary(item.Budget),
 		Lines:                make([]api.BudgetReportLine, 0, len(item.Lines)),
 		UnmappedTransactions: make([]api.BudgetUnmappedTransaction, 0, len(item.UnmappedTransactions)),
-		Totals: api.BudgetReportTotals{
-			AllocationAmount: item.Totals.AllocationAmount, ActualAmount: item.Totals.ActualAmount,
-			RemainingAmount: item.Totals.RemainingAmount, UnmappedActualAmount: item.Totals.UnmappedActualAmount,
-			UncategorizedActualAmount: item.Totals.UncategorizedActualAmount,
-		},
+		Goals:                make([]api.BudgetGoalProgress, 0, len(item.Goals)),
+		Reallocations:        make([]api.BudgetReallocation, 0, len(item.Reallocations)),
+		Totals:               reportTotals(item.Totals),
 	}
 	for _, value := range item.Lines {
 		mapped := line(value.Line)
 		result.Lines = append(result.Lines, api.BudgetReportLine{
 			ID: mapped.ID, BudgetID: mapped.BudgetID, Name: mapped.Name, AllocationAmount: mapped.AllocationAmount,
+			OriginalAllocationAmount: value.OriginalAllocationAmount, ReallocatedAmount: value.ReallocatedAmount,
 			ActualAmount: value.ActualAmount, RemainingAmount: value.RemainingAmount,
 			SortOrder: mapped.SortOrder, Categories: mapped.Categories,
 		})
@@ -216,5 +568,170 @@ func report(item appbudgets.Report) api.BudgetReport {
 		}
 		result.UnmappedTransactions = append(result.UnmappedTransactions, mapped)
 	}
+	for _, value := range item.Goals {
+		result.Goals = append(result.Goals, api.BudgetGoalProgress{
+			ID: value.ID, Name: value.Name, TargetAmount: value.TargetAmount, TargetDate: value.TargetDate,
+			BalanceAmount: value.BalanceAmount, RemainingAmount: value.RemainingAmount,
+			RequiredMonthlyAmount: value.RequiredMonthlyAmount, PeriodNetAmount: value.PeriodNetAmount, State: value.State,
+		})
+	}
+	for _, value := range item.Reallocations {
+		result.Reallocations = append(result.Reallocations, reallocation(value))
+	}
+	if item.Closing != nil {
+		result.Closing = &api.BudgetReportClosing{
+			ID: item.Closing.ID, ClosedByUserID: item.Closing.ClosedBy.ID, ClosedByUserName: item.Closing.ClosedBy.Name, ClosedAt: item.Closing.ClosedAt,
+			Drifted: item.Closing.Drifted, CurrentTotals: reportTotals(item.Closing.CurrentTotals), Drift: make([]api.BudgetLineDrift, 0, len(item.Closing.Drift)),
+		}
+		for _, value := range item.Closing.Drift {
+			result.Closing.Drift = append(result.Closing.Drift, api.BudgetLineDrift{
+				LineID: value.LineID, Name: value.Name, ClosedActualAmount: value.ClosedActualAmount, CurrentActualAmount: value.CurrentActualAmount,
+			})
+		}
+	}
 	return result
 }
+
+func reportTotals(item appbudgets.ReportTotals) api.BudgetReportTotals {
+	return api.BudgetReportTotals{
+		AllocationAmount: item.AllocationAmount, ActualAmount: item.ActualAmount,
+		RemainingAmount: item.RemainingAmount, UnmappedActualAmount: item.UnmappedActualAmount,
+		UncategorizedActualAmount: item.UncategorizedActualAmount,
+	}
+}
+
+func closing(item appbudgets.Closing) api.BudgetClosing {
+	result := api.BudgetClosing{
+		ID: item.ID, BudgetID: item.BudgetID, Lines: make([]api.BudgetClosingLine, 0, len(item.Lines)), Totals: reportTotals(item.Totals),
+		ClosedByUserID: item.ClosedBy.ID, ClosedByUserName: item.ClosedBy.Name, ClosedAt: item.ClosedAt,
+		ReopenedAt: item.ReopenedAt, ReopenReason: item.ReopenReason,
+	}
+	if item.ReopenedBy != nil {
+		result.ReopenedByUserID, result.ReopenedByUserName = &item.ReopenedBy.ID, &item.ReopenedBy.Name
+	}
+	for _, value := range item.Lines {
+		result.Lines = append(result.Lines, api.BudgetClosingLine{
+			LineID: value.LineID, Name: value.Name, AllocationAmount: value.AllocationAmount, ActualAmount: value.ActualAmount, SortOrder: value.SortOrder,
+		})
+	}
+	return result
+}
+
+func lint(item appbudgets.Lint) api.BudgetLint {
+	result := api.BudgetLint{
+		Budget: budgetSummary(item.Budget),
+		Issues: make([]api.BudgetLintIssue, 0, len(item.Issues)),
+	}
+	for _, value := range item.Issues {
+		issue := api.BudgetLintIssue{Kind: string(value.Kind), Message: value.Message, Lines: make([]api.BudgetLineRef, 0, len(value.Lines)), ActualAmount: value.ActualAmount}
+		for _, line := range value.Lines {
+			issue.Lines = append(issue.Lines, api.BudgetLineRef{ID: line.ID, Name: line.Name})
+		}
+		if value.Category != nil {
+			issue.Category = &api.CategoryRef{ID: value.Category.ID, Code: value.Category.Code, Name: value.Category.Name}
+		}
+		result.Issues = append(result.Issues, issue)
+	}
+	return result
+}
+
+func reallocation(item appbudgets.Reallocation) api.BudgetReallocation {
+	return api.BudgetReallocation{
+		ID: item.ID, BudgetID: item.BudgetID,
+		FromLine: api.BudgetLineRef{ID: item.FromLine.ID, Name: item.FromLine.Name}, ToLine: api.BudgetLineRef{ID: item.ToLine.ID, Name: item.ToLine.Name},
+		Amount: item.Amount, Reason: item.Reason, CreatedByUserID: item.CreatedBy.ID, CreatedByUserName: item.CreatedBy.Name, CreatedAt: item.CreatedAt,
+	}
+}
+
+const trendMonthLayout = "2006-01"
+
+func trends(item appbudgets.TrendReport) api.BudgetTrends {
+	result := api.BudgetTrends{
+		HouseholdID: item.Owner.HouseholdID, UserID: item.Owner.UserID,
+		From: item.From.Format(trendMonthLayout), To: item.To.Format(trendMonthLayout),
+		Months:     make([]api.BudgetTrendMonth, 0, len(item.Months)),
+		Lines:      make([]api.BudgetTrendSeries, 0, len(item.Lines)),
+		Categories: make([]api.BudgetTrendSeries, 0, len(item.Categories)),
+		Totals:     trendTotals(item.Totals), YearToDate: trendTotals(item.YearToDate),
+	}
+	for _, value := range item.Months {
+		result.Months = append(result.Months, api.BudgetTrendMonth{
+			Month: value.Month.Format(trendMonthLayout), BudgetID: value.BudgetID,
+			AllocationAmount: value.AllocationAmount, ActualAmount: value.ActualAmount, RemainingAmount: value.RemainingAmount,
+		})
+	}
+	for _, value := range item.Lines {
+		result.Lines = append(result.Lines, trendSeries(value))
+	}
+	for _, value := range item.Categories {
+		result.Categories = append(result.Categories, trendSeries(value))
+	}
+	return result
+}
+
+func comparison(item appbudgets.Comparison) api.BudgetComparison {
+	result := api.BudgetComparison{
+		A: budgetSummary(item.A), B: budgetSummary(item.B), Lines: make([]api.BudgetLineComparison, 0, len(item.Lines)),
+		Totals: api.BudgetComparisonTotals{
+			A: reportTotals(item.Totals.A), B: reportTotals(item.Totals.B),
+			AllocationDelta: item.Totals.AllocationDelta, ActualDelta: item.Totals.ActualDelta,
+		},
+	}
+	if item.Lineage != nil {
+		result.Lineage = &api.BudgetLineage{AncestorID: item.Lineage.AncestorID, DescendantID: item.Lineage.DescendantID, Generations: item.Lineage.Generations}
+	}
+	for _, value := range item.Lines {
+		result.Lines = append(result.Lines, api.BudgetLineComparison{
+			Status: string(value.Status), MatchedBy: string(value.MatchedBy), A: comparedLine(value.A), B: comparedLine(value.B),
+			AllocationDelta: value.AllocationDelta, ActualDelta: value.ActualDelta,
+			AddedCategories: categoryRefs(value.AddedCategories), RemovedCategories: categoryRefs(value.RemovedCategories),
+		})
+	}
+	return result
+}
+
+func comparedLine(item *appbudgets.ComparedLine) *api.BudgetComparedLine {
+	if item == nil {
+		return nil
+	}
+	return &api.BudgetComparedLine{ID: item.ID, Name: item.Name, AllocationAmount: item.AllocationAmount, ActualAmount: item.ActualAmount, Categories: categoryRefs(item.Categories)}
+}
+
+func budgetSummary(item appbudgets.BudgetSummary) api.BudgetSummary {
+	return api.BudgetSummary{
+		ID: item.ID, HouseholdID: item.Owner.HouseholdID, UserID: item.Owner.UserID, PeriodKind: string(item.PeriodKind),
+		PeriodStart: item.PeriodStart, PeriodEnd: item.PeriodEnd, SourceBudgetID: item.SourceBudgetID,
+	}
+}
+
+func categoryRefs(items []appbudgets.Category) []api.CategoryRef {
+	result := make([]api.CategoryRef, 0, len(items))
+	for _, value := range items {
+		result = append(result, api.CategoryRef{ID: value.ID, Code: value.Code, Name: value.Name})
+	}
+	return result
+}
+
+func trendSeries(item appbudgets.TrendSeries) api.BudgetTrendSeries {
+	result := api.BudgetTrendSeries{
+		Name: item.Name, Months: make([]api.BudgetTrendPoint, 0, len(item.Months)),
+		Totals: trendTotals(item.Totals), YearToDate: trendTotals(item.YearToDate),
+	}
+	if item.Category != nil {
+		result.Category = &api.CategoryRef{ID: item.Category.ID, Code: item.Category.Code, Name: item.Category.Name}
+	}
+	for _, value := range item.Months {
+		result.Months = append(result.Months, api.BudgetTrendPoint{
+			Month: value.Month.Format(trendMonthLayout), Budgeted: value.Budgeted,
+			AllocationAmount: value.AllocationAmount, ActualAmount: value.ActualAmount, RemainingAmount: value.RemainingAmount,
+		})
+	}
+	return result
+}
+
+func trendTotals(item appbudgets.TrendTotals) api.BudgetTrendTotals {
+	return api.BudgetTrendTotals{
+		Months: item.Months, AllocationAmount: item.AllocationAmount, ActualAmount: item.ActualAmount, RemainingAmount: item.RemainingAmount,
+		AverageAllocationAmount: item.AverageAllocationAmount, AverageActualAmount: item.AverageActualAmount,
+	}
+}
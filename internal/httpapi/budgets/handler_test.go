@@ -7,8 +7,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
 	"rdmm404/voltr-finance/internal/httpapi"
 )
 
@@ -20,16 +22,115 @@ func (s budgetServiceStub) EnsureMonthly(_ context.Context, input appbudgets.Mon
 func (budgetServiceStub) GetMonthly(_ context.Context, input appbudgets.MonthlyInput) (appbudgets.Budget, error) {
 	return appbudgets.Budget{ID: 5, Owner: input.Owner, Lines: []appbudgets.Line{}}, nil
 }
+func (s budgetServiceStub) EnsurePeriod(_ context.Context, input appbudgets.PeriodInput) (appbudgets.EnsureResult, error) {
+	return appbudgets.EnsureResult{Budget: appbudgets.Budget{ID: 6, Owner: input.Owner, PeriodKind: input.Kind, Lines: []appbudgets.Line{}}, Created: s.created}, nil
+}
+func (budgetServiceStub) GetPeriod(_ context.Context, input appbudgets.PeriodInput) (appbudgets.Budget, error) {
+	return appbudgets.Budget{ID: 6, Owner: input.Owner, PeriodKind: input.Kind, Lines: []appbudgets.Line{}}, nil
+}
 func (budgetServiceStub) CreateLine(_ context.Context, input appbudgets.CreateLineInput) (appbudgets.Line, error) {
-	return appbudgets.Line{ID: 2, BudgetID: input.BudgetID, Categories: []appbudgets.Category{}}, nil
+	members := make([]appbudgets.MemberAllocation, 0, len(input.MemberAllocations))
+	for _, item := range input.MemberAllocations {
+		members = append(members, appbudgets.MemberAllocation{Member: appbudgets.Author{ID: item.UserID, Name: "Alex"}, Amount: item.Amount, Percent: item.Percent})
+	}
+	return appbudgets.Line{ID: 2, BudgetID: input.BudgetID, Categories: []appbudgets.Category{}, MemberAllocations: members}, nil
 }
 func (budgetServiceStub) UpdateLine(_ context.Context, input appbudgets.UpdateLineInput) (appbudgets.Line, error) {
 	return appbudgets.Line{ID: input.LineID, Categories: []appbudgets.Category{}}, nil
 }
-func (budgetServiceStub) DeleteLine(context.Context, int64) error { return nil }
+func (budgetServiceStub) DeleteLine(context.Context, int64, *int64) error { return nil }
+func (budgetServiceStub) Reallocate(_ context.Context, input appbudgets.ReallocateInput) (appbudgets.ReallocationResult, error) {
+	return appbudgets.ReallocationResult{
+		Reallocation: appbudgets.Reallocation{
+			ID: 4, BudgetID: input.BudgetID, FromLine: appbudgets.LineRef{ID: input.FromLineID, Name: "Entertainment"}, ToLine: appbudgets.LineRef{ID: input.ToLineID, Name: "Groceries"},
+			Amount: input.Amount, Reason: input.Reason, CreatedBy: appbudgets.Author{ID: input.UserID, Name: "Alex"},
+		},
+		FromLine: appbudgets.Line{ID: input.FromLineID, BudgetID: input.BudgetID, AllocationAmount: "150.00", Categories: []appbudgets.Category{}},
+		ToLine:   appbudgets.Line{ID: input.ToLineID, BudgetID: input.BudgetID, AllocationAmount: "650.00", Categories: []appbudgets.Category{}},
+	}, nil
+}
 func (budgetServiceStub) Report(context.Context, int64) (appbudgets.Report, error) {
 	return appbudgets.Report{Lines: []appbudgets.ReportLine{}, UnmappedTransactions: []appbudgets.UnmappedTransaction{}}, nil
 }
+func (budgetServiceStub) DetailedReport(_ context.Context, budgetID int64) (appbudgets.DetailedReport, error) {
+	if budgetID == 404 {
+		return appbudgets.DetailedReport{}, apperrors.NotFound(apperrors.CodeBudgetNotFound, "budget not found", nil)
+	}
+	return appbudgets.DetailedReport{
+		Budget: appbudgets.BudgetSummary{ID: budgetID, PeriodKind: appbudgets.PeriodMonthly, PeriodStart: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC)},
+		Lines:  []appbudgets.DetailedReportLine{}, UnmappedTransactions: []appbudgets.DetailedTransaction{},
+		Totals: appbudgets.ReportTotals{AllocationAmount: "0.00", ActualAmount: "0.00", RemainingAmount: "0.00", UnmappedActualAmount: "0.00"},
+	}, nil
+}
+func (budgetServiceStub) Lint(_ context.Context, budgetID int64) (appbudgets.Lint, error) {
+	return appbudgets.Lint{Budget: appbudgets.BudgetSummary{ID: budgetID}, Issues: []appbudgets.LintIssue{
+		{Kind: appbudgets.LintUnmappedCategory, Message: "unmapped", Lines: []appbudgets.LineRef{}, Category: &appbudgets.Category{ID: 3, Code: "gifts", Name: "Gifts"}, ActualAmount: "40.00"},
+		{Kind: appbudgets.LintEmptyLine, Message: "empty", Lines: []appbudgets.LineRef{{ID: 9, Name: "Misc"}}},
+	}}, nil
+}
+func (budgetServiceStub) Trends(_ context.Context, input appbudgets.TrendInput) (appbudgets.TrendReport, error) {
+	point := appbudgets.TrendPoint{Month: input.From, Budgeted: true, AllocationAmount: "100.00", ActualAmount: "80.00", RemainingAmount: "20.00"}
+	totals := appbudgets.TrendTotals{Months: 1, AllocationAmount: "100.00", ActualAmount: "80.00", RemainingAmount: "20.00", AverageAllocationAmount: "100.00", AverageActualAmount: "80.00"}
+	return appbudgets.TrendReport{
+		Owner: input.Owner, From: input.From, To: input.To,
+		Months:     []appbudgets.TrendMonth{{Month: input.From, AllocationAmount: "100.00", ActualAmount: "80.00", RemainingAmount: "20.00"}},
+		Lines:      []appbudgets.TrendSeries{{Name: "Food", Months: []appbudgets.TrendPoint{point}, Totals: totals, YearToDate: totals}},
+		Categories: []appbudgets.TrendSeries{{Name: "Groceries", Category: &appbudgets.Category{ID: 3, Code: "groceries", Name: "Groceries"}, Months: []appbudgets.TrendPoint{point}, Totals: totals, YearToDate: totals}},
+		Totals:     totals, YearToDate: totals,
+	}, nil
+}
+func (budgetServiceStub) CreateTemplate(_ context.Context, input appbudgets.CreateTemplateInput) (appbudgets.Template, error) {
+	return appbudgets.Template{ID: 4, Owner: input.Owner, Name: input.Name, Lines: []appbudgets.TemplateLine{}}, nil
+}
+func (budgetServiceStub) GetTemplate(_ context.Context, id int64) (appbudgets.Template, error) {
+	return appbudgets.Template{ID: id, Name: "Standard", Lines: []appbudgets.TemplateLine{
+		{ID: 8, Name: "Food", AllocationAmount: "400.00", SortOrder: 1, Categories: []appbudgets.Category{{ID: 3, Code: "groceries", Name: "Groceries"}}},
+	}}, nil
+}
+func (budgetServiceStub) ListTemplates(_ context.Context, owner appbudgets.Owner) ([]appbudgets.Template, error) {
+	return []appbudgets.Template{{ID: 4, Owner: owner, Name: "Standard", Lines: []appbudgets.TemplateLine{}}}, nil
+}
+func (budgetServiceStub) UpdateTemplate(_ context.Context, input appbudgets.UpdateTemplateInput) (appbudgets.Template, error) {
+	return appbudgets.Template{ID: input.ID, Name: *input.Name, Lines: []appbudgets.TemplateLine{}}, nil
+}
+func (budgetServiceStub) DeleteTemplate(context.Context, int64) error { return nil }
+func (budgetServiceStub) SaveTemplate(_ context.Context, input appbudgets.SaveTemplateInput) (appbudgets.Template, error) {
+	return appbudgets.Template{ID: 5, Name: input.Name, Lines: []appbudgets.TemplateLine{}}, nil
+}
+func (budgetServiceStub) ApplyTemplate(_ context.Context, input appbudgets.ApplyTemplateInput) (appbudgets.ApplyTemplateResult, error) {
+	after := appbudgets.Line{ID: 9, BudgetID: input.BudgetID, Name: "Food", AllocationAmount: "400.00", SortOrder: 1, Categories: []appbudgets.Category{}, AlertThresholds: []int32{}}
+	return appbudgets.ApplyTemplateResult{
+		Budget: appbudgets.Budget{ID: input.BudgetID, Lines: []appbudgets.Line{}}, Template: appbudgets.Template{ID: 4, Name: *input.TemplateName, Lines: []appbudgets.TemplateLine{}},
+		Mode: input.Mode, Changes: []appbudgets.LineChange{{Action: appbudgets.LineAdded, After: &after}}, Applied: !input.DryRun,
+	}, nil
+}
+func (budgetServiceStub) Close(_ context.Context, input appbudgets.CloseInput) (appbudgets.Closing, error) {
+	lineID := int64(9)
+	return appbudgets.Closing{
+		ID: 3, BudgetID: input.BudgetID, ClosedBy: appbudgets.Author{ID: input.UserID, Name: "Ana"},
+		Totals: appbudgets.ReportTotals{AllocationAmount: "400.00", ActualAmount: "120.00", RemainingAmount: "280.00", UnmappedActualAmount: "0.00", UncategorizedActualAmount: "0.00"},
+		Lines:  []appbudgets.ClosingLine{{LineID: &lineID, Name: "Food", AllocationAmount: "400.00", ActualAmount: "120.00", SortOrder: 1}},
+	}, nil
+}
+func (budgetServiceStub) Compare(_ context.Context, input appbudgets.ComparisonInput) (appbudgets.Comparison, error) {
+	food := appbudgets.Category{ID: 3, Code: "groceries", Name: "Groceries"}
+	return appbudgets.Comparison{
+		A: appbudgets.BudgetSummary{ID: input.A}, B: appbudgets.BudgetSummary{ID: input.B},
+		Lineage: &appbudgets.Lineage{AncestorID: input.A, DescendantID: input.B, Generations: 1},
+		Lines: []appbudgets.LineComparison{{
+			Status: appbudgets.LineInBOnly, B: &appbudgets.ComparedLine{ID: 9, Name: "Food", AllocationAmount: "400.00", ActualAmount: "0.00", Categories: []appbudgets.Category{food}},
+			AllocationDelta: "400.00", ActualDelta: "0.00", AddedCategories: []appbudgets.Category{}, RemovedCategories: []appbudgets.Category{},
+		}},
+		Totals: appbudgets.ComparisonTotals{AllocationDelta: "400.00", ActualDelta: "0.00"},
+	}, nil
+}
+func (budgetServiceStub) Reopen(_ context.Context, input appbudgets.ReopenInput) (appbudgets.Closing, error) {
+	reopenedAt := time.Date(2026, 8, 2, 0, 0, 0, 0, time.UTC)
+	return appbudgets.Closing{
+		ID: 3, BudgetID: input.BudgetID, Lines: []appbudgets.ClosingLine{},
+		ReopenedBy: &appbudgets.Author{ID: input.UserID, Name: "Ana"}, ReopenedAt: &reopenedAt, ReopenReason: &input.Reason,
+	}, nil
+}
 func TestEnsureMonthlyReturnsCreated(t *testing.T) {
 	router := httpapi.NewRouter()
 	New(budgetServiceStub{created: true}).Register(router)
@@ -73,6 +174,28 @@ func TestMonthlyQueryModel(t *testing.T) {
 	}
 }
 
+func TestPeriodBudgetRoutesParseDatesAndReportCreation(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(budgetServiceStub{created: true}).Register(router)
+	tests := []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{http.MethodGet, "/v1/budgets?userId=2&period=weekly&date=2026-10-19", http.StatusOK, `"periodKind":"weekly"`},
+		{http.MethodPut, "/v1/budgets?householdId=2&period=custom&start=2026-10-01&end=2026-10-15", http.StatusCreated, `"periodKind":"custom"`},
+		{http.MethodGet, "/v1/budgets?userId=2&period=biweekly&date=2026-10-19&anchor=10/02/2026", http.StatusBadRequest, "anchor must use YYYY-MM-DD"},
+		{http.MethodPut, "/v1/budgets?userId=x&period=annual&date=2026-10-19", http.StatusBadRequest, "userId must be a positive integer"},
+	}
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(test.method, test.path, nil))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), test.body) {
+			t.Errorf("%s %s = %d: %s", test.method, test.path, response.Code, response.Body.String())
+		}
+	}
+}
+
 func TestBudgetReadLineAndReportRoutes(t *testing.T) {
 	router := httpapi.NewRouter()
 	New(budgetServiceStub{}).Register(router)
@@ -85,6 +208,14 @@ func TestBudgetReadLineAndReportRoutes(t *testing.T) {
 		{http.MethodPatch, "/v1/budget-lines/2", `{"name":"Groceries"}`, http.StatusOK},
 		{http.MethodDelete, "/v1/budget-lines/2", "", http.StatusNoContent},
 		{http.MethodGet, "/v1/budgets/1/report", "", http.StatusOK},
+		{http.MethodGet, "/v1/budgets/1/lint", "", http.StatusOK},
+		{http.MethodPost, "/v1/budget-templates", `{"householdId":2,"name":"Standard","lines":[]}`, http.StatusCreated},
+		{http.MethodGet, "/v1/budget-templates?householdId=2", "", http.StatusOK},
+		{http.MethodGet, "/v1/budget-templates?userId=x", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/budget-templates/4", "", http.StatusOK},
+		{http.MethodPatch, "/v1/budget-templates/4", `{"name":"Lean"}`, http.StatusOK},
+		{http.MethodDelete, "/v1/budget-templates/4", "", http.StatusNoContent},
+		{http.MethodPost, "/v1/budgets/1/save-template", `{"name":"Standard"}`, http.StatusCreated},
 	}
 	for _, test := range tests {
 		request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
@@ -95,3 +226,157 @@ func TestBudgetReadLineAndReportRoutes(t *testing.T) {
 		}
 	}
 }
+
+func TestBudgetLineRouteMapsMemberAllocations(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(budgetServiceStub{}).Register(router)
+	request := httptest.NewRequest(http.MethodPost, "/v1/budgets/1/lines", strings.NewReader(`{"name":"Food","allocationAmount":"400.00","memberAllocations":[{"userId":2,"amount":"150.00"},{"userId":3,"percent":"40"}]}`))
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	body := response.Body.String()
+	if response.Code != http.StatusCreated || !strings.Contains(body, `"memberAllocations":[{"userId":2,"userName":"Alex","amount":"150.00"},{"userId":3,"userName":"Alex","percent":"40"}]`) {
+		t.Fatalf("%d %s", response.Code, body)
+	}
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodPatch, "/v1/budget-lines/2", strings.NewReader(`{"name":"Groceries"}`)))
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"memberAllocations":[]`) {
+		t.Fatalf("%d %s", response.Code, response.Body.String())
+	}
+}
+
+func TestBudgetReportRouteExportsPDFAndXLSX(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(budgetServiceStub{}).Register(router)
+	for _, test := range []struct {
+		path, contentType, disposition, prefix string
+	}{
+		{"/v1/budgets/12/report?format=pdf", "application/pdf", `attachment; filename=budget-12-2026-07-01.pdf`, "%PDF-"},
+		{"/v1/budgets/12/report?format=xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", `attachment; filename=budget-12-2026-07-01.xlsx`, "PK"},
+	} {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if response.Code != http.StatusOK || response.Header().Get("Content-Type") != test.contentType || response.Header().Get("Content-Disposition") != test.disposition || !strings.HasPrefix(response.Body.String(), test.prefix) {
+			t.Fatalf("%s = %d %v", test.path, response.Code, response.Header())
+		}
+	}
+	for path, want := range map[string]int{
+		"/v1/budgets/12/report?format=json": http.StatusOK,
+		"/v1/budgets/12/report?format=csv":  http.StatusBadRequest,
+		"/v1/budgets/404/report?format=pdf": http.StatusNotFound,
+	} {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+		if response.Code != want || response.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("%s = %d %s", path, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestBudgetReallocationRouteReturnsMoveAndAdjustedLines(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(budgetServiceStub{}).Register(router)
+	request := httptest.NewRequest(http.MethodPost, "/v1/budgets/1/reallocations", strings.NewReader(`{"fromLineId":2,"toLineId":3,"amount":"50.00","reason":"Groceries ran over","userId":7}`))
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	body := response.Body.String()
+	for _, expected := range []string{`"fromLine":{"id":2,"name":"Entertainment"}`, `"reason":"Groceries ran over"`, `"createdByUserId":7`, `"toLine":{"id":3,"budgetId":1,"name":"","allocationAmount":"650.00"`} {
+		if response.Code != http.StatusCreated || !strings.Contains(body, expected) {
+			t.Fatalf("expected %q in %d %s", expected, response.Code, body)
+		}
+	}
+}
+
+func TestBudgetLintRouteMapsIssues(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(budgetServiceStub{}).Register(router)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/budgets/4/lint", nil))
+	body := response.Body.String()
+	for _, expected := range []string{`"budget":{"id":4`, `{"kind":"unmapped_category","message":"unmapped","lines":[],"category":{"id":3,"code":"gifts","name":"Gifts"},"actualAmount":"40.00"}`, `{"kind":"empty_line","message":"empty","lines":[{"id":9,"name":"Misc"}]}`} {
+		if response.Code != http.StatusOK || !strings.Contains(body, expected) {
+			t.Fatalf("expected %q in %d %s", expected, response.Code, body)
+		}
+	}
+}
+
+func TestBudgetTrendsRouteParsesMonths(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(budgetServiceStub{}).Register(router)
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/v1/budgets/trends?householdId=2&from=2026-01&to=2026-03", http.StatusOK, `"lines":[{"name":"Food","months":[{"month":"2026-01","budgeted":true`},
+		{"/v1/budgets/trends?householdId=2&from=2026-01&to=2026-03", http.StatusOK, `"category":{"id":3,"code":"groceries","name":"Groceries"}`},
+		{"/v1/budgets/trends?householdId=2&from=2026-01&to=2026-03", http.StatusOK, `"yearToDate":{"months":1,`},
+		{"/v1/budgets/trends?householdId=2&to=2026-03", http.StatusBadRequest, "from is required"},
+		{"/v1/budgets/trends?householdId=2&from=2026-01&to=March", http.StatusBadRequest, "to must use YYYY-MM"},
+	}
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), test.body) {
+			t.Errorf("GET %s = %d: %s", test.path, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestBudgetApplyTemplateRouteMapsChanges(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(budgetServiceStub{}).Register(router)
+	request := httptest.NewRequest(http.MethodPost, "/v1/budgets/3/apply-template", strings.NewReader(`{"templateName":"Standard","mode":"replace","dryRun":true}`))
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	body := response.Body.String()
+	for _, expected := range []string{`"template":{"id":4,"name":"Standard","lines":[]}`, `"mode":"replace","applied":false`, `"changes":[{"action":"add","after":{"id":9,"budgetId":3,"name":"Food","allocationAmount":"400.00"`} {
+		if response.Code != http.StatusOK || !strings.Contains(body, expected) {
+			t.Fatalf("expected %q in %d %s", expected, response.Code, body)
+		}
+	}
+}
+
+func TestBudgetCloseAndReopenRoutes(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(budgetServiceStub{}).Register(router)
+	tests := []struct {
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{"/v1/budgets/3/close", `{"userId":2}`, http.StatusCreated, `"lines":[{"lineId":9,"name":"Food","allocationAmount":"400.00","actualAmount":"120.00","sortOrder":1}]`},
+		{"/v1/budgets/3/close", `{"userId":2}`, http.StatusCreated, `"closedByUserId":2,"closedByUserName":"Ana"`},
+		{"/v1/budgets/3/reopen", `{"userId":2,"reason":"late refund"}`, http.StatusOK, `"reopenedByUserId":2,"reopenedByUserName":"Ana","reopenedAt":"2026-08-02T00:00:00Z","reopenReason":"late refund"`},
+		{"/v1/budgets/x/close", `{"userId":2}`, http.StatusBadRequest, "validation_error"},
+	}
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body)))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), test.want) {
+			t.Errorf("POST %s = %d: %s", test.path, response.Code, response.Body.String())
+		}
+	}
+}
+
+func TestBudgetCompareRouteRequiresBothBudgets(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(budgetServiceStub{}).Register(router)
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/v1/budgets/compare?a=12&b=13", http.StatusOK, `"lineage":{"ancestorId":12,"descendantId":13,"generations":1}`},
+		{"/v1/budgets/compare?a=12&b=13", http.StatusOK, `"lines":[{"status":"added","b":{"id":9,"name":"Food","allocationAmount":"400.00","actualAmount":"0.00","categories":[{"id":3,"code":"groceries","name":"Groceries"}]},"allocationDelta":"400.00","actualDelta":"0.00","addedCategories":[],"removedCategories":[]}]`},
+		{"/v1/budgets/compare?a=12", http.StatusBadRequest, "b is required"},
+		{"/v1/budgets/compare?a=x&b=13", http.StatusBadRequest, "a must be a positive integer"},
+	}
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), test.body) {
+			t.Errorf("GET %s = %d: %s", test.path, response.Code, response.Body.String())
+		}
+	}
+}
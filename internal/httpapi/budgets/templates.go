@@ -0,0 +1,187 @@
+package budgets
+
+import (
+	"net/http"
+
+	"rdmm404/voltr-finance/internal/api"
+	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+func (h *Handler) createTemplate(w http.ResponseWriter, request *http.Request) {
+	var body api.CreateBudgetTemplateRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	item, err := h.service.CreateTemplate(request.Context(), appbudgets.CreateTemplateInput{
+		Owner: appbudgets.Owner{HouseholdID: body.HouseholdID, UserID: body.UserID}, Name: body.Name, Lines: templateLineInputs(body.Lines),
+	})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusCreated, template(item))
+}
+
+func (h *Handler) listTemplates(w http.ResponseWriter, request *http.Request) {
+	householdID, err := httpapi.QueryInt64(request, "householdId")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	userID, err := httpapi.QueryInt64(request, "userId")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	items, err := h.service.ListTemplates(request.Context(), appbudgets.Owner{HouseholdID: householdID, UserID: userID})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := make([]api.BudgetTemplate, 0, len(items))
+	for _, item := range items {
+		response = append(response, template(item))
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) getTemplate(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.GetTemplate(request.Context(), id)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, template(item))
+}
+
+func (h *Handler) updateTemplate(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	var body api.UpdateBudgetTemplateRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	input := appbudgets.UpdateTemplateInput{ID: id, Name: body.Name}
+	if body.Lines != nil {
+		lines := templateLineInputs(*body.Lines)
+		input.Lines = &lines
+	}
+	item, err := h.service.UpdateTemplate(request.Context(), input)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, template(item))
+}
+
+func (h *Handler) deleteTemplate(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	if err := h.service.DeleteTemplate(request.Context(), id); err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) saveTemplate(w http.ResponseWriter, request *http.Request) {
+	budgetID, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	var body api.SaveBudgetTemplateRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	item, err := h.service.SaveTemplate(request.Context(), appbudgets.SaveTemplateInput{BudgetID: budgetID, Name: body.Name})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusCreated, template(item))
+}
+
+// applyTemplate returns 200 for both dry runs and applied changes; the
+// response's applied flag tells them apart.
+func (h *Handler) applyTemplate(w http.ResponseWriter, request *http.Request) {
+	budgetID, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	var body api.ApplyBudgetTemplateRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	item, err := h.service.ApplyTemplate(request.Context(), appbudgets.ApplyTemplateInput{
+		BudgetID: budgetID, TemplateID: body.TemplateID, TemplateName: body.TemplateName,
+		Mode: appbudgets.ApplyMode(body.Mode), DryRun: body.DryRun,
+	})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, templateApplication(item))
+}
+
+func templateLineInputs(values []api.BudgetTemplateLineRequest) []appbudgets.TemplateLineInput {
+	result := make([]appbudgets.TemplateLineInput, 0, len(values))
+	for _, value := range values {
+		result = append(result, appbudgets.TemplateLineInput{
+			Name: value.Name, AllocationAmount: value.AllocationAmount,
+			CategoryIDs: value.CategoryIDs, CategoryCodes: value.CategoryCodes, SortOrder: value.SortOrder,
+		})
+	}
+	return result
+}
+
+func template(item appbudgets.Template) api.BudgetTemplate {
+	result := api.BudgetTemplate{
+		ID: item.ID, HouseholdID: item.Owner.HouseholdID, UserID: item.Owner.UserID, Name: item.Name,
+		Lines: make([]api.BudgetTemplateLine, 0, len(item.Lines)),
+	}
+	for _, value := range item.Lines {
+		mapped := api.BudgetTemplateLine{
+			ID: value.ID, Name: value.Name, AllocationAmount: value.AllocationAmount, SortOrder: value.SortOrder,
+			Categories: make([]api.CategoryRef, 0, len(value.Categories)),
+		}
+		for _, category := range value.Categories {
+			mapped.Categories = append(mapped.Categories, api.CategoryRef{ID: category.ID, Code: category.Code, Name: category.Name})
+		}
+		result.Lines = append(result.Lines, mapped)
+	}
+	return result
+}
+
+func templateApplication(item appbudgets.ApplyTemplateResult) api.BudgetTemplateApplication {
+	result := api.BudgetTemplateApplication{
+		Budget: budget(item.Budget), Template: template(item.Template), Mode: string(item.Mode), Applied: item.Applied,
+		Changes: make([]api.BudgetTemplateChange, 0, len(item.Changes)),
+	}
+	for _, value := range item.Changes {
+		change := api.BudgetTemplateChange{Action: string(value.Action)}
+		if value.Before != nil {
+			before := line(*value.Before)
+			change.Before = &before
+		}
+		if value.After != nil {
+			after := line(*value.After)
+			change.After = &after
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	return result
+}
@@ -14,7 +14,7 @@ type Service interface {
 	List(context.Context, bool) ([]appcategories.Category, error)
 	GetByCode(context.Context, string) (appcategories.Category, error)
 	Update(context.Context, appcategories.UpdateInput) (appcategories.Category, error)
-	Deactivate(context.Context, string) (appcategories.Category, error)
+	Deactivate(ctx context.Context, code string, expectedVersion *int64) (appcategories.Category, error)
 }
 
 type Handler struct {
@@ -46,6 +46,7 @@ func (h *Handler) create(w http.ResponseWriter, request *http.Request) {
 		h.support.Fail(w, request, err)
 		return
 	}
+	httpapi.SetETag(w, item.Version)
 	httpapi.WriteJSON(w, http.StatusCreated, category(item))
 }
 
@@ -78,10 +79,16 @@ func (h *Handler) get(w http.ResponseWriter, request *http.Request) {
 		h.support.Fail(w, request, err)
 		return
 	}
+	httpapi.SetETag(w, item.Version)
 	httpapi.WriteJSON(w, http.StatusOK, category(item))
 }
 
 func (h *Handler) update(w http.ResponseWriter, request *http.Request) {
+	expectedVersion, err := httpapi.IfMatch(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
 	var body api.UpdateCategoryRequest
 	if !h.support.Decode(w, request, &body) {
 		return
@@ -91,23 +98,30 @@ func (h *Handler) update(w http.ResponseWriter, request *http.Request) {
 		httpapi.WriteValidationError(w, err.Error())
 		return
 	}
-	item, err := h.service.Update(request.Context(), appcategories.UpdateInput{Code: request.PathValue("code"), Name: body.Name, Description: description})
+	item, err := h.service.Update(request.Context(), appcategories.UpdateInput{Code: request.PathValue("code"), Name: body.Name, Description: description, ExpectedVersion: expectedVersion})
 	if err != nil {
 		h.support.Fail(w, request, err)
 		return
 	}
+	httpapi.SetETag(w, item.Version)
 	httpapi.WriteJSON(w, http.StatusOK, category(item))
 }
 
 func (h *Handler) deactivate(w http.ResponseWriter, request *http.Request) {
-	item, err := h.service.Deactivate(request.Context(), request.PathValue("code"))
+	expectedVersion, err := httpapi.IfMatch(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.Deactivate(request.Context(), request.PathValue("code"), expectedVersion)
 	if err != nil {
 		h.support.Fail(w, request, err)
 		return
 	}
+	httpapi.SetETag(w, item.Version)
 	httpapi.WriteJSON(w, http.StatusOK, category(item))
 }
 
 func category(item appcategories.Category) api.Category {
-	return api.Category{ID: item.ID, Code: item.Code, Name: item.Name, Description: item.Description, IsActive: item.IsActive}
+	return api.Category{ID: item.ID, Code: item.Code, Name: item.Name, Description: item.Description, IsActive: item.IsActive, Version: item.Version}
 }
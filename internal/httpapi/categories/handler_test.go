@@ -26,7 +26,7 @@ func (categoryServiceStub) List(context.Context, bool) ([]appcategories.Category
 func (categoryServiceStub) GetByCode(context.Context, string) (appcategories.Category, error) {
 	return appcategories.Category{ID: 1}, nil
 }
-func (categoryServiceStub) Deactivate(context.Context, string) (appcategories.Category, error) {
+func (categoryServiceStub) Deactivate(context.Context, string, *int64) (appcategories.Category, error) {
 	return appcategories.Category{ID: 1, IsActive: false}, nil
 }
 func TestUpdateRouteUsesCategoryCode(t *testing.T) {
@@ -65,6 +65,31 @@ func TestCategoryConflictMapsToConflict(t *testing.T) {
 		t.Fatalf("response = %d %s", response.Code, response.Body.String())
 	}
 }
+
+type versionedCategoryService struct{ categoryServiceStub }
+
+func (versionedCategoryService) Deactivate(_ context.Context, code string, expectedVersion *int64) (appcategories.Category, error) {
+	if expectedVersion != nil && *expectedVersion != 2 {
+		return appcategories.Category{}, apperrors.PreconditionFailed(apperrors.CodeVersionMismatch, "category version mismatch", nil)
+	}
+	return appcategories.Category{ID: 1, Code: code, Version: 3}, nil
+}
+func TestDeactivateHonorsIfMatch(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(versionedCategoryService{}).Register(router)
+	for ifMatch, want := range map[string]int{`"2"`: http.StatusOK, `"1"`: http.StatusPreconditionFailed, "2": http.StatusBadRequest} {
+		request := httptest.NewRequest(http.MethodDelete, "/v1/categories/food", nil)
+		request.Header.Set("If-Match", ifMatch)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		if response.Code != want {
+			t.Fatalf("If-Match %s = %d %s", ifMatch, response.Code, response.Body.String())
+		}
+		if want == http.StatusOK && response.Header().Get("ETag") != `"3"` {
+			t.Fatalf("ETag = %q", response.Header().Get("ETag"))
+		}
+	}
+}
 func TestListQueryModel(t *testing.T) {
 	query, err := listQuery(httptest.NewRequest(http.MethodGet, "/v1/categories?includeInactive=true", nil))
 	if err != nil || !query.IncludeInactive {
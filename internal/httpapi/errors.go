@@ -61,6 +61,10 @@ func MapApplicationError(err error) (int, api.ErrorResponse) {
 		return http.StatusNotFound, response
 	case apperrors.KindConflict:
 		return http.StatusConflict, response
+	case apperrors.KindPrecondition:
+		return http.StatusPreconditionFailed, response
+	case apperrors.KindUnprocessable:
+		return http.StatusUnprocessableEntity, response
 	default:
 		return safeInternalError()
 	}
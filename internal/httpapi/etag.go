@@ -0,0 +1,35 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ETag renders a row version as a strong entity tag.
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+func SetETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", ETag(version))
+}
+
+// IfMatch returns the version a conditional write expects. A missing header or
+// "*" places no precondition; weak tags and tag lists are rejected because a
+// row version identifies exactly one representation.
+func IfMatch(request *http.Request) (*int64, error) {
+	value := strings.TrimSpace(request.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+	if len(value) < 3 || value[0] != '"' || value[len(value)-1] != '"' {
+		return nil, errors.New(`If-Match must be a single strong ETag such as "3"`)
+	}
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version < 1 {
+		return nil, errors.New(`If-Match must be a single strong ETag such as "3"`)
+	}
+	return &version, nil
+}
@@ -0,0 +1,146 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"rdmm404/voltr-finance/internal/api"
+	appevents "rdmm404/voltr-finance/internal/app/events"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+const defaultHeartbeat = 15 * time.Second
+
+// globalTypes are events about records every household shares, which
+// household-filtered streams still receive. Other events without a household
+// concern one user's personal records and only reach unfiltered streams.
+var globalTypes = []appevents.Type{appevents.CategoryChanged}
+
+type Service interface {
+	Subscribe(lastID int64) *appevents.Subscription
+}
+
+type Handler struct {
+	service   Service
+	support   *httpapi.HandlerSupport
+	heartbeat time.Duration
+}
+
+func New(service Service, support ...*httpapi.HandlerSupport) *Handler {
+	return &Handler{service: service, support: httpapi.HandlerSupportOrDefault(support...), heartbeat: defaultHeartbeat}
+}
+
+func (h *Handler) Register(router *httpapi.Router) {
+	router.HandleFunc(http.MethodGet, api.EventsPath, h.stream)
+}
+
+// stream serves Server-Sent Events until the client disconnects or the
+// subscription ends. Each event's id is the Last-Event-ID to resume from, and
+// comment heartbeats keep idle connections from being cut by proxies.
+func (h *Handler) stream(w http.ResponseWriter, request *http.Request) {
+	householdID, err := httpapi.QueryInt64(request, "householdId")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	var lastID int64
+	if raw := request.Header.Get("Last-Event-ID"); raw != "" {
+		if lastID, err = strconv.ParseInt(raw, 10, 64); err != nil || lastID < 1 {
+			httpapi.WriteValidationError(w, "Last-Event-ID must be an event id")
+			return
+		}
+	}
+	subscription := h.service.Subscribe(lastID)
+	defer subscription.Close()
+	controller := http.NewResponseController(w)
+	// The stream outlives the server's write timeout.
+	_ = controller.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if subscription.Missed {
+		reset := api.Event{ID: strconv.FormatInt(subscription.Position, 10), Type: api.EventStreamReset, OccurredAt: time.Now().UTC()}
+		if writeEvent(w, reset) != nil {
+			return
+		}
+	}
+	for _, item := range subscription.Replay {
+		if matches(item, householdID) && writeEvent(w, event(item)) != nil {
+			return
+		}
+	}
+	if controller.Flush() != nil {
+		return
+	}
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case item, open := <-subscription.Events():
+			if !open {
+				return
+			}
+			if !matches(item, householdID) {
+				continue
+			}
+			if err := writeEvent(w, event(item)); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if controller.Flush() != nil {
+			return
+		}
+	}
+}
+
+func matches(item appevents.Event, householdID *int64) bool {
+	if householdID == nil {
+		return true
+	}
+	if item.PreviousHouseholdID != nil && *item.PreviousHouseholdID == *householdID {
+		return true
+	}
+	if item.HouseholdID == nil {
+		return slices.Contains(globalTypes, item.Type)
+	}
+	return *item.HouseholdID == *householdID
+}
+
+func writeEvent(w io.Writer, item api.Event) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", item.ID, item.Type, data)
+	return err
+}
+
+func event(item appevents.Event) api.Event {
+	result := api.Event{ID: strconv.FormatInt(item.ID, 10), Type: string(item.Type), OccurredAt: item.OccurredAt, HouseholdID: item.HouseholdID, PreviousHouseholdID: item.PreviousHouseholdID}
+	if item.TransactionID != 0 {
+		result.TransactionID = &item.TransactionID
+	}
+	if item.BudgetID != 0 {
+		result.BudgetID = &item.BudgetID
+	}
+	if item.BudgetLineID != 0 {
+		result.BudgetLineID = &item.BudgetLineID
+	}
+	if item.CategoryCode != "" {
+		result.CategoryCode = &item.CategoryCode
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	appevents "rdmm404/voltr-finance/internal/app/events"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+func TestStreamReplaysFiltersAndSendsHeartbeats(t *testing.T) {
+	bus := appevents.NewBus(10)
+	household, other := int64(2), int64(3)
+	bus.Publish(context.Background(), appevents.Event{Type: appevents.CategoryChanged, CategoryCode: "food"})
+	first := bus.Subscribe(0).Position
+	bus.Publish(context.Background(), appevents.Event{Type: appevents.TransactionCreated, HouseholdID: &other, TransactionID: 8})
+	bus.Publish(context.Background(), appevents.Event{Type: appevents.TransactionCreated, HouseholdID: &household, TransactionID: 9})
+	router := httpapi.NewRouter()
+	handler := New(bus)
+	handler.heartbeat = 20 * time.Millisecond
+	handler.Register(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/events?householdId=2", nil)
+	request.Header.Set("Last-Event-ID", "1")
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status=%d content-type=%s", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	lines := bufio.NewScanner(response.Body)
+	next := func() string {
+		for lines.Scan() {
+			if lines.Text() != "" {
+				return lines.Text()
+			}
+		}
+		t.Fatalf("stream ended: %v", lines.Err())
+		return ""
+	}
+	if next() != "id: "+itoa(first+2) || next() != "event: stream.reset" || !strings.Contains(next(), `"type":"stream.reset"`) {
+		t.Fatal("an unknown Last-Event-ID did not reset the stream")
+	}
+
+	request, _ = http.NewRequest(http.MethodGet, server.URL+"/v1/events?householdId=2", nil)
+	request.Header.Set("Last-Event-ID", itoa(first))
+	resumed, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Body.Close()
+	lines = bufio.NewScanner(resumed.Body)
+	if got := next(); got != "id: "+itoa(first+2) {
+		t.Fatalf("replay skipped the other household's event: %q", got)
+	}
+	if next() != "event: transaction.created" || !strings.Contains(next(), `"householdId":2,"transactionId":9`) {
+		t.Fatal("unexpected replayed event")
+	}
+	// Personal records have no household and stay off household streams.
+	bus.Publish(context.Background(), appevents.Event{Type: appevents.TransactionCreated, TransactionID: 10})
+	bus.Publish(context.Background(), appevents.Event{Type: appevents.BudgetLineChanged, BudgetID: 6, BudgetLineID: 7})
+	bus.Publish(context.Background(), appevents.Event{Type: appevents.BudgetLineChanged, HouseholdID: &household, BudgetID: 4, BudgetLineID: 5})
+	bus.Publish(context.Background(), appevents.Event{Type: appevents.CategoryChanged, CategoryCode: "rent"})
+	if got := next(); got != "id: "+itoa(first+5) {
+		t.Fatalf("personal events reached the household stream: %q", got)
+	}
+	if next() != "event: budget.line.changed" || !strings.Contains(next(), `"budgetId":4,"budgetLineId":5`) {
+		t.Fatal("unexpected live event")
+	}
+	if next() != "id: "+itoa(first+6) || next() != "event: category.changed" || !strings.Contains(next(), `"categoryCode":"rent"`) {
+		t.Fatal("global category event did not reach the household stream")
+	}
+	// A transaction moved to another household still reaches the one it left.
+	bus.Publish(context.Background(), appevents.Event{Type: appevents.TransactionUpdated, HouseholdID: &other, PreviousHouseholdID: &household, TransactionID: 9})
+	if next() != "id: "+itoa(first+7) || next() != "event: transaction.updated" || !strings.Contains(next(), `"householdId":3,"transactionId":9,"previousHouseholdId":2`) {
+		t.Fatal("a transaction moved out of the household did not reach its stream")
+	}
+	if got := next(); got != ": heartbeat" {
+		t.Fatalf("heartbeat=%q", got)
+	}
+}
+
+func TestStreamRejectsInvalidResumeIDs(t *testing.T) {
+	router := httpapi.NewRouter()
+	New(appevents.NewBus(1)).Register(router)
+	request := httptest.NewRequest(http.MethodGet, "/v1/events", nil)
+	request.Header.Set("Last-Event-ID", "yesterday")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("status=%d body=%s", response.Code, response.Body.String())
+	}
+}
+
+func itoa(value int64) string { return strconv.FormatInt(value, 10) }
@@ -0,0 +1,205 @@
+package goals
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"rdmm404/voltr-finance/internal/api"
+	appgoals "rdmm404/voltr-finance/internal/app/goals"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+type Service interface {
+	Create(context.Context, appgoals.CreateInput) (appgoals.Progress, error)
+	Get(context.Context, int64, time.Time) (appgoals.Progress, error)
+	List(context.Context, appgoals.ListFilter) ([]appgoals.Progress, error)
+	Update(context.Context, appgoals.UpdateInput) (appgoals.Progress, error)
+	Delete(context.Context, int64) error
+}
+
+type Handler struct {
+	service Service
+	support *httpapi.HandlerSupport
+}
+
+func New(service Service, support ...*httpapi.HandlerSupport) *Handler {
+	return &Handler{service: service, support: httpapi.HandlerSupportOrDefault(support...)}
+}
+
+func (h *Handler) Register(router *httpapi.Router) {
+	router.HandleFunc(http.MethodPost, api.GoalsPath, h.create)
+	router.HandleFunc(http.MethodGet, api.GoalsPath, h.list)
+	router.HandleFunc(http.MethodGet, api.GoalPath, h.get)
+	router.HandleFunc(http.MethodPatch, api.GoalPath, h.update)
+	router.HandleFunc(http.MethodDelete, api.GoalPath, h.delete)
+}
+
+func (h *Handler) create(w http.ResponseWriter, request *http.Request) {
+	var body api.CreateGoalRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	input, err := createInput(body)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.Create(request.Context(), input)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusCreated, goal(item))
+}
+
+func (h *Handler) list(w http.ResponseWriter, request *http.Request) {
+	query, err := goalQuery(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	asOf, err := parseDate("asOf", query.AsOf)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	items, err := h.service.List(request.Context(), appgoals.ListFilter{Owner: appgoals.Owner{HouseholdID: query.HouseholdID, UserID: query.UserID}, AsOf: dateOrZero(asOf)})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := make([]api.Goal, 0, len(items))
+	for _, item := range items {
+		response = append(response, goal(item))
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) get(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	asOf, err := parseDate("asOf", request.URL.Query().Get("asOf"))
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.Get(request.Context(), id, dateOrZero(asOf))
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, goal(item))
+}
+
+func (h *Handler) update(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	var body api.UpdateGoalRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	input := appgoals.UpdateInput{ID: id, Name: body.Name, TargetAmount: body.TargetAmount, CategoryIDs: body.CategoryIDs, CategoryCodes: body.CategoryCodes}
+	if body.StartDate != nil {
+		if input.StartDate, err = parseDate("startDate", *body.StartDate); err != nil {
+			httpapi.WriteValidationError(w, err.Error())
+			return
+		}
+	}
+	if body.TargetDate != nil {
+		if input.TargetDate, err = parseDate("targetDate", *body.TargetDate); err != nil {
+			httpapi.WriteValidationError(w, err.Error())
+			return
+		}
+	}
+	item, err := h.service.Update(request.Context(), input)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, goal(item))
+}
+
+func (h *Handler) delete(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	if err := h.service.Delete(request.Context(), id); err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func goalQuery(request *http.Request) (api.GoalQuery, error) {
+	householdID, err := httpapi.QueryInt64(request, "householdId")
+	if err != nil {
+		return api.GoalQuery{}, err
+	}
+	userID, err := httpapi.QueryInt64(request, "userId")
+	if err != nil {
+		return api.GoalQuery{}, err
+	}
+	return api.GoalQuery{HouseholdID: householdID, UserID: userID, AsOf: request.URL.Query().Get("asOf")}, nil
+}
+
+func createInput(body api.CreateGoalRequest) (appgoals.CreateInput, error) {
+	input := appgoals.CreateInput{
+		Owner: appgoals.Owner{HouseholdID: body.HouseholdID, UserID: body.UserID}, Name: body.Name, TargetAmount: body.TargetAmount,
+		CategoryIDs: body.CategoryIDs, CategoryCodes: body.CategoryCodes,
+	}
+	if body.StartDate != nil {
+		start, err := parseDate("startDate", *body.StartDate)
+		if err != nil {
+			return appgoals.CreateInput{}, err
+		}
+		input.StartDate = start
+	}
+	target, err := parseDate("targetDate", body.TargetDate)
+	if err != nil {
+		return appgoals.CreateInput{}, err
+	}
+	input.TargetDate = dateOrZero(target)
+	return input, nil
+}
+
+func parseDate(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must use YYYY-MM-DD", name)
+	}
+	return &parsed, nil
+}
+
+func dateOrZero(value *time.Time) time.Time {
+	if value == nil {
+		return time.Time{}
+	}
+	return *value
+}
+
+func goal(item appgoals.Progress) api.Goal {
+	result := api.Goal{
+		ID: item.ID, HouseholdID: item.Owner.HouseholdID, UserID: item.Owner.UserID, Name: item.Name, TargetAmount: item.TargetAmount,
+		StartDate: item.StartDate, TargetDate: item.TargetDate, Categories: make([]api.CategoryRef, 0, len(item.Categories)),
+		AsOf: item.AsOf, ContributedAmount: item.ContributedAmount, WithdrawnAmount: item.WithdrawnAmount,
+		BalanceAmount: item.BalanceAmount, RemainingAmount: item.RemainingAmount, PeriodNetAmount: item.PeriodNetAmount,
+		MonthsRemaining: item.MonthsRemaining, RequiredMonthlyAmount: item.RequiredMonthlyAmount, State: string(item.State),
+	}
+	for _, value := range item.Categories {
+		result.Categories = append(result.Categories, api.CategoryRef{ID: value.ID, Code: value.Code, Name: value.Name})
+	}
+	return result
+}
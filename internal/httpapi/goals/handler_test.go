@@ -0,0 +1,77 @@
+package goals
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	appgoals "rdmm404/voltr-finance/internal/app/goals"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+type goalServiceStub struct {
+	created appgoals.CreateInput
+	updated appgoals.UpdateInput
+	asOf    time.Time
+	filter  appgoals.ListFilter
+}
+
+func (s *goalServiceStub) Create(_ context.Context, input appgoals.CreateInput) (appgoals.Progress, error) {
+	s.created = input
+	return appgoals.Progress{Goal: appgoals.Goal{ID: 4, Owner: input.Owner, Name: input.Name, Categories: []appgoals.Category{{ID: 3, Code: "holiday-fund"}}}, State: appgoals.StateOnTrack}, nil
+}
+func (s *goalServiceStub) Get(_ context.Context, id int64, asOf time.Time) (appgoals.Progress, error) {
+	s.asOf = asOf
+	if id == 9 {
+		return appgoals.Progress{}, apperrors.NotFound(apperrors.CodeGoalNotFound, "savings goal not found", nil)
+	}
+	return appgoals.Progress{Goal: appgoals.Goal{ID: id}}, nil
+}
+func (s *goalServiceStub) List(_ context.Context, filter appgoals.ListFilter) ([]appgoals.Progress, error) {
+	s.filter = filter
+	return nil, nil
+}
+func (s *goalServiceStub) Update(_ context.Context, input appgoals.UpdateInput) (appgoals.Progress, error) {
+	s.updated = input
+	return appgoals.Progress{Goal: appgoals.Goal{ID: input.ID}}, nil
+}
+func (*goalServiceStub) Delete(context.Context, int64) error { return nil }
+
+func TestGoalRoutesParseDatesAndMapProgress(t *testing.T) {
+	service := &goalServiceStub{}
+	router := httpapi.NewRouter()
+	New(service).Register(router)
+	tests := []struct {
+		method, path, body string
+		status             int
+		contains           string
+	}{
+		{http.MethodPost, "/v1/goals", `{"householdId":2,"name":"Holidays","targetAmount":"1200","targetDate":"2027-06-30","categoryCodes":["holiday-fund"]}`, http.StatusCreated, `"state":"on_track"`},
+		{http.MethodPost, "/v1/goals", `{"userId":2,"name":"Holidays","targetAmount":"1200","targetDate":"30/06/2027"}`, http.StatusBadRequest, "targetDate must use YYYY-MM-DD"},
+		{http.MethodGet, "/v1/goals?householdId=2&asOf=2026-10-31", "", http.StatusOK, "[]"},
+		{http.MethodGet, "/v1/goals/4?asOf=2026-10-31", "", http.StatusOK, `"categories":[]`},
+		{http.MethodGet, "/v1/goals/9", "", http.StatusNotFound, "goal_not_found"},
+		{http.MethodPatch, "/v1/goals/4", `{"targetDate":"2027-07-31","categoryCodes":["travel"]}`, http.StatusOK, `"id":4`},
+		{http.MethodDelete, "/v1/goals/4", "", http.StatusNoContent, ""},
+	}
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(test.method, test.path, strings.NewReader(test.body)))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), test.contains) {
+			t.Errorf("%s %s = %d: %s", test.method, test.path, response.Code, response.Body.String())
+		}
+	}
+	if *service.created.Owner.HouseholdID != 2 || service.created.TargetDate != time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC) || service.created.StartDate != nil {
+		t.Fatalf("create input=%+v", service.created)
+	}
+	if *service.filter.Owner.HouseholdID != 2 || service.filter.AsOf != time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("list filter=%+v", service.filter)
+	}
+	if service.updated.TargetDate == nil || (*service.updated.CategoryCodes)[0] != "travel" {
+		t.Fatalf("update input=%+v", service.updated)
+	}
+}
@@ -2,6 +2,7 @@ package httpapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	appidempotency "rdmm404/voltr-finance/internal/app/idempotency"
 )
 
 func TestDecodeJSONIsStrictAndCollectionsAreArrays(t *testing.T) {
@@ -48,6 +50,8 @@ func TestApplicationErrorsMapWithoutLeakingInternalDetails(t *testing.T) {
 		{apperrors.Validation("bad input"), 400},
 		{apperrors.NotFound(apperrors.CodeUserNotFound, "user not found", nil), 404},
 		{apperrors.Conflict(apperrors.CodeCategoryConflict, "category conflict", nil), 409},
+		{apperrors.PreconditionFailed(apperrors.CodeVersionMismatch, "transaction version mismatch", nil), 412},
+		{apperrors.Unprocessable(apperrors.CodeIdempotencyKeyMismatch, "idempotency key was used for a different request", nil), 422},
 		{errors.New("password=database-secret"), 500},
 	}
 	for _, test := range tests {
@@ -73,6 +77,122 @@ func TestApplicationErrorsMapWithoutLeakingInternalDetails(t *testing.T) {
 	}
 }
 
+func TestIfMatchAcceptsOneStrongVersionTag(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int64
+		wantErr bool
+	}{
+		{header: ""},
+		{header: "*"},
+		{header: `"7"`, want: 7},
+		{header: ETag(42), want: 42},
+		{header: `W/"7"`, wantErr: true},
+		{header: `"1", "2"`, wantErr: true},
+		{header: `"0"`, wantErr: true},
+		{header: "7", wantErr: true},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodPatch, "/v1/test", nil)
+		if test.header != "" {
+			request.Header.Set("If-Match", test.header)
+		}
+		version, err := IfMatch(request)
+		if (err != nil) != test.wantErr {
+			t.Fatalf("IfMatch(%q) error=%v wantErr=%v", test.header, err, test.wantErr)
+		}
+		if test.want == 0 && version != nil || test.want != 0 && (version == nil || *version != test.want) {
+			t.Fatalf("IfMatch(%q)=%v want %d", test.header, version, test.want)
+		}
+	}
+}
+
+func TestIdempotencyReplaysStoredResponsesAndReleasesFailures(t *testing.T) {
+	store := &idempotencyStoreStub{records: map[string]idempotencyRecord{}}
+	calls := 0
+	handler := Idempotency(store, nil)(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		calls++
+		if request.URL.Path == "/v1/fail" {
+			WriteApplicationError(w, request, nil, errors.New("boom"))
+			return
+		}
+		var body map[string]any
+		if err := json.NewDecoder(request.Body).Decode(&body); request.Method == http.MethodPost && err != nil {
+			t.Errorf("handler could not read body: %v", err)
+		}
+		w.Header().Set("Location", "/v1/test/1")
+		WriteJSON(w, http.StatusCreated, map[string]int{"call": calls})
+	}))
+	send := func(method, path, key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		if key != "" {
+			request.Header.Set("Idempotency-Key", key)
+		}
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	first := send(http.MethodPost, "/v1/test", "k1", `{"amount":1}`)
+	replay := send(http.MethodPost, "/v1/test", "k1", `{"amount":1}`)
+	if first.Code != http.StatusCreated || replay.Code != http.StatusCreated || calls != 1 {
+		t.Fatalf("first=%d replay=%d calls=%d", first.Code, replay.Code, calls)
+	}
+	if replay.Body.String() != first.Body.String() || replay.Header().Get("Location") != "/v1/test/1" || replay.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("replay headers=%v body=%s", replay.Header(), replay.Body.String())
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("first response marked as replayed")
+	}
+	if mismatch := send(http.MethodPost, "/v1/test", "k1", `{"amount":2}`); mismatch.Code != http.StatusUnprocessableEntity || calls != 1 {
+		t.Fatalf("mismatch status=%d calls=%d", mismatch.Code, calls)
+	}
+	if failed := send(http.MethodPost, "/v1/fail", "k2", `{}`); failed.Code != http.StatusInternalServerError {
+		t.Fatalf("failed status=%d", failed.Code)
+	}
+	if _, ok := store.records["k2"]; ok {
+		t.Fatal("server error was stored")
+	}
+	send(http.MethodPost, "/v1/test", "", `{}`)
+	send(http.MethodGet, "/v1/test", "k3", "")
+	if calls != 4 || len(store.records) != 1 {
+		t.Fatalf("calls=%d stored=%d", calls, len(store.records))
+	}
+}
+
+type idempotencyRecord struct {
+	fingerprint string
+	response    *appidempotency.Response
+}
+
+type idempotencyStoreStub struct {
+	records map[string]idempotencyRecord
+}
+
+func (s *idempotencyStoreStub) Begin(_ context.Context, key, fingerprint string) (*appidempotency.Response, error) {
+	record, ok := s.records[key]
+	if !ok {
+		s.records[key] = idempotencyRecord{fingerprint: fingerprint}
+		return nil, nil
+	}
+	if record.fingerprint != fingerprint {
+		return nil, apperrors.Unprocessable(apperrors.CodeIdempotencyKeyMismatch, "mismatch", nil)
+	}
+	return record.response, nil
+}
+
+func (s *idempotencyStoreStub) Complete(_ context.Context, key string, response appidempotency.Response) error {
+	record := s.records[key]
+	record.response = &response
+	s.records[key] = record
+	return nil
+}
+
+func (s *idempotencyStoreStub) Release(_ context.Context, key string) error {
+	delete(s.records, key)
+	return nil
+}
+
 func TestBearerAPIKeyProtectsV1WithoutDisclosingKeys(t *testing.T) {
 	const configured = "configured-secret"
 	handler, err := NewHandler(configured, func(router *Router) {
@@ -0,0 +1,148 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"rdmm404/voltr-finance/internal/api"
+	appidempotency "rdmm404/voltr-finance/internal/app/idempotency"
+)
+
+// Middleware wraps the authenticated /v1 router.
+type Middleware func(http.Handler) http.Handler
+
+// IdempotencyStore remembers the responses of requests sent with an
+// Idempotency-Key.
+type IdempotencyStore interface {
+	Begin(ctx context.Context, key, fingerprint string) (*appidempotency.Response, error)
+	Complete(ctx context.Context, key string, response appidempotency.Response) error
+	Release(ctx context.Context, key string) error
+}
+
+// maxIdempotentBodyBytes bounds the request bodies the middleware buffers to
+// fingerprint. It leaves room for an attachment upload, the largest body any
+// handler accepts.
+const maxIdempotentBodyBytes = 16 << 20
+
+// replayedHeaders are the response headers stored with a response and sent
+// again when it is replayed.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests that carry an
+// Idempotency-Key run at most once. The request's method, URL and body
+// identify it; a retry with the same key gets the stored response back. Server
+// errors are not stored, so retrying after one runs the request again.
+func Idempotency(store IdempotencyStore, logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+			key := request.Header.Get(api.IdempotencyKeyHeader)
+			if key == "" || !mutating(request.Method) {
+				next.ServeHTTP(w, request)
+				return
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(w, request.Body, maxIdempotentBodyBytes))
+			if err != nil {
+				var maxBytesError *http.MaxBytesError
+				if errors.As(err, &maxBytesError) {
+					WriteValidationError(w, fmt.Sprintf("request body exceeds %d bytes", maxIdempotentBodyBytes))
+					return
+				}
+				WriteValidationError(w, "request body could not be read")
+				return
+			}
+			request.Body = io.NopCloser(bytes.NewReader(body))
+			replay, err := store.Begin(request.Context(), key, fingerprint(request, body))
+			if err != nil {
+				WriteApplicationError(w, request, logger, err)
+				return
+			}
+			if replay != nil {
+				writeReplay(w, *replay)
+				return
+			}
+			// The outcome is recorded even if the client has gone away, since
+			// that client is the one most likely to retry.
+			ctx := context.WithoutCancel(request.Context())
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				if !completed {
+					if err := store.Release(ctx, key); err != nil {
+						logger.Error("release idempotency key", "error", err)
+					}
+				}
+			}()
+			next.ServeHTTP(recorder, request)
+			if recorder.status >= http.StatusInternalServerError {
+				return
+			}
+			response := appidempotency.Response{StatusCode: recorder.status, Header: map[string]string{}, Body: recorder.body.Bytes()}
+			for _, name := range replayedHeaders {
+				if value := w.Header().Get(name); value != "" {
+					response.Header[name] = value
+				}
+			}
+			if err := store.Complete(ctx, key, response); err != nil {
+				logger.Error("complete idempotency key", "error", err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func fingerprint(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", request.Method, request.URL.RequestURI())
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func writeReplay(w http.ResponseWriter, response appidempotency.Response) {
+	for name, value := range response.Header {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(api.IdempotentReplayedHeader, "true")
+	w.WriteHeader(response.StatusCode)
+	_, _ = w.Write(response.Body)
+}
+
+// responseRecorder passes a response through while keeping a copy of its
+// status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,23 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+// RegisterOpenAPI serves the OpenAPI document for api.Routes at
+// api.OpenAPIPath. The document is encoded once, up front.
+func RegisterOpenAPI(router *Router) error {
+	document, err := json.Marshal(api.OpenAPI())
+	if err != nil {
+		return fmt.Errorf("encode OpenAPI document: %w", err)
+	}
+	router.HandleFunc(http.MethodGet, api.OpenAPIPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(document)
+	})
+	return nil
+}
@@ -34,6 +34,18 @@ func (r *Router) HandleFunc(method, path string, handler http.HandlerFunc) {
 	r.Handle(method, path, handler)
 }
 
+// Routes lists every registered operation as "METHOD path", sorted.
+func (r *Router) Routes() []string {
+	var routes []string
+	for path, methods := range r.methods {
+		for method := range methods {
+			routes = append(routes, method+" "+path)
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
 func (r *Router) ServeHTTP(w http.ResponseWriter, request *http.Request) {
 	_, pattern := r.mux.Handler(request)
 	if pattern != "" {
@@ -92,7 +104,7 @@ func (c Config) Validate() error {
 
 type RegisterRoutes func(*Router)
 
-func NewHandler(apiKey string, register RegisterRoutes) (http.Handler, error) {
+func NewHandler(apiKey string, register RegisterRoutes, middleware ...Middleware) (http.Handler, error) {
 	config := Config{APIKey: apiKey}
 	if err := config.Validate(); err != nil {
 		return nil, err
@@ -101,22 +113,29 @@ func NewHandler(apiKey string, register RegisterRoutes) (http.Handler, error) {
 	if register != nil {
 		register(apiRouter)
 	}
+	if err := RegisterOpenAPI(apiRouter); err != nil {
+		return nil, err
+	}
 	root := http.NewServeMux()
 	root.HandleFunc("GET "+api.LivePath, func(w http.ResponseWriter, _ *http.Request) {
 		WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 	})
-	authenticated := BearerAPIKey(apiKey, apiRouter)
+	var handler http.Handler = apiRouter
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	authenticated := BearerAPIKey(apiKey, handler)
 	root.Handle(api.APIPrefix, authenticated)
 	root.Handle(api.APIPrefix+"/", authenticated)
 	return root, nil
 }
 
-func NewServer(config Config, register RegisterRoutes) (*http.Server, error) {
+func NewServer(config Config, register RegisterRoutes, middleware ...Middleware) (*http.Server, error) {
 	config.setDefaults()
 	if err := config.Validate(); err != nil {
 		return nil, err
 	}
-	handler, err := NewHandler(config.APIKey, register)
+	handler, err := NewHandler(config.APIKey, register, middleware...)
 	if err != nil {
 		return nil, err
 	}
@@ -20,11 +20,18 @@ type Service interface {
 	CreateBatch(context.Context, []apptransactions.CreateInput) apptransactions.BulkResult
 	Get(context.Context, int64, bool) (apptransactions.Transaction, error)
 	GetMany(context.Context, []int64, bool) ([]apptransactions.Transaction, error)
-	List(context.Context, apptransactions.ListFilter) ([]apptransactions.Transaction, error)
+	List(context.Context, apptransactions.ListFilter) (apptransactions.Page, error)
+	Changes(context.Context, apptransactions.ChangesFilter) (apptransactions.ChangeSet, error)
+	TagTotals(context.Context, apptransactions.TagTotalsFilter) ([]apptransactions.TagTotal, error)
+	Aggregate(context.Context, apptransactions.AggregateFilter) (apptransactions.AggregateResult, error)
+	History(context.Context, int64) ([]apptransactions.HistoryEntry, error)
 	Update(context.Context, apptransactions.UpdateInput) (apptransactions.Transaction, error)
 	UpdateBatch(context.Context, []apptransactions.UpdateInput) apptransactions.BulkResult
-	DeleteBatch(context.Context, []int64, int64, *string) apptransactions.BulkResult
+	DeleteBatch(context.Context, []apptransactions.DeleteInput) apptransactions.BulkResult
 	RestoreBatch(context.Context, []int64, int64) apptransactions.BulkResult
+	SaveFilter(ctx context.Context, name, query string) (apptransactions.SavedFilter, error)
+	ListSavedFilters(context.Context) ([]apptransactions.SavedFilter, error)
+	DeleteSavedFilter(ctx context.Context, name string) error
 }
 
 type Handler struct {
@@ -43,8 +50,15 @@ func (h *Handler) Register(router *httpapi.Router) {
 	router.HandleFunc(http.MethodPost, api.TransactionsBulkPath, h.createBatch)
 	router.HandleFunc(http.MethodPatch, api.TransactionsBulkPath, h.updateBatch)
 	router.HandleFunc(http.MethodPost, api.TransactionsRestorePath, h.restoreBatch)
+	router.HandleFunc(http.MethodGet, api.TransactionChangesPath, h.changes)
+	router.HandleFunc(http.MethodGet, api.TransactionTagsPath, h.tagTotals)
+	router.HandleFunc(http.MethodGet, api.TransactionAggregatePath, h.aggregate)
 	router.HandleFunc(http.MethodGet, api.TransactionPath, h.get)
 	router.HandleFunc(http.MethodPatch, api.TransactionPath, h.update)
+	router.HandleFunc(http.MethodGet, api.TransactionHistoryPath, h.history)
+	router.HandleFunc(http.MethodGet, api.SavedFiltersPath, h.listSavedFilters)
+	router.HandleFunc(http.MethodPut, api.SavedFilterPath, h.saveFilter)
+	router.HandleFunc(http.MethodDelete, api.SavedFilterPath, h.deleteSavedFilter)
 }
 
 func (h *Handler) create(w http.ResponseWriter, request *http.Request) {
@@ -57,6 +71,7 @@ func (h *Handler) create(w http.ResponseWriter, request *http.Request) {
 		h.support.Fail(w, request, err)
 		return
 	}
+	httpapi.SetETag(w, item.Version)
 	httpapi.WriteJSON(w, http.StatusCreated, transaction(item))
 }
 
@@ -88,6 +103,7 @@ func (h *Handler) get(w http.ResponseWriter, request *http.Request) {
 		h.support.Fail(w, request, err)
 		return
 	}
+	httpapi.SetETag(w, item.Version)
 	httpapi.WriteJSON(w, http.StatusOK, transaction(item))
 }
 
@@ -98,19 +114,139 @@ func (h *Handler) list(w http.ResponseWriter, request *http.Request) {
 		return
 	}
 	filter := listInput(query)
-	var items []apptransactions.Transaction
+	var page apptransactions.Page
 	if len(query.IDs) > 0 {
-		items, err = h.service.GetMany(request.Context(), query.IDs, filter.IncludeDeleted)
+		page.Items, err = h.service.GetMany(request.Context(), query.IDs, filter.IncludeDeleted)
 	} else {
-		items, err = h.service.List(request.Context(), filter)
+		page, err = h.service.List(request.Context(), filter)
 	}
 	if err != nil {
 		h.support.Fail(w, request, err)
 		return
 	}
-	response := make([]api.Transaction, 0, len(items))
-	for _, item := range items {
-		response = append(response, transaction(item))
+	response := api.TransactionPage{Items: make([]api.Transaction, 0, len(page.Items)), NextCursor: page.NextCursor}
+	for _, item := range page.Items {
+		response.Items = append(response.Items, transaction(item))
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) changes(w http.ResponseWriter, request *http.Request) {
+	limit, err := httpapi.QueryInt(request, "limit", 500)
+	if err != nil || limit < 1 || limit > 1000 {
+		httpapi.WriteValidationError(w, "limit must be between 1 and 1000")
+		return
+	}
+	changes, err := h.service.Changes(request.Context(), apptransactions.ChangesFilter{Since: request.URL.Query().Get("since"), Limit: int32(limit)})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := api.TransactionChanges{Items: make([]api.Transaction, 0, len(changes.Items)), NextToken: changes.NextToken, HasMore: changes.HasMore}
+	for _, item := range changes.Items {
+		response.Items = append(response.Items, transaction(item))
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) tagTotals(w http.ResponseWriter, request *http.Request) {
+	filter, err := tagTotalsQuery(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	totals, err := h.service.TagTotals(request.Context(), filter)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := make([]api.TagTotal, 0, len(totals))
+	for _, total := range totals {
+		response = append(response, api.TagTotal(total))
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) aggregate(w http.ResponseWriter, request *http.Request) {
+	filter, err := aggregateQuery(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	result, err := h.service.Aggregate(request.Context(), filter)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := api.TransactionAggregate{By: make([]string, 0, len(result.By)), Metrics: make([]string, 0, len(result.Metrics)), Groups: make([]api.AggregateGroup, 0, len(result.Groups))}
+	for _, dimension := range result.By {
+		response.By = append(response.By, string(dimension))
+	}
+	for _, metric := range result.Metrics {
+		response.Metrics = append(response.Metrics, string(metric))
+	}
+	for _, group := range result.Groups {
+		item := api.AggregateGroup{Period: group.Period, AuthorID: group.AuthorID, AuthorName: group.AuthorName, HouseholdID: group.HouseholdID, HouseholdName: group.HouseholdName, Tag: group.Tag, Merchant: group.Merchant, Count: group.Count, Sum: group.Sum, Avg: group.Avg, Min: group.Min, Max: group.Max}
+		if group.Category != nil {
+			item.Category = &api.CategoryRef{ID: group.Category.ID, Code: group.Category.Code, Name: group.Category.Name}
+		}
+		response.Groups = append(response.Groups, item)
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) listSavedFilters(w http.ResponseWriter, request *http.Request) {
+	filters, err := h.service.ListSavedFilters(request.Context())
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := make([]api.SavedFilter, 0, len(filters))
+	for _, filter := range filters {
+		response = append(response, api.SavedFilter(filter))
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) saveFilter(w http.ResponseWriter, request *http.Request) {
+	var body api.SaveFilterRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	filter, err := h.service.SaveFilter(request.Context(), request.PathValue("name"), body.Query)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, api.SavedFilter(filter))
+}
+
+func (h *Handler) deleteSavedFilter(w http.ResponseWriter, request *http.Request) {
+	if err := h.service.DeleteSavedFilter(request.Context(), request.PathValue("name")); err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) history(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	entries, err := h.service.History(request.Context(), id)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := make([]api.TransactionHistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		item := api.TransactionHistoryEntry{ID: entry.ID, Action: string(entry.Action), ActorUserID: entry.ActorUserID, ActorName: entry.ActorName, ChangedAt: entry.ChangedAt, Changes: make([]api.TransactionFieldChange, 0, len(entry.Changes))}
+		for _, change := range entry.Changes {
+			item.Changes = append(item.Changes, api.TransactionFieldChange(change))
+		}
+		response = append(response, item)
 	}
 	httpapi.WriteJSON(w, http.StatusOK, response)
 }
@@ -121,6 +257,11 @@ func (h *Handler) update(w http.ResponseWriter, request *http.Request) {
 		httpapi.WriteValidationError(w, err.Error())
 		return
 	}
+	expectedVersion, err := httpapi.IfMatch(request)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
 	var body api.UpdateTransactionRequest
 	if !h.support.Decode(w, request, &body) {
 		return
@@ -130,11 +271,13 @@ func (h *Handler) update(w http.ResponseWriter, request *http.Request) {
 		httpapi.WriteValidationError(w, err.Error())
 		return
 	}
+	input.ExpectedVersion = expectedVersion
 	item, err := h.service.Update(request.Context(), input)
 	if err != nil {
 		h.support.Fail(w, request, err)
 		return
 	}
+	httpapi.SetETag(w, item.Version)
 	httpapi.WriteJSON(w, http.StatusOK, transaction(item))
 }
 
@@ -150,6 +293,7 @@ func (h *Handler) updateBatch(w http.ResponseWriter, request *http.Request) {
 			httpapi.WriteValidationError(w, err.Error())
 			return
 		}
+		input.ExpectedVersion = item.ExpectedVersion
 		inputs = append(inputs, input)
 	}
 	httpapi.WriteJSON(w, http.StatusOK, bulkResult(h.service.UpdateBatch(request.Context(), inputs)))
@@ -160,7 +304,18 @@ func (h *Handler) deleteBatch(w http.ResponseWriter, request *http.Request) {
 	if !h.support.Decode(w, request, &body) {
 		return
 	}
-	httpapi.WriteJSON(w, http.StatusOK, bulkResult(h.service.DeleteBatch(request.Context(), body.IDs, body.DeletedByUserID, body.Reason)))
+	if len(body.IDs) > 0 && len(body.Transactions) > 0 {
+		httpapi.WriteValidationError(w, "use either ids or transactions, not both")
+		return
+	}
+	inputs := make([]apptransactions.DeleteInput, 0, len(body.IDs)+len(body.Transactions))
+	for _, id := range body.IDs {
+		inputs = append(inputs, apptransactions.DeleteInput{ID: id, DeletedByUserID: body.DeletedByUserID, Reason: body.Reason})
+	}
+	for _, item := range body.Transactions {
+		inputs = append(inputs, apptransactions.DeleteInput{ID: item.ID, DeletedByUserID: body.DeletedByUserID, Reason: body.Reason, ExpectedVersion: item.ExpectedVersion})
+	}
+	httpapi.WriteJSON(w, http.StatusOK, bulkResult(h.service.DeleteBatch(request.Context(), inputs)))
 }
 
 func (h *Handler) restoreBatch(w http.ResponseWriter, request *http.Request) {
@@ -172,7 +327,7 @@ func (h *Handler) restoreBatch(w http.ResponseWriter, request *http.Request) {
 }
 
 func createInput(body api.CreateTransactionRequest) apptransactions.CreateInput {
-	return apptransactions.CreateInput{Amount: body.Amount, TransactionDate: body.TransactionDate, Description: body.Description, Notes: body.Notes, CategoryID: body.CategoryID, CategoryCode: body.CategoryCode, HouseholdID: body.HouseholdID, Author: identity(body.Author)}
+	return apptransactions.CreateInput{Amount: body.Amount, TransactionDate: body.TransactionDate, Description: body.Description, Notes: body.Notes, CategoryID: body.CategoryID, CategoryCode: body.CategoryCode, HouseholdID: body.HouseholdID, Author: identity(body.Author), Tags: body.Tags}
 }
 func updateInput(id int64, body api.UpdateTransactionRequest) (apptransactions.UpdateInput, error) {
 	description, err := httpapi.NullablePatch(body.Description, body.ClearDescription, "description")
@@ -196,7 +351,15 @@ func updateInput(id int64, body api.UpdateTransactionRequest) (apptransactions.U
 	} else if body.CategoryID != nil || body.CategoryCode != nil {
 		category = apppatch.Set(apptransactions.CategorySelector{ID: body.CategoryID, Code: body.CategoryCode})
 	}
-	input := apptransactions.UpdateInput{ID: id, Amount: body.Amount, TransactionDate: body.TransactionDate, Description: description, Notes: notes, Category: category, HouseholdID: householdID}
+	input := apptransactions.UpdateInput{ID: id, Amount: body.Amount, TransactionDate: body.TransactionDate, Description: description, Notes: notes, Category: category, HouseholdID: householdID, UpdatedByUserID: body.UpdatedByUserID}
+	if body.ClearTags && len(body.Tags) > 0 {
+		return apptransactions.UpdateInput{}, fmt.Errorf("tags and clearTags are mutually exclusive")
+	}
+	if body.ClearTags {
+		input.Tags = &[]string{}
+	} else if body.Tags != nil {
+		input.Tags = &body.Tags
+	}
 	if body.Author != nil {
 		value := identity(*body.Author)
 		input.Author = &value
@@ -208,10 +371,13 @@ func identity(value api.IdentitySelector) apptransactions.IdentitySelector {
 }
 
 func transaction(item apptransactions.Transaction) api.Transaction {
-	result := api.Transaction{ID: item.ID, Amount: item.Amount, TransactionDate: item.TransactionDate, AuthorID: item.AuthorID, AuthorName: item.AuthorName, HouseholdID: item.HouseholdID, HouseholdName: item.HouseholdName, Description: item.Description, Notes: item.Notes, CreatedAt: item.CreatedAt, UpdatedAt: item.UpdatedAt, DeletedAt: item.DeletedAt, DeleteReason: item.DeleteReason}
+	result := api.Transaction{ID: item.ID, Amount: item.Amount, TransactionDate: item.TransactionDate, AuthorID: item.AuthorID, AuthorName: item.AuthorName, HouseholdID: item.HouseholdID, HouseholdName: item.HouseholdName, Description: item.Description, Notes: item.Notes, CreatedAt: item.CreatedAt, UpdatedAt: item.UpdatedAt, DeletedAt: item.DeletedAt, DeleteReason: item.DeleteReason, Tags: item.Tags, Version: item.Version}
 	if item.Category != nil {
 		result.Category = &api.CategoryRef{ID: item.Category.ID, Code: item.Category.Code, Name: item.Category.Name}
 	}
+	if item.Match != nil {
+		result.Match = &api.SearchMatch{Rank: item.Match.Rank, Snippet: item.Match.Snippet}
+	}
 	return result
 }
 func bulkResult(result apptransactions.BulkResult) api.BulkResult {
@@ -260,6 +426,10 @@ func listQuery(request *http.Request) (api.ListTransactionsQuery, error) {
 	if err != nil || offset < 0 {
 		return api.ListTransactionsQuery{}, fmt.Errorf("offset must be a non-negative integer")
 	}
+	cursor := values.Get("cursor")
+	if cursor != "" && offset != 0 {
+		return api.ListTransactionsQuery{}, fmt.Errorf("cursor and offset cannot be combined")
+	}
 	includeDeleted, err := httpapi.QueryBool(request, "includeDeleted", false)
 	if err != nil {
 		return api.ListTransactionsQuery{}, err
@@ -268,6 +438,13 @@ func listQuery(request *http.Request) (api.ListTransactionsQuery, error) {
 	if err != nil {
 		return api.ListTransactionsQuery{}, err
 	}
+	tagMatch := values.Get("tagMatch")
+	if tagMatch == "" {
+		tagMatch = apptransactions.TagMatchAny
+	}
+	if !oneOf(tagMatch, apptransactions.TagMatchAny, apptransactions.TagMatchAll) {
+		return api.ListTransactionsQuery{}, fmt.Errorf("tagMatch must be any or all")
+	}
 	sortBy, order := values.Get("sort"), values.Get("sortOrder")
 	if sortBy == "" {
 		sortBy = "transaction_date"
@@ -275,7 +452,7 @@ func listQuery(request *http.Request) (api.ListTransactionsQuery, error) {
 	if order == "" {
 		order = "desc"
 	}
-	if !oneOf(sortBy, "transaction_date", "created_at", "amount", "id") {
+	if !oneOf(sortBy, "transaction_date", "created_at", "amount", "id", apptransactions.SortRelevance) {
 		return api.ListTransactionsQuery{}, fmt.Errorf("unsupported sort field")
 	}
 	if !oneOf(order, "asc", "desc") {
@@ -283,18 +460,91 @@ func listQuery(request *http.Request) (api.ListTransactionsQuery, error) {
 	}
 	return api.ListTransactionsQuery{
 		IDs: ids, AuthorID: authorID, HouseholdID: householdID, FromDate: from, ToDate: to,
-		Search: httpapi.QueryString(request, "search"), Sort: sortBy, SortOrder: order,
-		Limit: int32(limit), Offset: int32(offset), IncludeDeleted: includeDeleted, OnlyDeleted: onlyDeleted,
+		Search: httpapi.QueryString(request, "search"), Tags: splitList(values["tag"]), TagMatch: tagMatch, Query: values.Get("q"), Filter: values.Get("filter"), Sort: sortBy, SortOrder: order,
+		Limit: int32(limit), Offset: int32(offset), Cursor: cursor, IncludeDeleted: includeDeleted, OnlyDeleted: onlyDeleted,
 	}, nil
 }
 
 func listInput(query api.ListTransactionsQuery) apptransactions.ListFilter {
 	return apptransactions.ListFilter{
 		AuthorID: query.AuthorID, HouseholdID: query.HouseholdID, FromDate: query.FromDate, ToDate: query.ToDate,
-		Search: query.Search, Sort: query.Sort, SortOrder: query.SortOrder, Limit: query.Limit, Offset: query.Offset,
+		Search: query.Search, Tags: query.Tags, TagMatch: query.TagMatch, Query: query.Query, Filter: query.Filter, Sort: query.Sort, SortOrder: query.SortOrder, Limit: query.Limit, Offset: query.Offset, Cursor: query.Cursor,
 		IncludeDeleted: query.IncludeDeleted, OnlyDeleted: query.OnlyDeleted,
 	}
 }
+func tagTotalsQuery(request *http.Request) (apptransactions.TagTotalsFilter, error) {
+	authorID, err := httpapi.QueryInt64(request, "authorId")
+	if err != nil {
+		return apptransactions.TagTotalsFilter{}, err
+	}
+	householdID, err := httpapi.QueryInt64(request, "householdId")
+	if err != nil {
+		return apptransactions.TagTotalsFilter{}, err
+	}
+	from, err := parseTime(request.URL.Query().Get("fromDate"), "fromDate")
+	if err != nil {
+		return apptransactions.TagTotalsFilter{}, err
+	}
+	to, err := parseTime(request.URL.Query().Get("toDate"), "toDate")
+	if err != nil {
+		return apptransactions.TagTotalsFilter{}, err
+	}
+	return apptransactions.TagTotalsFilter{AuthorID: authorID, HouseholdID: householdID, FromDate: from, ToDate: to, Query: request.URL.Query().Get("q"), Filter: request.URL.Query().Get("filter")}, nil
+}
+
+func aggregateQuery(request *http.Request) (apptransactions.AggregateFilter, error) {
+	values := request.URL.Query()
+	authorID, err := httpapi.QueryInt64(request, "authorId")
+	if err != nil {
+		return apptransactions.AggregateFilter{}, err
+	}
+	householdID, err := httpapi.QueryInt64(request, "householdId")
+	if err != nil {
+		return apptransactions.AggregateFilter{}, err
+	}
+	from, err := parseTime(values.Get("fromDate"), "fromDate")
+	if err != nil {
+		return apptransactions.AggregateFilter{}, err
+	}
+	to, err := parseTime(values.Get("toDate"), "toDate")
+	if err != nil {
+		return apptransactions.AggregateFilter{}, err
+	}
+	includeDeleted, err := httpapi.QueryBool(request, "includeDeleted", false)
+	if err != nil {
+		return apptransactions.AggregateFilter{}, err
+	}
+	onlyDeleted, err := httpapi.QueryBool(request, "onlyDeleted", false)
+	if err != nil {
+		return apptransactions.AggregateFilter{}, err
+	}
+	filter := apptransactions.AggregateFilter{
+		AuthorID: authorID, HouseholdID: householdID, FromDate: from, ToDate: to,
+		Search: httpapi.QueryString(request, "search"), Tags: splitList(values["tag"]), TagMatch: values.Get("tagMatch"), Query: values.Get("q"), Filter: values.Get("filter"),
+		IncludeDeleted: includeDeleted, OnlyDeleted: onlyDeleted,
+	}
+	for _, dimension := range splitList(values["by"]) {
+		filter.By = append(filter.By, apptransactions.AggregateDimension(dimension))
+	}
+	for _, metric := range splitList(values["metrics"]) {
+		filter.Metrics = append(filter.Metrics, apptransactions.AggregateMetric(metric))
+	}
+	return filter, nil
+}
+
+// splitList reads a repeated query parameter whose values may also be
+// comma-separated.
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				items = append(items, part)
+			}
+		}
+	}
+	return items
+}
 func parseIDs(values []string) ([]int64, error) {
 	var ids []int64
 	for _, value := range values {
@@ -4,9 +4,12 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
 	apptransactions "rdmm404/voltr-finance/internal/app/transactions"
 	"rdmm404/voltr-finance/internal/httpapi"
 )
@@ -14,8 +17,17 @@ import (
 type transactionServiceStub struct {
 	create  func(context.Context, apptransactions.CreateInput) (apptransactions.Transaction, error)
 	get     func(context.Context, int64, bool) (apptransactions.Transaction, error)
-	list    func(context.Context, apptransactions.ListFilter) ([]apptransactions.Transaction, error)
+	list    func(context.Context, apptransactions.ListFilter) (apptransactions.Page, error)
 	getMany func(context.Context, []int64, bool) ([]apptransactions.Transaction, error)
+	changes func(context.Context, apptransactions.ChangesFilter) (apptransactions.ChangeSet, error)
+	totals  func(context.Context, apptransactions.TagTotalsFilter) ([]apptransactions.TagTotal, error)
+	groups  func(context.Context, apptransactions.AggregateFilter) (apptransactions.AggregateResult, error)
+	history func(context.Context, int64) ([]apptransactions.HistoryEntry, error)
+	update  func(context.Context, apptransactions.UpdateInput) (apptransactions.Transaction, error)
+	updates func(context.Context, []apptransactions.UpdateInput) apptransactions.BulkResult
+	deletes func(context.Context, []apptransactions.DeleteInput) apptransactions.BulkResult
+	save    func(context.Context, string, string) (apptransactions.SavedFilter, error)
+	remove  func(context.Context, string) error
 }
 
 func (s transactionServiceStub) Create(ctx context.Context, input apptransactions.CreateInput) (apptransactions.Transaction, error) {
@@ -27,11 +39,11 @@ func (s transactionServiceStub) Get(ctx context.Context, id int64, includeDelete
 	}
 	return apptransactions.Transaction{ID: 1}, nil
 }
-func (s transactionServiceStub) List(ctx context.Context, filter apptransactions.ListFilter) ([]apptransactions.Transaction, error) {
+func (s transactionServiceStub) List(ctx context.Context, filter apptransactions.ListFilter) (apptransactions.Page, error) {
 	if s.list != nil {
 		return s.list(ctx, filter)
 	}
-	return []apptransactions.Transaction{}, nil
+	return apptransactions.Page{Items: []apptransactions.Transaction{}}, nil
 }
 func (s transactionServiceStub) GetMany(ctx context.Context, ids []int64, includeDeleted bool) ([]apptransactions.Transaction, error) {
 	if s.getMany != nil {
@@ -39,21 +51,60 @@ func (s transactionServiceStub) GetMany(ctx context.Context, ids []int64, includ
 	}
 	return []apptransactions.Transaction{}, nil
 }
-func (transactionServiceStub) Update(_ context.Context, input apptransactions.UpdateInput) (apptransactions.Transaction, error) {
+func (s transactionServiceStub) Changes(ctx context.Context, filter apptransactions.ChangesFilter) (apptransactions.ChangeSet, error) {
+	if s.changes != nil {
+		return s.changes(ctx, filter)
+	}
+	return apptransactions.ChangeSet{Items: []apptransactions.Transaction{}, NextToken: "0"}, nil
+}
+func (s transactionServiceStub) TagTotals(ctx context.Context, filter apptransactions.TagTotalsFilter) ([]apptransactions.TagTotal, error) {
+	if s.totals != nil {
+		return s.totals(ctx, filter)
+	}
+	return []apptransactions.TagTotal{}, nil
+}
+func (s transactionServiceStub) Aggregate(ctx context.Context, filter apptransactions.AggregateFilter) (apptransactions.AggregateResult, error) {
+	return s.groups(ctx, filter)
+}
+func (s transactionServiceStub) History(ctx context.Context, id int64) ([]apptransactions.HistoryEntry, error) {
+	if s.history != nil {
+		return s.history(ctx, id)
+	}
+	return []apptransactions.HistoryEntry{}, nil
+}
+func (s transactionServiceStub) Update(ctx context.Context, input apptransactions.UpdateInput) (apptransactions.Transaction, error) {
+	if s.update != nil {
+		return s.update(ctx, input)
+	}
 	return apptransactions.Transaction{ID: input.ID}, nil
 }
 func (transactionServiceStub) CreateBatch(context.Context, []apptransactions.CreateInput) apptransactions.BulkResult {
 	return apptransactions.BulkResult{Succeeded: []apptransactions.Succeeded{{Index: 0, ID: 1}}}
 }
-func (transactionServiceStub) UpdateBatch(context.Context, []apptransactions.UpdateInput) apptransactions.BulkResult {
+func (s transactionServiceStub) UpdateBatch(ctx context.Context, inputs []apptransactions.UpdateInput) apptransactions.BulkResult {
+	if s.updates != nil {
+		return s.updates(ctx, inputs)
+	}
 	return apptransactions.BulkResult{Succeeded: []apptransactions.Succeeded{{Index: 0, ID: 1}}}
 }
-func (transactionServiceStub) DeleteBatch(context.Context, []int64, int64, *string) apptransactions.BulkResult {
+func (s transactionServiceStub) DeleteBatch(ctx context.Context, inputs []apptransactions.DeleteInput) apptransactions.BulkResult {
+	if s.deletes != nil {
+		return s.deletes(ctx, inputs)
+	}
 	return apptransactions.BulkResult{Succeeded: []apptransactions.Succeeded{{Index: 0, ID: 1}}}
 }
 func (transactionServiceStub) RestoreBatch(context.Context, []int64, int64) apptransactions.BulkResult {
 	return apptransactions.BulkResult{Succeeded: []apptransactions.Succeeded{{Index: 0, ID: 1}}}
 }
+func (s transactionServiceStub) SaveFilter(ctx context.Context, name, query string) (apptransactions.SavedFilter, error) {
+	return s.save(ctx, name, query)
+}
+func (transactionServiceStub) ListSavedFilters(context.Context) ([]apptransactions.SavedFilter, error) {
+	return []apptransactions.SavedFilter{}, nil
+}
+func (s transactionServiceStub) DeleteSavedFilter(ctx context.Context, name string) error {
+	return s.remove(ctx, name)
+}
 func TestCreateRoute(t *testing.T) {
 	stub := transactionServiceStub{create: func(_ context.Context, input apptransactions.CreateInput) (apptransactions.Transaction, error) {
 		if input.Amount != 12.34 || input.Author.UserID == nil || *input.Author.UserID != 7 {
@@ -86,6 +137,7 @@ func TestLifecycleRoutes(t *testing.T) {
 		{http.MethodPatch, "/v1/transactions/bulk", `{"transactions":[]}`},
 		{http.MethodDelete, "/v1/transactions", `{"ids":[1],"deletedByUserId":2}`},
 		{http.MethodPost, "/v1/transactions/restore", `{"ids":[1],"restoredByUserId":2}`},
+		{http.MethodGet, "/v1/transactions/1/history", ""},
 	}
 	for _, test := range tests {
 		request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
@@ -100,6 +152,62 @@ func TestLifecycleRoutes(t *testing.T) {
 	}
 }
 
+func TestUpdateHonorsIfMatchAndReturnsETag(t *testing.T) {
+	stub := transactionServiceStub{
+		get: func(_ context.Context, id int64, _ bool) (apptransactions.Transaction, error) {
+			return apptransactions.Transaction{ID: id, Version: 3}, nil
+		},
+		update: func(_ context.Context, input apptransactions.UpdateInput) (apptransactions.Transaction, error) {
+			if input.ExpectedVersion == nil {
+				return apptransactions.Transaction{ID: input.ID, Version: 1}, nil
+			}
+			if *input.ExpectedVersion != 3 {
+				return apptransactions.Transaction{}, apperrors.PreconditionFailed(apperrors.CodeVersionMismatch, "transaction version mismatch", nil)
+			}
+			return apptransactions.Transaction{ID: input.ID, Version: 4}, nil
+		},
+		updates: func(_ context.Context, inputs []apptransactions.UpdateInput) apptransactions.BulkResult {
+			if len(inputs) != 2 || inputs[0].ExpectedVersion == nil || *inputs[0].ExpectedVersion != 5 || inputs[1].ExpectedVersion != nil {
+				t.Fatalf("bulk inputs = %#v", inputs)
+			}
+			return apptransactions.BulkResult{}
+		},
+		deletes: func(_ context.Context, inputs []apptransactions.DeleteInput) apptransactions.BulkResult {
+			if len(inputs) != 2 || inputs[0].ExpectedVersion == nil || *inputs[0].ExpectedVersion != 6 || inputs[1].ExpectedVersion != nil || inputs[1].DeletedByUserID != 2 {
+				t.Fatalf("delete inputs = %#v", inputs)
+			}
+			return apptransactions.BulkResult{}
+		},
+	}
+	router := httpapi.NewRouter()
+	New(stub).Register(router)
+	tests := []struct {
+		method, path, ifMatch, body string
+		status                      int
+		etag                        string
+	}{
+		{http.MethodGet, "/v1/transactions/1", "", "", http.StatusOK, `"3"`},
+		{http.MethodPatch, "/v1/transactions/1", `"3"`, `{}`, http.StatusOK, `"4"`},
+		{http.MethodPatch, "/v1/transactions/1", "*", `{}`, http.StatusOK, `"1"`},
+		{http.MethodPatch, "/v1/transactions/1", `"2"`, `{}`, http.StatusPreconditionFailed, ""},
+		{http.MethodPatch, "/v1/transactions/1", `W/"3"`, `{}`, http.StatusBadRequest, ""},
+		{http.MethodPatch, "/v1/transactions/bulk", "", `{"transactions":[{"id":1,"expectedVersion":5},{"id":2}]}`, http.StatusOK, ""},
+		{http.MethodDelete, "/v1/transactions", "", `{"transactions":[{"id":1,"expectedVersion":6},{"id":2}],"deletedByUserId":2}`, http.StatusOK, ""},
+		{http.MethodDelete, "/v1/transactions", "", `{"ids":[3],"transactions":[{"id":1}],"deletedByUserId":2}`, http.StatusBadRequest, ""},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.ifMatch != "" {
+			request.Header.Set("If-Match", test.ifMatch)
+		}
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		if response.Code != test.status || response.Header().Get("ETag") != test.etag {
+			t.Errorf("%s %s If-Match %s = %d ETag %q: %s", test.method, test.path, test.ifMatch, response.Code, response.Header().Get("ETag"), response.Body.String())
+		}
+	}
+}
+
 func TestQueryModelsMapToTransactionInputs(t *testing.T) {
 	called := map[string]bool{}
 	stub := transactionServiceStub{
@@ -110,12 +218,12 @@ func TestQueryModelsMapToTransactionInputs(t *testing.T) {
 			}
 			return apptransactions.Transaction{ID: id}, nil
 		},
-		list: func(_ context.Context, filter apptransactions.ListFilter) ([]apptransactions.Transaction, error) {
+		list: func(_ context.Context, filter apptransactions.ListFilter) (apptransactions.Page, error) {
 			called["list"] = true
 			if filter.AuthorID == nil || *filter.AuthorID != 8 || filter.Search == nil || *filter.Search != "food" || filter.Sort != "amount" || filter.SortOrder != "asc" || filter.Limit != 25 || filter.Offset != 3 || !filter.OnlyDeleted {
 				t.Fatalf("list filter = %#v", filter)
 			}
-			return []apptransactions.Transaction{}, nil
+			return apptransactions.Page{Items: []apptransactions.Transaction{}}, nil
 		},
 		getMany: func(_ context.Context, ids []int64, includeDeleted bool) ([]apptransactions.Transaction, error) {
 			called["many"] = true
@@ -145,6 +253,199 @@ func TestQueryModelsMapToTransactionInputs(t *testing.T) {
 	}
 }
 
+func TestListReturnsAPageEnvelopeWithTheNextCursor(t *testing.T) {
+	var cursor string
+	router := httpapi.NewRouter()
+	New(transactionServiceStub{list: func(_ context.Context, filter apptransactions.ListFilter) (apptransactions.Page, error) {
+		cursor = filter.Cursor
+		return apptransactions.Page{Items: []apptransactions.Transaction{{ID: 7}}, NextCursor: "next"}, nil
+	}}).Register(router)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions?cursor=abc&limit=1", nil))
+	if response.Code != http.StatusOK || cursor != "abc" || !strings.Contains(response.Body.String(), `"items":[{"id":7,`) || !strings.Contains(response.Body.String(), `"nextCursor":"next"`) {
+		t.Fatalf("status=%d cursor=%q body=%s", response.Code, cursor, response.Body.String())
+	}
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions?cursor=abc&offset=2", nil))
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("cursor with offset status=%d", response.Code)
+	}
+}
+
+func TestSearchSortsByRelevanceAndRendersMatches(t *testing.T) {
+	var filter apptransactions.ListFilter
+	router := httpapi.NewRouter()
+	New(transactionServiceStub{list: func(_ context.Context, input apptransactions.ListFilter) (apptransactions.Page, error) {
+		filter = input
+		return apptransactions.Page{Items: []apptransactions.Transaction{{ID: 7, Match: &apptransactions.SearchMatch{Rank: 0.5, Snippet: "**Starbucks**"}}}}, nil
+	}}).Register(router)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions?search=starbuks&sort=relevance", nil))
+	body := response.Body.String()
+	if response.Code != http.StatusOK || filter.Sort != apptransactions.SortRelevance || filter.Search == nil || *filter.Search != "starbuks" || !strings.Contains(body, `"match":{"rank":0.5,"snippet":"**Starbucks**"}`) {
+		t.Fatalf("status=%d filter=%+v body=%s", response.Code, filter, body)
+	}
+}
+
+func TestAggregateParsesDimensionsAndRendersGroups(t *testing.T) {
+	var filter apptransactions.AggregateFilter
+	month := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	count, sum := int64(3), "41.50"
+	router := httpapi.NewRouter()
+	New(transactionServiceStub{groups: func(_ context.Context, input apptransactions.AggregateFilter) (apptransactions.AggregateResult, error) {
+		filter = input
+		return apptransactions.AggregateResult{
+			By:      []apptransactions.AggregateDimension{apptransactions.DimensionCategory, apptransactions.DimensionMonth},
+			Metrics: []apptransactions.AggregateMetric{apptransactions.MetricSum, apptransactions.MetricCount},
+			Groups:  []apptransactions.AggregateGroup{{Period: &month, Category: &apptransactions.CategoryRef{ID: 2, Code: "groceries", Name: "Groceries"}, Count: &count, Sum: &sum}},
+		}, nil
+	}}).Register(router)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions/aggregate?by=category,month&metrics=sum&metrics=count&householdId=3&tag=trip&q=amount%20%3E%2010", nil))
+	body := response.Body.String()
+	want := `{"by":["category","month"],"metrics":["sum","count"],"groups":[{"period":"2026-05-01T00:00:00Z","category":{"id":2,"code":"groceries","name":"Groceries"},"count":3,"sum":"41.50"}]}`
+	if response.Code != http.StatusOK || strings.TrimSpace(body) != want {
+		t.Fatalf("status=%d body=%s", response.Code, body)
+	}
+	wantBy := []apptransactions.AggregateDimension{apptransactions.DimensionCategory, apptransactions.DimensionMonth}
+	wantMetrics := []apptransactions.AggregateMetric{apptransactions.MetricSum, apptransactions.MetricCount}
+	if !slices.Equal(filter.By, wantBy) || !slices.Equal(filter.Metrics, wantMetrics) || filter.HouseholdID == nil || *filter.HouseholdID != 3 || !slices.Equal(filter.Tags, []string{"trip"}) || filter.Query != "amount > 10" {
+		t.Fatalf("filter=%+v", filter)
+	}
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions/aggregate?fromDate=yesterday", nil))
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("bad date status=%d", response.Code)
+	}
+}
+
+func TestChangesRouteIsNotTakenForATransactionID(t *testing.T) {
+	var filter apptransactions.ChangesFilter
+	deletedAt := time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC)
+	router := httpapi.NewRouter()
+	New(transactionServiceStub{changes: func(_ context.Context, input apptransactions.ChangesFilter) (apptransactions.ChangeSet, error) {
+		filter = input
+		return apptransactions.ChangeSet{Items: []apptransactions.Transaction{{ID: 4, DeletedAt: &deletedAt}}, NextToken: "12", HasMore: true}, nil
+	}}).Register(router)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions/changes?since=9&limit=50", nil))
+	body := response.Body.String()
+	if response.Code != http.StatusOK || filter.Since != "9" || filter.Limit != 50 || !strings.Contains(body, `"deletedAt":"2026-06-08T00:00:00Z"`) || !strings.Contains(body, `"nextToken":"12","hasMore":true`) {
+		t.Fatalf("status=%d filter=%+v body=%s", response.Code, filter, body)
+	}
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions/changes?limit=5000", nil))
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("oversized limit status=%d", response.Code)
+	}
+}
+
+func TestTagsFilterListsAndTotalRoute(t *testing.T) {
+	var filter apptransactions.ListFilter
+	var totalsFilter apptransactions.TagTotalsFilter
+	var update apptransactions.UpdateInput
+	router := httpapi.NewRouter()
+	New(transactionServiceStub{
+		list: func(_ context.Context, input apptransactions.ListFilter) (apptransactions.Page, error) {
+			filter = input
+			return apptransactions.Page{Items: []apptransactions.Transaction{{ID: 7, Tags: []string{"reimbursable", "trip-japan-2026"}}}}, nil
+		},
+		totals: func(_ context.Context, input apptransactions.TagTotalsFilter) ([]apptransactions.TagTotal, error) {
+			totalsFilter = input
+			return []apptransactions.TagTotal{{Tag: "reimbursable", Count: 2, Total: "41.50"}}, nil
+		},
+		update: func(_ context.Context, input apptransactions.UpdateInput) (apptransactions.Transaction, error) {
+			update = input
+			return apptransactions.Transaction{ID: input.ID}, nil
+		},
+	}).Register(router)
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions?tag=reimbursable,trip-japan-2026&tag=tax-deductible&tagMatch=all", nil))
+	if response.Code != http.StatusOK || strings.Join(filter.Tags, " ") != "reimbursable trip-japan-2026 tax-deductible" || filter.TagMatch != apptransactions.TagMatchAll || !strings.Contains(response.Body.String(), `"tags":["reimbursable","trip-japan-2026"]`) {
+		t.Fatalf("status=%d filter=%+v body=%s", response.Code, filter, response.Body.String())
+	}
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions?tag=a&tagMatch=some", nil))
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("tagMatch status=%d", response.Code)
+	}
+
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions/tags?householdId=2&fromDate=2026-06-01T00:00:00Z", nil))
+	if response.Code != http.StatusOK || totalsFilter.HouseholdID == nil || *totalsFilter.HouseholdID != 2 || totalsFilter.FromDate == nil || response.Body.String() != `[{"tag":"reimbursable","count":2,"total":"41.50"}]`+"\n" {
+		t.Fatalf("status=%d filter=%+v body=%q", response.Code, totalsFilter, response.Body.String())
+	}
+
+	for body, want := range map[string]*[]string{`{"amount":2}`: nil, `{"tags":["travel"]}`: {"travel"}, `{"clearTags":true}`: {}} {
+		response = httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodPatch, "/v1/transactions/3", strings.NewReader(body)))
+		if response.Code != http.StatusOK || (want == nil) != (update.Tags == nil) || (want != nil && strings.Join(*want, ",") != strings.Join(*update.Tags, ",")) {
+			t.Fatalf("body %s status=%d tags=%v", body, response.Code, update.Tags)
+		}
+	}
+}
+
+func TestQueryParametersAndSavedFilterRoutes(t *testing.T) {
+	var filter apptransactions.ListFilter
+	var totalsFilter apptransactions.TagTotalsFilter
+	var saved, deleted string
+	router := httpapi.NewRouter()
+	New(transactionServiceStub{
+		list: func(_ context.Context, input apptransactions.ListFilter) (apptransactions.Page, error) {
+			filter = input
+			return apptransactions.Page{Items: []apptransactions.Transaction{}}, nil
+		},
+		totals: func(_ context.Context, input apptransactions.TagTotalsFilter) ([]apptransactions.TagTotal, error) {
+			totalsFilter = input
+			return []apptransactions.TagTotal{}, nil
+		},
+		save: func(_ context.Context, name, query string) (apptransactions.SavedFilter, error) {
+			saved = name + ": " + query
+			return apptransactions.SavedFilter{Name: name, Query: query, CreatedAt: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC)}, nil
+		},
+		remove: func(_ context.Context, name string) error {
+			if name != "big-spend" {
+				return apperrors.NotFound(apperrors.CodeSavedFilterNotFound, "saved filter not found", nil)
+			}
+			deleted = name
+			return nil
+		},
+	}).Register(router)
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions?q=amount+%3E+50+and+not+tag%3Areimbursable&filter=big-spend", nil))
+	if response.Code != http.StatusOK || filter.Query != "amount > 50 and not tag:reimbursable" || filter.Filter != "big-spend" {
+		t.Fatalf("status=%d filter=%+v", response.Code, filter)
+	}
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions/tags?q=category+%3D+travel&filter=trips", nil))
+	if response.Code != http.StatusOK || totalsFilter.Query != "category = travel" || totalsFilter.Filter != "trips" {
+		t.Fatalf("status=%d filter=%+v", response.Code, totalsFilter)
+	}
+
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodPut, "/v1/saved-filters/big-spend", strings.NewReader(`{"query":"amount > 100"}`)))
+	if response.Code != http.StatusOK || saved != "big-spend: amount > 100" || response.Body.String() != `{"name":"big-spend","query":"amount > 100","createdAt":"2026-06-01T00:00:00Z","updatedAt":"2026-06-02T00:00:00Z"}`+"\n" {
+		t.Fatalf("status=%d saved=%q body=%s", response.Code, saved, response.Body.String())
+	}
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/saved-filters", nil))
+	if response.Code != http.StatusOK || response.Body.String() != "[]\n" {
+		t.Fatalf("list status=%d body=%s", response.Code, response.Body.String())
+	}
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/v1/saved-filters/big-spend", nil))
+	if response.Code != http.StatusNoContent || deleted != "big-spend" {
+		t.Fatalf("delete status=%d deleted=%q", response.Code, deleted)
+	}
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/v1/saved-filters/missing", nil))
+	if response.Code != http.StatusNotFound || !strings.Contains(response.Body.String(), "saved_filter_not_found") {
+		t.Fatalf("missing status=%d body=%s", response.Code, response.Body.String())
+	}
+}
+
 func TestUpdateRejectsContradictoryNullableFields(t *testing.T) {
 	router := httpapi.NewRouter()
 	New(transactionServiceStub{}).Register(router)
@@ -153,6 +454,7 @@ func TestUpdateRejectsContradictoryNullableFields(t *testing.T) {
 		`{"notes":"set","clearNotes":true}`,
 		`{"categoryId":1,"clearCategoryId":true}`,
 		`{"householdId":1,"clearHouseholdId":true}`,
+		`{"tags":["travel"],"clearTags":true}`,
 	} {
 		response := httptest.NewRecorder()
 		router.ServeHTTP(response, httptest.NewRequest(http.MethodPatch, "/v1/transactions/1", strings.NewReader(body)))
@@ -172,3 +474,25 @@ func TestCreateRejectsUnknownFields(t *testing.T) {
 		t.Fatalf("response = %d %s", response.Code, response.Body.String())
 	}
 }
+
+func TestHistoryRouteRendersFieldChanges(t *testing.T) {
+	actor, name := int64(7), "Ana"
+	stub := transactionServiceStub{history: func(_ context.Context, id int64) ([]apptransactions.HistoryEntry, error) {
+		if id != 4 {
+			t.Fatalf("history id = %d", id)
+		}
+		return []apptransactions.HistoryEntry{{
+			ID: 2, TransactionID: id, Action: apptransactions.ActionUpdated, ActorUserID: &actor, ActorName: &name,
+			ChangedAt: time.Date(2026, 6, 10, 9, 0, 0, 0, time.UTC),
+			Changes:   []apptransactions.FieldChange{{Field: "amount", Before: float32(4.25), After: float32(5)}, {Field: "categoryId", Before: int64(42)}},
+		}}, nil
+	}}
+	router := httpapi.NewRouter()
+	New(stub).Register(router)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v1/transactions/4/history", nil))
+	want := `[{"id":2,"action":"updated","actorUserId":7,"actorName":"Ana","changedAt":"2026-06-10T09:00:00Z","changes":[{"field":"amount","before":4.25,"after":5},{"field":"categoryId","before":42,"after":null}]}]`
+	if response.Code != http.StatusOK || strings.TrimSpace(response.Body.String()) != want {
+		t.Fatalf("response = %d %s", response.Code, response.Body.String())
+	}
+}
@@ -0,0 +1,194 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+
+	"rdmm404/voltr-finance/internal/api"
+	appwebhooks "rdmm404/voltr-finance/internal/app/webhooks"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+type Service interface {
+	Create(context.Context, appwebhooks.CreateInput) (appwebhooks.Webhook, error)
+	Get(context.Context, int64) (appwebhooks.Webhook, error)
+	List(context.Context, appwebhooks.ListFilter) ([]appwebhooks.Webhook, error)
+	Update(context.Context, appwebhooks.UpdateInput) (appwebhooks.Webhook, error)
+	Delete(context.Context, int64) error
+	ListDeliveries(ctx context.Context, webhookID int64, limit int32) ([]appwebhooks.Delivery, error)
+	Redeliver(context.Context, int64) (appwebhooks.Delivery, error)
+}
+
+type Handler struct {
+	service Service
+	support *httpapi.HandlerSupport
+}
+
+func New(service Service, support ...*httpapi.HandlerSupport) *Handler {
+	return &Handler{service: service, support: httpapi.HandlerSupportOrDefault(support...)}
+}
+
+func (h *Handler) Register(router *httpapi.Router) {
+	router.HandleFunc(http.MethodPost, api.WebhooksPath, h.create)
+	router.HandleFunc(http.MethodGet, api.WebhooksPath, h.list)
+	router.HandleFunc(http.MethodGet, api.WebhookPath, h.get)
+	router.HandleFunc(http.MethodPatch, api.WebhookPath, h.update)
+	router.HandleFunc(http.MethodDelete, api.WebhookPath, h.delete)
+	router.HandleFunc(http.MethodGet, api.WebhookDeliveriesPath, h.deliveries)
+	router.HandleFunc(http.MethodPost, api.WebhookDeliveryRedeliverPath, h.redeliver)
+}
+
+// create is the only response that includes the secret, so a generated one
+// can be copied into the receiver once.
+func (h *Handler) create(w http.ResponseWriter, request *http.Request) {
+	var body api.CreateWebhookRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	input := appwebhooks.CreateInput{URL: body.URL, EventTypes: body.EventTypes, HouseholdID: body.HouseholdID}
+	if body.Secret != nil {
+		input.Secret = *body.Secret
+	}
+	item, err := h.service.Create(request.Context(), input)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := webhook(item)
+	response.Secret = item.Secret
+	httpapi.WriteJSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) list(w http.ResponseWriter, request *http.Request) {
+	householdID, err := httpapi.QueryInt64(request, "householdId")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	items, err := h.service.List(request.Context(), appwebhooks.ListFilter{HouseholdID: householdID})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := make([]api.Webhook, 0, len(items))
+	for _, item := range items {
+		response = append(response, webhook(item))
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) get(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.Get(request.Context(), id)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, webhook(item))
+}
+
+func (h *Handler) update(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	var body api.UpdateWebhookRequest
+	if !h.support.Decode(w, request, &body) {
+		return
+	}
+	householdID, err := httpapi.NullablePatch(body.HouseholdID, body.ClearHouseholdID, "householdId")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.Update(request.Context(), appwebhooks.UpdateInput{
+		ID: id, URL: body.URL, EventTypes: body.EventTypes, HouseholdID: householdID, Secret: body.Secret, Active: body.Active,
+	})
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, webhook(item))
+}
+
+func (h *Handler) delete(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	if err := h.service.Delete(request.Context(), id); err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deliveries(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	limit, err := httpapi.QueryInt(request, "limit", 0)
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	items, err := h.service.ListDeliveries(request.Context(), id, int32(limit))
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	response := make([]api.WebhookDelivery, 0, len(items))
+	for _, item := range items {
+		response = append(response, delivery(item))
+	}
+	httpapi.WriteJSON(w, http.StatusOK, response)
+}
+
+// redeliver answers 202 because the worker sends the delivery on its next
+// pass rather than during the request.
+func (h *Handler) redeliver(w http.ResponseWriter, request *http.Request) {
+	id, err := httpapi.ParsePathID(request, "id")
+	if err != nil {
+		httpapi.WriteValidationError(w, err.Error())
+		return
+	}
+	item, err := h.service.Redeliver(request.Context(), id)
+	if err != nil {
+		h.support.Fail(w, request, err)
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusAccepted, delivery(item))
+}
+
+func webhook(item appwebhooks.Webhook) api.Webhook {
+	types := item.EventTypes
+	if types == nil {
+		types = []string{}
+	}
+	return api.Webhook{
+		ID: item.ID, URL: item.URL, EventTypes: types, HouseholdID: item.HouseholdID, Active: item.Active,
+		CreatedAt: item.CreatedAt, UpdatedAt: item.UpdatedAt,
+	}
+}
+
+func delivery(item appwebhooks.Delivery) api.WebhookDelivery {
+	result := api.WebhookDelivery{
+		ID: item.ID, WebhookID: item.WebhookID, EventID: item.EventID, EventType: item.EventType, OccurredAt: item.OccurredAt,
+		Status: string(item.Status), Attempts: item.Attempts, NextAttemptAt: item.NextAttemptAt, LastError: item.LastError,
+		DeliveredAt: item.DeliveredAt, CreatedAt: item.CreatedAt, History: make([]api.WebhookDeliveryAttempt, 0, len(item.History)),
+	}
+	for _, attempt := range item.History {
+		result.History = append(result.History, api.WebhookDeliveryAttempt{
+			AttemptedAt: attempt.AttemptedAt, StatusCode: attempt.StatusCode, Error: attempt.Error, DurationMs: attempt.Duration.Milliseconds(),
+		})
+	}
+	return result
+}
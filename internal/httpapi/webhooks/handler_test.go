@@ -0,0 +1,94 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	appwebhooks "rdmm404/voltr-finance/internal/app/webhooks"
+	"rdmm404/voltr-finance/internal/httpapi"
+)
+
+type webhookServiceStub struct {
+	created     appwebhooks.CreateInput
+	updated     appwebhooks.UpdateInput
+	filter      appwebhooks.ListFilter
+	limit       int32
+	redelivered int64
+}
+
+func (s *webhookServiceStub) Create(_ context.Context, input appwebhooks.CreateInput) (appwebhooks.Webhook, error) {
+	s.created = input
+	return appwebhooks.Webhook{ID: 5, URL: input.URL, Secret: "generated-secret-value", Active: true}, nil
+}
+func (s *webhookServiceStub) Get(_ context.Context, id int64) (appwebhooks.Webhook, error) {
+	if id == 9 {
+		return appwebhooks.Webhook{}, apperrors.NotFound(apperrors.CodeWebhookNotFound, "webhook not found", nil)
+	}
+	return appwebhooks.Webhook{ID: id, Secret: "stored-secret-value"}, nil
+}
+func (s *webhookServiceStub) List(_ context.Context, filter appwebhooks.ListFilter) ([]appwebhooks.Webhook, error) {
+	s.filter = filter
+	return nil, nil
+}
+func (s *webhookServiceStub) Update(_ context.Context, input appwebhooks.UpdateInput) (appwebhooks.Webhook, error) {
+	s.updated = input
+	return appwebhooks.Webhook{ID: input.ID}, nil
+}
+func (*webhookServiceStub) Delete(context.Context, int64) error { return nil }
+func (s *webhookServiceStub) ListDeliveries(_ context.Context, webhookID int64, limit int32) ([]appwebhooks.Delivery, error) {
+	s.limit = limit
+	code, failure := int32(503), "webhook responded with status 503"
+	return []appwebhooks.Delivery{{
+		ID: 11, WebhookID: webhookID, EventType: "transaction.created", Status: appwebhooks.DeliveryPending, Attempts: 1, LastError: &failure,
+		History: []appwebhooks.Attempt{{StatusCode: &code, Error: &failure, Duration: 250 * time.Millisecond}},
+	}}, nil
+}
+func (s *webhookServiceStub) Redeliver(_ context.Context, id int64) (appwebhooks.Delivery, error) {
+	s.redelivered = id
+	return appwebhooks.Delivery{ID: id, Status: appwebhooks.DeliveryPending}, nil
+}
+
+func TestWebhookRoutesHideSecretsAfterCreate(t *testing.T) {
+	service := &webhookServiceStub{}
+	router := httpapi.NewRouter()
+	New(service).Register(router)
+	tests := []struct {
+		method, path, body string
+		status             int
+		contains           string
+	}{
+		{http.MethodPost, "/v1/webhooks", `{"url":"https://hooks.example.com","eventTypes":["transaction.created"],"householdId":2}`, http.StatusCreated, `"secret":"generated-secret-value"`},
+		{http.MethodGet, "/v1/webhooks?householdId=2", "", http.StatusOK, "[]"},
+		{http.MethodGet, "/v1/webhooks/5", "", http.StatusOK, `"eventTypes":[]`},
+		{http.MethodGet, "/v1/webhooks/9", "", http.StatusNotFound, "webhook_not_found"},
+		{http.MethodPatch, "/v1/webhooks/5", `{"active":false,"clearHouseholdId":true}`, http.StatusOK, `"id":5`},
+		{http.MethodPatch, "/v1/webhooks/5", `{"householdId":2,"clearHouseholdId":true}`, http.StatusBadRequest, "mutually exclusive"},
+		{http.MethodDelete, "/v1/webhooks/5", "", http.StatusNoContent, ""},
+		{http.MethodGet, "/v1/webhooks/5/deliveries?limit=5", "", http.StatusOK, `"history":[{"attemptedAt":"0001-01-01T00:00:00Z","statusCode":503,"error":"webhook responded with status 503","durationMs":250}]`},
+		{http.MethodPost, "/v1/webhook-deliveries/11/redeliver", "", http.StatusAccepted, `"status":"pending"`},
+	}
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(test.method, test.path, strings.NewReader(test.body)))
+		if response.Code != test.status || !strings.Contains(response.Body.String(), test.contains) {
+			t.Errorf("%s %s = %d: %s", test.method, test.path, response.Code, response.Body.String())
+		}
+		if test.method == http.MethodGet && strings.Contains(response.Body.String(), "secret") {
+			t.Errorf("%s %s exposed the secret: %s", test.method, test.path, response.Body.String())
+		}
+	}
+	if service.created.URL != "https://hooks.example.com" || *service.created.HouseholdID != 2 || service.created.Secret != "" {
+		t.Fatalf("create input=%+v", service.created)
+	}
+	if *service.filter.HouseholdID != 2 || service.limit != 5 || service.redelivered != 11 {
+		t.Fatalf("filter=%+v limit=%d redelivered=%d", service.filter, service.limit, service.redelivered)
+	}
+	if !service.updated.HouseholdID.Present() || service.updated.HouseholdID.Value() != nil || *service.updated.Active {
+		t.Fatalf("update input=%+v", service.updated)
+	}
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	appwebhooks "rdmm404/voltr-finance/internal/app/webhooks"
+)
+
+// DeliverySender posts signed webhook deliveries. It reports every response
+// status and leaves deciding on retries to the webhook service.
+type DeliverySender struct{ client *http.Client }
+
+func NewDeliverySender(timeout time.Duration) *DeliverySender {
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return &DeliverySender{client: &http.Client{Timeout: timeout}}
+}
+
+func (s *DeliverySender) Send(ctx context.Context, message appwebhooks.Message) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, message.URL, bytes.NewReader(message.Body))
+	if err != nil {
+		return 0, fmt.Errorf("build webhook request: %w", err)
+	}
+	for name, value := range message.Headers {
+		request.Header.Set(name, value)
+	}
+	request.Header.Set("User-Agent", "voltr-finance-webhooks")
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("send webhook: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+	return response.StatusCode, nil
+}
+
+var _ appwebhooks.Sender = (*DeliverySender)(nil)
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+)
+
+// LogNotifier writes alerts to a structured logger. It is the fallback when
+// no delivery channel is configured and a convenient notifier in tests.
+type LogNotifier struct{ logger *slog.Logger }
+
+func NewLogNotifier(logger *slog.Logger) *LogNotifier {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, alert appalerts.Alert) error {
+	n.logger.InfoContext(ctx, Summary(alert),
+		"alertId", alert.ID, "budgetId", alert.BudgetID, "budgetLineId", alert.LineID,
+		"thresholdPercent", alert.ThresholdPercent, "actualAmount", alert.ActualAmount, "allocationAmount", alert.AllocationAmount,
+	)
+	return nil
+}
+
+var _ appalerts.Notifier = (*LogNotifier)(nil)
@@ -0,0 +1,95 @@
+// Package notify delivers budget alerts and webhook events outside the API
+// process.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Config selects the alert notifiers. Every configured channel receives each
+// alert; with none configured alerts are only logged.
+type Config struct {
+	WebhookURL     string
+	WebhookTimeout time.Duration
+	SMTP           SMTPConfig
+}
+
+// SMTPConfig points at a mail relay. Username and Password are optional and
+// enable PLAIN authentication, which net/smtp only allows over TLS or to
+// localhost. Timeout bounds each email and defaults to ten seconds.
+type SMTPConfig struct {
+	Address  string
+	From     string
+	To       []string
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+func (c SMTPConfig) enabled() bool { return strings.TrimSpace(c.Address) != "" }
+
+func (c Config) Validate() error {
+	var errs []error
+	if strings.TrimSpace(c.WebhookURL) != "" {
+		parsed, err := url.Parse(c.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs = append(errs, errors.New("alert webhook URL must be an absolute http or https URL"))
+		}
+	}
+	if c.WebhookTimeout < 0 {
+		errs = append(errs, errors.New("alert webhook timeout cannot be negative"))
+	}
+	if c.SMTP.enabled() {
+		if _, err := mail.ParseAddress(c.SMTP.From); err != nil {
+			errs = append(errs, errors.New("alert SMTP sender must be a valid email address"))
+		}
+		if len(c.SMTP.To) == 0 {
+			errs = append(errs, errors.New("alert SMTP recipients are required"))
+		}
+		for _, recipient := range c.SMTP.To {
+			if _, err := mail.ParseAddress(recipient); err != nil {
+				errs = append(errs, fmt.Errorf("alert SMTP recipient %q is not a valid email address", recipient))
+			}
+		}
+		if (c.SMTP.Username == "") != (c.SMTP.Password == "") {
+			errs = append(errs, errors.New("alert SMTP username and password must be set together"))
+		}
+		if c.SMTP.Timeout < 0 {
+			errs = append(errs, errors.New("alert SMTP timeout cannot be negative"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// New builds the notifiers selected by the configuration.
+func New(cfg Config, logger *slog.Logger) []appalerts.Notifier {
+	var notifiers []appalerts.Notifier
+	if strings.TrimSpace(cfg.WebhookURL) != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(cfg.WebhookURL, cfg.WebhookTimeout))
+	}
+	if cfg.SMTP.enabled() {
+		notifiers = append(notifiers, NewSMTPNotifier(cfg.SMTP))
+	}
+	if len(notifiers) == 0 {
+		notifiers = append(notifiers, NewLogNotifier(logger))
+	}
+	return notifiers
+}
+
+// Summary is the one-line human description shared by the log and email
+// notifiers.
+func Summary(alert appalerts.Alert) string {
+	return fmt.Sprintf("Budget line %q reached %d%% of its allocation: %s spent of %s for %s to %s",
+		alert.LineName, alert.ThresholdPercent, alert.ActualAmount, alert.AllocationAmount,
+		alert.PeriodStart.Format(time.DateOnly), alert.PeriodEnd.Format(time.DateOnly))
+}
@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+	appwebhooks "rdmm404/voltr-finance/internal/app/webhooks"
+)
+
+func sampleAlert() appalerts.Alert {
+	householdID, transactionID := int64(3), int64(40)
+	return appalerts.Alert{
+		ID: 9, BudgetID: 1, LineID: 10, LineName: "Groceries", Owner: appalerts.Owner{HouseholdID: &householdID},
+		PeriodKind: "monthly", PeriodStart: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+		ThresholdPercent: 80, AllocationAmount: "500.00", ActualAmount: "412.50", TransactionID: &transactionID,
+	}
+}
+
+func TestWebhookNotifierPostsAlertJSON(t *testing.T) {
+	var received WebhookEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("method=%s content-type=%s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL, time.Second).Notify(context.Background(), sampleAlert()); err != nil {
+		t.Fatal(err)
+	}
+	if received.Event != "budget.alert" || received.Alert.BudgetLineName != "Groceries" || received.Alert.PeriodStart != "2026-06-01" || *received.Alert.HouseholdID != 3 || received.Alert.Summary == "" {
+		t.Fatalf("received=%+v", received)
+	}
+}
+
+func TestWebhookNotifierFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusBadGateway) }))
+	defer server.Close()
+	err := NewWebhookNotifier(server.URL, time.Second).Notify(context.Background(), sampleAlert())
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("error=%v", err)
+	}
+}
+
+func TestDeliverySenderPostsHeadersAndReportsStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(appwebhooks.HeaderSignature) != "sha256=abc" || r.Header.Get("User-Agent") != "voltr-finance-webhooks" {
+			t.Errorf("headers=%v", r.Header)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	status, err := NewDeliverySender(time.Second).Send(context.Background(), appwebhooks.Message{
+		URL: server.URL, Body: []byte(`{}`), Headers: map[string]string{appwebhooks.HeaderSignature: "sha256=abc"},
+	})
+	if err != nil || status != http.StatusServiceUnavailable {
+		t.Fatalf("status=%d error=%v", status, err)
+	}
+}
+
+func TestSMTPNotifierSendsThroughRelay(t *testing.T) {
+	notifier := NewSMTPNotifier(SMTPConfig{Address: "mail.example.com:587", From: "voltr@example.com", To: []string{"a@example.com", "b@example.com"}, Username: "voltr", Password: "secret"})
+	var addr, from string
+	var to []string
+	var message []byte
+	var auth smtp.Auth
+	notifier.send = func(_ context.Context, gotAddr string, gotAuth smtp.Auth, gotFrom string, gotTo []string, msg []byte) error {
+		addr, auth, from, to, message = gotAddr, gotAuth, gotFrom, gotTo, msg
+		return nil
+	}
+	alert := sampleAlert()
+	alert.LineName = "Food\r\nBcc: x@example.com"
+	if err := notifier.Notify(context.Background(), alert); err != nil {
+		t.Fatal(err)
+	}
+	if addr != "mail.example.com:587" || auth == nil || from != "voltr@example.com" || len(to) != 2 {
+		t.Fatalf("addr=%s auth=%v from=%s to=%v", addr, auth, from, to)
+	}
+	text := string(message)
+	if !strings.Contains(text, "Subject: Budget alert: Food  Bcc: x@example.com at 80%\r\n") || strings.Contains(text, "\r\nBcc:") || !strings.Contains(text, "412.50 spent of 500.00") {
+		t.Fatalf("message=%q", text)
+	}
+}
+
+func TestSMTPNotifierGivesUpOnSilentRelay(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		// Accept and never greet, like a relay that hangs.
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+	notifier := NewSMTPNotifier(SMTPConfig{Address: listener.Addr().String(), From: "voltr@example.com", To: []string{"me@example.com"}, Timeout: 50 * time.Millisecond})
+	started := time.Now()
+	if err := notifier.Notify(context.Background(), sampleAlert()); err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Fatalf("notify took %s", elapsed)
+	}
+}
+
+func TestLogNotifierWritesSummary(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := NewLogNotifier(slog.New(slog.NewTextHandler(&buffer, nil))).Notify(context.Background(), sampleAlert()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), "reached 80%") || !strings.Contains(buffer.String(), "budgetLineId=10") {
+		t.Fatalf("log=%s", buffer.String())
+	}
+}
+
+func TestConfigSelectsNotifiersAndValidates(t *testing.T) {
+	if notifiers := New(Config{}, nil); len(notifiers) != 1 {
+		t.Fatalf("default notifiers=%v", notifiers)
+	} else if _, ok := notifiers[0].(*LogNotifier); !ok {
+		t.Fatalf("default notifier=%T", notifiers[0])
+	}
+	cfg := Config{WebhookURL: "https://hooks.example.com/voltr", SMTP: SMTPConfig{Address: "localhost:25", From: "voltr@example.com", To: []string{"me@example.com"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if notifiers := New(cfg, nil); len(notifiers) != 2 {
+		t.Fatalf("configured notifiers=%v", notifiers)
+	}
+	for name, invalid := range map[string]Config{
+		"relative webhook":      {WebhookURL: "/hook"},
+		"negative timeout":      {WebhookTimeout: -time.Second},
+		"missing recipient":     {SMTP: SMTPConfig{Address: "localhost:25", From: "voltr@example.com"}},
+		"bad sender":            {SMTP: SMTPConfig{Address: "localhost:25", From: "voltr", To: []string{"me@example.com"}}},
+		"half credentials":      {SMTP: SMTPConfig{Address: "localhost:25", From: "voltr@example.com", To: []string{"me@example.com"}, Username: "voltr"}},
+		"negative smtp timeout": {SMTP: SMTPConfig{Address: "localhost:25", From: "voltr@example.com", To: []string{"me@example.com"}, Timeout: -time.Second}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
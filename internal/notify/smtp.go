@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+)
+
+type sendMailFunc func(ctx context.Context, addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+// SMTPNotifier emails alerts through a configured relay. The whole exchange
+// with the relay, dial included, is bounded by the configured timeout and by
+// the context deadline.
+type SMTPNotifier struct {
+	config SMTPConfig
+	send   sendMailFunc
+}
+
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	notifier := &SMTPNotifier{config: config}
+	notifier.send = notifier.sendMail
+	return notifier
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, alert appalerts.Alert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		host, _, err := net.SplitHostPort(n.config.Address)
+		if err != nil {
+			return fmt.Errorf("parse SMTP relay address: %w", err)
+		}
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, host)
+	}
+	if err := n.send(ctx, n.config.Address, auth, n.config.From, n.config.To, n.message(alert)); err != nil {
+		return fmt.Errorf("send alert email: %w", err)
+	}
+	return nil
+}
+
+// sendMail is smtp.SendMail with a deadline on the connection, which the
+// standard helper does not offer.
+func (n *SMTPNotifier) sendMail(ctx context.Context, addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, n.config.Timeout)
+	defer cancel()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(msg); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (n *SMTPNotifier) message(alert appalerts.Alert) []byte {
+	subject := fmt.Sprintf("Budget alert: %s at %d%%", alert.LineName, alert.ThresholdPercent)
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&builder, "To: %s\r\n", strings.Join(n.config.To, ", "))
+	fmt.Fprintf(&builder, "Subject: %s\r\n", headerValue(subject))
+	fmt.Fprintf(&builder, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	builder.WriteString(Summary(alert))
+	fmt.Fprintf(&builder, ".\r\n\r\nBudget %d, line %d, alert %d.\r\n", alert.BudgetID, alert.LineID, alert.ID)
+	return []byte(builder.String())
+}
+
+// headerValue keeps user-supplied line names from injecting extra headers.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+var _ appalerts.Notifier = (*SMTPNotifier)(nil)
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+)
+
+// WebhookEvent is the JSON body posted for every alert.
+type WebhookEvent struct {
+	Event string       `json:"event"`
+	Alert WebhookAlert `json:"alert"`
+}
+
+type WebhookAlert struct {
+	ID               int64     `json:"id"`
+	BudgetID         int64     `json:"budgetId"`
+	BudgetLineID     int64     `json:"budgetLineId"`
+	BudgetLineName   string    `json:"budgetLineName"`
+	HouseholdID      *int64    `json:"householdId,omitempty"`
+	UserID           *int64    `json:"userId,omitempty"`
+	PeriodKind       string    `json:"periodKind"`
+	PeriodStart      string    `json:"periodStart"`
+	PeriodEnd        string    `json:"periodEnd"`
+	ThresholdPercent int32     `json:"thresholdPercent"`
+	AllocationAmount string    `json:"allocationAmount"`
+	ActualAmount     string    `json:"actualAmount"`
+	TransactionID    *int64    `json:"transactionId,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+	Summary          string    `json:"summary"`
+}
+
+// WebhookNotifier posts alerts as JSON to an outbound URL. Any non-2xx
+// response counts as a failed delivery.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert appalerts.Alert) error {
+	body, err := json.Marshal(WebhookEvent{Event: "budget.alert", Alert: WebhookAlert{
+		ID: alert.ID, BudgetID: alert.BudgetID, BudgetLineID: alert.LineID, BudgetLineName: alert.LineName,
+		HouseholdID: alert.Owner.HouseholdID, UserID: alert.Owner.UserID, PeriodKind: alert.PeriodKind,
+		PeriodStart: alert.PeriodStart.Format(time.DateOnly), PeriodEnd: alert.PeriodEnd.Format(time.DateOnly),
+		ThresholdPercent: alert.ThresholdPercent, AllocationAmount: alert.AllocationAmount, ActualAmount: alert.ActualAmount,
+		TransactionID: alert.TransactionID, CreatedAt: alert.CreatedAt, Summary: Summary(alert),
+	}})
+	if err != nil {
+		return fmt.Errorf("encode alert webhook: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build alert webhook request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := n.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("send alert webhook: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("alert webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+var _ appalerts.Notifier = (*WebhookNotifier)(nil)
@@ -0,0 +1,172 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+	"rdmm404/voltr-finance/internal/postgres"
+)
+
+// Repository owns the PostgreSQL mechanics behind budget alerts. Candidate
+// actual amounts use the same owner scope and period window as budget reports.
+type Repository struct{ pool *pgxpool.Pool }
+
+func NewRepository(pool *pgxpool.Pool) *Repository { return &Repository{pool: pool} }
+
+func (r *Repository) ListCandidates(ctx context.Context, transactionID int64) ([]appalerts.Candidate, error) {
+	rows, err := sqlc.New(r.pool).ListBudgetAlertCandidates(ctx, transactionID)
+	if err != nil {
+		return nil, mapAlertError(err)
+	}
+	items := make([]appalerts.Candidate, 0, len(rows))
+	for _, row := range rows {
+		allocation, err := numericString(row.AllocationAmount)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		actual, err := numericString(row.ActualAmount)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		items = append(items, appalerts.Candidate{BudgetID: row.BudgetID, LineID: row.BudgetLineID, ThresholdPercent: row.ThresholdPercent, AllocationAmount: allocation, ActualAmount: actual})
+	}
+	return items, nil
+}
+
+func (r *Repository) Create(ctx context.Context, input appalerts.NewAlert) (appalerts.Alert, bool, error) {
+	type result struct {
+		alert   appalerts.Alert
+		created bool
+	}
+	created, err := withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (result, error) {
+		allocation, err := numeric(input.AllocationAmount)
+		if err != nil {
+			return result{}, apperrors.Internal(err)
+		}
+		actual, err := numeric(input.ActualAmount)
+		if err != nil {
+			return result{}, apperrors.Internal(err)
+		}
+		id, err := q.CreateBudgetAlert(ctx, sqlc.CreateBudgetAlertParams{
+			BudgetID: input.BudgetID, BudgetLineID: input.LineID, ThresholdPercent: input.ThresholdPercent,
+			AllocationAmount: allocation, ActualAmount: actual, TransactionID: input.TransactionID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result{}, nil
+		}
+		if err != nil {
+			return result{}, mapAlertError(err)
+		}
+		alert, err := get(ctx, q, id)
+		return result{alert: alert, created: true}, err
+	})
+	return created.alert, created.created, err
+}
+
+func (r *Repository) List(ctx context.Context, filter appalerts.ListFilter) ([]appalerts.Alert, error) {
+	rows, err := sqlc.New(r.pool).ListBudgetAlerts(ctx, sqlc.ListBudgetAlertsParams{
+		BudgetID: filter.BudgetID, HouseholdID: filter.Owner.HouseholdID, UserID: filter.Owner.UserID, RowLimit: filter.Limit,
+	})
+	if err != nil {
+		return nil, mapAlertError(err)
+	}
+	items := make([]appalerts.Alert, 0, len(rows))
+	for _, row := range rows {
+		item, err := mapAlert(row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (r *Repository) MarkNotified(ctx context.Context, id int64, notificationError *string) error {
+	return mapAlertError(sqlc.New(r.pool).MarkBudgetAlertNotified(ctx, sqlc.MarkBudgetAlertNotifiedParams{ID: id, NotificationError: notificationError}))
+}
+
+func get(ctx context.Context, q *sqlc.Queries, id int64) (appalerts.Alert, error) {
+	rows, err := q.ListBudgetAlerts(ctx, sqlc.ListBudgetAlertsParams{ID: &id, RowLimit: 1})
+	if err != nil {
+		return appalerts.Alert{}, mapAlertError(err)
+	}
+	if len(rows) == 0 {
+		return appalerts.Alert{}, apperrors.Internal(fmt.Errorf("budget alert %d vanished after insert", id))
+	}
+	return mapAlert(rows[0])
+}
+
+func withTransaction[T any](ctx context.Context, pool *pgxpool.Pool, options pgx.TxOptions, operation func(*sqlc.Queries) (T, error)) (T, error) {
+	var zero T
+	tx, err := pool.BeginTx(ctx, options)
+	if err != nil {
+		return zero, mapAlertError(err)
+	}
+	defer tx.Rollback(ctx)
+	result, err := operation(sqlc.New(tx))
+	if err != nil {
+		return zero, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return zero, mapAlertError(err)
+	}
+	return result, nil
+}
+
+func mapAlert(row sqlc.ListBudgetAlertsRow) (appalerts.Alert, error) {
+	allocation, err := numericString(row.AllocationAmount)
+	if err != nil {
+		return appalerts.Alert{}, apperrors.Internal(err)
+	}
+	actual, err := numericString(row.ActualAmount)
+	if err != nil {
+		return appalerts.Alert{}, apperrors.Internal(err)
+	}
+	item := appalerts.Alert{
+		ID: row.ID, BudgetID: row.BudgetID, LineID: row.BudgetLineID, LineName: row.BudgetLineName,
+		Owner:      appalerts.Owner{HouseholdID: row.HouseholdID, UserID: row.UserID},
+		PeriodKind: row.PeriodKind, PeriodStart: row.PeriodStart.Time, PeriodEnd: row.PeriodEnd.Time,
+		ThresholdPercent: row.ThresholdPercent, AllocationAmount: allocation, ActualAmount: actual,
+		TransactionID: row.TransactionID, CreatedAt: row.CreatedAt.Time, NotificationError: row.NotificationError,
+	}
+	if row.NotifiedAt.Valid {
+		notifiedAt := row.NotifiedAt.Time
+		item.NotifiedAt = &notifiedAt
+	}
+	return item, nil
+}
+
+func numeric(value string) (pgtype.Numeric, error) {
+	var result pgtype.Numeric
+	if err := result.Scan(value); err != nil {
+		return pgtype.Numeric{}, fmt.Errorf("parse numeric: %w", err)
+	}
+	return result, nil
+}
+func numericString(value pgtype.Numeric) (string, error) {
+	raw, err := value.Value()
+	if err != nil {
+		return "", fmt.Errorf("format numeric: %w", err)
+	}
+	if raw == nil {
+		return "0", nil
+	}
+	result, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected numeric value %T", raw)
+	}
+	return result, nil
+}
+func mapAlertError(err error) error {
+	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeBudgetNotFound, NotFoundMessage: "budget not found", ConflictCode: apperrors.CodeBudgetConflict, ConflictMessage: "budget alert violates an invariant"})
+}
+
+var _ appalerts.Repository = (*Repository)(nil)
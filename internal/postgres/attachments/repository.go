@@ -0,0 +1,86 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	appattachments "rdmm404/voltr-finance/internal/app/attachments"
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+	"rdmm404/voltr-finance/internal/postgres"
+)
+
+type Repository struct{ pool *pgxpool.Pool }
+
+func NewRepository(pool *pgxpool.Pool) *Repository { return &Repository{pool: pool} }
+
+func (r *Repository) Create(ctx context.Context, input appattachments.NewAttachment) (appattachments.Attachment, error) {
+	row, err := sqlc.New(r.pool).CreateTransactionAttachment(ctx, sqlc.CreateTransactionAttachmentParams{
+		FileName: input.FileName, ContentType: input.ContentType, SizeBytes: input.Size, Sha256: input.SHA256,
+		StorageKey: input.StorageKey, TransactionID: input.TransactionID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return appattachments.Attachment{}, transactionNotFound()
+	}
+	if err != nil {
+		return appattachments.Attachment{}, mapError(err)
+	}
+	return mapAttachment(row), nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int64) (appattachments.Attachment, error) {
+	row, err := sqlc.New(r.pool).GetTransactionAttachmentById(ctx, id)
+	if err != nil {
+		return appattachments.Attachment{}, mapError(err)
+	}
+	return mapAttachment(row), nil
+}
+
+func (r *Repository) List(ctx context.Context, transactionID int64) ([]appattachments.Attachment, error) {
+	queries := sqlc.New(r.pool)
+	rows, err := queries.ListTransactionAttachments(ctx, transactionID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if len(rows) == 0 {
+		// No attachments and no transaction are different answers.
+		if _, err := queries.GetTransactionById(ctx, transactionID); errors.Is(err, pgx.ErrNoRows) {
+			return nil, transactionNotFound()
+		} else if err != nil {
+			return nil, mapError(err)
+		}
+	}
+	items := make([]appattachments.Attachment, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, mapAttachment(row))
+	}
+	return items, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id int64) (appattachments.Attachment, error) {
+	row, err := sqlc.New(r.pool).DeleteTransactionAttachment(ctx, id)
+	if err != nil {
+		return appattachments.Attachment{}, mapError(err)
+	}
+	return mapAttachment(row), nil
+}
+
+func mapAttachment(row sqlc.TransactionAttachment) appattachments.Attachment {
+	return appattachments.Attachment{
+		ID: row.ID, TransactionID: row.TransactionID, FileName: row.FileName, ContentType: row.ContentType,
+		Size: row.SizeBytes, SHA256: row.Sha256, StorageKey: row.StorageKey, CreatedAt: row.CreatedAt.Time,
+	}
+}
+
+func mapError(err error) error {
+	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeAttachmentNotFound, NotFoundMessage: "attachment not found", ConflictCode: apperrors.CodeAttachmentConflict, ConflictMessage: "attachment violates an invariant"})
+}
+
+func transactionNotFound() error {
+	return apperrors.NotFound(apperrors.CodeTransactionNotFound, "transaction not found", nil)
+}
+
+var _ appattachments.Repository = (*Repository)(nil)
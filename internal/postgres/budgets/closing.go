@@ -0,0 +1,179 @@
+package budgets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+)
+
+// Close takes the report snapshot only once it holds the budget lock.
+// Transaction writes share-lock the budgets covering them, so every write that
+// got past its closed-period check has committed by then and is counted, and
+// later writes see the closing and are rejected.
+func (r *Repository) Close(ctx context.Context, input appbudgets.CloseInput, snapshot func(appbudgets.ReportSnapshot) (appbudgets.CloseInput, error)) (appbudgets.Closing, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appbudgets.Closing, error) {
+		if _, err := q.LockBudgetForUpdate(ctx, input.BudgetID); err != nil {
+			return appbudgets.Closing{}, mapBudgetError(err)
+		}
+		report, err := loadReportSnapshot(ctx, q, input.BudgetID)
+		if err != nil {
+			return appbudgets.Closing{}, err
+		}
+		if input, err = snapshot(report); err != nil {
+			return appbudgets.Closing{}, err
+		}
+		totals, err := numerics(input.Totals.AllocationAmount, input.Totals.ActualAmount, input.Totals.UnmappedActualAmount, input.Totals.UncategorizedActualAmount)
+		if err != nil {
+			return appbudgets.Closing{}, apperrors.Internal(err)
+		}
+		id, err := q.CreateBudgetClosing(ctx, sqlc.CreateBudgetClosingParams{
+			BudgetID: input.BudgetID, AllocationAmount: totals[0], ActualAmount: totals[1], UnmappedActualAmount: totals[2], UncategorizedActualAmount: totals[3],
+			ClosedByUserID: input.UserID,
+		})
+		if err != nil {
+			return appbudgets.Closing{}, mapClosingError(err)
+		}
+		for _, line := range input.Lines {
+			amounts, err := numerics(line.AllocationAmount, line.ActualAmount)
+			if err != nil {
+				return appbudgets.Closing{}, apperrors.Internal(err)
+			}
+			if err := q.CreateBudgetClosingLine(ctx, sqlc.CreateBudgetClosingLineParams{
+				ClosingID: id, BudgetLineID: line.LineID, Name: line.Name, AllocationAmount: amounts[0], ActualAmount: amounts[1], SortOrder: line.SortOrder,
+			}); err != nil {
+				return appbudgets.Closing{}, mapClosingError(err)
+			}
+		}
+		return loadClosing(ctx, q, id)
+	})
+}
+
+func (r *Repository) Reopen(ctx context.Context, input appbudgets.ReopenInput) (appbudgets.Closing, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appbudgets.Closing, error) {
+		if _, err := q.LockBudgetForUpdate(ctx, input.BudgetID); err != nil {
+			return appbudgets.Closing{}, mapBudgetError(err)
+		}
+		id, err := q.ReopenBudgetClosing(ctx, sqlc.ReopenBudgetClosingParams{BudgetID: input.BudgetID, ReopenedByUserID: input.UserID, ReopenReason: input.Reason})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return appbudgets.Closing{}, apperrors.Conflict(apperrors.CodeBudgetConflict, fmt.Sprintf("budget %d is not closed", input.BudgetID), nil)
+		}
+		if err != nil {
+			return appbudgets.Closing{}, mapClosingError(err)
+		}
+		return loadClosing(ctx, q, id)
+	})
+}
+
+// lockOpenBudget locks a budget for a line change and rejects the change while
+// the budget is closed. Holding the lock keeps a concurrent close from slipping
+// in before the change commits.
+func lockOpenBudget(ctx context.Context, q *sqlc.Queries, budgetID int64) error {
+	if _, err := q.LockBudgetForUpdate(ctx, budgetID); err != nil {
+		return mapBudgetError(err)
+	}
+	_, err := q.GetOpenBudgetClosing(ctx, budgetID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return mapBudgetError(err)
+	}
+	return apperrors.Conflict(apperrors.CodeBudgetClosed, fmt.Sprintf("budget %d is closed; reopen it before changing its lines", budgetID), nil)
+}
+
+// findOpenClosing loads a budget's open closing, or nil when it is not closed.
+func findOpenClosing(ctx context.Context, q *sqlc.Queries, budgetID int64) (*appbudgets.Closing, error) {
+	id, err := q.GetOpenBudgetClosing(ctx, budgetID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, mapBudgetError(err)
+	}
+	closing, err := loadClosing(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	return &closing, nil
+}
+
+func loadClosing(ctx context.Context, q *sqlc.Queries, id int64) (appbudgets.Closing, error) {
+	row, err := q.GetBudgetClosingById(ctx, id)
+	if err != nil {
+		return appbudgets.Closing{}, mapBudgetError(err)
+	}
+	totals, err := numericStrings(row.AllocationAmount, row.ActualAmount, row.UnmappedActualAmount, row.UncategorizedActualAmount)
+	if err != nil {
+		return appbudgets.Closing{}, apperrors.Internal(err)
+	}
+	closing := appbudgets.Closing{
+		ID: row.ID, BudgetID: row.BudgetID, ClosedBy: appbudgets.Author{ID: row.ClosedByUserID, Name: row.ClosedByUserName}, ClosedAt: row.ClosedAt.Time,
+		Totals:       appbudgets.ReportTotals{AllocationAmount: totals[0], ActualAmount: totals[1], UnmappedActualAmount: totals[2], UncategorizedActualAmount: totals[3]},
+		ReopenReason: row.ReopenReason,
+	}
+	if row.ReopenedAt.Valid {
+		reopenedAt := row.ReopenedAt.Time
+		closing.ReopenedAt = &reopenedAt
+	}
+	if row.ReopenedByUserID != nil && row.ReopenedByUserName != nil {
+		closing.ReopenedBy = &appbudgets.Author{ID: *row.ReopenedByUserID, Name: *row.ReopenedByUserName}
+	}
+	lines, err := q.ListBudgetClosingLines(ctx, id)
+	if err != nil {
+		return appbudgets.Closing{}, mapBudgetError(err)
+	}
+	closing.Lines = make([]appbudgets.ClosingLine, 0, len(lines))
+	for _, line := range lines {
+		amounts, err := numericStrings(line.AllocationAmount, line.ActualAmount)
+		if err != nil {
+			return appbudgets.Closing{}, apperrors.Internal(err)
+		}
+		closing.Lines = append(closing.Lines, appbudgets.ClosingLine{LineID: line.BudgetLineID, Name: line.Name, AllocationAmount: amounts[0], ActualAmount: amounts[1], SortOrder: line.SortOrder})
+	}
+	return closing, nil
+}
+
+func numerics(values ...string) ([]pgtype.Numeric, error) {
+	result := make([]pgtype.Numeric, len(values))
+	for i, value := range values {
+		amount, err := numeric(value)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = amount
+	}
+	return result, nil
+}
+
+func numericStrings(values ...pgtype.Numeric) ([]string, error) {
+	result := make([]string, len(values))
+	for i, value := range values {
+		amount, err := numericString(value)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = amount
+	}
+	return result, nil
+}
+
+func mapClosingError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.ConstraintName {
+		case "budget_closing_closed_by_user_id_fkey", "budget_closing_reopened_by_user_id_fkey":
+			return apperrors.NotFound(apperrors.CodeUserNotFound, "user not found", err)
+		case "idx_budget_closing_open_budget_id":
+			return apperrors.Conflict(apperrors.CodeBudgetClosed, "budget is already closed", err)
+		}
+	}
+	return mapBudgetError(err)
+}
@@ -0,0 +1,142 @@
+package budgets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+)
+
+// listMemberAllocations returns the member allocations of every line in a
+// budget keyed by line id, each ordered by member name.
+func listMemberAllocations(ctx context.Context, q *sqlc.Queries, budgetID int64) (map[int64][]appbudgets.MemberAllocation, error) {
+	rows, err := q.ListBudgetLineMemberAllocations(ctx, budgetID)
+	if err != nil {
+		return nil, mapBudgetError(err)
+	}
+	items := make(map[int64][]appbudgets.MemberAllocation)
+	for _, row := range rows {
+		item := appbudgets.MemberAllocation{Member: appbudgets.Author{ID: row.UserID, Name: row.UserName}}
+		if item.Amount, err = optionalNumericString(row.AllocationAmount); err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		if item.Percent, err = optionalNumericString(row.AllocationPercent); err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		items[row.BudgetLineID] = append(items[row.BudgetLineID], item)
+	}
+	return items, nil
+}
+
+// checkMemberAmounts rejects a line whose member amounts and percentage shares
+// no longer fit in its allocation, as stored in the current transaction.
+func checkMemberAmounts(ctx context.Context, q *sqlc.Queries, line appbudgets.Line) error {
+	members, err := listMemberAllocations(ctx, q, line.BudgetID)
+	if err != nil {
+		return err
+	}
+	shares := make([]appbudgets.MemberAllocationInput, 0, len(members[line.ID]))
+	for _, member := range members[line.ID] {
+		shares = append(shares, appbudgets.MemberAllocationInput{UserID: member.Member.ID, Amount: member.Amount, Percent: member.Percent})
+	}
+	return appbudgets.CheckMemberAmounts(line.AllocationAmount, shares)
+}
+
+// replaceMemberAllocations sets a line's member allocations. Members must
+// belong to the household that owns the budget.
+func replaceMemberAllocations(ctx context.Context, q *sqlc.Queries, budgetID, lineID int64, items []appbudgets.MemberAllocationInput) error {
+	if len(items) > 0 {
+		budget, err := q.GetBudgetById(ctx, budgetID)
+		if err != nil {
+			return mapBudgetError(err)
+		}
+		if budget.HouseholdID == nil {
+			return apperrors.Validation("member allocations require a household budget")
+		}
+		members, err := q.ListBudgetHouseholdMemberIds(ctx, budgetID)
+		if err != nil {
+			return mapBudgetError(err)
+		}
+		for _, item := range items {
+			if !slices.Contains(members, item.UserID) {
+				return apperrors.Validation(fmt.Sprintf("user %d is not a member of household %d", item.UserID, *budget.HouseholdID))
+			}
+		}
+	}
+	if err := q.DeleteBudgetLineMemberAllocations(ctx, lineID); err != nil {
+		return mapLineError(err)
+	}
+	for _, item := range items {
+		params := sqlc.CreateBudgetLineMemberAllocationParams{BudgetLineID: lineID, UserID: item.UserID}
+		var err error
+		if params.AllocationAmount, err = optionalNumeric(item.Amount); err != nil {
+			return apperrors.Internal(err)
+		}
+		if params.AllocationPercent, err = optionalNumeric(item.Percent); err != nil {
+			return apperrors.Internal(err)
+		}
+		if err := q.CreateBudgetLineMemberAllocation(ctx, params); err != nil {
+			return mapMemberAllocationError(err)
+		}
+	}
+	return nil
+}
+
+// copyMemberAllocations copies a source line's member allocations to a new
+// line, skipping members who have since left the household.
+func copyMemberAllocations(ctx context.Context, q *sqlc.Queries, targetBudgetID, lineID int64, items []appbudgets.MemberAllocation) error {
+	if len(items) == 0 {
+		return nil
+	}
+	members, err := q.ListBudgetHouseholdMemberIds(ctx, targetBudgetID)
+	if err != nil {
+		return mapBudgetError(err)
+	}
+	inputs := make([]appbudgets.MemberAllocationInput, 0, len(items))
+	for _, item := range items {
+		if slices.Contains(members, item.Member.ID) {
+			inputs = append(inputs, appbudgets.MemberAllocationInput{UserID: item.Member.ID, Amount: item.Amount, Percent: item.Percent})
+		}
+	}
+	return replaceMemberAllocations(ctx, q, targetBudgetID, lineID, inputs)
+}
+
+func nonNilMemberAllocations(items []appbudgets.MemberAllocation) []appbudgets.MemberAllocation {
+	if items == nil {
+		return []appbudgets.MemberAllocation{}
+	}
+	return items
+}
+
+func optionalNumeric(value *string) (pgtype.Numeric, error) {
+	if value == nil {
+		return pgtype.Numeric{}, nil
+	}
+	return numeric(*value)
+}
+
+func optionalNumericString(value pgtype.Numeric) (*string, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	result, err := numericString(value)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func mapMemberAllocationError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == "budget_line_member_allocation_user_id_fkey" {
+		return apperrors.NotFound(apperrors.CodeUserNotFound, "user not found", err)
+	}
+	return mapLineError(err)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -23,9 +24,9 @@ type Repository struct{ pool *pgxpool.Pool }
 
 func NewRepository(pool *pgxpool.Pool) *Repository { return &Repository{pool: pool} }
 
-func (r *Repository) FindMonthly(ctx context.Context, owner appbudgets.Owner, start, end time.Time) (appbudgets.Budget, error) {
+func (r *Repository) FindByPeriod(ctx context.Context, owner appbudgets.Owner, period appbudgets.Period) (appbudgets.Budget, error) {
 	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) (appbudgets.Budget, error) {
-		budget, err := findMonthly(ctx, q, owner, start, end)
+		budget, err := findByPeriod(ctx, q, owner, period)
 		if err != nil {
 			return appbudgets.Budget{}, err
 		}
@@ -33,9 +34,42 @@ func (r *Repository) FindMonthly(ctx context.Context, owner appbudgets.Owner, st
 	})
 }
 
-func (r *Repository) CreateMonthlyFromTemplate(ctx context.Context, input appbudgets.CreateMonthlyFromTemplateInput) (appbudgets.Budget, error) {
+func (r *Repository) FindByID(ctx context.Context, id int64) (appbudgets.Budget, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) (appbudgets.Budget, error) {
+		row, err := q.GetBudgetById(ctx, id)
+		if err != nil {
+			return appbudgets.Budget{}, mapBudgetError(err)
+		}
+		return loadBudget(ctx, q, mapBudget(row))
+	})
+}
+
+// maxLineageGenerations bounds the source budget chain ListLineage follows,
+// ten years of monthly copies.
+const maxLineageGenerations = 120
+
+// ListLineage lists the budgets a budget was copied from, nearest first.
+func (r *Repository) ListLineage(ctx context.Context, id int64) ([]int64, error) {
+	ids, err := sqlc.New(r.pool).ListBudgetLineage(ctx, sqlc.ListBudgetLineageParams{ID: id, MaxGenerations: maxLineageGenerations})
+	if err != nil {
+		return nil, mapBudgetError(err)
+	}
+	return ids, nil
+}
+
+func (r *Repository) CreateFromTemplate(ctx context.Context, input appbudgets.CreateFromTemplateInput) (appbudgets.Budget, error) {
 	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appbudgets.Budget, error) {
-		prior, err := findLatestPrior(ctx, q, input.Owner, input.PeriodStart)
+		if input.TemplateID != nil {
+			created, err := createBudget(ctx, q, input.Owner, input.Period, nil)
+			if err != nil {
+				return appbudgets.Budget{}, err
+			}
+			if err := copyTemplate(ctx, q, *input.TemplateID, created.ID); err != nil {
+				return appbudgets.Budget{}, err
+			}
+			return loadBudget(ctx, q, created)
+		}
+		prior, err := findLatestPrior(ctx, q, input.Owner, input.Period)
 		if err != nil && !apperrors.IsKind(err, apperrors.KindNotFound) {
 			return appbudgets.Budget{}, err
 		}
@@ -43,7 +77,7 @@ func (r *Repository) CreateMonthlyFromTemplate(ctx context.Context, input appbud
 		if err == nil {
 			sourceID = &prior.ID
 		}
-		created, err := createBudget(ctx, q, input.Owner, input.PeriodStart, input.PeriodEnd, sourceID)
+		created, err := createBudget(ctx, q, input.Owner, input.Period, sourceID)
 		if err != nil {
 			return appbudgets.Budget{}, err
 		}
@@ -58,8 +92,8 @@ func (r *Repository) CreateMonthlyFromTemplate(ctx context.Context, input appbud
 
 func (r *Repository) CreateLineWithCategories(ctx context.Context, input appbudgets.CreateLineInput) (appbudgets.Line, error) {
 	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appbudgets.Line, error) {
-		if _, err := q.GetBudgetById(ctx, input.BudgetID); err != nil {
-			return appbudgets.Line{}, mapBudgetError(err)
+		if err := lockOpenBudget(ctx, q, input.BudgetID); err != nil {
+			return appbudgets.Line{}, err
 		}
 		categoryIDs, err := resolveCategoryIDs(ctx, q, input.CategoryIDs, input.CategoryCodes)
 		if err != nil {
@@ -67,9 +101,6 @@ func (r *Repository) CreateLineWithCategories(ctx context.Context, input appbudg
 		}
 		sortOrder := input.SortOrder
 		if sortOrder == nil {
-			if _, err := q.LockBudgetForUpdate(ctx, input.BudgetID); err != nil {
-				return appbudgets.Line{}, mapBudgetError(err)
-			}
 			max, err := q.GetMaxBudgetLineSortOrder(ctx, input.BudgetID)
 			if err != nil {
 				return appbudgets.Line{}, mapBudgetError(err)
@@ -84,6 +115,12 @@ func (r *Repository) CreateLineWithCategories(ctx context.Context, input appbudg
 		if err := replaceCategories(ctx, q, input.BudgetID, created.ID, categoryIDs); err != nil {
 			return appbudgets.Line{}, err
 		}
+		if err := replaceAlertRules(ctx, q, created.ID, input.AlertThresholds); err != nil {
+			return appbudgets.Line{}, err
+		}
+		if err := replaceMemberAllocations(ctx, q, input.BudgetID, created.ID, input.MemberAllocations); err != nil {
+			return appbudgets.Line{}, err
+		}
 		return loadLine(ctx, q, created)
 	})
 }
@@ -98,6 +135,12 @@ func (r *Repository) UpdateLineWithCategories(ctx context.Context, input appbudg
 		if err != nil {
 			return appbudgets.Line{}, err
 		}
+		if err := lockOpenBudget(ctx, q, existing.BudgetID); err != nil {
+			return appbudgets.Line{}, err
+		}
+		if err := checkLineVersion(existing.Version, input.ExpectedVersion); err != nil {
+			return appbudgets.Line{}, err
+		}
 		changeCategories := input.CategoryIDs != nil || input.CategoryCodes != nil
 		var categoryIDs []int64
 		if changeCategories {
@@ -123,54 +166,165 @@ func (r *Repository) UpdateLineWithCategories(ctx context.Context, input appbudg
 				return appbudgets.Line{}, err
 			}
 		}
+		if input.AlertThresholds != nil {
+			if err := replaceAlertRules(ctx, q, existing.ID, *input.AlertThresholds); err != nil {
+				return appbudgets.Line{}, err
+			}
+		}
+		if input.MemberAllocations != nil {
+			if err := replaceMemberAllocations(ctx, q, existing.BudgetID, existing.ID, *input.MemberAllocations); err != nil {
+				return appbudgets.Line{}, err
+			}
+		}
+		if input.AllocationAmount != nil || input.MemberAllocations != nil {
+			if err := checkMemberAmounts(ctx, q, updated); err != nil {
+				return appbudgets.Line{}, err
+			}
+		}
 		return loadLine(ctx, q, updated)
 	})
 }
 
-func (r *Repository) DeleteLine(ctx context.Context, id int64) error {
-	q := sqlc.New(r.pool)
-	if _, err := q.GetBudgetLineById(ctx, id); err != nil {
-		return mapLineError(err)
-	}
-	return mapLineError(q.DeleteBudgetLine(ctx, id))
+func (r *Repository) DeleteLine(ctx context.Context, id int64, expectedVersion *int64) (int64, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (int64, error) {
+		row, err := q.GetBudgetLineById(ctx, id)
+		if err != nil {
+			return 0, mapLineError(err)
+		}
+		if err := lockOpenBudget(ctx, q, row.BudgetID); err != nil {
+			return 0, err
+		}
+		if err := checkLineVersion(row.Version, expectedVersion); err != nil {
+			return 0, err
+		}
+		deleted, err := q.DeleteBudgetLine(ctx, sqlc.DeleteBudgetLineParams{ID: id, ExpectedVersion: expectedVersion})
+		if err != nil {
+			return 0, mapLineError(err)
+		}
+		if deleted == 0 {
+			return 0, lineVersionMismatch()
+		}
+		return row.BudgetID, nil
+	})
 }
 
-func (r *Repository) LoadReportSnapshot(ctx context.Context, budgetID int64) (appbudgets.ReportSnapshot, error) {
-	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) (appbudgets.ReportSnapshot, error) {
-		budgetRow, err := q.GetBudgetById(ctx, budgetID)
+func (r *Repository) Reallocate(ctx context.Context, input appbudgets.ReallocateInput) (appbudgets.ReallocationResult, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appbudgets.ReallocationResult, error) {
+		if err := lockOpenBudget(ctx, q, input.BudgetID); err != nil {
+			return appbudgets.ReallocationResult{}, err
+		}
+		amount, err := numeric(input.Amount)
 		if err != nil {
-			return appbudgets.ReportSnapshot{}, mapBudgetError(err)
+			return appbudgets.ReallocationResult{}, apperrors.Internal(err)
 		}
-		rows, err := listReportLines(ctx, q, budgetID)
+		withdrawal, err := numeric("-" + input.Amount)
 		if err != nil {
-			return appbudgets.ReportSnapshot{}, err
+			return appbudgets.ReallocationResult{}, apperrors.Internal(err)
 		}
-		mappings, err := listLineCategories(ctx, q, budgetID)
+		fromRow, err := q.AdjustBudgetLineAllocation(ctx, sqlc.AdjustBudgetLineAllocationParams{Delta: withdrawal, ID: input.FromLineID, BudgetID: input.BudgetID})
 		if err != nil {
-			return appbudgets.ReportSnapshot{}, err
+			return appbudgets.ReallocationResult{}, mapReallocationError(err)
 		}
-		categories := make(map[int64][]appbudgets.Category)
-		for _, mapping := range mappings {
-			categories[mapping.lineID] = append(categories[mapping.lineID], mapping.category)
+		toRow, err := q.AdjustBudgetLineAllocation(ctx, sqlc.AdjustBudgetLineAllocationParams{Delta: amount, ID: input.ToLineID, BudgetID: input.BudgetID})
+		if err != nil {
+			return appbudgets.ReallocationResult{}, mapReallocationError(err)
 		}
-		for i := range rows {
-			rows[i].Categories = nonNilCategories(categories[rows[i].ID])
+		id, err := q.CreateBudgetReallocation(ctx, sqlc.CreateBudgetReallocationParams{BudgetID: input.BudgetID, FromLineID: input.FromLineID, ToLineID: input.ToLineID, Amount: amount, Reason: input.Reason, CreatedByUserID: input.UserID})
+		if err != nil {
+			return appbudgets.ReallocationResult{}, mapReallocationError(err)
 		}
-		uncategorized, err := sumUncategorized(ctx, q, budgetID)
+		reallocations, err := listReallocations(ctx, q, input.BudgetID)
 		if err != nil {
-			return appbudgets.ReportSnapshot{}, err
+			return appbudgets.ReallocationResult{}, err
 		}
-		unmapped, err := listUnmappedTransactions(ctx, q, budgetID)
+		index := slices.IndexFunc(reallocations, func(item appbudgets.Reallocation) bool { return item.ID == id })
+		if index < 0 {
+			return appbudgets.ReallocationResult{}, apperrors.Internal(fmt.Errorf("reallocation %d not found after insert", id))
+		}
+		from, err := mapLine(fromRow)
 		if err != nil {
-			return appbudgets.ReportSnapshot{}, err
+			return appbudgets.ReallocationResult{}, err
+		}
+		if err := checkMemberAmounts(ctx, q, from); err != nil {
+			return appbudgets.ReallocationResult{}, err
+		}
+		if from, err = loadLine(ctx, q, from); err != nil {
+			return appbudgets.ReallocationResult{}, err
+		}
+		to, err := mapLine(toRow)
+		if err != nil {
+			return appbudgets.ReallocationResult{}, err
+		}
+		if to, err = loadLine(ctx, q, to); err != nil {
+			return appbudgets.ReallocationResult{}, err
 		}
-		return appbudgets.ReportSnapshot{Budget: mapBudget(budgetRow), Lines: rows, UnmappedTransactions: unmapped, UncategorizedAmount: uncategorized}, nil
+		return appbudgets.ReallocationResult{Reallocation: reallocations[index], FromLine: from, ToLine: to}, nil
 	})
 }
 
-func (r *Repository) LoadDetailedMonthlySnapshot(ctx context.Context, owner appbudgets.Owner, start, end time.Time) (appbudgets.DetailedReportSnapshot, error) {
+func (r *Repository) LoadReportSnapshot(ctx context.Context, budgetID int64) (appbudgets.ReportSnapshot, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) (appbudgets.ReportSnapshot, error) {
+		return loadReportSnapshot(ctx, q, budgetID)
+	})
+}
+
+func loadReportSnapshot(ctx context.Context, q *sqlc.Queries, budgetID int64) (appbudgets.ReportSnapshot, error) {
+	budgetRow, err := q.GetBudgetById(ctx, budgetID)
+	if err != nil {
+		return appbudgets.ReportSnapshot{}, mapBudgetError(err)
+	}
+	rows, err := listReportLines(ctx, q, budgetID)
+	if err != nil {
+		return appbudgets.ReportSnapshot{}, err
+	}
+	mappings, err := listLineCategories(ctx, q, budgetID)
+	if err != nil {
+		return appbudgets.ReportSnapshot{}, err
+	}
+	categories := make(map[int64][]appbudgets.Category)
+	for _, mapping := range mappings {
+		categories[mapping.lineID] = append(categories[mapping.lineID], mapping.category)
+	}
+	thresholds, err := listAlertRules(ctx, q, budgetID)
+	if err != nil {
+		return appbudgets.ReportSnapshot{}, err
+	}
+	members, err := listMemberAllocations(ctx, q, budgetID)
+	if err != nil {
+		return appbudgets.ReportSnapshot{}, err
+	}
+	originals, err := listOriginalAllocations(ctx, q, budgetID)
+	if err != nil {
+		return appbudgets.ReportSnapshot{}, err
+	}
+	for i := range rows {
+		rows[i].Categories = nonNilCategories(categories[rows[i].ID])
+		rows[i].AlertThresholds = nonNilThresholds(thresholds[rows[i].ID])
+		rows[i].MemberAllocations = nonNilMemberAllocations(members[rows[i].ID])
+		rows[i].OriginalAllocationAmount = originals[rows[i].ID]
+	}
+	reallocations, err := listReallocations(ctx, q, budgetID)
+	if err != nil {
+		return appbudgets.ReportSnapshot{}, err
+	}
+	uncategorized, err := sumUncategorized(ctx, q, budgetID)
+	if err != nil {
+		return appbudgets.ReportSnapshot{}, err
+	}
+	unmapped, err := listUnmappedTransactions(ctx, q, budgetID)
+	if err != nil {
+		return appbudgets.ReportSnapshot{}, err
+	}
+	closing, err := findOpenClosing(ctx, q, budgetID)
+	if err != nil {
+		return appbudgets.ReportSnapshot{}, err
+	}
+	return appbudgets.ReportSnapshot{Budget: mapBudget(budgetRow), Lines: rows, UnmappedTransactions: unmapped, UncategorizedAmount: uncategorized, Reallocations: reallocations, Closing: closing}, nil
+}
+
+func (r *Repository) LoadDetailedSnapshot(ctx context.Context, owner appbudgets.Owner, period appbudgets.Period) (appbudgets.DetailedReportSnapshot, error) {
 	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) (appbudgets.DetailedReportSnapshot, error) {
-		budget, err := findMonthly(ctx, q, owner, start, end)
+		budget, err := findByPeriod(ctx, q, owner, period)
 		if err != nil {
 			return appbudgets.DetailedReportSnapshot{}, err
 		}
@@ -186,10 +340,25 @@ func (r *Repository) LoadDetailedMonthlySnapshot(ctx context.Context, owner appb
 		for _, mapping := range mappings {
 			categories[mapping.lineID] = append(categories[mapping.lineID], mapping.category)
 		}
+		thresholds, err := listAlertRules(ctx, q, budget.ID)
+		if err != nil {
+			return appbudgets.DetailedReportSnapshot{}, err
+		}
+		members, err := listMemberAllocations(ctx, q, budget.ID)
+		if err != nil {
+			return appbudgets.DetailedReportSnapshot{}, err
+		}
+		originals, err := listOriginalAllocations(ctx, q, budget.ID)
+		if err != nil {
+			return appbudgets.DetailedReportSnapshot{}, err
+		}
 		detailedLines := make([]appbudgets.DetailedReportLineData, 0, len(lines))
 		lineIndexes := make(map[int64]int, len(lines))
 		for _, line := range lines {
 			line.Categories = nonNilCategories(categories[line.ID])
+			line.AlertThresholds = nonNilThresholds(thresholds[line.ID])
+			line.MemberAllocations = nonNilMemberAllocations(members[line.ID])
+			line.OriginalAllocationAmount = originals[line.ID]
 			lineIndexes[line.ID] = len(detailedLines)
 			detailedLines = append(detailedLines, appbudgets.DetailedReportLineData{ReportLineData: line, Transactions: []appbudgets.DetailedTransaction{}})
 		}
@@ -222,7 +391,106 @@ func (r *Repository) LoadDetailedMonthlySnapshot(ctx context.Context, owner appb
 		if err != nil {
 			return appbudgets.DetailedReportSnapshot{}, err
 		}
-		return appbudgets.DetailedReportSnapshot{Budget: budget, Lines: detailedLines, UnmappedTransactions: unmapped, UncategorizedAmount: uncategorized}, nil
+		closing, err := findOpenClosing(ctx, q, budget.ID)
+		if err != nil {
+			return appbudgets.DetailedReportSnapshot{}, err
+		}
+		return appbudgets.DetailedReportSnapshot{Budget: budget, Lines: detailedLines, UnmappedTransactions: unmapped, UncategorizedAmount: uncategorized, Closing: closing}, nil
+	})
+}
+
+func (r *Repository) LoadLintSnapshot(ctx context.Context, budgetID int64) (appbudgets.LintSnapshot, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) (appbudgets.LintSnapshot, error) {
+		row, err := q.GetBudgetById(ctx, budgetID)
+		if err != nil {
+			return appbudgets.LintSnapshot{}, mapBudgetError(err)
+		}
+		budget, err := loadBudget(ctx, q, mapBudget(row))
+		if err != nil {
+			return appbudgets.LintSnapshot{}, err
+		}
+		rows, err := q.ListCategories(ctx, false)
+		if err != nil {
+			return appbudgets.LintSnapshot{}, mapCategoryError(err)
+		}
+		categories := make([]appbudgets.Category, 0, len(rows))
+		for _, category := range rows {
+			categories = append(categories, appbudgets.Category{ID: category.ID, Code: category.Code, Name: category.Name})
+		}
+		unmapped, err := listUnmappedTransactions(ctx, q, budgetID)
+		if err != nil {
+			return appbudgets.LintSnapshot{}, err
+		}
+		return appbudgets.LintSnapshot{Budget: budget, ActiveCategories: categories, UnmappedTransactions: unmapped}, nil
+	})
+}
+
+func (r *Repository) ListLineHistory(ctx context.Context, budgetID int64, from time.Time) ([]appbudgets.HistoryTransaction, error) {
+	rows, err := sqlc.New(r.pool).ListBudgetLineHistoryTransactions(ctx, sqlc.ListBudgetLineHistoryTransactionsParams{HistoryStart: date(from), BudgetID: budgetID})
+	if err != nil {
+		return nil, mapBudgetError(err)
+	}
+	result := make([]appbudgets.HistoryTransaction, 0, len(rows))
+	for _, row := range rows {
+		amount, err := numericString(row.Amount)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		result = append(result, appbudgets.HistoryTransaction{LineID: row.BudgetLineID, ID: row.ID, TransactionDate: row.TransactionDate.Time, Description: row.Description, Amount: amount})
+	}
+	return result, nil
+}
+
+func (r *Repository) LoadTrendSnapshot(ctx context.Context, owner appbudgets.Owner, period appbudgets.Period) (appbudgets.TrendSnapshot, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) (appbudgets.TrendSnapshot, error) {
+		lineRows, err := q.ListBudgetTrendLines(ctx, sqlc.ListBudgetTrendLinesParams{PeriodStart: date(period.Start), PeriodEnd: date(period.End), HouseholdID: owner.HouseholdID, UserID: owner.UserID})
+		if err != nil {
+			return appbudgets.TrendSnapshot{}, mapBudgetError(err)
+		}
+		snapshot := appbudgets.TrendSnapshot{Lines: make([]appbudgets.TrendLineData, 0, len(lineRows))}
+		categories := make(map[int64][]appbudgets.Category)
+		loaded := make(map[int64]bool)
+		for _, row := range lineRows {
+			if !loaded[row.BudgetID] {
+				mappings, err := listLineCategories(ctx, q, row.BudgetID)
+				if err != nil {
+					return appbudgets.TrendSnapshot{}, err
+				}
+				for _, mapping := range mappings {
+					categories[mapping.lineID] = append(categories[mapping.lineID], mapping.category)
+				}
+				loaded[row.BudgetID] = true
+			}
+			allocation, err := numericString(row.AllocationAmount)
+			if err != nil {
+				return appbudgets.TrendSnapshot{}, apperrors.Internal(err)
+			}
+			actual, err := numericString(row.ActualAmount)
+			if err != nil {
+				return appbudgets.TrendSnapshot{}, apperrors.Internal(err)
+			}
+			snapshot.Lines = append(snapshot.Lines, appbudgets.TrendLineData{
+				BudgetID: row.BudgetID, PeriodStart: row.PeriodStart.Time, LineID: row.ID, Name: row.Name,
+				AllocationAmount: allocation, ActualAmount: actual, SortOrder: row.SortOrder, Categories: nonNilCategories(categories[row.ID]),
+			})
+		}
+		categoryRows, err := q.ListBudgetTrendCategoryActuals(ctx, sqlc.ListBudgetTrendCategoryActualsParams{PeriodStart: date(period.Start), PeriodEnd: date(period.End), HouseholdID: owner.HouseholdID, UserID: owner.UserID})
+		if err != nil {
+			return appbudgets.TrendSnapshot{}, mapBudgetError(err)
+		}
+		snapshot.Categories = make([]appbudgets.TrendCategoryData, 0, len(categoryRows))
+		for _, row := range categoryRows {
+			actual, err := numericString(row.ActualAmount)
+			if err != nil {
+				return appbudgets.TrendSnapshot{}, apperrors.Internal(err)
+			}
+			item := appbudgets.TrendCategoryData{Month: row.Month.Time, ActualAmount: actual}
+			if row.CategoryID != nil && row.CategoryCode != nil && row.CategoryName != nil {
+				item.Category = &appbudgets.Category{ID: *row.CategoryID, Code: *row.CategoryCode, Name: *row.CategoryName}
+			}
+			snapshot.Categories = append(snapshot.Categories, item)
+		}
+		return snapshot, nil
 	})
 }
 
@@ -249,43 +517,48 @@ func mapDetailedTransaction(row sqlc.ListDetailedBudgetTransactionsRow) (appbudg
 	return item, nil
 }
 
-func findMonthly(ctx context.Context, q *sqlc.Queries, owner appbudgets.Owner, start, end time.Time) (appbudgets.Budget, error) {
+func findByPeriod(ctx context.Context, q *sqlc.Queries, owner appbudgets.Owner, period appbudgets.Period) (appbudgets.Budget, error) {
 	var row sqlc.Budget
 	var err error
 	if owner.HouseholdID != nil {
-		row, err = q.GetHouseholdBudgetByPeriod(ctx, sqlc.GetHouseholdBudgetByPeriodParams{HouseholdID: *owner.HouseholdID, PeriodStart: date(start), PeriodEnd: date(end)})
+		row, err = q.GetHouseholdBudgetByPeriod(ctx, sqlc.GetHouseholdBudgetByPeriodParams{HouseholdID: *owner.HouseholdID, PeriodKind: string(period.Kind), PeriodStart: date(period.Start), PeriodEnd: date(period.End)})
 	} else if owner.UserID != nil {
-		row, err = q.GetUserBudgetByPeriod(ctx, sqlc.GetUserBudgetByPeriodParams{UserID: *owner.UserID, PeriodStart: date(start), PeriodEnd: date(end)})
+		row, err = q.GetUserBudgetByPeriod(ctx, sqlc.GetUserBudgetByPeriodParams{UserID: *owner.UserID, PeriodKind: string(period.Kind), PeriodStart: date(period.Start), PeriodEnd: date(period.End)})
 	} else {
 		return appbudgets.Budget{}, apperrors.Validation("budget owner is required")
 	}
 	return mapBudget(row), mapBudgetError(err)
 }
 
-func findLatestPrior(ctx context.Context, q *sqlc.Queries, owner appbudgets.Owner, start time.Time) (appbudgets.Budget, error) {
+// findLatestPrior only considers budgets of the same period kind so a weekly
+// budget never inherits the allocations of a monthly one.
+func findLatestPrior(ctx context.Context, q *sqlc.Queries, owner appbudgets.Owner, period appbudgets.Period) (appbudgets.Budget, error) {
 	var row sqlc.Budget
 	var err error
 	if owner.HouseholdID != nil {
-		row, err = q.GetLatestPriorHouseholdBudget(ctx, sqlc.GetLatestPriorHouseholdBudgetParams{HouseholdID: *owner.HouseholdID, PeriodStart: date(start)})
+		row, err = q.GetLatestPriorHouseholdBudget(ctx, sqlc.GetLatestPriorHouseholdBudgetParams{HouseholdID: *owner.HouseholdID, PeriodKind: string(period.Kind), PeriodStart: date(period.Start)})
 	} else if owner.UserID != nil {
-		row, err = q.GetLatestPriorUserBudget(ctx, sqlc.GetLatestPriorUserBudgetParams{UserID: *owner.UserID, PeriodStart: date(start)})
+		row, err = q.GetLatestPriorUserBudget(ctx, sqlc.GetLatestPriorUserBudgetParams{UserID: *owner.UserID, PeriodKind: string(period.Kind), PeriodStart: date(period.Start)})
 	} else {
 		return appbudgets.Budget{}, apperrors.Validation("budget owner is required")
 	}
 	return mapBudget(row), mapBudgetError(err)
 }
 
-func createBudget(ctx context.Context, q *sqlc.Queries, owner appbudgets.Owner, start, end time.Time, sourceID *int64) (appbudgets.Budget, error) {
+func createBudget(ctx context.Context, q *sqlc.Queries, owner appbudgets.Owner, period appbudgets.Period, sourceID *int64) (appbudgets.Budget, error) {
 	var row sqlc.Budget
 	var err error
 	if owner.HouseholdID != nil {
-		row, err = q.CreateHouseholdBudget(ctx, sqlc.CreateHouseholdBudgetParams{HouseholdID: *owner.HouseholdID, PeriodStart: date(start), PeriodEnd: date(end), SourceBudgetID: sourceID})
+		row, err = q.CreateHouseholdBudget(ctx, sqlc.CreateHouseholdBudgetParams{HouseholdID: *owner.HouseholdID, PeriodKind: string(period.Kind), PeriodStart: date(period.Start), PeriodEnd: date(period.End), SourceBudgetID: sourceID})
 	} else if owner.UserID != nil {
-		row, err = q.CreateUserBudget(ctx, sqlc.CreateUserBudgetParams{UserID: *owner.UserID, PeriodStart: date(start), PeriodEnd: date(end), SourceBudgetID: sourceID})
+		row, err = q.CreateUserBudget(ctx, sqlc.CreateUserBudgetParams{UserID: *owner.UserID, PeriodKind: string(period.Kind), PeriodStart: date(period.Start), PeriodEnd: date(period.End), SourceBudgetID: sourceID})
 	} else {
 		return appbudgets.Budget{}, apperrors.Validation("budget owner is required")
 	}
-	return mapBudget(row), mapBudgetError(err)
+	if err != nil {
+		return appbudgets.Budget{}, mapCreateBudgetError(err)
+	}
+	return mapBudget(row), nil
 }
 
 func copyStructure(ctx context.Context, q *sqlc.Queries, sourceID, targetID int64) error {
@@ -301,8 +574,26 @@ func copyStructure(ctx context.Context, q *sqlc.Queries, sourceID, targetID int6
 	for _, mapping := range mappings {
 		byLine[mapping.lineID] = append(byLine[mapping.lineID], mapping.category.ID)
 	}
+	thresholds, err := listAlertRules(ctx, q, sourceID)
+	if err != nil {
+		return err
+	}
+	members, err := listMemberAllocations(ctx, q, sourceID)
+	if err != nil {
+		return err
+	}
+	// Reallocations adjust a single period, so the copy starts from the
+	// allocations originally planned.
+	originals, err := listOriginalAllocations(ctx, q, sourceID)
+	if err != nil {
+		return err
+	}
 	for _, source := range lines {
-		created, err := createLine(ctx, q, targetID, source.Name, source.AllocationAmount, source.SortOrder)
+		amount := source.AllocationAmount
+		if original, exists := originals[source.ID]; exists {
+			amount = original
+		}
+		created, err := createLine(ctx, q, targetID, source.Name, amount, source.SortOrder)
 		if err != nil {
 			return err
 		}
@@ -311,6 +602,12 @@ func copyStructure(ctx context.Context, q *sqlc.Queries, sourceID, targetID int6
 				return err
 			}
 		}
+		if err := replaceAlertRules(ctx, q, created.ID, thresholds[source.ID]); err != nil {
+			return err
+		}
+		if err := copyMemberAllocations(ctx, q, targetID, created.ID, members[source.ID]); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -328,8 +625,18 @@ func loadBudget(ctx context.Context, q *sqlc.Queries, budget appbudgets.Budget)
 	for _, mapping := range mappings {
 		categories[mapping.lineID] = append(categories[mapping.lineID], mapping.category)
 	}
+	thresholds, err := listAlertRules(ctx, q, budget.ID)
+	if err != nil {
+		return appbudgets.Budget{}, err
+	}
+	members, err := listMemberAllocations(ctx, q, budget.ID)
+	if err != nil {
+		return appbudgets.Budget{}, err
+	}
 	for i := range lines {
 		lines[i].Categories = nonNilCategories(categories[lines[i].ID])
+		lines[i].AlertThresholds = nonNilThresholds(thresholds[lines[i].ID])
+		lines[i].MemberAllocations = nonNilMemberAllocations(members[lines[i].ID])
 	}
 	budget.Lines = nonNilLines(lines)
 	return budget, nil
@@ -346,6 +653,16 @@ func loadLine(ctx context.Context, q *sqlc.Queries, line appbudgets.Line) (appbu
 			line.Categories = append(line.Categories, mapping.category)
 		}
 	}
+	thresholds, err := listAlertRules(ctx, q, line.BudgetID)
+	if err != nil {
+		return appbudgets.Line{}, err
+	}
+	line.AlertThresholds = nonNilThresholds(thresholds[line.ID])
+	members, err := listMemberAllocations(ctx, q, line.BudgetID)
+	if err != nil {
+		return appbudgets.Line{}, err
+	}
+	line.MemberAllocations = nonNilMemberAllocations(members[line.ID])
 	return line, nil
 }
 
@@ -411,7 +728,10 @@ func updateLine(ctx context.Context, q *sqlc.Queries, input appbudgets.UpdateLin
 	if input.SortOrder != nil {
 		order = *input.SortOrder
 	}
-	row, err := q.UpdateBudgetLine(ctx, sqlc.UpdateBudgetLineParams{SetName: input.Name != nil, Name: name, SetAllocationAmount: input.AllocationAmount != nil, AllocationAmount: amount, SetSortOrder: input.SortOrder != nil, SortOrder: order, ID: input.LineID})
+	row, err := q.UpdateBudgetLine(ctx, sqlc.UpdateBudgetLineParams{SetName: input.Name != nil, Name: name, SetAllocationAmount: input.AllocationAmount != nil, AllocationAmount: amount, SetSortOrder: input.SortOrder != nil, SortOrder: order, ID: input.LineID, ExpectedVersion: input.ExpectedVersion})
+	if errors.Is(err, pgx.ErrNoRows) && input.ExpectedVersion != nil {
+		return appbudgets.Line{}, lineVersionMismatch()
+	}
 	if err != nil {
 		return appbudgets.Line{}, mapLineError(err)
 	}
@@ -445,6 +765,9 @@ func resolveCategoryIDs(ctx context.Context, q *sqlc.Queries, ids []int64, codes
 }
 
 func replaceCategories(ctx context.Context, q *sqlc.Queries, budgetID, lineID int64, categoryIDs []int64) error {
+	if err := checkCategoryOverlap(ctx, q, budgetID, lineID, categoryIDs); err != nil {
+		return err
+	}
 	if err := q.DeleteBudgetLineCategories(ctx, lineID); err != nil {
 		return mapLineError(err)
 	}
@@ -456,6 +779,56 @@ func replaceCategories(ctx context.Context, q *sqlc.Queries, budgetID, lineID in
 	return nil
 }
 
+// checkCategoryOverlap rejects categories already mapped to another line of
+// the budget, naming that line. The unique mapping constraint still guards
+// concurrent writers.
+func checkCategoryOverlap(ctx context.Context, q *sqlc.Queries, budgetID, lineID int64, categoryIDs []int64) error {
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+	mappings, err := listLineCategories(ctx, q, budgetID)
+	if err != nil {
+		return err
+	}
+	for _, mapping := range mappings {
+		if mapping.lineID == lineID || !slices.Contains(categoryIDs, mapping.category.ID) {
+			continue
+		}
+		line, err := q.GetBudgetLineById(ctx, mapping.lineID)
+		if err != nil {
+			return mapLineError(err)
+		}
+		return apperrors.Conflict(apperrors.CodeBudgetCategoryOverlap, fmt.Sprintf("category %q is already mapped to budget line %q", mapping.category.Code, line.Name), nil)
+	}
+	return nil
+}
+
+// listAlertRules returns the alert thresholds of every line in a budget keyed
+// by line id, each in ascending order.
+func listAlertRules(ctx context.Context, q *sqlc.Queries, budgetID int64) (map[int64][]int32, error) {
+	rows, err := q.ListBudgetLineAlertRules(ctx, budgetID)
+	if err != nil {
+		return nil, mapBudgetError(err)
+	}
+	items := make(map[int64][]int32)
+	for _, row := range rows {
+		items[row.BudgetLineID] = append(items[row.BudgetLineID], row.ThresholdPercent)
+	}
+	return items, nil
+}
+
+func replaceAlertRules(ctx context.Context, q *sqlc.Queries, lineID int64, thresholds []int32) error {
+	if err := q.DeleteBudgetLineAlertRules(ctx, lineID); err != nil {
+		return mapLineError(err)
+	}
+	for _, threshold := range thresholds {
+		if err := q.CreateBudgetLineAlertRule(ctx, sqlc.CreateBudgetLineAlertRuleParams{BudgetLineID: lineID, ThresholdPercent: threshold}); err != nil {
+			return mapLineError(err)
+		}
+	}
+	return nil
+}
+
 func createLineCategory(ctx context.Context, q *sqlc.Queries, budgetID, lineID, categoryID int64) error {
 	return mapLineCategoryError(q.CreateBudgetLineCategory(ctx, sqlc.CreateBudgetLineCategoryParams{BudgetID: budgetID, BudgetLineID: lineID, CategoryID: categoryID}))
 }
@@ -480,6 +853,43 @@ func listReportLines(ctx context.Context, q *sqlc.Queries, budgetID int64) ([]ap
 	return items, nil
 }
 
+// listOriginalAllocations returns each line's allocation before reallocations.
+func listOriginalAllocations(ctx context.Context, q *sqlc.Queries, budgetID int64) (map[int64]string, error) {
+	rows, err := q.ListBudgetLineOriginalAllocations(ctx, budgetID)
+	if err != nil {
+		return nil, mapBudgetError(err)
+	}
+	result := make(map[int64]string)
+	for _, row := range rows {
+		original, err := numericString(row.OriginalAllocationAmount)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		result[row.BudgetLineID] = original
+	}
+	return result, nil
+}
+
+func listReallocations(ctx context.Context, q *sqlc.Queries, budgetID int64) ([]appbudgets.Reallocation, error) {
+	rows, err := q.ListBudgetReallocations(ctx, budgetID)
+	if err != nil {
+		return nil, mapBudgetError(err)
+	}
+	items := make([]appbudgets.Reallocation, 0, len(rows))
+	for _, row := range rows {
+		amount, err := numericString(row.Amount)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		items = append(items, appbudgets.Reallocation{
+			ID: row.ID, BudgetID: row.BudgetID,
+			FromLine: appbudgets.LineRef{ID: row.FromLineID, Name: row.FromLineName}, ToLine: appbudgets.LineRef{ID: row.ToLineID, Name: row.ToLineName},
+			Amount: amount, Reason: row.Reason, CreatedBy: appbudgets.Author{ID: row.CreatedByUserID, Name: row.CreatedByUserName}, CreatedAt: row.CreatedAt.Time,
+		})
+	}
+	return items, nil
+}
+
 func listUnmappedTransactions(ctx context.Context, q *sqlc.Queries, budgetID int64) ([]appbudgets.UnmappedTransaction, error) {
 	rows, err := q.ListUnmappedBudgetTransactions(ctx, budgetID)
 	if err != nil {
@@ -537,14 +947,26 @@ func withTransaction[T any](ctx context.Context, pool *pgxpool.Pool, options pgx
 }
 
 func mapBudget(row sqlc.Budget) appbudgets.Budget {
-	return appbudgets.Budget{ID: row.ID, Owner: appbudgets.Owner{HouseholdID: row.HouseholdID, UserID: row.UserID}, PeriodStart: row.PeriodStart.Time, PeriodEnd: row.PeriodEnd.Time, SourceBudgetID: row.SourceBudgetID}
+	return appbudgets.Budget{ID: row.ID, Owner: appbudgets.Owner{HouseholdID: row.HouseholdID, UserID: row.UserID}, PeriodKind: appbudgets.PeriodKind(row.PeriodKind), PeriodStart: row.PeriodStart.Time, PeriodEnd: row.PeriodEnd.Time, SourceBudgetID: row.SourceBudgetID}
 }
 func mapLine(row sqlc.BudgetLine) (appbudgets.Line, error) {
 	amount, err := numericString(row.AllocationAmount)
 	if err != nil {
 		return appbudgets.Line{}, apperrors.Internal(err)
 	}
-	return appbudgets.Line{ID: row.ID, BudgetID: row.BudgetID, Name: row.Name, AllocationAmount: amount, SortOrder: row.SortOrder}, nil
+	return appbudgets.Line{ID: row.ID, BudgetID: row.BudgetID, Name: row.Name, AllocationAmount: amount, SortOrder: row.SortOrder, Version: row.Version}, nil
+}
+
+// checkLineVersion fails early with the precondition error; the line's UPDATE
+// and DELETE repeat the comparison so a concurrent write cannot slip between.
+func checkLineVersion(current int64, expected *int64) error {
+	if expected != nil && *expected != current {
+		return lineVersionMismatch()
+	}
+	return nil
+}
+func lineVersionMismatch() error {
+	return apperrors.PreconditionFailed(apperrors.CodeVersionMismatch, "budget line version mismatch", nil)
 }
 func date(value time.Time) pgtype.Date { return pgtype.Date{Time: value, Valid: true} }
 func numeric(value string) (pgtype.Numeric, error) {
@@ -571,13 +993,32 @@ func numericString(value pgtype.Numeric) (string, error) {
 func mapBudgetError(err error) error {
 	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeBudgetNotFound, NotFoundMessage: "budget not found", ConflictCode: apperrors.CodeBudgetConflict, ConflictMessage: "budget already exists or violates an invariant"})
 }
+func mapCreateBudgetError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23P01" {
+		return apperrors.Conflict(apperrors.CodeBudgetConflict, "budget period overlaps another budget of the same kind for this owner", err)
+	}
+	return mapBudgetError(err)
+}
 func mapLineError(err error) error {
 	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeBudgetLineNotFound, NotFoundMessage: "budget line not found", ConflictCode: apperrors.CodeBudgetConflict, ConflictMessage: "budget line violates an invariant"})
 }
+func mapReallocationError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.ConstraintName {
+		case "budget_line_allocation_amount_check":
+			return apperrors.Conflict(apperrors.CodeBudgetConflict, "reallocation amount exceeds the source line allocation", err)
+		case "budget_reallocation_created_by_user_id_fkey":
+			return apperrors.NotFound(apperrors.CodeUserNotFound, "user not found", err)
+		}
+	}
+	return mapLineError(err)
+}
 func mapLineCategoryError(err error) error {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "budget_line_category_budget_id_category_id_key" {
-		return apperrors.Conflict(apperrors.CodeBudgetConflict, "category already mapped to another budget line", err)
+		return apperrors.Conflict(apperrors.CodeBudgetCategoryOverlap, "category already mapped to another budget line", err)
 	}
 	return mapLineError(err)
 }
@@ -590,6 +1031,12 @@ func nonNilCategories(items []appbudgets.Category) []appbudgets.Category {
 	}
 	return items
 }
+func nonNilThresholds(items []int32) []int32 {
+	if items == nil {
+		return []int32{}
+	}
+	return items
+}
 func nonNilLines(items []appbudgets.Line) []appbudgets.Line {
 	if items == nil {
 		return []appbudgets.Line{}
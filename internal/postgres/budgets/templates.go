@@ -0,0 +1,335 @@
+package budgets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+	"rdmm404/voltr-finance/internal/postgres"
+)
+
+func (r *Repository) CreateTemplate(ctx context.Context, input appbudgets.CreateTemplateInput) (appbudgets.Template, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appbudgets.Template, error) {
+		row, err := q.CreateBudgetTemplate(ctx, sqlc.CreateBudgetTemplateParams{HouseholdID: input.Owner.HouseholdID, UserID: input.Owner.UserID, Name: input.Name})
+		if err != nil {
+			return appbudgets.Template{}, mapTemplateError(err)
+		}
+		if err := createTemplateLines(ctx, q, row.ID, input.Lines); err != nil {
+			return appbudgets.Template{}, err
+		}
+		return loadTemplate(ctx, q, row)
+	})
+}
+
+func (r *Repository) GetTemplate(ctx context.Context, id int64) (appbudgets.Template, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) (appbudgets.Template, error) {
+		row, err := q.GetBudgetTemplateById(ctx, id)
+		if err != nil {
+			return appbudgets.Template{}, mapTemplateError(err)
+		}
+		return loadTemplate(ctx, q, row)
+	})
+}
+
+// FindTemplateByName matches the name case-insensitively within the owner's
+// templates.
+func (r *Repository) FindTemplateByName(ctx context.Context, owner appbudgets.Owner, name string) (appbudgets.Template, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) (appbudgets.Template, error) {
+		var row sqlc.BudgetTemplate
+		var err error
+		if owner.HouseholdID != nil {
+			row, err = q.GetHouseholdBudgetTemplateByName(ctx, sqlc.GetHouseholdBudgetTemplateByNameParams{HouseholdID: *owner.HouseholdID, Name: name})
+		} else if owner.UserID != nil {
+			row, err = q.GetUserBudgetTemplateByName(ctx, sqlc.GetUserBudgetTemplateByNameParams{UserID: *owner.UserID, Name: name})
+		} else {
+			return appbudgets.Template{}, apperrors.Validation("budget template owner is required")
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return appbudgets.Template{}, apperrors.NotFound(apperrors.CodeBudgetTemplateNotFound, fmt.Sprintf("budget template %q not found", name), err)
+		}
+		if err != nil {
+			return appbudgets.Template{}, mapTemplateError(err)
+		}
+		return loadTemplate(ctx, q, row)
+	})
+}
+
+func (r *Repository) ListTemplates(ctx context.Context, owner appbudgets.Owner) ([]appbudgets.Template, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) ([]appbudgets.Template, error) {
+		rows, err := q.ListBudgetTemplates(ctx, sqlc.ListBudgetTemplatesParams{HouseholdID: owner.HouseholdID, UserID: owner.UserID})
+		if err != nil {
+			return nil, mapTemplateError(err)
+		}
+		return loadTemplates(ctx, q, rows)
+	})
+}
+
+func (r *Repository) UpdateTemplate(ctx context.Context, input appbudgets.UpdateTemplateInput) (appbudgets.Template, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appbudgets.Template, error) {
+		name := ""
+		if input.Name != nil {
+			name = *input.Name
+		}
+		row, err := q.UpdateBudgetTemplate(ctx, sqlc.UpdateBudgetTemplateParams{SetName: input.Name != nil, Name: name, ID: input.ID})
+		if err != nil {
+			return appbudgets.Template{}, mapTemplateError(err)
+		}
+		if input.Lines != nil {
+			if err := q.DeleteBudgetTemplateLines(ctx, row.ID); err != nil {
+				return appbudgets.Template{}, mapTemplateError(err)
+			}
+			if err := createTemplateLines(ctx, q, row.ID, *input.Lines); err != nil {
+				return appbudgets.Template{}, err
+			}
+		}
+		return loadTemplate(ctx, q, row)
+	})
+}
+
+func (r *Repository) DeleteTemplate(ctx context.Context, id int64) error {
+	q := sqlc.New(r.pool)
+	if _, err := q.GetBudgetTemplateById(ctx, id); err != nil {
+		return mapTemplateError(err)
+	}
+	return mapTemplateError(q.DeleteBudgetTemplate(ctx, id))
+}
+
+func (r *Repository) SaveTemplate(ctx context.Context, input appbudgets.SaveTemplateInput) (appbudgets.Template, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appbudgets.Template, error) {
+		budgetRow, err := q.GetBudgetById(ctx, input.BudgetID)
+		if err != nil {
+			return appbudgets.Template{}, mapBudgetError(err)
+		}
+		budget, err := loadBudget(ctx, q, mapBudget(budgetRow))
+		if err != nil {
+			return appbudgets.Template{}, err
+		}
+		originals, err := listOriginalAllocations(ctx, q, budget.ID)
+		if err != nil {
+			return appbudgets.Template{}, err
+		}
+		row, err := q.CreateBudgetTemplate(ctx, sqlc.CreateBudgetTemplateParams{HouseholdID: budget.Owner.HouseholdID, UserID: budget.Owner.UserID, Name: input.Name})
+		if err != nil {
+			return appbudgets.Template{}, mapTemplateError(err)
+		}
+		for _, line := range budget.Lines {
+			amount := line.AllocationAmount
+			if original, exists := originals[line.ID]; exists {
+				amount = original
+			}
+			created, err := createTemplateLine(ctx, q, row.ID, line.Name, amount, line.SortOrder)
+			if err != nil {
+				return appbudgets.Template{}, err
+			}
+			for _, category := range line.Categories {
+				if err := createTemplateLineCategory(ctx, q, row.ID, created.ID, category.ID); err != nil {
+					return appbudgets.Template{}, err
+				}
+			}
+		}
+		return loadTemplate(ctx, q, row)
+	})
+}
+
+// ApplyLineChanges applies planned template changes to a budget in one
+// transaction. Removed lines and the categories of updated lines are cleared
+// first so a category can move between lines without tripping the overlap
+// check.
+func (r *Repository) ApplyLineChanges(ctx context.Context, budgetID int64, changes []appbudgets.LineChange) (appbudgets.Budget, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appbudgets.Budget, error) {
+		if err := lockOpenBudget(ctx, q, budgetID); err != nil {
+			return appbudgets.Budget{}, err
+		}
+		for _, change := range changes {
+			if change.Before == nil {
+				continue
+			}
+			row, err := q.GetBudgetLineById(ctx, change.Before.ID)
+			if err != nil {
+				return appbudgets.Budget{}, mapLineError(err)
+			}
+			if row.BudgetID != budgetID {
+				return appbudgets.Budget{}, apperrors.NotFound(apperrors.CodeBudgetLineNotFound, "budget line not found", nil)
+			}
+			if change.Action == appbudgets.LineRemoved {
+				_, err = q.DeleteBudgetLine(ctx, sqlc.DeleteBudgetLineParams{ID: row.ID})
+			} else {
+				err = q.DeleteBudgetLineCategories(ctx, row.ID)
+			}
+			if err != nil {
+				return appbudgets.Budget{}, mapLineError(err)
+			}
+		}
+		for _, change := range changes {
+			if change.After == nil {
+				continue
+			}
+			lineID := int64(0)
+			switch change.Action {
+			case appbudgets.LineUpdated:
+				updated, err := updateLine(ctx, q, appbudgets.UpdateLineInput{LineID: change.Before.ID, AllocationAmount: &change.After.AllocationAmount})
+				if err != nil {
+					return appbudgets.Budget{}, err
+				}
+				if err := checkMemberAmounts(ctx, q, updated); err != nil {
+					return appbudgets.Budget{}, err
+				}
+				lineID = updated.ID
+			case appbudgets.LineAdded:
+				created, err := createLine(ctx, q, budgetID, change.After.Name, change.After.AllocationAmount, change.After.SortOrder)
+				if err != nil {
+					return appbudgets.Budget{}, err
+				}
+				lineID = created.ID
+			default:
+				return appbudgets.Budget{}, apperrors.Internal(fmt.Errorf("unexpected line change %q", change.Action))
+			}
+			categoryIDs := make([]int64, 0, len(change.After.Categories))
+			for _, category := range change.After.Categories {
+				categoryIDs = append(categoryIDs, category.ID)
+			}
+			if err := replaceCategories(ctx, q, budgetID, lineID, categoryIDs); err != nil {
+				return appbudgets.Budget{}, err
+			}
+		}
+		budgetRow, err := q.GetBudgetById(ctx, budgetID)
+		if err != nil {
+			return appbudgets.Budget{}, mapBudgetError(err)
+		}
+		return loadBudget(ctx, q, mapBudget(budgetRow))
+	})
+}
+
+// copyTemplate creates a budget's lines and category mappings from a template.
+func copyTemplate(ctx context.Context, q *sqlc.Queries, templateID, budgetID int64) error {
+	row, err := q.GetBudgetTemplateById(ctx, templateID)
+	if err != nil {
+		return mapTemplateError(err)
+	}
+	template, err := loadTemplate(ctx, q, row)
+	if err != nil {
+		return err
+	}
+	for _, source := range template.Lines {
+		created, err := createLine(ctx, q, budgetID, source.Name, source.AllocationAmount, source.SortOrder)
+		if err != nil {
+			return err
+		}
+		for _, category := range source.Categories {
+			if err := createLineCategory(ctx, q, budgetID, created.ID, category.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createTemplateLines resolves each line's categories and rejects a category
+// mapped by two lines, naming both.
+func createTemplateLines(ctx context.Context, q *sqlc.Queries, templateID int64, lines []appbudgets.TemplateLineInput) error {
+	owners := make(map[int64]string)
+	for _, line := range lines {
+		categoryIDs, err := resolveCategoryIDs(ctx, q, line.CategoryIDs, line.CategoryCodes)
+		if err != nil {
+			return err
+		}
+		for _, categoryID := range categoryIDs {
+			if owner, exists := owners[categoryID]; exists {
+				return apperrors.Conflict(apperrors.CodeBudgetCategoryOverlap, fmt.Sprintf("template lines %q and %q map the same category", owner, line.Name), nil)
+			}
+			owners[categoryID] = line.Name
+		}
+		sortOrder := int32(0)
+		if line.SortOrder != nil {
+			sortOrder = *line.SortOrder
+		}
+		created, err := createTemplateLine(ctx, q, templateID, line.Name, line.AllocationAmount, sortOrder)
+		if err != nil {
+			return err
+		}
+		for _, categoryID := range categoryIDs {
+			if err := createTemplateLineCategory(ctx, q, templateID, created.ID, categoryID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func createTemplateLine(ctx context.Context, q *sqlc.Queries, templateID int64, name, amount string, sortOrder int32) (sqlc.BudgetTemplateLine, error) {
+	numericAmount, err := numeric(amount)
+	if err != nil {
+		return sqlc.BudgetTemplateLine{}, apperrors.Internal(err)
+	}
+	row, err := q.CreateBudgetTemplateLine(ctx, sqlc.CreateBudgetTemplateLineParams{TemplateID: templateID, Name: name, AllocationAmount: numericAmount, SortOrder: sortOrder})
+	return row, mapTemplateError(err)
+}
+
+func createTemplateLineCategory(ctx context.Context, q *sqlc.Queries, templateID, lineID, categoryID int64) error {
+	return mapTemplateError(q.CreateBudgetTemplateLineCategory(ctx, sqlc.CreateBudgetTemplateLineCategoryParams{TemplateID: templateID, TemplateLineID: lineID, CategoryID: categoryID}))
+}
+
+func loadTemplate(ctx context.Context, q *sqlc.Queries, row sqlc.BudgetTemplate) (appbudgets.Template, error) {
+	items, err := loadTemplates(ctx, q, []sqlc.BudgetTemplate{row})
+	if err != nil {
+		return appbudgets.Template{}, err
+	}
+	return items[0], nil
+}
+
+func loadTemplates(ctx context.Context, q *sqlc.Queries, rows []sqlc.BudgetTemplate) ([]appbudgets.Template, error) {
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	lineRows, err := q.ListBudgetTemplateLines(ctx, ids)
+	if err != nil {
+		return nil, mapTemplateError(err)
+	}
+	categoryRows, err := q.ListBudgetTemplateLineCategories(ctx, ids)
+	if err != nil {
+		return nil, mapTemplateError(err)
+	}
+	categories := make(map[int64][]appbudgets.Category)
+	for _, row := range categoryRows {
+		categories[row.TemplateLineID] = append(categories[row.TemplateLineID], appbudgets.Category{ID: row.CategoryID, Code: row.CategoryCode, Name: row.CategoryName})
+	}
+	lines := make(map[int64][]appbudgets.TemplateLine)
+	for _, row := range lineRows {
+		amount, err := numericString(row.AllocationAmount)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		lines[row.TemplateID] = append(lines[row.TemplateID], appbudgets.TemplateLine{
+			ID: row.ID, Name: row.Name, AllocationAmount: amount, SortOrder: row.SortOrder, Categories: nonNilCategories(categories[row.ID]),
+		})
+	}
+	items := make([]appbudgets.Template, 0, len(rows))
+	for _, row := range rows {
+		template := appbudgets.Template{ID: row.ID, Owner: appbudgets.Owner{HouseholdID: row.HouseholdID, UserID: row.UserID}, Name: row.Name, Lines: lines[row.ID]}
+		if template.Lines == nil {
+			template.Lines = []appbudgets.TemplateLine{}
+		}
+		items = append(items, template)
+	}
+	return items, nil
+}
+
+func mapTemplateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.ConstraintName {
+		case "idx_budget_template_household_name", "idx_budget_template_user_name":
+			return apperrors.Conflict(apperrors.CodeBudgetTemplateConflict, "a budget template with this name already exists for the owner", err)
+		case "budget_template_line_category_template_id_category_id_key":
+			return apperrors.Conflict(apperrors.CodeBudgetCategoryOverlap, "category already mapped to another template line", err)
+		}
+	}
+	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeBudgetTemplateNotFound, NotFoundMessage: "budget template not found", ConflictCode: apperrors.CodeBudgetTemplateConflict, ConflictMessage: "budget template violates an invariant"})
+}
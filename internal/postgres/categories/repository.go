@@ -2,6 +2,9 @@ package categories
 
 import (
 	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
 
 	appcategories "rdmm404/voltr-finance/internal/app/categories"
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
@@ -16,7 +19,7 @@ type queries interface {
 	GetActiveCategoryById(context.Context, int64) (sqlc.Category, error)
 	GetActiveCategoryByCode(context.Context, string) (sqlc.Category, error)
 	UpdateCategory(context.Context, sqlc.UpdateCategoryParams) (sqlc.Category, error)
-	DeactivateCategory(context.Context, string) (sqlc.Category, error)
+	DeactivateCategory(context.Context, sqlc.DeactivateCategoryParams) (sqlc.Category, error)
 }
 
 type Repository struct{ queries queries }
@@ -59,16 +62,28 @@ func (r *Repository) Update(ctx context.Context, code string, input appcategorie
 	if input.Name != nil {
 		name = *input.Name
 	}
-	row, err := r.queries.UpdateCategory(ctx, sqlc.UpdateCategoryParams{SetName: input.Name != nil, Name: name, SetDescription: input.Description.Present(), Description: input.Description.Value(), Code: code})
-	return mapCategory(row), mapError(err)
+	row, err := r.queries.UpdateCategory(ctx, sqlc.UpdateCategoryParams{SetName: input.Name != nil, Name: name, SetDescription: input.Description.Present(), Description: input.Description.Value(), Code: code, ExpectedVersion: input.ExpectedVersion})
+	return mapCategory(row), r.mapWriteError(ctx, code, input.ExpectedVersion, err)
 }
-func (r *Repository) Deactivate(ctx context.Context, code string) (appcategories.Category, error) {
-	row, err := r.queries.DeactivateCategory(ctx, code)
-	return mapCategory(row), mapError(err)
+func (r *Repository) Deactivate(ctx context.Context, code string, expectedVersion *int64) (appcategories.Category, error) {
+	row, err := r.queries.DeactivateCategory(ctx, sqlc.DeactivateCategoryParams{Code: code, ExpectedVersion: expectedVersion})
+	return mapCategory(row), r.mapWriteError(ctx, code, expectedVersion, err)
+}
+
+// mapWriteError tells a stale expected version apart from a missing category:
+// both leave the conditional UPDATE without a row.
+func (r *Repository) mapWriteError(ctx context.Context, code string, expectedVersion *int64, err error) error {
+	if expectedVersion == nil || !errors.Is(err, pgx.ErrNoRows) {
+		return mapError(err)
+	}
+	if _, lookupErr := r.queries.GetCategoryByCode(ctx, code); lookupErr != nil {
+		return mapError(lookupErr)
+	}
+	return apperrors.PreconditionFailed(apperrors.CodeVersionMismatch, "category version mismatch", nil)
 }
 
 func mapCategory(row sqlc.Category) appcategories.Category {
-	return appcategories.Category{ID: row.ID, Code: row.Code, Name: row.Name, Description: row.Description, IsActive: row.IsActive}
+	return appcategories.Category{ID: row.ID, Code: row.Code, Name: row.Name, Description: row.Description, IsActive: row.IsActive, Version: row.Version}
 }
 func mapError(err error) error {
 	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeCategoryNotFound, NotFoundMessage: "category not found", ConflictCode: apperrors.CodeCategoryConflict, ConflictMessage: "category already exists or violates an invariant"})
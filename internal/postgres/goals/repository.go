@@ -0,0 +1,289 @@
+package goals
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	appgoals "rdmm404/voltr-finance/internal/app/goals"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+	"rdmm404/voltr-finance/internal/postgres"
+)
+
+// Repository owns the PostgreSQL mechanics behind savings goals and their
+// linked categories.
+type Repository struct{ pool *pgxpool.Pool }
+
+func NewRepository(pool *pgxpool.Pool) *Repository { return &Repository{pool: pool} }
+
+func (r *Repository) Create(ctx context.Context, input appgoals.CreateInput) (appgoals.Goal, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appgoals.Goal, error) {
+		categoryIDs, err := resolveCategoryIDs(ctx, q, input.CategoryIDs, input.CategoryCodes)
+		if err != nil {
+			return appgoals.Goal{}, err
+		}
+		amount, err := numeric(input.TargetAmount)
+		if err != nil {
+			return appgoals.Goal{}, apperrors.Validation("target amount must be a decimal number")
+		}
+		row, err := q.CreateSavingsGoal(ctx, sqlc.CreateSavingsGoalParams{
+			HouseholdID: input.Owner.HouseholdID, UserID: input.Owner.UserID, Name: input.Name,
+			TargetAmount: amount, StartDate: date(*input.StartDate), TargetDate: date(input.TargetDate),
+		})
+		if err != nil {
+			return appgoals.Goal{}, mapGoalError(err)
+		}
+		if err := replaceCategories(ctx, q, row.ID, categoryIDs); err != nil {
+			return appgoals.Goal{}, err
+		}
+		return loadGoal(ctx, q, row)
+	})
+}
+
+func (r *Repository) Get(ctx context.Context, id int64) (appgoals.Goal, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) (appgoals.Goal, error) {
+		row, err := q.GetSavingsGoalById(ctx, id)
+		if err != nil {
+			return appgoals.Goal{}, mapGoalError(err)
+		}
+		return loadGoal(ctx, q, row)
+	})
+}
+
+func (r *Repository) Update(ctx context.Context, input appgoals.UpdateInput) (appgoals.Goal, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (appgoals.Goal, error) {
+		params := sqlc.UpdateSavingsGoalParams{ID: input.ID}
+		if input.Name != nil {
+			params.SetName, params.Name = true, *input.Name
+		}
+		if input.TargetAmount != nil {
+			amount, err := numeric(*input.TargetAmount)
+			if err != nil {
+				return appgoals.Goal{}, apperrors.Validation("target amount must be a decimal number")
+			}
+			params.SetTargetAmount, params.TargetAmount = true, amount
+		}
+		if input.StartDate != nil {
+			params.SetStartDate, params.StartDate = true, date(*input.StartDate)
+		}
+		if input.TargetDate != nil {
+			params.SetTargetDate, params.TargetDate = true, date(*input.TargetDate)
+		}
+		row, err := q.UpdateSavingsGoal(ctx, params)
+		if err != nil {
+			return appgoals.Goal{}, mapGoalError(err)
+		}
+		if input.CategoryIDs != nil || input.CategoryCodes != nil {
+			var ids []int64
+			var codes []string
+			if input.CategoryIDs != nil {
+				ids = *input.CategoryIDs
+			}
+			if input.CategoryCodes != nil {
+				codes = *input.CategoryCodes
+			}
+			categoryIDs, err := resolveCategoryIDs(ctx, q, ids, codes)
+			if err != nil {
+				return appgoals.Goal{}, err
+			}
+			if err := replaceCategories(ctx, q, row.ID, categoryIDs); err != nil {
+				return appgoals.Goal{}, err
+			}
+		}
+		return loadGoal(ctx, q, row)
+	})
+}
+
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	_, err := withTransaction(ctx, r.pool, pgx.TxOptions{}, func(q *sqlc.Queries) (struct{}, error) {
+		if _, err := q.GetSavingsGoalById(ctx, id); err != nil {
+			return struct{}{}, mapGoalError(err)
+		}
+		return struct{}{}, mapGoalError(q.DeleteSavingsGoal(ctx, id))
+	})
+	return err
+}
+
+func (r *Repository) ListTotals(ctx context.Context, query appgoals.TotalsQuery) ([]appgoals.GoalTotals, error) {
+	return withTransaction(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(q *sqlc.Queries) ([]appgoals.GoalTotals, error) {
+		rows, err := q.ListSavingsGoalProgress(ctx, sqlc.ListSavingsGoalProgressParams{
+			PeriodStart: date(query.PeriodStart), AsOf: date(query.AsOf),
+			ID: query.ID, HouseholdID: query.Owner.HouseholdID, UserID: query.Owner.UserID,
+		})
+		if err != nil {
+			return nil, mapGoalError(err)
+		}
+		ids := make([]int64, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+		categories, err := listCategories(ctx, q, ids)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]appgoals.GoalTotals, 0, len(rows))
+		for _, row := range rows {
+			item, err := mapTotals(row)
+			if err != nil {
+				return nil, err
+			}
+			item.Categories = nonNilCategories(categories[row.ID])
+			items = append(items, item)
+		}
+		return items, nil
+	})
+}
+
+func loadGoal(ctx context.Context, q *sqlc.Queries, row sqlc.SavingsGoal) (appgoals.Goal, error) {
+	goal, err := mapGoal(row)
+	if err != nil {
+		return appgoals.Goal{}, err
+	}
+	categories, err := listCategories(ctx, q, []int64{row.ID})
+	if err != nil {
+		return appgoals.Goal{}, err
+	}
+	goal.Categories = nonNilCategories(categories[row.ID])
+	return goal, nil
+}
+
+func listCategories(ctx context.Context, q *sqlc.Queries, goalIDs []int64) (map[int64][]appgoals.Category, error) {
+	result := make(map[int64][]appgoals.Category, len(goalIDs))
+	if len(goalIDs) == 0 {
+		return result, nil
+	}
+	rows, err := q.ListSavingsGoalCategories(ctx, goalIDs)
+	if err != nil {
+		return nil, mapGoalError(err)
+	}
+	for _, row := range rows {
+		result[row.GoalID] = append(result[row.GoalID], appgoals.Category{ID: row.CategoryID, Code: row.CategoryCode, Name: row.CategoryName})
+	}
+	return result, nil
+}
+
+func resolveCategoryIDs(ctx context.Context, q *sqlc.Queries, ids []int64, codes []string) ([]int64, error) {
+	seen := make(map[int64]struct{})
+	resolved := make([]int64, 0, len(ids)+len(codes))
+	appendCategory := func(row sqlc.Category, err error) error {
+		if err != nil {
+			return mapCategoryError(err)
+		}
+		if _, ok := seen[row.ID]; !ok {
+			seen[row.ID] = struct{}{}
+			resolved = append(resolved, row.ID)
+		}
+		return nil
+	}
+	for _, id := range ids {
+		if err := appendCategory(q.GetActiveCategoryById(ctx, id)); err != nil {
+			return nil, err
+		}
+	}
+	for _, code := range codes {
+		if err := appendCategory(q.GetActiveCategoryByCode(ctx, strings.TrimSpace(code))); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+func replaceCategories(ctx context.Context, q *sqlc.Queries, goalID int64, categoryIDs []int64) error {
+	if err := q.DeleteSavingsGoalCategories(ctx, goalID); err != nil {
+		return mapGoalError(err)
+	}
+	for _, categoryID := range categoryIDs {
+		if err := q.CreateSavingsGoalCategory(ctx, sqlc.CreateSavingsGoalCategoryParams{GoalID: goalID, CategoryID: categoryID}); err != nil {
+			return mapGoalError(err)
+		}
+	}
+	return nil
+}
+
+func withTransaction[T any](ctx context.Context, pool *pgxpool.Pool, options pgx.TxOptions, operation func(*sqlc.Queries) (T, error)) (T, error) {
+	var zero T
+	tx, err := pool.BeginTx(ctx, options)
+	if err != nil {
+		return zero, mapGoalError(err)
+	}
+	defer tx.Rollback(ctx)
+	result, err := operation(sqlc.New(tx))
+	if err != nil {
+		return zero, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return zero, mapGoalError(err)
+	}
+	return result, nil
+}
+
+func mapGoal(row sqlc.SavingsGoal) (appgoals.Goal, error) {
+	amount, err := numericString(row.TargetAmount)
+	if err != nil {
+		return appgoals.Goal{}, apperrors.Internal(err)
+	}
+	return appgoals.Goal{ID: row.ID, Owner: appgoals.Owner{HouseholdID: row.HouseholdID, UserID: row.UserID}, Name: row.Name, TargetAmount: amount, StartDate: row.StartDate.Time, TargetDate: row.TargetDate.Time}, nil
+}
+
+func mapTotals(row sqlc.ListSavingsGoalProgressRow) (appgoals.GoalTotals, error) {
+	goal, err := mapGoal(sqlc.SavingsGoal{ID: row.ID, HouseholdID: row.HouseholdID, UserID: row.UserID, Name: row.Name, TargetAmount: row.TargetAmount, StartDate: row.StartDate, TargetDate: row.TargetDate})
+	if err != nil {
+		return appgoals.GoalTotals{}, err
+	}
+	contributed, err := numericString(row.ContributedAmount)
+	if err != nil {
+		return appgoals.GoalTotals{}, apperrors.Internal(err)
+	}
+	withdrawn, err := numericString(row.WithdrawnAmount)
+	if err != nil {
+		return appgoals.GoalTotals{}, apperrors.Internal(err)
+	}
+	periodNet, err := numericString(row.PeriodNetAmount)
+	if err != nil {
+		return appgoals.GoalTotals{}, apperrors.Internal(err)
+	}
+	return appgoals.GoalTotals{Goal: goal, ContributedAmount: contributed, WithdrawnAmount: withdrawn, PeriodNetAmount: periodNet}, nil
+}
+
+func date(value time.Time) pgtype.Date { return pgtype.Date{Time: value, Valid: true} }
+func numeric(value string) (pgtype.Numeric, error) {
+	var result pgtype.Numeric
+	if err := result.Scan(value); err != nil {
+		return pgtype.Numeric{}, fmt.Errorf("parse numeric: %w", err)
+	}
+	return result, nil
+}
+func numericString(value pgtype.Numeric) (string, error) {
+	raw, err := value.Value()
+	if err != nil {
+		return "", fmt.Errorf("format numeric: %w", err)
+	}
+	if raw == nil {
+		return "0", nil
+	}
+	result, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected numeric value %T", raw)
+	}
+	return result, nil
+}
+func mapGoalError(err error) error {
+	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeGoalNotFound, NotFoundMessage: "savings goal not found", ConflictCode: apperrors.CodeGoalConflict, ConflictMessage: "savings goal violates an invariant"})
+}
+func mapCategoryError(err error) error {
+	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeCategoryNotFound, NotFoundMessage: "category not found", ConflictCode: apperrors.CodeGoalConflict, ConflictMessage: "category violates a savings goal invariant"})
+}
+func nonNilCategories(items []appgoals.Category) []appgoals.Category {
+	if items == nil {
+		return []appgoals.Category{}
+	}
+	return items
+}
+
+var _ appgoals.Repository = (*Repository)(nil)
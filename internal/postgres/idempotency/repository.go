@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	appidempotency "rdmm404/voltr-finance/internal/app/idempotency"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+	"rdmm404/voltr-finance/internal/postgres"
+)
+
+type Repository struct{ pool *pgxpool.Pool }
+
+func NewRepository(pool *pgxpool.Pool) *Repository { return &Repository{pool: pool} }
+
+func (r *Repository) Claim(ctx context.Context, key, fingerprint string, now, expiresAt time.Time) (bool, error) {
+	_, err := sqlc.New(r.pool).ClaimIdempotencyKey(ctx, sqlc.ClaimIdempotencyKeyParams{
+		Key: key, Fingerprint: fingerprint, ExpiresAt: timestamptz(expiresAt), Now: timestamptz(now),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, mapError(err)
+	}
+	return true, nil
+}
+
+func (r *Repository) Get(ctx context.Context, key string, now time.Time) (appidempotency.Record, error) {
+	row, err := sqlc.New(r.pool).GetIdempotencyKey(ctx, sqlc.GetIdempotencyKeyParams{Key: key, Now: timestamptz(now)})
+	if err != nil {
+		return appidempotency.Record{}, mapError(err)
+	}
+	record := appidempotency.Record{Key: row.Key, Fingerprint: row.Fingerprint}
+	if row.StatusCode != nil {
+		header := map[string]string{}
+		if err := json.Unmarshal(row.ResponseHeaders, &header); err != nil {
+			return appidempotency.Record{}, apperrors.Internal(err)
+		}
+		record.Response = &appidempotency.Response{StatusCode: int(*row.StatusCode), Header: header, Body: row.ResponseBody}
+	}
+	return record, nil
+}
+
+func (r *Repository) Complete(ctx context.Context, key string, response appidempotency.Response) error {
+	header := response.Header
+	if header == nil {
+		header = map[string]string{}
+	}
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	return mapError(sqlc.New(r.pool).CompleteIdempotencyKey(ctx, sqlc.CompleteIdempotencyKeyParams{
+		Key: key, StatusCode: int32(response.StatusCode), ResponseHeaders: encoded, ResponseBody: response.Body,
+	}))
+}
+
+func (r *Repository) Release(ctx context.Context, key string) error {
+	return mapError(sqlc.New(r.pool).ReleaseIdempotencyKey(ctx, key))
+}
+
+func (r *Repository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	count, err := sqlc.New(r.pool).DeleteExpiredIdempotencyKeys(ctx, timestamptz(now))
+	return count, mapError(err)
+}
+
+func timestamptz(value time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: value, Valid: true}
+}
+
+func mapError(err error) error {
+	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeIdempotencyKeyNotFound, NotFoundMessage: "idempotency key not found", ConflictCode: apperrors.CodeIdempotencyKeyInUse, ConflictMessage: "idempotency key is in use"})
+}
+
+var _ appidempotency.Repository = (*Repository)(nil)
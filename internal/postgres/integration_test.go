@@ -4,24 +4,38 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+	appattachments "rdmm404/voltr-finance/internal/app/attachments"
 	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
 	appcategories "rdmm404/voltr-finance/internal/app/categories"
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	appgoals "rdmm404/voltr-finance/internal/app/goals"
+	appidempotency "rdmm404/voltr-finance/internal/app/idempotency"
 	"rdmm404/voltr-finance/internal/app/patch"
 	apptransactions "rdmm404/voltr-finance/internal/app/transactions"
 	appusers "rdmm404/voltr-finance/internal/app/users"
+	appwebhooks "rdmm404/voltr-finance/internal/app/webhooks"
+	"rdmm404/voltr-finance/internal/blobstore"
 	"rdmm404/voltr-finance/internal/database/sqlc"
+	postgresalerts "rdmm404/voltr-finance/internal/postgres/alerts"
+	postgresattachments "rdmm404/voltr-finance/internal/postgres/attachments"
 	postgresbudgets "rdmm404/voltr-finance/internal/postgres/budgets"
 	postgrescategories "rdmm404/voltr-finance/internal/postgres/categories"
+	postgresgoals "rdmm404/voltr-finance/internal/postgres/goals"
 	postgreshouseholds "rdmm404/voltr-finance/internal/postgres/households"
+	postgresidempotency "rdmm404/voltr-finance/internal/postgres/idempotency"
 	postgrestransactions "rdmm404/voltr-finance/internal/postgres/transactions"
 	postgresusers "rdmm404/voltr-finance/internal/postgres/users"
+	postgreswebhooks "rdmm404/voltr-finance/internal/postgres/webhooks"
 )
 
 type identityResolver struct{ id int64 }
@@ -43,6 +57,13 @@ func (r categoryResolver) ResolveActiveCategoryID(ctx context.Context, id *int64
 	return &item.ID, nil
 }
 
+type recordingSender struct{ messages []appwebhooks.Message }
+
+func (s *recordingSender) Send(_ context.Context, message appwebhooks.Message) (int, error) {
+	s.messages = append(s.messages, message)
+	return 204, nil
+}
+
 func TestPostgresAdaptersEndToEnd(t *testing.T) {
 	if os.Getenv("VOLTR_INTEGRATION_TEST") == "" {
 		t.Skip("set VOLTR_INTEGRATION_TEST=1 to run against PostgreSQL")
@@ -110,6 +131,21 @@ func TestPostgresAdaptersEndToEnd(t *testing.T) {
 	if _, err := categoryService.Create(ctx, appcategories.CreateInput{Name: "Duplicate", Code: &category.Code}); !apperrors.IsKind(err, apperrors.KindConflict) {
 		t.Fatalf("duplicate category error=%v", err)
 	}
+	staleVersion := category.Version
+	category, err = categoryService.Update(ctx, appcategories.UpdateInput{Code: category.Code, Name: stringPointer("Adapter Category " + suffix), ExpectedVersion: &staleVersion})
+	if err != nil || category.Version != staleVersion+1 {
+		t.Fatalf("conditional category update=%+v error=%v", category, err)
+	}
+	if _, err := categoryService.Deactivate(ctx, category.Code, &staleVersion); apperrors.CodeOf(err) != apperrors.CodeVersionMismatch {
+		t.Fatalf("stale category deactivate error=%v", err)
+	}
+
+	webhookService := appwebhooks.NewService(postgreswebhooks.NewRepository(pool), &recordingSender{})
+	webhook, err := webhookService.Create(ctx, appwebhooks.CreateInput{URL: "https://hooks.example.com/voltr", HouseholdID: &householdID})
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), `DELETE FROM webhook_subscription WHERE id=$1`, webhook.ID) })
 
 	transactionRepo := postgrestransactions.NewRepository(pool)
 	transactionService := apptransactions.NewService(transactionRepo, identityResolver{id: user.ID}, categoryResolver{service: categoryService})
@@ -130,7 +166,7 @@ func TestPostgresAdaptersEndToEnd(t *testing.T) {
 	updateWait.Add(2)
 	go func() {
 		defer updateWait.Done()
-		_, updateErrors[0] = transactionService.Update(ctx, apptransactions.UpdateInput{ID: transaction.ID, Amount: &updatedAmount})
+		_, updateErrors[0] = transactionService.Update(ctx, apptransactions.UpdateInput{ID: transaction.ID, Amount: &updatedAmount, UpdatedByUserID: &user.ID})
 	}()
 	go func() {
 		defer updateWait.Done()
@@ -145,15 +181,128 @@ func TestPostgresAdaptersEndToEnd(t *testing.T) {
 	if finalTransaction.Hash != wantHash {
 		t.Fatalf("hash=%q want=%q", finalTransaction.Hash, wantHash)
 	}
-	if _, err := transactionService.SoftDelete(ctx, apptransactions.DeleteInput{ID: transaction.ID, DeletedByUserID: user.ID}); err != nil {
+	if finalTransaction.Version != transaction.Version+2 {
+		t.Fatalf("version=%d after two updates of version %d", finalTransaction.Version, transaction.Version)
+	}
+	if _, err := transactionService.Update(ctx, apptransactions.UpdateInput{ID: transaction.ID, Amount: &updatedAmount, ExpectedVersion: &transaction.Version}); !apperrors.IsKind(err, apperrors.KindPrecondition) {
+		t.Fatalf("stale transaction update error=%v", err)
+	}
+	if _, err := transactionService.SoftDelete(ctx, apptransactions.DeleteInput{ID: transaction.ID, DeletedByUserID: user.ID, ExpectedVersion: &transaction.Version}); apperrors.CodeOf(err) != apperrors.CodeVersionMismatch {
+		t.Fatalf("stale transaction delete error=%v", err)
+	}
+	beforeDelete := strconv.FormatInt(finalTransaction.ChangeSequence, 10)
+	if _, err := transactionService.SoftDelete(ctx, apptransactions.DeleteInput{ID: transaction.ID, DeletedByUserID: user.ID, ExpectedVersion: &finalTransaction.Version}); err != nil {
 		t.Fatal(err)
 	}
+	changes, err := transactionService.Changes(ctx, apptransactions.ChangesFilter{Since: beforeDelete})
+	tombstone := slices.IndexFunc(changes.Items, func(item apptransactions.Transaction) bool { return item.ID == transaction.ID })
+	if err != nil || tombstone < 0 || changes.Items[tombstone].DeletedAt == nil || changes.Items[tombstone].ChangeSequence <= finalTransaction.ChangeSequence {
+		t.Fatalf("changes since %s=%+v error=%v", beforeDelete, changes, err)
+	}
 	if _, err := transactionService.Restore(ctx, apptransactions.RestoreInput{ID: transaction.ID, RestoredByUserID: user.ID}); err != nil {
 		t.Fatal(err)
 	}
 	if _, err := transactionService.SoftDelete(ctx, apptransactions.DeleteInput{ID: -1, DeletedByUserID: user.ID}); !apperrors.IsKind(err, apperrors.KindNotFound) {
 		t.Fatalf("missing delete error=%v", err)
 	}
+	for {
+		sent, err := webhookService.Deliver(ctx)
+		if err != nil {
+			t.Fatalf("deliver webhooks: %v", err)
+		}
+		if sent == 0 {
+			break
+		}
+	}
+	deliveries, err := webhookService.ListDeliveries(ctx, webhook.ID, 0)
+	if err != nil || len(deliveries) != 5 || deliveries[0].EventType != "transaction.restored" || deliveries[4].EventType != "transaction.created" {
+		t.Fatalf("webhook deliveries=%+v error=%v", deliveries, err)
+	}
+	for _, delivery := range deliveries {
+		if delivery.Status != appwebhooks.DeliverySucceeded || len(delivery.History) != 1 || *delivery.History[0].StatusCode != 204 {
+			t.Fatalf("webhook delivery=%+v", delivery)
+		}
+	}
+	redelivered, err := webhookService.Redeliver(ctx, deliveries[0].ID)
+	if err != nil || redelivered.Status != appwebhooks.DeliveryPending || redelivered.Attempts != 0 || len(redelivered.History) != 1 {
+		t.Fatalf("redelivered=%+v error=%v", redelivered, err)
+	}
+	changeLog, err := transactionService.History(ctx, transaction.ID)
+	if err != nil || len(changeLog) != 5 {
+		t.Fatalf("history=%+v error=%v", changeLog, err)
+	}
+	for index, action := range []apptransactions.Action{apptransactions.ActionCreated, apptransactions.ActionUpdated, apptransactions.ActionUpdated, apptransactions.ActionDeleted, apptransactions.ActionRestored} {
+		if changeLog[index].Action != action || changeLog[index].TransactionID != transaction.ID {
+			t.Fatalf("changeLog[%d]=%+v", index, changeLog[index])
+		}
+	}
+	if actor := changeLog[0].ActorUserID; actor == nil || *actor != transaction.AuthorID || len(changeLog[0].Changes) == 0 || changeLog[0].Changes[0].Before != nil {
+		t.Fatalf("created history=%+v", changeLog[0])
+	}
+	for _, entry := range changeLog[1:3] {
+		if len(entry.Changes) != 1 {
+			t.Fatalf("updated history=%+v", entry)
+		}
+		switch change := entry.Changes[0]; change.Field {
+		case "amount":
+			if entry.ActorUserID == nil || *entry.ActorUserID != user.ID || change.Before != float64(25.5) || change.After != float64(updatedAmount) {
+				t.Fatalf("amount history=%+v", entry)
+			}
+		case "description":
+			if entry.ActorUserID != nil || change.Before != nil || change.After != updatedDescription {
+				t.Fatalf("description history=%+v", entry)
+			}
+		default:
+			t.Fatalf("updated history=%+v", entry)
+		}
+	}
+	if deleted := changeLog[3]; *deleted.ActorUserID != user.ID || len(deleted.Changes) != 2 || deleted.Changes[0].Field != "deletedAt" || deleted.Changes[1].Field != "deletedByUserId" {
+		t.Fatalf("deleted history=%+v", deleted)
+	}
+	attachmentService := appattachments.NewService(postgresattachments.NewRepository(pool), blobstore.NewFileSystem(t.TempDir()))
+	attachment, err := attachmentService.Upload(ctx, appattachments.UploadInput{TransactionID: transaction.ID, FileName: "receipt.png", Content: strings.NewReader("\x89PNG\r\n\x1a\nreceipt")})
+	if err != nil || attachment.ContentType != "image/png" || attachment.Size != 15 {
+		t.Fatalf("upload attachment=%+v error=%v", attachment, err)
+	}
+	if attachments, err := attachmentService.List(ctx, transaction.ID); err != nil || len(attachments) != 1 || attachments[0].ID != attachment.ID {
+		t.Fatalf("attachments=%+v error=%v", attachments, err)
+	}
+	if _, err := attachmentService.List(ctx, transaction.ID+1_000_000); apperrors.CodeOf(err) != apperrors.CodeTransactionNotFound {
+		t.Fatalf("missing transaction attachments error=%v", err)
+	}
+	if _, err := attachmentService.Upload(ctx, appattachments.UploadInput{TransactionID: transaction.ID + 1_000_000, FileName: "receipt.png", Content: strings.NewReader("\x89PNG\r\n\x1a\nreceipt")}); apperrors.CodeOf(err) != apperrors.CodeTransactionNotFound {
+		t.Fatalf("missing transaction upload error=%v", err)
+	}
+	if err := attachmentService.Delete(ctx, attachment.ID); err != nil {
+		t.Fatalf("delete attachment: %v", err)
+	}
+	if _, _, err := attachmentService.Open(ctx, attachment.ID); apperrors.CodeOf(err) != apperrors.CodeAttachmentNotFound {
+		t.Fatalf("deleted attachment error=%v", err)
+	}
+	second, err := transactionService.Create(ctx, apptransactions.CreateInput{Amount: 5, TransactionDate: transaction.TransactionDate, HouseholdID: &householdID})
+	if err != nil {
+		t.Fatalf("create second transaction: %v", err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), `DELETE FROM "transaction" WHERE id=$1`, second.ID) })
+	for _, sortBy := range []string{"transaction_date", "created_at", "amount", "id"} {
+		var paged []int64
+		filter := apptransactions.ListFilter{HouseholdID: &householdID, Sort: sortBy, SortOrder: "asc", Limit: 1}
+		for {
+			page, err := transactionService.List(ctx, filter)
+			if err != nil {
+				t.Fatalf("list %s page: %v", sortBy, err)
+			}
+			for _, item := range page.Items {
+				paged = append(paged, item.ID)
+			}
+			if filter.Cursor = page.NextCursor; filter.Cursor == "" || len(paged) > 2 {
+				break
+			}
+		}
+		if len(paged) != 2 || paged[0] == paged[1] {
+			t.Fatalf("%s pages=%v", sortBy, paged)
+		}
+	}
 
 	budgetRepo := postgresbudgets.NewRepository(pool)
 	budgetService := appbudgets.NewService(budgetRepo)
@@ -173,7 +322,14 @@ func TestPostgresAdaptersEndToEnd(t *testing.T) {
 	if err != nil || len(line.Categories) != 1 || line.Categories[0].Code != category.Code {
 		t.Fatalf("replace line categories=%+v error=%v", line, err)
 	}
-	if _, err := budgetService.CreateLine(ctx, appbudgets.CreateLineInput{BudgetID: ensured.Budget.ID, Name: "Duplicate mapping", AllocationAmount: "1.00", CategoryIDs: []int64{category.ID}}); !apperrors.IsKind(err, apperrors.KindConflict) || apperrors.MessageOf(err) != "category already mapped to another budget line" {
+	staleLine := line.Version - 1
+	if _, err := budgetService.UpdateLine(ctx, appbudgets.UpdateLineInput{LineID: line.ID, Name: stringPointer("Stale"), ExpectedVersion: &staleLine}); apperrors.CodeOf(err) != apperrors.CodeVersionMismatch {
+		t.Fatalf("stale line update error=%v", err)
+	}
+	if err := budgetService.DeleteLine(ctx, line.ID, &staleLine); apperrors.CodeOf(err) != apperrors.CodeVersionMismatch {
+		t.Fatalf("stale line delete error=%v", err)
+	}
+	if _, err := budgetService.CreateLine(ctx, appbudgets.CreateLineInput{BudgetID: ensured.Budget.ID, Name: "Duplicate mapping", AllocationAmount: "1.00", CategoryIDs: []int64{category.ID}}); !apperrors.IsKind(err, apperrors.KindConflict) || apperrors.CodeOf(err) != apperrors.CodeBudgetCategoryOverlap || apperrors.MessageOf(err) != fmt.Sprintf("category %q is already mapped to budget line %q", category.Code, "Food") {
 		t.Fatalf("category mapping conflict=%v", err)
 	}
 	lineResults := make([]appbudgets.Line, 2)
@@ -190,10 +346,43 @@ func TestPostgresAdaptersEndToEnd(t *testing.T) {
 	if lineErrors[0] != nil || lineErrors[1] != nil || lineResults[0].SortOrder == lineResults[1].SortOrder {
 		t.Fatalf("concurrent lines=%+v errors=%v", lineResults, lineErrors)
 	}
+	weekly, err := budgetService.EnsurePeriod(ctx, appbudgets.PeriodInput{Owner: monthly.Owner, Kind: appbudgets.PeriodWeekly, Date: now})
+	if err != nil || !weekly.Created || weekly.Budget.PeriodKind != appbudgets.PeriodWeekly || weekly.Budget.SourceBudgetID != nil {
+		t.Fatalf("ensure weekly budget inside monthly=%+v error=%v", weekly, err)
+	}
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), `DELETE FROM budget WHERE household_id=$1 AND period_kind IN ('weekly', 'custom')`, householdID)
+	})
+	overlapStart, overlapEnd := weekly.Budget.PeriodEnd, weekly.Budget.PeriodEnd.AddDate(0, 0, 3)
+	if _, err := budgetService.EnsurePeriod(ctx, appbudgets.PeriodInput{Owner: monthly.Owner, Kind: appbudgets.PeriodWeekly, Date: overlapEnd}); err != nil {
+		t.Fatalf("adjacent weekly budget error=%v", err)
+	}
+	if _, err := budgetService.EnsurePeriod(ctx, appbudgets.PeriodInput{Owner: monthly.Owner, Kind: appbudgets.PeriodCustom, Start: &overlapStart, End: &overlapEnd}); err != nil {
+		t.Fatalf("first custom budget error=%v", err)
+	}
+	overlapStart = overlapStart.AddDate(0, 0, 1)
+	if _, err := budgetService.EnsurePeriod(ctx, appbudgets.PeriodInput{Owner: monthly.Owner, Kind: appbudgets.PeriodCustom, Start: &overlapStart, End: &overlapEnd}); !apperrors.IsKind(err, apperrors.KindConflict) {
+		t.Fatalf("overlapping custom budget error=%v", err)
+	}
 	report, err := budgetService.Report(ctx, ensured.Budget.ID)
 	if err != nil || report.Totals.ActualAmount != "30.75" || report.Totals.RemainingAmount != "71.25" {
 		t.Fatalf("report=%+v error=%v", report, err)
 	}
+	reason := "Food ran over"
+	moved, err := budgetService.Reallocate(ctx, appbudgets.ReallocateInput{BudgetID: ensured.Budget.ID, FromLineID: lineResults[0].ID, ToLineID: line.ID, Amount: "0.50", Reason: &reason, UserID: user.ID})
+	if err != nil || moved.FromLine.AllocationAmount != "0.50" || moved.ToLine.AllocationAmount != "100.50" || moved.Reallocation.CreatedBy.ID != user.ID || moved.Reallocation.ToLine.Name != "Food" {
+		t.Fatalf("reallocate=%+v error=%v", moved, err)
+	}
+	if _, err := budgetService.Reallocate(ctx, appbudgets.ReallocateInput{BudgetID: ensured.Budget.ID, FromLineID: lineResults[1].ID, ToLineID: line.ID, Amount: "5.00", UserID: user.ID}); !apperrors.IsKind(err, apperrors.KindConflict) {
+		t.Fatalf("over-allocation reallocation error=%v", err)
+	}
+	if _, err := budgetService.Reallocate(ctx, appbudgets.ReallocateInput{BudgetID: weekly.Budget.ID, FromLineID: lineResults[1].ID, ToLineID: line.ID, Amount: "0.10", UserID: user.ID}); !apperrors.IsKind(err, apperrors.KindNotFound) {
+		t.Fatalf("cross-budget reallocation error=%v", err)
+	}
+	report, err = budgetService.Report(ctx, ensured.Budget.ID)
+	if err != nil || len(report.Reallocations) != 1 || report.Lines[0].OriginalAllocationAmount != "100.00" || report.Lines[0].ReallocatedAmount != "0.50" || report.Totals.RemainingAmount != "71.25" {
+		t.Fatalf("reallocated report=%+v error=%v", report, err)
+	}
 
 	unmappedDescription, unmappedNotes := "Unmapped detail", "shown in dashboard"
 	unmappedTransaction, err := transactionService.Create(ctx, apptransactions.CreateInput{
@@ -231,6 +420,164 @@ func TestPostgresAdaptersEndToEnd(t *testing.T) {
 	if detailed.Totals.ActualAmount != report.Totals.ActualAmount || detailed.Totals.UnmappedActualAmount != "4.25" || detailed.Totals.UncategorizedActualAmount != "4.25" {
 		t.Fatalf("detailed totals=%+v aggregate before unmapped=%+v", detailed.Totals, report.Totals)
 	}
+	if detailed.Lines[0].Forecast.AsOf.IsZero() || detailed.Lines[0].Forecast.Risk == "" {
+		t.Fatalf("detailed forecast=%+v", detailed.Lines[0].Forecast)
+	}
+	half := "50"
+	memberLines := []appbudgets.MemberAllocationInput{{UserID: user.ID, Percent: &half}}
+	line, err = budgetService.UpdateLine(ctx, appbudgets.UpdateLineInput{LineID: line.ID, MemberAllocations: &memberLines})
+	if err != nil || len(line.MemberAllocations) != 1 || line.MemberAllocations[0].Member.ID != user.ID || *line.MemberAllocations[0].Percent != "50.00" {
+		t.Fatalf("line member allocations=%+v error=%v", line, err)
+	}
+	outsiders := []appbudgets.MemberAllocationInput{{UserID: -1, Percent: &half}}
+	if _, err := budgetService.UpdateLine(ctx, appbudgets.UpdateLineInput{LineID: line.ID, MemberAllocations: &outsiders}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("non-member allocation error=%v", err)
+	}
+	tooMuch := "1000000"
+	fixed := []appbudgets.MemberAllocationInput{{UserID: user.ID, Amount: &tooMuch}}
+	if _, err := budgetService.UpdateLine(ctx, appbudgets.UpdateLineInput{LineID: line.ID, MemberAllocations: &fixed}); !apperrors.IsKind(err, apperrors.KindValidation) {
+		t.Fatalf("member amount above the stored allocation error=%v", err)
+	}
+	detailed, err = budgetService.DetailedMonthlyReport(ctx, monthly)
+	if err != nil || len(detailed.Lines[0].Members) == 0 || detailed.Lines[0].Members[0].Member.ID != user.ID || detailed.Lines[0].Members[0].AllocationAmount != "50.25" {
+		t.Fatalf("detailed member spending=%+v error=%v", detailed.Lines[0].Members, err)
+	}
+	historyTransaction, err := transactionService.Create(ctx, apptransactions.CreateInput{Amount: 12, TransactionDate: detailed.Budget.PeriodStart.AddDate(0, -1, 2), HouseholdID: &householdID, CategoryID: &category.ID})
+	if err != nil {
+		t.Fatalf("create history transaction: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), `DELETE FROM "transaction" WHERE id=$1`, historyTransaction.ID)
+	})
+	history, err := budgetRepo.ListLineHistory(ctx, ensured.Budget.ID, detailed.Budget.PeriodStart.AddDate(0, -3, 0))
+	if err != nil || len(history) != 1 || history[0].ID != historyTransaction.ID || history[0].LineID != line.ID {
+		t.Fatalf("line history=%+v error=%v", history, err)
+	}
+	trends, err := budgetService.Trends(ctx, appbudgets.TrendInput{Owner: monthly.Owner, From: detailed.Budget.PeriodStart.AddDate(0, -1, 0), To: detailed.Budget.PeriodStart})
+	if err != nil || len(trends.Months) != 2 || trends.Months[1].BudgetID == nil || *trends.Months[1].BudgetID != detailed.Budget.ID || len(trends.Lines) != 3 || len(trends.Categories) == 0 {
+		t.Fatalf("trends=%+v error=%v", trends, err)
+	}
+	for _, series := range trends.Categories {
+		if series.Category != nil && series.Category.ID == category.ID && (!series.Months[1].Budgeted || series.Months[1].AllocationAmount == "0.00") {
+			t.Fatalf("mapped category trend=%+v", series)
+		}
+	}
+	lint, err := budgetService.Lint(ctx, detailed.Budget.ID)
+	if err != nil || lint.Budget.ID != detailed.Budget.ID || lint.Issues == nil {
+		t.Fatalf("lint=%+v error=%v", lint, err)
+	}
+	template, err := budgetService.SaveTemplate(ctx, appbudgets.SaveTemplateInput{BudgetID: ensured.Budget.ID, Name: "Adapter standard " + suffix})
+	if err != nil || len(template.Lines) != 3 || template.Lines[0].Name != "Food" || template.Lines[0].AllocationAmount != "100.00" || len(template.Lines[0].Categories) != 1 {
+		t.Fatalf("save template=%+v error=%v", template, err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), `DELETE FROM budget_template WHERE id=$1`, template.ID) })
+	if _, err := budgetService.CreateTemplate(ctx, appbudgets.CreateTemplateInput{Owner: monthly.Owner, Name: " ADAPTER standard " + suffix}); apperrors.CodeOf(err) != apperrors.CodeBudgetTemplateConflict {
+		t.Fatalf("duplicate template name error=%v", err)
+	}
+	templateName := template.Name
+	applied, err := budgetService.ApplyTemplate(ctx, appbudgets.ApplyTemplateInput{BudgetID: weekly.Budget.ID, TemplateName: &templateName})
+	if err != nil || !applied.Applied || len(applied.Changes) != 3 || len(applied.Budget.Lines) != 3 || applied.Changes[0].After.ID == 0 {
+		t.Fatalf("apply template=%+v error=%v", applied, err)
+	}
+	if again, err := budgetService.ApplyTemplate(ctx, appbudgets.ApplyTemplateInput{BudgetID: weekly.Budget.ID, TemplateID: &template.ID}); err != nil || again.Applied || len(again.Changes) != 0 {
+		t.Fatalf("reapply template=%+v error=%v", again, err)
+	}
+	templateLines := []appbudgets.TemplateLineInput{{Name: "food", AllocationAmount: "120", CategoryIDs: []int64{category.ID}}}
+	if _, err := budgetService.UpdateTemplate(ctx, appbudgets.UpdateTemplateInput{ID: template.ID, Lines: &templateLines}); err != nil {
+		t.Fatalf("update template: %v", err)
+	}
+	applied, err = budgetService.ApplyTemplate(ctx, appbudgets.ApplyTemplateInput{BudgetID: weekly.Budget.ID, TemplateID: &template.ID, Mode: appbudgets.ApplyReplace})
+	if err != nil || len(applied.Changes) != 3 || len(applied.Budget.Lines) != 1 || applied.Budget.Lines[0].Name != "Food" || applied.Budget.Lines[0].AllocationAmount != "120.00" {
+		t.Fatalf("replace from template=%+v error=%v", applied, err)
+	}
+	fromTemplate, err := budgetService.EnsureMonthly(ctx, appbudgets.MonthlyInput{Owner: monthly.Owner, Year: now.Year() + 3, Month: 1, Template: templateName})
+	if err != nil || !fromTemplate.Created || fromTemplate.Budget.SourceBudgetID != nil || len(fromTemplate.Budget.Lines) != 1 || len(fromTemplate.Budget.Lines[0].Categories) != 1 {
+		t.Fatalf("ensure from template=%+v error=%v", fromTemplate, err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), `DELETE FROM budget WHERE id=$1`, fromTemplate.Budget.ID) })
+
+	goalService := appgoals.NewService(postgresgoals.NewRepository(pool))
+	goalStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	goal, err := goalService.Create(ctx, appgoals.CreateInput{
+		Owner: appgoals.Owner{HouseholdID: &householdID}, Name: "Adapter goal", TargetAmount: "120.00",
+		StartDate: &goalStart, TargetDate: goalStart.AddDate(0, 12, -1), CategoryCodes: []string{category.Code},
+	})
+	if err != nil {
+		t.Fatalf("create goal: %v", err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), `DELETE FROM savings_goal WHERE id=$1`, goal.ID) })
+	if len(goal.Categories) != 1 || goal.Categories[0].ID != category.ID {
+		t.Fatalf("goal categories=%+v", goal.Categories)
+	}
+	periodGoals, err := goalService.ForPeriod(ctx, appgoals.Owner{HouseholdID: &householdID}, detailed.Budget.PeriodStart, detailed.Budget.PeriodEnd)
+	if err != nil || len(periodGoals) != 1 || periodGoals[0].BalanceAmount != "30.75" || periodGoals[0].PeriodNetAmount != "30.75" || periodGoals[0].MonthsRemaining != 12 {
+		t.Fatalf("period goals=%+v error=%v", periodGoals, err)
+	}
+	if _, err := goalService.Update(ctx, appgoals.UpdateInput{ID: goal.ID, CategoryIDs: &[]int64{-1}}); !apperrors.IsKind(err, apperrors.KindNotFound) {
+		t.Fatalf("goal missing category error=%v", err)
+	}
+	if err := goalService.Delete(ctx, goal.ID); err != nil {
+		t.Fatalf("delete goal: %v", err)
+	}
+	if _, err := goalService.Get(ctx, goal.ID, time.Time{}); apperrors.CodeOf(err) != apperrors.CodeGoalNotFound {
+		t.Fatalf("deleted goal error=%v", err)
+	}
+
+	thresholds := []int32{50, 25}
+	line, err = budgetService.UpdateLine(ctx, appbudgets.UpdateLineInput{LineID: line.ID, AlertThresholds: &thresholds})
+	if err != nil || len(line.AlertThresholds) != 2 || line.AlertThresholds[0] != 25 {
+		t.Fatalf("line alert thresholds=%+v error=%v", line, err)
+	}
+	alertService := appalerts.NewService(postgresalerts.NewRepository(pool))
+	alerts, err := alertService.EvaluateTransaction(ctx, transaction.ID)
+	if err != nil || len(alerts) != 1 || alerts[0].LineID != line.ID || alerts[0].ThresholdPercent != 25 || alerts[0].ActualAmount != "30.75" || alerts[0].LineName != "Food" {
+		t.Fatalf("budget alerts=%+v error=%v", alerts, err)
+	}
+	if again, err := alertService.EvaluateTransaction(ctx, transaction.ID); err != nil || len(again) != 0 {
+		t.Fatalf("repeated budget alerts=%+v error=%v", again, err)
+	}
+	listed, err := alertService.List(ctx, appalerts.ListFilter{Owner: appalerts.Owner{HouseholdID: &householdID}})
+	if err != nil || len(listed) != 1 || listed[0].ID != alerts[0].ID || listed[0].TransactionID == nil || *listed[0].TransactionID != transaction.ID {
+		t.Fatalf("listed budget alerts=%+v error=%v", listed, err)
+	}
+
+	closing, err := budgetService.Close(ctx, appbudgets.CloseInput{BudgetID: ensured.Budget.ID, UserID: user.ID})
+	if err != nil || closing.ClosedBy.ID != user.ID || len(closing.Lines) != 3 || closing.Totals.ActualAmount != "30.75" {
+		t.Fatalf("close budget=%+v error=%v", closing, err)
+	}
+	if _, err := budgetService.Close(ctx, appbudgets.CloseInput{BudgetID: ensured.Budget.ID, UserID: user.ID}); apperrors.CodeOf(err) != apperrors.CodeBudgetClosed {
+		t.Fatalf("repeated close error=%v", err)
+	}
+	if _, err := budgetService.UpdateLine(ctx, appbudgets.UpdateLineInput{LineID: line.ID, Name: stringPointer("Closed food")}); apperrors.CodeOf(err) != apperrors.CodeBudgetClosed {
+		t.Fatalf("closed line update error=%v", err)
+	}
+	closedAmount := float32(31)
+	if _, err := transactionService.Update(ctx, apptransactions.UpdateInput{ID: transaction.ID, Amount: &closedAmount}); apperrors.CodeOf(err) != apperrors.CodeBudgetClosed {
+		t.Fatalf("closed period transaction error=%v", err)
+	}
+	if _, err := pool.Exec(ctx, `UPDATE "transaction" SET amount=40.75 WHERE id=$1`, transaction.ID); err != nil {
+		t.Fatal(err)
+	}
+	report, err = budgetService.Report(ctx, ensured.Budget.ID)
+	if err != nil || report.Closing == nil || !report.Closing.Drifted || report.Totals.ActualAmount != "30.75" || report.Closing.CurrentTotals.ActualAmount != "40.75" {
+		t.Fatalf("closed report=%+v error=%v", report, err)
+	}
+	if detailed, err := budgetService.DetailedReport(ctx, ensured.Budget.ID); err != nil || detailed.Closing == nil || !detailed.Closing.Drifted || detailed.Totals.ActualAmount != "30.75" || detailed.Forecast.ProjectedAmount != "30.75" {
+		t.Fatalf("closed detailed report=%+v error=%v", detailed, err)
+	}
+	if _, err := pool.Exec(ctx, `UPDATE "transaction" SET amount=30.75 WHERE id=$1`, transaction.ID); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := budgetService.Reopen(ctx, appbudgets.ReopenInput{BudgetID: ensured.Budget.ID, UserID: user.ID, Reason: "late refund"})
+	if err != nil || reopened.ID != closing.ID || reopened.ReopenedBy == nil || reopened.ReopenReason == nil || *reopened.ReopenReason != "late refund" {
+		t.Fatalf("reopen budget=%+v error=%v", reopened, err)
+	}
+	if _, err := budgetService.Reopen(ctx, appbudgets.ReopenInput{BudgetID: ensured.Budget.ID, UserID: user.ID, Reason: "again"}); !apperrors.IsKind(err, apperrors.KindConflict) {
+		t.Fatalf("repeated reopen error=%v", err)
+	}
+	if report, err := budgetService.Report(ctx, ensured.Budget.ID); err != nil || report.Closing != nil {
+		t.Fatalf("reopened report closing=%+v error=%v", report.Closing, err)
+	}
 
 	next := now.AddDate(0, 1, 0)
 	nextMonthly := appbudgets.MonthlyInput{Owner: monthly.Owner, Year: next.Year(), Month: int(next.Month())}
@@ -242,10 +589,150 @@ func TestPostgresAdaptersEndToEnd(t *testing.T) {
 		go func(i int) { defer wait.Done(); results[i], errs[i] = budgetService.EnsureMonthly(ctx, nextMonthly) }(index)
 	}
 	wait.Wait()
-	if errs[0] != nil || errs[1] != nil || results[0].Budget.ID != results[1].Budget.ID || results[0].Created == results[1].Created || len(results[0].Budget.Lines) != 3 {
+	if errs[0] != nil || errs[1] != nil || results[0].Budget.ID != results[1].Budget.ID || results[0].Created == results[1].Created || len(results[0].Budget.Lines) != 3 || len(results[0].Budget.Lines[0].AlertThresholds) != 2 || len(results[0].Budget.Lines[0].MemberAllocations) != 1 {
 		t.Fatalf("concurrent ensure results=%+v errors=%v", results, errs)
 	}
 	t.Cleanup(func() { pool.Exec(context.Background(), `DELETE FROM budget WHERE id=$1`, results[0].Budget.ID) })
+	compared, err := budgetService.Compare(ctx, appbudgets.ComparisonInput{A: ensured.Budget.ID, B: results[0].Budget.ID})
+	if err != nil || compared.Lineage == nil || compared.Lineage.AncestorID != ensured.Budget.ID || compared.Lineage.Generations != 1 || len(compared.Lines) != 3 || compared.Totals.ActualDelta != "-30.75" {
+		t.Fatalf("budget comparison=%+v error=%v", compared, err)
+	}
+
+	idempotencyService := appidempotency.NewService(postgresidempotency.NewRepository(pool), time.Hour)
+	key := "integration-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	t.Cleanup(func() { pool.Exec(context.Background(), `DELETE FROM idempotency_key WHERE key=$1`, key) })
+	if replay, err := idempotencyService.Begin(ctx, key, "first"); replay != nil || err != nil {
+		t.Fatalf("claim idempotency key replay=%+v error=%v", replay, err)
+	}
+	if _, err := idempotencyService.Begin(ctx, key, "first"); !apperrors.IsKind(err, apperrors.KindConflict) {
+		t.Fatalf("in-flight idempotency key error=%v", err)
+	}
+	stored := appidempotency.Response{StatusCode: 201, Header: map[string]string{"Location": "/v1/test/1"}, Body: []byte(`{"id":1}`)}
+	if err := idempotencyService.Complete(ctx, key, stored); err != nil {
+		t.Fatalf("complete idempotency key: %v", err)
+	}
+	if replay, err := idempotencyService.Begin(ctx, key, "first"); err != nil || replay == nil || replay.StatusCode != 201 || string(replay.Body) != `{"id":1}` || replay.Header["Location"] != "/v1/test/1" {
+		t.Fatalf("replay=%+v error=%v", replay, err)
+	}
+	if _, err := idempotencyService.Begin(ctx, key, "second"); !apperrors.IsKind(err, apperrors.KindUnprocessable) {
+		t.Fatalf("mismatched idempotency key error=%v", err)
+	}
+
+	trip, reimbursable := "trip-"+suffix, "reimbursable-"+suffix
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), `DELETE FROM tag WHERE name = ANY($1)`, []string{trip, reimbursable})
+	})
+	tagDate := now.AddDate(0, 3, 0)
+	tagged, err := transactionService.Create(ctx, apptransactions.CreateInput{Amount: 33.25, TransactionDate: tagDate, HouseholdID: &householdID, Tags: []string{strings.ToUpper(trip), reimbursable}})
+	if err != nil || !slices.Equal(tagged.Tags, []string{reimbursable, trip}) {
+		t.Fatalf("tagged=%+v error=%v", tagged, err)
+	}
+	tripOnly, err := transactionService.Create(ctx, apptransactions.CreateInput{Amount: 10, TransactionDate: tagDate.Add(time.Second), HouseholdID: &householdID, Tags: []string{trip}})
+	if err != nil {
+		t.Fatalf("create trip transaction: %v", err)
+	}
+	for match, want := range map[string][]int64{apptransactions.TagMatchAny: {tripOnly.ID, tagged.ID}, apptransactions.TagMatchAll: {tagged.ID}} {
+		page, err := transactionService.List(ctx, apptransactions.ListFilter{HouseholdID: &householdID, Tags: []string{trip, reimbursable}, TagMatch: match})
+		if err != nil || len(page.Items) != len(want) {
+			t.Fatalf("%s tag page=%+v error=%v", match, page, err)
+		}
+		for index, item := range page.Items {
+			if item.ID != want[index] {
+				t.Fatalf("%s tag page=%+v", match, page.Items)
+			}
+		}
+	}
+	totals, err := transactionService.TagTotals(ctx, apptransactions.TagTotalsFilter{HouseholdID: &householdID})
+	if err != nil || len(totals) != 2 || totals[0] != (apptransactions.TagTotal{Tag: reimbursable, Count: 1, Total: "33.25"}) || totals[1] != (apptransactions.TagTotal{Tag: trip, Count: 2, Total: "43.25"}) {
+		t.Fatalf("tag totals=%+v error=%v", totals, err)
+	}
+	if cleared, err := transactionService.Update(ctx, apptransactions.UpdateInput{ID: tagged.ID, Tags: &[]string{}}); err != nil || len(cleared.Tags) != 0 {
+		t.Fatalf("cleared tags=%+v error=%v", cleared, err)
+	}
+	taggedHistory, err := transactionService.History(ctx, tagged.ID)
+	if err != nil || len(taggedHistory) != 2 || len(taggedHistory[1].Changes) != 1 || taggedHistory[1].Changes[0].Field != "tags" || taggedHistory[1].Changes[0].After != nil {
+		t.Fatalf("tag history=%+v error=%v", taggedHistory, err)
+	}
+
+	window := fmt.Sprintf("household = %d and date >= %q and date <= %q", householdID, tagDate.Format(time.RFC3339), tagDate.Add(time.Second).Format(time.RFC3339))
+	for query, want := range map[string][]int64{
+		window + " and tag:" + trip + " and amount < 20":         {tripOnly.ID},
+		window + " and not tag = " + trip:                        {tagged.ID},
+		window + " and (amount in (33.25, 99) or notes ~ \"x\")": {tagged.ID},
+		window + " and category != groceries and amount >= 10":   {tripOnly.ID, tagged.ID},
+		window + ` and description ~ "'; DROP TABLE tag; --"`:    {},
+	} {
+		page, err := transactionService.List(ctx, apptransactions.ListFilter{Query: query})
+		if err != nil || len(page.Items) != len(want) {
+			t.Fatalf("query %q page=%+v error=%v", query, page, err)
+		}
+		for index, item := range page.Items {
+			if item.ID != want[index] {
+				t.Fatalf("query %q page=%+v", query, page.Items)
+			}
+		}
+	}
+	filterName := "trips-" + suffix
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), `DELETE FROM saved_filter WHERE name = $1`, filterName)
+	})
+	if _, err := transactionService.SaveFilter(ctx, filterName, "tag:"+reimbursable); err != nil {
+		t.Fatalf("save filter: %v", err)
+	}
+	if saved, err := transactionService.SaveFilter(ctx, filterName, window); err != nil || saved.Query != window || saved.UpdatedAt.Before(saved.CreatedAt) {
+		t.Fatalf("replaced filter=%+v error=%v", saved, err)
+	}
+	filtered, err := transactionService.TagTotals(ctx, apptransactions.TagTotalsFilter{Filter: filterName, Query: "amount < 20"})
+	if err != nil || len(filtered) != 1 || filtered[0] != (apptransactions.TagTotal{Tag: trip, Count: 1, Total: "10.00"}) {
+		t.Fatalf("filtered tag totals=%+v error=%v", filtered, err)
+	}
+	if err := transactionService.DeleteSavedFilter(ctx, filterName); err != nil {
+		t.Fatalf("delete saved filter: %v", err)
+	}
+	if _, err := transactionService.List(ctx, apptransactions.ListFilter{Filter: filterName}); apperrors.CodeOf(err) != apperrors.CodeSavedFilterNotFound {
+		t.Fatalf("deleted saved filter error=%v", err)
+	}
+
+	searchDate := now.AddDate(0, 4, 0)
+	once, err := transactionService.Create(ctx, apptransactions.CreateInput{Amount: 4.5, TransactionDate: searchDate, HouseholdID: &householdID, Description: stringPointer("Starbucks downtown")})
+	if err != nil {
+		t.Fatalf("create search transaction: %v", err)
+	}
+	twice, err := transactionService.Create(ctx, apptransactions.CreateInput{Amount: 6, TransactionDate: searchDate, HouseholdID: &householdID, Description: stringPointer("Coffee"), Notes: stringPointer("starbucks reserve, starbucks rewards")})
+	if err != nil {
+		t.Fatalf("create search transaction: %v", err)
+	}
+	fuzzy, err := transactionService.List(ctx, apptransactions.ListFilter{HouseholdID: &householdID, Search: stringPointer("starbuks")})
+	if err != nil || len(fuzzy.Items) != 2 || fuzzy.Items[1].ID != once.ID || fuzzy.Items[1].Match == nil || fuzzy.Items[1].Match.Snippet != "**Starbucks** downtown" || fuzzy.Items[0].Match.Snippet != "**starbucks** reserve, **starbucks** rewards" {
+		t.Fatalf("fuzzy search page=%+v error=%v", fuzzy, err)
+	}
+	var ranked []int64
+	cursor := ""
+	for {
+		page, err := transactionService.List(ctx, apptransactions.ListFilter{HouseholdID: &householdID, Search: stringPointer("starbucks"), Sort: apptransactions.SortRelevance, SortOrder: "desc", Limit: 1, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("relevance page: %v", err)
+		}
+		for _, item := range page.Items {
+			ranked = append(ranked, item.ID)
+		}
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if !slices.Equal(ranked, []int64{twice.ID, once.ID}) {
+		t.Fatalf("relevance order=%v want %v", ranked, []int64{twice.ID, once.ID})
+	}
+
+	byMerchant, err := transactionService.Aggregate(ctx, apptransactions.AggregateFilter{HouseholdID: &householdID, FromDate: &searchDate, ToDate: &searchDate, By: []apptransactions.AggregateDimension{apptransactions.DimensionMerchant}, Metrics: []apptransactions.AggregateMetric{apptransactions.MetricSum, apptransactions.MetricMax}})
+	if err != nil || len(byMerchant.Groups) != 2 || *byMerchant.Groups[0].Merchant != "Coffee" || *byMerchant.Groups[0].Sum != "6.00" || *byMerchant.Groups[1].Merchant != "Starbucks downtown" || *byMerchant.Groups[1].Max != "4.50" || byMerchant.Groups[1].Count != nil {
+		t.Fatalf("merchant aggregate=%+v error=%v", byMerchant, err)
+	}
+	byMonth, err := transactionService.Aggregate(ctx, apptransactions.AggregateFilter{HouseholdID: &householdID, Search: stringPointer("starbucks"), By: []apptransactions.AggregateDimension{apptransactions.DimensionMonth, apptransactions.DimensionCategory}})
+	month := time.Date(searchDate.UTC().Year(), searchDate.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	if err != nil || len(byMonth.Groups) != 1 || !byMonth.Groups[0].Period.Equal(month) || byMonth.Groups[0].Category != nil || *byMonth.Groups[0].Count != 2 || *byMonth.Groups[0].Sum != "10.50" {
+		t.Fatalf("month aggregate=%+v error=%v", byMonth, err)
+	}
 }
 
 func stringPointer(value string) *string { return &value }
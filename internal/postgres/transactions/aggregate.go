@@ -0,0 +1,68 @@
+package transactions
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	apptransactions "rdmm404/voltr-finance/internal/app/transactions"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+)
+
+// periodUnits maps the period dimensions to date_trunc units.
+var periodUnits = map[apptransactions.AggregateDimension]string{
+	apptransactions.DimensionDay:   "day",
+	apptransactions.DimensionWeek:  "week",
+	apptransactions.DimensionMonth: "month",
+}
+
+func (r *Repository) Aggregate(ctx context.Context, filter apptransactions.AggregateFilter) ([]apptransactions.AggregateGroup, error) {
+	params := sqlc.AggregateTransactionsParams{Dimensions: make([]string, 0, len(filter.By)), OnlyDeleted: filter.OnlyDeleted, IncludeDeleted: filter.IncludeDeleted, AuthorID: filter.AuthorID, HouseholdID: filter.HouseholdID, FromDate: optionalTimestamptz(filter.FromDate), ToDate: optionalTimestamptz(filter.ToDate), Search: filter.Search, Tags: filter.Tags, TagMatch: filter.TagMatch}
+	for _, dimension := range filter.By {
+		if unit, ok := periodUnits[dimension]; ok {
+			params.PeriodUnit = &unit
+			continue
+		}
+		params.Dimensions = append(params.Dimensions, string(dimension))
+	}
+	if params.Tags == nil {
+		// A NULL array would filter out every row.
+		params.Tags = []string{}
+	}
+	rows, err := r.filtered(filter.Where).AggregateTransactions(ctx, params)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	groups := make([]apptransactions.AggregateGroup, 0, len(rows))
+	for _, row := range rows {
+		amounts, err := numericStrings(row.TotalAmount, row.AverageAmount, row.MinAmount, row.MaxAmount)
+		if err != nil {
+			return nil, err
+		}
+		group := apptransactions.AggregateGroup{AuthorID: row.AuthorID, AuthorName: row.AuthorName, HouseholdID: row.HouseholdID, HouseholdName: row.HouseholdName, Tag: row.Tag, Count: &row.TransactionCount, Sum: &amounts[0], Avg: &amounts[1], Min: &amounts[2], Max: &amounts[3]}
+		if row.Period.Valid {
+			group.Period = &row.Period.Time
+		}
+		if row.CategoryID != nil {
+			group.Category = &apptransactions.CategoryRef{ID: *row.CategoryID, Code: valueOrZero(row.CategoryCode), Name: valueOrZero(row.CategoryName)}
+		}
+		// sqlc cannot type the merchant expression, which pgx scans as text.
+		if merchant, ok := row.Merchant.(string); ok {
+			group.Merchant = &merchant
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func numericStrings(values ...pgtype.Numeric) ([]string, error) {
+	result := make([]string, len(values))
+	for i, value := range values {
+		amount, err := numericString(value)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = amount
+	}
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package transactions
+
+import (
+	"context"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	apptransactions "rdmm404/voltr-finance/internal/app/transactions"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+	"rdmm404/voltr-finance/internal/postgres"
+)
+
+func (r *Repository) SaveFilter(ctx context.Context, name, query string) (apptransactions.SavedFilter, error) {
+	row, err := sqlc.New(r.pool).UpsertSavedFilter(ctx, sqlc.UpsertSavedFilterParams{Name: name, Query: query})
+	if err != nil {
+		return apptransactions.SavedFilter{}, mapSavedFilterError(err)
+	}
+	return mapSavedFilter(row), nil
+}
+
+func (r *Repository) GetSavedFilter(ctx context.Context, name string) (apptransactions.SavedFilter, error) {
+	row, err := sqlc.New(r.pool).GetSavedFilter(ctx, name)
+	if err != nil {
+		return apptransactions.SavedFilter{}, mapSavedFilterError(err)
+	}
+	return mapSavedFilter(row), nil
+}
+
+func (r *Repository) ListSavedFilters(ctx context.Context) ([]apptransactions.SavedFilter, error) {
+	rows, err := sqlc.New(r.pool).ListSavedFilters(ctx)
+	if err != nil {
+		return nil, mapSavedFilterError(err)
+	}
+	filters := make([]apptransactions.SavedFilter, 0, len(rows))
+	for _, row := range rows {
+		filters = append(filters, mapSavedFilter(row))
+	}
+	return filters, nil
+}
+
+func (r *Repository) DeleteSavedFilter(ctx context.Context, name string) error {
+	deleted, err := sqlc.New(r.pool).DeleteSavedFilter(ctx, name)
+	if err != nil {
+		return mapSavedFilterError(err)
+	}
+	if deleted == 0 {
+		return apperrors.NotFound(apperrors.CodeSavedFilterNotFound, "saved filter not found", nil)
+	}
+	return nil
+}
+
+func mapSavedFilter(row sqlc.SavedFilter) apptransactions.SavedFilter {
+	return apptransactions.SavedFilter{Name: row.Name, Query: row.Query, CreatedAt: row.CreatedAt.Time, UpdatedAt: row.UpdatedAt.Time}
+}
+
+func mapSavedFilterError(err error) error {
+	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeSavedFilterNotFound, NotFoundMessage: "saved filter not found"})
+}
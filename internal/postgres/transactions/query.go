@@ -0,0 +1,186 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	apptransactions "rdmm404/voltr-finance/internal/app/transactions"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+)
+
+// queryColumns maps filter expression fields to the columns they compare,
+// relying only on the transaction alias t so any query can embed them. Tag
+// conditions look at transaction_tag instead.
+var queryColumns = map[apptransactions.QueryField]string{
+	apptransactions.FieldAmount:      "t.amount",
+	apptransactions.FieldDate:        "t.transaction_date",
+	apptransactions.FieldCategory:    "(SELECT fc.code FROM category fc WHERE fc.id = t.category_id)",
+	apptransactions.FieldDescription: "t.description",
+	apptransactions.FieldNotes:       "t.notes",
+	apptransactions.FieldAuthor:      "t.author_id",
+	apptransactions.FieldHousehold:   "t.household_id",
+}
+
+var queryOperators = map[apptransactions.QueryOp]string{
+	apptransactions.QueryEqual:        "=",
+	apptransactions.QueryLess:         "<",
+	apptransactions.QueryLessEqual:    "<=",
+	apptransactions.QueryGreater:      ">",
+	apptransactions.QueryGreaterEqual: ">=",
+}
+
+// filterMarker stands in the listing and report queries for the filter
+// expression. Keeping the expression in the same statement lets the other
+// filters, the ordering and the limit apply to it in one snapshot.
+const filterMarker = "/* filter expression */ TRUE"
+
+// filtered returns the queries with where compiled into filterMarker, or the
+// plain queries without an expression.
+func (r *Repository) filtered(where apptransactions.Expr) queries {
+	if where == nil {
+		return r.queries
+	}
+	return sqlc.New(expressionDB{db: r.pool, where: where})
+}
+
+// expressionDB compiles a filter expression into each statement in place of
+// filterMarker. Its placeholders are numbered after the statement's own.
+type expressionDB struct {
+	db    sqlc.DBTX
+	where apptransactions.Expr
+}
+
+func (e expressionDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	sql, args, err := e.compile(sql, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return e.db.Exec(ctx, sql, args...)
+}
+
+func (e expressionDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	sql, args, err := e.compile(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return e.db.Query(ctx, sql, args...)
+}
+
+func (e expressionDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	sql, args, err := e.compile(sql, args)
+	if err != nil {
+		return errorRow{err: err}
+	}
+	return e.db.QueryRow(ctx, sql, args...)
+}
+
+func (e expressionDB) compile(sql string, args []any) (string, []any, error) {
+	if strings.Count(sql, filterMarker) != 1 {
+		return "", nil, fmt.Errorf("statement has no place for a filter expression")
+	}
+	compiler := queryCompiler{args: slices.Clone(args)}
+	predicate, err := compiler.compile(e.where)
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.Replace(sql, filterMarker, "("+predicate+")", 1), compiler.args, nil
+}
+
+type errorRow struct{ err error }
+
+func (r errorRow) Scan(...any) error { return r.err }
+
+// queryCompiler turns an expression into a SQL predicate. Columns and
+// operators come from fixed tables and every value becomes a placeholder, so
+// nothing the caller wrote reaches the SQL text. Placeholders continue after
+// the arguments the compiler starts with.
+type queryCompiler struct {
+	args []any
+}
+
+func (c *queryCompiler) compile(expr apptransactions.Expr) (string, error) {
+	switch expr := expr.(type) {
+	case apptransactions.AndExpr:
+		return c.binary(expr.Left, "AND", expr.Right)
+	case apptransactions.OrExpr:
+		return c.binary(expr.Left, "OR", expr.Right)
+	case apptransactions.NotExpr:
+		operand, err := c.compile(expr.Operand)
+		return "NOT " + operand, err
+	case apptransactions.Condition:
+		return c.condition(expr)
+	}
+	return "", fmt.Errorf("unsupported filter expression %T", expr)
+}
+
+func (c *queryCompiler) binary(left apptransactions.Expr, operator string, right apptransactions.Expr) (string, error) {
+	leftSQL, err := c.compile(left)
+	if err != nil {
+		return "", err
+	}
+	rightSQL, err := c.compile(right)
+	if err != nil {
+		return "", err
+	}
+	return "(" + leftSQL + " " + operator + " " + rightSQL + ")", nil
+}
+
+// condition compiles one comparison. Comparisons never yield NULL: a
+// transaction without a category or notes equals no value, so = and in leave
+// it out while != and not keep it.
+func (c *queryCompiler) condition(condition apptransactions.Condition) (string, error) {
+	if len(condition.Values) == 0 {
+		return "", fmt.Errorf("filter condition on %s has no values", condition.Field)
+	}
+	if condition.Field == apptransactions.FieldTag {
+		return c.tagCondition(condition)
+	}
+	column, ok := queryColumns[condition.Field]
+	if !ok {
+		return "", fmt.Errorf("unsupported filter field %q", condition.Field)
+	}
+	switch condition.Op {
+	case apptransactions.QueryIn:
+		return "COALESCE(" + column + " IN (" + c.list(condition.Values) + "), FALSE)", nil
+	case apptransactions.QueryNotEqual:
+		return "COALESCE(" + column + " <> " + c.arg(condition.Values[0]) + ", TRUE)", nil
+	case apptransactions.QueryContains:
+		return "COALESCE(strpos(lower(" + column + "), lower(" + c.arg(condition.Values[0]) + ")) > 0, FALSE)", nil
+	}
+	operator, ok := queryOperators[condition.Op]
+	if !ok {
+		return "", fmt.Errorf("unsupported filter operator %q", condition.Op)
+	}
+	return "COALESCE(" + column + " " + operator + " " + c.arg(condition.Values[0]) + ", FALSE)", nil
+}
+
+func (c *queryCompiler) tagCondition(condition apptransactions.Condition) (string, error) {
+	exists := "EXISTS"
+	switch condition.Op {
+	case apptransactions.QueryEqual, apptransactions.QueryIn:
+	case apptransactions.QueryNotEqual:
+		exists = "NOT EXISTS"
+	default:
+		return "", fmt.Errorf("unsupported tag operator %q", condition.Op)
+	}
+	return exists + " (SELECT 1 FROM transaction_tag tt JOIN tag tg ON tg.id = tt.tag_id WHERE tt.transaction_id = t.id AND tg.name IN (" + c.list(condition.Values) + "))", nil
+}
+
+func (c *queryCompiler) list(values []any) string {
+	placeholders := make([]string, 0, len(values))
+	for _, value := range values {
+		placeholders = append(placeholders, c.arg(value))
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+func (c *queryCompiler) arg(value any) string {
+	c.args = append(c.args, value)
+	return "$" + strconv.Itoa(len(c.args))
+}
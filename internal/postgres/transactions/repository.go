@@ -2,22 +2,33 @@ package transactions
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/app/events"
 	apptransactions "rdmm404/voltr-finance/internal/app/transactions"
 	"rdmm404/voltr-finance/internal/database/sqlc"
 	"rdmm404/voltr-finance/internal/postgres"
 )
 
 type queries interface {
+	AggregateTransactions(context.Context, sqlc.AggregateTransactionsParams) ([]sqlc.AggregateTransactionsRow, error)
 	CreateTransaction(context.Context, sqlc.CreateTransactionParams) (sqlc.Transaction, error)
+	GetClosedBudgetForTransaction(context.Context, sqlc.GetClosedBudgetForTransactionParams) (sqlc.GetClosedBudgetForTransactionRow, error)
 	GetTransactionByIdForUpdate(context.Context, int64) (sqlc.Transaction, error)
 	GetTransactionsByIdWithDetails(context.Context, sqlc.GetTransactionsByIdWithDetailsParams) ([]sqlc.GetTransactionsByIdWithDetailsRow, error)
 	ListTransactions(context.Context, sqlc.ListTransactionsParams) ([]sqlc.ListTransactionsRow, error)
+	ListTransactionChanges(context.Context, sqlc.ListTransactionChangesParams) ([]sqlc.ListTransactionChangesRow, error)
+	ListTransactionHistory(context.Context, int64) ([]sqlc.ListTransactionHistoryRow, error)
+	ListTransactionTagTotals(context.Context, sqlc.ListTransactionTagTotalsParams) ([]sqlc.ListTransactionTagTotalsRow, error)
+	LockBudgetsForTransaction(context.Context, sqlc.LockBudgetsForTransactionParams) error
 	UpdateTransactionById(context.Context, sqlc.UpdateTransactionByIdParams) (sqlc.Transaction, error)
 	SoftDeleteTransactionsById(context.Context, sqlc.SoftDeleteTransactionsByIdParams) ([]sqlc.Transaction, error)
 	RestoreTransactionsById(context.Context, []int64) ([]sqlc.Transaction, error)
@@ -33,11 +44,26 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 }
 
 func (r *Repository) Create(ctx context.Context, input apptransactions.NewTransaction) (apptransactions.Transaction, error) {
-	row, err := r.queries.CreateTransaction(ctx, sqlc.CreateTransactionParams{Amount: input.Amount, CategoryID: input.CategoryID, Description: input.Description, TransactionDate: timestamptz(input.TransactionDate), TransactionID: input.Hash, AuthorID: input.AuthorID, HouseholdID: input.HouseholdID, Notes: input.Notes})
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return apptransactions.Transaction{}, mapError(err)
 	}
-	return r.getDetails(ctx, row.ID, true)
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+
+	if err := ensureOpenPeriod(ctx, q, input.TransactionDate, input.HouseholdID, input.AuthorID); err != nil {
+		return apptransactions.Transaction{}, err
+	}
+	row, err := q.CreateTransaction(ctx, sqlc.CreateTransactionParams{Amount: input.Amount, CategoryID: input.CategoryID, Description: input.Description, TransactionDate: timestamptz(input.TransactionDate), TransactionID: input.Hash, AuthorID: input.AuthorID, HouseholdID: input.HouseholdID, Notes: input.Notes})
+	if err != nil {
+		return apptransactions.Transaction{}, mapError(err)
+	}
+	if len(input.Tags) > 0 {
+		if err := setTags(ctx, q, row.ID, input.Tags); err != nil {
+			return apptransactions.Transaction{}, err
+		}
+	}
+	return commitChange(ctx, tx, q, apptransactions.Transaction{}, row.ID, apptransactions.ActionCreated, &input.AuthorID)
 }
 
 func (r *Repository) Get(ctx context.Context, id int64, includeDeleted bool) (apptransactions.Transaction, error) {
@@ -45,17 +71,80 @@ func (r *Repository) Get(ctx context.Context, id int64, includeDeleted bool) (ap
 }
 
 func (r *Repository) List(ctx context.Context, filter apptransactions.ListFilter) ([]apptransactions.Transaction, error) {
-	rows, err := r.queries.ListTransactions(ctx, sqlc.ListTransactionsParams{OnlyDeleted: filter.OnlyDeleted, IncludeDeleted: filter.IncludeDeleted, AuthorID: filter.AuthorID, HouseholdID: filter.HouseholdID, FromDate: optionalTimestamptz(filter.FromDate), ToDate: optionalTimestamptz(filter.ToDate), Search: filter.Search, Sort: filter.Sort, SortOrder: filter.SortOrder, ResultOffset: filter.Offset, ResultLimit: filter.Limit})
+	params := sqlc.ListTransactionsParams{OnlyDeleted: filter.OnlyDeleted, IncludeDeleted: filter.IncludeDeleted, AuthorID: filter.AuthorID, HouseholdID: filter.HouseholdID, FromDate: optionalTimestamptz(filter.FromDate), ToDate: optionalTimestamptz(filter.ToDate), Search: filter.Search, Tags: filter.Tags, TagMatch: filter.TagMatch, Sort: filter.Sort, SortOrder: filter.SortOrder, ResultOffset: filter.Offset, ResultLimit: filter.Limit}
+	if params.Tags == nil {
+		// A NULL array would filter out every row.
+		params.Tags = []string{}
+	}
+	if after := filter.After; after != nil {
+		params.AfterID, params.AfterTime, params.AfterAmount, params.AfterRelevance = &after.ID, timestamptz(after.Time), &after.Amount, &after.Relevance
+	}
+	rows, err := r.filtered(filter.Where).ListTransactions(ctx, params)
 	if err != nil {
 		return nil, mapError(err)
 	}
 	items := make([]apptransactions.Transaction, 0, len(rows))
 	for _, row := range rows {
-		items = append(items, mapDetailed(row.Transaction, row.AuthorName, row.HouseholdID, row.HouseholdName, row.CategoryID, row.CategoryCode, row.CategoryName))
+		item := mapDetailed(row.Transaction, row.AuthorName, row.HouseholdID, row.HouseholdName, row.CategoryID, row.CategoryCode, row.CategoryName, row.Tags)
+		if filter.Search != nil {
+			item.Match = &apptransactions.SearchMatch{Rank: row.Relevance}
+		}
+		items = append(items, item)
 	}
 	return items, nil
 }
 
+func (r *Repository) TagTotals(ctx context.Context, filter apptransactions.TagTotalsFilter) ([]apptransactions.TagTotal, error) {
+	rows, err := r.filtered(filter.Where).ListTransactionTagTotals(ctx, sqlc.ListTransactionTagTotalsParams{AuthorID: filter.AuthorID, HouseholdID: filter.HouseholdID, FromDate: optionalTimestamptz(filter.FromDate), ToDate: optionalTimestamptz(filter.ToDate)})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	totals := make([]apptransactions.TagTotal, 0, len(rows))
+	for _, row := range rows {
+		total, err := numericString(row.TotalAmount)
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, apptransactions.TagTotal{Tag: row.Tag, Count: row.TransactionCount, Total: total})
+	}
+	return totals, nil
+}
+
+func (r *Repository) ListChanges(ctx context.Context, since int64, limit int32) ([]apptransactions.Transaction, error) {
+	rows, err := r.queries.ListTransactionChanges(ctx, sqlc.ListTransactionChangesParams{Since: since, ResultLimit: limit})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	items := make([]apptransactions.Transaction, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, mapDetailed(row.Transaction, row.AuthorName, row.HouseholdID, row.HouseholdName, row.CategoryID, row.CategoryCode, row.CategoryName, row.Tags))
+	}
+	return items, nil
+}
+
+func (r *Repository) History(ctx context.Context, id int64) ([]apptransactions.HistoryEntry, error) {
+	rows, err := r.queries.ListTransactionHistory(ctx, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	entries := make([]apptransactions.HistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		var stored []fieldChange
+		if err := json.Unmarshal(row.TransactionHistory.Changes, &stored); err != nil {
+			return nil, fmt.Errorf("decode transaction history %d: %w", row.TransactionHistory.ID, err)
+		}
+		changes := make([]apptransactions.FieldChange, 0, len(stored))
+		for _, change := range stored {
+			changes = append(changes, apptransactions.FieldChange(change))
+		}
+		entries = append(entries, apptransactions.HistoryEntry{
+			ID: row.TransactionHistory.ID, TransactionID: row.TransactionHistory.TransactionID, Action: apptransactions.Action(row.TransactionHistory.Action),
+			ActorUserID: row.TransactionHistory.ActorUserID, ActorName: row.ActorName, ChangedAt: row.TransactionHistory.ChangedAt.Time, Changes: changes,
+		})
+	}
+	return entries, nil
+}
+
 func (r *Repository) Update(ctx context.Context, id int64, input apptransactions.Mutation) (apptransactions.Transaction, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
@@ -64,12 +153,19 @@ func (r *Repository) Update(ctx context.Context, id int64, input apptransactions
 	defer tx.Rollback(ctx)
 	q := sqlc.New(tx)
 
-	row, err := q.GetTransactionByIdForUpdate(ctx, id)
+	existing, err := lockTransaction(ctx, q, id)
 	if err != nil {
-		return apptransactions.Transaction{}, mapError(err)
+		return apptransactions.Transaction{}, err
+	}
+	if input.ExpectedVersion != nil && *input.ExpectedVersion != existing.Version {
+		return apptransactions.Transaction{}, apperrors.PreconditionFailed(apperrors.CodeVersionMismatch, "transaction version mismatch", nil)
 	}
-	existing := mapTransaction(row)
 	merged := input.Apply(existing)
+	for _, item := range []apptransactions.Transaction{existing, merged} {
+		if err := ensureOpenPeriod(ctx, q, item.TransactionDate, item.HouseholdID, item.AuthorID); err != nil {
+			return apptransactions.Transaction{}, err
+		}
+	}
 	hash, err := apptransactions.Hash(merged.Description, merged.TransactionDate, merged.AuthorID, merged.HouseholdID, merged.CategoryID, merged.Amount)
 	if err != nil {
 		return apptransactions.Transaction{}, err
@@ -87,36 +183,152 @@ func (r *Repository) Update(ctx context.Context, id int64, input apptransactions
 	if err != nil {
 		return apptransactions.Transaction{}, mapError(err)
 	}
-	item, err := getDetails(ctx, q, id, true)
+	if input.Tags != nil {
+		if err := q.DeleteTransactionTags(ctx, id); err != nil {
+			return apptransactions.Transaction{}, mapError(err)
+		}
+		if err := setTags(ctx, q, id, *input.Tags); err != nil {
+			return apptransactions.Transaction{}, err
+		}
+	}
+	return commitChange(ctx, tx, q, existing, id, apptransactions.ActionUpdated, input.ActorID)
+}
+
+func (r *Repository) SoftDelete(ctx context.Context, input apptransactions.DeleteInput) (apptransactions.Transaction, error) {
+	return r.changeState(ctx, input.ID, input.ExpectedVersion, apptransactions.ActionDeleted, input.DeletedByUserID, func(q *sqlc.Queries) ([]sqlc.Transaction, error) {
+		return q.SoftDeleteTransactionsById(ctx, sqlc.SoftDeleteTransactionsByIdParams{DeletedByUserID: input.DeletedByUserID, DeleteReason: input.Reason, Ids: []int64{input.ID}})
+	})
+}
+
+func (r *Repository) Restore(ctx context.Context, input apptransactions.RestoreInput) (apptransactions.Transaction, error) {
+	return r.changeState(ctx, input.ID, nil, apptransactions.ActionRestored, input.RestoredByUserID, func(q *sqlc.Queries) ([]sqlc.Transaction, error) {
+		return q.RestoreTransactionsById(ctx, []int64{input.ID})
+	})
+}
+
+// changeState soft-deletes or restores one transaction after checking its
+// expected version, when given, and that its date is not inside a closed
+// budget.
+func (r *Repository) changeState(ctx context.Context, id int64, expectedVersion *int64, action apptransactions.Action, actorID int64, change func(*sqlc.Queries) ([]sqlc.Transaction, error)) (apptransactions.Transaction, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return apptransactions.Transaction{}, mapError(err)
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+
+	existing, err := lockTransaction(ctx, q, id)
 	if err != nil {
 		return apptransactions.Transaction{}, err
 	}
-	if err := tx.Commit(ctx); err != nil {
+	if expectedVersion != nil && *expectedVersion != existing.Version {
+		return apptransactions.Transaction{}, apperrors.PreconditionFailed(apperrors.CodeVersionMismatch, "transaction version mismatch", nil)
+	}
+	if err := ensureOpenPeriod(ctx, q, existing.TransactionDate, existing.HouseholdID, existing.AuthorID); err != nil {
+		return apptransactions.Transaction{}, err
+	}
+	rows, err := change(q)
+	if err != nil {
 		return apptransactions.Transaction{}, mapError(err)
 	}
-	return item, nil
+	if len(rows) == 0 {
+		return apptransactions.Transaction{}, notFound(nil)
+	}
+	return commitChange(ctx, tx, q, existing, id, action, &actorID)
 }
 
-func (r *Repository) SoftDelete(ctx context.Context, input apptransactions.DeleteInput) (apptransactions.Transaction, error) {
-	rows, err := r.queries.SoftDeleteTransactionsById(ctx, sqlc.SoftDeleteTransactionsByIdParams{DeletedByUserID: input.DeletedByUserID, DeleteReason: input.Reason, Ids: []int64{input.ID}})
+// lockTransaction locks the transaction's row for the rest of the database
+// transaction and returns it with its tags.
+func lockTransaction(ctx context.Context, q *sqlc.Queries, id int64) (apptransactions.Transaction, error) {
+	row, err := q.GetTransactionByIdForUpdate(ctx, id)
 	if err != nil {
 		return apptransactions.Transaction{}, mapError(err)
 	}
-	if len(rows) == 0 {
-		return apptransactions.Transaction{}, notFound(nil)
+	item := mapTransaction(row)
+	if item.Tags, err = q.ListTransactionTags(ctx, id); err != nil {
+		return apptransactions.Transaction{}, mapError(err)
 	}
-	return r.getDetails(ctx, input.ID, true)
+	return item, nil
 }
 
-func (r *Repository) Restore(ctx context.Context, input apptransactions.RestoreInput) (apptransactions.Transaction, error) {
-	rows, err := r.queries.RestoreTransactionsById(ctx, []int64{input.ID})
+// setTags creates any tags that do not exist yet and links the transaction to
+// them.
+func setTags(ctx context.Context, q *sqlc.Queries, id int64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	if err := q.CreateTags(ctx, tags); err != nil {
+		return mapError(err)
+	}
+	if err := q.CreateTransactionTags(ctx, sqlc.CreateTransactionTagsParams{TransactionID: id, Names: tags}); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+// actionEvents names the webhook event written for each history action.
+var actionEvents = map[apptransactions.Action]events.Type{
+	apptransactions.ActionCreated:  events.TransactionCreated,
+	apptransactions.ActionUpdated:  events.TransactionUpdated,
+	apptransactions.ActionDeleted:  events.TransactionDeleted,
+	apptransactions.ActionRestored: events.TransactionRestored,
+}
+
+// fieldChange is how a FieldChange is stored in transaction_history.changes.
+type fieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// commitChange records the change in the transaction's history and the
+// webhook outbox and commits them with it, then returns the transaction as
+// committed. before is the transaction as it stood before the change.
+func commitChange(ctx context.Context, tx pgx.Tx, q *sqlc.Queries, before apptransactions.Transaction, id int64, action apptransactions.Action, actorID *int64) (apptransactions.Transaction, error) {
+	item, err := getDetails(ctx, q, id, true)
 	if err != nil {
+		return apptransactions.Transaction{}, err
+	}
+	diff := apptransactions.Diff(before, item)
+	stored := make([]fieldChange, 0, len(diff))
+	for _, change := range diff {
+		stored = append(stored, fieldChange(change))
+	}
+	changes, err := json.Marshal(stored)
+	if err != nil {
+		return apptransactions.Transaction{}, fmt.Errorf("encode transaction history: %w", err)
+	}
+	if err := q.CreateTransactionHistory(ctx, sqlc.CreateTransactionHistoryParams{TransactionID: id, Action: string(action), ActorUserID: actorID, Changes: changes}); err != nil {
 		return apptransactions.Transaction{}, mapError(err)
 	}
-	if len(rows) == 0 {
-		return apptransactions.Transaction{}, notFound(nil)
+	if err := q.CreateTransactionWebhookEvent(ctx, sqlc.CreateTransactionWebhookEventParams{EventType: string(actionEvents[action]), TransactionID: id}); err != nil {
+		return apptransactions.Transaction{}, mapError(err)
 	}
-	return r.getDetails(ctx, input.ID, false)
+	if err := tx.Commit(ctx); err != nil {
+		return apptransactions.Transaction{}, mapError(err)
+	}
+	return item, nil
+}
+
+// ensureOpenPeriod rejects a transaction change when the transaction's date
+// falls inside a closed budget of its owner, matching the budget report scope.
+// The covering budgets stay share-locked until the change commits, so a
+// budget close cannot snapshot the period while the change is in flight.
+func ensureOpenPeriod(ctx context.Context, q queries, date time.Time, householdID *int64, authorID int64) error {
+	if err := q.LockBudgetsForTransaction(ctx, sqlc.LockBudgetsForTransactionParams{TransactionDate: timestamptz(date), HouseholdID: householdID, AuthorID: authorID}); err != nil {
+		return mapError(err)
+	}
+	budget, err := q.GetClosedBudgetForTransaction(ctx, sqlc.GetClosedBudgetForTransactionParams{TransactionDate: timestamptz(date), HouseholdID: householdID, AuthorID: authorID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return mapError(err)
+	}
+	return apperrors.Conflict(apperrors.CodeBudgetClosed, fmt.Sprintf(
+		"transaction date falls in closed budget %d (%s to %s); reopen the budget before changing it",
+		budget.ID, budget.PeriodStart.Time.Format(time.DateOnly), budget.PeriodEnd.Time.Format(time.DateOnly),
+	), nil)
 }
 
 func (r *Repository) getDetails(ctx context.Context, id int64, includeDeleted bool) (apptransactions.Transaction, error) {
@@ -132,15 +344,15 @@ func getDetails(ctx context.Context, q queries, id int64, includeDeleted bool) (
 		return apptransactions.Transaction{}, notFound(nil)
 	}
 	row := rows[0]
-	return mapDetailed(row.Transaction, row.AuthorName, row.HouseholdID, row.HouseholdName, row.CategoryID, row.CategoryCode, row.CategoryName), nil
+	return mapDetailed(row.Transaction, row.AuthorName, row.HouseholdID, row.HouseholdName, row.CategoryID, row.CategoryCode, row.CategoryName, row.Tags), nil
 }
 
 func mapTransaction(row sqlc.Transaction) apptransactions.Transaction {
-	return apptransactions.Transaction{ID: row.ID, Hash: row.TransactionID, Amount: row.Amount, TransactionDate: row.TransactionDate.Time, AuthorID: row.AuthorID, HouseholdID: row.HouseholdID, CategoryID: row.CategoryID, Description: row.Description, Notes: row.Notes}
+	return apptransactions.Transaction{ID: row.ID, Hash: row.TransactionID, Amount: row.Amount, TransactionDate: row.TransactionDate.Time, AuthorID: row.AuthorID, HouseholdID: row.HouseholdID, CategoryID: row.CategoryID, Description: row.Description, Notes: row.Notes, DeletedAt: timestamp(row.DeletedAt), DeletedByUserID: row.DeletedByUserID, DeleteReason: row.DeleteReason, ChangeSequence: row.ChangeSeq, Version: row.Version}
 }
 
-func mapDetailed(row sqlc.Transaction, authorName string, householdID *int64, householdName *string, categoryID *int64, categoryCode, categoryName *string) apptransactions.Transaction {
-	item := apptransactions.Transaction{ID: row.ID, Hash: row.TransactionID, Amount: row.Amount, TransactionDate: row.TransactionDate.Time, AuthorID: row.AuthorID, AuthorName: authorName, HouseholdID: householdID, HouseholdName: householdName, CategoryID: categoryID, Description: row.Description, Notes: row.Notes, CreatedAt: timestamp(row.CreatedAt), UpdatedAt: timestamp(row.UpdatedAt), DeletedAt: timestamp(row.DeletedAt), DeletedByUserID: row.DeletedByUserID, DeleteReason: row.DeleteReason}
+func mapDetailed(row sqlc.Transaction, authorName string, householdID *int64, householdName *string, categoryID *int64, categoryCode, categoryName *string, tags []string) apptransactions.Transaction {
+	item := apptransactions.Transaction{ID: row.ID, Hash: row.TransactionID, Amount: row.Amount, TransactionDate: row.TransactionDate.Time, AuthorID: row.AuthorID, AuthorName: authorName, HouseholdID: householdID, HouseholdName: householdName, CategoryID: categoryID, Description: row.Description, Notes: row.Notes, CreatedAt: timestamp(row.CreatedAt), UpdatedAt: timestamp(row.UpdatedAt), DeletedAt: timestamp(row.DeletedAt), DeletedByUserID: row.DeletedByUserID, DeleteReason: row.DeleteReason, Tags: tags, ChangeSequence: row.ChangeSeq, Version: row.Version}
 	if categoryID != nil {
 		code, name := "", ""
 		if categoryCode != nil {
@@ -177,6 +389,20 @@ func timestamp(value pgtype.Timestamptz) *time.Time {
 	result := value.Time
 	return &result
 }
+func numericString(value pgtype.Numeric) (string, error) {
+	raw, err := value.Value()
+	if err != nil {
+		return "", fmt.Errorf("format numeric: %w", err)
+	}
+	if raw == nil {
+		return "0", nil
+	}
+	result, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected numeric value %T", raw)
+	}
+	return result, nil
+}
 func notFound(cause error) error {
 	return apperrors.NotFound(apperrors.CodeTransactionNotFound, "transaction not found", cause)
 }
@@ -0,0 +1,229 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	appwebhooks "rdmm404/voltr-finance/internal/app/webhooks"
+	"rdmm404/voltr-finance/internal/database/sqlc"
+	"rdmm404/voltr-finance/internal/postgres"
+)
+
+// Repository owns webhook subscriptions and delivers the webhook outbox. The
+// outbox itself is written by the repositories whose changes it describes.
+type Repository struct{ pool *pgxpool.Pool }
+
+func NewRepository(pool *pgxpool.Pool) *Repository { return &Repository{pool: pool} }
+
+func (r *Repository) Create(ctx context.Context, input appwebhooks.CreateInput) (appwebhooks.Webhook, error) {
+	row, err := sqlc.New(r.pool).CreateWebhookSubscription(ctx, sqlc.CreateWebhookSubscriptionParams{
+		Url: input.URL, EventTypes: input.EventTypes, HouseholdID: input.HouseholdID, Secret: input.Secret,
+	})
+	if err != nil {
+		return appwebhooks.Webhook{}, mapWebhookError(err)
+	}
+	return mapWebhook(row), nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int64) (appwebhooks.Webhook, error) {
+	row, err := sqlc.New(r.pool).GetWebhookSubscriptionById(ctx, id)
+	if err != nil {
+		return appwebhooks.Webhook{}, mapWebhookError(err)
+	}
+	return mapWebhook(row), nil
+}
+
+func (r *Repository) List(ctx context.Context, filter appwebhooks.ListFilter) ([]appwebhooks.Webhook, error) {
+	rows, err := sqlc.New(r.pool).ListWebhookSubscriptions(ctx, filter.HouseholdID)
+	if err != nil {
+		return nil, mapWebhookError(err)
+	}
+	items := make([]appwebhooks.Webhook, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, mapWebhook(row))
+	}
+	return items, nil
+}
+
+func (r *Repository) Update(ctx context.Context, input appwebhooks.UpdateInput) (appwebhooks.Webhook, error) {
+	params := sqlc.UpdateWebhookSubscriptionParams{ID: input.ID, EventTypes: []string{}}
+	if input.URL != nil {
+		params.SetUrl, params.Url = true, *input.URL
+	}
+	if input.EventTypes != nil {
+		params.SetEventTypes, params.EventTypes = true, *input.EventTypes
+	}
+	if input.HouseholdID.Present() {
+		params.SetHouseholdID, params.HouseholdID = true, input.HouseholdID.Value()
+	}
+	if input.Secret != nil {
+		params.SetSecret, params.Secret = true, *input.Secret
+	}
+	if input.Active != nil {
+		params.SetIsActive, params.IsActive = true, *input.Active
+	}
+	row, err := sqlc.New(r.pool).UpdateWebhookSubscription(ctx, params)
+	if err != nil {
+		return appwebhooks.Webhook{}, mapWebhookError(err)
+	}
+	return mapWebhook(row), nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	deleted, err := sqlc.New(r.pool).DeleteWebhookSubscription(ctx, id)
+	if err != nil {
+		return mapWebhookError(err)
+	}
+	if deleted == 0 {
+		return apperrors.NotFound(apperrors.CodeWebhookNotFound, "webhook not found", nil)
+	}
+	return nil
+}
+
+func (r *Repository) ListDeliveries(ctx context.Context, webhookID int64, limit int32) ([]appwebhooks.Delivery, error) {
+	return r.deliveries(ctx, sqlc.ListWebhookDeliveriesParams{SubscriptionID: &webhookID, RowLimit: limit})
+}
+
+func (r *Repository) GetDelivery(ctx context.Context, id int64) (appwebhooks.Delivery, error) {
+	items, err := r.deliveries(ctx, sqlc.ListWebhookDeliveriesParams{ID: &id, RowLimit: 1})
+	if err != nil {
+		return appwebhooks.Delivery{}, err
+	}
+	if len(items) == 0 {
+		return appwebhooks.Delivery{}, apperrors.NotFound(apperrors.CodeWebhookDeliveryNotFound, "webhook delivery not found", nil)
+	}
+	return items[0], nil
+}
+
+func (r *Repository) Redeliver(ctx context.Context, id int64) error {
+	_, err := sqlc.New(r.pool).RedeliverWebhookDelivery(ctx, id)
+	return mapDeliveryError(err)
+}
+
+func (r *Repository) Dispatch(ctx context.Context, limit int32) (int64, error) {
+	dispatched, err := sqlc.New(r.pool).DispatchWebhookEvents(ctx, limit)
+	return dispatched, mapDeliveryError(err)
+}
+
+func (r *Repository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int32) ([]appwebhooks.PendingDelivery, error) {
+	rows, err := sqlc.New(r.pool).ClaimDueWebhookDeliveries(ctx, sqlc.ClaimDueWebhookDeliveriesParams{
+		Now: timestamptz(now), LeaseUntil: timestamptz(leaseUntil), RowLimit: limit,
+	})
+	if err != nil {
+		return nil, mapDeliveryError(err)
+	}
+	items := make([]appwebhooks.PendingDelivery, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, appwebhooks.PendingDelivery{
+			ID: row.ID, WebhookID: row.SubscriptionID, URL: row.Url, Secret: row.Secret,
+			EventID: row.OutboxID, EventType: row.EventType, HouseholdID: row.HouseholdID,
+			OccurredAt: row.OccurredAt.Time, Payload: row.Payload, Attempts: row.Attempts,
+		})
+	}
+	return items, nil
+}
+
+// RecordAttempt stores the attempt and the delivery's new state together, so
+// the history always accounts for the attempt count.
+func (r *Repository) RecordAttempt(ctx context.Context, result appwebhooks.AttemptResult) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return mapDeliveryError(err)
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+	attempt := result.Attempt
+	if err := q.CreateWebhookDeliveryAttempt(ctx, sqlc.CreateWebhookDeliveryAttemptParams{
+		DeliveryID: result.DeliveryID, AttemptedAt: timestamptz(attempt.AttemptedAt), StatusCode: attempt.StatusCode,
+		Error: attempt.Error, DurationMs: int32(attempt.Duration.Milliseconds()),
+	}); err != nil {
+		return mapDeliveryError(err)
+	}
+	params := sqlc.UpdateWebhookDeliveryResultParams{
+		ID: result.DeliveryID, Status: string(result.Status), AttemptedAt: timestamptz(attempt.AttemptedAt), LastError: attempt.Error,
+	}
+	if result.NextAttemptAt != nil {
+		params.NextAttemptAt = timestamptz(*result.NextAttemptAt)
+	}
+	if err := q.UpdateWebhookDeliveryResult(ctx, params); err != nil {
+		return mapDeliveryError(err)
+	}
+	return mapDeliveryError(tx.Commit(ctx))
+}
+
+func (r *Repository) deliveries(ctx context.Context, params sqlc.ListWebhookDeliveriesParams) ([]appwebhooks.Delivery, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, mapDeliveryError(err)
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+	rows, err := q.ListWebhookDeliveries(ctx, params)
+	if err != nil {
+		return nil, mapDeliveryError(err)
+	}
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.WebhookDelivery.ID)
+	}
+	attempts, err := q.ListWebhookDeliveryAttempts(ctx, ids)
+	if err != nil {
+		return nil, mapDeliveryError(err)
+	}
+	history := map[int64][]appwebhooks.Attempt{}
+	for _, attempt := range attempts {
+		history[attempt.DeliveryID] = append(history[attempt.DeliveryID], appwebhooks.Attempt{
+			AttemptedAt: attempt.AttemptedAt.Time, StatusCode: attempt.StatusCode, Error: attempt.Error,
+			Duration: time.Duration(attempt.DurationMs) * time.Millisecond,
+		})
+	}
+	items := make([]appwebhooks.Delivery, 0, len(rows))
+	for _, row := range rows {
+		delivery := row.WebhookDelivery
+		item := appwebhooks.Delivery{
+			ID: delivery.ID, WebhookID: delivery.SubscriptionID, EventID: delivery.OutboxID, EventType: row.EventType,
+			OccurredAt: row.OccurredAt.Time, Status: appwebhooks.DeliveryStatus(delivery.Status), Attempts: delivery.Attempts,
+			NextAttemptAt: timestamp(delivery.NextAttemptAt), LastError: delivery.LastError, DeliveredAt: timestamp(delivery.DeliveredAt),
+			CreatedAt: delivery.CreatedAt.Time, History: history[delivery.ID],
+		}
+		if item.Status != appwebhooks.DeliveryPending {
+			item.NextAttemptAt = nil
+		}
+		if item.History == nil {
+			item.History = []appwebhooks.Attempt{}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func mapWebhook(row sqlc.WebhookSubscription) appwebhooks.Webhook {
+	return appwebhooks.Webhook{
+		ID: row.ID, URL: row.Url, EventTypes: row.EventTypes, HouseholdID: row.HouseholdID, Secret: row.Secret,
+		Active: row.IsActive, CreatedAt: row.CreatedAt.Time, UpdatedAt: row.UpdatedAt.Time,
+	}
+}
+
+func mapWebhookError(err error) error {
+	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeWebhookNotFound, NotFoundMessage: "webhook not found", ConflictCode: apperrors.CodeWebhookConflict, ConflictMessage: "webhook household does not exist"})
+}
+func mapDeliveryError(err error) error {
+	return postgres.MapError(err, postgres.ErrorMapping{NotFoundCode: apperrors.CodeWebhookDeliveryNotFound, NotFoundMessage: "webhook delivery not found", ConflictCode: apperrors.CodeWebhookConflict, ConflictMessage: "webhook delivery violates an invariant"})
+}
+func timestamptz(value time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: value, Valid: true}
+}
+func timestamp(value pgtype.Timestamptz) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	result := value.Time
+	return &result
+}
+
+var _ appwebhooks.Repository = (*Repository)(nil)
@@ -0,0 +1,122 @@
+// Package reportexport renders detailed budget reports as printable PDF
+// documents and XLSX workbooks for download.
+package reportexport
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+)
+
+// Format names a downloadable report format.
+type Format string
+
+const (
+	FormatPDF  Format = "pdf"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat accepts pdf or xlsx in any letter case.
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(value))); format {
+	case FormatPDF, FormatXLSX:
+		return format, nil
+	}
+	return "", fmt.Errorf("format must be pdf or xlsx")
+}
+
+// ContentType is the media type a download of the format is served with.
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/pdf"
+}
+
+// Filename names a download after the budget and its period start, such as
+// budget-12-2026-07-01.pdf.
+func Filename(report appbudgets.DetailedReport, format Format) string {
+	return fmt.Sprintf("budget-%d-%s.%s", report.Budget.ID, report.Budget.PeriodStart.Format("2006-01-02"), format)
+}
+
+// Write renders the report in format to w.
+func Write(w io.Writer, format Format, report appbudgets.DetailedReport) error {
+	switch format {
+	case FormatPDF:
+		return PDF(w, report)
+	case FormatXLSX:
+		return XLSX(w, report)
+	}
+	return fmt.Errorf("unsupported report format %q", format)
+}
+
+func title(report appbudgets.DetailedReport) string {
+	owner := "Budget"
+	switch {
+	case report.Budget.Owner.HouseholdID != nil:
+		owner = fmt.Sprintf("Household %d budget", *report.Budget.Owner.HouseholdID)
+	case report.Budget.Owner.UserID != nil:
+		owner = fmt.Sprintf("User %d budget", *report.Budget.Owner.UserID)
+	}
+	return fmt.Sprintf("%s #%d", owner, report.Budget.ID)
+}
+
+func period(report appbudgets.DetailedReport) string {
+	start, end := report.Budget.PeriodStart, report.Budget.PeriodEnd
+	kind := string(report.Budget.PeriodKind)
+	if kind != "" {
+		kind = strings.ToUpper(kind[:1]) + kind[1:] + ", "
+	}
+	return fmt.Sprintf("%s%s to %s", kind, start.Format("Jan 2, 2006"), end.Format("Jan 2, 2006"))
+}
+
+// closedNote says who closed a closed budget and when, and what its live
+// spending is now if that drifted from the closing. It is empty for open
+// budgets.
+func closedNote(report appbudgets.DetailedReport) string {
+	closing := report.Closing
+	if closing == nil {
+		return ""
+	}
+	note := fmt.Sprintf("Closed %s by %s; amounts are as closed", closing.ClosedAt.Format("Jan 2, 2006"), closing.ClosedBy.Name)
+	if closing.Drifted {
+		note += fmt.Sprintf(", live spending is now %s", money(closing.CurrentTotals.ActualAmount))
+	}
+	return note
+}
+
+// money formats a two-decimal amount such as -1234.50 as -$1,234.50.
+func money(amount string) string {
+	sign := ""
+	if strings.HasPrefix(amount, "-") {
+		sign, amount = "-", amount[1:]
+	}
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	fraction = (fraction + "00")[:2]
+	return fmt.Sprintf("%s$%s.%s", sign, whole, fraction)
+}
+
+func description(transaction appbudgets.DetailedTransaction) string {
+	if transaction.Description == nil {
+		return ""
+	}
+	return *transaction.Description
+}
+
+func category(transaction appbudgets.DetailedTransaction) string {
+	if transaction.Category == nil {
+		return ""
+	}
+	if transaction.Category.Name != "" {
+		return transaction.Category.Name
+	}
+	return transaction.Category.Code
+}
@@ -0,0 +1,203 @@
+package reportexport
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+)
+
+func sampleReport() appbudgets.DetailedReport {
+	householdID := int64(3)
+	description, notes := "Weekly shop – Café", "receipt in drawer"
+	alex := appbudgets.Author{ID: 2, Name: "Alex"}
+	line := func(name, allocation, actual, remaining string, transactions ...appbudgets.DetailedTransaction) appbudgets.DetailedReportLine {
+		return appbudgets.DetailedReportLine{
+			ReportLine:   appbudgets.ReportLine{Line: appbudgets.Line{Name: name, AllocationAmount: allocation}, ActualAmount: actual, RemainingAmount: remaining},
+			Transactions: transactions,
+			Forecast:     appbudgets.Forecast{ProjectedAmount: actual},
+			Members:      []appbudgets.MemberSpending{},
+		}
+	}
+	groceries := line("Groceries", "1200.00", "185.00", "1015.00", appbudgets.DetailedTransaction{
+		ID: 21, TransactionDate: time.Date(2026, 7, 4, 0, 0, 0, 0, time.UTC), Amount: "185.00", Description: &description, Notes: &notes,
+		Category: &appbudgets.Category{Code: "food", Name: "Food"}, Author: alex,
+	})
+	groceries.Members = []appbudgets.MemberSpending{{Member: alex, AllocationAmount: "600.00", ActualAmount: "185.00", RemainingAmount: "415.00"}}
+	return appbudgets.DetailedReport{
+		Budget: appbudgets.BudgetSummary{
+			ID: 12, Owner: appbudgets.Owner{HouseholdID: &householdID}, PeriodKind: appbudgets.PeriodMonthly,
+			PeriodStart: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC),
+		},
+		Lines: []appbudgets.DetailedReportLine{groceries, line("Rent: main/flat", "2000.00", "0.00", "2000.00"), line("RENT- main-flat", "10.00", "0.00", "10.00")},
+		UnmappedTransactions: []appbudgets.DetailedTransaction{
+			{ID: 22, TransactionDate: time.Date(2026, 7, 5, 0, 0, 0, 0, time.UTC), Amount: "-12.50", Author: alex},
+		},
+		Totals:   appbudgets.ReportTotals{AllocationAmount: "3210.00", ActualAmount: "185.00", RemainingAmount: "3025.00", UnmappedActualAmount: "-12.50", UncategorizedActualAmount: "-12.50"},
+		Forecast: appbudgets.Forecast{AsOf: time.Date(2026, 7, 10, 0, 0, 0, 0, time.UTC), ProjectedAmount: "400.00"},
+	}
+}
+
+func TestParseFormatAndDownloadMetadata(t *testing.T) {
+	format, err := ParseFormat(" XLSX ")
+	if err != nil || format != FormatXLSX || format.ContentType() != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+		t.Fatalf("format=%q error=%v", format, err)
+	}
+	if _, err := ParseFormat("csv"); err == nil || err.Error() != "format must be pdf or xlsx" {
+		t.Fatalf("csv error=%v", err)
+	}
+	if name := Filename(sampleReport(), FormatPDF); name != "budget-12-2026-07-01.pdf" || FormatPDF.ContentType() != "application/pdf" {
+		t.Fatalf("filename=%q", name)
+	}
+}
+
+func TestPDFRendersADocument(t *testing.T) {
+	var output bytes.Buffer
+	if err := Write(&output, FormatPDF, sampleReport()); err != nil {
+		t.Fatal(err)
+	}
+	body := output.String()
+	if !strings.HasPrefix(body, "%PDF-") || !strings.HasSuffix(strings.TrimSpace(body), "%%EOF") {
+		t.Fatalf("not a pdf document: %q", body[:min(len(body), 32)])
+	}
+	output.Reset()
+	if err := PDF(&output, appbudgets.DetailedReport{}); err != nil || !strings.HasPrefix(output.String(), "%PDF-") {
+		t.Fatalf("empty report error=%v", err)
+	}
+}
+
+func TestXLSXWritesSummaryLineAndUnmappedSheets(t *testing.T) {
+	var output bytes.Buffer
+	if err := Write(&output, FormatXLSX, sampleReport()); err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if sheets := file.GetSheetList(); !reflect.DeepEqual(sheets, []string{"Summary", "Groceries", "Rent- main-flat", "RENT- main-flat (2)", "Unmapped"}) {
+		t.Fatalf("sheets=%v", sheets)
+	}
+	summary, err := file.GetRows("Summary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary[0][0] != "Household 3 budget #12" || summary[1][0] != "Monthly, Jul 1, 2026 to Jul 31, 2026" {
+		t.Fatalf("summary heading=%v", summary[:2])
+	}
+	if want := []string{"Groceries", "1,200.00", "185.00", "1,015.00", "185.00", "1"}; !reflect.DeepEqual(summary[5], want) {
+		t.Fatalf("summary line=%v", summary[5])
+	}
+	if total := summary[8]; total[0] != "Total" || total[1] != "3,210.00" || total[4] != "400.00" {
+		t.Fatalf("summary total=%v", total)
+	}
+	if value, err := file.GetCellValue("Summary", "B6", excelize.Options{RawCellValue: true}); err != nil || value != "1200" {
+		t.Fatalf("allocation cell=%q error=%v", value, err)
+	}
+	groceries, err := file.GetRows("Groceries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if groceries[4][0] != "Member" || groceries[5][0] != "Alex" || groceries[5][1] != "600.00" {
+		t.Fatalf("member rows=%v", groceries)
+	}
+	if want := []string{"2026-07-04", "Weekly shop – Café", "Food", "Alex", "receipt in drawer", "185.00"}; !reflect.DeepEqual(groceries[8], want) {
+		t.Fatalf("transaction row=%v", groceries[8])
+	}
+	unmapped, err := file.GetRows("Unmapped")
+	if err != nil || len(unmapped) != 2 || unmapped[1][5] != "-12.50" {
+		t.Fatalf("unmapped=%v error=%v", unmapped, err)
+	}
+}
+
+// closedRepository serves one closed budget's detailed snapshot.
+type closedRepository struct {
+	appbudgets.Repository
+	snapshot appbudgets.DetailedReportSnapshot
+}
+
+func (r closedRepository) FindByID(context.Context, int64) (appbudgets.Budget, error) {
+	return r.snapshot.Budget, nil
+}
+
+func (r closedRepository) LoadDetailedSnapshot(context.Context, appbudgets.Owner, appbudgets.Period) (appbudgets.DetailedReportSnapshot, error) {
+	return r.snapshot, nil
+}
+
+func TestExportsOfClosedBudgetsPrintTheClosingSnapshot(t *testing.T) {
+	householdID := int64(3)
+	// The groceries transaction grew from 185.00 to 210.00 after the close.
+	repo := closedRepository{snapshot: appbudgets.DetailedReportSnapshot{
+		Budget: appbudgets.Budget{
+			ID: 12, Owner: appbudgets.Owner{HouseholdID: &householdID}, PeriodKind: appbudgets.PeriodMonthly,
+			PeriodStart: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC),
+		},
+		Lines: []appbudgets.DetailedReportLineData{{
+			ReportLineData: appbudgets.ReportLineData{Line: appbudgets.Line{ID: 1, Name: "Groceries", AllocationAmount: "1200.00"}, ActualAmount: "210.00"},
+			Transactions:   []appbudgets.DetailedTransaction{{ID: 21, TransactionDate: time.Date(2026, 7, 4, 0, 0, 0, 0, time.UTC), Amount: "210.00"}},
+		}},
+		UncategorizedAmount: "0",
+		Closing: &appbudgets.Closing{
+			ID: 3, ClosedAt: time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC), ClosedBy: appbudgets.Author{ID: 2, Name: "Alex"},
+			Lines:  []appbudgets.ClosingLine{{LineID: int64Pointer(1), Name: "Groceries", ActualAmount: "185.00"}},
+			Totals: appbudgets.ReportTotals{AllocationAmount: "1200.00", ActualAmount: "185.00", UnmappedActualAmount: "0.00", UncategorizedActualAmount: "0.00"},
+		},
+	}}
+	report, err := appbudgets.NewService(repo).DetailedReport(context.Background(), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output bytes.Buffer
+	if err := Write(&output, FormatPDF, report); err != nil || !strings.HasPrefix(output.String(), "%PDF-") {
+		t.Fatalf("pdf error=%v", err)
+	}
+	output.Reset()
+	if err := Write(&output, FormatXLSX, report); err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	summary, err := file.GetRows("Summary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note := summary[2][0]; note != "Closed Aug 1, 2026 by Alex; amounts are as closed, live spending is now $210.00" {
+		t.Fatalf("closed note=%q", note)
+	}
+	if want := []string{"Groceries", "1,200.00", "185.00", "1,015.00", "185.00", "1"}; !reflect.DeepEqual(summary[6], want) {
+		t.Fatalf("summary line=%v", summary[6])
+	}
+	if total := summary[7]; total[0] != "Total" || total[2] != "185.00" || total[3] != "1,015.00" || total[4] != "185.00" {
+		t.Fatalf("summary total=%v", total)
+	}
+}
+
+func int64Pointer(value int64) *int64 { return &value }
+
+func TestSheetNamesAreValidAndUnique(t *testing.T) {
+	names := sheetNames{"summary": true}
+	long := strings.Repeat("x", 40)
+	got := []string{names.claim("Summary"), names.claim("'Bills?'"), names.claim(" "), names.claim(long), names.claim(long)}
+	want := []string{"Summary (2)", "Bills-", "Line", strings.Repeat("x", 31), strings.Repeat("x", 27) + " (2)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("names=%q", got)
+	}
+}
+
+func TestMoneyFormatsThousandsAndSign(t *testing.T) {
+	for value, want := range map[string]string{"0.00": "$0.00", "1234567.5": "$1,234,567.50", "-999.99": "-$999.99", "100": "$100.00"} {
+		if got := money(value); got != want {
+			t.Errorf("money(%q)=%q want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package reportexport
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-pdf/fpdf"
+
+	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+)
+
+const (
+	pdfMargin = 15.0
+	pdfRow    = 6.0
+)
+
+var (
+	summaryColumns     = []pdfColumn{{"Line", 60, "L"}, {"Allocated", 30, "R"}, {"Spent", 30, "R"}, {"Remaining", 30, "R"}, {"Projected", 30, "R"}}
+	memberColumns      = []pdfColumn{{"Member", 60, "L"}, {"Allocated", 40, "R"}, {"Spent", 40, "R"}, {"Remaining", 40, "R"}}
+	transactionColumns = []pdfColumn{{"Date", 22, "L"}, {"Description", 68, "L"}, {"Category", 35, "L"}, {"Author", 30, "L"}, {"Amount", 25, "R"}}
+)
+
+type pdfColumn struct {
+	title string
+	width float64
+	align string
+}
+
+// PDF renders an A4 report: a summary of every line, then each line's member
+// spending and transactions, then unmapped spending.
+func PDF(w io.Writer, report appbudgets.DetailedReport) error {
+	doc := fpdf.New("P", "mm", "A4", "")
+	doc.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	doc.SetAutoPageBreak(true, pdfMargin)
+	doc.SetTitle(title(report), true)
+	doc.AliasNbPages("")
+	if !report.Forecast.AsOf.IsZero() {
+		doc.SetCreationDate(report.Forecast.AsOf)
+	}
+	writer := pdfWriter{doc: doc, tr: doc.UnicodeTranslatorFromDescriptor("")}
+	doc.SetFooterFunc(func() {
+		doc.SetY(-pdfMargin + 5)
+		doc.SetFont("Helvetica", "", 8)
+		doc.SetTextColor(110, 110, 110)
+		doc.CellFormat(0, 4, fmt.Sprintf("Page %d of {nb}", doc.PageNo()), "", 0, "R", false, 0, "")
+	})
+	doc.AddPage()
+
+	doc.SetFont("Helvetica", "B", 16)
+	doc.CellFormat(0, 9, writer.tr(title(report)), "", 1, "L", false, 0, "")
+	doc.SetFont("Helvetica", "", 10)
+	doc.SetTextColor(90, 90, 90)
+	doc.CellFormat(0, 5, writer.tr(period(report)), "", 1, "L", false, 0, "")
+	if note := closedNote(report); note != "" {
+		doc.CellFormat(0, 5, writer.tr(note), "", 1, "L", false, 0, "")
+	}
+	if !report.Forecast.AsOf.IsZero() {
+		doc.CellFormat(0, 5, "Projections as of "+report.Forecast.AsOf.Format("Jan 2, 2006"), "", 1, "L", false, 0, "")
+	}
+	doc.SetTextColor(0, 0, 0)
+
+	writer.heading("Summary")
+	writer.header(summaryColumns)
+	for _, line := range report.Lines {
+		writer.row(summaryColumns, false, line.Name, money(line.AllocationAmount), money(line.ActualAmount), money(line.RemainingAmount), money(line.Forecast.ProjectedAmount))
+	}
+	writer.row(summaryColumns, true, "Total", money(report.Totals.AllocationAmount), money(report.Totals.ActualAmount), money(report.Totals.RemainingAmount), money(report.Forecast.ProjectedAmount))
+	writer.row(summaryColumns, false, "Unmapped spending", "", money(report.Totals.UnmappedActualAmount), "", "")
+
+	for _, line := range report.Lines {
+		writer.heading(line.Name)
+		writer.note(fmt.Sprintf("Spent %s of %s, %s remaining", money(line.ActualAmount), money(line.AllocationAmount), money(line.RemainingAmount)))
+		if len(line.Members) > 0 {
+			writer.header(memberColumns)
+			for _, member := range line.Members {
+				writer.row(memberColumns, false, member.Member.Name, money(member.AllocationAmount), money(member.ActualAmount), money(member.RemainingAmount))
+			}
+			doc.Ln(2)
+		}
+		writer.transactions(line.Transactions, "No transactions in this period.")
+	}
+	writer.heading("Unmapped spending")
+	writer.transactions(report.UnmappedTransactions, "No unmapped spending.")
+
+	if err := doc.Error(); err != nil {
+		return fmt.Errorf("render pdf report: %w", err)
+	}
+	if err := doc.Output(w); err != nil {
+		return fmt.Errorf("write pdf report: %w", err)
+	}
+	return nil
+}
+
+type pdfWriter struct {
+	doc *fpdf.Fpdf
+	tr  func(string) string
+}
+
+// heading starts a section, moving to a new page when the heading and its
+// first rows would not fit.
+func (p pdfWriter) heading(text string) {
+	p.breakBefore(4 * pdfRow)
+	p.doc.Ln(4)
+	p.doc.SetFont("Helvetica", "B", 12)
+	p.doc.CellFormat(0, 7, p.fit(text, 180), "", 1, "L", false, 0, "")
+}
+
+func (p pdfWriter) note(text string) {
+	p.doc.SetFont("Helvetica", "", 9)
+	p.doc.SetTextColor(90, 90, 90)
+	p.doc.CellFormat(0, 5, p.tr(text), "", 1, "L", false, 0, "")
+	p.doc.SetTextColor(0, 0, 0)
+}
+
+func (p pdfWriter) transactions(items []appbudgets.DetailedTransaction, empty string) {
+	if len(items) == 0 {
+		p.note(empty)
+		return
+	}
+	p.header(transactionColumns)
+	for _, item := range items {
+		p.row(transactionColumns, false, item.TransactionDate.Format("2006-01-02"), description(item), category(item), item.Author.Name, money(item.Amount))
+	}
+}
+
+func (p pdfWriter) header(columns []pdfColumn) {
+	p.breakBefore(2 * pdfRow)
+	p.doc.SetFont("Helvetica", "B", 9)
+	p.doc.SetFillColor(235, 235, 235)
+	for _, column := range columns {
+		p.doc.CellFormat(column.width, pdfRow, column.title, "B", 0, column.align, true, 0, "")
+	}
+	p.doc.Ln(-1)
+}
+
+// row writes one table row, repeating the header on a new page when the
+// current page is full.
+func (p pdfWriter) row(columns []pdfColumn, bold bool, values ...string) {
+	if p.breakBefore(pdfRow) {
+		p.header(columns)
+	}
+	style := ""
+	if bold {
+		style = "B"
+	}
+	p.doc.SetFont("Helvetica", style, 9)
+	for i, column := range columns {
+		p.doc.CellFormat(column.width, pdfRow, p.fit(values[i], column.width), "", 0, column.align, false, 0, "")
+	}
+	p.doc.Ln(-1)
+}
+
+func (p pdfWriter) breakBefore(height float64) bool {
+	_, pageHeight := p.doc.GetPageSize()
+	if p.doc.GetY()+height <= pageHeight-pdfMargin {
+		return false
+	}
+	p.doc.AddPage()
+	return true
+}
+
+// fit translates text to the core font encoding and truncates it to the cell
+// width.
+func (p pdfWriter) fit(text string, width float64) string {
+	text = p.tr(text)
+	limit := width - 2
+	if p.doc.GetStringWidth(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && p.doc.GetStringWidth(string(runes)+"...") > limit {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,243 @@
+package reportexport
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+)
+
+const (
+	summarySheet      = "Summary"
+	unmappedSheet     = "Unmapped"
+	maxSheetNameRunes = 31
+)
+
+var transactionHeader = []any{"Date", "Description", "Category", "Author", "Notes", "Amount"}
+
+// XLSX renders a workbook with a Summary sheet of every line, one sheet per
+// line with its member spending and transactions, and an Unmapped sheet.
+// Amounts are numeric cells so the workbook can be totalled and charted.
+func XLSX(w io.Writer, report appbudgets.DetailedReport) error {
+	file := excelize.NewFile()
+	defer file.Close()
+	styles, err := newXLSXStyles(file)
+	if err != nil {
+		return fmt.Errorf("create xlsx styles: %w", err)
+	}
+	if err := file.SetSheetName(file.GetSheetName(0), summarySheet); err != nil {
+		return fmt.Errorf("name xlsx summary sheet: %w", err)
+	}
+	if err := file.SetDocProps(&excelize.DocProperties{Title: title(report)}); err != nil {
+		return fmt.Errorf("set xlsx properties: %w", err)
+	}
+
+	summary := &sheetWriter{file: file, name: summarySheet, styles: styles, row: 1}
+	summary.put(styles.bold, title(report))
+	summary.put(0, period(report))
+	if note := closedNote(report); note != "" {
+		summary.put(0, note)
+	}
+	if !report.Forecast.AsOf.IsZero() {
+		summary.put(0, "Projections as of", report.Forecast.AsOf)
+	}
+	summary.row++
+	summary.put(styles.header, "Line", "Allocated", "Spent", "Remaining", "Projected", "Transactions")
+	for _, line := range report.Lines {
+		summary.put(0, line.Name, amount(line.AllocationAmount), amount(line.ActualAmount), amount(line.RemainingAmount), amount(line.Forecast.ProjectedAmount), len(line.Transactions))
+	}
+	summary.put(styles.bold, "Total", amount(report.Totals.AllocationAmount), amount(report.Totals.ActualAmount), amount(report.Totals.RemainingAmount), amount(report.Forecast.ProjectedAmount))
+	summary.put(0, "Unmapped spending", nil, amount(report.Totals.UnmappedActualAmount), nil, nil, len(report.UnmappedTransactions))
+	summary.columns(32, 14, 14, 14, 14, 14)
+	if summary.err != nil {
+		return fmt.Errorf("write xlsx summary: %w", summary.err)
+	}
+
+	names := sheetNames{strings.ToLower(summarySheet): true, strings.ToLower(unmappedSheet): true}
+	for _, line := range report.Lines {
+		sheet, err := newSheet(file, names.claim(line.Name), styles)
+		if err != nil {
+			return err
+		}
+		sheet.put(styles.bold, line.Name)
+		sheet.put(styles.header, "Allocated", "Spent", "Remaining", "Projected")
+		sheet.put(0, amount(line.AllocationAmount), amount(line.ActualAmount), amount(line.RemainingAmount), amount(line.Forecast.ProjectedAmount))
+		if len(line.Members) > 0 {
+			sheet.row++
+			sheet.put(styles.header, "Member", "Allocated", "Spent", "Remaining")
+			for _, member := range line.Members {
+				sheet.put(0, member.Member.Name, amount(member.AllocationAmount), amount(member.ActualAmount), amount(member.RemainingAmount))
+			}
+		}
+		sheet.row++
+		sheet.transactions(line.Transactions)
+		if sheet.err != nil {
+			return fmt.Errorf("write xlsx sheet for line %q: %w", line.Name, sheet.err)
+		}
+	}
+	unmapped, err := newSheet(file, unmappedSheet, styles)
+	if err != nil {
+		return err
+	}
+	unmapped.transactions(report.UnmappedTransactions)
+	if unmapped.err != nil {
+		return fmt.Errorf("write xlsx unmapped sheet: %w", unmapped.err)
+	}
+
+	if err := file.Write(w); err != nil {
+		return fmt.Errorf("write xlsx report: %w", err)
+	}
+	return nil
+}
+
+type xlsxStyles struct {
+	bold, header, money, boldMoney, date int
+}
+
+func newXLSXStyles(file *excelize.File) (xlsxStyles, error) {
+	moneyFormat, dateFormat := "#,##0.00", "yyyy-mm-dd"
+	var styles xlsxStyles
+	var err error
+	if styles.bold, err = file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return xlsxStyles{}, err
+	}
+	if styles.header, err = file.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"EBEBEB"}},
+	}); err != nil {
+		return xlsxStyles{}, err
+	}
+	if styles.money, err = file.NewStyle(&excelize.Style{CustomNumFmt: &moneyFormat}); err != nil {
+		return xlsxStyles{}, err
+	}
+	if styles.boldMoney, err = file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}, CustomNumFmt: &moneyFormat}); err != nil {
+		return xlsxStyles{}, err
+	}
+	if styles.date, err = file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return xlsxStyles{}, err
+	}
+	return styles, nil
+}
+
+func newSheet(file *excelize.File, name string, styles xlsxStyles) (*sheetWriter, error) {
+	if _, err := file.NewSheet(name); err != nil {
+		return nil, fmt.Errorf("create xlsx sheet %q: %w", name, err)
+	}
+	sheet := &sheetWriter{file: file, name: name, styles: styles, row: 1}
+	sheet.columns(12, 36, 20, 18, 28, 14)
+	return sheet, nil
+}
+
+// sheetWriter appends rows to one sheet and keeps the first error, so callers
+// check it once per sheet.
+type sheetWriter struct {
+	file   *excelize.File
+	name   string
+	styles xlsxStyles
+	row    int
+	err    error
+}
+
+// put writes values to the next row with rowStyle, which is zero, bold or
+// header. Amounts and dates get their number formats.
+func (s *sheetWriter) put(rowStyle int, values ...any) {
+	if s.err != nil {
+		return
+	}
+	start := cell(1, s.row)
+	if s.err = s.file.SetSheetRow(s.name, start, &values); s.err != nil {
+		return
+	}
+	if rowStyle != 0 {
+		s.err = s.file.SetCellStyle(s.name, start, cell(len(values), s.row), rowStyle)
+	}
+	for i, value := range values {
+		style := 0
+		switch value.(type) {
+		case float64:
+			style = s.styles.money
+			if rowStyle == s.styles.bold {
+				style = s.styles.boldMoney
+			}
+		case time.Time:
+			style = s.styles.date
+		}
+		if style != 0 && s.err == nil {
+			s.err = s.file.SetCellStyle(s.name, cell(i+1, s.row), cell(i+1, s.row), style)
+		}
+	}
+	s.row++
+}
+
+func (s *sheetWriter) transactions(items []appbudgets.DetailedTransaction) {
+	s.put(s.styles.header, transactionHeader...)
+	for _, item := range items {
+		notes := ""
+		if item.Notes != nil {
+			notes = *item.Notes
+		}
+		s.put(0, item.TransactionDate, description(item), category(item), item.Author.Name, notes, amount(item.Amount))
+	}
+}
+
+func (s *sheetWriter) columns(widths ...float64) {
+	for i, width := range widths {
+		if s.err == nil {
+			column, _ := excelize.ColumnNumberToName(i + 1)
+			s.err = s.file.SetColWidth(s.name, column, column, width)
+		}
+	}
+}
+
+// sheetNames hands out unique sheet names. Excel compares them without case.
+type sheetNames map[string]bool
+
+// claim turns a line name into a valid sheet name, at most 31 characters
+// without []:*?/\, numbering repeats as "Name (2)".
+func (n sheetNames) claim(value string) string {
+	base := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(value))
+	base = strings.Trim(base, "'")
+	if base == "" {
+		base = "Line"
+	}
+	for index := 1; ; index++ {
+		suffix := ""
+		if index > 1 {
+			suffix = fmt.Sprintf(" (%d)", index)
+		}
+		runes := []rune(base)
+		if limit := maxSheetNameRunes - len(suffix); len(runes) > limit {
+			runes = runes[:limit]
+		}
+		name := strings.TrimSpace(string(runes)) + suffix
+		if !n[strings.ToLower(name)] {
+			n[strings.ToLower(name)] = true
+			return name
+		}
+	}
+}
+
+// amount turns a two-decimal amount into a number, leaving anything
+// unparsable as text.
+func amount(value string) any {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return parsed
+}
+
+func cell(column, row int) string {
+	name, _ := excelize.CoordinatesToCellName(column, row)
+	return name
+}
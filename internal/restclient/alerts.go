@@ -0,0 +1,27 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+func (c *Client) ListAlerts(ctx context.Context, input api.AlertQuery) ([]api.BudgetAlert, error) {
+	var response []api.BudgetAlert
+	err := c.do(ctx, http.MethodGet, api.AlertsPath, alertQuery(input), nil, &response)
+	return response, err
+}
+
+func alertQuery(input api.AlertQuery) url.Values {
+	query := url.Values{}
+	setInt64(query, "householdId", input.HouseholdID)
+	setInt64(query, "userId", input.UserID)
+	setInt64(query, "budgetId", input.BudgetID)
+	if input.Limit != 0 {
+		query.Set("limit", strconv.Itoa(input.Limit))
+	}
+	return query
+}
@@ -0,0 +1,26 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+func TestListAlertsEncodesFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet || request.URL.RequestURI() != "/v1/alerts?budgetId=5&householdId=3&limit=10" {
+			t.Errorf("request = %s %s", request.Method, request.URL.RequestURI())
+		}
+		_, _ = w.Write([]byte(`[{"id":9,"budgetLineName":"Groceries","thresholdPercent":80}]`))
+	}))
+	defer server.Close()
+	client, _ := New(Config{BaseURL: server.URL, APIKey: "key"})
+	householdID, budgetID := int64(3), int64(5)
+	alerts, err := client.ListAlerts(context.Background(), api.AlertQuery{HouseholdID: &householdID, BudgetID: &budgetID, Limit: 10})
+	if err != nil || len(alerts) != 1 || alerts[0].BudgetLineName != "Groceries" || alerts[0].ThresholdPercent != 80 {
+		t.Fatalf("alerts=%+v err=%v", alerts, err)
+	}
+}
@@ -0,0 +1,53 @@
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+// UploadAttachment attaches content to a transaction under fileName. The
+// server detects the content type from the bytes.
+func (c *Client) UploadAttachment(ctx context.Context, transactionID int64, fileName string, content io.Reader) (api.Attachment, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(api.AttachmentFileField, fileName)
+	if err != nil {
+		return api.Attachment{}, &TransportError{Operation: "encode request", Err: err}
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return api.Attachment{}, &TransportError{Operation: "read attachment", Err: err}
+	}
+	if err := writer.Close(); err != nil {
+		return api.Attachment{}, &TransportError{Operation: "encode request", Err: err}
+	}
+	var response api.Attachment
+	input := encodedBody{contentType: writer.FormDataContentType(), data: body.Bytes()}
+	err = c.do(ctx, http.MethodPost, replace(api.TransactionAttachmentsPath, "{id}", transactionID), nil, input, &response)
+	return response, err
+}
+
+func (c *Client) ListAttachments(ctx context.Context, transactionID int64) ([]api.Attachment, error) {
+	var response []api.Attachment
+	err := c.do(ctx, http.MethodGet, replace(api.TransactionAttachmentsPath, "{id}", transactionID), nil, nil, &response)
+	return response, err
+}
+
+func (c *Client) GetAttachment(ctx context.Context, id int64) (api.Attachment, error) {
+	var response api.Attachment
+	err := c.do(ctx, http.MethodGet, replace(api.AttachmentPath, "{id}", id), nil, nil, &response)
+	return response, err
+}
+
+// DownloadAttachment writes the attachment's contents to w.
+func (c *Client) DownloadAttachment(ctx context.Context, id int64, w io.Writer) error {
+	return c.download(ctx, replace(api.AttachmentContentPath, "{id}", id), nil, w)
+}
+
+func (c *Client) DeleteAttachment(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, replace(api.AttachmentPath, "{id}", id), nil, nil, nil)
+}
@@ -0,0 +1,95 @@
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+func TestUploadAttachmentSendsOneMultipartBodyAcrossRetries(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || request.URL.Path != "/v1/transactions/4/attachments" {
+			t.Errorf("request = %s %s", request.Method, request.URL.Path)
+		}
+		file, header, err := request.FormFile(api.AttachmentFileField)
+		if err != nil {
+			t.Errorf("form file: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		if header.Filename != "receipt.png" || string(data) != "png bytes" {
+			t.Errorf("file = %s %q", header.Filename, data)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":3,"transactionId":4,"fileName":"receipt.png","contentType":"image/png","size":9,"sha256":"x","createdAt":"2026-06-13T00:00:00Z"}`))
+	}))
+	defer server.Close()
+	client, _ := New(Config{BaseURL: server.URL, APIKey: "key", Retries: 1})
+	transport, failed := http.DefaultTransport, false
+	client.http.Transport = roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(request.Body)
+		bodies = append(bodies, string(body))
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		if !failed {
+			failed = true
+			return nil, errors.New("connection reset")
+		}
+		return transport.RoundTrip(request)
+	})
+	attachment, err := client.UploadAttachment(context.Background(), 4, "receipt.png", strings.NewReader("png bytes"))
+	if err != nil || attachment.ID != 3 {
+		t.Fatalf("attachment = %+v, error = %v", attachment, err)
+	}
+	if len(bodies) != 2 || bodies[0] != bodies[1] {
+		t.Fatalf("retry sent a different body: %q", bodies)
+	}
+}
+
+func TestAttachmentMethods(t *testing.T) {
+	tests := []struct {
+		name, method, path, response string
+		status                       int
+		call                         func(*Client) error
+	}{
+		{"list", http.MethodGet, "/v1/transactions/4/attachments", `[]`, http.StatusOK, func(c *Client) error {
+			_, err := c.ListAttachments(context.Background(), 4)
+			return err
+		}},
+		{"get", http.MethodGet, "/v1/attachments/3", `{}`, http.StatusOK, func(c *Client) error { _, err := c.GetAttachment(context.Background(), 3); return err }},
+		{"download", http.MethodGet, "/v1/attachments/3/content", `%PDF-1.7`, http.StatusOK, func(c *Client) error {
+			var content bytes.Buffer
+			if err := c.DownloadAttachment(context.Background(), 3, &content); err != nil || content.String() != "%PDF-1.7" {
+				return errors.Join(err, errors.New("content = "+content.String()))
+			}
+			return nil
+		}},
+		{"delete", http.MethodDelete, "/v1/attachments/3", ``, http.StatusNoContent, func(c *Client) error { return c.DeleteAttachment(context.Background(), 3) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				if request.Method != test.method || request.URL.RequestURI() != test.path {
+					t.Errorf("request = %s %s", request.Method, request.URL.RequestURI())
+				}
+				w.WriteHeader(test.status)
+				if test.response != "" {
+					_, _ = w.Write([]byte(test.response))
+				}
+			}))
+			defer server.Close()
+			client, _ := New(Config{BaseURL: server.URL, APIKey: "key"})
+			if err := test.call(client); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package restclient
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -21,20 +22,50 @@ func (c *Client) EnsureMonthlyBudget(ctx context.Context, input api.EnsureMonthl
 	return response, err
 }
 
+func (c *Client) GetBudget(ctx context.Context, input api.BudgetPeriodQuery) (api.Budget, error) {
+	var response api.Budget
+	err := c.do(ctx, http.MethodGet, api.BudgetsPath, periodQuery(input), nil, &response)
+	return response, err
+}
+
+func (c *Client) EnsureBudget(ctx context.Context, input api.BudgetPeriodQuery) (api.Budget, error) {
+	var response api.Budget
+	err := c.do(ctx, http.MethodPut, api.BudgetsPath, periodQuery(input), nil, &response)
+	return response, err
+}
+
 func (c *Client) CreateBudgetLine(ctx context.Context, budgetID int64, request api.CreateBudgetLineRequest) (api.BudgetLine, error) {
 	var response api.BudgetLine
 	err := c.do(ctx, http.MethodPost, replace(api.BudgetLinesPath, "{id}", budgetID), nil, request, &response)
 	return response, err
 }
 
-func (c *Client) UpdateBudgetLine(ctx context.Context, lineID int64, request api.UpdateBudgetLineRequest) (api.BudgetLine, error) {
+func (c *Client) UpdateBudgetLine(ctx context.Context, lineID int64, request api.UpdateBudgetLineRequest, options ...RequestOption) (api.BudgetLine, error) {
 	var response api.BudgetLine
-	err := c.do(ctx, http.MethodPatch, replace(api.BudgetLinePath, "{id}", lineID), nil, request, &response)
+	err := c.do(ctx, http.MethodPatch, replace(api.BudgetLinePath, "{id}", lineID), nil, request, &response, options...)
 	return response, err
 }
 
-func (c *Client) DeleteBudgetLine(ctx context.Context, lineID int64) error {
-	return c.do(ctx, http.MethodDelete, replace(api.BudgetLinePath, "{id}", lineID), nil, nil, nil)
+func (c *Client) DeleteBudgetLine(ctx context.Context, lineID int64, options ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, replace(api.BudgetLinePath, "{id}", lineID), nil, nil, nil, options...)
+}
+
+func (c *Client) ReallocateBudget(ctx context.Context, budgetID int64, request api.ReallocateBudgetRequest) (api.BudgetReallocationResult, error) {
+	var response api.BudgetReallocationResult
+	err := c.do(ctx, http.MethodPost, replace(api.BudgetReallocationsPath, "{id}", budgetID), nil, request, &response)
+	return response, err
+}
+
+func (c *Client) CloseBudget(ctx context.Context, budgetID int64, request api.CloseBudgetRequest) (api.BudgetClosing, error) {
+	var response api.BudgetClosing
+	err := c.do(ctx, http.MethodPost, replace(api.BudgetClosePath, "{id}", budgetID), nil, request, &response)
+	return response, err
+}
+
+func (c *Client) ReopenBudget(ctx context.Context, budgetID int64, request api.ReopenBudgetRequest) (api.BudgetClosing, error) {
+	var response api.BudgetClosing
+	err := c.do(ctx, http.MethodPost, replace(api.BudgetReopenPath, "{id}", budgetID), nil, request, &response)
+	return response, err
 }
 
 func (c *Client) GetBudgetReport(ctx context.Context, budgetID int64) (api.BudgetReport, error) {
@@ -43,9 +74,92 @@ func (c *Client) GetBudgetReport(ctx context.Context, budgetID int64) (api.Budge
 	return response, err
 }
 
+// ExportBudgetReport writes the budget's detailed report to w in format,
+// api.BudgetReportFormatPDF or api.BudgetReportFormatXLSX.
+func (c *Client) ExportBudgetReport(ctx context.Context, budgetID int64, format string, w io.Writer) error {
+	return c.download(ctx, replace(api.BudgetReportPath, "{id}", budgetID), url.Values{"format": {format}}, w)
+}
+
+func (c *Client) GetBudgetLint(ctx context.Context, budgetID int64) (api.BudgetLint, error) {
+	var response api.BudgetLint
+	err := c.do(ctx, http.MethodGet, replace(api.BudgetLintPath, "{id}", budgetID), nil, nil, &response)
+	return response, err
+}
+
+func (c *Client) GetBudgetTrends(ctx context.Context, input api.BudgetTrendQuery) (api.BudgetTrends, error) {
+	var response api.BudgetTrends
+	query := url.Values{"from": []string{input.From}, "to": []string{input.To}}
+	setInt64(query, "householdId", input.HouseholdID)
+	setInt64(query, "userId", input.UserID)
+	err := c.do(ctx, http.MethodGet, api.BudgetTrendsPath, query, nil, &response)
+	return response, err
+}
+
+func (c *Client) CompareBudgets(ctx context.Context, input api.BudgetComparisonQuery) (api.BudgetComparison, error) {
+	var response api.BudgetComparison
+	query := url.Values{"a": []string{strconv.FormatInt(input.A, 10)}, "b": []string{strconv.FormatInt(input.B, 10)}}
+	err := c.do(ctx, http.MethodGet, api.BudgetComparePath, query, nil, &response)
+	return response, err
+}
+
+func (c *Client) CreateBudgetTemplate(ctx context.Context, request api.CreateBudgetTemplateRequest) (api.BudgetTemplate, error) {
+	var response api.BudgetTemplate
+	err := c.do(ctx, http.MethodPost, api.BudgetTemplatesPath, nil, request, &response)
+	return response, err
+}
+
+func (c *Client) ListBudgetTemplates(ctx context.Context, input api.BudgetTemplateQuery) ([]api.BudgetTemplate, error) {
+	var response []api.BudgetTemplate
+	query := url.Values{}
+	setInt64(query, "householdId", input.HouseholdID)
+	setInt64(query, "userId", input.UserID)
+	err := c.do(ctx, http.MethodGet, api.BudgetTemplatesPath, query, nil, &response)
+	return response, err
+}
+
+func (c *Client) GetBudgetTemplate(ctx context.Context, id int64) (api.BudgetTemplate, error) {
+	var response api.BudgetTemplate
+	err := c.do(ctx, http.MethodGet, replace(api.BudgetTemplatePath, "{id}", id), nil, nil, &response)
+	return response, err
+}
+
+func (c *Client) UpdateBudgetTemplate(ctx context.Context, id int64, request api.UpdateBudgetTemplateRequest) (api.BudgetTemplate, error) {
+	var response api.BudgetTemplate
+	err := c.do(ctx, http.MethodPatch, replace(api.BudgetTemplatePath, "{id}", id), nil, request, &response)
+	return response, err
+}
+
+func (c *Client) DeleteBudgetTemplate(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, replace(api.BudgetTemplatePath, "{id}", id), nil, nil, nil)
+}
+
+func (c *Client) SaveBudgetTemplate(ctx context.Context, budgetID int64, request api.SaveBudgetTemplateRequest) (api.BudgetTemplate, error) {
+	var response api.BudgetTemplate
+	err := c.do(ctx, http.MethodPost, replace(api.BudgetSaveTemplatePath, "{id}", budgetID), nil, request, &response)
+	return response, err
+}
+
+func (c *Client) ApplyBudgetTemplate(ctx context.Context, budgetID int64, request api.ApplyBudgetTemplateRequest) (api.BudgetTemplateApplication, error) {
+	var response api.BudgetTemplateApplication
+	err := c.do(ctx, http.MethodPost, replace(api.BudgetApplyTemplatePath, "{id}", budgetID), nil, request, &response)
+	return response, err
+}
+
 func monthlyQuery(input api.MonthlyBudgetQuery) url.Values {
 	query := url.Values{"year": []string{strconv.Itoa(input.Year)}, "month": []string{strconv.Itoa(input.Month)}}
 	setInt64(query, "householdId", input.HouseholdID)
 	setInt64(query, "userId", input.UserID)
 	return query
 }
+
+func periodQuery(input api.BudgetPeriodQuery) url.Values {
+	query := url.Values{"period": []string{input.Period}}
+	setInt64(query, "householdId", input.HouseholdID)
+	setInt64(query, "userId", input.UserID)
+	for name, value := range map[string]string{"date": input.Date, "anchor": input.Anchor, "start": input.Start, "end": input.End, "template": input.Template} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	return query
+}
@@ -1,7 +1,9 @@
 package restclient
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -24,6 +26,14 @@ func TestBudgetMethods(t *testing.T) {
 			_, err := c.EnsureMonthlyBudget(context.Background(), api.EnsureMonthlyBudgetRequest{HouseholdID: &householdID, Year: 2026, Month: 7})
 			return err
 		}},
+		{"get period", http.MethodGet, "/v1/budgets?anchor=2026-01-02&date=2026-10-19&householdId=3&period=biweekly", `{}`, http.StatusOK, func(c *Client) error {
+			_, err := c.GetBudget(context.Background(), api.BudgetPeriodQuery{HouseholdID: &householdID, Period: "biweekly", Date: "2026-10-19", Anchor: "2026-01-02"})
+			return err
+		}},
+		{"ensure period", http.MethodPut, "/v1/budgets?end=2026-10-15&householdId=3&period=custom&start=2026-10-01", `{}`, http.StatusCreated, func(c *Client) error {
+			_, err := c.EnsureBudget(context.Background(), api.BudgetPeriodQuery{HouseholdID: &householdID, Period: "custom", Start: "2026-10-01", End: "2026-10-15"})
+			return err
+		}},
 		{"create line", http.MethodPost, "/v1/budgets/5/lines", `{}`, http.StatusCreated, func(c *Client) error {
 			_, err := c.CreateBudgetLine(context.Background(), 5, api.CreateBudgetLineRequest{})
 			return err
@@ -33,6 +43,49 @@ func TestBudgetMethods(t *testing.T) {
 			return err
 		}},
 		{"delete line", http.MethodDelete, "/v1/budget-lines/6", ``, http.StatusNoContent, func(c *Client) error { return c.DeleteBudgetLine(context.Background(), 6) }},
+		{"reallocate", http.MethodPost, "/v1/budgets/5/reallocations", `{"reallocation":{},"fromLine":{},"toLine":{}}`, http.StatusCreated, func(c *Client) error {
+			_, err := c.ReallocateBudget(context.Background(), 5, api.ReallocateBudgetRequest{FromLineID: 6, ToLineID: 7, Amount: "50.00", UserID: 1})
+			return err
+		}},
+		{"compare", http.MethodGet, "/v1/budgets/compare?a=12&b=13", `{"lines":[],"totals":{}}`, http.StatusOK, func(c *Client) error {
+			_, err := c.CompareBudgets(context.Background(), api.BudgetComparisonQuery{A: 12, B: 13})
+			return err
+		}},
+		{"trends", http.MethodGet, "/v1/budgets/trends?from=2026-01&householdId=3&to=2026-06", `{"months":[],"lines":[],"categories":[]}`, http.StatusOK, func(c *Client) error {
+			_, err := c.GetBudgetTrends(context.Background(), api.BudgetTrendQuery{HouseholdID: &householdID, From: "2026-01", To: "2026-06"})
+			return err
+		}},
+		{"lint", http.MethodGet, "/v1/budgets/5/lint", `{"budget":{},"issues":[]}`, http.StatusOK, func(c *Client) error { _, err := c.GetBudgetLint(context.Background(), 5); return err }},
+		{"create template", http.MethodPost, "/v1/budget-templates", `{"lines":[]}`, http.StatusCreated, func(c *Client) error {
+			_, err := c.CreateBudgetTemplate(context.Background(), api.CreateBudgetTemplateRequest{HouseholdID: &householdID, Name: "Standard"})
+			return err
+		}},
+		{"list templates", http.MethodGet, "/v1/budget-templates?householdId=3", `[]`, http.StatusOK, func(c *Client) error {
+			_, err := c.ListBudgetTemplates(context.Background(), api.BudgetTemplateQuery{HouseholdID: &householdID})
+			return err
+		}},
+		{"get template", http.MethodGet, "/v1/budget-templates/4", `{"lines":[]}`, http.StatusOK, func(c *Client) error { _, err := c.GetBudgetTemplate(context.Background(), 4); return err }},
+		{"update template", http.MethodPatch, "/v1/budget-templates/4", `{"lines":[]}`, http.StatusOK, func(c *Client) error {
+			_, err := c.UpdateBudgetTemplate(context.Background(), 4, api.UpdateBudgetTemplateRequest{})
+			return err
+		}},
+		{"delete template", http.MethodDelete, "/v1/budget-templates/4", ``, http.StatusNoContent, func(c *Client) error { return c.DeleteBudgetTemplate(context.Background(), 4) }},
+		{"save template", http.MethodPost, "/v1/budgets/5/save-template", `{"lines":[]}`, http.StatusCreated, func(c *Client) error {
+			_, err := c.SaveBudgetTemplate(context.Background(), 5, api.SaveBudgetTemplateRequest{Name: "Standard"})
+			return err
+		}},
+		{"apply template", http.MethodPost, "/v1/budgets/5/apply-template", `{"budget":{},"template":{},"changes":[]}`, http.StatusOK, func(c *Client) error {
+			_, err := c.ApplyBudgetTemplate(context.Background(), 5, api.ApplyBudgetTemplateRequest{TemplateID: &householdID})
+			return err
+		}},
+		{"close", http.MethodPost, "/v1/budgets/5/close", `{"lines":[],"totals":{}}`, http.StatusCreated, func(c *Client) error {
+			_, err := c.CloseBudget(context.Background(), 5, api.CloseBudgetRequest{UserID: 2})
+			return err
+		}},
+		{"reopen", http.MethodPost, "/v1/budgets/5/reopen", `{"lines":[],"totals":{},"reopenReason":"late refund"}`, http.StatusOK, func(c *Client) error {
+			_, err := c.ReopenBudget(context.Background(), 5, api.ReopenBudgetRequest{UserID: 2, Reason: "late refund"})
+			return err
+		}},
 		{"report", http.MethodGet, "/v1/budgets/5/report", `{"budget":{},"lines":[],"unmappedTransactions":[],"totals":{}}`, http.StatusOK, func(c *Client) error { _, err := c.GetBudgetReport(context.Background(), 5); return err }},
 	}
 	for _, test := range tests {
@@ -54,3 +107,29 @@ func TestBudgetMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestExportBudgetReportStreamsDocumentAndDecodesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.URL.RequestURI() == "/v1/budgets/404/report?format=pdf" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"budget_not_found","message":"budget not found"}}`))
+			return
+		}
+		if request.URL.RequestURI() != "/v1/budgets/5/report?format=xlsx" || request.Header.Get("Accept") != "*/*" {
+			t.Errorf("request = %s accept=%q", request.URL.RequestURI(), request.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		_, _ = w.Write([]byte("PK\x03\x04workbook"))
+	}))
+	defer server.Close()
+	client, _ := New(Config{BaseURL: server.URL, APIKey: "key"})
+	var document bytes.Buffer
+	if err := client.ExportBudgetReport(context.Background(), 5, api.BudgetReportFormatXLSX, &document); err != nil || document.String() != "PK\x03\x04workbook" {
+		t.Fatalf("document=%q error=%v", document.String(), err)
+	}
+	document.Reset()
+	var apiErr *APIError
+	if err := client.ExportBudgetReport(context.Background(), 404, api.BudgetReportFormatPDF, &document); !errors.As(err, &apiErr) || apiErr.Code != "budget_not_found" || document.Len() != 0 {
+		t.Fatalf("error=%v document=%q", err, document.String())
+	}
+}
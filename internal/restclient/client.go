@@ -4,12 +4,15 @@ package restclient
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,12 +26,20 @@ type Config struct {
 	APIKey     string
 	Timeout    time.Duration
 	HTTPClient *http.Client
+	// Retries is how many more times a POST, PUT, PATCH or DELETE is sent
+	// after the connection fails. Retries reuse the request's
+	// Idempotency-Key, so the server runs the request at most once.
+	Retries int
 }
 
 type Client struct {
 	baseURL *url.URL
 	apiKey  string
+	retries int
 	http    *http.Client
+	// streams serves long-lived responses such as the event stream, which
+	// the request timeout would cut off.
+	streams *http.Client
 }
 
 type APIError struct {
@@ -41,6 +52,37 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Message)
 }
 
+// VersionConflictError reports a 412 response: a conditional write named a
+// version the resource no longer has. Re-read the resource and retry with its
+// new version. It unwraps to the underlying *APIError.
+type VersionConflictError struct {
+	APIError
+}
+
+func (e *VersionConflictError) Unwrap() error { return &e.APIError }
+
+// RequestOption adjusts a single request, for example to make it conditional.
+type RequestOption func(*http.Request)
+
+// IfMatch makes a write apply only while the resource is still at version,
+// the value of its ETag or of its version field. A stale version fails with
+// *VersionConflictError.
+func IfMatch(version int64) RequestOption {
+	return func(request *http.Request) {
+		request.Header.Set("If-Match", `"`+strconv.FormatInt(version, 10)+`"`)
+	}
+}
+
+// IdempotencyKey sends a write with the given Idempotency-Key instead of a
+// generated one. Use it to retry a write across processes: a request repeated
+// with the same key and body returns the first response instead of running
+// again.
+func IdempotencyKey(key string) RequestOption {
+	return func(request *http.Request) {
+		request.Header.Set(api.IdempotencyKeyHeader, key)
+	}
+}
+
 type TransportError struct {
 	Operation string
 	Err       error
@@ -60,6 +102,9 @@ func New(config Config) (*Client, error) {
 	if config.Timeout < 0 {
 		return nil, errors.New("request timeout cannot be negative")
 	}
+	if config.Retries < 0 {
+		return nil, errors.New("retries cannot be negative")
+	}
 	timeout := config.Timeout
 	if timeout == 0 {
 		timeout = defaultTimeout
@@ -70,7 +115,9 @@ func New(config Config) (*Client, error) {
 		clone.Timeout = timeout
 		httpClient = &clone
 	}
-	return &Client{baseURL: baseURL, apiKey: config.APIKey, http: httpClient}, nil
+	streams := *httpClient
+	streams.Timeout = 0
+	return &Client{baseURL: baseURL, apiKey: config.APIKey, retries: config.Retries, http: httpClient, streams: &streams}, nil
 }
 
 func normalizeBaseURL(value string) (*url.URL, error) {
@@ -92,48 +139,135 @@ func normalizeBaseURL(value string) (*url.URL, error) {
 	return parsed, nil
 }
 
-func (c *Client) do(ctx context.Context, method, path string, query url.Values, input, output any) error {
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, input, output any, options ...RequestOption) error {
+	response, err := c.send(ctx, method, path, query, input, "application/json", options...)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if output == nil || response.StatusCode == http.StatusNoContent {
+		_, err := io.Copy(io.Discard, response.Body)
+		if err != nil {
+			return &TransportError{Operation: "read response", Err: err}
+		}
+		return nil
+	}
+	if err := decodeStrict(response.Body, output); err != nil {
+		return &TransportError{Operation: "decode response", Err: err}
+	}
+	return nil
+}
+
+// download copies a successful non-JSON response body, such as an exported
+// document, to w. Errors still arrive as JSON envelopes.
+func (c *Client) download(ctx context.Context, path string, query url.Values, w io.Writer) error {
+	response, err := c.send(ctx, http.MethodGet, path, query, nil, "*/*")
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if _, err := io.Copy(w, response.Body); err != nil {
+		return &TransportError{Operation: "read response", Err: err}
+	}
+	return nil
+}
+
+// send performs an authenticated request and turns non-2xx responses into
+// APIErrors. Writes carry an Idempotency-Key and are resent with it when the
+// connection fails. The caller closes the returned body.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, input any, accept string, options ...RequestOption) (*http.Response, error) {
+	if !isWrite(method) {
+		request, err := c.newRequest(ctx, method, path, query, input, accept, options...)
+		if err != nil {
+			return nil, err
+		}
+		return c.roundTrip(c.http, request)
+	}
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+	// The generated key goes first so an IdempotencyKey option replaces it.
+	options = append([]RequestOption{IdempotencyKey(key)}, options...)
+	for attempt := 0; ; attempt++ {
+		request, err := c.newRequest(ctx, method, path, query, input, accept, options...)
+		if err != nil {
+			return nil, err
+		}
+		response, err := c.roundTrip(c.http, request)
+		var transportErr *TransportError
+		if err == nil || attempt >= c.retries || ctx.Err() != nil || !errors.As(err, &transportErr) {
+			return response, err
+		}
+	}
+}
+
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func newIdempotencyKey() (string, error) {
+	var key [16]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return "", &TransportError{Operation: "generate idempotency key", Err: err}
+	}
+	return hex.EncodeToString(key[:]), nil
+}
+
+// encodedBody is a request body that is not JSON, such as a multipart
+// upload. It is encoded once so that retries send the same bytes.
+type encodedBody struct {
+	contentType string
+	data        []byte
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, input any, accept string, options ...RequestOption) (*http.Request, error) {
 	endpoint := *c.baseURL
 	endpoint.Path = strings.TrimRight(endpoint.Path, "/") + "/" + strings.TrimLeft(path, "/")
 	endpoint.RawQuery = query.Encode()
 
 	var body io.Reader
-	if input != nil {
+	contentType := "application/json"
+	switch input := input.(type) {
+	case nil:
+	case encodedBody:
+		body, contentType = bytes.NewReader(input.data), input.contentType
+	default:
 		encoded, err := json.Marshal(input)
 		if err != nil {
-			return &TransportError{Operation: "encode request", Err: err}
+			return nil, &TransportError{Operation: "encode request", Err: err}
 		}
 		body = bytes.NewReader(encoded)
 	}
 	request, err := http.NewRequestWithContext(ctx, method, endpoint.String(), body)
 	if err != nil {
-		return &TransportError{Operation: "create request", Err: err}
+		return nil, &TransportError{Operation: "create request", Err: err}
 	}
-	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Accept", accept)
 	request.Header.Set("Authorization", "Bearer "+c.apiKey)
 	if input != nil {
-		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Content-Type", contentType)
 	}
+	for _, option := range options {
+		option(request)
+	}
+	return request, nil
+}
 
-	response, err := c.http.Do(request)
+func (c *Client) roundTrip(client *http.Client, request *http.Request) (*http.Response, error) {
+	response, err := client.Do(request)
 	if err != nil {
-		return &TransportError{Operation: "send request", Err: err}
+		return nil, &TransportError{Operation: "send request", Err: err}
 	}
-	defer response.Body.Close()
 	if response.StatusCode < 200 || response.StatusCode >= 300 {
-		return decodeAPIError(response)
-	}
-	if output == nil || response.StatusCode == http.StatusNoContent {
-		_, err := io.Copy(io.Discard, response.Body)
-		if err != nil {
-			return &TransportError{Operation: "read response", Err: err}
-		}
-		return nil
+		defer response.Body.Close()
+		return nil, decodeAPIError(response)
 	}
-	if err := decodeStrict(response.Body, output); err != nil {
-		return &TransportError{Operation: "decode response", Err: err}
-	}
-	return nil
+	return response, nil
 }
 
 func decodeAPIError(response *http.Response) error {
@@ -144,7 +278,11 @@ func decodeAPIError(response *http.Response) error {
 	if envelope.Error.Code == "" || envelope.Error.Message == "" {
 		return &APIError{StatusCode: response.StatusCode, Code: "invalid_response", Message: "API returned an invalid error response"}
 	}
-	return &APIError{StatusCode: response.StatusCode, Code: envelope.Error.Code, Message: envelope.Error.Message}
+	apiErr := APIError{StatusCode: response.StatusCode, Code: envelope.Error.Code, Message: envelope.Error.Message}
+	if response.StatusCode == http.StatusPreconditionFailed {
+		return &VersionConflictError{APIError: apiErr}
+	}
+	return &apiErr
 }
 
 func decodeStrict(reader io.Reader, output any) error {
@@ -23,6 +23,7 @@ func TestNewValidatesAndNormalizesConfiguration(t *testing.T) {
 		"missing URL": {APIKey: "key"}, "invalid scheme": {BaseURL: "ftp://example.com", APIKey: "key"},
 		"credentials in URL": {BaseURL: "https://user@example.com", APIKey: "key"}, "missing key": {BaseURL: "https://example.com"},
 		"negative timeout": {BaseURL: "https://example.com", APIKey: "key", Timeout: -1},
+		"negative retries": {BaseURL: "https://example.com", APIKey: "key", Retries: -1},
 	} {
 		t.Run(name, func(t *testing.T) {
 			if _, err := New(config); err == nil {
@@ -73,6 +74,71 @@ func TestDoReturnsTypedAPIErrors(t *testing.T) {
 	}
 }
 
+func TestIfMatchSendsVersionAndConflictsAreTyped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("If-Match") != `"3"` {
+			t.Errorf("If-Match = %q", request.Header.Get("If-Match"))
+		}
+		w.WriteHeader(http.StatusPreconditionFailed)
+		_, _ = w.Write([]byte(`{"error":{"code":"version_mismatch","message":"category version mismatch"}}`))
+	}))
+	defer server.Close()
+	client, _ := New(Config{BaseURL: server.URL, APIKey: "secret"})
+	_, err := client.DeactivateCategory(context.Background(), "food", IfMatch(3))
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) || conflict.Code != "version_mismatch" {
+		t.Fatalf("error = %#v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("conflict does not unwrap to APIError: %#v", err)
+	}
+}
+
+func TestWritesRetryTransportFailuresWithOneIdempotencyKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		keys = append(keys, request.Header.Get("Idempotency-Key"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client, _ := New(Config{BaseURL: server.URL, APIKey: "secret", Retries: 2})
+	transport := http.DefaultTransport
+	failures := 2
+	client.http.Transport = roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		if failures > 0 {
+			failures--
+			keys = append(keys, request.Header.Get("Idempotency-Key"))
+			return nil, errors.New("connection reset")
+		}
+		return transport.RoundTrip(request)
+	})
+	if err := client.do(context.Background(), http.MethodPost, "/v1/test", nil, map[string]int{"amount": 1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 || keys[0] == "" || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Fatalf("keys = %q", keys)
+	}
+
+	keys = nil
+	if err := client.do(context.Background(), http.MethodDelete, "/v1/test", nil, nil, nil, IdempotencyKey("chosen")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.do(context.Background(), http.MethodGet, "/v1/test", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != "chosen" || keys[1] != "" {
+		t.Fatalf("keys = %q", keys)
+	}
+
+	failures = 3
+	err := client.do(context.Background(), http.MethodPost, "/v1/test", nil, nil, nil)
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) || failures != 0 {
+		t.Fatalf("error = %#v failures left = %d", err, failures)
+	}
+}
+
 func TestDoReturnsTypedTransportAndStrictResponseErrors(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(`{"ok":true,"unknown":1}`)) }))
 	defer server.Close()
@@ -0,0 +1,94 @@
+package restclient
+
+import (
+	"bufio"
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+// defaultEventRetry is how long Events waits before reconnecting when the
+// server has not sent a retry interval.
+const defaultEventRetry = time.Second
+
+// Events follows GET /v1/events and yields each event as it arrives. When the
+// server ends the stream, as it does for clients that fall behind, Events
+// reconnects from the last event it yielded, so a stream.reset event is the
+// only sign of a gap. Iteration ends when ctx is done and stops after
+// yielding the first error.
+func (c *Client) Events(ctx context.Context, input api.EventsQuery) iter.Seq2[api.Event, error] {
+	return func(yield func(api.Event, error) bool) {
+		retry := defaultEventRetry
+		for {
+			more, err := c.readEvents(ctx, &input, &retry, yield)
+			if !more || ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				yield(api.Event{}, err)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retry):
+			}
+		}
+	}
+}
+
+// readEvents reads one connection's events, advancing input.LastEventID past
+// each one yielded. It reports false once the consumer stops iterating.
+func (c *Client) readEvents(ctx context.Context, input *api.EventsQuery, retry *time.Duration, yield func(api.Event, error) bool) (bool, error) {
+	query := url.Values{}
+	setInt64(query, "householdId", input.HouseholdID)
+	request, err := c.newRequest(ctx, http.MethodGet, api.EventsPath, query, nil, "text/event-stream")
+	if err != nil {
+		return true, err
+	}
+	if input.LastEventID != "" {
+		request.Header.Set("Last-Event-ID", input.LastEventID)
+	}
+	response, err := c.roundTrip(c.streams, request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	var data []string
+	lines := bufio.NewScanner(response.Body)
+	for lines.Scan() {
+		field, value, _ := strings.Cut(lines.Text(), ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "":
+			if lines.Text() != "" || len(data) == 0 {
+				continue
+			}
+			var event api.Event
+			if err := decodeStrict(strings.NewReader(strings.Join(data, "\n")), &event); err != nil {
+				return true, &TransportError{Operation: "decode event", Err: err}
+			}
+			data = data[:0]
+			input.LastEventID = event.ID
+			if !yield(event, nil) {
+				return false, nil
+			}
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if milliseconds, err := strconv.Atoi(value); err == nil && milliseconds >= 0 {
+				*retry = time.Duration(milliseconds) * time.Millisecond
+			}
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return true, &TransportError{Operation: "read event stream", Err: err}
+	}
+	return true, nil
+}
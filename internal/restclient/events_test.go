@@ -0,0 +1,80 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+func TestEventsResumesAfterTheStreamEnds(t *testing.T) {
+	var resumedFrom []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.URL.RequestURI() != "/v1/events?householdId=2" || request.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("request=%s accept=%s", request.URL.RequestURI(), request.Header.Get("Accept"))
+		}
+		resumedFrom = append(resumedFrom, request.Header.Get("Last-Event-ID"))
+		switch len(resumedFrom) {
+		case 1:
+			fmt.Fprint(w, "retry: 1\n\n: heartbeat\n\n")
+			w.(http.Flusher).Flush()
+			// Outlasts the client's request timeout, which streams ignore.
+			time.Sleep(100 * time.Millisecond)
+			fmt.Fprint(w, "id: 5\nevent: transaction.created\ndata: {\"id\":\"5\",\"type\":\"transaction.created\",\"occurredAt\":\"2026-06-01T00:00:00Z\",\"transactionId\":9}\n\n")
+		case 2:
+			fmt.Fprint(w, "id: 8\nevent: stream.reset\ndata: {\"id\":\"8\",\"type\":\"stream.reset\",\n")
+			fmt.Fprint(w, "data: \"occurredAt\":\"2026-06-01T00:00:00Z\"}\n\n")
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"code":"unavailable","message":"shutting down"}}`)
+		}
+	}))
+	defer server.Close()
+	client, err := New(Config{BaseURL: server.URL, APIKey: "key", Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	householdID := int64(2)
+	var received []api.Event
+	var streamErr error
+	for event, err := range client.Events(context.Background(), api.EventsQuery{HouseholdID: &householdID, LastEventID: "3"}) {
+		if err != nil {
+			streamErr = err
+			break
+		}
+		received = append(received, event)
+	}
+	if len(received) != 2 || received[0].TransactionID == nil || *received[0].TransactionID != 9 || received[1].Type != api.EventStreamReset {
+		t.Fatalf("received=%+v", received)
+	}
+	if fmt.Sprint(resumedFrom) != "[3 5 8]" {
+		t.Fatalf("Last-Event-ID per connection=%v", resumedFrom)
+	}
+	var apiErr *APIError
+	if !errors.As(streamErr, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("error=%v", streamErr)
+	}
+}
+
+func TestEventsEndsQuietlyWhenTheContextIsDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		w.(http.Flusher).Flush()
+		<-request.Context().Done()
+	}))
+	defer server.Close()
+	client, err := New(Config{BaseURL: server.URL, APIKey: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	for event, err := range client.Events(ctx, api.EventsQuery{}) {
+		t.Fatalf("event=%+v error=%v", event, err)
+	}
+}
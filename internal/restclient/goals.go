@@ -0,0 +1,47 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+func (c *Client) CreateGoal(ctx context.Context, request api.CreateGoalRequest) (api.Goal, error) {
+	var response api.Goal
+	err := c.do(ctx, http.MethodPost, api.GoalsPath, nil, request, &response)
+	return response, err
+}
+
+func (c *Client) ListGoals(ctx context.Context, input api.GoalQuery) ([]api.Goal, error) {
+	var response []api.Goal
+	err := c.do(ctx, http.MethodGet, api.GoalsPath, goalQuery(input), nil, &response)
+	return response, err
+}
+
+func (c *Client) GetGoal(ctx context.Context, id int64, asOf string) (api.Goal, error) {
+	var response api.Goal
+	err := c.do(ctx, http.MethodGet, replace(api.GoalPath, "{id}", id), goalQuery(api.GoalQuery{AsOf: asOf}), nil, &response)
+	return response, err
+}
+
+func (c *Client) UpdateGoal(ctx context.Context, id int64, request api.UpdateGoalRequest) (api.Goal, error) {
+	var response api.Goal
+	err := c.do(ctx, http.MethodPatch, replace(api.GoalPath, "{id}", id), nil, request, &response)
+	return response, err
+}
+
+func (c *Client) DeleteGoal(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, replace(api.GoalPath, "{id}", id), nil, nil, nil)
+}
+
+func goalQuery(input api.GoalQuery) url.Values {
+	query := url.Values{}
+	setInt64(query, "householdId", input.HouseholdID)
+	setInt64(query, "userId", input.UserID)
+	if input.AsOf != "" {
+		query.Set("asOf", input.AsOf)
+	}
+	return query
+}
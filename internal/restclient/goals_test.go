@@ -0,0 +1,52 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+func TestGoalMethods(t *testing.T) {
+	householdID := int64(3)
+	tests := []struct {
+		name, method, path, response string
+		status                       int
+		call                         func(*Client) error
+	}{
+		{"create", http.MethodPost, "/v1/goals", `{}`, http.StatusCreated, func(c *Client) error {
+			_, err := c.CreateGoal(context.Background(), api.CreateGoalRequest{HouseholdID: &householdID, Name: "Holidays"})
+			return err
+		}},
+		{"list", http.MethodGet, "/v1/goals?asOf=2026-10-31&householdId=3", `[]`, http.StatusOK, func(c *Client) error {
+			_, err := c.ListGoals(context.Background(), api.GoalQuery{HouseholdID: &householdID, AsOf: "2026-10-31"})
+			return err
+		}},
+		{"get", http.MethodGet, "/v1/goals/4", `{}`, http.StatusOK, func(c *Client) error { _, err := c.GetGoal(context.Background(), 4, ""); return err }},
+		{"update", http.MethodPatch, "/v1/goals/4", `{}`, http.StatusOK, func(c *Client) error {
+			_, err := c.UpdateGoal(context.Background(), 4, api.UpdateGoalRequest{})
+			return err
+		}},
+		{"delete", http.MethodDelete, "/v1/goals/4", ``, http.StatusNoContent, func(c *Client) error { return c.DeleteGoal(context.Background(), 4) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				if request.Method != test.method || request.URL.RequestURI() != test.path {
+					t.Errorf("request = %s %s", request.Method, request.URL.RequestURI())
+				}
+				w.WriteHeader(test.status)
+				if test.response != "" {
+					_, _ = w.Write([]byte(test.response))
+				}
+			}))
+			defer server.Close()
+			client, _ := New(Config{BaseURL: server.URL, APIKey: "key"})
+			if err := test.call(client); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -82,13 +82,13 @@ func (c *Client) GetCategory(ctx context.Context, code string) (api.Category, er
 	err := c.do(ctx, http.MethodGet, strings.Replace(api.CategoryPath, "{code}", url.PathEscape(code), 1), nil, nil, &response)
 	return response, err
 }
-func (c *Client) UpdateCategory(ctx context.Context, code string, request api.UpdateCategoryRequest) (api.Category, error) {
+func (c *Client) UpdateCategory(ctx context.Context, code string, request api.UpdateCategoryRequest, options ...RequestOption) (api.Category, error) {
 	var response api.Category
-	err := c.do(ctx, http.MethodPatch, strings.Replace(api.CategoryPath, "{code}", url.PathEscape(code), 1), nil, request, &response)
+	err := c.do(ctx, http.MethodPatch, strings.Replace(api.CategoryPath, "{code}", url.PathEscape(code), 1), nil, request, &response, options...)
 	return response, err
 }
-func (c *Client) DeactivateCategory(ctx context.Context, code string) (api.Category, error) {
+func (c *Client) DeactivateCategory(ctx context.Context, code string, options ...RequestOption) (api.Category, error) {
 	var response api.Category
-	err := c.do(ctx, http.MethodDelete, strings.Replace(api.CategoryPath, "{code}", url.PathEscape(code), 1), nil, nil, &response)
+	err := c.do(ctx, http.MethodDelete, strings.Replace(api.CategoryPath, "{code}", url.PathEscape(code), 1), nil, nil, &response, options...)
 	return response, err
 }
@@ -2,6 +2,7 @@ package restclient
 
 import (
 	"context"
+	"iter"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -33,7 +34,9 @@ func (c *Client) GetTransaction(ctx context.Context, id int64, input api.GetTran
 	return response, err
 }
 
-func (c *Client) ListTransactions(ctx context.Context, input api.ListTransactionsQuery) ([]api.Transaction, error) {
+// ListTransactions fetches one page. Pass its NextCursor as input.Cursor for
+// the next page, or use AllTransactions to walk them all.
+func (c *Client) ListTransactions(ctx context.Context, input api.ListTransactionsQuery) (api.TransactionPage, error) {
 	query := url.Values{}
 	for _, id := range input.IDs {
 		query.Add("ids", strconv.FormatInt(id, 10))
@@ -45,6 +48,13 @@ func (c *Client) ListTransactions(ctx context.Context, input api.ListTransaction
 	if input.Search != nil {
 		query.Set("search", *input.Search)
 	}
+	for _, tag := range input.Tags {
+		query.Add("tag", tag)
+	}
+	if input.TagMatch != "" {
+		query.Set("tagMatch", input.TagMatch)
+	}
+	setFilter(query, input.Query, input.Filter)
 	if input.Sort != "" {
 		query.Set("sort", input.Sort)
 	}
@@ -57,20 +67,134 @@ func (c *Client) ListTransactions(ctx context.Context, input api.ListTransaction
 	if input.Offset != 0 {
 		query.Set("offset", strconv.FormatInt(int64(input.Offset), 10))
 	}
+	if input.Cursor != "" {
+		query.Set("cursor", input.Cursor)
+	}
 	if input.IncludeDeleted {
 		query.Set("includeDeleted", "true")
 	}
 	if input.OnlyDeleted {
 		query.Set("onlyDeleted", "true")
 	}
-	var response []api.Transaction
+	var response api.TransactionPage
 	err := c.do(ctx, http.MethodGet, api.TransactionsPath, query, nil, &response)
 	return response, err
 }
 
-func (c *Client) UpdateTransaction(ctx context.Context, id int64, request api.UpdateTransactionRequest) (api.Transaction, error) {
+// AllTransactions yields every transaction matching input, following each
+// page's cursor from input.Cursor on. input.Limit sets the page size.
+// Iteration stops after yielding the first error.
+func (c *Client) AllTransactions(ctx context.Context, input api.ListTransactionsQuery) iter.Seq2[api.Transaction, error] {
+	return func(yield func(api.Transaction, error) bool) {
+		for {
+			page, err := c.ListTransactions(ctx, input)
+			if err != nil {
+				yield(api.Transaction{}, err)
+				return
+			}
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			input.Cursor = page.NextCursor
+		}
+	}
+}
+
+// TransactionChanges fetches the transactions changed after input.Since. Keep
+// the returned NextToken and pass it as Since on the next sync.
+func (c *Client) TransactionChanges(ctx context.Context, input api.TransactionChangesQuery) (api.TransactionChanges, error) {
+	query := url.Values{}
+	if input.Since != "" {
+		query.Set("since", input.Since)
+	}
+	if input.Limit != 0 {
+		query.Set("limit", strconv.FormatInt(int64(input.Limit), 10))
+	}
+	var response api.TransactionChanges
+	err := c.do(ctx, http.MethodGet, api.TransactionChangesPath, query, nil, &response)
+	return response, err
+}
+
+// TransactionTagTotals counts and sums the transactions carrying each tag.
+func (c *Client) TransactionTagTotals(ctx context.Context, input api.TagTotalsQuery) ([]api.TagTotal, error) {
+	query := url.Values{}
+	setInt64(query, "authorId", input.AuthorID)
+	setInt64(query, "householdId", input.HouseholdID)
+	setTime(query, "fromDate", input.FromDate)
+	setTime(query, "toDate", input.ToDate)
+	setFilter(query, input.Query, input.Filter)
+	var response []api.TagTotal
+	err := c.do(ctx, http.MethodGet, api.TransactionTagsPath, query, nil, &response)
+	return response, err
+}
+
+// AggregateTransactions groups the transactions matching input by its
+// dimensions and summarizes each group.
+func (c *Client) AggregateTransactions(ctx context.Context, input api.AggregateTransactionsQuery) (api.TransactionAggregate, error) {
+	query := url.Values{}
+	setInt64(query, "authorId", input.AuthorID)
+	setInt64(query, "householdId", input.HouseholdID)
+	setTime(query, "fromDate", input.FromDate)
+	setTime(query, "toDate", input.ToDate)
+	if input.Search != nil {
+		query.Set("search", *input.Search)
+	}
+	for _, tag := range input.Tags {
+		query.Add("tag", tag)
+	}
+	if input.TagMatch != "" {
+		query.Set("tagMatch", input.TagMatch)
+	}
+	setFilter(query, input.Query, input.Filter)
+	if input.IncludeDeleted {
+		query.Set("includeDeleted", "true")
+	}
+	if input.OnlyDeleted {
+		query.Set("onlyDeleted", "true")
+	}
+	if len(input.By) > 0 {
+		query.Set("by", strings.Join(input.By, ","))
+	}
+	if len(input.Metrics) > 0 {
+		query.Set("metrics", strings.Join(input.Metrics, ","))
+	}
+	var response api.TransactionAggregate
+	err := c.do(ctx, http.MethodGet, api.TransactionAggregatePath, query, nil, &response)
+	return response, err
+}
+
+func (c *Client) ListSavedFilters(ctx context.Context) ([]api.SavedFilter, error) {
+	var response []api.SavedFilter
+	err := c.do(ctx, http.MethodGet, api.SavedFiltersPath, nil, nil, &response)
+	return response, err
+}
+
+// SaveFilter creates the named filter or replaces its query.
+func (c *Client) SaveFilter(ctx context.Context, name string, request api.SaveFilterRequest) (api.SavedFilter, error) {
+	var response api.SavedFilter
+	err := c.do(ctx, http.MethodPut, strings.Replace(api.SavedFilterPath, "{name}", url.PathEscape(name), 1), nil, request, &response)
+	return response, err
+}
+
+func (c *Client) DeleteSavedFilter(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, strings.Replace(api.SavedFilterPath, "{name}", url.PathEscape(name), 1), nil, nil, nil)
+}
+
+func (c *Client) UpdateTransaction(ctx context.Context, id int64, request api.UpdateTransactionRequest, options ...RequestOption) (api.Transaction, error) {
 	var response api.Transaction
-	err := c.do(ctx, http.MethodPatch, replace(api.TransactionPath, "{id}", id), nil, request, &response)
+	err := c.do(ctx, http.MethodPatch, replace(api.TransactionPath, "{id}", id), nil, request, &response, options...)
+	return response, err
+}
+
+// TransactionHistory lists the transaction's recorded changes, oldest first.
+func (c *Client) TransactionHistory(ctx context.Context, id int64) ([]api.TransactionHistoryEntry, error) {
+	var response []api.TransactionHistoryEntry
+	err := c.do(ctx, http.MethodGet, replace(api.TransactionHistoryPath, "{id}", id), nil, nil, &response)
 	return response, err
 }
 
@@ -100,6 +224,14 @@ func setInt64(query url.Values, name string, value *int64) {
 		query.Set(name, strconv.FormatInt(*value, 10))
 	}
 }
+func setFilter(query url.Values, expression, filter string) {
+	if expression != "" {
+		query.Set("q", expression)
+	}
+	if filter != "" {
+		query.Set("filter", filter)
+	}
+}
 func setTime(query url.Values, name string, value *time.Time) {
 	if value != nil {
 		query.Set(name, value.Format(time.RFC3339))
@@ -31,14 +31,37 @@ func TestTransactionMethodsAndQueries(t *testing.T) {
 			_, err := c.GetTransaction(context.Background(), 4, api.GetTransactionQuery{IncludeDeleted: true})
 			return err
 		}},
-		{"list", http.MethodGet, "/v1/transactions?authorId=8&fromDate=2026-07-01T02%3A03%3A04Z&ids=1&ids=2&includeDeleted=true&limit=25&offset=3&search=food&sort=amount&sortOrder=asc", func(c *Client) error {
-			_, err := c.ListTransactions(context.Background(), api.ListTransactionsQuery{IDs: []int64{1, 2}, AuthorID: &authorID, FromDate: &from, Search: &search, Sort: "amount", SortOrder: "asc", Limit: 25, Offset: 3, IncludeDeleted: true})
+		{"list", http.MethodGet, "/v1/transactions?authorId=8&cursor=abc&filter=big-spend&fromDate=2026-07-01T02%3A03%3A04Z&ids=1&ids=2&includeDeleted=true&limit=25&offset=3&q=amount+%3E+50&search=food&sort=amount&sortOrder=asc&tag=reimbursable&tag=travel&tagMatch=all", func(c *Client) error {
+			_, err := c.ListTransactions(context.Background(), api.ListTransactionsQuery{IDs: []int64{1, 2}, AuthorID: &authorID, FromDate: &from, Search: &search, Tags: []string{"reimbursable", "travel"}, TagMatch: "all", Query: "amount > 50", Filter: "big-spend", Sort: "amount", SortOrder: "asc", Limit: 25, Offset: 3, Cursor: "abc", IncludeDeleted: true})
 			return err
 		}},
+		{"tag totals", http.MethodGet, "/v1/transactions/tags?authorId=8&fromDate=2026-07-01T02%3A03%3A04Z&q=tag+%3D+travel", func(c *Client) error {
+			_, err := c.TransactionTagTotals(context.Background(), api.TagTotalsQuery{AuthorID: &authorID, FromDate: &from, Query: "tag = travel"})
+			return err
+		}},
+		{"aggregate", http.MethodGet, "/v1/transactions/aggregate?authorId=8&by=category%2Cmonth&metrics=sum%2Cavg&search=food&tag=travel", func(c *Client) error {
+			_, err := c.AggregateTransactions(context.Background(), api.AggregateTransactionsQuery{AuthorID: &authorID, Search: &search, Tags: []string{"travel"}, By: []string{"category", "month"}, Metrics: []string{"sum", "avg"}})
+			return err
+		}},
+		{"saved filters", http.MethodGet, "/v1/saved-filters", func(c *Client) error {
+			_, err := c.ListSavedFilters(context.Background())
+			return err
+		}},
+		{"save filter", http.MethodPut, "/v1/saved-filters/big-spend", func(c *Client) error {
+			_, err := c.SaveFilter(context.Background(), "big-spend", api.SaveFilterRequest{Query: "amount > 100"})
+			return err
+		}},
+		{"delete saved filter", http.MethodDelete, "/v1/saved-filters/big-spend", func(c *Client) error {
+			return c.DeleteSavedFilter(context.Background(), "big-spend")
+		}},
 		{"update", http.MethodPatch, "/v1/transactions/4", func(c *Client) error {
 			_, err := c.UpdateTransaction(context.Background(), 4, api.UpdateTransactionRequest{})
 			return err
 		}},
+		{"history", http.MethodGet, "/v1/transactions/4/history", func(c *Client) error {
+			_, err := c.TransactionHistory(context.Background(), 4)
+			return err
+		}},
 		{"bulk update", http.MethodPatch, "/v1/transactions/bulk", func(c *Client) error {
 			_, err := c.UpdateTransactions(context.Background(), api.BulkUpdateTransactionsRequest{})
 			return err
@@ -63,6 +86,18 @@ func TestTransactionMethodsAndQueries(t *testing.T) {
 					return
 				}
 				if test.name == "list" {
+					_, _ = w.Write([]byte(`{"items":[]}`))
+					return
+				}
+				if test.name == "aggregate" {
+					_, _ = w.Write([]byte(`{"by":["category","month"],"metrics":["sum","avg"],"groups":[]}`))
+					return
+				}
+				if test.name == "save filter" {
+					_, _ = w.Write([]byte(`{"name":"big-spend","query":"amount > 100"}`))
+					return
+				}
+				if test.name == "history" || test.name == "tag totals" || test.name == "saved filters" {
 					_, _ = w.Write([]byte(`[]`))
 					return
 				}
@@ -76,3 +111,39 @@ func TestTransactionMethodsAndQueries(t *testing.T) {
 		})
 	}
 }
+
+func TestAllTransactionsFollowsCursorsUntilTheLastPage(t *testing.T) {
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		cursor := request.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		switch cursor {
+		case "":
+			_, _ = w.Write([]byte(`{"items":[{"id":1},{"id":2}],"nextCursor":"c2"}`))
+		case "c2":
+			_, _ = w.Write([]byte(`{"items":[{"id":3}]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":"validation_error","message":"cursor is invalid"}}`))
+		}
+	}))
+	defer server.Close()
+	client, _ := New(Config{BaseURL: server.URL, APIKey: "key"})
+	var ids []int64
+	for item, err := range client.AllTransactions(context.Background(), api.ListTransactionsQuery{Limit: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, item.ID)
+	}
+	if len(ids) != 3 || ids[2] != 3 || len(cursors) != 2 || cursors[1] != "c2" {
+		t.Fatalf("ids=%v cursors=%q", ids, cursors)
+	}
+	var stale error
+	for _, err := range client.AllTransactions(context.Background(), api.ListTransactionsQuery{Cursor: "stale"}) {
+		stale = err
+	}
+	if stale == nil {
+		t.Fatal("stale cursor error was not yielded")
+	}
+}
@@ -0,0 +1,56 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+func (c *Client) CreateWebhook(ctx context.Context, request api.CreateWebhookRequest) (api.Webhook, error) {
+	var response api.Webhook
+	err := c.do(ctx, http.MethodPost, api.WebhooksPath, nil, request, &response)
+	return response, err
+}
+
+func (c *Client) ListWebhooks(ctx context.Context, input api.WebhookQuery) ([]api.Webhook, error) {
+	query := url.Values{}
+	setInt64(query, "householdId", input.HouseholdID)
+	var response []api.Webhook
+	err := c.do(ctx, http.MethodGet, api.WebhooksPath, query, nil, &response)
+	return response, err
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id int64) (api.Webhook, error) {
+	var response api.Webhook
+	err := c.do(ctx, http.MethodGet, replace(api.WebhookPath, "{id}", id), nil, nil, &response)
+	return response, err
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id int64, request api.UpdateWebhookRequest) (api.Webhook, error) {
+	var response api.Webhook
+	err := c.do(ctx, http.MethodPatch, replace(api.WebhookPath, "{id}", id), nil, request, &response)
+	return response, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, replace(api.WebhookPath, "{id}", id), nil, nil, nil)
+}
+
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id int64, input api.WebhookDeliveryQuery) ([]api.WebhookDelivery, error) {
+	query := url.Values{}
+	if input.Limit != 0 {
+		query.Set("limit", strconv.Itoa(input.Limit))
+	}
+	var response []api.WebhookDelivery
+	err := c.do(ctx, http.MethodGet, replace(api.WebhookDeliveriesPath, "{id}", id), query, nil, &response)
+	return response, err
+}
+
+func (c *Client) RedeliverWebhookDelivery(ctx context.Context, id int64) (api.WebhookDelivery, error) {
+	var response api.WebhookDelivery
+	err := c.do(ctx, http.MethodPost, replace(api.WebhookDeliveryRedeliverPath, "{id}", id), nil, nil, &response)
+	return response, err
+}
@@ -0,0 +1,60 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rdmm404/voltr-finance/internal/api"
+)
+
+func TestWebhookMethods(t *testing.T) {
+	householdID := int64(3)
+	tests := []struct {
+		name, method, path, response string
+		status                       int
+		call                         func(*Client) error
+	}{
+		{"create", http.MethodPost, "/v1/webhooks", `{}`, http.StatusCreated, func(c *Client) error {
+			_, err := c.CreateWebhook(context.Background(), api.CreateWebhookRequest{URL: "https://hooks.example.com", HouseholdID: &householdID})
+			return err
+		}},
+		{"list", http.MethodGet, "/v1/webhooks?householdId=3", `[]`, http.StatusOK, func(c *Client) error {
+			_, err := c.ListWebhooks(context.Background(), api.WebhookQuery{HouseholdID: &householdID})
+			return err
+		}},
+		{"get", http.MethodGet, "/v1/webhooks/5", `{}`, http.StatusOK, func(c *Client) error { _, err := c.GetWebhook(context.Background(), 5); return err }},
+		{"update", http.MethodPatch, "/v1/webhooks/5", `{}`, http.StatusOK, func(c *Client) error {
+			_, err := c.UpdateWebhook(context.Background(), 5, api.UpdateWebhookRequest{})
+			return err
+		}},
+		{"delete", http.MethodDelete, "/v1/webhooks/5", ``, http.StatusNoContent, func(c *Client) error { return c.DeleteWebhook(context.Background(), 5) }},
+		{"deliveries", http.MethodGet, "/v1/webhooks/5/deliveries?limit=10", `[]`, http.StatusOK, func(c *Client) error {
+			_, err := c.ListWebhookDeliveries(context.Background(), 5, api.WebhookDeliveryQuery{Limit: 10})
+			return err
+		}},
+		{"redeliver", http.MethodPost, "/v1/webhook-deliveries/11/redeliver", `{}`, http.StatusAccepted, func(c *Client) error {
+			_, err := c.RedeliverWebhookDelivery(context.Background(), 11)
+			return err
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				if request.Method != test.method || request.URL.RequestURI() != test.path {
+					t.Errorf("request = %s %s", request.Method, request.URL.RequestURI())
+				}
+				w.WriteHeader(test.status)
+				if test.response != "" {
+					_, _ = w.Write([]byte(test.response))
+				}
+			}))
+			defer server.Close()
+			client, _ := New(Config{BaseURL: server.URL, APIKey: "key"})
+			if err := test.call(client); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
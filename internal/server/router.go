@@ -7,15 +7,21 @@ import (
 	"strings"
 
 	"rdmm404/voltr-finance/internal/httpapi"
+	alerthttp "rdmm404/voltr-finance/internal/httpapi/alerts"
+	attachmenthttp "rdmm404/voltr-finance/internal/httpapi/attachments"
 	budgethttp "rdmm404/voltr-finance/internal/httpapi/budgets"
 	categoryhttp "rdmm404/voltr-finance/internal/httpapi/categories"
+	eventhttp "rdmm404/voltr-finance/internal/httpapi/events"
+	goalhttp "rdmm404/voltr-finance/internal/httpapi/goals"
 	householdhttp "rdmm404/voltr-finance/internal/httpapi/households"
 	transactionhttp "rdmm404/voltr-finance/internal/httpapi/transactions"
 	userhttp "rdmm404/voltr-finance/internal/httpapi/users"
+	webhookhttp "rdmm404/voltr-finance/internal/httpapi/webhooks"
 	"rdmm404/voltr-finance/internal/webui"
 )
 
-// New wires feature handlers into the shared authenticated HTTP server.
+// New wires feature handlers into the shared authenticated HTTP server. A nil
+// idempotency store disables Idempotency-Key handling.
 func New(
 	config httpapi.Config,
 	uiConfig webui.Config,
@@ -27,15 +33,19 @@ func New(
 		budgethttp.Service
 		webui.BudgetReader
 	},
+	goalService goalhttp.Service,
+	alertService alerthttp.Service,
+	eventService eventhttp.Service,
+	webhookService webhookhttp.Service,
+	attachmentService attachmenthttp.Service,
+	idempotency httpapi.IdempotencyStore,
 ) (*http.Server, error) {
 	support := httpapi.NewHandlerSupport(slog.Default())
-	apiServer, err := httpapi.NewServer(config, func(router *httpapi.Router) {
-		transactionhttp.New(transactionService, support).Register(router)
-		userhttp.New(userService, support).Register(router)
-		householdhttp.New(householdService, support).Register(router)
-		categoryhttp.New(categoryService, support).Register(router)
-		budgethttp.New(budgetService, support).Register(router)
-	})
+	var middleware []httpapi.Middleware
+	if idempotency != nil {
+		middleware = append(middleware, httpapi.Idempotency(idempotency, slog.Default()))
+	}
+	apiServer, err := httpapi.NewServer(config, registerAPI(support, transactionService, userService, householdService, categoryService, budgetService, goalService, alertService, eventService, webhookService, attachmentService), middleware...)
 	if err != nil {
 		return nil, err
 	}
@@ -56,3 +66,32 @@ func New(
 	})
 	return apiServer, nil
 }
+
+// registerAPI registers every feature's /v1 routes. Each must be documented in
+// api.Routes.
+func registerAPI(
+	support *httpapi.HandlerSupport,
+	transactionService transactionhttp.Service,
+	userService userhttp.Service,
+	householdService householdhttp.Service,
+	categoryService categoryhttp.Service,
+	budgetService budgethttp.Service,
+	goalService goalhttp.Service,
+	alertService alerthttp.Service,
+	eventService eventhttp.Service,
+	webhookService webhookhttp.Service,
+	attachmentService attachmenthttp.Service,
+) httpapi.RegisterRoutes {
+	return func(router *httpapi.Router) {
+		transactionhttp.New(transactionService, support).Register(router)
+		userhttp.New(userService, support).Register(router)
+		householdhttp.New(householdService, support).Register(router)
+		categoryhttp.New(categoryService, support).Register(router)
+		budgethttp.New(budgetService, support).Register(router)
+		goalhttp.New(goalService, support).Register(router)
+		alerthttp.New(alertService, support).Register(router)
+		eventhttp.New(eventService, support).Register(router)
+		webhookhttp.New(webhookService, support).Register(router)
+		attachmenthttp.New(attachmentService, support).Register(router)
+	}
+}
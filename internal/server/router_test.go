@@ -2,15 +2,25 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"rdmm404/voltr-finance/internal/api"
+	appalerts "rdmm404/voltr-finance/internal/app/alerts"
+	appattachments "rdmm404/voltr-finance/internal/app/attachments"
 	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
 	appcategories "rdmm404/voltr-finance/internal/app/categories"
+	appevents "rdmm404/voltr-finance/internal/app/events"
+	appgoals "rdmm404/voltr-finance/internal/app/goals"
 	apphouseholds "rdmm404/voltr-finance/internal/app/households"
 	apptransactions "rdmm404/voltr-finance/internal/app/transactions"
 	appusers "rdmm404/voltr-finance/internal/app/users"
+	appwebhooks "rdmm404/voltr-finance/internal/app/webhooks"
 	"rdmm404/voltr-finance/internal/httpapi"
 	"rdmm404/voltr-finance/internal/webui"
 )
@@ -29,9 +39,21 @@ func (transactionServiceStub) Get(context.Context, int64, bool) (apptransactions
 func (transactionServiceStub) GetMany(context.Context, []int64, bool) ([]apptransactions.Transaction, error) {
 	panic("unexpected GetMany")
 }
-func (s transactionServiceStub) List(context.Context, apptransactions.ListFilter) ([]apptransactions.Transaction, error) {
+func (s transactionServiceStub) List(context.Context, apptransactions.ListFilter) (apptransactions.Page, error) {
 	(*s.calls)++
-	return []apptransactions.Transaction{}, nil
+	return apptransactions.Page{Items: []apptransactions.Transaction{}}, nil
+}
+func (transactionServiceStub) Changes(context.Context, apptransactions.ChangesFilter) (apptransactions.ChangeSet, error) {
+	panic("unexpected Changes")
+}
+func (transactionServiceStub) TagTotals(context.Context, apptransactions.TagTotalsFilter) ([]apptransactions.TagTotal, error) {
+	panic("unexpected TagTotals")
+}
+func (transactionServiceStub) Aggregate(context.Context, apptransactions.AggregateFilter) (apptransactions.AggregateResult, error) {
+	panic("unexpected Aggregate")
+}
+func (transactionServiceStub) History(context.Context, int64) ([]apptransactions.HistoryEntry, error) {
+	panic("unexpected History")
 }
 func (transactionServiceStub) Update(context.Context, apptransactions.UpdateInput) (apptransactions.Transaction, error) {
 	panic("unexpected Update")
@@ -39,12 +61,21 @@ func (transactionServiceStub) Update(context.Context, apptransactions.UpdateInpu
 func (transactionServiceStub) UpdateBatch(context.Context, []apptransactions.UpdateInput) apptransactions.BulkResult {
 	panic("unexpected UpdateBatch")
 }
-func (transactionServiceStub) DeleteBatch(context.Context, []int64, int64, *string) apptransactions.BulkResult {
+func (transactionServiceStub) DeleteBatch(context.Context, []apptransactions.DeleteInput) apptransactions.BulkResult {
 	panic("unexpected DeleteBatch")
 }
 func (transactionServiceStub) RestoreBatch(context.Context, []int64, int64) apptransactions.BulkResult {
 	panic("unexpected RestoreBatch")
 }
+func (transactionServiceStub) SaveFilter(context.Context, string, string) (apptransactions.SavedFilter, error) {
+	panic("unexpected SaveFilter")
+}
+func (transactionServiceStub) ListSavedFilters(context.Context) ([]apptransactions.SavedFilter, error) {
+	panic("unexpected ListSavedFilters")
+}
+func (transactionServiceStub) DeleteSavedFilter(context.Context, string) error {
+	panic("unexpected DeleteSavedFilter")
+}
 
 type userServiceStub struct{ calls *int }
 
@@ -94,7 +125,7 @@ func (categoryServiceStub) GetByCode(context.Context, string) (appcategories.Cat
 func (categoryServiceStub) Update(context.Context, appcategories.UpdateInput) (appcategories.Category, error) {
 	panic("unexpected Update")
 }
-func (categoryServiceStub) Deactivate(context.Context, string) (appcategories.Category, error) {
+func (categoryServiceStub) Deactivate(context.Context, string, *int64) (appcategories.Category, error) {
 	panic("unexpected Deactivate")
 }
 
@@ -107,22 +138,145 @@ func (s budgetServiceStub) GetMonthly(_ context.Context, input appbudgets.Monthl
 func (budgetServiceStub) EnsureMonthly(context.Context, appbudgets.MonthlyInput) (appbudgets.EnsureResult, error) {
 	panic("unexpected EnsureMonthly")
 }
+func (budgetServiceStub) GetPeriod(context.Context, appbudgets.PeriodInput) (appbudgets.Budget, error) {
+	panic("unexpected GetPeriod")
+}
+func (budgetServiceStub) EnsurePeriod(context.Context, appbudgets.PeriodInput) (appbudgets.EnsureResult, error) {
+	panic("unexpected EnsurePeriod")
+}
 func (budgetServiceStub) CreateLine(context.Context, appbudgets.CreateLineInput) (appbudgets.Line, error) {
 	panic("unexpected CreateLine")
 }
 func (budgetServiceStub) UpdateLine(context.Context, appbudgets.UpdateLineInput) (appbudgets.Line, error) {
 	panic("unexpected UpdateLine")
 }
-func (budgetServiceStub) DeleteLine(context.Context, int64) error { panic("unexpected DeleteLine") }
+func (budgetServiceStub) DeleteLine(context.Context, int64, *int64) error {
+	panic("unexpected DeleteLine")
+}
+func (budgetServiceStub) Reallocate(context.Context, appbudgets.ReallocateInput) (appbudgets.ReallocationResult, error) {
+	panic("unexpected Reallocate")
+}
 func (budgetServiceStub) Report(context.Context, int64) (appbudgets.Report, error) {
 	panic("unexpected Report")
 }
+func (budgetServiceStub) DetailedReport(context.Context, int64) (appbudgets.DetailedReport, error) {
+	panic("unexpected DetailedReport")
+}
 func (budgetServiceStub) DetailedMonthlyReport(context.Context, appbudgets.MonthlyInput) (appbudgets.DetailedReport, error) {
 	panic("unexpected DetailedMonthlyReport")
 }
+func (budgetServiceStub) Lint(context.Context, int64) (appbudgets.Lint, error) {
+	panic("unexpected Lint")
+}
+func (budgetServiceStub) Trends(context.Context, appbudgets.TrendInput) (appbudgets.TrendReport, error) {
+	panic("unexpected Trends")
+}
+func (budgetServiceStub) CreateTemplate(context.Context, appbudgets.CreateTemplateInput) (appbudgets.Template, error) {
+	panic("unexpected CreateTemplate")
+}
+func (budgetServiceStub) GetTemplate(context.Context, int64) (appbudgets.Template, error) {
+	panic("unexpected GetTemplate")
+}
+func (budgetServiceStub) ListTemplates(context.Context, appbudgets.Owner) ([]appbudgets.Template, error) {
+	panic("unexpected ListTemplates")
+}
+func (budgetServiceStub) UpdateTemplate(context.Context, appbudgets.UpdateTemplateInput) (appbudgets.Template, error) {
+	panic("unexpected UpdateTemplate")
+}
+func (budgetServiceStub) DeleteTemplate(context.Context, int64) error {
+	panic("unexpected DeleteTemplate")
+}
+func (budgetServiceStub) SaveTemplate(context.Context, appbudgets.SaveTemplateInput) (appbudgets.Template, error) {
+	panic("unexpected SaveTemplate")
+}
+func (budgetServiceStub) ApplyTemplate(context.Context, appbudgets.ApplyTemplateInput) (appbudgets.ApplyTemplateResult, error) {
+	panic("unexpected ApplyTemplate")
+}
+func (budgetServiceStub) Close(context.Context, appbudgets.CloseInput) (appbudgets.Closing, error) {
+	panic("unexpected Close")
+}
+func (budgetServiceStub) Reopen(context.Context, appbudgets.ReopenInput) (appbudgets.Closing, error) {
+	panic("unexpected Reopen")
+}
+func (budgetServiceStub) Compare(context.Context, appbudgets.ComparisonInput) (appbudgets.Comparison, error) {
+	panic("unexpected Compare")
+}
+
+type goalServiceStub struct{ calls *int }
+
+func (goalServiceStub) Create(context.Context, appgoals.CreateInput) (appgoals.Progress, error) {
+	panic("unexpected Create")
+}
+func (goalServiceStub) Get(context.Context, int64, time.Time) (appgoals.Progress, error) {
+	panic("unexpected Get")
+}
+func (s goalServiceStub) List(context.Context, appgoals.ListFilter) ([]appgoals.Progress, error) {
+	(*s.calls)++
+	return []appgoals.Progress{}, nil
+}
+func (goalServiceStub) Update(context.Context, appgoals.UpdateInput) (appgoals.Progress, error) {
+	panic("unexpected Update")
+}
+func (goalServiceStub) Delete(context.Context, int64) error { panic("unexpected Delete") }
+
+type alertServiceStub struct{ calls *int }
+
+func (s alertServiceStub) List(context.Context, appalerts.ListFilter) ([]appalerts.Alert, error) {
+	(*s.calls)++
+	return []appalerts.Alert{}, nil
+}
+
+type eventServiceStub struct{ calls *int }
+
+func (s eventServiceStub) Subscribe(int64) *appevents.Subscription {
+	(*s.calls)++
+	bus := appevents.NewBus(0)
+	bus.Close()
+	return bus.Subscribe(0)
+}
+
+type webhookServiceStub struct{ calls *int }
+
+func (webhookServiceStub) Create(context.Context, appwebhooks.CreateInput) (appwebhooks.Webhook, error) {
+	panic("unexpected Create")
+}
+func (webhookServiceStub) Get(context.Context, int64) (appwebhooks.Webhook, error) {
+	panic("unexpected Get")
+}
+func (s webhookServiceStub) List(context.Context, appwebhooks.ListFilter) ([]appwebhooks.Webhook, error) {
+	(*s.calls)++
+	return []appwebhooks.Webhook{}, nil
+}
+func (webhookServiceStub) Update(context.Context, appwebhooks.UpdateInput) (appwebhooks.Webhook, error) {
+	panic("unexpected Update")
+}
+func (webhookServiceStub) Delete(context.Context, int64) error { panic("unexpected Delete") }
+func (webhookServiceStub) ListDeliveries(context.Context, int64, int32) ([]appwebhooks.Delivery, error) {
+	panic("unexpected ListDeliveries")
+}
+func (webhookServiceStub) Redeliver(context.Context, int64) (appwebhooks.Delivery, error) {
+	panic("unexpected Redeliver")
+}
+
+type attachmentServiceStub struct{ calls *int }
+
+func (attachmentServiceStub) Upload(context.Context, appattachments.UploadInput) (appattachments.Attachment, error) {
+	panic("unexpected Upload")
+}
+func (s attachmentServiceStub) List(context.Context, int64) ([]appattachments.Attachment, error) {
+	(*s.calls)++
+	return []appattachments.Attachment{}, nil
+}
+func (attachmentServiceStub) Get(context.Context, int64) (appattachments.Attachment, error) {
+	panic("unexpected Get")
+}
+func (attachmentServiceStub) Open(context.Context, int64) (appattachments.Attachment, io.ReadCloser, error) {
+	panic("unexpected Open")
+}
+func (attachmentServiceStub) Delete(context.Context, int64) error { panic("unexpected Delete") }
 
 func TestCompositionExecutesEveryFeatureFlow(t *testing.T) {
-	transactionCalls, userCalls, householdCalls, categoryCalls, budgetCalls := 0, 0, 0, 0, 0
+	transactionCalls, userCalls, householdCalls, categoryCalls, budgetCalls, goalCalls, alertCalls, eventCalls, webhookCalls, attachmentCalls := 0, 0, 0, 0, 0, 0, 0, 0, 0, 0
 	server, err := New(
 		httpapi.Config{APIKey: "secret"},
 		webui.Config{DefaultUserID: 1, DefaultHouseholdID: 1},
@@ -131,6 +285,12 @@ func TestCompositionExecutesEveryFeatureFlow(t *testing.T) {
 		householdServiceStub{calls: &householdCalls},
 		categoryServiceStub{calls: &categoryCalls},
 		budgetServiceStub{calls: &budgetCalls},
+		goalServiceStub{calls: &goalCalls},
+		alertServiceStub{calls: &alertCalls},
+		eventServiceStub{calls: &eventCalls},
+		webhookServiceStub{calls: &webhookCalls},
+		attachmentServiceStub{calls: &attachmentCalls},
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -142,6 +302,11 @@ func TestCompositionExecutesEveryFeatureFlow(t *testing.T) {
 		{"households", "/v1/households"},
 		{"categories", "/v1/categories"},
 		{"budgets", "/v1/budgets/monthly?householdId=1&year=2026&month=7"},
+		{"goals", "/v1/goals?householdId=1"},
+		{"alerts", "/v1/alerts?householdId=1"},
+		{"events", "/v1/events?householdId=1"},
+		{"webhooks", "/v1/webhooks?householdId=1"},
+		{"attachments", "/v1/transactions/1/attachments"},
 	}
 	for _, test := range requests {
 		t.Run(test.feature, func(t *testing.T) {
@@ -176,10 +341,65 @@ func TestCompositionExecutesEveryFeatureFlow(t *testing.T) {
 	}
 	for feature, count := range map[string]int{
 		"transactions": transactionCalls, "users": userCalls, "households": householdCalls,
-		"categories": categoryCalls, "budgets": budgetCalls,
+		"categories": categoryCalls, "budgets": budgetCalls, "goals": goalCalls,
+		"alerts": alertCalls, "events": eventCalls, "webhooks": webhookCalls, "attachments": attachmentCalls,
 	} {
 		if count != 1 {
 			t.Errorf("%s service calls=%d, want 1", feature, count)
 		}
 	}
 }
+
+func TestOpenAPIDocumentsEveryRegisteredRoute(t *testing.T) {
+	calls := 0
+	server, err := New(
+		httpapi.Config{APIKey: "secret"},
+		webui.Config{DefaultUserID: 1, DefaultHouseholdID: 1},
+		transactionServiceStub{calls: &calls}, userServiceStub{calls: &calls}, householdServiceStub{calls: &calls},
+		categoryServiceStub{calls: &calls}, budgetServiceStub{calls: &calls}, goalServiceStub{calls: &calls}, alertServiceStub{calls: &calls}, eventServiceStub{calls: &calls},
+		webhookServiceStub{calls: &calls}, attachmentServiceStub{calls: &calls},
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := httptest.NewRequest(http.MethodGet, api.OpenAPIPath, nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	response := httptest.NewRecorder()
+	server.Handler.ServeHTTP(response, request)
+	if response.Code != http.StatusOK || response.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status=%d content-type=%s", response.Code, response.Header().Get("Content-Type"))
+	}
+	var document struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &document); err != nil || document.OpenAPI != "3.1.0" {
+		t.Fatalf("openapi=%q error=%v", document.OpenAPI, err)
+	}
+
+	router := httpapi.NewRouter()
+	registerAPI(httpapi.NewHandlerSupport(nil),
+		transactionServiceStub{calls: &calls}, userServiceStub{calls: &calls}, householdServiceStub{calls: &calls},
+		categoryServiceStub{calls: &calls}, budgetServiceStub{calls: &calls}, goalServiceStub{calls: &calls}, alertServiceStub{calls: &calls}, eventServiceStub{calls: &calls},
+		webhookServiceStub{calls: &calls}, attachmentServiceStub{calls: &calls},
+	)(router)
+	if err := httpapi.RegisterOpenAPI(router); err != nil {
+		t.Fatal(err)
+	}
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		registered[route] = true
+		method, path, _ := strings.Cut(route, " ")
+		if _, documented := document.Paths[path][strings.ToLower(method)]; !documented {
+			t.Errorf("route %s is registered but missing from the OpenAPI document", route)
+		}
+	}
+	for path, operations := range document.Paths {
+		for method := range operations {
+			if route := strings.ToUpper(method) + " " + path; !registered[route] {
+				t.Errorf("route %s is documented but not registered", route)
+			}
+		}
+	}
+}
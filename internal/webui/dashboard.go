@@ -13,6 +13,7 @@ import (
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
 	apphouseholds "rdmm404/voltr-finance/internal/app/households"
 	appusers "rdmm404/voltr-finance/internal/app/users"
+	"rdmm404/voltr-finance/internal/reportexport"
 )
 
 type Config struct {
@@ -33,6 +34,7 @@ func (c Config) Validate() error {
 
 type BudgetReader interface {
 	DetailedMonthlyReport(context.Context, appbudgets.MonthlyInput) (appbudgets.DetailedReport, error)
+	Trends(context.Context, appbudgets.TrendInput) (appbudgets.TrendReport, error)
 }
 type UserReader interface {
 	List(context.Context) ([]appusers.User, error)
@@ -62,27 +64,42 @@ func ParseRequestState(values url.Values, config Config, now time.Time) (Request
 		state.Month = time.Date(now.In(time.Local).Year(), now.In(time.Local).Month(), 1, 0, 0, 0, 0, time.Local)
 		return state, true, nil
 	}
-	if len(month) != 7 {
-		return RequestState{}, false, fmt.Errorf("month must use YYYY-MM format")
-	}
-	parsed, err := time.ParseInLocation("2006-01", month, time.Local)
-	if err != nil || parsed.Format("2006-01") != month {
-		return RequestState{}, false, fmt.Errorf("month must be a valid calendar month in YYYY-MM format")
+	parsed, err := parseMonth("month", month)
+	if err != nil {
+		return RequestState{}, false, err
 	}
 	state.Month = parsed
-	for name, target := range map[string]*int64{"userId": &state.UserID, "householdId": &state.HouseholdID} {
+	if err := parseOwners(values, &state.UserID, &state.HouseholdID); err != nil {
+		return RequestState{}, false, err
+	}
+	return state, false, nil
+}
+
+func parseMonth(name, value string) (time.Time, error) {
+	if len(value) != 7 {
+		return time.Time{}, fmt.Errorf("%s must use YYYY-MM format", name)
+	}
+	parsed, err := time.ParseInLocation("2006-01", value, time.Local)
+	if err != nil || parsed.Format("2006-01") != value {
+		return time.Time{}, fmt.Errorf("%s must be a valid calendar month in YYYY-MM format", name)
+	}
+	return parsed, nil
+}
+
+func parseOwners(values url.Values, userID, householdID *int64) error {
+	for name, target := range map[string]*int64{"userId": userID, "householdId": householdID} {
 		if raw, exists := values[name]; exists {
 			if len(raw) != 1 || strings.TrimSpace(raw[0]) == "" {
-				return RequestState{}, false, fmt.Errorf("%s must be a positive integer", name)
+				return fmt.Errorf("%s must be a positive integer", name)
 			}
 			value, err := strconv.ParseInt(raw[0], 10, 64)
 			if err != nil || value <= 0 {
-				return RequestState{}, false, fmt.Errorf("%s must be a positive integer", name)
+				return fmt.Errorf("%s must be a positive integer", name)
 			}
 			*target = value
 		}
 	}
-	return state, false, nil
+	return nil
 }
 
 func StateURL(state RequestState) string {
@@ -97,23 +114,38 @@ type Dashboard struct{ services Services }
 
 func NewDashboard(services Services) *Dashboard { return &Dashboard{services: services} }
 
-func (d *Dashboard) Assemble(ctx context.Context, state RequestState) (PageView, error) {
-	users, err := d.services.Users.List(ctx)
-	if err != nil {
-		return PageView{}, err
+// owners holds the owner pickers and the selected user and household.
+type owners struct {
+	users      []appusers.User
+	households []apphouseholds.Household
+	user       appusers.User
+	household  apphouseholds.Household
+}
+
+func (d *Dashboard) owners(ctx context.Context, userID, householdID int64) (owners, error) {
+	var result owners
+	var err error
+	if result.users, err = d.services.Users.List(ctx); err != nil {
+		return owners{}, err
 	}
-	households, err := d.services.Households.List(ctx)
-	if err != nil {
-		return PageView{}, err
+	if result.households, err = d.services.Households.List(ctx); err != nil {
+		return owners{}, err
 	}
-	user, err := d.services.Users.Get(ctx, state.UserID)
-	if err != nil {
-		return PageView{}, err
+	if result.user, err = d.services.Users.Get(ctx, userID); err != nil {
+		return owners{}, err
+	}
+	if result.household, err = d.services.Households.Get(ctx, householdID); err != nil {
+		return owners{}, err
 	}
-	household, err := d.services.Households.Get(ctx, state.HouseholdID)
+	return result, nil
+}
+
+func (d *Dashboard) Assemble(ctx context.Context, state RequestState) (PageView, error) {
+	selected, err := d.owners(ctx, state.UserID, state.HouseholdID)
 	if err != nil {
 		return PageView{}, err
 	}
+	users, households, user, household := selected.users, selected.households, selected.user, selected.household
 	personal, personalMissing, err := d.report(ctx, appbudgets.Owner{UserID: &state.UserID}, state, "Personal", user.Name)
 	if err != nil {
 		return PageView{}, err
@@ -127,7 +159,7 @@ func (d *Dashboard) Assemble(ctx context.Context, state RequestState) (PageView,
 	next.Month = state.Month.AddDate(0, 1, 0)
 	view := PageView{
 		Month: state.Month.Format("January 2006"), MonthValue: state.Month.Format("2006-01"),
-		PreviousURL: StateURL(previous), NextURL: StateURL(next),
+		PreviousURL: StateURL(previous), NextURL: StateURL(next), TrendsURL: TrendStateURL(DefaultTrendState(state)),
 		UserID: state.UserID, HouseholdID: state.HouseholdID,
 		Users: users, Households: households, Personal: personal, Household: householdReport,
 		AllEmpty: personalMissing && householdMissing,
@@ -145,5 +177,46 @@ func (d *Dashboard) report(ctx context.Context, owner appbudgets.Owner, state Re
 		return ScopeView{}, false, err
 	}
 	view, err := mapScope(report, label, name)
+	view.PDFURL, view.XLSXURL = ExportURL(state, owner, reportexport.FormatPDF), ExportURL(state, owner, reportexport.FormatXLSX)
 	return view, false, err
 }
+
+// ExportURL downloads one owner's monthly report as a document.
+func ExportURL(state RequestState, owner appbudgets.Owner, format reportexport.Format) string {
+	values := url.Values{}
+	values.Set("format", string(format))
+	values.Set("month", state.Month.Format("2006-01"))
+	if owner.HouseholdID != nil {
+		values.Set("householdId", strconv.FormatInt(*owner.HouseholdID, 10))
+	} else if owner.UserID != nil {
+		values.Set("userId", strconv.FormatInt(*owner.UserID, 10))
+	}
+	return "/export?" + values.Encode()
+}
+
+// ParseExportRequest reads a report download: a month, exactly one of userId
+// and householdId, and a pdf or xlsx format.
+func ParseExportRequest(values url.Values) (appbudgets.MonthlyInput, reportexport.Format, error) {
+	format, err := reportexport.ParseFormat(values.Get("format"))
+	if err != nil {
+		return appbudgets.MonthlyInput{}, "", err
+	}
+	month, err := parseMonth("month", values.Get("month"))
+	if err != nil {
+		return appbudgets.MonthlyInput{}, "", err
+	}
+	var userID, householdID int64
+	if err := parseOwners(values, &userID, &householdID); err != nil {
+		return appbudgets.MonthlyInput{}, "", err
+	}
+	input := appbudgets.MonthlyInput{Year: month.Year(), Month: int(month.Month())}
+	switch {
+	case userID != 0 && householdID == 0:
+		input.Owner.UserID = &userID
+	case householdID != 0 && userID == 0:
+		input.Owner.HouseholdID = &householdID
+	default:
+		return appbudgets.MonthlyInput{}, "", errors.New("exactly one of userId and householdId is required")
+	}
+	return input, format, nil
+}
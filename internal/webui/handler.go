@@ -1,17 +1,21 @@
 package webui
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"fmt"
 	"io/fs"
 	"log/slog"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/a-h/templ"
 	apperrors "rdmm404/voltr-finance/internal/app/errors"
+	"rdmm404/voltr-finance/internal/reportexport"
 )
 
 //go:embed assets/dist/*
@@ -44,6 +48,8 @@ func (h *Handler) Register(mux *http.ServeMux) {
 		w.Header().Set("Cache-Control", "public, max-age=3600")
 		assetHandler.ServeHTTP(w, r)
 	}))
+	mux.HandleFunc("GET /trends", h.trendsPage)
+	mux.HandleFunc("GET /export", h.exportReport)
 	mux.HandleFunc("GET /", h.dashboardPage)
 }
 
@@ -74,6 +80,60 @@ func (h *Handler) dashboardPage(w http.ResponseWriter, r *http.Request) {
 	h.render(r.Context(), w, http.StatusOK, DashboardPage(view))
 }
 
+func (h *Handler) trendsPage(w http.ResponseWriter, r *http.Request) {
+	state, redirect, err := ParseTrendState(r.URL.Query(), h.config, h.now())
+	if err != nil {
+		h.renderStatus(w, http.StatusBadRequest, "Invalid trends request", err.Error())
+		return
+	}
+	if redirect {
+		http.Redirect(w, r, TrendStateURL(state), http.StatusSeeOther)
+		return
+	}
+	view, err := h.dashboard.AssembleTrends(r.Context(), state)
+	if err != nil {
+		if apperrors.IsKind(err, apperrors.KindNotFound) {
+			h.renderStatus(w, http.StatusNotFound, "Owner not found", "The selected user or household does not exist.")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "render budget trends", "error", err)
+		h.renderStatus(w, http.StatusInternalServerError, "Trends unavailable", "Budget trends could not be loaded safely. Please try again.")
+		return
+	}
+	h.render(r.Context(), w, http.StatusOK, TrendsPage(view))
+}
+
+// exportReport downloads a monthly report as a PDF or XLSX document. Unlike
+// the dashboard, a missing budget is a 404 since there is nothing to export.
+func (h *Handler) exportReport(w http.ResponseWriter, r *http.Request) {
+	input, format, err := ParseExportRequest(r.URL.Query())
+	if err != nil {
+		h.renderStatus(w, http.StatusBadRequest, "Invalid export request", err.Error())
+		return
+	}
+	report, err := h.dashboard.services.Budgets.DetailedMonthlyReport(r.Context(), input)
+	if err != nil {
+		if apperrors.IsKind(err, apperrors.KindNotFound) {
+			h.renderStatus(w, http.StatusNotFound, "Budget not found", "No budget exists for this owner and month.")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "load exported budget report", "error", err)
+		h.renderStatus(w, http.StatusInternalServerError, "Export unavailable", "The report could not be exported. Please try again.")
+		return
+	}
+	var document bytes.Buffer
+	if err := reportexport.Write(&document, format, report); err != nil {
+		h.logger.ErrorContext(r.Context(), "render exported budget report", "error", err)
+		h.renderStatus(w, http.StatusInternalServerError, "Export unavailable", "The report could not be exported. Please try again.")
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": reportexport.Filename(report, format)}))
+	w.Header().Set("Content-Length", strconv.Itoa(document.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = document.WriteTo(w)
+}
+
 func (h *Handler) renderStatus(w http.ResponseWriter, status int, title, message string) {
 	h.render(context.Background(), w, status, StatusPage(status, title, message))
 }
@@ -0,0 +1,197 @@
+package webui
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	appbudgets "rdmm404/voltr-finance/internal/app/budgets"
+	apphouseholds "rdmm404/voltr-finance/internal/app/households"
+	appusers "rdmm404/voltr-finance/internal/app/users"
+)
+
+// TrendState selects the months and owners of the trends page. Both months are
+// first days of a month in the local timezone.
+type TrendState struct {
+	From        time.Time
+	To          time.Time
+	UserID      int64
+	HouseholdID int64
+}
+
+// DefaultTrendState covers January through the dashboard's month for the same
+// owners.
+func DefaultTrendState(state RequestState) TrendState {
+	return TrendState{
+		From: time.Date(state.Month.Year(), time.January, 1, 0, 0, 0, 0, time.Local), To: state.Month,
+		UserID: state.UserID, HouseholdID: state.HouseholdID,
+	}
+}
+
+// ParseTrendState reads from, to and owner overrides. Missing months redirect
+// to the year to date for the current month.
+func ParseTrendState(values url.Values, config Config, now time.Time) (TrendState, bool, error) {
+	state := TrendState{UserID: config.DefaultUserID, HouseholdID: config.DefaultHouseholdID}
+	if err := parseOwners(values, &state.UserID, &state.HouseholdID); err != nil {
+		return TrendState{}, false, err
+	}
+	if values.Get("from") == "" || values.Get("to") == "" {
+		month := time.Date(now.In(time.Local).Year(), now.In(time.Local).Month(), 1, 0, 0, 0, 0, time.Local)
+		return DefaultTrendState(RequestState{Month: month, UserID: state.UserID, HouseholdID: state.HouseholdID}), true, nil
+	}
+	var err error
+	if state.From, err = parseMonth("from", values.Get("from")); err != nil {
+		return TrendState{}, false, err
+	}
+	if state.To, err = parseMonth("to", values.Get("to")); err != nil {
+		return TrendState{}, false, err
+	}
+	if state.To.Before(state.From) {
+		return TrendState{}, false, fmt.Errorf("from must not be after to")
+	}
+	if months := (state.To.Year()-state.From.Year())*12 + int(state.To.Month()-state.From.Month()) + 1; months > appbudgets.MaxTrendMonths {
+		return TrendState{}, false, fmt.Errorf("trends can span at most %d months", appbudgets.MaxTrendMonths)
+	}
+	return state, false, nil
+}
+
+func TrendStateURL(state TrendState) string {
+	values := url.Values{}
+	values.Set("from", state.From.Format("2006-01"))
+	values.Set("to", state.To.Format("2006-01"))
+	values.Set("userId", strconv.FormatInt(state.UserID, 10))
+	values.Set("householdId", strconv.FormatInt(state.HouseholdID, 10))
+	return "/trends?" + values.Encode()
+}
+
+func (d *Dashboard) AssembleTrends(ctx context.Context, state TrendState) (TrendsPageView, error) {
+	selected, err := d.owners(ctx, state.UserID, state.HouseholdID)
+	if err != nil {
+		return TrendsPageView{}, err
+	}
+	personal, err := d.trendScope(ctx, appbudgets.Owner{UserID: &state.UserID}, state, "Personal", selected.user.Name)
+	if err != nil {
+		return TrendsPageView{}, err
+	}
+	household, err := d.trendScope(ctx, appbudgets.Owner{HouseholdID: &state.HouseholdID}, state, "Household", selected.household.Name)
+	if err != nil {
+		return TrendsPageView{}, err
+	}
+	return TrendsPageView{
+		Range:     state.From.Format("Jan 2006") + " – " + state.To.Format("Jan 2006"),
+		FromValue: state.From.Format("2006-01"), ToValue: state.To.Format("2006-01"),
+		DashboardURL: StateURL(RequestState{Month: state.To, UserID: state.UserID, HouseholdID: state.HouseholdID}),
+		UserID:       state.UserID, HouseholdID: state.HouseholdID,
+		Users: selected.users, Households: selected.households,
+		Personal: personal, Household: household,
+	}, nil
+}
+
+func (d *Dashboard) trendScope(ctx context.Context, owner appbudgets.Owner, state TrendState, label, name string) (TrendScopeView, error) {
+	report, err := d.services.Budgets.Trends(ctx, appbudgets.TrendInput{Owner: owner, From: state.From, To: state.To})
+	if err != nil {
+		return TrendScopeView{}, err
+	}
+	return mapTrendScope(report, label, name)
+}
+
+type TrendsPageView struct {
+	Range, FromValue, ToValue, DashboardURL string
+	UserID, HouseholdID                     int64
+	Users                                   []appusers.User
+	Households                              []apphouseholds.Household
+	Personal, Household                     TrendScopeView
+}
+
+type TrendScopeView struct {
+	Label, OwnerName  string
+	Empty             bool
+	Months            []string
+	Lines, Categories []TrendRowView
+	Total             TrendRowView
+	YearToDate        string
+}
+
+// TrendRowView is one line or category across the selected months. Category
+// rows leave Allocation empty in months no line mapped them, and entirely for
+// uncategorized spending.
+type TrendRowView struct {
+	Name                       string
+	Cells                      []TrendCellView
+	Total, Average, YearToDate string
+	State                      SemanticState
+}
+
+// TrendCellView is one month of a row. Empty marks months without a budget
+// line and without spending.
+type TrendCellView struct {
+	Actual, Allocation string
+	Empty              bool
+	State              SemanticState
+}
+
+func mapTrendScope(report appbudgets.TrendReport, label, ownerName string) (TrendScopeView, error) {
+	view := TrendScopeView{
+		Label: label, OwnerName: ownerName, Empty: len(report.Lines) == 0 && len(report.Categories) == 0,
+		Months: make([]string, 0, len(report.Months)), Lines: make([]TrendRowView, 0, len(report.Lines)), Categories: make([]TrendRowView, 0, len(report.Categories)),
+	}
+	totalPoints := make([]appbudgets.TrendPoint, 0, len(report.Months))
+	for _, month := range report.Months {
+		view.Months = append(view.Months, month.Month.Format("Jan"))
+		totalPoints = append(totalPoints, appbudgets.TrendPoint{Month: month.Month, Budgeted: month.BudgetID != nil, AllocationAmount: month.AllocationAmount, ActualAmount: month.ActualAmount})
+	}
+	total, err := trendRow(appbudgets.TrendSeries{Name: "Total", Months: totalPoints, Totals: report.Totals, YearToDate: report.YearToDate}, true)
+	if err != nil {
+		return TrendScopeView{}, err
+	}
+	view.Total = total
+	view.YearToDate = fmt.Sprintf("%s of %s", formatCAD(mustMoneyCents(report.YearToDate.ActualAmount)), formatCAD(mustMoneyCents(report.YearToDate.AllocationAmount)))
+	for _, line := range report.Lines {
+		row, err := trendRow(line, true)
+		if err != nil {
+			return TrendScopeView{}, err
+		}
+		view.Lines = append(view.Lines, row)
+	}
+	for _, category := range report.Categories {
+		budgeted := slices.ContainsFunc(category.Months, func(point appbudgets.TrendPoint) bool { return point.Budgeted })
+		row, err := trendRow(category, budgeted)
+		if err != nil {
+			return TrendScopeView{}, err
+		}
+		view.Categories = append(view.Categories, row)
+	}
+	return view, nil
+}
+
+func trendRow(series appbudgets.TrendSeries, allocated bool) (TrendRowView, error) {
+	row := TrendRowView{Name: series.Name, Cells: make([]TrendCellView, 0, len(series.Months)), State: StateNormal}
+	for _, point := range series.Months {
+		actual, err := moneyCents(point.ActualAmount)
+		if err != nil {
+			return TrendRowView{}, err
+		}
+		cell := TrendCellView{Actual: formatCAD(actual), Empty: !point.Budgeted && actual == 0, State: StateNormal}
+		if allocated && point.Budgeted {
+			allocation, err := moneyCents(point.AllocationAmount)
+			if err != nil {
+				return TrendRowView{}, err
+			}
+			cell.Allocation = formatCAD(allocation)
+			cell.State = varianceState(allocation-actual, actual, allocation, "")
+		}
+		row.Cells = append(row.Cells, cell)
+	}
+	row.Total = formatCAD(mustMoneyCents(series.Totals.ActualAmount))
+	row.Average = formatCAD(mustMoneyCents(series.Totals.AverageActualAmount))
+	row.YearToDate = formatCAD(mustMoneyCents(series.YearToDate.ActualAmount))
+	if allocated {
+		allocation, actual := mustMoneyCents(series.Totals.AllocationAmount), mustMoneyCents(series.Totals.ActualAmount)
+		row.Total += " of " + formatCAD(allocation)
+		row.State = varianceState(allocation-actual, actual, allocation, "")
+	}
+	return row, nil
+}
@@ -21,6 +21,7 @@ const (
 
 type PageView struct {
 	Month, MonthValue, PreviousURL, NextURL string
+	TrendsURL                               string
 	UserID, HouseholdID                     int64
 	Users                                   []appusers.User
 	Households                              []apphouseholds.Household
@@ -33,21 +34,39 @@ type SummaryView struct {
 	Allocation, Spent, Remaining, Unmapped string
 	Progress                               string
 	State                                  SemanticState
+	Risk                                   appbudgets.ForecastRisk
 }
 
 type ScopeView struct {
 	Label, OwnerName string
 	Empty            bool
+	PDFURL, XLSXURL  string
 	Summary          SummaryView
 	Lines            []LineView
 	Unmapped         []TransactionView
+	Goals            []GoalView
 }
 
 type LineView struct {
 	Name, Allocation, Actual, Remaining, Progress string
+	Projected                                     string
 	State                                         SemanticState
 	Categories                                    string
 	Transactions                                  []TransactionView
+	Members                                       []MemberView
+}
+
+// MemberView is one household member's spending against their share of a
+// line. Allocation is zero for members who spent without a share.
+type MemberView struct {
+	Name, Allocation, Actual, Remaining string
+	State                               SemanticState
+}
+
+type GoalView struct {
+	Name, Balance, Target, Required, ThisPeriod, TargetDate, Progress string
+	Status                                                            string
+	State                                                             SemanticState
 }
 
 type TransactionView struct {
@@ -70,7 +89,7 @@ func mapScope(report appbudgets.DetailedReport, label, ownerName string) (ScopeV
 	}
 	spent, remaining := mapped+unmapped, allocation-mapped-unmapped
 	view := ScopeView{Label: label, OwnerName: ownerName, Lines: make([]LineView, 0, len(report.Lines)), Unmapped: mapTransactions(report.UnmappedTransactions)}
-	view.Summary = summary(allocation, spent, remaining, unmapped)
+	view.Summary = summary(allocation, spent, remaining, unmapped, report.Forecast.Risk)
 	for _, line := range report.Lines {
 		lineAllocation, err := moneyCents(line.AllocationAmount)
 		if err != nil {
@@ -88,34 +107,100 @@ func mapScope(report appbudgets.DetailedReport, label, ownerName string) (ScopeV
 		if percentage < 0 {
 			percentage = 0
 		}
+		members, err := mapMembers(line.Members)
+		if err != nil {
+			return ScopeView{}, err
+		}
 		categories := make([]string, 0, len(line.Categories))
 		for _, category := range line.Categories {
 			categories = append(categories, category.Name)
 		}
 		view.Lines = append(view.Lines, LineView{
 			Name: line.Name, Allocation: formatCAD(lineAllocation), Actual: formatCAD(actual), Remaining: formatCAD(remaining),
-			Progress: strconv.FormatInt(percentage, 10), State: varianceState(remaining, actual, lineAllocation),
-			Categories: strings.Join(categories, ", "), Transactions: mapTransactions(line.Transactions),
+			Projected: formatCAD(mustMoneyCents(line.Forecast.ProjectedAmount)), Progress: strconv.FormatInt(percentage, 10), State: varianceState(remaining, actual, lineAllocation, line.Forecast.Risk),
+			Categories: strings.Join(categories, ", "), Transactions: mapTransactions(line.Transactions), Members: members,
 		})
 	}
+	goals, err := mapGoals(report.Goals)
+	if err != nil {
+		return ScopeView{}, err
+	}
+	view.Goals = goals
 	return view, nil
 }
 
+func mapMembers(items []appbudgets.MemberSpending) ([]MemberView, error) {
+	result := make([]MemberView, 0, len(items))
+	for _, item := range items {
+		allocation, err := moneyCents(item.AllocationAmount)
+		if err != nil {
+			return nil, err
+		}
+		actual, err := moneyCents(item.ActualAmount)
+		if err != nil {
+			return nil, err
+		}
+		remaining := allocation - actual
+		result = append(result, MemberView{
+			Name: item.Member.Name, Allocation: formatCAD(allocation), Actual: formatCAD(actual), Remaining: formatCAD(remaining),
+			State: varianceState(remaining, actual, allocation, appbudgets.ForecastOnTrack),
+		})
+	}
+	return result, nil
+}
+
+func mapGoals(items []appbudgets.GoalProgress) ([]GoalView, error) {
+	result := make([]GoalView, 0, len(items))
+	for _, item := range items {
+		target, err := moneyCents(item.TargetAmount)
+		if err != nil {
+			return nil, err
+		}
+		balance, err := moneyCents(item.BalanceAmount)
+		if err != nil {
+			return nil, err
+		}
+		percentage := int64(0)
+		if target > 0 {
+			percentage = min(max(balance*100/target, 0), 100)
+		}
+		view := GoalView{
+			Name: item.Name, Balance: formatCAD(balance), Target: formatCAD(target),
+			Required: formatCAD(mustMoneyCents(item.RequiredMonthlyAmount)), ThisPeriod: formatCAD(mustMoneyCents(item.PeriodNetAmount)),
+			TargetDate: item.TargetDate.Format("Jan 2, 2006"), Progress: strconv.FormatInt(percentage, 10), Status: "On track", State: StateNormal,
+		}
+		switch item.State {
+		case "achieved":
+			view.Status = "Achieved"
+		case "behind":
+			view.Status, view.State = "Behind", StateWarning
+		case "overdue":
+			view.Status, view.State = "Overdue", StateDanger
+		}
+		result = append(result, view)
+	}
+	return result, nil
+}
+
 func combineScopes(scopes ...ScopeView) SummaryView {
 	var allocation, spent, remaining, unmapped int64
+	risk := appbudgets.ForecastOnTrack
 	for _, scope := range scopes {
 		if scope.Empty {
 			continue
 		}
+		if riskSeverity(scope.Summary.Risk) > riskSeverity(risk) {
+			risk = scope.Summary.Risk
+		}
 		allocation += mustMoneyCents(scope.Summary.Allocation)
 		spent += mustMoneyCents(scope.Summary.Spent)
 		remaining += mustMoneyCents(scope.Summary.Remaining)
 		unmapped += mustMoneyCents(scope.Summary.Unmapped)
 	}
-	return summary(allocation, spent, remaining, unmapped)
+	return summary(allocation, spent, remaining, unmapped, risk)
 }
 
-func summary(allocation, spent, remaining, unmapped int64) SummaryView {
+func summary(allocation, spent, remaining, unmapped int64, risk appbudgets.ForecastRisk) SummaryView {
 	percentage := int64(0)
 	if allocation > 0 {
 		percentage = spent * 100 / allocation
@@ -125,20 +210,35 @@ func summary(allocation, spent, remaining, unmapped int64) SummaryView {
 	}
 	return SummaryView{
 		Allocation: formatCAD(allocation), Spent: formatCAD(spent), Remaining: formatCAD(remaining), Unmapped: formatCAD(unmapped),
-		Progress: strconv.FormatInt(percentage, 10), State: varianceState(remaining, spent, allocation),
+		Progress: strconv.FormatInt(percentage, 10), State: varianceState(remaining, spent, allocation, risk), Risk: risk,
 	}
 }
 
-func varianceState(remaining, spent, allocation int64) SemanticState {
-	if remaining < 0 {
+// varianceState flags spending that is already over or near its allocation,
+// and also lines the month-end forecast expects to get there.
+func varianceState(remaining, spent, allocation int64, risk appbudgets.ForecastRisk) SemanticState {
+	if remaining < 0 || riskSeverity(risk) >= riskSeverity(appbudgets.ForecastAtRisk) {
 		return StateDanger
 	}
-	if allocation > 0 && spent*100 >= allocation*80 {
+	if (allocation > 0 && spent*100 >= allocation*80) || risk == appbudgets.ForecastWatch {
 		return StateWarning
 	}
 	return StateNormal
 }
 
+func riskSeverity(risk appbudgets.ForecastRisk) int {
+	switch risk {
+	case appbudgets.ForecastWatch:
+		return 1
+	case appbudgets.ForecastAtRisk:
+		return 2
+	case appbudgets.ForecastOverBudget:
+		return 3
+	default:
+		return 0
+	}
+}
+
 func mapTransactions(items []appbudgets.DetailedTransaction) []TransactionView {
 	result := make([]TransactionView, 0, len(items))
 	for _, item := range items {
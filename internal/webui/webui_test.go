@@ -20,6 +20,7 @@ import (
 
 type budgetStub struct {
 	reports map[bool]appbudgets.DetailedReport
+	trends  map[bool]appbudgets.TrendReport
 	errs    map[bool]error
 	calls   int
 }
@@ -30,6 +31,12 @@ func (s *budgetStub) DetailedMonthlyReport(_ context.Context, input appbudgets.M
 	return s.reports[household], s.errs[household]
 }
 
+func (s *budgetStub) Trends(_ context.Context, input appbudgets.TrendInput) (appbudgets.TrendReport, error) {
+	s.calls++
+	household := input.Owner.HouseholdID != nil
+	return s.trends[household], s.errs[household]
+}
+
 type userStub struct {
 	users []appusers.User
 	err   error
@@ -101,6 +108,97 @@ func TestMapScopeCurrencyAndCombinedTotals(t *testing.T) {
 	}
 }
 
+func TestMapScopeFlagsLinesByMonthEndForecast(t *testing.T) {
+	line := func(name string, risk appbudgets.ForecastRisk, projected string) appbudgets.DetailedReportLine {
+		return appbudgets.DetailedReportLine{
+			ReportLine: appbudgets.ReportLine{Line: appbudgets.Line{Name: name, AllocationAmount: "100.00"}, ActualAmount: "40", RemainingAmount: "60"},
+			Forecast:   appbudgets.Forecast{ProjectedAmount: projected, Risk: risk},
+		}
+	}
+	report := appbudgets.DetailedReport{
+		Totals:   appbudgets.ReportTotals{AllocationAmount: "300.00", ActualAmount: "120.00", UnmappedActualAmount: "0"},
+		Lines:    []appbudgets.DetailedReportLine{line("Food", appbudgets.ForecastOnTrack, "70"), line("Fuel", appbudgets.ForecastWatch, "85"), line("Dining", appbudgets.ForecastAtRisk, "130.50")},
+		Forecast: appbudgets.Forecast{ProjectedAmount: "285.50", Risk: appbudgets.ForecastWatch},
+	}
+	scope, err := mapScope(report, "Personal", "Alex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scope.Lines[0].State != StateNormal || scope.Lines[1].State != StateWarning || scope.Lines[2].State != StateDanger || scope.Lines[2].Projected != "$130.50" {
+		t.Fatalf("lines=%+v", scope.Lines)
+	}
+	if scope.Summary.State != StateWarning {
+		t.Fatalf("summary=%+v", scope.Summary)
+	}
+	if combined := combineScopes(scope, ScopeView{Summary: SummaryView{Allocation: "$0.00", Spent: "$0.00", Remaining: "$0.00", Unmapped: "$0.00", Risk: appbudgets.ForecastAtRisk}}); combined.State != StateDanger {
+		t.Fatalf("combined=%+v", combined)
+	}
+	var output strings.Builder
+	if err := BudgetLine(scope.Lines[2]).Render(context.Background(), &output); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), "Projected by month end") || !strings.Contains(output.String(), "$130.50") {
+		t.Fatalf("rendered line: %s", output.String())
+	}
+}
+
+func TestMapScopeRendersSavingsGoals(t *testing.T) {
+	report := appbudgets.DetailedReport{
+		Totals: appbudgets.ReportTotals{AllocationAmount: "0", ActualAmount: "0", UnmappedActualAmount: "0"},
+		Goals: []appbudgets.GoalProgress{
+			{Name: "Car insurance", TargetAmount: "1200.00", BalanceAmount: "300.00", RequiredMonthlyAmount: "150.00", PeriodNetAmount: "100.00", TargetDate: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), State: "behind"},
+			{Name: "Holidays", TargetAmount: "500.00", BalanceAmount: "650.00", RequiredMonthlyAmount: "0.00", PeriodNetAmount: "0.00", TargetDate: time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC), State: "achieved"},
+		},
+	}
+	scope, err := mapScope(report, "Household", "Home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scope.Goals) != 2 || scope.Goals[0].Progress != "25" || scope.Goals[0].State != StateWarning || scope.Goals[0].Required != "$150.00" || scope.Goals[1].Progress != "100" || scope.Goals[1].Status != "Achieved" {
+		t.Fatalf("goals=%+v", scope.Goals)
+	}
+	var output strings.Builder
+	if err := ScopeReport(scope).Render(context.Background(), &output); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"Savings goals", "Car insurance", "$150.00/month until Dec 31, 2026", ">Behind<"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Fatalf("expected %q in rendered scope: %s", expected, output.String())
+		}
+	}
+}
+
+func TestMapScopeRendersHouseholdMemberSpending(t *testing.T) {
+	report := appbudgets.DetailedReport{
+		Totals: appbudgets.ReportTotals{AllocationAmount: "300.00", ActualAmount: "185.00", UnmappedActualAmount: "0"},
+		Lines: []appbudgets.DetailedReportLine{{
+			ReportLine: appbudgets.ReportLine{Line: appbudgets.Line{Name: "Groceries", AllocationAmount: "300.00"}, ActualAmount: "185.00", RemainingAmount: "115.00"},
+			Forecast:   appbudgets.Forecast{ProjectedAmount: "185.00", Risk: appbudgets.ForecastOnTrack},
+			Members: []appbudgets.MemberSpending{
+				{Member: appbudgets.Author{ID: 2, Name: "Alex"}, AllocationAmount: "100.00", ActualAmount: "120.00", RemainingAmount: "-20.00"},
+				{Member: appbudgets.Author{ID: 3, Name: "Sam"}, AllocationAmount: "200.00", ActualAmount: "65.00", RemainingAmount: "135.00"},
+			},
+		}},
+	}
+	scope, err := mapScope(report, "Household", "Home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := scope.Lines[0].Members
+	if len(members) != 2 || members[0].State != StateDanger || members[0].Remaining != "-$20.00" || members[1].State != StateNormal || members[1].Allocation != "$200.00" {
+		t.Fatalf("members=%+v", members)
+	}
+	var output strings.Builder
+	if err := BudgetLine(scope.Lines[0]).Render(context.Background(), &output); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"Alex", "$120.00 of $100.00", "-$20.00 left", "Sam"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Fatalf("expected %q in rendered line: %s", expected, output.String())
+		}
+	}
+}
+
 func TestSummaryMetricsUsesSummaryState(t *testing.T) {
 	var output strings.Builder
 	summary := SummaryView{
@@ -169,6 +267,55 @@ func TestHandlerRedirectRenderAssetsAndErrors(t *testing.T) {
 	}
 }
 
+func TestExportDownloadsOneOwnersMonthlyReport(t *testing.T) {
+	report := appbudgets.DetailedReport{
+		Budget: appbudgets.BudgetSummary{ID: 10, PeriodKind: appbudgets.PeriodMonthly, PeriodStart: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC)},
+		Totals: appbudgets.ReportTotals{AllocationAmount: "100.00", ActualAmount: "25.00", RemainingAmount: "75.00", UnmappedActualAmount: "0.00"},
+	}
+	budgets := &budgetStub{reports: map[bool]appbudgets.DetailedReport{true: report}, errs: map[bool]error{false: apperrors.NotFound(apperrors.CodeBudgetNotFound, "budget not found", nil)}}
+	handler, err := New(Config{DefaultUserID: 1, DefaultHouseholdID: 2}, Services{Budgets: budgets, Users: userStub{}, Households: householdStub{}}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	handler.Register(mux)
+	householdID := int64(2)
+	path := ExportURL(RequestState{Month: time.Date(2026, 7, 1, 0, 0, 0, 0, time.Local)}, appbudgets.Owner{HouseholdID: &householdID}, "pdf")
+	if path != "/export?format=pdf&householdId=2&month=2026-07" {
+		t.Fatalf("export url=%s", path)
+	}
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+	if response.Code != http.StatusOK || response.Header().Get("Content-Type") != "application/pdf" || response.Header().Get("Content-Disposition") != "attachment; filename=budget-10-2026-07-01.pdf" || !strings.HasPrefix(response.Body.String(), "%PDF-") {
+		t.Fatalf("export=%d %v", response.Code, response.Header())
+	}
+	for path, want := range map[string]int{
+		"/export?format=xlsx&userId=1&month=2026-07":              http.StatusNotFound,
+		"/export?format=csv&householdId=2&month=2026-07":          http.StatusBadRequest,
+		"/export?format=pdf&month=2026-07":                        http.StatusBadRequest,
+		"/export?format=pdf&userId=1&householdId=2&month=2026-07": http.StatusBadRequest,
+		"/export?format=pdf&householdId=2&month=July":             http.StatusBadRequest,
+	} {
+		response := httptest.NewRecorder()
+		mux.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+		if response.Code != want {
+			t.Errorf("%s = %d", path, response.Code)
+		}
+	}
+	scope, err := mapScope(report, "Household", "Home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scope.PDFURL, scope.XLSXURL = path, "/export?format=xlsx"
+	var output strings.Builder
+	if err := ScopeReport(scope).Render(context.Background(), &output); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), `href="/export?format=pdf&amp;householdId=2&amp;month=2026-07"`) || !strings.Contains(output.String(), ">XLSX</a>") {
+		t.Fatalf("rendered export links: %s", output.String())
+	}
+}
+
 func TestMissingBudgetsRenderSuccessfulEmptyState(t *testing.T) {
 	notFound := apperrors.NotFound(apperrors.CodeBudgetNotFound, "budget not found", nil)
 	budgets := &budgetStub{reports: map[bool]appbudgets.DetailedReport{}, errs: map[bool]error{false: notFound, true: notFound}}
@@ -184,3 +331,83 @@ func TestMissingBudgetsRenderSuccessfulEmptyState(t *testing.T) {
 		t.Fatalf("status=%d body=%s", response.Code, response.Body.String())
 	}
 }
+
+func TestParseTrendStateDefaultsAndValidation(t *testing.T) {
+	config := Config{DefaultUserID: 1, DefaultHouseholdID: 2}
+	now := time.Date(2026, 7, 14, 12, 0, 0, 0, time.Local)
+	state, redirect, err := ParseTrendState(url.Values{}, config, now)
+	if err != nil || !redirect || TrendStateURL(state) != "/trends?from=2026-01&householdId=2&to=2026-07&userId=1" {
+		t.Fatalf("state=%+v redirect=%v err=%v", state, redirect, err)
+	}
+	state, redirect, err = ParseTrendState(url.Values{"from": {"2025-11"}, "to": {"2026-02"}, "userId": {"3"}}, config, now)
+	if err != nil || redirect || state.From.Month() != time.November || state.To.Year() != 2026 || state.UserID != 3 {
+		t.Fatalf("state=%+v redirect=%v err=%v", state, redirect, err)
+	}
+	for _, values := range []url.Values{
+		{"from": {"2026-03"}, "to": {"2026-02"}},
+		{"from": {"2024-01"}, "to": {"2026-01"}},
+		{"from": {"bad"}, "to": {"2026-02"}},
+	} {
+		if _, _, err := ParseTrendState(values, config, now); err == nil {
+			t.Fatalf("expected error for %v", values)
+		}
+	}
+}
+
+func TestTrendsPageRendersLinesAndCategories(t *testing.T) {
+	jan, feb := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	budgetID := int64(10)
+	totals := appbudgets.TrendTotals{Months: 2, AllocationAmount: "400.00", ActualAmount: "450.00", RemainingAmount: "-50.00", AverageAllocationAmount: "200.00", AverageActualAmount: "225.00"}
+	report := appbudgets.TrendReport{
+		Months: []appbudgets.TrendMonth{
+			{Month: jan, BudgetID: &budgetID, AllocationAmount: "200.00", ActualAmount: "150.00", RemainingAmount: "50.00"},
+			{Month: feb, BudgetID: &budgetID, AllocationAmount: "200.00", ActualAmount: "300.00", RemainingAmount: "-100.00"},
+		},
+		Lines: []appbudgets.TrendSeries{{Name: "Groceries", Months: []appbudgets.TrendPoint{
+			{Month: jan, Budgeted: true, AllocationAmount: "200.00", ActualAmount: "150.00", RemainingAmount: "50.00"},
+			{Month: feb, Budgeted: true, AllocationAmount: "200.00", ActualAmount: "300.00", RemainingAmount: "-100.00"},
+		}, Totals: totals, YearToDate: totals}},
+		Categories: []appbudgets.TrendSeries{{Name: "Uncategorized", Months: []appbudgets.TrendPoint{
+			{Month: jan, AllocationAmount: "0.00", ActualAmount: "0.00", RemainingAmount: "0.00"},
+			{Month: feb, AllocationAmount: "0.00", ActualAmount: "12.50", RemainingAmount: "-12.50"},
+		}, Totals: appbudgets.TrendTotals{Months: 2, AllocationAmount: "0.00", ActualAmount: "12.50", AverageActualAmount: "6.25"}, YearToDate: appbudgets.TrendTotals{ActualAmount: "12.50"}}, {Name: "Produce", Months: []appbudgets.TrendPoint{
+			{Month: jan, Budgeted: true, AllocationAmount: "100.00", ActualAmount: "75.00", RemainingAmount: "25.00"},
+			{Month: feb, Budgeted: true, AllocationAmount: "100.00", ActualAmount: "150.00", RemainingAmount: "-50.00"},
+		}, Totals: appbudgets.TrendTotals{Months: 2, AllocationAmount: "200.00", ActualAmount: "225.00", RemainingAmount: "-25.00", AverageActualAmount: "112.50"}, YearToDate: appbudgets.TrendTotals{ActualAmount: "225.00"}}},
+		Totals: totals, YearToDate: totals,
+	}
+	view, err := mapTrendScope(report, "Household", "Home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Empty || len(view.Months) != 2 || view.Lines[0].Cells[1].State != StateDanger || view.Lines[0].State != StateDanger || !view.Categories[0].Cells[0].Empty || view.Categories[0].Cells[1].Allocation != "" {
+		t.Fatalf("unexpected view: %+v", view)
+	}
+	if produce := view.Categories[1]; produce.Cells[0].Allocation != "$100.00" || produce.Cells[1].State != StateDanger || produce.Total != "$225.00 of $200.00" {
+		t.Fatalf("budgeted category row=%+v", produce)
+	}
+
+	budgets := &budgetStub{trends: map[bool]appbudgets.TrendReport{false: {}, true: report}, errs: map[bool]error{}}
+	handler, err := New(Config{DefaultUserID: 1, DefaultHouseholdID: 2}, Services{Budgets: budgets, Users: userStub{users: []appusers.User{{ID: 1, Name: "Alex"}}}, Households: householdStub{items: []apphouseholds.Household{{ID: 2, Name: "Home"}}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	handler.Register(mux)
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/trends?from=2026-01&to=2026-02", nil))
+	body := response.Body.String()
+	for _, expected := range []string{"Jan 2026 – Feb 2026", "Groceries", "Uncategorized", "No budgets or spending in these months", "$450.00 of $400.00"} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("status=%d expected %q in body: %s", response.Code, expected, body)
+		}
+	}
+	if response.Code != http.StatusOK || budgets.calls != 2 {
+		t.Fatalf("status=%d calls=%d", response.Code, budgets.calls)
+	}
+	response = httptest.NewRecorder()
+	mux.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/trends?from=2026-03&to=2026-02", nil))
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("validation status=%d", response.Code)
+	}
+}